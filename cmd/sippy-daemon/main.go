@@ -12,23 +12,31 @@ import (
 
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/github"
+	"github.com/openshift/sippy/pkg/digest"
 	"github.com/openshift/sippy/pkg/flags"
 	"github.com/openshift/sippy/pkg/github/commenter"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/sippyserver"
 )
 
-var logLevel = "info"
+var (
+	logLevel  = "info"
+	logFormat = logging.FormatText
+)
 
 type SippyDaemonFlags struct {
+	ConfigFlags      *flags.ConfigFlags
 	DBFlags          *flags.PostgresFlags
 	GoogleCloudFlags *flags.GoogleCloudFlags
 
 	GithubCommenterFlags *flags.GithubCommenterFlags
 	MetricsAddr          string
+	EmailDigest          bool
 }
 
 func NewSippyDaemonFlags() *SippyDaemonFlags {
 	return &SippyDaemonFlags{
+		ConfigFlags:          flags.NewConfigFlags(),
 		DBFlags:              flags.NewPostgresDatabaseFlags(),
 		GithubCommenterFlags: flags.NewGithubCommenterFlags(),
 		GoogleCloudFlags:     flags.NewGoogleCloudFlags(),
@@ -36,10 +44,12 @@ func NewSippyDaemonFlags() *SippyDaemonFlags {
 }
 
 func (f *SippyDaemonFlags) BindFlags(fs *pflag.FlagSet) {
+	f.ConfigFlags.BindFlags(fs)
 	f.DBFlags.BindFlags(fs)
 	f.GithubCommenterFlags.BindFlags(fs)
 	f.GoogleCloudFlags.BindFlags(fs)
 
+	fs.BoolVar(&f.EmailDigest, "email-digest", f.EmailDigest, "Email component owners digests of their tests, as configured in the sippy config")
 	fs.StringVar(&f.MetricsAddr, "listen-metrics", f.MetricsAddr, "The address to serve prometheus metrics on (default :2112)")
 }
 
@@ -60,9 +70,19 @@ func NewSippyDaemonCommand() *cobra.Command {
 					return err
 				}
 
+				sippyConfig, err := f.ConfigFlags.GetConfig()
+				if err != nil {
+					return err
+				}
+				commentTemplates, err := sippyserver.NewCommentTemplates(sippyConfig.Commenter, f.GithubCommenterFlags.SippyURL)
+				if err != nil {
+					return err
+				}
+
 				githubClient := github.New(context.TODO())
 				ghCommenter, err := commenter.NewGitHubCommenter(githubClient,
-					dbc, f.GithubCommenterFlags.ExcludeReposCommenting, f.GithubCommenterFlags.IncludeReposCommenting)
+					dbc, f.GithubCommenterFlags.ExcludeReposCommenting, f.GithubCommenterFlags.IncludeReposCommenting,
+					f.GithubCommenterFlags.GetCommentLimits())
 				if err != nil {
 					log.WithError(err).Error("CRITICAL error initializing GitHub commenter which prevents PR commenting")
 					return nil
@@ -78,12 +98,31 @@ func NewSippyDaemonCommand() *cobra.Command {
 				}
 
 				// we only process one comment every 5 seconds,
-				// 4 potential GitHub calls per comment gives us a safe buffer
-				// get comment data, get existing comments, possible delete existing, and adding the comment
-				// could  lower to 3 seconds if we need, most writes likely won't have to delete
+				// 3 potential GitHub calls per comment gives us a safe buffer
+				// get comment data, get existing comments, and adding the comment
+				// could  lower to 3 seconds if we need
 				processes = append(processes, sippyserver.NewWorkProcessor(dbc,
 					gcsClient.Bucket(f.GoogleCloudFlags.StorageBucket),
-					10, 5*time.Minute, 5*time.Second, ghCommenter, f.GithubCommenterFlags.CommentProcessingDryRun))
+					10, 5*time.Minute, 5*time.Second, ghCommenter, f.GithubCommenterFlags.CommentProcessingDryRun,
+					f.GithubCommenterFlags.SippyURL, f.GithubCommenterFlags.CheckRuns, commentTemplates))
+			}
+
+			if f.EmailDigest {
+				dbc, err := f.DBFlags.GetDBClient()
+				if err != nil {
+					return err
+				}
+
+				sippyConfig, err := f.ConfigFlags.GetConfig()
+				if err != nil {
+					return err
+				}
+
+				digestProcessor, err := digest.NewProcessor(dbc, sippyConfig.Digest)
+				if err != nil {
+					return err
+				}
+				processes = append(processes, digestProcessor)
 			}
 
 			daemonServer := sippyserver.NewDaemonServer(processes)
@@ -91,8 +130,10 @@ func NewSippyDaemonCommand() *cobra.Command {
 			// Serve our metrics endpoint for prometheus to scrape
 			if f.MetricsAddr != "" {
 				go func() {
-					http.Handle("/metrics", promhttp.Handler())
-					err := http.ListenAndServe(f.MetricsAddr, nil) //nolint
+					// a mux of its own, as net/http/pprof registers the profiles on the default mux
+					metricsMux := http.NewServeMux()
+					metricsMux.Handle("/metrics", promhttp.Handler())
+					err := http.ListenAndServe(f.MetricsAddr, metricsMux) //nolint
 					if err != nil {
 						panic(err)
 					}
@@ -111,26 +152,21 @@ func NewSippyDaemonCommand() *cobra.Command {
 }
 
 func main() {
-	// Set log level
-	level, err := log.ParseLevel(logLevel)
-	if err != nil {
-		log.WithError(err).Fatal("cannot parse log-level")
+	// Flags are only parsed once the command executes, so until then log with the defaults
+	if err := logging.Configure(logLevel, logFormat); err != nil {
+		log.WithError(err).Fatal("cannot configure logging")
 	}
-	log.SetLevel(level)
-	log.Debug("debug logging enabled")
-
-	// Add some millisecond precision to log timestamps, useful for debugging performance.
-	formatter := new(log.TextFormatter)
-	formatter.TimestampFormat = "2006-01-02T15:04:05.999Z07:00"
-	formatter.FullTimestamp = true
-	formatter.DisableColors = false
-	log.SetFormatter(formatter)
 
 	cmd := NewSippyDaemonCommand()
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return logging.Configure(logLevel, logFormat)
+	}
 	cmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
 		"Log level (trace,debug,info,warn,error) (default info)")
+	cmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormat,
+		"Log format (text,json)")
 
-	err = cmd.Execute()
+	err := cmd.Execute()
 	if err != nil {
 		log.WithError(err).Fatal("could not execute root command")
 	}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/analysis"
+	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+type AnalyzeFlags struct {
+	DBFlags *flags.PostgresFlags
+
+	Release string
+	Reports []string
+	Format  string
+	Output  string
+	Limit   int
+}
+
+func NewAnalyzeFlags() *AnalyzeFlags {
+	return &AnalyzeFlags{
+		DBFlags: flags.NewPostgresDatabaseFlags(),
+		Reports: analysis.ReportNames(),
+		Format:  analysis.FormatJSON,
+		Limit:   50,
+	}
+}
+
+func (f *AnalyzeFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	fs.StringVar(&f.Release, "release", f.Release, "Release to analyze")
+	fs.StringSliceVar(&f.Reports, "report", f.Reports, "Which reports to run (one per arg instance, or comma separated), defaults to all")
+	fs.StringVar(&f.Format, "format", f.Format, fmt.Sprintf("Output format, one of %v", analysis.Formats))
+	fs.StringVarP(&f.Output, "output", "o", f.Output, "File to write the reports to, defaults to stdout")
+	fs.IntVar(&f.Limit, "limit", f.Limit, "Maximum number of tests to list in test reports, 0 for no limit")
+}
+
+func (f *AnalyzeFlags) Validate() error {
+	if f.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+	return nil
+}
+
+func NewAnalyzeCommand() *cobra.Command {
+	f := NewAnalyzeFlags()
+
+	descriptions := analysis.Reports()
+	var long strings.Builder
+	long.WriteString("Run reports against the database, without the HTTP server, and write them as JSON, CSV or Markdown. Reports are:\n\n")
+	for _, name := range analysis.ReportNames() {
+		fmt.Fprintf(&long, "  %s: %s\n", name, descriptions[name])
+	}
+
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Run sippy reports offline and write them to stdout or a file",
+		Long:  long.String(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			tables, err := analysis.Run(dbc, f.Reports, analysis.Options{
+				Release:   f.Release,
+				ReportEnd: util.GetReportEnd(f.DBFlags.GetPinnedTime()),
+				Limit:     f.Limit,
+			})
+			if err != nil {
+				return err
+			}
+
+			var out io.Writer = os.Stdout
+			if f.Output != "" {
+				file, err := os.Create(f.Output)
+				if err != nil {
+					return errors.WithMessage(err, "could not create output file")
+				}
+				defer file.Close()
+				out = file
+			}
+			return analysis.Write(out, f.Format, tables)
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+	cmd.MarkFlagRequired("release") //nolint:errcheck
+	completeReports := cobra.FixedCompletions(analysis.ReportNames(), cobra.ShellCompDirectiveNoFileComp)
+	completeFormats := cobra.FixedCompletions(analysis.Formats, cobra.ShellCompDirectiveNoFileComp)
+	cmd.RegisterFlagCompletionFunc("report", completeReports) //nolint:errcheck
+	cmd.RegisterFlagCompletionFunc("format", completeFormats) //nolint:errcheck
+
+	return cmd
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/archive"
+	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/notify"
+	"github.com/openshift/sippy/pkg/sippyserver"
+)
+
+type ArchiveFlags struct {
+	DBFlags          *flags.PostgresFlags
+	GoogleCloudFlags *flags.GoogleCloudFlags
+	ArchiveFlags     *flags.ArchiveFlags
+
+	OlderThanMonths int
+	BatchSize       int
+	DryRun          bool
+}
+
+func NewArchiveFlags() *ArchiveFlags {
+	return &ArchiveFlags{
+		DBFlags:          flags.NewPostgresDatabaseFlags(),
+		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
+		ArchiveFlags:     flags.NewArchiveFlags(),
+		OlderThanMonths:  6,
+		BatchSize:        archive.DefaultBatchSize,
+	}
+}
+
+func (f *ArchiveFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+	f.ArchiveFlags.BindFlags(fs)
+	fs.IntVar(&f.OlderThanMonths, "older-than-months", f.OlderThanMonths,
+		fmt.Sprintf("Archive job runs older than this many months (at least %d)", archive.MinAgeMonths))
+	fs.IntVar(&f.BatchSize, "batch-size", f.BatchSize, "Number of job runs to archive to each file")
+	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Only count what would be archived")
+}
+
+func NewArchiveCommand() *cobra.Command {
+	f := NewArchiveFlags()
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move old job runs and their test results to parquet files in object storage",
+		Long: "Write the job runs older than --older-than-months, and their test results, to parquet files at " +
+			"--archive-location and delete them from the database, in batches. Archived runs can still be read back " +
+			"with the /api/jobs/runs/archived API of a server given the same location. The materialized views are " +
+			"refreshed after.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			store, err := f.ArchiveFlags.GetStore(ctx, f.GoogleCloudFlags)
+			if err != nil {
+				return errors.WithMessage(err, "could not get archive store")
+			}
+			if store == nil {
+				return fmt.Errorf("--archive-location is required")
+			}
+
+			now := time.Now()
+			if pinned := f.DBFlags.GetPinnedTime(); pinned != nil {
+				now = *pinned
+			}
+			result, err := archive.Archive(ctx, dbc, store, f.OlderThanMonths, now, f.BatchSize, f.DryRun)
+			if err != nil {
+				return errors.WithMessage(err, "could not archive job runs")
+			}
+
+			verb := "archived"
+			if f.DryRun {
+				verb = "would archive"
+			}
+			fmt.Printf("%s %d job runs with %d test results to %d files\n", verb, result.JobRuns, result.TestResults,
+				result.Files)
+			if f.DryRun || result.JobRuns == 0 {
+				return nil
+			}
+
+			log.Info("refreshing materialized views")
+			sippyserver.RefreshData(dbc, "archive", f.DBFlags.GetPinnedTime(), false, v1.RegressionDetectionConfig{},
+				v1.SilentJobDetectionConfig{}, notify.NewNoopNotifier())
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
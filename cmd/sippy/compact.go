@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/compaction"
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+type CompactFlags struct {
+	DBFlags *flags.PostgresFlags
+
+	OlderThanDays int
+	DryRun        bool
+}
+
+func NewCompactFlags() *CompactFlags {
+	return &CompactFlags{
+		DBFlags:       flags.NewPostgresDatabaseFlags(),
+		OlderThanDays: 90,
+	}
+}
+
+func (f *CompactFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	fs.IntVar(&f.OlderThanDays, "older-than-days", f.OlderThanDays,
+		fmt.Sprintf("Compact the test results of job runs older than this many days (at least %d)", int(compaction.MinAge.Hours()/24)))
+	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Only count what would be compacted")
+}
+
+func NewCompactCommand() *cobra.Command {
+	f := NewCompactFlags()
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Collapse the passing test results of old job runs into daily results",
+		Long: "Collapse the passing test results of job runs older than --older-than-days into a result per test, " +
+			"job and day, keeping failed and flaky results as they are. Days whose week of history hasn't been " +
+			"recorded are skipped. Run it periodically, i.e. as a cron job, to keep the test results table small.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			now := time.Now()
+			if pinned := f.DBFlags.GetPinnedTime(); pinned != nil {
+				now = *pinned
+			}
+			result, err := compaction.Compact(dbc, time.Duration(f.OlderThanDays)*24*time.Hour, now, f.DryRun)
+			if err != nil {
+				return errors.WithMessage(err, "could not compact test results")
+			}
+
+			verb := "compacted"
+			if f.DryRun {
+				verb = "would compact"
+			}
+			fmt.Printf("%s %d test results of %d days into %d daily results\n", verb, result.TestResults, result.Days,
+				result.DailyResults)
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
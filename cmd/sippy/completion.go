@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/logging"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// registerCompletions completes the values of flags shared by several commands, cobra provides the completion command
+// itself. Command specific flags are registered where the command is created.
+func registerCompletions(root *cobra.Command) {
+	root.RegisterFlagCompletionFunc("log-level", cobra.FixedCompletions( //nolint:errcheck
+		[]string{"trace", "debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp))
+	root.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions( //nolint:errcheck
+		logging.Formats, cobra.ShellCompDirectiveNoFileComp))
+
+	for _, cmd := range root.Commands() {
+		fs := cmd.Flags()
+		if fs.Lookup("mode") != nil {
+			cmd.RegisterFlagCompletionFunc("mode", cobra.FixedCompletions( //nolint:errcheck
+				testidentification.RegisteredVariantManagers(), cobra.ShellCompDirectiveNoFileComp))
+		}
+		for _, name := range []string{"config", "variant-config"} {
+			if fs.Lookup(name) != nil {
+				cmd.MarkFlagFilename(name, "yaml", "yml") //nolint:errcheck
+			}
+		}
+		for _, name := range []string{"google-service-account-credential-file", "google-oauth-credential-file"} {
+			if fs.Lookup(name) != nil {
+				cmd.MarkFlagFilename(name, "json") //nolint:errcheck
+			}
+		}
+	}
+}
+
+// FlagDoc describes a flag for tooling, i.e. to generate deployment config forms.
+type FlagDoc struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default"`
+	Usage     string `json:"usage"`
+	Required  bool   `json:"required,omitempty"`
+}
+
+// CommandDoc describes a command and every flag it accepts, including those inherited from the root command.
+type CommandDoc struct {
+	Command string    `json:"command"`
+	Short   string    `json:"short"`
+	Flags   []FlagDoc `json:"flags"`
+}
+
+func NewFlagsCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:    "flags",
+		Short:  "Describe every command's flags and their defaults",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			docs := describeCommands(cmd.Root())
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(docs)
+			}
+			for _, doc := range docs {
+				fmt.Println(doc.Command)
+				for _, f := range doc.Flags {
+					fmt.Printf("  --%s %s (default %q)\n", f.Name, f.Type, f.Default)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", asJSON, "Output JSON")
+
+	return cmd
+}
+
+// describeCommands describes the visible commands under root, in the order cobra lists them.
+func describeCommands(root *cobra.Command) []CommandDoc {
+	docs := make([]CommandDoc, 0)
+	for _, cmd := range root.Commands() {
+		if cmd.Hidden || !cmd.IsAvailableCommand() || cmd.Name() == "completion" {
+			continue
+		}
+		if cmd.HasSubCommands() {
+			docs = append(docs, describeCommands(cmd)...)
+		}
+		if cmd.Runnable() {
+			docs = append(docs, describeCommand(cmd))
+		}
+	}
+	return docs
+}
+
+func describeCommand(cmd *cobra.Command) CommandDoc {
+	doc := CommandDoc{Command: cmd.CommandPath(), Short: cmd.Short, Flags: make([]FlagDoc, 0)}
+	add := func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		_, required := f.Annotations[cobra.BashCompOneRequiredFlag]
+		doc.Flags = append(doc.Flags, FlagDoc{
+			Name:      f.Name,
+			Shorthand: f.Shorthand,
+			Type:      f.Value.Type(),
+			Default:   f.DefValue,
+			Usage:     f.Usage,
+			Required:  required,
+		})
+	}
+	cmd.NonInheritedFlags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+	return doc
+}
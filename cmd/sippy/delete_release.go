@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/notify"
+	"github.com/openshift/sippy/pkg/releasedeletion"
+	"github.com/openshift/sippy/pkg/sippyserver"
+)
+
+type DeleteReleaseFlags struct {
+	DBFlags *flags.PostgresFlags
+
+	BatchSize int
+	DryRun    bool
+}
+
+func NewDeleteReleaseFlags() *DeleteReleaseFlags {
+	return &DeleteReleaseFlags{
+		DBFlags:   flags.NewPostgresDatabaseFlags(),
+		BatchSize: releasedeletion.DefaultBatchSize,
+	}
+}
+
+func (f *DeleteReleaseFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	fs.IntVar(&f.BatchSize, "batch-size", f.BatchSize, "Number of job runs or release tags to delete at a time")
+	fs.BoolVar(&f.DryRun, "dry-run", f.DryRun, "Only count what would be deleted")
+}
+
+func NewDeleteReleaseCommand() *cobra.Command {
+	f := NewDeleteReleaseFlags()
+
+	cmd := &cobra.Command{
+		Use:   "delete-release <release>",
+		Short: "Delete all data for an end-of-life release",
+		Long: "Delete a release's jobs, their runs and test results, its payloads, and the regressions, funnels and " +
+			"weekly history computed from them, in batches. The materialized views are refreshed after. Use " +
+			"--dry-run to count what would be deleted first.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			release := args[0]
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			result, err := releasedeletion.Delete(dbc, release, f.BatchSize, f.DryRun)
+			if err != nil {
+				return errors.WithMessagef(err, "could not delete release %s", release)
+			}
+
+			verb := "deleted"
+			if f.DryRun {
+				verb = "would delete"
+			}
+			fmt.Printf("%s %d jobs, %d job runs, %d release tags, %d regressions, %d funnel days, %d weekly results, "+
+				"%d daily results, %d sustained regressions, %d silent jobs and %d loader errors of release %s\n", verb,
+				result.Jobs, result.JobRuns, result.ReleaseTags, result.Regressions, result.FunnelDays,
+				result.WeeklyResults, result.DailyResults, result.SustainedIssues, result.SilentJobs,
+				result.LoaderErrors, release)
+			if f.DryRun {
+				return nil
+			}
+
+			log.Info("refreshing materialized views")
+			sippyserver.RefreshData(dbc, "delete-release", f.DBFlags.GetPinnedTime(), false, v1.RegressionDetectionConfig{},
+				v1.SilentJobDetectionConfig{}, notify.NewNoopNotifier())
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
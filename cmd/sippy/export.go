@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/api/option"
+
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/export"
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+type ExportBigQueryFlags struct {
+	DBFlags          *flags.PostgresFlags
+	GoogleCloudFlags *flags.GoogleCloudFlags
+
+	Project             string
+	Dataset             string
+	Table               string
+	Releases            []string
+	RegressionThreshold float64
+	Interval            time.Duration
+}
+
+func NewExportBigQueryFlags() *ExportBigQueryFlags {
+	return &ExportBigQueryFlags{
+		DBFlags:             flags.NewPostgresDatabaseFlags(),
+		GoogleCloudFlags:    flags.NewGoogleCloudFlags(),
+		Table:               "test_reports",
+		RegressionThreshold: 5,
+	}
+}
+
+func (f *ExportBigQueryFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+	fs.StringVar(&f.Project, "project", f.Project, "BigQuery project to export to")
+	fs.StringVar(&f.Dataset, "dataset", f.Dataset, "BigQuery dataset to export to")
+	fs.StringVar(&f.Table, "table", f.Table, "BigQuery table to append the test reports to, created if it doesn't exist")
+	fs.StringArrayVar(&f.Releases, "release", f.Releases, "Which releases to export (one per arg instance), defaults to all releases")
+	fs.Float64Var(&f.RegressionThreshold, "regression-threshold", f.RegressionThreshold, "Flag tests whose pass percentage dropped by at least this much as regressed")
+	fs.DurationVar(&f.Interval, "interval", f.Interval, "Export repeatedly at this interval, instead of once")
+}
+
+func NewExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export sippy data to other systems",
+	}
+	cmd.AddCommand(NewExportBigQueryCommand())
+	return cmd
+}
+
+func NewExportBigQueryCommand() *cobra.Command {
+	f := NewExportBigQueryFlags()
+
+	cmd := &cobra.Command{
+		Use:   "bigquery",
+		Short: "Export the computed test reports and regression flags to a BigQuery dataset",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			bqc, err := bigquery.NewClient(ctx, f.Project, option.WithCredentialsFile(f.GoogleCloudFlags.ServiceAccountCredentialFile))
+			if err != nil {
+				return errors.WithMessage(err, "could not get bigquery client")
+			}
+			defer bqc.Close()
+
+			releases := f.Releases
+			if len(releases) == 0 {
+				dbReleases, err := query.ReleasesFromDB(dbc)
+				if err != nil {
+					return errors.WithMessage(err, "could not query releases")
+				}
+				for _, r := range dbReleases {
+					releases = append(releases, r.Release)
+				}
+			}
+
+			exporter := export.NewBigQueryExporter(dbc, bqc, f.Dataset, f.Table, releases, f.RegressionThreshold)
+			if f.Interval == 0 {
+				return exporter.Export(ctx, time.Now().UTC())
+			}
+
+			for {
+				if err := exporter.Export(ctx, time.Now().UTC()); err != nil {
+					log.WithError(err).Error("error exporting to bigquery")
+				}
+				log.Infof("next export in %s", f.Interval)
+				time.Sleep(f.Interval)
+			}
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+	cmd.MarkFlagRequired("project") //nolint:errcheck
+	cmd.MarkFlagRequired("dataset") //nolint:errcheck
+
+	return cmd
+}
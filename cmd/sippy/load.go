@@ -3,31 +3,63 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/storage"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"google.golang.org/api/option"
 
+	"github.com/openshift/sippy/pkg/alerting"
 	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
 	"github.com/openshift/sippy/pkg/dataloader"
 	"github.com/openshift/sippy/pkg/dataloader/bugloader"
+	"github.com/openshift/sippy/pkg/dataloader/elasticsearchloader"
 	"github.com/openshift/sippy/pkg/dataloader/jiraloader"
 	"github.com/openshift/sippy/pkg/dataloader/loaderwithmetrics"
+	"github.com/openshift/sippy/pkg/dataloader/loadsummary"
+	"github.com/openshift/sippy/pkg/dataloader/neverstableloader"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/github"
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
 	"github.com/openshift/sippy/pkg/dataloader/testownershiploader"
 	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/failureclassification"
 	"github.com/openshift/sippy/pkg/flags"
 	"github.com/openshift/sippy/pkg/github/commenter"
+	"github.com/openshift/sippy/pkg/jirafiling"
+	"github.com/openshift/sippy/pkg/logging"
+	"github.com/openshift/sippy/pkg/notify"
 	"github.com/openshift/sippy/pkg/sippyserver"
+	"github.com/openshift/sippy/pkg/synthetictests"
+	"github.com/openshift/sippy/pkg/testidentification"
+	"github.com/openshift/sippy/pkg/tracing"
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
 )
 
+// loaderNames are the data sources load can use, all of them are used by default.
+var loaderNames = []string{"prow", "releases", "jira", "github", "bugs", "test-mapping", "never-stable"}
+
+// loaderTables are the tables each loader writes to, so a load only refreshes the materialized views computed from
+// them.
+var loaderTables = map[string][]string{
+	"prow": {"prow_jobs", "prow_job_runs", "prow_job_run_tests", "prow_job_run_test_outputs", "tests", "suites",
+		"prow_pull_requests", "prow_job_run_prow_pull_requests"},
+	"releases":     {"release_tags", "release_pull_requests", "release_repositories", "release_job_runs"},
+	"jira":         {"jira_components", "jira_incidents"},
+	"github":       {"prow_pull_requests", "prow_job_run_prow_pull_requests"},
+	"bugs":         {"bugs", "bug_tests", "bug_jobs"},
+	"test-mapping": {"test_ownerships", "jira_components", "suites"},
+	"never-stable": {"prow_jobs"},
+}
+
 type LoadFlags struct {
 	LoadOpenShiftCIBigQuery bool
 	Loaders                 []string
@@ -36,6 +68,21 @@ type LoadFlags struct {
 
 	Architectures []string
 	Releases      []string
+	Tenant        string
+
+	// From and To are the dates of a historical window to backfill prow job runs for.
+	From string
+	To   string
+
+	// StoreJUnit keeps the junit files of the imported runs, so they can be reprocessed without reading them again.
+	StoreJUnit bool
+
+	// TestLogRetentionDays is how many days the failure output and system-out of failed tests are kept, zero doesn't
+	// keep them.
+	TestLogRetentionDays int
+
+	// SummaryOutput is the file, or gs://bucket/path object, a JSON summary of the load is written to.
+	SummaryOutput string
 
 	BigQueryFlags        *flags.BigQueryFlags
 	ConfigFlags          *flags.ConfigFlags
@@ -66,9 +113,73 @@ func (f *LoadFlags) BindFlags(fs *pflag.FlagSet) {
 
 	fs.BoolVar(&f.InitDatabase, "init-database", false, "Migrate the DB before loading")
 	fs.BoolVar(&f.LoadOpenShiftCIBigQuery, "load-openshift-ci-bigquery", false, "Load ProwJobs from OpenShift CI BigQuery")
-	fs.StringArrayVar(&f.Loaders, "loader", []string{"prow", "releases", "jira", "github", "bugs", "test-mapping"}, "Which data sources to use for data loading")
+	fs.StringArrayVar(&f.Loaders, "loader", loaderNames, "Which data sources to use for data loading")
 	fs.StringArrayVar(&f.Releases, "release", f.Releases, "Which releases to load (one per arg instance)")
+	fs.StringVar(&f.Tenant, "tenant", f.Tenant, "Load the tenant's releases, using its prow and GCS bucket if configured")
 	fs.StringArrayVar(&f.Architectures, "arch", f.Architectures, "Which architectures to load (one per arg instance)")
+	fs.StringVar(&f.From, "from", f.From, "Backfill prow job runs started on or after this date (YYYY-MM-DD) by walking GCS, instead of loading new runs")
+	fs.StringVar(&f.To, "to", f.To, "Backfill prow job runs started before this date (YYYY-MM-DD), defaults to now")
+	fs.BoolVar(&f.StoreJUnit, "store-junit", f.StoreJUnit, "Keep the gzipped junit files of imported runs in the DB, so sippy reprocess doesn't read them from GCS")
+	fs.IntVar(&f.TestLogRetentionDays, "test-log-retention-days", f.TestLogRetentionDays, "Keep the compressed failure output and system-out of failed tests for this many days, 0 doesn't keep them")
+	fs.StringVar(&f.SummaryOutput, "summary-output", f.SummaryOutput, "Write a JSON summary of the load, with the rows written per table, each loader's duration and errors, and the data's watermarks, to this file or gs://bucket/path")
+}
+
+// applyTenant restricts the load to the tenant's releases, and imports them from its prow and bucket if it has its
+// own.
+func (f *LoadFlags) applyTenant(config *v1.SippyConfig) error {
+	if f.Tenant == "" {
+		return nil
+	}
+	tenantConfig, ok := config.Tenants[f.Tenant]
+	if !ok {
+		return fmt.Errorf("tenant %s isn't in the sippy config", f.Tenant)
+	}
+
+	if len(f.Releases) == 0 {
+		f.Releases = tenantConfig.Releases
+	}
+	tenantReleases := sets.NewString(tenantConfig.Releases...)
+	for _, release := range f.Releases {
+		if !tenantReleases.Has(release) {
+			return fmt.Errorf("release %s doesn't belong to tenant %s", release, f.Tenant)
+		}
+	}
+
+	if tenantConfig.Prow.URL != "" {
+		config.Prow = tenantConfig.Prow
+	}
+	if tenantConfig.GCSBucket != "" {
+		f.GoogleCloudFlags.StorageBucket = tenantConfig.GCSBucket
+	}
+	return nil
+}
+
+// GetBackfillWindow returns the window of prow job runs to backfill, or zero times when not backfilling.
+func (f *LoadFlags) GetBackfillWindow() (time.Time, time.Time, error) {
+	if f.From == "" {
+		if f.To != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("--to requires --from")
+		}
+		return time.Time{}, time.Time{}, nil
+	}
+	if f.LoadOpenShiftCIBigQuery {
+		return time.Time{}, time.Time{}, fmt.Errorf("--from backfills from GCS and can't be used with --load-openshift-ci-bigquery")
+	}
+
+	from, err := time.Parse("2006-01-02", f.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.WithMessage(err, "invalid --from")
+	}
+	to := time.Now().UTC()
+	if f.To != "" {
+		if to, err = time.Parse("2006-01-02", f.To); err != nil {
+			return time.Time{}, time.Time{}, errors.WithMessage(err, "invalid --to")
+		}
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--from must be before --to")
+	}
+	return from, to, nil
 }
 
 func NewLoadCommand() *cobra.Command {
@@ -78,6 +189,11 @@ func NewLoadCommand() *cobra.Command {
 		Use:   "load",
 		Short: "Load data in the database",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			backfillFrom, backfillTo, err := f.GetBackfillWindow()
+			if err != nil {
+				return err
+			}
+
 			loaders := make([]dataloader.DataLoader, 0)
 			allErrs := []error{}
 
@@ -85,6 +201,15 @@ func NewLoadCommand() *cobra.Command {
 			ctx, cancel := context.WithTimeout(context.Background(), time.Hour*4)
 			defer cancel()
 
+			// Every log line of this load, including the database statements, carries its request ID
+			log.AddHook(logging.RequestIDHook{RequestID: logging.NewRequestID()})
+
+			shutdownTracing, err := tracing.Init(ctx, "sippy-loader")
+			if err != nil {
+				return errors.WithMessage(err, "couldn't initialize tracing")
+			}
+			defer shutdownTracing(context.Background()) //nolint:errcheck
+
 			// Get a DB client
 			dbc, err := f.DBFlags.GetDBClient()
 			if err != nil {
@@ -105,11 +230,33 @@ func NewLoadCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if err := f.applyTenant(config); err != nil {
+				return err
+			}
+			jobTenants, err := config.JobTenants()
+			if err != nil {
+				return err
+			}
+
+			notifier, err := notify.NewNotifier(config.Notifications)
+			if err != nil {
+				return errors.WithMessage(err, "could not create notifier")
+			}
+
+			alertSenders, err := alerting.NewSenders(config.Alerting)
+			if err != nil {
+				return errors.WithMessage(err, "could not create alert senders")
+			}
+
+			regressionFiler, err := jirafiling.NewFiler(dbc, config.JiraFiling)
+			if err != nil {
+				return errors.WithMessage(err, "could not create jira regression filer")
+			}
 
 			for _, l := range f.Loaders {
 				// Release payload tag loader
 				if l == "releases" {
-					loaders = append(loaders, releaseloader.New(dbc, f.Releases, f.Architectures))
+					loaders = append(loaders, releaseloader.New(dbc, f.Releases, f.Architectures, notifier))
 				}
 
 				// Prow Loader
@@ -118,6 +265,10 @@ func NewLoadCommand() *cobra.Command {
 					if err != nil {
 						return err
 					}
+					prowLoader.SetJobTenants(jobTenants)
+					if !backfillTo.IsZero() {
+						prowLoader.SetBackfillWindow(backfillFrom, backfillTo)
+					}
 					loaders = append(loaders, prowLoader)
 				}
 
@@ -143,20 +294,58 @@ func NewLoadCommand() *cobra.Command {
 				if l == "bugs" {
 					loaders = append(loaders, bugloader.New(dbc))
 				}
+
+				// Flag jobs that have been failing for weeks as never-stable
+				if l == "never-stable" {
+					loaders = append(loaders, neverstableloader.New(dbc, config.NeverStable))
+				}
+			}
+
+			// Export the loaded job runs last, once the other loaders have updated them
+			if config.Elasticsearch.URL != "" {
+				loaders = append(loaders, elasticsearchloader.New(dbc, config.Elasticsearch))
+			}
+
+			var statsBefore loadsummary.TableStats
+			if f.SummaryOutput != "" {
+				if statsBefore, err = loadsummary.GetTableStats(dbc, f.loadedTables()); err != nil {
+					log.WithError(err).Warning("couldn't read table statistics, the load summary won't count rows written")
+				}
 			}
 
 			// Run loaders with the metrics wrapper
-			l := loaderwithmetrics.New(loaders)
-			l.Load()
+			l := loaderwithmetrics.New(dbc, loaders)
+			l.Load(ctx)
 			if len(l.Errors()) > 0 {
 				allErrs = append(allErrs, l.Errors()...)
 			}
+			notifyLoaderFailures(notifier, loaders)
+
+			if err := dbc.AssignTenants(jobTenants.Tenant); err != nil {
+				allErrs = append(allErrs, errors.WithMessage(err, "could not assign tenants"))
+			}
 
 			elapsed := time.Since(start)
 			log.WithField("elapsed", elapsed).Info("database load complete")
 
 			pinnedTime := f.DBFlags.GetPinnedTime()
-			sippyserver.RefreshData(dbc, pinnedTime, false)
+			sippyserver.RefreshDataForTables(dbc, "load", pinnedTime, config.RegressionDetection, config.SilentJobDetection, notifier,
+				f.loadedTables())
+
+			// alert on the refreshed data
+			if err := alerting.Evaluate(dbc, config.Alerting, config.Releases, alertSenders, util.GetReportEnd(pinnedTime)); err != nil {
+				log.WithError(err).Error("error evaluating alerts")
+			}
+			if err := regressionFiler.Evaluate(time.Now()); err != nil {
+				log.WithError(err).Error("error filing jira issues for sustained regressions")
+			}
+
+			if f.SummaryOutput != "" {
+				summary := f.summarize(dbc, start, loaders, l, statsBefore, len(allErrs))
+				if err := f.writeSummary(ctx, summary); err != nil {
+					allErrs = append(allErrs, errors.WithMessage(err, "could not write load summary"))
+				}
+			}
 
 			if len(allErrs) > 0 {
 				log.Warningf("%d errors were encountered while loading database:", len(allErrs))
@@ -171,11 +360,94 @@ func NewLoadCommand() *cobra.Command {
 	}
 
 	f.BindFlags(cmd.Flags())
+	cmd.RegisterFlagCompletionFunc("loader", cobra.FixedCompletions(loaderNames, cobra.ShellCompDirectiveNoFileComp)) //nolint:errcheck
 
 	return cmd
 }
 
-func (f *LoadFlags) prowLoader(ctx context.Context, dbc *db.DB, sippyConfig *v1.SippyConfig) (dataloader.DataLoader, error) {
+func notifyLoaderFailures(notifier notify.Notifier, loaders []dataloader.DataLoader) {
+	for _, loader := range loaders {
+		errs := loader.Errors()
+		if len(errs) == 0 {
+			continue
+		}
+
+		err := notifier.Notify(notify.Event{
+			Type:    notify.EventLoaderFailed,
+			Title:   fmt.Sprintf("Sippy %s loader failed", loader.Name()),
+			Message: fmt.Sprintf("%d errors were encountered, the first was: %s", len(errs), errs[0]),
+		})
+		if err != nil {
+			log.WithError(err).Warningf("error notifying about %s loader failure", loader.Name())
+		}
+	}
+}
+
+// summarize summarizes the load, taking the rows written from the table statistics since those read before it.
+func (f *LoadFlags) summarize(dbc *db.DB, start time.Time, loaders []dataloader.DataLoader,
+	l *loaderwithmetrics.LoaderWithMetrics, statsBefore loadsummary.TableStats, errs int) *loadsummary.Summary {
+	finished := time.Now()
+	summary := &loadsummary.Summary{
+		StartedAt:  start,
+		FinishedAt: finished,
+		DurationMS: finished.Sub(start).Milliseconds(),
+		Releases:   f.Releases,
+		Tenant:     f.Tenant,
+		Loaders:    make([]loadsummary.Loader, 0, len(loaders)),
+		Tables:     []loadsummary.Table{},
+		Errors:     errs,
+		Success:    errs == 0,
+	}
+	for _, loader := range loaders {
+		result := loadsummary.Loader{
+			Name:       loader.Name(),
+			DurationMS: l.Duration(loader.Name()).Milliseconds(),
+			Errors:     len(loader.Errors()),
+		}
+		if result.Errors > 0 {
+			result.FirstError = loader.Errors()[0].Error()
+		}
+		summary.Loaders = append(summary.Loaders, result)
+	}
+
+	tables := f.loadedTables()
+	if statsBefore != nil {
+		if statsAfter, err := loadsummary.GetTableStats(dbc, tables); err != nil {
+			log.WithError(err).Warning("couldn't read table statistics, the load summary won't count rows written")
+		} else {
+			summary.Tables = statsAfter.Since(statsBefore)
+		}
+	}
+	watermarks, err := loadsummary.GetWatermarks(dbc, tables)
+	if err != nil {
+		log.WithError(err).Warning("couldn't query watermarks for the load summary")
+	}
+	summary.Watermarks = watermarks
+	return summary
+}
+
+// writeSummary writes the summary to the summary output, authenticating to GCS with the google flags' credentials
+// when it's a gs:// path.
+func (f *LoadFlags) writeSummary(ctx context.Context, summary *loadsummary.Summary) error {
+	var client *storage.Client
+	if strings.HasPrefix(f.SummaryOutput, "gs://") {
+		var err error
+		client, err = gcs.NewGCSClient(ctx,
+			f.GoogleCloudFlags.ServiceAccountCredentialFile,
+			f.GoogleCloudFlags.OAuthClientCredentialFile,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := loadsummary.Write(ctx, f.SummaryOutput, client, summary); err != nil {
+		return err
+	}
+	log.Infof("wrote load summary to %s", f.SummaryOutput)
+	return nil
+}
+
+func (f *LoadFlags) prowLoader(ctx context.Context, dbc *db.DB, sippyConfig *v1.SippyConfig) (*prowloader.ProwLoader, error) {
 	gcsClient, err := gcs.NewGCSClient(ctx,
 		f.GoogleCloudFlags.ServiceAccountCredentialFile,
 		f.GoogleCloudFlags.OAuthClientCredentialFile,
@@ -203,22 +475,81 @@ func (f *LoadFlags) prowLoader(ctx context.Context, dbc *db.DB, sippyConfig *v1.
 		}
 	}
 
-	ghCommenter, err := commenter.NewGitHubCommenter(githubClient, dbc, f.GithubCommenterFlags.ExcludeReposCommenting, f.GithubCommenterFlags.IncludeReposCommenting)
+	variantManager, err := f.ModeFlags.GetVariantManager()
+	if err != nil {
+		log.WithError(err).Error("CRITICAL error loading variant manager which prevents importing prow jobs")
+		return nil, err
+	}
+	neverStableJobs, err := query.NeverStableJobNames(dbc)
+	if err != nil {
+		log.WithError(err).Error("CRITICAL error querying never-stable jobs which prevents importing prow jobs")
+		return nil, err
+	}
+	variantManager = testidentification.NewNeverStableVariantManager(variantManager, neverStableJobs)
+	variantManager = testidentification.NewReleaseVariantManager(variantManager, invalidVariantsByRelease(sippyConfig))
+	variantManager = testidentification.NewExperimentalVariantManager(variantManager, experimentalJobs(sippyConfig))
+
+	syntheticTestManager, err := synthetictests.NewConfigSyntheticTestManager(f.ModeFlags.GetSyntheticTestManager(), sippyConfig.SyntheticTests)
+	if err != nil {
+		log.WithError(err).Error("CRITICAL error loading synthetic tests which prevents importing prow jobs")
+		return nil, err
+	}
+
+	failureClassifier, err := failureclassification.NewClassifier(sippyConfig.FailureClassification)
+	if err != nil {
+		log.WithError(err).Error("CRITICAL error loading failure classification rules which prevents importing prow jobs")
+		return nil, err
+	}
+
+	ghCommenter, err := commenter.NewGitHubCommenter(githubClient, dbc, f.GithubCommenterFlags.ExcludeReposCommenting, f.GithubCommenterFlags.IncludeReposCommenting,
+		f.GithubCommenterFlags.GetCommentLimits())
 	if err != nil {
 		log.WithError(err).Error("CRITICAL error initializing GitHub commenter which prevents importing prow jobs")
 		return nil, err
 	}
 
-	return prowloader.New(
-		ctx,
+	prowLoader := prowloader.New(
 		dbc,
 		gcsClient,
 		bigQueryClient,
 		f.GoogleCloudFlags.StorageBucket,
 		githubClient,
-		f.ModeFlags.GetVariantManager(),
-		f.ModeFlags.GetSyntheticTestManager(),
+		variantManager,
+		syntheticTestManager,
+		failureClassifier,
 		f.Releases,
 		sippyConfig,
-		ghCommenter), nil
+		ghCommenter)
+	prowLoader.SetStoreJUnit(f.StoreJUnit)
+	prowLoader.SetTestLogRetention(time.Duration(f.TestLogRetentionDays) * 24 * time.Hour)
+	return prowLoader, nil
+}
+
+// experimentalJobs returns the jobs configured as experimental in any release.
+func experimentalJobs(sippyConfig *v1.SippyConfig) []string {
+	var jobs []string
+	for _, releaseConfig := range sippyConfig.Releases {
+		jobs = append(jobs, releaseConfig.ExperimentalJobs...)
+	}
+	return jobs
+}
+
+// invalidVariantsByRelease returns the variants configured as invalid for each release.
+func invalidVariantsByRelease(sippyConfig *v1.SippyConfig) map[string][]string {
+	invalidVariants := map[string][]string{}
+	for release, releaseConfig := range sippyConfig.Releases {
+		if len(releaseConfig.InvalidVariants) > 0 {
+			invalidVariants[release] = releaseConfig.InvalidVariants
+		}
+	}
+	return invalidVariants
+}
+
+// loadedTables returns the tables the loaders write to.
+func (f *LoadFlags) loadedTables() []string {
+	tables := sets.NewString()
+	for _, l := range f.Loaders {
+		tables.Insert(loaderTables[l]...)
+	}
+	return tables.List()
 }
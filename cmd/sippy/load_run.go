@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+type LoadRunFlags struct {
+	*LoadFlags
+
+	Replace bool
+}
+
+func NewLoadRunFlags() *LoadRunFlags {
+	f := NewLoadFlags()
+	// the github loader provides the client used to look up a presubmit's pull request
+	f.Loaders = []string{"prow", "github"}
+	return &LoadRunFlags{LoadFlags: f}
+}
+
+func (f *LoadRunFlags) BindFlags(fs *pflag.FlagSet) {
+	f.ConfigFlags.BindFlags(fs)
+	f.DBFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+	f.ModeFlags.BindFlags(fs)
+
+	fs.StringArrayVar(&f.Releases, "release", f.Releases, "Which releases the run may belong to (one per arg instance), defaults to all configured releases")
+	fs.BoolVar(&f.Replace, "replace", f.Replace, "Delete the run and import it again if it was already imported")
+	fs.BoolVar(&f.StoreJUnit, "store-junit", f.StoreJUnit, "Keep the run's gzipped junit files in the DB, so sippy reprocess doesn't read them from GCS")
+	fs.IntVar(&f.TestLogRetentionDays, "test-log-retention-days", f.TestLogRetentionDays, "Keep the compressed failure output and system-out of the run's failed tests if not 0, sippy load deletes them after this many days")
+}
+
+func NewLoadRunCommand() *cobra.Command {
+	f := NewLoadRunFlags()
+
+	cmd := &cobra.Command{
+		Use:   "load-run <gcs-path-or-prowjob-url>",
+		Short: "Import a single job run, logging how it's parsed",
+		Long: "Import a single job run, given its path in the GCS bucket (logs/<job>/<build id>), or a gs://, prow or " +
+			"gcsweb URL for it. Logging is verbose, showing which suites were imported or skipped, the variants " +
+			"identified, and the synthetic tests created, to debug why a run looks wrong.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.SetLevel(log.DebugLevel)
+			log.AddHook(logging.RequestIDHook{RequestID: logging.NewRequestID()})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			defer cancel()
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			config, err := f.ConfigFlags.GetConfig()
+			if err != nil {
+				return err
+			}
+			if len(f.Releases) == 0 {
+				for release := range config.Releases {
+					f.Releases = append(f.Releases, release)
+				}
+				sort.Strings(f.Releases)
+			}
+
+			prowLoader, err := f.prowLoader(ctx, dbc, config)
+			if err != nil {
+				return err
+			}
+			if err := prowLoader.LoadRun(ctx, args[0], f.Replace); err != nil {
+				return errors.WithMessage(err, "could not import job run")
+			}
+			log.Info("job run imported, refresh the materialized views to see it in reports")
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -3,9 +3,14 @@ package main
 import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/openshift/sippy/pkg/logging"
 )
 
-var logLevel = "info"
+var (
+	logLevel  = "info"
+	logFormat = logging.FormatText
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -17,32 +22,39 @@ platform, etc).`,
 }
 
 func main() {
-	// Set log level
-	level, err := log.ParseLevel(logLevel)
-	if err != nil {
-		log.WithError(err).Fatal("cannot parse log-level")
+	// Flags are only parsed once the command executes, so until then log with the defaults
+	if err := logging.Configure(logLevel, logFormat); err != nil {
+		log.WithError(err).Fatal("cannot configure logging")
+	}
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return logging.Configure(logLevel, logFormat)
 	}
-	log.SetLevel(level)
-	log.Debug("debug logging enabled")
-
-	// Add some millisecond precision to log timestamps, useful for debugging performance.
-	formatter := new(log.TextFormatter)
-	formatter.TimestampFormat = "2006-01-02T15:04:05.999Z07:00"
-	formatter.FullTimestamp = true
-	formatter.DisableColors = false
-	log.SetFormatter(formatter)
 
 	rootCmd.AddCommand(
 		NewServeCommand(),
 		NewLoadCommand(),
+		NewLoadRunCommand(),
+		NewReprocessCommand(),
 		NewSnapshotCommand(),
 		NewRefreshCommand(),
+		NewExportCommand(),
+		NewAnalyzeCommand(),
+		NewSeedCommand(),
+		NewDeleteReleaseCommand(),
+		NewCompactCommand(),
+		NewArchiveCommand(),
+		NewReportCommand(),
+		NewVerifyConfigCommand(),
+		NewFlagsCommand(),
 	)
 
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
 		"Log level (trace,debug,info,warn,error) (default info)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormat,
+		"Log format (text,json)")
+	registerCompletions(rootCmd)
 
-	err = rootCmd.Execute()
+	err := rootCmd.Execute()
 	if err != nil {
 		log.WithError(err).Fatal("could not execute root command")
 	}
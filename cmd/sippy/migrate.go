@@ -16,7 +16,7 @@ func init() {
 		Use:   "migrate",
 		Short: "Migrates or initializes the PostgreSQL database to the latest schema.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			dbc, err := db.New(f.DSN, gormlogger.LogLevel(f.LogLevel))
+			dbc, err := db.New(f.DSN, gormlogger.LogLevel(f.LogLevel), f.StatementCacheSize)
 			if err != nil {
 				return errors.WithMessage(err, "could not connect to db")
 			}
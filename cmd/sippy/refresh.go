@@ -1,26 +1,31 @@
 package main
 
 import (
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/notify"
 	"github.com/openshift/sippy/pkg/sippyserver"
 )
 
 type RefreshFlags struct {
 	DBFlags            *flags.PostgresFlags
+	ConfigFlags        *flags.ConfigFlags
 	RefreshOnlyIfEmpty bool
 }
 
 func NewRefreshFlags() *RefreshFlags {
 	return &RefreshFlags{
-		DBFlags: flags.NewPostgresDatabaseFlags(),
+		DBFlags:     flags.NewPostgresDatabaseFlags(),
+		ConfigFlags: flags.NewConfigFlags(),
 	}
 }
 
 func (f *RefreshFlags) BindFlags(fs *pflag.FlagSet) {
 	f.DBFlags.BindFlags(fs)
+	f.ConfigFlags.BindFlags(fs)
 	fs.BoolVar(&f.RefreshOnlyIfEmpty, "refresh-only-if-empty", f.RefreshOnlyIfEmpty, "only refresh matviews if they're empty")
 }
 
@@ -35,8 +40,17 @@ func NewRefreshCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			config, err := f.ConfigFlags.GetConfig()
+			if err != nil {
+				return err
+			}
+			notifier, err := notify.NewNotifier(config.Notifications)
+			if err != nil {
+				return errors.WithMessage(err, "could not create notifier")
+			}
 			pinnedDateTime := f.DBFlags.GetPinnedTime()
-			sippyserver.RefreshData(dbc, pinnedDateTime, f.RefreshOnlyIfEmpty)
+			sippyserver.RefreshData(dbc, "refresh", pinnedDateTime, f.RefreshOnlyIfEmpty, config.RegressionDetection,
+				config.SilentJobDetection, notifier)
 			return nil
 		},
 	}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/handoff"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+type HandoffFlags struct {
+	DBFlags *flags.PostgresFlags
+
+	Window time.Duration
+}
+
+func NewHandoffFlags() *HandoffFlags {
+	return &HandoffFlags{
+		DBFlags: flags.NewPostgresDatabaseFlags(),
+		Window:  handoff.DefaultWindow,
+	}
+}
+
+func (f *HandoffFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	fs.DurationVar(&f.Window, "window", f.Window, "How far back the report looks")
+}
+
+func NewReportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate reports from the database",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(NewHandoffCommand())
+	return cmd
+}
+
+func NewHandoffCommand() *cobra.Command {
+	f := NewHandoffFlags()
+
+	cmd := &cobra.Command{
+		Use:   "handoff",
+		Short: "Print a Markdown summary of the last day for the on-call handoff",
+		Long: "Print the regressions opened and resolved, the payloads of each stream, and when the loaders last " +
+			"loaded data, over the last --window, as Markdown for pasting into the TRT handoff doc.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			report, err := handoff.Build(dbc, util.GetReportEnd(f.DBFlags.GetPinnedTime()), f.Window)
+			if err != nil {
+				return errors.WithMessage(err, "could not build handoff report")
+			}
+			out, err := handoff.Render(report)
+			if err != nil {
+				return errors.WithMessage(err, "could not render handoff report")
+			}
+			fmt.Print(out)
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type ReprocessFlags struct {
+	*LoadFlags
+
+	// Since is the date runs started on or after are reprocessed, when no runs are given.
+	Since string
+}
+
+func NewReprocessFlags() *ReprocessFlags {
+	f := NewLoadFlags()
+	f.Loaders = []string{"prow"}
+	return &ReprocessFlags{LoadFlags: f}
+}
+
+func (f *ReprocessFlags) BindFlags(fs *pflag.FlagSet) {
+	f.ConfigFlags.BindFlags(fs)
+	f.DBFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+	f.ModeFlags.BindFlags(fs)
+
+	fs.StringArrayVar(&f.Releases, "release", f.Releases, "Which releases' runs to reprocess with --since (one per arg instance), defaults to all configured releases")
+	fs.StringVar(&f.Since, "since", f.Since, "Reprocess the runs started on or after this date (YYYY-MM-DD)")
+}
+
+func NewReprocessCommand() *cobra.Command {
+	f := NewReprocessFlags()
+
+	cmd := &cobra.Command{
+		Use:   "reprocess [run-id...]",
+		Short: "Parse imported job runs' junit files again, replacing their tests",
+		Long: "Parse the junit files of imported job runs again and recreate their synthetic tests, replacing the " +
+			"runs' tests and results, i.e. after the parsing, suite stripping or synthetic tests changed. The junit " +
+			"files kept by load --store-junit are used, otherwise they're read from the paths recorded when the run " +
+			"was imported, so the bucket isn't listed again. Runs are given by ID, or by --since.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (f.Since == "") {
+				return fmt.Errorf("give either the runs to reprocess or --since")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 24*time.Hour)
+			defer cancel()
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			config, err := f.ConfigFlags.GetConfig()
+			if err != nil {
+				return err
+			}
+			if len(f.Releases) == 0 {
+				for release := range config.Releases {
+					f.Releases = append(f.Releases, release)
+				}
+				sort.Strings(f.Releases)
+			}
+
+			prowLoader, err := f.prowLoader(ctx, dbc, config)
+			if err != nil {
+				return err
+			}
+
+			ids := make([]uint, 0, len(args))
+			for _, arg := range args {
+				id, err := strconv.ParseUint(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid run ID %q", arg)
+				}
+				ids = append(ids, uint(id))
+			}
+			if f.Since != "" {
+				since, err := time.Parse("2006-01-02", f.Since)
+				if err != nil {
+					return errors.WithMessage(err, "invalid --since")
+				}
+				if ids, err = prowLoader.RunsToReprocess(ctx, f.Releases, since); err != nil {
+					return errors.WithMessage(err, "could not list job runs to reprocess")
+				}
+			}
+
+			failed := 0
+			for i, id := range ids {
+				if err := prowLoader.ReprocessRun(ctx, id); err != nil {
+					log.WithError(err).Errorf("could not reprocess job run %d", id)
+					failed++
+				}
+				if (i+1)%100 == 0 {
+					log.Infof("reprocessed %d of %d job runs", i+1, len(ids))
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("could not reprocess %d of %d job runs", failed, len(ids))
+			}
+			log.Infof("%d job runs reprocessed, refresh the materialized views to see them in reports", len(ids))
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/notify"
+	"github.com/openshift/sippy/pkg/seed"
+	"github.com/openshift/sippy/pkg/sippyserver"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+type SeedFlags struct {
+	DBFlags *flags.PostgresFlags
+	Options seed.Options
+}
+
+func NewSeedFlags() *SeedFlags {
+	return &SeedFlags{
+		DBFlags: flags.NewPostgresDatabaseFlags(),
+		Options: seed.DefaultOptions(),
+	}
+}
+
+func (f *SeedFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	fs.StringSliceVar(&f.Options.Releases, "release", f.Options.Releases, "Which releases to generate (one per arg instance, or comma separated)")
+	fs.IntVar(&f.Options.Jobs, "jobs", f.Options.Jobs, "Number of periodic jobs per release")
+	fs.IntVar(&f.Options.Tests, "tests", f.Options.Tests, "Number of e2e tests, in addition to the install and upgrade tests")
+	fs.IntVar(&f.Options.Days, "days", f.Options.Days, "Days of history to generate")
+	fs.IntVar(&f.Options.RunsPerDay, "runs-per-day", f.Options.RunsPerDay, "How many times each job runs a day")
+	fs.IntVar(&f.Options.PayloadsPerDay, "payloads-per-day", f.Options.PayloadsPerDay, "How many nightly payloads each release gets a day")
+	fs.Int64Var(&f.Options.Seed, "seed", f.Options.Seed, "Random seed, the same seed generates the same data")
+}
+
+func NewSeedCommand() *cobra.Command {
+	f := NewSeedFlags()
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Populate a fresh database with synthetic jobs, runs, tests and payloads",
+		Long: "Populate a fresh database with realistic synthetic jobs, job runs, test results and payloads, so sippy " +
+			"can be developed and tested without GCP credentials. The schema is migrated first, and the materialized " +
+			"views refreshed after.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := f.Options.Validate(); err != nil {
+				return err
+			}
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return errors.WithMessage(err, "could not get db client")
+			}
+
+			pinnedDateTime := f.DBFlags.GetPinnedTime()
+			if err := dbc.UpdateSchema(pinnedDateTime); err != nil {
+				return errors.WithMessage(err, "could not migrate db")
+			}
+
+			if err := seed.Load(dbc, seed.Generate(f.Options, util.GetReportEnd(pinnedDateTime))); err != nil {
+				return errors.WithMessage(err, "could not seed db")
+			}
+
+			sippyserver.RefreshData(dbc, "seed", pinnedDateTime, false,
+				v1.RegressionDetectionConfig{Releases: f.Options.Releases}, v1.SilentJobDetectionConfig{},
+				notify.NewNoopNotifier())
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"io/fs"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"cloud.google.com/go/storage"
@@ -16,12 +20,19 @@ import (
 
 	resources "github.com/openshift/sippy"
 	"github.com/openshift/sippy/pkg/apis/cache"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
 	"github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/federation"
 	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/notify"
 	"github.com/openshift/sippy/pkg/sippyserver"
 	"github.com/openshift/sippy/pkg/sippyserver/metrics"
+	"github.com/openshift/sippy/pkg/testidentification"
+	"github.com/openshift/sippy/pkg/tracing"
 	"github.com/openshift/sippy/pkg/util"
 )
 
@@ -31,8 +42,10 @@ var (
 )
 
 type ServerFlags struct {
+	ArchiveFlags     *flags.ArchiveFlags
 	BigQueryFlags    *flags.BigQueryFlags
 	CacheFlags       *flags.CacheFlags
+	ConfigFlags      *flags.ConfigFlags
 	DBFlags          *flags.PostgresFlags
 	GoogleCloudFlags *flags.GoogleCloudFlags
 	ModeFlags        *flags.ModeFlags
@@ -40,23 +53,41 @@ type ServerFlags struct {
 	ListenAddr           string
 	MetricsAddr          string
 	CRTimeRoundingFactor time.Duration
+	ReadinessMaxDataAge  time.Duration
+	Tenant               string
+	RefreshInterval      time.Duration
+	RefreshJitter        time.Duration
+	ShutdownTimeout      time.Duration
+	WarmCacheReleases    int
+	WarmCachePaths       []string
+	AsyncWorkers         int
+	ProfilingToken       string
 }
 
 func NewServerFlags() *ServerFlags {
 	return &ServerFlags{
-		BigQueryFlags:    flags.NewBigQueryFlags(),
-		CacheFlags:       flags.NewCacheFlags(),
-		DBFlags:          flags.NewPostgresDatabaseFlags(),
-		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
-		ModeFlags:        flags.NewModeFlags(),
-		ListenAddr:       ":8080",
-		MetricsAddr:      ":2112",
+		ArchiveFlags:      flags.NewArchiveFlags(),
+		BigQueryFlags:     flags.NewBigQueryFlags(),
+		CacheFlags:        flags.NewCacheFlags(),
+		ConfigFlags:       flags.NewConfigFlags(),
+		DBFlags:           flags.NewPostgresDatabaseFlags(),
+		GoogleCloudFlags:  flags.NewGoogleCloudFlags(),
+		ModeFlags:         flags.NewModeFlags(),
+		ListenAddr:        ":8080",
+		MetricsAddr:       ":2112",
+		RefreshJitter:     5 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
+		WarmCacheReleases: 2,
+		WarmCachePaths:    sippyserver.DefaultWarmPaths,
+		AsyncWorkers:      2,
 	}
 }
 
 func (f *ServerFlags) BindFlags(flagSet *pflag.FlagSet) {
+	f.ArchiveFlags.BindFlags(flagSet)
 	f.BigQueryFlags.BindFlags(flagSet)
 	f.CacheFlags.BindFlags(flagSet)
+	f.ConfigFlags.BindFlags(flagSet)
 	f.DBFlags.BindFlags(flagSet)
 	f.GoogleCloudFlags.BindFlags(flagSet)
 	f.ModeFlags.BindFlags(flagSet)
@@ -65,7 +96,81 @@ func (f *ServerFlags) BindFlags(flagSet *pflag.FlagSet) {
 	flagSet.StringVar(&f.MetricsAddr, "listen-metrics", f.MetricsAddr, "The address to serve prometheus metrics on (default :2112)")
 	factorUsage := fmt.Sprintf("Set the rounding factor for component readiness release time. The time will be rounded down to the nearest multiple of the factor. Maximum value is %v", maxCRTimeRoundingFactor)
 	flagSet.DurationVar(&f.CRTimeRoundingFactor, "component-readiness-time-rounding-factor", defaultCRTimeRoundingFactor, factorUsage)
+	flagSet.StringVar(&f.Tenant, "tenant", f.Tenant, "Scope every API request to this tenant, for a deployment serving a single product")
+	flagSet.DurationVar(&f.ReadinessMaxDataAge, "readiness-max-data-age", f.ReadinessMaxDataAge, "Report not ready on /readyz when the newest prow job run is older than this (default 0, disabled)")
+	flagSet.DurationVar(&f.RefreshInterval, "refresh-interval", f.RefreshInterval, "Refresh materialized views and detect regressions on this interval while serving (default 0, disabled)")
+	flagSet.DurationVar(&f.RefreshJitter, "refresh-jitter", f.RefreshJitter, "Delay each scheduled refresh by a random duration up to this, so replicas don't refresh at once")
+	flagSet.IntVar(&f.WarmCacheReleases, "warm-cache-releases", f.WarmCacheReleases, "After a scheduled refresh, cache the reports of this many of the newest releases (0 disables)")
+	flagSet.StringArrayVar(&f.WarmCachePaths, "warm-cache-path", f.WarmCachePaths, "A report to cache for each release after a scheduled refresh, {release} is replaced with the release (one per arg instance)")
+	flagSet.IntVar(&f.AsyncWorkers, "async-workers", f.AsyncWorkers, "How many async API jobs, i.e. large exports, to run at once (0 disables creating them)")
+	flagSet.StringVar(&f.ProfilingToken, "profiling-token", "", "Serve pprof profiles under /debug/pprof/ to requests with this bearer token, defaults to $SIPPY_PROFILING_TOKEN (disabled when empty)")
+	flagSet.DurationVar(&f.ShutdownTimeout, "shutdown-timeout", f.ShutdownTimeout, "How long to wait for in-flight requests to finish on SIGTERM")
+}
+
+// getVariantManager returns the variant manager for the mode, excluding never-stable jobs and the variants the config
+// marks invalid for each release.
+func (f *ServerFlags) getVariantManager(dbc *db.DB, sippyConfig *v1.SippyConfig) (testidentification.VariantManager, error) {
+	variantManager, err := f.ModeFlags.GetVariantManager()
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't get variant manager")
+	}
+
+	neverStableJobs, err := query.NeverStableJobNames(dbc)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't query never-stable jobs")
+	}
+	variantManager = testidentification.NewNeverStableVariantManager(variantManager, neverStableJobs)
+	return testidentification.NewReleaseVariantManager(variantManager, invalidVariantsByRelease(sippyConfig)), nil
+}
+
+// liveConfig is the sippy config, and the notifier built from it, that SIGHUP reloads while serving. The variant
+// manager is reloaded with it, see testidentification.ReloadableVariantManager.
+type liveConfig struct {
+	lock     sync.RWMutex
+	config   *v1.SippyConfig
+	notifier notify.Notifier
+}
+
+func (c *liveConfig) get() (*v1.SippyConfig, notify.Notifier) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.config, c.notifier
+}
 
+func (c *liveConfig) set(config *v1.SippyConfig, notifier notify.Notifier) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.config = config
+	c.notifier = notifier
+}
+
+// reload reads the config and variant rules again, replacing the live ones only if all of them are valid.
+func (f *ServerFlags) reload(dbc *db.DB, live *liveConfig, variantManager *testidentification.ReloadableVariantManager) error {
+	sippyConfig, err := f.ConfigFlags.GetConfig()
+	if err != nil {
+		return errors.WithMessage(err, "couldn't get sippy config")
+	}
+	vm, err := f.getVariantManager(dbc, sippyConfig)
+	if err != nil {
+		return err
+	}
+	notifier, err := notify.NewNotifier(sippyConfig.Notifications)
+	if err != nil {
+		return errors.WithMessage(err, "couldn't create notifier")
+	}
+
+	variantManager.Set(vm)
+	live.set(sippyConfig, notifier)
+	return nil
+}
+
+// GetProfilingToken returns the profiling token, from the flag or the environment. The environment variable isn't the
+// flag's default, so the token isn't printed with the command's usage.
+func (f *ServerFlags) GetProfilingToken() string {
+	if f.ProfilingToken != "" {
+		return f.ProfilingToken
+	}
+	return os.Getenv("SIPPY_PROFILING_TOKEN")
 }
 
 func NewServeCommand() *cobra.Command {
@@ -75,6 +180,12 @@ func NewServeCommand() *cobra.Command {
 		Use:   "serve",
 		Short: "Run the sippy server",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			shutdownTracing, err := tracing.Init(context.Background(), "sippy")
+			if err != nil {
+				return errors.WithMessage(err, "couldn't initialize tracing")
+			}
+			defer shutdownTracing(context.Background()) //nolint:errcheck
+
 			dbc, err := f.DBFlags.GetDBClient()
 			if err != nil {
 				return errors.WithMessage(err, "couldn't get DB client")
@@ -103,6 +214,11 @@ func NewServeCommand() *cobra.Command {
 				}
 			}
 
+			archiveStore, err := f.ArchiveFlags.GetStore(context.TODO(), f.GoogleCloudFlags)
+			if err != nil {
+				return errors.WithMessage(err, "couldn't get archive store")
+			}
+
 			// Make sure the db is intialized, otherwise let the user know:
 			prowJobs := []models.ProwJob{}
 			res := dbc.DB.Find(&prowJobs).Limit(1)
@@ -110,6 +226,12 @@ func NewServeCommand() *cobra.Command {
 				return errors.WithMessage(err, "error querying for a ProwJob, database may need to be initialized with --init-database")
 			}
 
+			live := &liveConfig{}
+			variantManager := testidentification.NewReloadableVariantManager(nil)
+			if err := f.reload(dbc, live, variantManager); err != nil {
+				return err
+			}
+
 			webRoot, err := fs.Sub(resources.SippyNG, "sippy-ng/build")
 			if err != nil {
 				log.WithError(err).Fatal("could not load frontend")
@@ -121,7 +243,7 @@ func NewServeCommand() *cobra.Command {
 				f.ModeFlags.GetServerMode(),
 				f.ListenAddr,
 				f.ModeFlags.GetSyntheticTestManager(),
-				f.ModeFlags.GetVariantManager(),
+				variantManager,
 				webRoot,
 				&resources.Static,
 				dbc,
@@ -129,13 +251,25 @@ func NewServeCommand() *cobra.Command {
 				gcsClient,
 				bigQueryClient,
 				pinnedDateTime,
-				cacheClient,
+				f.CacheFlags.GetMemoryCache(cacheClient),
 				f.CRTimeRoundingFactor,
+				f.ReadinessMaxDataAge,
 			)
+			server.SetTenant(f.Tenant)
+			server.SetCacheWarming(f.WarmCacheReleases, f.WarmCachePaths)
+			server.SetAsyncWorkers(f.AsyncWorkers)
+			server.SetProfilingToken(f.GetProfilingToken())
+			server.SetArchiveStore(archiveStore)
+			// federated instances are read at startup, changing them requires a restart
+			sippyConfig, _ := live.get()
+			server.SetFederation(federation.New(sippyConfig.Federation))
+			server.SetReleaseMilestones(sippyConfig.Releases)
+			server.SetTestRenames(sippyConfig.TestRenames)
 
 			if f.MetricsAddr != "" {
 				// Do an immediate metrics update
-				err = metrics.RefreshMetricsDB(dbc, bigQueryClient, f.GoogleCloudFlags.StorageBucket, f.ModeFlags.GetVariantManager(), util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor})
+				_, notifier := live.get()
+				err = metrics.RefreshMetricsDB(context.Background(), dbc, bigQueryClient, f.GoogleCloudFlags.StorageBucket, variantManager, util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor}, notifier)
 				if err != nil {
 					log.WithError(err).Error("error refreshing metrics")
 				}
@@ -148,7 +282,8 @@ func NewServeCommand() *cobra.Command {
 						select {
 						case <-ticker.C:
 							log.Info("tick")
-							err := metrics.RefreshMetricsDB(dbc, bigQueryClient, f.GoogleCloudFlags.StorageBucket, f.ModeFlags.GetVariantManager(), util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor})
+							_, notifier := live.get()
+							err := metrics.RefreshMetricsDB(context.Background(), dbc, bigQueryClient, f.GoogleCloudFlags.StorageBucket, variantManager, util.GetReportEnd(pinnedDateTime), cache.RequestOptions{CRTimeRoundingFactor: f.CRTimeRoundingFactor}, notifier)
 							if err != nil {
 								log.WithError(err).Error("error refreshing metrics")
 							}
@@ -161,15 +296,69 @@ func NewServeCommand() *cobra.Command {
 
 				// Serve our metrics endpoint for prometheus to scrape
 				go func() {
-					http.Handle("/metrics", promhttp.Handler())
-					err := http.ListenAndServe(f.MetricsAddr, nil) //nolint
+					// a mux of its own, as net/http/pprof registers the profiles on the default mux
+					metricsMux := http.NewServeMux()
+					metricsMux.Handle("/metrics", promhttp.Handler())
+					err := http.ListenAndServe(f.MetricsAddr, metricsMux) //nolint
 					if err != nil {
 						panic(err)
 					}
 				}()
 			}
 
+			// SIGTERM and SIGINT drain in-flight requests and stop the server, SIGHUP reloads the config
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+			defer stop()
+			hangup := make(chan os.Signal, 1)
+			signal.Notify(hangup, syscall.SIGHUP)
+			defer signal.Stop(hangup)
+			go func() {
+				for {
+					select {
+					case <-hangup:
+						log.Info("received SIGHUP, reloading config")
+						if err := f.reload(dbc, live, variantManager); err != nil {
+							log.WithError(err).Error("couldn't reload config, continuing with the previous one")
+						} else {
+							sippyConfig, _ := live.get()
+							server.SetReleaseMilestones(sippyConfig.Releases)
+							server.SetTestRenames(sippyConfig.TestRenames)
+							log.Info("config reloaded")
+						}
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+
+			// the scheduler also starts the refreshes requested from the API, so it's created even when not run
+			scheduler := sippyserver.NewRefreshScheduler(f.RefreshInterval, f.RefreshJitter, func(trigger string) {
+				sippyConfig, notifier := live.get()
+				sippyserver.RefreshData(dbc, trigger, pinnedDateTime, false, sippyConfig.RegressionDetection,
+					sippyConfig.SilentJobDetection, notifier)
+				server.WarmCache(ctx)
+			})
+			server.SetRefreshScheduler(scheduler)
+			if f.RefreshInterval > 0 {
+				go scheduler.Run(ctx)
+			}
+
+			shutdownComplete := make(chan struct{})
+			go func() {
+				defer close(shutdownComplete)
+				<-ctx.Done()
+				log.Infof("shutting down, waiting up to %s for in-flight requests", f.ShutdownTimeout)
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), f.ShutdownTimeout)
+				defer cancel()
+				if err := server.Shutdown(shutdownCtx); err != nil {
+					log.WithError(err).Warning("in-flight requests didn't finish before the shutdown timeout")
+				}
+			}()
+
 			server.Serve()
+			// if the server exited on its own, this lets the shutdown finish
+			stop()
+			<-shutdownComplete
 			return nil
 		},
 	}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/configcheck"
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+const (
+	verifyOutputText = "text"
+	verifyOutputJSON = "json"
+)
+
+type VerifyConfigFlags struct {
+	ConfigFlags      *flags.ConfigFlags
+	ModeFlags        *flags.ModeFlags
+	GoogleCloudFlags *flags.GoogleCloudFlags
+	BigQueryFlags    *flags.BigQueryFlags
+
+	Output          string
+	SkipCredentials bool
+	Timeout         time.Duration
+}
+
+func NewVerifyConfigFlags() *VerifyConfigFlags {
+	return &VerifyConfigFlags{
+		ConfigFlags:      flags.NewConfigFlags(),
+		ModeFlags:        flags.NewModeFlags(),
+		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
+		BigQueryFlags:    flags.NewBigQueryFlags(),
+		Output:           verifyOutputText,
+		Timeout:          30 * time.Second,
+	}
+}
+
+func (f *VerifyConfigFlags) BindFlags(fs *pflag.FlagSet) {
+	f.ConfigFlags.BindFlags(fs)
+	f.ModeFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+	f.BigQueryFlags.BindFlags(fs)
+	fs.StringVarP(&f.Output, "output", "o", f.Output, "Report format, text or json")
+	fs.BoolVar(&f.SkipCredentials, "skip-credentials", f.SkipCredentials, "Only verify the config, without contacting GCS, BigQuery, GitHub or Jira")
+	fs.DurationVar(&f.Timeout, "timeout", f.Timeout, "How long to wait for each service when verifying credentials")
+}
+
+func (f *VerifyConfigFlags) Validate() error {
+	if f.Output != verifyOutputText && f.Output != verifyOutputJSON {
+		return fmt.Errorf("--output must be %s or %s", verifyOutputText, verifyOutputJSON)
+	}
+	return nil
+}
+
+func NewVerifyConfigCommand() *cobra.Command {
+	f := NewVerifyConfigFlags()
+
+	cmd := &cobra.Command{
+		Use:   "verify-config",
+		Short: "Validate the sippy config and credentials before deploying",
+		Long: "Validate the sippy config: release definitions, variant rules, synthetic tests, notification, alerting " +
+			"and digest settings. Then verify the GCS, BigQuery, GitHub (GITHUB_TOKEN) and Jira (JIRA_TOKEN) credentials " +
+			"can reach their services. Every check is reported, and the command fails if any of them did.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+
+			r := configcheck.NewReport()
+			config, err := f.ConfigFlags.GetConfig()
+			r.Add("config", err)
+			if err == nil {
+				configcheck.CheckConfig(r, config)
+				vm, err := f.ModeFlags.GetVariantManager()
+				configcheck.CheckVariants(r, vm, err, config)
+
+				if !f.SkipCredentials {
+					f.checkCredentials(cmd.Context(), r, config.JiraFiling)
+				}
+			}
+
+			if f.Output == verifyOutputJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				err = enc.Encode(r)
+			} else {
+				err = r.WriteText(os.Stdout)
+			}
+			if err != nil {
+				return err
+			}
+
+			if !r.Passed {
+				return fmt.Errorf("config verification failed")
+			}
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{verifyOutputText, verifyOutputJSON}, cobra.ShellCompDirectiveNoFileComp)) //nolint:errcheck
+
+	return cmd
+}
+
+func (f *VerifyConfigFlags) checkCredentials(ctx context.Context, r *configcheck.Report, jira v1.JiraFilingConfig) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	withTimeout := func(check func(ctx context.Context)) {
+		ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+		check(ctx)
+	}
+
+	withTimeout(func(ctx context.Context) {
+		configcheck.CheckGCS(ctx, r, f.GoogleCloudFlags.ServiceAccountCredentialFile, f.GoogleCloudFlags.StorageBucket)
+	})
+	withTimeout(func(ctx context.Context) {
+		configcheck.CheckBigQuery(ctx, r, f.GoogleCloudFlags.ServiceAccountCredentialFile,
+			f.BigQueryFlags.BigQueryProject, f.BigQueryFlags.BigQueryDataset)
+	})
+
+	client := &http.Client{Timeout: f.Timeout}
+	configcheck.CheckGitHub(ctx, r, client, "https://api.github.com", os.Getenv("GITHUB_TOKEN"))
+	configcheck.CheckJira(ctx, r, client, jira, os.Getenv("JIRA_TOKEN"))
+}
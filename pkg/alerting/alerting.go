@@ -0,0 +1,129 @@
+package alerting
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// Alert is a condition that is either firing or resolved. Alerts with the same key are the same alert, so sending
+// it repeatedly while firing doesn't page again.
+type Alert struct {
+	Key     string
+	Name    string
+	Summary string
+	Firing  bool
+	Labels  map[string]string
+}
+
+// Sender delivers alerts, resolving those that are no longer firing.
+type Sender interface {
+	Send(alerts []Alert) error
+}
+
+// NewSenders returns the senders configured, or none if alerting isn't configured.
+func NewSenders(config v1.AlertingConfig) ([]Sender, error) {
+	var senders []Sender
+	if config.PagerDuty {
+		routingKey := os.Getenv("SIPPY_PAGERDUTY_ROUTING_KEY")
+		if routingKey == "" {
+			return nil, fmt.Errorf("PagerDuty alerting requires the SIPPY_PAGERDUTY_ROUTING_KEY environment variable")
+		}
+		senders = append(senders, newPagerDutySender(pagerDutyEventsURL, routingKey))
+	}
+	if config.AlertmanagerURL != "" {
+		senders = append(senders, newAlertmanagerSender(config.AlertmanagerURL))
+	}
+	return senders, nil
+}
+
+// Evaluate checks the configured releases' blocking jobs and payload streams, and sends the resulting alerts.
+func Evaluate(dbc *db.DB, config v1.AlertingConfig, releases map[string]v1.ReleaseConfig, senders []Sender, reportEnd time.Time) error {
+	if len(senders) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(releases))
+	for release := range releases {
+		names = append(names, release)
+	}
+	sort.Strings(names)
+
+	var alerts []Alert
+	for _, release := range names {
+		if config.BlockingJobPassThreshold > 0 && len(releases[release].BlockingJobs) > 0 {
+			jobs, err := api.JobReportsFromDB(dbc, release, "default", nil, time.Time{}, time.Time{}, time.Time{}, reportEnd, false)
+			if err != nil {
+				return fmt.Errorf("error querying %s jobs: %w", release, err)
+			}
+			alerts = append(alerts, blockingJobAlerts(release, jobs, releases[release].BlockingJobs, config.BlockingJobPassThreshold)...)
+		}
+
+		if config.HoursWithoutAcceptedPayload > 0 {
+			lastAccepted, err := query.GetLastAcceptedByArchitectureAndStream(dbc.DB, release, reportEnd)
+			if err != nil {
+				return fmt.Errorf("error querying %s accepted payloads: %w", release, err)
+			}
+			alerts = append(alerts, payloadAlerts(release, lastAccepted, reportEnd, config.HoursWithoutAcceptedPayload)...)
+		}
+	}
+
+	log.Infof("sending %d alerts", len(alerts))
+	var errs []error
+	for _, sender := range senders {
+		if err := sender.Send(alerts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error sending alerts: %v", errs)
+	}
+	return nil
+}
+
+func blockingJobAlerts(release string, jobs []apitype.Job, blockingJobs []string, threshold float64) []Alert {
+	blocking := sets.NewString(blockingJobs...)
+
+	var alerts []Alert
+	for _, job := range jobs {
+		if !blocking.Has(job.Name) || job.CurrentRuns == 0 {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Key:  fmt.Sprintf("sippy-blocking-job-%s", job.Name),
+			Name: "SippyBlockingJobPassRateLow",
+			Summary: fmt.Sprintf("Release blocking job %s is passing %.1f%% of %d runs, below %.1f%%",
+				job.Name, job.CurrentPassPercentage, job.CurrentRuns, threshold),
+			Firing: job.CurrentPassPercentage < threshold,
+			Labels: map[string]string{"release": release, "job": job.Name},
+		})
+	}
+	return alerts
+}
+
+func payloadAlerts(release string, lastAccepted []models.ReleaseTag, now time.Time, hours float64) []Alert {
+	var alerts []Alert
+	for _, tag := range lastAccepted {
+		since := now.Sub(tag.ReleaseTime).Hours()
+		alerts = append(alerts, Alert{
+			Key:  fmt.Sprintf("sippy-payload-%s-%s-%s", release, tag.Stream, tag.Architecture),
+			Name: "SippyNoAcceptedPayload",
+			Summary: fmt.Sprintf("No accepted %s %s payload for %s in %.0f hours, the last was %s",
+				tag.Stream, tag.Architecture, release, since, tag.ReleaseTag),
+			Firing: since > hours,
+			Labels: map[string]string{"release": release, "stream": tag.Stream, "architecture": tag.Architecture},
+		})
+	}
+	return alerts
+}
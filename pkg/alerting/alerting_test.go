@@ -0,0 +1,99 @@
+package alerting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestBlockingJobAlerts(t *testing.T) {
+	jobs := []apitype.Job{
+		{Name: "blocking-failing", CurrentPassPercentage: 50, CurrentRuns: 10},
+		{Name: "blocking-passing", CurrentPassPercentage: 95, CurrentRuns: 10},
+		{Name: "blocking-no-runs", CurrentRuns: 0},
+		{Name: "informing-failing", CurrentPassPercentage: 0, CurrentRuns: 10},
+	}
+
+	alerts := blockingJobAlerts("4.15", jobs, []string{"blocking-failing", "blocking-passing", "blocking-no-runs"}, 80)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "sippy-blocking-job-blocking-failing", alerts[0].Key)
+	assert.True(t, alerts[0].Firing)
+	assert.Equal(t, map[string]string{"release": "4.15", "job": "blocking-failing"}, alerts[0].Labels)
+	assert.Equal(t, "sippy-blocking-job-blocking-passing", alerts[1].Key)
+	assert.False(t, alerts[1].Firing)
+}
+
+func TestPayloadAlerts(t *testing.T) {
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	lastAccepted := []models.ReleaseTag{
+		{ReleaseTag: "4.15.0-0.nightly-1", Stream: "nightly", Architecture: "amd64", ReleaseTime: now.Add(-30 * time.Hour)},
+		{ReleaseTag: "4.15.0-0.ci-1", Stream: "ci", Architecture: "amd64", ReleaseTime: now.Add(-2 * time.Hour)},
+	}
+
+	alerts := payloadAlerts("4.15", lastAccepted, now, 24)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "sippy-payload-4.15-nightly-amd64", alerts[0].Key)
+	assert.True(t, alerts[0].Firing)
+	assert.Equal(t, "No accepted nightly amd64 payload for 4.15 in 30 hours, the last was 4.15.0-0.nightly-1", alerts[0].Summary)
+	assert.False(t, alerts[1].Firing)
+}
+
+func TestSenders(t *testing.T) {
+	var requests []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	alerts := []Alert{
+		{Key: "firing", Name: "Firing", Summary: "on fire", Firing: true, Labels: map[string]string{"release": "4.15"}},
+		{Key: "resolved", Name: "Resolved"},
+	}
+
+	require.NoError(t, newPagerDutySender(server.URL, "key").Send(alerts))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"routing_key":  "key",
+			"event_action": "trigger",
+			"dedup_key":    "firing",
+			"payload": map[string]interface{}{
+				"summary":        "on fire",
+				"source":         "sippy",
+				"severity":       "error",
+				"custom_details": map[string]interface{}{"release": "4.15"},
+			},
+		},
+		map[string]interface{}{"routing_key": "key", "event_action": "resolve", "dedup_key": "resolved"},
+	}, requests)
+
+	requests = nil
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	am := newAlertmanagerSender(server.URL + "/")
+	am.now = func() time.Time { return now }
+	require.NoError(t, am.Send(alerts))
+	assert.Equal(t, []interface{}{
+		[]interface{}{
+			map[string]interface{}{
+				"labels":      map[string]interface{}{"alertname": "Firing", "release": "4.15"},
+				"annotations": map[string]interface{}{"summary": "on fire"},
+				"endsAt":      "2023-11-01T15:00:00Z",
+			},
+			map[string]interface{}{
+				"labels":      map[string]interface{}{"alertname": "Resolved"},
+				"annotations": map[string]interface{}{"summary": ""},
+				"endsAt":      "2023-11-01T12:00:00Z",
+			},
+		},
+	}, requests)
+}
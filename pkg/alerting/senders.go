@@ -0,0 +1,130 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// alertmanagerTTL keeps firing alerts active in the Alertmanager until the next load re-sends them
+	alertmanagerTTL = 3 * time.Hour
+)
+
+func postJSON(client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+type pagerDutySender struct {
+	url        string
+	routingKey string
+	httpClient *http.Client
+}
+
+func newPagerDutySender(url, routingKey string) *pagerDutySender {
+	return &pagerDutySender{url: url, routingKey: routingKey, httpClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Send triggers an event for every firing alert and resolves the rest, PagerDuty deduplicates them by key.
+func (p *pagerDutySender) Send(alerts []Alert) error {
+	for _, alert := range alerts {
+		event := pagerDutyEvent{RoutingKey: p.routingKey, EventAction: "resolve", DedupKey: alert.Key}
+		if alert.Firing {
+			event.EventAction = "trigger"
+			event.Payload = &pagerDutyPayload{
+				Summary:       alert.Summary,
+				Source:        "sippy",
+				Severity:      "error",
+				CustomDetails: alert.Labels,
+			}
+		}
+		if err := postJSON(p.httpClient, p.url, event); err != nil {
+			return fmt.Errorf("error sending %s to PagerDuty: %w", alert.Key, err)
+		}
+	}
+	return nil
+}
+
+type alertmanagerSender struct {
+	url        string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func newAlertmanagerSender(url string) *alertmanagerSender {
+	return &alertmanagerSender{
+		url:        strings.TrimSuffix(url, "/") + "/api/v2/alerts",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}
+}
+
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// Send posts every alert, resolved alerts end now and firing alerts stay active until the TTL.
+func (a *alertmanagerSender) Send(alerts []Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	now := a.now()
+	amAlerts := make([]alertmanagerAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		labels := map[string]string{"alertname": alert.Name}
+		for k, v := range alert.Labels {
+			labels[k] = v
+		}
+
+		endsAt := now
+		if alert.Firing {
+			endsAt = now.Add(alertmanagerTTL)
+		}
+		amAlerts = append(amAlerts, alertmanagerAlert{
+			Labels:      labels,
+			Annotations: map[string]string{"summary": alert.Summary},
+			EndsAt:      endsAt,
+		})
+	}
+
+	if err := postJSON(a.httpClient, a.url, amAlerts); err != nil {
+		return fmt.Errorf("error sending alerts to the Alertmanager: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package analysis runs sippy reports against the database without the HTTP server, and writes them as JSON, CSV or
+// Markdown, so CI pipelines and cron jobs can consume them.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// Options are shared by every report.
+type Options struct {
+	Release   string
+	ReportEnd time.Time
+	// Limit caps the rows of reports listing tests, zero for no limit.
+	Limit int
+}
+
+// Table is a report's rows, each with a value for every column.
+type Table struct {
+	Report  string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+type report struct {
+	description string
+	run         func(dbc *db.DB, opts Options) (Table, error)
+}
+
+var reports = map[string]report{
+	"top-regressions": {
+		description: "tests regression detection has open, largest pass rate drop first",
+		run:         topRegressions,
+	},
+	"payload-health": {
+		description: "each payload stream's latest payload, its phase, and last week's accepted and rejected counts",
+		run:         payloadHealth,
+	},
+	"component-rollup": {
+		description: "each jira component's test runs, pass rates and regressed tests, most regressed first",
+		run:         componentRollup,
+	},
+}
+
+// Reports returns the names of the reports, and what each reports.
+func Reports() map[string]string {
+	descriptions := map[string]string{}
+	for name, r := range reports {
+		descriptions[name] = r.description
+	}
+	return descriptions
+}
+
+// ReportNames returns the report names, sorted.
+func ReportNames() []string {
+	names := make([]string, 0, len(reports))
+	for name := range reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs the named reports, in order.
+func Run(dbc *db.DB, names []string, opts Options) ([]Table, error) {
+	tables := make([]Table, 0, len(names))
+	for _, name := range names {
+		r, ok := reports[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown report %q, must be one of %v", name, ReportNames())
+		}
+		table, err := r.run(dbc, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error running %s report: %w", name, err)
+		}
+		table.Report = name
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
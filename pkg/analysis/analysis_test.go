@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func testResult(name, component string, currentSuccesses, currentFailures int) apitype.Test {
+	return apitype.Test{
+		Name:                   name,
+		JiraComponent:          component,
+		CurrentSuccesses:       currentSuccesses,
+		CurrentFailures:        currentFailures,
+		CurrentRuns:            currentSuccesses + currentFailures,
+		CurrentPassPercentage:  float64(currentSuccesses) / float64(currentSuccesses+currentFailures) * 100,
+		PreviousSuccesses:      100,
+		PreviousRuns:           100,
+		PreviousPassPercentage: 100,
+		NetImprovement:         float64(currentSuccesses)/float64(currentSuccesses+currentFailures)*100 - 100,
+	}
+}
+
+func TestTopRegressionsTable(t *testing.T) {
+	regressions := []models.TestRegression{
+		{TestName: "worse", BasisPassPercentage: 100, BasisRuns: 100, SamplePassPercentage: 80, SampleRuns: 100},
+		{TestName: "much worse", Variants: pq.StringArray{"aws", "ovn"}, BasisPassPercentage: 100, BasisRuns: 100,
+			SamplePassPercentage: 50, SampleRuns: 100},
+	}
+	tests := []apitype.Test{
+		testResult("much worse", "Networking", 50, 50),
+		testResult("worse", "Storage", 80, 20),
+	}
+
+	table := topRegressionsTable(regressions, tests, Options{})
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, []interface{}{"much worse", "aws,ovn", "Networking", 50.0, 100.0, -50.0, 100, 100, 0.0, 0},
+		table.Rows[0])
+	assert.Equal(t, "worse", table.Rows[1][0])
+
+	table = topRegressionsTable(regressions, tests, Options{Limit: 1})
+	require.Len(t, table.Rows, 1)
+}
+
+func TestComponentRollupTable(t *testing.T) {
+	table := componentRollupTable([]apitype.Test{
+		testResult("a", "Networking", 100, 0),
+		testResult("b", "Networking", 50, 50),
+		testResult("c", "Storage", 100, 0),
+		testResult("d", "", 90, 10),
+	}, []models.TestRegression{
+		{TestName: "b", Variants: pq.StringArray{"aws"}},
+		{TestName: "b", Variants: pq.StringArray{"gcp"}},
+		{TestName: "d"},
+	})
+
+	require.Len(t, table.Rows, 3)
+	assert.Equal(t, []interface{}{"Networking", 2, 1, 200, 75.0, 200, 100.0, 0}, table.Rows[0],
+		"a test regressed in several variants counts once")
+	assert.Equal(t, "Unknown", table.Rows[1][0])
+	assert.Equal(t, 1, table.Rows[1][2])
+	assert.Equal(t, "Storage", table.Rows[2][0])
+	assert.Equal(t, 0, table.Rows[2][2])
+}
+
+func TestWrite(t *testing.T) {
+	tables := []Table{
+		{Report: "first", Columns: []string{"name", "value"}, Rows: [][]interface{}{{"a|b", 1.5}, {"c", 2}}},
+		{Report: "second", Columns: []string{"name"}, Rows: [][]interface{}{}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJSON, tables))
+	var decoded map[string][]map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "a|b", decoded["first"][0]["name"])
+	assert.Empty(t, decoded["second"])
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatCSV, tables[:1]))
+	assert.Equal(t, "name,value\na|b,1.5\nc,2\n", buf.String())
+	assert.Error(t, Write(&buf, FormatCSV, tables), "csv holds a single report")
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatMarkdown, tables))
+	assert.Equal(t, "## first\n\n| name | value |\n|---|---|\n| a\\|b | 1.5 |\n| c | 2 |\n\n## second\n\nNo results.\n", buf.String())
+
+	assert.Error(t, Write(&buf, "xml", tables))
+}
@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Formats are the output formats Write supports.
+var Formats = []string{FormatJSON, FormatCSV, FormatMarkdown}
+
+// Write writes the tables in the format. JSON is an object of each report's rows, CSV only holds a single report, and
+// Markdown has a section per report.
+func Write(w io.Writer, format string, tables []Table) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, tables)
+	case FormatCSV:
+		if len(tables) != 1 {
+			return fmt.Errorf("csv output holds a single report, got %d", len(tables))
+		}
+		return writeCSV(w, tables[0])
+	case FormatMarkdown:
+		return writeMarkdown(w, tables)
+	}
+	return fmt.Errorf("unknown format %q, must be one of %v", format, Formats)
+}
+
+func writeJSON(w io.Writer, tables []Table) error {
+	out := map[string][]map[string]interface{}{}
+	for _, table := range tables {
+		rows := make([]map[string]interface{}, 0, len(table.Rows))
+		for _, row := range table.Rows {
+			obj := map[string]interface{}{}
+			for i, column := range table.Columns {
+				obj[column] = row[i]
+			}
+			rows = append(rows, obj)
+		}
+		out[table.Report] = rows
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeCSV(w io.Writer, table Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.Columns); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = formatValue(value)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeMarkdown(w io.Writer, tables []Table) error {
+	var sb strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "## %s\n\n", table.Report)
+		if len(table.Rows) == 0 {
+			sb.WriteString("No results.\n")
+			continue
+		}
+		sb.WriteString("| " + strings.Join(table.Columns, " | ") + " |\n")
+		sb.WriteString("|" + strings.Repeat("---|", len(table.Columns)) + "\n")
+		for _, row := range table.Rows {
+			cells := make([]string, len(row))
+			for i, value := range row {
+				// pipes would end the cell early
+				cells[i] = strings.ReplaceAll(formatValue(value), "|", `\|`)
+			}
+			sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,157 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// The regressed tests are those regression detection has open, so the reports agree with the regressions the server
+// reports and files.
+func topRegressions(dbc *db.DB, opts Options) (Table, error) {
+	tests, _, err := api.BuildTestsResults(dbc, opts.Release, "default", true, false, nil)
+	if err != nil {
+		return Table{}, err
+	}
+	regressions, err := api.GetTestRegressionsFromDB(dbc, opts.Release, models.TestRegressionOpen)
+	if err != nil {
+		return Table{}, err
+	}
+	return topRegressionsTable(regressions, tests, opts), nil
+}
+
+func topRegressionsTable(regressions []models.TestRegression, tests []apitype.Test, opts Options) Table {
+	table := Table{
+		Columns: []string{"test", "variants", "component", "current_pass_percentage", "previous_pass_percentage",
+			"net_improvement", "current_runs", "previous_runs", "p_value", "open_bugs"},
+		Rows: make([][]interface{}, 0),
+	}
+
+	byName := map[string]apitype.Test{}
+	for _, test := range tests {
+		byName[test.Name] = test
+	}
+	sorted := make([]models.TestRegression, len(regressions))
+	copy(sorted, regressions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SamplePassPercentage-sorted[i].BasisPassPercentage <
+			sorted[j].SamplePassPercentage-sorted[j].BasisPassPercentage
+	})
+	for _, tr := range sorted {
+		test := byName[tr.TestName]
+		table.Rows = append(table.Rows, []interface{}{
+			tr.TestName, strings.Join(tr.Variants, ","), test.JiraComponent, tr.SamplePassPercentage,
+			tr.BasisPassPercentage, tr.SamplePassPercentage - tr.BasisPassPercentage, tr.SampleRuns, tr.BasisRuns,
+			tr.PValue, test.OpenBugs,
+		})
+		if opts.Limit > 0 && len(table.Rows) == opts.Limit {
+			break
+		}
+	}
+	return table
+}
+
+func payloadHealth(dbc *db.DB, opts Options) (Table, error) {
+	reports, err := api.ReleaseHealthReports(dbc, opts.Release, opts.ReportEnd)
+	if err != nil {
+		return Table{}, err
+	}
+
+	table := Table{
+		Columns: []string{"stream", "architecture", "last_payload", "last_payload_time", "last_phase",
+			"consecutive_in_phase", "accepted_last_week", "rejected_last_week"},
+		Rows: make([][]interface{}, 0, len(reports)),
+	}
+	for _, report := range reports {
+		table.Rows = append(table.Rows, []interface{}{
+			report.Stream, report.Architecture, report.ReleaseTag.ReleaseTag, report.ReleaseTime, report.LastPhase,
+			report.Count, report.PhaseCounts.CurrentWeek.Accepted, report.PhaseCounts.CurrentWeek.Rejected,
+		})
+	}
+	return table, nil
+}
+
+func componentRollup(dbc *db.DB, opts Options) (Table, error) {
+	tests, _, err := api.BuildTestsResults(dbc, opts.Release, "default", true, false, nil)
+	if err != nil {
+		return Table{}, err
+	}
+	regressions, err := api.GetTestRegressionsFromDB(dbc, opts.Release, models.TestRegressionOpen)
+	if err != nil {
+		return Table{}, err
+	}
+	return componentRollupTable(tests, regressions), nil
+}
+
+type componentCounts struct {
+	name                            string
+	tests, regressed, openBugs      int
+	currentRuns, currentPasses      int
+	previousRuns, previousPasses    int
+	currentPercent, previousPercent float64
+}
+
+func componentRollupTable(tests []apitype.Test, regressions []models.TestRegression) Table {
+	regressed := map[string]bool{}
+	for _, tr := range regressions {
+		regressed[tr.TestName] = true
+	}
+	byComponent := map[string]*componentCounts{}
+	for _, test := range tests {
+		name := test.JiraComponent
+		if name == "" {
+			name = "Unknown"
+		}
+		c, ok := byComponent[name]
+		if !ok {
+			c = &componentCounts{name: name}
+			byComponent[name] = c
+		}
+		c.tests++
+		c.openBugs += test.OpenBugs
+		c.currentRuns += test.CurrentRuns
+		c.currentPasses += test.CurrentSuccesses + test.CurrentFlakes
+		c.previousRuns += test.PreviousRuns
+		c.previousPasses += test.PreviousSuccesses + test.PreviousFlakes
+		if regressed[test.Name] {
+			c.regressed++
+		}
+	}
+
+	components := make([]*componentCounts, 0, len(byComponent))
+	for _, c := range byComponent {
+		if c.currentRuns > 0 {
+			c.currentPercent = float64(c.currentPasses) / float64(c.currentRuns) * 100
+		}
+		if c.previousRuns > 0 {
+			c.previousPercent = float64(c.previousPasses) / float64(c.previousRuns) * 100
+		}
+		components = append(components, c)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		a, b := components[i], components[j]
+		if a.regressed != b.regressed {
+			return a.regressed > b.regressed
+		}
+		if a.currentPercent != b.currentPercent {
+			return a.currentPercent < b.currentPercent
+		}
+		return a.name < b.name
+	})
+
+	table := Table{
+		Columns: []string{"component", "tests", "regressed_tests", "current_runs", "current_pass_percentage",
+			"previous_runs", "previous_pass_percentage", "open_bugs"},
+		Rows: make([][]interface{}, 0, len(components)),
+	}
+	for _, c := range components {
+		table.Rows = append(table.Rows, []interface{}{
+			c.name, c.tests, c.regressed, c.currentRuns, c.currentPercent, c.previousRuns, c.previousPercent, c.openBugs,
+		})
+	}
+	return table
+}
@@ -52,12 +52,25 @@ func PrintAutocompleteFromDB(w http.ResponseWriter, req *http.Request, dbc *db.D
 		q = q.Table("suites").
 			Select("name").
 			Order("name")
+	case "sig":
+		q = q.Table("tests").
+			Select("DISTINCT(sig) as name").
+			Where("sig != ''").
+			Order("name")
+	case "feature_gates":
+		q = q.Table("tests").
+			Select("DISTINCT(unnest(feature_gates)) as name").
+			Order("name")
+	case "capabilities":
+		q = q.Table("tests").
+			Select("DISTINCT(unnest(capabilities)) as name").
+			Order("name")
 	case "jira_component":
 		q = q.Table("jira_components").
 			Select("name").
 			Order("name")
 	default:
-		RespondWithJSON(404, w, map[string]string{"message": "Autocomplete field not found."})
+		RespondWithError(http.StatusNotFound, w, "Autocomplete field not found.")
 	}
 
 	if release != "" {
@@ -71,7 +84,7 @@ func PrintAutocompleteFromDB(w http.ResponseWriter, req *http.Request, dbc *db.D
 
 	q = q.Limit(50).Scan(&result)
 	if q.Error != nil {
-		RespondWithJSON(503, w, map[string]string{"message": q.Error.Error()})
+		RespondWithError(http.StatusServiceUnavailable, w, q.Error.Error())
 		return
 	}
 
@@ -8,9 +8,40 @@ import (
 	"github.com/openshift/sippy/pkg/db/query"
 )
 
+// buildClusterOutlierConfidence is the percent confidence a cluster's failure rate must be worse than the other
+// clusters' at to be an outlier.
+const buildClusterOutlierConfidence = 95
+
 func GetBuildClusterHealthReport(dbc *db.DB, start, boundary, end time.Time) ([]apitype.BuildClusterHealth, error) {
 	results, err := query.BuildClusterHealth(dbc, start, boundary, end)
-	return results, err
+	if err != nil {
+		return results, err
+	}
+	flagBuildClusterOutliers(results, buildClusterOutlierConfidence)
+	return results, nil
+}
+
+// flagBuildClusterOutliers compares each cluster's current failures, and infrastructure failures, to those of every
+// other cluster combined.
+func flagBuildClusterOutliers(clusters []apitype.BuildClusterHealth, confidence int) {
+	var runs, fails, infraFails int
+	for _, c := range clusters {
+		runs += c.CurrentRuns
+		fails += c.CurrentFails
+		infraFails += c.CurrentInfraFails
+	}
+
+	for i := range clusters {
+		c := &clusters[i]
+		otherRuns, otherFails, otherInfraFails := runs-c.CurrentRuns, fails-c.CurrentFails, infraFails-c.CurrentInfraFails
+
+		var failWorse, infraFailWorse bool
+		c.FailPValue, failWorse = SignificantlyWorse(otherFails, otherRuns-otherFails,
+			c.CurrentFails, c.CurrentRuns-c.CurrentFails, confidence)
+		c.InfraFailPValue, infraFailWorse = SignificantlyWorse(otherInfraFails, otherRuns-otherInfraFails,
+			c.CurrentInfraFails, c.CurrentRuns-c.CurrentInfraFails, confidence)
+		c.Outlier = failWorse || infraFailWorse
+	}
 }
 
 func GetBuildClusterHealthAnalysis(dbc *db.DB, period string) (map[string]apitype.BuildClusterHealthAnalysis, error) {
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestFlagBuildClusterOutliers(t *testing.T) {
+	clusters := []apitype.BuildClusterHealth{
+		{Cluster: "build01", CurrentRuns: 1000, CurrentFails: 100, CurrentInfraFails: 10},
+		{Cluster: "build02", CurrentRuns: 1000, CurrentFails: 110, CurrentInfraFails: 12},
+		// fails as often as the others, but more of its failures are infrastructure
+		{Cluster: "build03", CurrentRuns: 1000, CurrentFails: 105, CurrentInfraFails: 60},
+		{Cluster: "build04", CurrentRuns: 1000, CurrentFails: 300, CurrentInfraFails: 11},
+		{Cluster: "build05"},
+	}
+
+	flagBuildClusterOutliers(clusters, 95)
+
+	outliers := map[string]bool{}
+	for _, c := range clusters {
+		outliers[c.Cluster] = c.Outlier
+	}
+	assert.Equal(t, map[string]bool{
+		"build01": false,
+		"build02": false,
+		"build03": true,
+		"build04": true,
+		"build05": false,
+	}, outliers)
+}
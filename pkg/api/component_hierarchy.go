@@ -0,0 +1,143 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/lib/pq"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+const (
+	componentLevel    = "component"
+	subComponentLevel = "sub-component"
+	capabilityLevel   = "capability"
+)
+
+// componentTestCounts are a test's results in the last week and the week before, with where the test mapping placed
+// it in the component hierarchy.
+type componentTestCounts struct {
+	TestID            uint
+	Component         string
+	SubComponent      string
+	Capabilities      pq.StringArray `gorm:"type:text[]"`
+	CurrentRuns       int
+	CurrentSuccesses  int
+	CurrentFailures   int
+	PreviousRuns      int
+	PreviousSuccesses int
+}
+
+// GetComponentHierarchyFromDB rolls the release's test results up the component hierarchy, limited to the component
+// when it's set.
+func GetComponentHierarchyFromDB(dbc *db.DB, release, component string) (*apitype.ComponentHierarchy, error) {
+	counts := make([]componentTestCounts, 0)
+	q := dbc.DB.Table("prow_test_report_7d_matview report").
+		Select(`report.id AS test_id,
+			test_ownerships.jira_parent_component AS component,
+			test_ownerships.jira_sub_component AS sub_component,
+			test_ownerships.capabilities,
+			SUM(report.current_runs) AS current_runs,
+			SUM(report.current_successes) AS current_successes,
+			SUM(report.current_failures) AS current_failures,
+			SUM(report.previous_runs) AS previous_runs,
+			SUM(report.previous_successes) AS previous_successes`).
+		Joins(`JOIN test_ownerships ON test_ownerships.test_id = report.id
+			AND test_ownerships.suite_id = report.suite_id`).
+		Where("report.release = ? AND test_ownerships.jira_parent_component <> ''", release)
+	if component != "" {
+		q = q.Where("test_ownerships.jira_parent_component = ?", component)
+	}
+	res := q.Group(`report.id, test_ownerships.jira_parent_component, test_ownerships.jira_sub_component,
+			test_ownerships.capabilities`).
+		Having("SUM(report.current_runs) + SUM(report.previous_runs) > 0").
+		Scan(&counts)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return buildComponentHierarchy(release, counts), nil
+}
+
+// componentNode accumulates a node's results, counting each of its tests once.
+type componentNode struct {
+	node              apitype.ComponentNode
+	tests             map[uint]bool
+	currentSuccesses  int
+	previousSuccesses int
+	children          map[string]*componentNode
+}
+
+func newComponentNode(name, level string) *componentNode {
+	return &componentNode{
+		node:     apitype.ComponentNode{Name: name, Level: level},
+		tests:    map[uint]bool{},
+		children: map[string]*componentNode{},
+	}
+}
+
+func (n *componentNode) child(name, level string) *componentNode {
+	key := level + "/" + name
+	if _, ok := n.children[key]; !ok {
+		n.children[key] = newComponentNode(name, level)
+	}
+	return n.children[key]
+}
+
+func (n *componentNode) add(c componentTestCounts) {
+	n.tests[c.TestID] = true
+	n.node.CurrentRuns += c.CurrentRuns
+	n.node.CurrentFailures += c.CurrentFailures
+	n.node.PreviousRuns += c.PreviousRuns
+	n.currentSuccesses += c.CurrentSuccesses
+	n.previousSuccesses += c.PreviousSuccesses
+}
+
+// result computes the node's pass rates, with its children ordered by level, sub-components before capabilities,
+// then name.
+func (n *componentNode) result() apitype.ComponentNode {
+	node := n.node
+	node.Tests = len(n.tests)
+	node.CurrentPassPercentage = percentage(n.currentSuccesses, node.CurrentRuns)
+	node.PreviousPassPercentage = percentage(n.previousSuccesses, node.PreviousRuns)
+	if node.CurrentRuns > 0 && node.PreviousRuns > 0 {
+		node.NetImprovement = node.CurrentPassPercentage - node.PreviousPassPercentage
+	}
+	for _, child := range n.children {
+		node.Children = append(node.Children, child.result())
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if a.Level != b.Level {
+			return a.Level == subComponentLevel
+		}
+		return a.Name < b.Name
+	})
+	return node
+}
+
+func buildComponentHierarchy(release string, counts []componentTestCounts) *apitype.ComponentHierarchy {
+	root := newComponentNode("", "")
+	for _, c := range counts {
+		component := root.child(c.Component, componentLevel)
+		component.add(c)
+		parent := component
+		if c.SubComponent != "" {
+			parent = component.child(c.SubComponent, subComponentLevel)
+			parent.add(c)
+		}
+		seen := map[string]bool{}
+		for _, capability := range c.Capabilities {
+			if !seen[capability] {
+				seen[capability] = true
+				parent.child(capability, capabilityLevel).add(c)
+			}
+		}
+	}
+
+	hierarchy := &apitype.ComponentHierarchy{Release: release, Components: root.result().Children}
+	if hierarchy.Components == nil {
+		hierarchy.Components = make([]apitype.ComponentNode, 0)
+	}
+	return hierarchy
+}
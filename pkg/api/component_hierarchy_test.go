@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildComponentHierarchy(t *testing.T) {
+	hierarchy := buildComponentHierarchy("4.16", []componentTestCounts{
+		{TestID: 1, Component: "Networking", SubComponent: "ovn-kubernetes", Capabilities: pq.StringArray{"IPv6", "EgressIP"},
+			CurrentRuns: 10, CurrentSuccesses: 9, CurrentFailures: 1, PreviousRuns: 10, PreviousSuccesses: 10},
+		// the same test in another suite
+		{TestID: 1, Component: "Networking", SubComponent: "ovn-kubernetes", Capabilities: pq.StringArray{"IPv6"},
+			CurrentRuns: 10, CurrentSuccesses: 10, PreviousRuns: 10, PreviousSuccesses: 10},
+		{TestID: 2, Component: "Networking", SubComponent: "router", Capabilities: pq.StringArray{"Ingress", "Ingress"},
+			CurrentRuns: 20, CurrentSuccesses: 10, CurrentFailures: 10},
+		{TestID: 3, Component: "Networking", Capabilities: pq.StringArray{"DNS"},
+			CurrentRuns: 10, CurrentSuccesses: 10, PreviousRuns: 10, PreviousSuccesses: 5},
+		{TestID: 4, Component: "Etcd", CurrentRuns: 5, CurrentSuccesses: 5},
+	})
+	assert.Equal(t, "4.16", hierarchy.Release)
+	require.Len(t, hierarchy.Components, 2)
+
+	etcd := hierarchy.Components[0]
+	assert.Equal(t, "Etcd", etcd.Name)
+	assert.Equal(t, componentLevel, etcd.Level)
+	assert.Empty(t, etcd.Children)
+
+	networking := hierarchy.Components[1]
+	assert.Equal(t, "Networking", networking.Name)
+	assert.Equal(t, 3, networking.Tests)
+	assert.Equal(t, 50, networking.CurrentRuns)
+	assert.Equal(t, 11, networking.CurrentFailures)
+	assert.InDelta(t, 78, networking.CurrentPassPercentage, 0.01)
+	assert.Equal(t, 30, networking.PreviousRuns)
+	assert.InDelta(t, 83.33, networking.PreviousPassPercentage, 0.01)
+	assert.InDelta(t, -5.33, networking.NetImprovement, 0.01)
+
+	// sub-components first, then the capabilities of tests without one
+	require.Len(t, networking.Children, 3)
+	assert.Equal(t, []string{"ovn-kubernetes", "router", "DNS"},
+		[]string{networking.Children[0].Name, networking.Children[1].Name, networking.Children[2].Name})
+	assert.Equal(t, capabilityLevel, networking.Children[2].Level)
+
+	ovn := networking.Children[0]
+	assert.Equal(t, subComponentLevel, ovn.Level)
+	assert.Equal(t, 1, ovn.Tests)
+	assert.Equal(t, 20, ovn.CurrentRuns)
+	require.Len(t, ovn.Children, 2)
+	assert.Equal(t, "EgressIP", ovn.Children[0].Name)
+	assert.Equal(t, 10, ovn.Children[0].CurrentRuns)
+	assert.Equal(t, "IPv6", ovn.Children[1].Name)
+	assert.Equal(t, 20, ovn.Children[1].CurrentRuns)
+
+	router := networking.Children[1]
+	require.Len(t, router.Children, 1)
+	assert.Equal(t, 20, router.Children[0].CurrentRuns, "capabilities listed twice count once")
+	assert.Equal(t, float64(0), router.NetImprovement, "no runs in the previous week")
+}
+
+func TestBuildComponentHierarchyEmpty(t *testing.T) {
+	assert.NotNil(t, buildComponentHierarchy("4.16", nil).Components)
+}
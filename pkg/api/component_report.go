@@ -21,6 +21,7 @@ import (
 	"github.com/openshift/sippy/pkg/apis/cache"
 	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/regressionallowances"
+	"github.com/openshift/sippy/pkg/tracing"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
@@ -78,9 +79,11 @@ var (
 	DefaultIgnoreDisruption = true
 )
 
-func getSingleColumnResultToSlice(query *bigquery.Query) ([]string, error) {
+func getSingleColumnResultToSlice(ctx context.Context, query *bigquery.Query) ([]string, error) {
 	names := []string{}
-	it, err := query.Read(context.TODO())
+	ctx, span := tracing.StartQuerySpan(ctx, "bigquery", query.Q)
+	defer span.End()
+	it, err := query.Read(ctx)
 	if err != nil {
 		log.WithError(err).Error("error querying test status from bigquery")
 		return names, err
@@ -101,16 +104,16 @@ func getSingleColumnResultToSlice(query *bigquery.Query) ([]string, error) {
 	return names, nil
 }
 
-func GetComponentTestVariantsFromBigQuery(client *bqcachedclient.Client, gcsBucket string) (apitype.ComponentReportTestVariants, []error) {
+func GetComponentTestVariantsFromBigQuery(ctx context.Context, client *bqcachedclient.Client, gcsBucket string) (apitype.ComponentReportTestVariants, []error) {
 	generator := componentReportGenerator{
 		client:    client,
 		gcsBucket: gcsBucket,
 	}
 
-	return getReportFromCacheOrGenerate[apitype.ComponentReportTestVariants](client.Cache, cache.RequestOptions{}, "component_readiness_variants", generator.GenerateVariants, apitype.ComponentReportTestVariants{})
+	return getReportFromCacheOrGenerate[apitype.ComponentReportTestVariants](ctx, client.Cache, cache.RequestOptions{}, "component_readiness_variants", generator.GenerateVariants, apitype.ComponentReportTestVariants{})
 }
 
-func GetComponentReportFromBigQuery(client *bqcachedclient.Client, gcsBucket string,
+func GetComponentReportFromBigQuery(ctx context.Context, client *bqcachedclient.Client, gcsBucket string,
 	baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions,
 	testIDOption apitype.ComponentReportRequestTestIdentificationOptions,
 	variantOption apitype.ComponentReportRequestVariantOptions,
@@ -130,10 +133,10 @@ func GetComponentReportFromBigQuery(client *bqcachedclient.Client, gcsBucket str
 		ComponentReportRequestAdvancedOptions:           advancedOption,
 	}
 
-	return getReportFromCacheOrGenerate[apitype.ComponentReport](client.Cache, cacheOption, generator, generator.GenerateReport, apitype.ComponentReport{})
+	return getReportFromCacheOrGenerate[apitype.ComponentReport](ctx, client.Cache, cacheOption, generator, generator.GenerateReport, apitype.ComponentReport{})
 }
 
-func GetComponentReportTestDetailsFromBigQuery(client *bqcachedclient.Client, gcsBucket string,
+func GetComponentReportTestDetailsFromBigQuery(ctx context.Context, client *bqcachedclient.Client, gcsBucket string,
 	baseRelease, sampleRelease apitype.ComponentReportRequestReleaseOptions,
 	testIDOption apitype.ComponentReportRequestTestIdentificationOptions,
 	variantOption apitype.ComponentReportRequestVariantOptions,
@@ -152,7 +155,7 @@ func GetComponentReportTestDetailsFromBigQuery(client *bqcachedclient.Client, gc
 		ComponentReportRequestAdvancedOptions:           advancedOption,
 	}
 
-	return getReportFromCacheOrGenerate[apitype.ComponentReportTestDetails](client.Cache, cacheOption, generator, generator.GenerateTestDetailsReport, apitype.ComponentReportTestDetails{})
+	return getReportFromCacheOrGenerate[apitype.ComponentReportTestDetails](ctx, client.Cache, cacheOption, generator, generator.GenerateTestDetailsReport, apitype.ComponentReportTestDetails{})
 }
 
 // componentReportGenerator contains the information needed to generate a CR report. Do
@@ -169,12 +172,12 @@ type componentReportGenerator struct {
 	apitype.ComponentReportRequestAdvancedOptions
 }
 
-func (c *componentReportGenerator) GenerateVariants() (apitype.ComponentReportTestVariants, []error) {
+func (c *componentReportGenerator) GenerateVariants(ctx context.Context) (apitype.ComponentReportTestVariants, []error) {
 	errs := []error{}
 	columns := make(map[string][]string)
 
 	for _, column := range []string{"platform", "network", "arch", "upgrade", "variants"} {
-		values, err := c.getUniqueJUnitColumnValues(column, column == "variants")
+		values, err := c.getUniqueJUnitColumnValues(ctx, column, column == "variants")
 		if err != nil {
 			wrappedErr := errors.Wrapf(err, "couldn't fetch %s", column)
 			log.WithError(wrappedErr).Errorf("error generating variants")
@@ -192,9 +195,9 @@ func (c *componentReportGenerator) GenerateVariants() (apitype.ComponentReportTe
 	}, errs
 }
 
-func (c *componentReportGenerator) GenerateReport() (apitype.ComponentReport, []error) {
+func (c *componentReportGenerator) GenerateReport(ctx context.Context) (apitype.ComponentReport, []error) {
 	before := time.Now()
-	baseStatus, sampleStatus, errs := c.getTestStatusFromBigQuery()
+	baseStatus, sampleStatus, errs := c.getTestStatusFromBigQuery(ctx)
 	if len(errs) > 0 {
 		return apitype.ComponentReport{}, errs
 	}
@@ -206,7 +209,7 @@ func (c *componentReportGenerator) GenerateReport() (apitype.ComponentReport, []
 	return report, nil
 }
 
-func (c *componentReportGenerator) GenerateTestDetailsReport() (apitype.ComponentReportTestDetails, []error) {
+func (c *componentReportGenerator) GenerateTestDetailsReport(ctx context.Context) (apitype.ComponentReportTestDetails, []error) {
 	if c.TestID == "" ||
 		c.Platform == "" ||
 		c.Network == "" ||
@@ -216,7 +219,7 @@ func (c *componentReportGenerator) GenerateTestDetailsReport() (apitype.Componen
 		return apitype.ComponentReportTestDetails{}, []error{fmt.Errorf("all parameters have to be defined for test details: test_id, platform, network, upgrade, arch, variant")}
 	}
 	before := time.Now()
-	baseStatus, sampleStatus, errs := c.getJobRunTestStatusFromBigQuery()
+	baseStatus, sampleStatus, errs := c.getJobRunTestStatusFromBigQuery(ctx)
 	if len(errs) > 0 {
 		return apitype.ComponentReportTestDetails{}, errs
 	}
@@ -227,7 +230,7 @@ func (c *componentReportGenerator) GenerateTestDetailsReport() (apitype.Componen
 	return report, nil
 }
 
-func (c *componentReportGenerator) getJobRunTestStatusFromBigQuery() (
+func (c *componentReportGenerator) getJobRunTestStatusFromBigQuery(ctx context.Context) (
 	map[string][]apitype.ComponentJobRunTestStatusRow,
 	map[string][]apitype.ComponentJobRunTestStatusRow,
 	[]error,
@@ -325,7 +328,7 @@ func (c *componentReportGenerator) getJobRunTestStatusFromBigQuery() (
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		baseStatus, baseErrs = c.fetchJobRunTestStatus(baseQuery)
+		baseStatus, baseErrs = c.fetchJobRunTestStatus(ctx, baseQuery)
 	}()
 
 	sampleString := queryString + ` AND branch = @SampleRelease`
@@ -348,7 +351,7 @@ func (c *componentReportGenerator) getJobRunTestStatusFromBigQuery() (
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		sampleStatus, sampleErrs = c.fetchJobRunTestStatus(sampleQuery)
+		sampleStatus, sampleErrs = c.fetchJobRunTestStatus(ctx, sampleQuery)
 	}()
 	wg.Wait()
 	if len(baseErrs) != 0 || len(sampleErrs) != 0 {
@@ -359,7 +362,7 @@ func (c *componentReportGenerator) getJobRunTestStatusFromBigQuery() (
 	return baseStatus, sampleStatus, errs
 }
 
-func (c *componentReportGenerator) getTestStatusFromBigQuery() (
+func (c *componentReportGenerator) getTestStatusFromBigQuery(ctx context.Context) (
 	map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus,
 	map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus,
 	[]error,
@@ -521,7 +524,7 @@ func (c *componentReportGenerator) getTestStatusFromBigQuery() (
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		baseStatus, baseErrs = c.fetchTestStatus(baseQuery)
+		baseStatus, baseErrs = c.fetchTestStatus(ctx, baseQuery)
 	}()
 
 	sampleString := queryString + ` AND branch = @SampleRelease`
@@ -544,7 +547,7 @@ func (c *componentReportGenerator) getTestStatusFromBigQuery() (
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		sampleStatus, sampleErrs = c.fetchTestStatus(sampleQuery)
+		sampleStatus, sampleErrs = c.fetchTestStatus(ctx, sampleQuery)
 	}()
 	wg.Wait()
 	if len(baseErrs) != 0 || len(sampleErrs) != 0 {
@@ -657,12 +660,14 @@ func (c *componentReportGenerator) getRowColumnIdentifications(test apitype.Comp
 	return rows, columns
 }
 
-func (c *componentReportGenerator) fetchTestStatus(query *bigquery.Query) (map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, []error) {
+func (c *componentReportGenerator) fetchTestStatus(ctx context.Context, query *bigquery.Query) (map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus, []error) {
 	errs := []error{}
 	status := map[apitype.ComponentTestIdentification]apitype.ComponentTestStatus{}
 	log.Infof("Fetching test status with:\n%s\nParameters:\n%+v\n", query.Q, query.Parameters)
 
-	it, err := query.Read(context.TODO())
+	ctx, span := tracing.StartQuerySpan(ctx, "bigquery", query.Q)
+	defer span.End()
+	it, err := query.Read(ctx)
 	if err != nil {
 		log.WithError(err).Error("error querying test status from bigquery")
 		errs = append(errs, err)
@@ -745,12 +750,14 @@ func (c *componentReportGenerator) normalizeProwJobName(prowName string) string
 	return name
 }
 
-func (c *componentReportGenerator) fetchJobRunTestStatus(query *bigquery.Query) (map[string][]apitype.ComponentJobRunTestStatusRow, []error) {
+func (c *componentReportGenerator) fetchJobRunTestStatus(ctx context.Context, query *bigquery.Query) (map[string][]apitype.ComponentJobRunTestStatusRow, []error) {
 	errs := []error{}
 	status := map[string][]apitype.ComponentJobRunTestStatusRow{}
 	log.Infof("Fetching job run test details with:\n%s\nParameters:\n%+v\n", query.Q, query.Parameters)
 
-	it, err := query.Read(context.TODO())
+	ctx, span := tracing.StartQuerySpan(ctx, "bigquery", query.Q)
+	defer span.End()
+	it, err := query.Read(ctx)
 	if err != nil {
 		log.WithError(err).Error("error querying job run test status from bigquery")
 		errs = append(errs, err)
@@ -1235,7 +1242,7 @@ func (c *componentReportGenerator) assessComponentStatus(sampleTotal, sampleSucc
 	return status, fischerExact
 }
 
-func (c *componentReportGenerator) getUniqueJUnitColumnValues(field string, nested bool) ([]string, error) {
+func (c *componentReportGenerator) getUniqueJUnitColumnValues(ctx context.Context, field string, nested bool) ([]string, error) {
 	unnest := ""
 	if nested {
 		unnest = fmt.Sprintf(", UNNEST(%s) nested", field)
@@ -1259,7 +1266,7 @@ func (c *componentReportGenerator) getUniqueJUnitColumnValues(field string, nest
 		},
 	}
 
-	return getSingleColumnResultToSlice(query)
+	return getSingleColumnResultToSlice(ctx, query)
 }
 
 func init() {
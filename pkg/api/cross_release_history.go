@@ -0,0 +1,171 @@
+package api
+
+import (
+	"github.com/hashicorp/go-version"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	TestMatchExact  = "exact"
+	TestMatchRename = "rename"
+	TestMatchFuzzy  = "fuzzy"
+
+	// minFuzzyTestScore is the pg_trgm similarity a name needs to be taken for the test's name in an older release.
+	// Renames usually change a tag or a few words of long names, so they stay well above it.
+	minFuzzyTestScore = 0.8
+)
+
+// testHistoryLookup reads a test's weekly results in a release, and finds the name most similar to the test's in a
+// release, among tests without results in the newer release. similar is nil when names aren't fuzzily matched.
+type testHistoryLookup struct {
+	weeks   func(release, testName string) ([]apitype.WeeklyResult, error)
+	similar func(release, newerRelease, testName string) (string, float64, error)
+}
+
+// GetCrossReleaseTestHistoryFromDB returns the test's weekly results in the jobs of the variant in every release, as
+// one series, following the test to its older names through the renames, and when fuzzy is set, to the most similar
+// name of a release it has no results in under its name.
+func GetCrossReleaseTestHistoryFromDB(dbc *db.DB, testName, variant string, renames []v1.TestRenameConfig,
+	fuzzy bool) (*apitype.CrossReleaseTestHistory, error) {
+	releases, err := query.ReleasesFromDB(dbc)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(releases))
+	for _, r := range releases {
+		names = append(names, r.Release)
+	}
+
+	lookup := testHistoryLookup{
+		weeks: func(release, testName string) ([]apitype.WeeklyResult, error) {
+			return GetTestHistoryFromDB(dbc, release, testName, variant)
+		},
+	}
+	if fuzzy {
+		lookup.similar = func(release, newerRelease, testName string) (string, float64, error) {
+			return similarTestInRelease(dbc, release, newerRelease, testName, variant)
+		}
+	}
+	return stitchTestHistory(testName, variant, names, renames, lookup)
+}
+
+// similarTestInRelease returns the name most similar to the test's among the tests with results in the release but
+// not in the newer release, i.e. those that may have been renamed, or an empty name if none is similar enough.
+func similarTestInRelease(dbc *db.DB, release, newerRelease, testName, variant string) (string, float64, error) {
+	match := struct {
+		Name  string
+		Score float64
+	}{}
+	res := dbc.DB.Raw(`
+		SELECT tests.name, similarity(tests.name, @name) AS score
+		FROM test_weekly_results
+		JOIN tests ON tests.id = test_weekly_results.test_id
+		WHERE test_weekly_results.release = @release AND test_weekly_results.variant = @variant
+			AND tests.name % @name AND similarity(tests.name, @name) >= @min
+			AND NOT EXISTS (
+				SELECT 1 FROM test_weekly_results newer
+				WHERE newer.test_id = test_weekly_results.test_id AND newer.release = @newer AND newer.variant = @variant)
+		GROUP BY tests.name
+		ORDER BY score DESC, tests.name
+		LIMIT 1`,
+		map[string]interface{}{
+			"name": testName, "release": release, "newer": newerRelease, "variant": variant, "min": minFuzzyTestScore,
+		}).Scan(&match)
+	return match.Name, match.Score, res.Error
+}
+
+// stitchTestHistory walks the releases from the newest, looking the test up under its name in each. A rename to the
+// name from a newer release gives the test's name in the releases before it. When the test has no results in a release
+// right after having some in the newer one, it's looked for under the most similar name. Releases that aren't
+// versions, i.e. Presubmits, are skipped.
+func stitchTestHistory(testName, variant string, releases []string, renames []v1.TestRenameConfig,
+	lookup testHistoryLookup) (*apitype.CrossReleaseTestHistory, error) {
+	found := make([]apitype.ReleaseTestHistory, 0)
+	weeksByRelease := map[string][]apitype.WeeklyResult{}
+
+	name, match, score := testName, TestMatchExact, 0.0
+	newerRelease := ""
+	for _, release := range releases {
+		if _, err := version.NewVersion(release); err != nil {
+			continue
+		}
+
+		visited := map[string]bool{name: true}
+		for renamed := true; renamed; {
+			renamed = false
+			for _, rename := range renames {
+				if rename.To == name && releaseBefore(release, rename.Release) && !visited[rename.From] {
+					name, match, score = rename.From, TestMatchRename, 0
+					visited[name] = true
+					renamed = true
+				}
+			}
+		}
+
+		weeks, err := lookup.weeks(release, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(weeks) == 0 && newerRelease != "" && lookup.similar != nil {
+			similar, similarScore, err := lookup.similar(release, newerRelease, name)
+			if err != nil {
+				return nil, err
+			}
+			if similar != "" {
+				if weeks, err = lookup.weeks(release, similar); err != nil {
+					return nil, err
+				}
+				name, match, score = similar, TestMatchFuzzy, similarScore
+			}
+		}
+		if len(weeks) == 0 {
+			newerRelease = ""
+			continue
+		}
+
+		found = append(found, apitype.ReleaseTestHistory{
+			Release:  release,
+			TestName: name,
+			Match:    match,
+			Score:    score,
+			Weeks:    len(weeks),
+		})
+		weeksByRelease[release] = weeks
+		newerRelease = release
+	}
+
+	history := &apitype.CrossReleaseTestHistory{
+		TestName: testName,
+		Variant:  variant,
+		Releases: make([]apitype.ReleaseTestHistory, 0, len(found)),
+		Series:   make([]apitype.CrossReleaseWeeklyResult, 0),
+	}
+	for i := len(found) - 1; i >= 0; i-- {
+		history.Releases = append(history.Releases, found[i])
+		for _, week := range weeksByRelease[found[i].Release] {
+			history.Series = append(history.Series, apitype.CrossReleaseWeeklyResult{
+				Release:      found[i].Release,
+				TestName:     found[i].TestName,
+				WeeklyResult: week,
+			})
+		}
+	}
+	return history, nil
+}
+
+// releaseBefore returns whether the release is older than the other, false when either isn't a version.
+func releaseBefore(release, other string) bool {
+	v, err := version.NewVersion(release)
+	if err != nil {
+		return false
+	}
+	o, err := version.NewVersion(other)
+	if err != nil {
+		return false
+	}
+	return v.LessThan(o)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+func TestStitchTestHistory(t *testing.T) {
+	results := map[string]map[string][]apitype.WeeklyResult{
+		"4.17": {"[sig-network] pods are reachable [apigroup:network]": {{Week: "2024-06-03", Runs: 10, Successes: 10}}},
+		"4.16": {"[sig-network] pods are reachable [apigroup:network]": {{Week: "2024-03-04", Runs: 8, Successes: 7}}},
+		"4.15": {"[sig-network] pods should be reachable": {{Week: "2023-11-06", Runs: 5, Successes: 5}}},
+		"4.14": {"[sig-network] pods should be reachable [Serial]": {{Week: "2023-06-05", Runs: 4, Successes: 2}}},
+		"4.12": {"[sig-network] pods should be reachable [Serial]": {{Week: "2022-06-06", Runs: 3, Successes: 3}}},
+	}
+	var similarCalls []string
+	lookup := testHistoryLookup{
+		weeks: func(release, testName string) ([]apitype.WeeklyResult, error) {
+			return results[release][testName], nil
+		},
+		similar: func(release, newerRelease, testName string) (string, float64, error) {
+			similarCalls = append(similarCalls, release+" after "+newerRelease)
+			if release == "4.14" && testName == "[sig-network] pods should be reachable" {
+				return "[sig-network] pods should be reachable [Serial]", 0.9, nil
+			}
+			return "", 0, nil
+		},
+	}
+	renames := []v1.TestRenameConfig{{
+		From:    "[sig-network] pods should be reachable",
+		To:      "[sig-network] pods are reachable [apigroup:network]",
+		Release: "4.16",
+	}}
+
+	history, err := stitchTestHistory("[sig-network] pods are reachable [apigroup:network]", "All",
+		[]string{"4.17", "4.16", "4.15", "4.14", "4.13", "4.12", "Presubmits"}, renames, lookup)
+	require.NoError(t, err)
+
+	assert.Equal(t, []apitype.ReleaseTestHistory{
+		{Release: "4.12", TestName: "[sig-network] pods should be reachable [Serial]", Match: TestMatchFuzzy, Score: 0.9, Weeks: 1},
+		{Release: "4.14", TestName: "[sig-network] pods should be reachable [Serial]", Match: TestMatchFuzzy, Score: 0.9, Weeks: 1},
+		{Release: "4.15", TestName: "[sig-network] pods should be reachable", Match: TestMatchRename, Weeks: 1},
+		{Release: "4.16", TestName: "[sig-network] pods are reachable [apigroup:network]", Match: TestMatchExact, Weeks: 1},
+		{Release: "4.17", TestName: "[sig-network] pods are reachable [apigroup:network]", Match: TestMatchExact, Weeks: 1},
+	}, history.Releases)
+	// 4.12 isn't fuzzily matched, as the test has no results in 4.13
+	assert.Equal(t, []string{"4.14 after 4.15", "4.13 after 4.14"}, similarCalls)
+
+	weeks := make([]string, 0, len(history.Series))
+	for _, week := range history.Series {
+		weeks = append(weeks, week.Release+" "+week.Week)
+	}
+	assert.Equal(t, []string{"4.12 2022-06-06", "4.14 2023-06-05", "4.15 2023-11-06", "4.16 2024-03-04",
+		"4.17 2024-06-03"}, weeks)
+}
+
+func TestStitchTestHistoryRenameCycle(t *testing.T) {
+	lookup := testHistoryLookup{
+		weeks: func(release, testName string) ([]apitype.WeeklyResult, error) {
+			return []apitype.WeeklyResult{{Week: "2024-03-04", Runs: 1, Successes: 1}}, nil
+		},
+	}
+	renames := []v1.TestRenameConfig{
+		{From: "a", To: "b", Release: "4.16"},
+		{From: "b", To: "a", Release: "4.15"},
+	}
+
+	history, err := stitchTestHistory("b", "All", []string{"4.16", "4.15", "4.14"}, renames, lookup)
+	require.NoError(t, err)
+	names := make([]string, 0, len(history.Releases))
+	for _, r := range history.Releases {
+		names = append(names, r.Release+" "+r.TestName)
+	}
+	assert.Equal(t, []string{"4.14 b", "4.15 a", "4.16 b"}, names)
+}
@@ -12,24 +12,25 @@ import (
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/apis/cache"
 	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/tracing"
 )
 
-func GetDisruptionVsPrevGAReportFromBigQuery(client *bqcachedclient.Client) (apitype.DisruptionReport, []error) {
+func GetDisruptionVsPrevGAReportFromBigQuery(ctx context.Context, client *bqcachedclient.Client) (apitype.DisruptionReport, []error) {
 	generator := disruptionReportGenerator{
 		client:   client.BQ,
 		ViewName: "BackendDisruptionPercentilesDeltaCurrentVsPrevGA",
 	}
 
-	return getReportFromCacheOrGenerate[apitype.DisruptionReport](client.Cache, cache.RequestOptions{}, generator, generator.GenerateReport, apitype.DisruptionReport{})
+	return getReportFromCacheOrGenerate[apitype.DisruptionReport](ctx, client.Cache, cache.RequestOptions{}, generator, generator.GenerateReport, apitype.DisruptionReport{})
 }
 
-func GetDisruptionVsTwoWeeksAgoReportFromBigQuery(client *bqcachedclient.Client) (apitype.DisruptionReport, []error) {
+func GetDisruptionVsTwoWeeksAgoReportFromBigQuery(ctx context.Context, client *bqcachedclient.Client) (apitype.DisruptionReport, []error) {
 	generator := disruptionReportGenerator{
 		client:   client.BQ,
 		ViewName: "BackendDisruptionPercentilesDeltaCurrentVs14DaysAgo",
 	}
 
-	return getReportFromCacheOrGenerate[apitype.DisruptionReport](client.Cache, cache.RequestOptions{}, generator, generator.GenerateReport, apitype.DisruptionReport{})
+	return getReportFromCacheOrGenerate[apitype.DisruptionReport](ctx, client.Cache, cache.RequestOptions{}, generator, generator.GenerateReport, apitype.DisruptionReport{})
 }
 
 type disruptionReportGenerator struct {
@@ -37,9 +38,9 @@ type disruptionReportGenerator struct {
 	ViewName string
 }
 
-func (c *disruptionReportGenerator) GenerateReport() (apitype.DisruptionReport, []error) {
+func (c *disruptionReportGenerator) GenerateReport(ctx context.Context) (apitype.DisruptionReport, []error) {
 	before := time.Now()
-	disruptionReport, err := c.getDisruptionDeltasFromBigQuery()
+	disruptionReport, err := c.getDisruptionDeltasFromBigQuery(ctx)
 	if err != nil {
 		return apitype.DisruptionReport{}, []error{err}
 	}
@@ -48,7 +49,7 @@ func (c *disruptionReportGenerator) GenerateReport() (apitype.DisruptionReport,
 	return disruptionReport, nil
 }
 
-func (c *disruptionReportGenerator) getDisruptionDeltasFromBigQuery() (apitype.DisruptionReport, error) {
+func (c *disruptionReportGenerator) getDisruptionDeltasFromBigQuery(ctx context.Context) (apitype.DisruptionReport, error) {
 	// We'll publish a metric for whatever is in the views, which need to be updated for each GA release:
 	queryString := fmt.Sprintf(`
 						SELECT *
@@ -56,7 +57,9 @@ func (c *disruptionReportGenerator) getDisruptionDeltasFromBigQuery() (apitype.D
 						WHERE LookbackDays = 3`, c.ViewName)
 
 	query := c.client.Query(queryString)
-	it, err := query.Read(context.TODO())
+	ctx, span := tracing.StartQuerySpan(ctx, "bigquery", query.Q)
+	defer span.End()
+	it, err := query.Read(ctx)
 	if err != nil {
 		log.WithError(err).Error("error querying disruption data from bigquery")
 		return apitype.DisruptionReport{}, err
@@ -0,0 +1,196 @@
+// Package failureclusters groups test failures whose output is nearly identical, across tests and jobs, so failures
+// sharing a cause such as a broken registry or an unreachable cloud API show up together. Outputs are compared with
+// MinHash signatures of their shingled words, and candidates found with locality sensitive hashing, so failures are
+// never compared pairwise.
+package failureclusters
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+const (
+	// maxFailures limits how many of the most recent failures are clustered.
+	maxFailures = 20000
+
+	maxListed       = 10
+	maxURLs         = 5
+	maxOutputLength = 1000
+)
+
+// Failure is a failed test run and its output.
+type Failure struct {
+	TestName string
+	JobName  string
+	URL      string
+	Output   string
+}
+
+// FailureClustersFromDB clusters the failures of the release's tests between start and end, returning the clusters of
+// at least two failures, largest first.
+func FailureClustersFromDB(dbc *db.DB, release string, start, end time.Time, threshold float64) ([]apitype.FailureCluster, error) {
+	failures := make([]Failure, 0)
+	res := dbc.DB.Table("prow_job_run_test_outputs").
+		Joins("JOIN prow_job_run_tests ON prow_job_run_test_outputs.prow_job_run_test_id = prow_job_run_tests.id").
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("JOIN prow_job_runs ON prow_job_run_tests.prow_job_run_id = prow_job_runs.id").
+		Joins("JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id").
+		Where("prow_jobs.release = ?", release).
+		Where("prow_job_runs.timestamp BETWEEN ? AND ?", start, end).
+		Where("prow_job_run_tests.status = 12").
+		Select("tests.name AS test_name, prow_jobs.name AS job_name, prow_job_runs.url, prow_job_run_test_outputs.output").
+		Order("prow_job_runs.timestamp DESC").
+		Limit(maxFailures).
+		Scan(&failures)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return Cluster(failures, threshold), nil
+}
+
+// TestFailuresFromDB returns the failures of the tests between start and end, newest first, in the runs of every job
+// but the excluded one.
+func TestFailuresFromDB(dbc *db.DB, testIDs []uint, excludeJobID uint, start, end time.Time) ([]Failure, error) {
+	failures := make([]Failure, 0)
+	res := dbc.DB.Table("prow_job_run_test_outputs").
+		Joins("JOIN prow_job_run_tests ON prow_job_run_test_outputs.prow_job_run_test_id = prow_job_run_tests.id").
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("JOIN prow_job_runs ON prow_job_run_tests.prow_job_run_id = prow_job_runs.id").
+		Joins("JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id").
+		Where("prow_job_run_tests.test_id IN ?", testIDs).
+		Where("prow_jobs.id <> ?", excludeJobID).
+		Where("prow_job_runs.timestamp BETWEEN ? AND ?", start, end).
+		Where("prow_job_run_tests.status = 12").
+		Select("tests.name AS test_name, prow_jobs.name AS job_name, prow_job_runs.url, prow_job_run_test_outputs.output").
+		Order("prow_job_runs.timestamp DESC").
+		Limit(maxFailures).
+		Scan(&failures)
+	return failures, res.Error
+}
+
+// Matching returns the candidates whose output's estimated similarity to the failure's is at least the threshold. It
+// compares the failure to each candidate, so it's meant for the failures of a single run.
+func Matching(failure Failure, candidates []Failure, threshold float64) []Failure {
+	sig := minHash(failure.Output)
+	matching := make([]Failure, 0)
+	for _, candidate := range candidates {
+		candidateSig := minHash(candidate.Output)
+		if similarity(&sig, &candidateSig) >= threshold {
+			matching = append(matching, candidate)
+		}
+	}
+	return matching
+}
+
+// Cluster groups failures whose outputs' estimated similarity is at least the threshold, between 0 and 1. Failures
+// should be ordered newest first, as each cluster's output and URLs are taken from its first failures.
+func Cluster(failures []Failure, threshold float64) []apitype.FailureCluster {
+	sigs := make([]signature, len(failures))
+	for i, f := range failures {
+		sigs[i] = minHash(f.Output)
+	}
+
+	// each bucket's failures are compared to the first failure in it, similar failures link through whichever band
+	// they share with it
+	parents := newUnionFind(len(failures))
+	buckets := map[[2]uint64]int{}
+	for i := range sigs {
+		for band, key := range bandKeys(&sigs[i]) {
+			bucket := [2]uint64{uint64(band), key}
+			first, ok := buckets[bucket]
+			if !ok {
+				buckets[bucket] = i
+				continue
+			}
+			if parents.find(first) != parents.find(i) && similarity(&sigs[first], &sigs[i]) >= threshold {
+				parents.union(first, i)
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	for i := range failures {
+		root := parents.find(i)
+		members[root] = append(members[root], i)
+	}
+
+	clusters := make([]apitype.FailureCluster, 0)
+	for _, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+		clusters = append(clusters, newCluster(failures, indexes))
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Failures != clusters[j].Failures {
+			return clusters[i].Failures > clusters[j].Failures
+		}
+		return clusters[i].Output < clusters[j].Output
+	})
+	return clusters
+}
+
+func newCluster(failures []Failure, indexes []int) apitype.FailureCluster {
+	tests, jobs := map[string]int{}, map[string]int{}
+	cluster := apitype.FailureCluster{
+		Failures: len(indexes),
+		Output:   failures[indexes[0]].Output,
+	}
+	if len(cluster.Output) > maxOutputLength {
+		cluster.Output = cluster.Output[:maxOutputLength]
+	}
+	for _, i := range indexes {
+		tests[failures[i].TestName]++
+		jobs[failures[i].JobName]++
+		if len(cluster.URLs) < maxURLs {
+			cluster.URLs = append(cluster.URLs, failures[i].URL)
+		}
+	}
+	cluster.TestCount, cluster.Tests = len(tests), mostCommon(tests)
+	cluster.JobCount, cluster.Jobs = len(jobs), mostCommon(jobs)
+	return cluster
+}
+
+// mostCommon returns the names with the highest counts.
+func mostCommon(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxListed {
+		names = names[:maxListed]
+	}
+	return names
+}
+
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	parents := make(unionFind, n)
+	for i := range parents {
+		parents[i] = i
+	}
+	return parents
+}
+
+func (u unionFind) find(i int) int {
+	for u[i] != i {
+		u[i] = u[u[i]]
+		i = u[i]
+	}
+	return i
+}
+
+func (u unionFind) union(a, b int) {
+	u[u.find(b)] = u.find(a)
+}
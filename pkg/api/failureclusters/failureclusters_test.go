@@ -0,0 +1,55 @@
+package failureclusters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCluster(t *testing.T) {
+	registry := "error pulling image: failed to reach registry.ci.openshift.org at 10.0.%s.4:443 after %s attempts: connection reset by peer while fetching manifest for the release payload"
+	failures := []Failure{
+		{TestName: "test a", JobName: "job 1", URL: "https://prow/1", Output: fmt.Sprintf(registry, "1", "3")},
+		{TestName: "test b", JobName: "job 2", URL: "https://prow/2", Output: fmt.Sprintf(registry, "27", "5")},
+		{TestName: "test a", JobName: "job 3", URL: "https://prow/3", Output: fmt.Sprintf(registry, "8", "12")},
+		{TestName: "test c", JobName: "job 1", URL: "https://prow/4", Output: "timed out waiting for the condition on deployments/console in namespace openshift-console"},
+		{TestName: "test d", JobName: "job 4", URL: "https://prow/5", Output: "expected 3 nodes to be ready but only 2 were ready after waiting for the machine set to scale up"},
+	}
+
+	clusters := Cluster(failures, 0.8)
+	require.Len(t, clusters, 1)
+	assert.Equal(t, 3, clusters[0].Failures)
+	assert.Equal(t, failures[0].Output, clusters[0].Output)
+	assert.Equal(t, 2, clusters[0].TestCount)
+	assert.Equal(t, []string{"test a", "test b"}, clusters[0].Tests)
+	assert.Equal(t, 3, clusters[0].JobCount)
+	assert.Equal(t, []string{"https://prow/1", "https://prow/2", "https://prow/3"}, clusters[0].URLs)
+}
+
+func TestMatching(t *testing.T) {
+	registry := "error pulling image: failed to reach registry.ci.openshift.org at 10.0.%s.4:443 after %s attempts: connection reset by peer while fetching manifest for the release payload"
+	failure := Failure{TestName: "test a", JobName: "job 1", Output: fmt.Sprintf(registry, "1", "3")}
+	candidates := []Failure{
+		{TestName: "test a", JobName: "job 2", Output: fmt.Sprintf(registry, "27", "5")},
+		{TestName: "test a", JobName: "job 3", Output: "timed out waiting for the condition on deployments/console in namespace openshift-console"},
+	}
+
+	assert.Equal(t, candidates[:1], Matching(failure, candidates, 0.8))
+	assert.Empty(t, Matching(failure, nil, 0.8))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t,
+		normalize("Pod 5b0c1d2e-aaaa-bbbb-cccc-0123456789ab at 10.0.0.1:6443 failed with 0xdeadbeef after 12s"),
+		normalize("pod 11111111-2222-3333-4444-555555555555 at 192.168.1.20:8080 failed with 0xcafef00d after 3s"))
+}
+
+func TestSimilarity(t *testing.T) {
+	a := minHash("the quick brown fox jumps over the lazy dog near the river bank today")
+	b := minHash("the quick brown fox jumps over the lazy dog near the river bank today")
+	c := minHash("an entirely unrelated failure message about storage volumes not attaching")
+	assert.Equal(t, 1.0, similarity(&a, &b))
+	assert.Less(t, similarity(&a, &c), 0.2)
+}
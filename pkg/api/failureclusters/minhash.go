@@ -0,0 +1,119 @@
+package failureclusters
+
+import (
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+const (
+	shingleSize = 3
+	bands       = 16
+	rowsPerBand = 4
+	numHashes   = bands * rowsPerBand
+)
+
+var (
+	// volatile parts of failure output, replaced so outputs differing only by them are identical
+	uuidRegexp   = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	hexRegexp    = regexp.MustCompile(`\b(0x)?[0-9a-f]{8,}\b`)
+	ipRegexp     = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`)
+	numberRegexp = regexp.MustCompile(`\d+`)
+
+	hashSeeds = newHashSeeds()
+)
+
+type signature [numHashes]uint64
+
+// normalize lower cases the output and masks ids, addresses and numbers.
+func normalize(output string) string {
+	output = strings.ToLower(output)
+	output = uuidRegexp.ReplaceAllString(output, "<uuid>")
+	output = hexRegexp.ReplaceAllString(output, "<hex>")
+	output = ipRegexp.ReplaceAllString(output, "<ip>")
+	return numberRegexp.ReplaceAllString(output, "<n>")
+}
+
+// shingles returns the hashes of each run of shingleSize words.
+func shingles(normalized string) []uint64 {
+	words := strings.Fields(normalized)
+	if len(words) < shingleSize {
+		return []uint64{hashString(strings.Join(words, " "))}
+	}
+	hashes := make([]uint64, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		hashes = append(hashes, hashString(strings.Join(words[i:i+shingleSize], " ")))
+	}
+	return hashes
+}
+
+// minHash returns the output's signature, where the fraction of equal values between two signatures estimates the
+// Jaccard similarity of their shingles.
+func minHash(output string) signature {
+	var sig signature
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, shingle := range shingles(normalize(output)) {
+		for i, seed := range hashSeeds {
+			if h := mix64((shingle ^ seed[1]) * seed[0]); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+func similarity(a, b *signature) float64 {
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / numHashes
+}
+
+// bandKeys hashes each band of the signature, signatures sharing any band key are candidates to compare.
+func bandKeys(sig *signature) [bands]uint64 {
+	var keys [bands]uint64
+	for b := range keys {
+		h := fnv.New64a()
+		var buf [8]byte
+		for _, v := range sig[b*rowsPerBand : (b+1)*rowsPerBand] {
+			for i := range buf {
+				buf[i] = byte(v >> (8 * i))
+			}
+			h.Write(buf[:]) //nolint:errcheck
+		}
+		keys[b] = h.Sum64()
+	}
+	return keys
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s)) //nolint:errcheck
+	return h.Sum64()
+}
+
+// newHashSeeds returns fixed odd multipliers and xor masks, so signatures are stable across runs.
+func newHashSeeds() [numHashes][2]uint64 {
+	var seeds [numHashes][2]uint64
+	state := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		return mix64(state)
+	}
+	for i := range seeds {
+		seeds[i] = [2]uint64{next() | 1, next()}
+	}
+	return seeds
+}
+
+// mix64 is the splitmix64 finalizer, spreading every input bit over the output.
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// GetFunnelFromDB returns the release's install or upgrade funnel days from start, oldest first, for the variant or
+// every variant when it is empty.
+func GetFunnelFromDB(dbc *db.DB, kind, release, variant string, start time.Time) ([]apitype.FunnelDay, error) {
+	days := make([]models.FunnelDay, 0)
+	q := dbc.DB.Where("kind = ? AND release = ? AND date >= ?", kind, release, start)
+	if variant != "" {
+		q = q.Where("variant = ?", variant)
+	}
+	if res := q.Order("date, variant").Find(&days); res.Error != nil {
+		return nil, res.Error
+	}
+
+	funnel := make([]apitype.FunnelDay, 0, len(days))
+	for _, day := range days {
+		funnel = append(funnel, funnelDay(day))
+	}
+	return funnel, nil
+}
+
+func funnelDay(day models.FunnelDay) apitype.FunnelDay {
+	result := apitype.FunnelDay{
+		Date:           day.Date.Format("2006-01-02"),
+		Variant:        day.Variant,
+		Attempts:       day.Attempts,
+		Successes:      day.Successes,
+		FailureReasons: make([]apitype.FunnelFailureReason, 0, len(day.FailureReasons)),
+	}
+	if day.Attempts > 0 {
+		result.SuccessPercentage = float64(day.Successes) / float64(day.Attempts) * 100
+	}
+	for reason, count := range day.FailureReasons {
+		result.FailureReasons = append(result.FailureReasons, apitype.FunnelFailureReason{Reason: reason, Count: count})
+	}
+	sort.Slice(result.FailureReasons, func(i, j int) bool {
+		a, b := result.FailureReasons[i], result.FailureReasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+	return result
+}
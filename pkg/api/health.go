@@ -133,7 +133,7 @@ func PrintOverallReleaseHealthFromDB(w http.ResponseWriter, dbc *db.DB, release
 	start := reportEnd.Add(-14 * 24 * time.Hour)
 	boundary := reportEnd.Add(-7 * 24 * time.Hour)
 	end := reportEnd
-	jobReports, err := query.JobReports(dbc, filterOpts, release, start, boundary, end)
+	jobReports, err := query.JobReports(dbc, filterOpts, release, Tenant(dbc.DB.Statement.Context), start, boundary, end, false)
 	if err != nil {
 		log.WithError(err).Error("error querying job reports")
 		return
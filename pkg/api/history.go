@@ -0,0 +1,210 @@
+package api
+
+import (
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// GetTestHistoryFromDB returns the test's weekly results in the release's jobs of the variant, oldest first.
+func GetTestHistoryFromDB(dbc *db.DB, release, testName, variant string) ([]apitype.WeeklyResult, error) {
+	rows := make([]models.TestWeeklyResult, 0)
+	res := dbc.DB.
+		Joins("JOIN tests ON tests.id = test_weekly_results.test_id").
+		Where("test_weekly_results.release = ? AND tests.name = ? AND test_weekly_results.variant = ?", release, testName, variant).
+		Order("week").
+		Find(&rows)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	results := make([]apitype.WeeklyResult, 0, len(rows))
+	for _, r := range rows {
+		results = append(results, weeklyResult(r.Week.Format("2006-01-02"), r.Runs, r.Successes, r.Flakes, r.Failures, 0))
+	}
+	return results, nil
+}
+
+// GetJobHistoryFromDB returns the job's weekly results, oldest first.
+func GetJobHistoryFromDB(dbc *db.DB, jobName string) ([]apitype.WeeklyResult, error) {
+	rows := make([]models.JobWeeklyResult, 0)
+	res := dbc.DB.
+		Joins("JOIN prow_jobs ON prow_jobs.id = job_weekly_results.prow_job_id").
+		Where("prow_jobs.name = ?", jobName).
+		Order("week").
+		Find(&rows)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	results := make([]apitype.WeeklyResult, 0, len(rows))
+	for _, r := range rows {
+		results = append(results, weeklyResult(r.Week.Format("2006-01-02"), r.Runs, r.Successes, 0, r.Failures, r.InfrastructureFailures))
+	}
+	return results, nil
+}
+
+// jobRunClassifications name the overall results of job runs.
+var jobRunClassifications = map[v1.JobOverallResult]string{
+	v1.JobSucceeded:             "succeeded",
+	v1.JobRunning:               "running",
+	v1.JobInfrastructureFailure: "infrastructure",
+	v1.JobFailureBeforeSetup:    "infrastructure",
+	v1.JobInstallFailure:        "install",
+	v1.JobUpgradeFailure:        "upgrade",
+	v1.JobTestFailure:           "test",
+	v1.JobAborted:               "aborted",
+	v1.JobUnknown:               "unknown",
+}
+
+// GetJobRunHistoryFromDB returns the job's runs of the days before end, with their results summarized per day, or
+// nil if there's no such job. With excludeInfraFailures, the days' pass rates leave out runs that failed due to CI
+// infrastructure.
+func GetJobRunHistoryFromDB(dbc *db.DB, jobID uint, days int, end time.Time,
+	excludeInfraFailures bool) (*apitype.JobRunHistory, error) {
+	job := models.ProwJob{}
+	res := dbc.DB.Where("id = ?", jobID).Limit(1).Find(&job)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	end = end.UTC()
+	start := end.Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+	runs := make([]models.ProwJobRun, 0)
+	res = dbc.DB.Select("id", "url", "timestamp", "duration", "cluster", "overall_result", "succeeded", "failed",
+		"infrastructure_failure", "failure_classification", "known_failure", "test_failures", "test_flakes",
+		"config_hash").
+		Where("prow_job_id = ? AND timestamp >= ? AND timestamp < ?", jobID, start, end).
+		Order("timestamp DESC").
+		Find(&runs)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	// the configuration of the last run before the window, so a change in its first run is marked
+	var previousHash string
+	res = dbc.DB.Model(&models.ProwJobRun{}).Select("config_hash").
+		Where("prow_job_id = ? AND timestamp < ? AND config_hash <> ''", jobID, start).
+		Order("timestamp DESC").
+		Limit(1).
+		Scan(&previousHash)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	history := &apitype.JobRunHistory{
+		ID:       job.ID,
+		Name:     job.Name,
+		Release:  job.Release,
+		Variants: job.Variants,
+		Start:    start,
+		End:      end,
+		Runs:     make([]apitype.JobHistoryRun, 0, len(runs)),
+		Days:     jobHistoryDays(runs, start, days, excludeInfraFailures),
+	}
+	for _, run := range runs {
+		classification, ok := jobRunClassifications[run.OverallResult]
+		if !ok {
+			classification = "unknown"
+		}
+		history.Runs = append(history.Runs, apitype.JobHistoryRun{
+			ID:                    run.ID,
+			URL:                   run.URL,
+			Timestamp:             run.Timestamp,
+			Duration:              run.Duration.Seconds(),
+			Cluster:               run.Cluster,
+			OverallResult:         run.OverallResult,
+			Classification:        classification,
+			InfrastructureFailure: run.InfrastructureFailure,
+			FailureClassification: run.FailureClassification,
+			KnownFailure:          run.KnownFailure,
+			TestFailures:          run.TestFailures,
+			TestFlakes:            run.TestFlakes,
+			ConfigHash:            run.ConfigHash,
+		})
+	}
+	markJobConfigChanges(history, previousHash)
+	return history, nil
+}
+
+// markJobConfigChanges marks the runs whose job configuration differs from the last known one before them, and the
+// days they ran on, starting from the configuration of the run before the history. Runs of an unknown configuration
+// are skipped.
+func markJobConfigChanges(history *apitype.JobRunHistory, previousHash string) {
+	days := map[string]int{}
+	for i, day := range history.Days {
+		days[day.Date] = i
+	}
+	for i := len(history.Runs) - 1; i >= 0; i-- {
+		run := &history.Runs[i]
+		if run.ConfigHash == "" {
+			continue
+		}
+		if previousHash != "" && run.ConfigHash != previousHash {
+			run.ConfigChanged = true
+			if day, ok := days[run.Timestamp.UTC().Format("2006-01-02")]; ok {
+				history.Days[day].ConfigChanged = true
+			}
+		}
+		previousHash = run.ConfigHash
+	}
+}
+
+// jobHistoryDays summarizes the runs of each of the days from start, in UTC, counting them as the weekly history does.
+// With excludeInfraFailures, infrastructure failures are left out of the days' pass rates, though still counted.
+func jobHistoryDays(runs []models.ProwJobRun, start time.Time, days int, excludeInfraFailures bool) []apitype.JobHistoryDay {
+	results := make([]apitype.JobHistoryDay, days)
+	for i := range results {
+		results[i].Date = start.AddDate(0, 0, i).Format("2006-01-02")
+	}
+	for _, run := range runs {
+		if run.Timestamp.Before(start) {
+			continue
+		}
+		i := int(run.Timestamp.Sub(start) / (24 * time.Hour))
+		if i >= days {
+			continue
+		}
+		results[i].Runs++
+		if run.Succeeded {
+			results[i].Successes++
+		}
+		if run.Failed {
+			results[i].Failures++
+		}
+		if run.InfrastructureFailure {
+			results[i].InfrastructureFailures++
+		}
+	}
+	for i := range results {
+		runs := results[i].Runs
+		if excludeInfraFailures {
+			runs -= results[i].InfrastructureFailures
+		}
+		if runs > 0 {
+			results[i].PassPercentage = float64(results[i].Successes) / float64(runs) * 100
+		}
+	}
+	return results
+}
+
+func weeklyResult(week string, runs, successes, flakes, failures, infrastructureFailures int) apitype.WeeklyResult {
+	result := apitype.WeeklyResult{
+		Week:                   week,
+		Runs:                   runs,
+		Successes:              successes,
+		Flakes:                 flakes,
+		Failures:               failures,
+		InfrastructureFailures: infrastructureFailures,
+	}
+	if runs > 0 {
+		result.PassPercentage = float64(successes+flakes) / float64(runs) * 100
+	}
+	return result
+}
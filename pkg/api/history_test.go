@@ -0,0 +1,88 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestJobHistoryDays(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time { return start.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour) }
+	runs := []models.ProwJobRun{
+		{Timestamp: at(2, 23), Failed: true, InfrastructureFailure: true},
+		{Timestamp: at(2, 1), Failed: true},
+		{Timestamp: at(2, 0), Succeeded: true},
+		{Timestamp: at(0, 12), Succeeded: true},
+		// outside the window
+		{Timestamp: at(3, 0), Succeeded: true},
+		{Timestamp: at(-1, 23), Failed: true},
+	}
+
+	days := jobHistoryDays(runs, start, 3, false)
+	assert.Len(t, days, 3)
+	assert.Equal(t, "2024-03-01", days[0].Date)
+	assert.Equal(t, 1, days[0].Runs)
+	assert.Equal(t, float64(100), days[0].PassPercentage)
+
+	assert.Equal(t, "2024-03-02", days[1].Date)
+	assert.Equal(t, 0, days[1].Runs, "days without runs are kept for the sparkline")
+	assert.Equal(t, float64(0), days[1].PassPercentage)
+
+	assert.Equal(t, "2024-03-03", days[2].Date)
+	assert.Equal(t, 3, days[2].Runs)
+	assert.Equal(t, 1, days[2].Successes)
+	assert.Equal(t, 2, days[2].Failures)
+	assert.Equal(t, 1, days[2].InfrastructureFailures)
+	assert.InDelta(t, 33.33, days[2].PassPercentage, 0.01)
+
+	days = jobHistoryDays(runs, start, 3, true)
+	assert.Equal(t, 3, days[2].Runs, "infrastructure failures are still counted")
+	assert.Equal(t, float64(50), days[2].PassPercentage)
+}
+
+func TestMarkJobConfigChanges(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time { return start.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour) }
+	history := &apitype.JobRunHistory{
+		// newest first
+		Runs: []apitype.JobHistoryRun{
+			{ID: 5, Timestamp: at(2, 6), ConfigHash: "b"},
+			{ID: 4, Timestamp: at(2, 3)},
+			{ID: 3, Timestamp: at(1, 12), ConfigHash: "b"},
+			{ID: 2, Timestamp: at(0, 12), ConfigHash: "a"},
+			{ID: 1, Timestamp: at(0, 6), ConfigHash: "c"},
+		},
+		Days: []apitype.JobHistoryDay{{Date: "2024-03-01"}, {Date: "2024-03-02"}, {Date: "2024-03-03"}},
+	}
+
+	markJobConfigChanges(history, "c")
+	changed := map[uint]bool{}
+	for _, run := range history.Runs {
+		changed[run.ID] = run.ConfigChanged
+	}
+	assert.Equal(t, map[uint]bool{1: false, 2: true, 3: true, 4: false, 5: false}, changed,
+		"runs of an unknown configuration are skipped, not counted as changes")
+	assert.True(t, history.Days[0].ConfigChanged)
+	assert.True(t, history.Days[1].ConfigChanged)
+	assert.False(t, history.Days[2].ConfigChanged)
+}
+
+func TestMarkJobConfigChangesWithoutPreviousRun(t *testing.T) {
+	history := &apitype.JobRunHistory{
+		Runs: []apitype.JobHistoryRun{
+			{ID: 2, Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), ConfigHash: "a"},
+			{ID: 1, Timestamp: time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC), ConfigHash: "a"},
+		},
+		Days: []apitype.JobHistoryDay{{Date: "2024-03-01"}},
+	}
+
+	markJobConfigChanges(history, "")
+	assert.False(t, history.Runs[0].ConfigChanged)
+	assert.False(t, history.Runs[1].ConfigChanged, "the first known configuration isn't a change")
+	assert.False(t, history.Days[0].ConfigChanged)
+}
@@ -16,7 +16,7 @@ import (
 )
 
 // PrintInstallJSONReportFromDB renders a report showing the success/fail rates of operator installation.
-func PrintInstallJSONReportFromDB(w http.ResponseWriter, dbc *db.DB, release string) {
+func PrintInstallJSONReportFromDB(w http.ResponseWriter, dbc *db.DB, release string, invalidVariants []string) {
 	excludedVariants := append(testidentification.DefaultExcludedVariants, "upgrade-minor")
 	exactTestNames := sets.NewString()
 	testPrefixes := sets.NewString(testidentification.OperatorInstallPrefix)
@@ -27,10 +27,10 @@ func PrintInstallJSONReportFromDB(w http.ResponseWriter, dbc *db.DB, release str
 	}
 
 	variantColumns, tests, err := VariantTestsReport(dbc, release, v1.CurrentReport,
-		exactTestNames, testPrefixes, sets.NewString(), excludedVariants)
+		exactTestNames, testPrefixes, sets.NewString(), excludedVariants, invalidVariants)
 	if err != nil {
 		log.WithError(err).Error("could not generate install report")
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Could not generate install report: " + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Could not generate install report: "+err.Error())
 		return
 	}
 
@@ -45,7 +45,7 @@ func PrintInstallJSONReportFromDB(w http.ResponseWriter, dbc *db.DB, release str
 	result, err := json.Marshal(summary)
 	if err != nil {
 		log.WithError(err).Error("could not generate install report")
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Could not generate install report: " + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Could not generate install report: "+err.Error())
 		return
 	}
 
@@ -54,9 +54,10 @@ func PrintInstallJSONReportFromDB(w http.ResponseWriter, dbc *db.DB, release str
 }
 
 // VariantTestsReport returns a set of all variant columns plus "All", and a map of testName to variant column to test results for that variant.
-// Caller can provide exact test names to match, test name prefixes, or test substrings.
+// Caller can provide exact test names to match, test name prefixes, or test substrings. Jobs with any of the
+// excludedVariants are left out, and there are no columns for invalidVariants.
 func VariantTestsReport(dbc *db.DB, release string, reportType v1.ReportType,
-	testNames, testPrefixes, testSubStrings sets.String, excludedVariants []string) (sets.String, map[string]map[string]apitype.Test, error) {
+	testNames, testPrefixes, testSubStrings sets.String, excludedVariants, invalidVariants []string) (sets.String, map[string]map[string]apitype.Test, error) {
 
 	// Build a list of all sub-strings to search for, we'll sort out exact matches later as these
 	// can pickup unintented tests.
@@ -64,7 +65,7 @@ func VariantTestsReport(dbc *db.DB, release string, reportType v1.ReportType,
 	testSearchStrings.Insert(testPrefixes.List()...)
 	testSearchStrings.Insert(testSubStrings.List()...)
 
-	testReports, err := query.TestReportsByVariant(dbc, release, reportType, testSearchStrings.List(), excludedVariants)
+	testReports, err := query.TestReportsByVariant(dbc, release, reportType, testSearchStrings.List(), excludedVariants, invalidVariants)
 	if err != nil {
 		return sets.NewString(), map[string]map[string]apitype.Test{}, err
 	}
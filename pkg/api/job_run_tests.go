@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// jobRunTestRow is a failed or flaky test of a run, with the start of its failure output.
+type jobRunTestRow struct {
+	ID        uint
+	TestName  string
+	SuiteName string
+	Status    int
+	Duration  float64
+	Output    string
+}
+
+// GetJobRunTestsFromDB returns the tests that failed or flaked in the run, by name, only the test with the name when
+// testName is set. The kept failure output and system-out are included when a test is named.
+func GetJobRunTestsFromDB(dbc *db.DB, prowJobRunID uint, testName string) ([]apitype.JobRunTest, error) {
+	rows := make([]jobRunTestRow, 0)
+	q := dbc.DB.Table("prow_job_run_tests").
+		Select(`prow_job_run_tests.id, tests.name AS test_name, suites.name AS suite_name, prow_job_run_tests.status,
+			prow_job_run_tests.duration, prow_job_run_test_outputs.output`).
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("LEFT JOIN suites ON suites.id = prow_job_run_tests.suite_id").
+		Joins("LEFT JOIN prow_job_run_test_outputs ON prow_job_run_test_outputs.prow_job_run_test_id = prow_job_run_tests.id").
+		Where("prow_job_run_tests.prow_job_run_id = ? AND prow_job_run_tests.deleted_at IS NULL", prowJobRunID).
+		Where("prow_job_run_tests.status IN ?", []int{int(v1.TestStatusFailure), int(v1.TestStatusFlake)})
+	if testName != "" {
+		q = q.Where("tests.name = ?", testName)
+	}
+	if res := q.Order("tests.name, suites.name").Scan(&rows); res.Error != nil {
+		return nil, res.Error
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	logs := make([]models.ProwJobRunTestLog, 0)
+	if len(ids) > 0 {
+		q := dbc.DB.Where("prow_job_run_test_id IN ?", ids)
+		if testName == "" {
+			q = q.Select("id, prow_job_run_test_id, truncated")
+		}
+		if res := q.Find(&logs); res.Error != nil {
+			return nil, res.Error
+		}
+	}
+	return jobRunTests(rows, logs)
+}
+
+// jobRunTests returns the run's tests with their kept logs, decompressing the outputs of those that were loaded.
+func jobRunTests(rows []jobRunTestRow, logs []models.ProwJobRunTestLog) ([]apitype.JobRunTest, error) {
+	byTest := map[uint]models.ProwJobRunTestLog{}
+	for _, testLog := range logs {
+		byTest[testLog.ProwJobRunTestID] = testLog
+	}
+
+	tests := make([]apitype.JobRunTest, 0, len(rows))
+	for _, row := range rows {
+		test := apitype.JobRunTest{
+			ID:        row.ID,
+			TestName:  row.TestName,
+			SuiteName: row.SuiteName,
+			Status:    "failure",
+			Duration:  row.Duration,
+			Output:    row.Output,
+		}
+		if v1.TestStatus(row.Status) == v1.TestStatusFlake {
+			test.Status = "flake"
+		}
+		if testLog, ok := byTest[row.ID]; ok {
+			test.HasLog, test.LogTruncated = true, testLog.Truncated
+			failureOutput, systemOut, err := testLog.Outputs()
+			if err != nil {
+				return nil, fmt.Errorf("error decompressing the log of test %d: %w", row.ID, err)
+			}
+			test.FailureOutput, test.SystemOut = failureOutput, systemOut
+		}
+		tests = append(tests, test)
+	}
+	return tests, nil
+}
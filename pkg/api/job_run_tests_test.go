@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestJobRunTests(t *testing.T) {
+	rows := []jobRunTestRow{
+		{ID: 1, TestName: "failed", SuiteName: "openshift-tests", Status: 12, Output: "expected 1"},
+		{ID: 2, TestName: "flaked", SuiteName: "openshift-tests", Status: 13},
+		{ID: 3, TestName: "log expired", Status: 12, Output: "timed out"},
+	}
+	fullLog := models.NewProwJobRunTestLog("expected 1, got 2", "starting")
+	fullLog.ProwJobRunTestID = 1
+
+	tests, err := jobRunTests(rows, []models.ProwJobRunTestLog{*fullLog, {ProwJobRunTestID: 2, Truncated: true}})
+	require.NoError(t, err)
+	require.Len(t, tests, 3)
+
+	assert.Equal(t, "failure", tests[0].Status)
+	assert.True(t, tests[0].HasLog)
+	assert.Equal(t, "expected 1", tests[0].Output)
+	assert.Equal(t, "expected 1, got 2", tests[0].FailureOutput)
+	assert.Equal(t, "starting", tests[0].SystemOut)
+
+	// a listing only loads whether the log is kept, not its outputs
+	assert.Equal(t, "flake", tests[1].Status)
+	assert.True(t, tests[1].HasLog)
+	assert.True(t, tests[1].LogTruncated)
+	assert.Empty(t, tests[1].FailureOutput)
+
+	assert.False(t, tests[2].HasLog)
+
+	_, err = jobRunTests(rows[:1], []models.ProwJobRunTestLog{{ProwJobRunTestID: 1, FailureOutput: []byte("not zstd")}})
+	assert.Error(t, err)
+}
@@ -73,6 +73,10 @@ func JobsRunsReportFromDB(dbc *db.DB, filterOpts *filter.FilterOptions, release
 	if len(release) > 0 {
 		q = q.Where("release = ?", release)
 	}
+	if tenant := Tenant(dbc.DB.Statement.Context); tenant != "" {
+		q = q.Where("(release, name) IN (?)",
+			dbc.DB.Model(&models.ProwJob{}).Select("release", "name").Where("tenant = ?", tenant))
+	}
 
 	q = q.Where("timestamp < ?", reportEnd.UnixMilli())
 
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// DefaultProwJobTimeout is prow's timeout for jobs that don't set their own.
+const DefaultProwJobTimeout = 4 * time.Hour
+
+// GetJobTimeoutRisksFromDB lists the release's jobs whose P95 duration over the last week is at least minPercentage of
+// their timeout, or that had runs time out, closest to timing out first.
+func GetJobTimeoutRisksFromDB(dbc *db.DB, release string, minPercentage float64, reportEnd time.Time) ([]apitype.JobTimeoutRisk, error) {
+	stats, err := query.JobDurations(dbc, release, DefaultProwJobTimeout, reportEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	risks := jobTimeoutRisks(stats, minPercentage)
+	if len(risks) == 0 {
+		return risks, nil
+	}
+
+	jobIDs := make([]uint, 0, len(risks))
+	for _, risk := range risks {
+		jobIDs = append(jobIDs, risk.JobID)
+	}
+	steps, err := query.SlowestJobSteps(dbc, jobIDs, reportEnd.Add(-7*24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	for i := range risks {
+		if step, ok := steps[risks[i].JobID]; ok {
+			risks[i].SlowestStep, risks[i].SlowestStepDuration = step.Name, step.Duration
+		}
+	}
+	return risks, nil
+}
+
+func jobTimeoutRisks(stats []query.JobDurationStats, minPercentage float64) []apitype.JobTimeoutRisk {
+	risks := make([]apitype.JobTimeoutRisk, 0)
+	for _, s := range stats {
+		timeout := s.Timeout
+		if timeout == 0 {
+			timeout = DefaultProwJobTimeout
+		}
+		percentage := s.CurrentP95 / timeout.Seconds() * 100
+		if percentage < minPercentage && s.CurrentTimedOutRuns == 0 {
+			continue
+		}
+		risks = append(risks, apitype.JobTimeoutRisk{
+			JobID:                s.ID,
+			JobName:              s.Name,
+			Timeout:              timeout.Seconds(),
+			DefaultTimeout:       s.Timeout == 0,
+			CurrentRuns:          s.CurrentRuns,
+			CurrentP95:           s.CurrentP95,
+			CurrentMax:           s.CurrentMax,
+			PreviousP95:          s.PreviousP95,
+			TimeoutPercentage:    percentage,
+			CurrentTimedOutRuns:  s.CurrentTimedOutRuns,
+			PreviousTimedOutRuns: s.PreviousTimedOutRuns,
+		})
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		return risks[i].TimeoutPercentage > risks[j].TimeoutPercentage
+	})
+	return risks
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestJobTimeoutRisks(t *testing.T) {
+	hours := func(h float64) float64 { return h * 3600 }
+	stats := []query.JobDurationStats{
+		{ID: 1, Name: "fast", Timeout: 4 * time.Hour, CurrentP95: hours(1)},
+		{ID: 2, Name: "close", Timeout: 2 * time.Hour, CurrentP95: hours(1.8), PreviousP95: hours(1.5)},
+		{ID: 3, Name: "default timeout", CurrentP95: hours(3.5)},
+		{ID: 4, Name: "timed out once", Timeout: 4 * time.Hour, CurrentP95: hours(2), CurrentTimedOutRuns: 1},
+	}
+
+	risks := jobTimeoutRisks(stats, 80)
+	names := make([]string, 0, len(risks))
+	for _, risk := range risks {
+		names = append(names, risk.JobName)
+	}
+	assert.Equal(t, []string{"close", "default timeout", "timed out once"}, names)
+
+	assert.Equal(t, 90.0, risks[0].TimeoutPercentage)
+	assert.Equal(t, hours(2), risks[0].Timeout)
+	assert.False(t, risks[0].DefaultTimeout)
+	assert.True(t, risks[1].DefaultTimeout)
+	assert.Equal(t, hours(4), risks[1].Timeout)
+}
@@ -55,9 +55,9 @@ func (jobs jobsAPIResult) limit(req *http.Request) jobsAPIResult {
 	return jobs
 }
 
-// PrintVariantReportFromDB
+// PrintVariantReportFromDB renders pass rates for each variant of the release, except invalidVariants.
 func PrintVariantReportFromDB(w http.ResponseWriter, req *http.Request,
-	dbc *db.DB, release string, reportEnd time.Time) {
+	dbc *db.DB, release string, reportEnd time.Time, invalidVariants []string) {
 	// Preferred method of slicing is with start->boundary->end query params in the format ?start=2021-12-02&boundary=2021-12-07.
 	// 'end' can be specified if you wish to view historical reports rather than now, which is assumed if end param is absent.
 	var start time.Time
@@ -69,7 +69,7 @@ func PrintVariantReportFromDB(w http.ResponseWriter, req *http.Request,
 	if startParam != "" {
 		start, err = time.Parse("2006-01-02", startParam)
 		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding start param: %s", err.Error())})
+			RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("Error decoding start param: %s", err.Error()))
 			return
 		}
 	} else if req.URL.Query().Get("period") == periodTwoDay {
@@ -86,7 +86,7 @@ func PrintVariantReportFromDB(w http.ResponseWriter, req *http.Request,
 	if boundaryParam != "" {
 		boundary, err = time.Parse("2006-01-02", boundaryParam)
 		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding boundary param: %s", err.Error())})
+			RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("Error decoding boundary param: %s", err.Error()))
 			return
 		}
 	} else if req.URL.Query().Get("period") == periodTwoDay {
@@ -101,7 +101,7 @@ func PrintVariantReportFromDB(w http.ResponseWriter, req *http.Request,
 	if endParam != "" {
 		end, err = time.Parse("2006-01-02", endParam)
 		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding end param: %s", err.Error())})
+			RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("Error decoding end param: %s", err.Error()))
 			return
 		}
 	} else {
@@ -111,9 +111,9 @@ func PrintVariantReportFromDB(w http.ResponseWriter, req *http.Request,
 
 	log.Debugf("Querying between %s -> %s -> %s", start.Format(time.RFC3339), boundary.Format(time.RFC3339), end.Format(time.RFC3339))
 
-	variantsResult, err := query.VariantReports(dbc, release, start, boundary, end)
+	variantsResult, err := query.VariantReports(dbc, release, start, boundary, end, invalidVariants)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building variant report:" + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Error building variant report:"+err.Error())
 		return
 	}
 
@@ -130,7 +130,7 @@ func PrintJobsReportFromDB(w http.ResponseWriter, req *http.Request,
 	if queryFilter != "" {
 		fil = &filter.Filter{}
 		if err := json.Unmarshal([]byte(queryFilter), fil); err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
+			RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
 			return
 		}
 	}
@@ -146,7 +146,7 @@ func PrintJobsReportFromDB(w http.ResponseWriter, req *http.Request,
 	if startParam != "" {
 		start, err = time.Parse("2006-01-02", startParam)
 		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding start param: %s", err.Error())})
+			RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("Error decoding start param: %s", err.Error()))
 			return
 		}
 	}
@@ -157,7 +157,7 @@ func PrintJobsReportFromDB(w http.ResponseWriter, req *http.Request,
 	if boundaryParam != "" {
 		boundary, err = time.Parse("2006-01-02", boundaryParam)
 		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding boundary param: %s", err.Error())})
+			RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("Error decoding boundary param: %s", err.Error()))
 			return
 		}
 	}
@@ -166,7 +166,7 @@ func PrintJobsReportFromDB(w http.ResponseWriter, req *http.Request,
 	if endParam != "" {
 		end, err = time.Parse("2006-01-02", endParam)
 		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding end param: %s", err.Error())})
+			RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("Error decoding end param: %s", err.Error()))
 			return
 		}
 	}
@@ -175,20 +175,25 @@ func PrintJobsReportFromDB(w http.ResponseWriter, req *http.Request,
 
 	filterOpts, err := filter.FilterOptionsFromRequest(req, currentPassPercentage, apitype.SortDescending)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Error building job report:"+err.Error())
 		return
 	}
 
-	jobsResult, err := JobReportsFromDB(dbc, release, req.URL.Query().Get("period"), filterOpts, start, boundary, end, reportEnd)
+	excludeInfraFailures, _ := strconv.ParseBool(req.URL.Query().Get("excludeInfraFailures"))
+	jobsResult, err := JobReportsFromDB(dbc, release, req.URL.Query().Get("period"), filterOpts, start, boundary, end,
+		reportEnd, excludeInfraFailures)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Error building job report:"+err.Error())
 		return
 	}
 
 	RespondWithJSON(http.StatusOK, w, jobsResult)
 }
 
-func JobReportsFromDB(dbc *db.DB, release, period string, filterOpts *filter.FilterOptions, start, boundary, end, reportEnd time.Time) ([]apitype.Job, error) {
+// JobReportsFromDB returns the release's job reports for the period, or the windows either side of boundary when
+// they're set. With excludeInfraFailures, runs that failed due to CI infrastructure aren't counted.
+func JobReportsFromDB(dbc *db.DB, release, period string, filterOpts *filter.FilterOptions, start, boundary, end,
+	reportEnd time.Time, excludeInfraFailures bool) ([]apitype.Job, error) {
 
 	// set a default filter if none provided
 	if filterOpts == nil {
@@ -220,7 +225,8 @@ func JobReportsFromDB(dbc *db.DB, release, period string, filterOpts *filter.Fil
 		end = reportEnd
 	}
 
-	jobsResult, err := query.JobReports(dbc, filterOpts, release, start, boundary, end)
+	jobsResult, err := query.JobReports(dbc, filterOpts, release, Tenant(dbc.DB.Statement.Context), start, boundary, end,
+		excludeInfraFailures)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// maxLoaderErrors caps how many of a job's errors are listed.
+const maxLoaderErrors = 100
+
+// GetLoaderErrorJobsFromDB summarizes, by job, the loader's errors since the time, the jobs with the most failed runs
+// first. An empty release includes every release's jobs.
+func GetLoaderErrorJobsFromDB(dbc *db.DB, loader, release string, since time.Time) ([]apitype.LoaderErrorJob, error) {
+	loaderErrs := make([]models.LoaderError, 0)
+	q := dbc.DB.Where("loader = ? AND created_at >= ?", loader, since)
+	if release != "" {
+		q = q.Where("release = ?", release)
+	}
+	if res := q.Order("created_at").Find(&loaderErrs); res.Error != nil {
+		return nil, res.Error
+	}
+	return summarizeLoaderErrors(loaderErrs), nil
+}
+
+// GetJobLoaderErrorsFromDB returns the loader's latest errors importing the job's runs since the time, the latest
+// first.
+func GetJobLoaderErrorsFromDB(dbc *db.DB, loader, jobName string, since time.Time) ([]models.LoaderError, error) {
+	loaderErrs := make([]models.LoaderError, 0)
+	res := dbc.DB.Where("loader = ? AND job_name = ? AND created_at >= ?", loader, jobName, since).
+		Order("created_at DESC").
+		Limit(maxLoaderErrors).
+		Find(&loaderErrs)
+	return loaderErrs, res.Error
+}
+
+// summarizeLoaderErrors groups the errors, oldest first, by job.
+func summarizeLoaderErrors(loaderErrs []models.LoaderError) []apitype.LoaderErrorJob {
+	byJob := map[string]*apitype.LoaderErrorJob{}
+	runs := map[string]map[uint]bool{}
+	for _, loaderErr := range loaderErrs {
+		job, ok := byJob[loaderErr.JobName]
+		if !ok {
+			job = &apitype.LoaderErrorJob{
+				JobName:   loaderErr.JobName,
+				Release:   loaderErr.Release,
+				FirstSeen: loaderErr.CreatedAt,
+				Classes:   map[string]int{},
+			}
+			byJob[loaderErr.JobName] = job
+			runs[loaderErr.JobName] = map[uint]bool{}
+		}
+		job.Errors++
+		job.Classes[loaderErr.Class]++
+		job.LastSeen = loaderErr.CreatedAt
+		job.LastMessage, job.LastPath = loaderErr.Message, loaderErr.Path
+		runs[loaderErr.JobName][loaderErr.ProwJobRunID] = true
+	}
+
+	jobs := make([]apitype.LoaderErrorJob, 0, len(byJob))
+	for name, job := range byJob {
+		job.Runs = len(runs[name])
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Runs != jobs[j].Runs {
+			return jobs[i].Runs > jobs[j].Runs
+		}
+		return jobs[i].JobName < jobs[j].JobName
+	})
+	return jobs
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestSummarizeLoaderErrors(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	loaderErr := func(job string, run uint, hours int, class, message string) models.LoaderError {
+		return models.LoaderError{
+			Model:        models.Model{CreatedAt: start.Add(time.Duration(hours) * time.Hour)},
+			Loader:       "prow",
+			Release:      "4.16",
+			JobName:      job,
+			ProwJobRunID: run,
+			Path:         "logs/" + job,
+			Class:        class,
+			Message:      message,
+		}
+	}
+
+	jobs := summarizeLoaderErrors([]models.LoaderError{
+		loaderErr("e2e-aws", 1, 0, models.LoaderErrorJUnit, "unexpected EOF"),
+		loaderErr("e2e-gcp", 10, 1, models.LoaderErrorDatabase, "deadlock detected"),
+		loaderErr("e2e-aws", 1, 2, models.LoaderErrorJUnit, "unexpected EOF"),
+		loaderErr("e2e-aws", 2, 3, models.LoaderErrorGCSPath, "gcs path empty"),
+	})
+
+	require.Len(t, jobs, 2)
+	aws := jobs[0]
+	assert.Equal(t, "e2e-aws", aws.JobName)
+	assert.Equal(t, 2, aws.Runs)
+	assert.Equal(t, 3, aws.Errors)
+	assert.Equal(t, start, aws.FirstSeen)
+	assert.Equal(t, start.Add(3*time.Hour), aws.LastSeen)
+	assert.Equal(t, map[string]int{models.LoaderErrorJUnit: 2, models.LoaderErrorGCSPath: 1}, aws.Classes)
+	assert.Equal(t, "gcs path empty", aws.LastMessage)
+	assert.Equal(t, "e2e-gcp", jobs[1].JobName)
+	assert.Equal(t, 1, jobs[1].Runs)
+
+	assert.Empty(t, summarizeLoaderErrors(nil))
+}
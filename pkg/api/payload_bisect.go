@@ -0,0 +1,105 @@
+package api
+
+import (
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// payloadSplit is where a test's results in a stream's payloads are split into before and after a drop.
+type payloadSplit struct {
+	lastGood, firstBad int
+	before, after      float64
+	pValue             float64
+}
+
+// GetPayloadBisect finds the payloads of the stream released between start and end where the test's pass rate dropped,
+// and the pull requests first included in them.
+func GetPayloadBisect(dbc *db.DB, release, stream, arch, testName string, start, end time.Time, confidence int) (apitype.PayloadBisect, error) {
+	bisect := apitype.PayloadBisect{
+		TestName:        testName,
+		Release:         release,
+		Stream:          stream,
+		Architecture:    arch,
+		Payloads:        make([]apitype.PayloadTestResult, 0),
+		SuspectPayloads: make([]string, 0),
+		PullRequests:    make([]apitype.PayloadBisectPullRequest, 0),
+	}
+
+	payloads, err := query.GetPayloadTestCounts(dbc.DB, release, stream, arch, testName, start, end)
+	if err != nil {
+		return bisect, err
+	}
+	for _, p := range payloads {
+		bisect.Payloads = append(bisect.Payloads, apitype.PayloadTestResult{
+			ReleaseTag:  p.ReleaseTag,
+			Phase:       p.Phase,
+			ReleaseTime: p.ReleaseTime,
+			Passes:      p.Passes,
+			Failures:    p.Failures,
+		})
+	}
+
+	split, ok := bisectPayloads(payloads, confidence)
+	if !ok {
+		return bisect, nil
+	}
+	bisect.LastGoodPayload = payloads[split.lastGood].ReleaseTag
+	bisect.FirstBadPayload = payloads[split.firstBad].ReleaseTag
+	bisect.BeforePassPercentage, bisect.AfterPassPercentage = split.before, split.after
+	bisect.PValue = split.pValue
+
+	suspectIDs := make([]uint, 0)
+	for _, p := range payloads[split.lastGood+1 : split.firstBad+1] {
+		bisect.SuspectPayloads = append(bisect.SuspectPayloads, p.ReleaseTag)
+		suspectIDs = append(suspectIDs, p.ID)
+	}
+	prs, err := query.GetPullRequestsForPayloads(dbc.DB, suspectIDs)
+	if err != nil {
+		return bisect, err
+	}
+	for _, pr := range prs {
+		bisect.PullRequests = append(bisect.PullRequests, apitype.PayloadBisectPullRequest(pr))
+	}
+	return bisect, nil
+}
+
+// bisectPayloads splits the payloads that ran the test, oldest first, where the later payloads are most significantly
+// worse than the earlier ones, returning the indexes of the payloads either side of the split.
+func bisectPayloads(payloads []models.PayloadTestCounts, confidence int) (payloadSplit, bool) {
+	ran := make([]int, 0, len(payloads))
+	var totalPasses, totalFailures int
+	for i, p := range payloads {
+		if p.Passes+p.Failures > 0 {
+			ran = append(ran, i)
+			totalPasses += p.Passes
+			totalFailures += p.Failures
+		}
+	}
+
+	var best payloadSplit
+	found := false
+	var beforePasses, beforeFailures int
+	for i := 1; i < len(ran); i++ {
+		beforePasses += payloads[ran[i-1]].Passes
+		beforeFailures += payloads[ran[i-1]].Failures
+		afterPasses, afterFailures := totalPasses-beforePasses, totalFailures-beforeFailures
+
+		pValue, worse := SignificantlyWorse(beforeFailures, beforePasses, afterFailures, afterPasses, confidence)
+		if !worse || (found && pValue >= best.pValue) {
+			continue
+		}
+		found = true
+		best = payloadSplit{
+			lastGood: ran[i-1],
+			firstBad: ran[i],
+			before:   float64(beforePasses) / float64(beforePasses+beforeFailures) * 100,
+			after:    float64(afterPasses) / float64(afterPasses+afterFailures) * 100,
+			pValue:   pValue,
+		}
+	}
+	return best, found
+}
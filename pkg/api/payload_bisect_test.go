@@ -0,0 +1,34 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestBisectPayloads(t *testing.T) {
+	payload := func(passes, failures int) models.PayloadTestCounts {
+		return models.PayloadTestCounts{Passes: passes, Failures: failures}
+	}
+
+	split, ok := bisectPayloads([]models.PayloadTestCounts{
+		payload(10, 0), payload(9, 1), payload(10, 0),
+		// the test didn't run in these, so the drop could have come from either
+		payload(0, 0), payload(0, 0),
+		payload(3, 7), payload(2, 8), payload(4, 6),
+	}, 95)
+	require.True(t, ok)
+	assert.Equal(t, 2, split.lastGood)
+	assert.Equal(t, 5, split.firstBad)
+	assert.InDelta(t, 96.67, split.before, 0.01)
+	assert.InDelta(t, 30, split.after, 0.01)
+
+	_, ok = bisectPayloads([]models.PayloadTestCounts{payload(9, 1), payload(10, 0), payload(8, 2), payload(9, 1)}, 95)
+	assert.False(t, ok, "no significant drop")
+
+	_, ok = bisectPayloads([]models.PayloadTestCounts{payload(2, 8), payload(10, 0)}, 95)
+	assert.False(t, ok, "improvements aren't bisected")
+}
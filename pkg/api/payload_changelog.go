@@ -0,0 +1,103 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// payloadChangelogRegressionWindow is how long after a payload test regressions are listed with its changelog.
+const payloadChangelogRegressionWindow = 48 * time.Hour
+
+// GetPayloadChangelogFromDB returns the changelog of a payload loaded into sippy, with the test regressions opened
+// after it, or nil if there's no such payload.
+func GetPayloadChangelogFromDB(dbc *db.DB, releaseTag string) (*apitype.PayloadChangelog, error) {
+	payload := models.ReleaseTag{}
+	res := dbc.DB.Where("release_tag = ?", releaseTag).Limit(1).Find(&payload)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	repositories := make([]models.ReleaseRepository, 0)
+	if res := dbc.DB.Where("release_tag_id = ?", payload.ID).Find(&repositories); res.Error != nil {
+		return nil, res.Error
+	}
+	prs, err := query.GetPullRequestsForPayloads(dbc.DB, []uint{payload.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	regressions := make([]models.TestRegression, 0)
+	res = dbc.DB.Where("release = ? AND first_seen > ? AND first_seen <= ?", payload.Release, payload.ReleaseTime,
+		payload.ReleaseTime.Add(payloadChangelogRegressionWindow)).
+		Order("first_seen").
+		Find(&regressions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return buildPayloadChangelog(payload, repositories, prs, regressions), nil
+}
+
+// buildPayloadChangelog groups the payload's pull requests by the repository whose images they bumped. Pull requests
+// of a repository the changelog didn't list are grouped under their own name.
+func buildPayloadChangelog(payload models.ReleaseTag, repositories []models.ReleaseRepository,
+	prs []models.PayloadPullRequest, regressions []models.TestRegression) *apitype.PayloadChangelog {
+	changelog := &apitype.PayloadChangelog{
+		ReleaseTag:         payload.ReleaseTag,
+		Release:            payload.Release,
+		Stream:             payload.Stream,
+		Architecture:       payload.Architecture,
+		Phase:              payload.Phase,
+		ReleaseTime:        payload.ReleaseTime,
+		PreviousReleaseTag: payload.PreviousReleaseTag,
+		KubernetesVersion:  payload.KubernetesVersion,
+		CurrentOSVersion:   payload.CurrentOSVersion,
+		PreviousOSVersion:  payload.PreviousOSVersion,
+		OSDiffURL:          payload.OSDiffURL,
+		PullRequests:       len(prs),
+		Components:         make([]apitype.PayloadChangelogComponent, 0, len(repositories)),
+		Regressions:        regressions,
+	}
+
+	components := map[string]int{}
+	component := func(name string) *apitype.PayloadChangelogComponent {
+		i, ok := components[name]
+		if !ok {
+			i = len(changelog.Components)
+			components[name] = i
+			changelog.Components = append(changelog.Components, apitype.PayloadChangelogComponent{
+				Name:         name,
+				PullRequests: make([]apitype.PayloadChangelogPullRequest, 0),
+			})
+		}
+		return &changelog.Components[i]
+	}
+	for _, repository := range repositories {
+		c := component(repository.Name)
+		c.FromCommit = repository.FromCommit
+		c.ToCommit = repository.ToCommit
+		c.DiffURL = repository.DiffURL
+	}
+	for _, pr := range prs {
+		c := component(pr.Name)
+		c.PullRequests = append(c.PullRequests, apitype.PayloadChangelogPullRequest{
+			URL:           pr.URL,
+			PullRequestID: pr.PullRequestID,
+			Description:   pr.Description,
+			BugURL:        pr.BugURL,
+		})
+	}
+
+	sort.Slice(changelog.Components, func(i, j int) bool {
+		return changelog.Components[i].Name < changelog.Components[j].Name
+	})
+	return changelog
+}
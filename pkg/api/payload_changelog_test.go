@@ -0,0 +1,57 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestBuildPayloadChangelog(t *testing.T) {
+	payload := models.ReleaseTag{
+		ReleaseTag:         "4.16.0-0.nightly-2024-03-14-120000",
+		Release:            "4.16",
+		Stream:             "nightly",
+		Architecture:       "amd64",
+		Phase:              "Rejected",
+		ReleaseTime:        time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC),
+		PreviousReleaseTag: "4.16.0-0.nightly-2024-03-13-120000",
+	}
+	repositories := []models.ReleaseRepository{
+		{Name: "installer", FromCommit: "ba94031", ToCommit: "303c9d4",
+			DiffURL: "https://github.com/openshift/installer/compare/ba94031...303c9d4"},
+		{Name: "console", FromCommit: "46aeb86", ToCommit: "7d748cb",
+			DiffURL: "https://github.com/openshift/console/compare/46aeb86...7d748cb"},
+	}
+	prs := []models.PayloadPullRequest{
+		{Name: "installer", URL: "https://github.com/openshift/installer/pull/1", PullRequestID: "1"},
+		{Name: "installer", URL: "https://github.com/openshift/installer/pull/2", PullRequestID: "2",
+			BugURL: "https://issues.redhat.com/browse/OCPBUGS-1"},
+		{Name: "api", URL: "https://github.com/openshift/api/pull/3", PullRequestID: "3"},
+	}
+	regressions := []models.TestRegression{{TestName: "test a", FirstSeen: payload.ReleaseTime.Add(6 * time.Hour)}}
+
+	changelog := buildPayloadChangelog(payload, repositories, prs, regressions)
+	assert.Equal(t, payload.ReleaseTag, changelog.ReleaseTag)
+	assert.Equal(t, payload.PreviousReleaseTag, changelog.PreviousReleaseTag)
+	assert.Equal(t, 3, changelog.PullRequests)
+	assert.Equal(t, regressions, changelog.Regressions)
+
+	require.Len(t, changelog.Components, 3)
+	assert.Equal(t, "api", changelog.Components[0].Name, "pull requests of unlisted repositories are kept")
+	assert.Empty(t, changelog.Components[0].DiffURL)
+	assert.Len(t, changelog.Components[0].PullRequests, 1)
+
+	assert.Equal(t, "console", changelog.Components[1].Name)
+	assert.Equal(t, "7d748cb", changelog.Components[1].ToCommit)
+	assert.Empty(t, changelog.Components[1].PullRequests, "an image bump without pull requests is still listed")
+
+	assert.Equal(t, "installer", changelog.Components[2].Name)
+	assert.Equal(t, "ba94031", changelog.Components[2].FromCommit)
+	assert.Equal(t, "303c9d4", changelog.Components[2].ToCommit)
+	require.Len(t, changelog.Components[2].PullRequests, 2)
+	assert.Equal(t, "https://issues.redhat.com/browse/OCPBUGS-1", changelog.Components[2].PullRequests[1].BugURL)
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+const (
+	// payloadGateHealthWindow is how far back a blocking job's health is measured.
+	payloadGateHealthWindow = 7 * 24 * time.Hour
+	// payloadGateMinRuns is the fewest runs a blocking job's health is judged from.
+	payloadGateMinRuns = 5
+	// payloadGateUnhealthyPassPercentage is the pass rate below which a blocking job is unhealthy, so its failures
+	// aren't held against a payload.
+	payloadGateUnhealthyPassPercentage = 50
+	// maxPayloadGateReasonTests caps how many tests a reason names.
+	maxPayloadGateReasonTests = 5
+)
+
+// payloadGateRun is a blocking job's run as loaded into sippy.
+type payloadGateRun struct {
+	ID                    uint
+	ProwJobID             uint
+	ProwJobName           string
+	InfrastructureFailure bool
+	FailedTestNames       pq.StringArray `gorm:"type:text[]"`
+}
+
+// payloadGateJobHealth counts a job's recent runs and successes.
+type payloadGateJobHealth struct {
+	ProwJobID uint
+	Runs      int
+	Successes int
+}
+
+// GatePayloadFromDB advises whether a payload loaded into sippy should be accepted, or returns nil if there's no such
+// payload.
+func GatePayloadFromDB(dbc *db.DB, releaseTag string, reportEnd time.Time) (*apitype.PayloadGate, error) {
+	payload := models.ReleaseTag{}
+	res := dbc.DB.Where("release_tag = ?", releaseTag).Limit(1).Find(&payload)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	jobRuns := make([]models.ReleaseJobRun, 0)
+	if res := dbc.DB.Where("release_tag_id = ? AND kind = ?", payload.ID, "Blocking").Find(&jobRuns); res.Error != nil {
+		return nil, res.Error
+	}
+	request := apitype.PayloadGateRequest{
+		ReleaseTag:   payload.ReleaseTag,
+		Release:      payload.Release,
+		Stream:       payload.Stream,
+		Architecture: payload.Architecture,
+		BlockingJobs: map[string]apitype.PayloadGateJobRunState{},
+	}
+	for _, jobRun := range jobRuns {
+		request.BlockingJobs[jobRun.JobName] = apitype.PayloadGateJobRunState{State: jobRun.State, URL: jobRun.URL}
+	}
+	return GatePayload(dbc, request, reportEnd)
+}
+
+// GatePayload advises whether a payload should be accepted, from how its blocking jobs' runs failed, the jobs' pass
+// rates over the last week, the release's open regressions and the quarantined tests.
+func GatePayload(dbc *db.DB, request apitype.PayloadGateRequest, reportEnd time.Time) (*apitype.PayloadGate, error) {
+	jobs := make([]apitype.PayloadGateJob, 0, len(request.BlockingJobs))
+	ids := make([]uint, 0, len(request.BlockingJobs))
+	for name, jobRun := range request.BlockingJobs {
+		job := apitype.PayloadGateJob{Name: name, URL: jobRun.URL, State: jobRun.State}
+		if id, err := prowJobRunIDFromURL(jobRun.URL); err == nil {
+			job.ProwJobRunID = id
+			ids = append(ids, id)
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+
+	runs := map[uint]payloadGateRun{}
+	health := map[uint]payloadGateJobHealth{}
+	if len(ids) > 0 {
+		loaded := make([]payloadGateRun, 0, len(ids))
+		res := dbc.DB.Raw(`SELECT prow_job_runs.id, prow_job_runs.prow_job_id, prow_jobs.name AS prow_job_name,
+			prow_job_runs.infrastructure_failure, prow_job_runs.failed_test_names
+			FROM prow_job_runs
+			JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_job_runs.id IN ?`, ids).Scan(&loaded)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		prowJobIDs := make([]uint, 0, len(loaded))
+		for _, run := range loaded {
+			runs[run.ID] = run
+			prowJobIDs = append(prowJobIDs, run.ProwJobID)
+		}
+
+		if len(prowJobIDs) > 0 {
+			counts := make([]payloadGateJobHealth, 0, len(prowJobIDs))
+			res = dbc.DB.Raw(`SELECT prow_job_id, COUNT(*) AS runs, COUNT(*) FILTER (WHERE succeeded) AS successes
+				FROM prow_job_runs
+				WHERE prow_job_id IN ? AND timestamp >= ? AND timestamp < ? AND id NOT IN ?
+				GROUP BY prow_job_id`, prowJobIDs, reportEnd.Add(-payloadGateHealthWindow), reportEnd, ids).
+				Scan(&counts)
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			for _, count := range counts {
+				health[count.ProwJobID] = count
+			}
+		}
+	}
+
+	regressed := sets.NewString()
+	openRegressions := make([]models.TestRegression, 0)
+	res := dbc.DB.Where("release = ? AND status = ?", request.Release, models.TestRegressionOpen).Find(&openRegressions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	for _, regression := range openRegressions {
+		regressed.Insert(regression.TestName)
+	}
+	quarantined, err := quarantinedTestsFromDB(dbc, reportEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	gate := &apitype.PayloadGate{
+		ReleaseTag:   request.ReleaseTag,
+		Release:      request.Release,
+		Stream:       request.Stream,
+		Architecture: request.Architecture,
+	}
+	evaluatePayloadGate(gate, jobs, runs, health, regressed, quarantined)
+	return gate, nil
+}
+
+// evaluatePayloadGate decides each blocking job's verdict, then the payload's. A failed job only rejects the payload
+// when its run failed tests that aren't known regressions or quarantined, in a job that's otherwise healthy; a run that
+// failed on infrastructure should be retried.
+func evaluatePayloadGate(gate *apitype.PayloadGate, jobs []apitype.PayloadGateJob, runs map[uint]payloadGateRun,
+	health map[uint]payloadGateJobHealth, regressed, quarantined sets.String) {
+	gate.Reasons = make([]string, 0)
+	for i := range jobs {
+		job := &jobs[i]
+		job.Verdict = apitype.PayloadGateAccept
+		run, loaded := runs[job.ProwJobRunID]
+		if loaded {
+			job.ProwJobName = run.ProwJobName
+			if h, ok := health[run.ProwJobID]; ok && h.Runs > 0 {
+				job.Runs = h.Runs
+				job.PassPercentage = float64(h.Successes) * 100 / float64(h.Runs)
+			}
+			job.FailedTests = run.FailedTestNames
+			for _, test := range run.FailedTestNames {
+				switch {
+				case regressed.Has(test):
+					job.KnownRegressions = append(job.KnownRegressions, test)
+				case quarantined.Has(test):
+					job.QuarantinedTests = append(job.QuarantinedTests, test)
+				}
+			}
+		}
+		excused := len(job.KnownRegressions) + len(job.QuarantinedTests)
+
+		switch {
+		case job.State == "Succeeded":
+			continue
+		case job.State != "Failed":
+			job.Verdict = apitype.PayloadGatePending
+			job.Reason = fmt.Sprintf("%s is %s", job.Name, strings.ToLower(job.State))
+		case !loaded:
+			job.Verdict = apitype.PayloadGateReject
+			job.Reason = fmt.Sprintf("%s failed, and its run isn't loaded into sippy to tell why", job.Name)
+		case run.InfrastructureFailure:
+			job.Verdict = apitype.PayloadGatePending
+			job.Reason = fmt.Sprintf("%s failed on infrastructure, retry it", job.Name)
+		case len(job.FailedTests) > 0 && len(job.KnownRegressions) == len(job.FailedTests):
+			job.Reason = fmt.Sprintf("%s only failed known regressions: %s", job.Name,
+				listPayloadGateTests(job.KnownRegressions))
+		case len(job.FailedTests) > 0 && excused == len(job.FailedTests):
+			job.Reason = fmt.Sprintf("%s only failed known regressions and quarantined tests: %s", job.Name,
+				listPayloadGateTests(append(append([]string{}, job.KnownRegressions...), job.QuarantinedTests...)))
+		case job.Runs >= payloadGateMinRuns && job.PassPercentage < payloadGateUnhealthyPassPercentage:
+			job.Reason = fmt.Sprintf("%s failed, but passed only %.0f%% of %d runs in the last week, so its failure "+
+				"isn't specific to this payload", job.Name, job.PassPercentage, job.Runs)
+		case len(job.FailedTests) == 0:
+			job.Verdict = apitype.PayloadGateReject
+			job.Reason = fmt.Sprintf("%s failed without failing any tests", job.Name)
+		default:
+			unknown := make([]string, 0, len(job.FailedTests))
+			known := sets.NewString(job.KnownRegressions...).Insert(job.QuarantinedTests...)
+			for _, test := range job.FailedTests {
+				if !known.Has(test) {
+					unknown = append(unknown, test)
+				}
+			}
+			job.Verdict = apitype.PayloadGateReject
+			job.Reason = fmt.Sprintf("%s failed tests that aren't known regressions or quarantined: %s", job.Name,
+				listPayloadGateTests(unknown))
+		}
+		gate.Reasons = append(gate.Reasons, job.Reason)
+	}
+	gate.Jobs = jobs
+
+	gate.Verdict = apitype.PayloadGateAccept
+	for _, job := range jobs {
+		if job.Verdict == apitype.PayloadGateReject {
+			gate.Verdict = apitype.PayloadGateReject
+			break
+		}
+		if job.Verdict == apitype.PayloadGatePending {
+			gate.Verdict = apitype.PayloadGatePending
+		}
+	}
+	if len(jobs) == 0 {
+		gate.Reasons = append(gate.Reasons, "payload has no blocking jobs")
+	}
+}
+
+func listPayloadGateTests(tests []string) string {
+	if len(tests) <= maxPayloadGateReasonTests {
+		return strings.Join(tests, ", ")
+	}
+	return fmt.Sprintf("%s (and %d more)", strings.Join(tests[:maxPayloadGateReasonTests], ", "),
+		len(tests)-maxPayloadGateReasonTests)
+}
+
+// prowJobRunIDFromURL returns the ID of a prow job run from its URL, the last element of its path.
+func prowJobRunIDFromURL(prowURL string) (uint, error) {
+	parsed, err := url.Parse(prowURL)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseUint(path.Base(parsed.Path), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+func TestEvaluatePayloadGate(t *testing.T) {
+	runs := map[uint]payloadGateRun{
+		1: {ID: 1, ProwJobID: 10, ProwJobName: "e2e-aws"},
+		2: {ID: 2, ProwJobID: 20, FailedTestNames: []string{"regressed", "new failure"}},
+		3: {ID: 3, ProwJobID: 30, FailedTestNames: []string{"regressed"}},
+		4: {ID: 4, ProwJobID: 40, FailedTestNames: []string{"new failure"}},
+		5: {ID: 5, ProwJobID: 50, InfrastructureFailure: true},
+		7: {ID: 7, ProwJobID: 70, FailedTestNames: []string{"regressed", "quarantined"}},
+		8: {ID: 8, ProwJobID: 80, FailedTestNames: []string{"quarantined", "new failure"}},
+	}
+	health := map[uint]payloadGateJobHealth{
+		10: {ProwJobID: 10, Runs: 10, Successes: 9},
+		20: {ProwJobID: 20, Runs: 10, Successes: 9},
+		40: {ProwJobID: 40, Runs: 10, Successes: 3},
+	}
+	regressed := sets.NewString("regressed")
+	quarantined := sets.NewString("quarantined")
+	job := func(name string, id uint, state string) apitype.PayloadGateJob {
+		return apitype.PayloadGateJob{Name: name, ProwJobRunID: id, State: state}
+	}
+
+	tests := []struct {
+		name    string
+		jobs    []apitype.PayloadGateJob
+		verdict string
+		reasons []string
+	}{
+		{
+			name:    "all passed",
+			jobs:    []apitype.PayloadGateJob{job("aws", 1, "Succeeded")},
+			verdict: apitype.PayloadGateAccept,
+			reasons: []string{},
+		},
+		{
+			name:    "new failure rejects",
+			jobs:    []apitype.PayloadGateJob{job("aws", 1, "Succeeded"), job("gcp", 2, "Failed")},
+			verdict: apitype.PayloadGateReject,
+			reasons: []string{"gcp failed tests that aren't known regressions or quarantined: new failure"},
+		},
+		{
+			name:    "known regressions accept",
+			jobs:    []apitype.PayloadGateJob{job("azure", 3, "Failed")},
+			verdict: apitype.PayloadGateAccept,
+			reasons: []string{"azure only failed known regressions: regressed"},
+		},
+		{
+			name:    "quarantined tests accept",
+			jobs:    []apitype.PayloadGateJob{job("ovn", 7, "Failed")},
+			verdict: apitype.PayloadGateAccept,
+			reasons: []string{"ovn only failed known regressions and quarantined tests: regressed, quarantined"},
+		},
+		{
+			name:    "quarantined tests don't excuse new failures",
+			jobs:    []apitype.PayloadGateJob{job("sdn", 8, "Failed")},
+			verdict: apitype.PayloadGateReject,
+			reasons: []string{"sdn failed tests that aren't known regressions or quarantined: new failure"},
+		},
+		{
+			name:    "unhealthy job accepts",
+			jobs:    []apitype.PayloadGateJob{job("metal", 4, "Failed")},
+			verdict: apitype.PayloadGateAccept,
+			reasons: []string{"metal failed, but passed only 30% of 10 runs in the last week, so its failure isn't specific to this payload"},
+		},
+		{
+			name:    "infrastructure failure is retried",
+			jobs:    []apitype.PayloadGateJob{job("vsphere", 5, "Failed"), job("aws", 1, "Pending")},
+			verdict: apitype.PayloadGatePending,
+			reasons: []string{"vsphere failed on infrastructure, retry it", "aws is pending"},
+		},
+		{
+			name:    "rejection beats pending",
+			jobs:    []apitype.PayloadGateJob{job("aws", 1, "Pending"), job("unloaded", 6, "Failed")},
+			verdict: apitype.PayloadGateReject,
+			reasons: []string{"aws is pending", "unloaded failed, and its run isn't loaded into sippy to tell why"},
+		},
+		{
+			name:    "no blocking jobs",
+			jobs:    []apitype.PayloadGateJob{},
+			verdict: apitype.PayloadGateAccept,
+			reasons: []string{"payload has no blocking jobs"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gate := &apitype.PayloadGate{}
+			evaluatePayloadGate(gate, tc.jobs, runs, health, regressed, quarantined)
+			assert.Equal(t, tc.verdict, gate.Verdict)
+			assert.Equal(t, tc.reasons, gate.Reasons)
+		})
+	}
+}
+
+func TestListPayloadGateTests(t *testing.T) {
+	assert.Equal(t, "a, b", listPayloadGateTests([]string{"a", "b"}))
+	assert.Equal(t, "a, b, c, d, e (and 2 more)", listPayloadGateTests([]string{"a", "b", "c", "d", "e", "f", "g"}))
+}
+
+func TestProwJobRunIDFromURL(t *testing.T) {
+	id, err := prowJobRunIDFromURL("https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-job/1234567890")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1234567890), id)
+
+	_, err = prowJobRunIDFromURL("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// payloadStreamMinPayloads is the fewest finished payloads a stream's health is judged from.
+	payloadStreamMinPayloads = 3
+	// payloadStreamUnhealthyAcceptance is the acceptance percentage below which a stream is failing.
+	payloadStreamUnhealthyAcceptance = 50
+)
+
+// payloadStreams are the streams compared, both built from the same release's content.
+var payloadStreams = []string{"nightly", "ci"}
+
+// payloadStreamJobFailures counts a blocking job's runs and failures in a stream's payloads.
+type payloadStreamJobFailures struct {
+	Stream   string
+	JobName  string
+	Runs     int
+	Failures int
+}
+
+// ComparePayloadStreams compares the release's nightly and ci payloads on the architecture released between start and
+// end.
+func ComparePayloadStreams(dbc *db.DB, release, arch string, start, end time.Time) (*apitype.PayloadStreamComparison,
+	error) {
+	streams := make([]apitype.PayloadStreamHealth, 0, len(payloadStreams))
+	for _, stream := range payloadStreams {
+		counts, err := query.GetPayloadStreamPhaseCounts(dbc.DB, release, arch, stream, &start, end)
+		if err != nil {
+			return nil, err
+		}
+		health := apitype.PayloadStreamHealth{Stream: stream}
+		for _, count := range counts {
+			health.Payloads += count.Count
+			switch count.Phase {
+			case "Accepted":
+				health.Accepted += count.Count
+			case "Rejected":
+				health.Rejected += count.Count
+			}
+		}
+		streams = append(streams, health)
+	}
+
+	failures := make([]payloadStreamJobFailures, 0)
+	res := dbc.DB.Raw(`SELECT release_tags.stream, release_job_runs.job_name,
+			COUNT(*) AS runs, COUNT(*) FILTER (WHERE release_job_runs.state = 'Failed') AS failures
+		FROM release_job_runs
+		JOIN release_tags ON release_tags.id = release_job_runs.release_tag_id
+		WHERE release_tags.release = ? AND release_tags.architecture = ? AND release_tags.stream IN ?
+			AND release_tags.release_time >= ? AND release_tags.release_time < ?
+			AND release_job_runs.kind = 'Blocking' AND release_job_runs.deleted_at IS NULL
+		GROUP BY release_tags.stream, release_job_runs.job_name`,
+		release, arch, payloadStreams, start, end).Scan(&failures)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	comparison := &apitype.PayloadStreamComparison{
+		Release:      release,
+		Architecture: arch,
+		Start:        start,
+		End:          end,
+	}
+	comparePayloadStreams(comparison, streams, failures)
+	return comparison, nil
+}
+
+// comparePayloadStreams fills in each stream's acceptance and failing blocking jobs, the jobs failing in both, and the
+// verdict. When both streams are failing the problem is most likely in the content they share; when only one is, it's
+// in that stream's jobs or infrastructure.
+func comparePayloadStreams(comparison *apitype.PayloadStreamComparison, streams []apitype.PayloadStreamHealth,
+	failures []payloadStreamJobFailures) {
+	byStream := map[string]map[string]int{}
+	for i := range streams {
+		stream := &streams[i]
+		if finished := stream.Accepted + stream.Rejected; finished > 0 {
+			stream.AcceptancePercentage = float64(stream.Accepted) * 100 / float64(finished)
+		}
+		stream.BlockingFailures = make([]apitype.PayloadStreamJobFailures, 0)
+		byStream[stream.Stream] = map[string]int{}
+		for _, f := range failures {
+			if f.Stream != stream.Stream || f.Failures == 0 {
+				continue
+			}
+			stream.BlockingFailures = append(stream.BlockingFailures, apitype.PayloadStreamJobFailures{
+				JobName:  f.JobName,
+				Runs:     f.Runs,
+				Failures: f.Failures,
+			})
+			byStream[stream.Stream][f.JobName] = f.Failures
+		}
+		sort.Slice(stream.BlockingFailures, func(a, b int) bool {
+			if stream.BlockingFailures[a].Failures != stream.BlockingFailures[b].Failures {
+				return stream.BlockingFailures[a].Failures > stream.BlockingFailures[b].Failures
+			}
+			return stream.BlockingFailures[a].JobName < stream.BlockingFailures[b].JobName
+		})
+	}
+	comparison.Streams = streams
+
+	comparison.SharedFailures = make([]apitype.PayloadStreamSharedFailure, 0)
+	for job, nightly := range byStream["nightly"] {
+		if ci, ok := byStream["ci"][job]; ok {
+			comparison.SharedFailures = append(comparison.SharedFailures, apitype.PayloadStreamSharedFailure{
+				JobName:         job,
+				NightlyFailures: nightly,
+				CIFailures:      ci,
+			})
+		}
+	}
+	sort.Slice(comparison.SharedFailures, func(i, j int) bool {
+		fi, fj := comparison.SharedFailures[i], comparison.SharedFailures[j]
+		if fi.NightlyFailures+fi.CIFailures != fj.NightlyFailures+fj.CIFailures {
+			return fi.NightlyFailures+fi.CIFailures > fj.NightlyFailures+fj.CIFailures
+		}
+		return fi.JobName < fj.JobName
+	})
+
+	failing := make([]string, 0)
+	for _, stream := range streams {
+		if stream.Accepted+stream.Rejected < payloadStreamMinPayloads {
+			comparison.Verdict = apitype.PayloadStreamsInsufficientData
+			comparison.Reason = fmt.Sprintf("the %s stream finished %d payloads, too few to compare", stream.Stream,
+				stream.Accepted+stream.Rejected)
+			return
+		}
+		if stream.AcceptancePercentage < payloadStreamUnhealthyAcceptance {
+			failing = append(failing, stream.Stream)
+		}
+	}
+	switch len(failing) {
+	case 0:
+		comparison.Verdict = apitype.PayloadStreamsHealthy
+		comparison.Reason = "both streams are accepting payloads"
+	case len(streams):
+		comparison.Verdict = apitype.PayloadStreamsContentProblem
+		comparison.Reason = fmt.Sprintf("both streams are rejecting most payloads, so the problem is likely in the "+
+			"payload content; %d blocking jobs are failing in both", len(comparison.SharedFailures))
+	default:
+		comparison.Verdict = apitype.PayloadStreamsStreamProblem
+		comparison.Reason = fmt.Sprintf("only the %s stream is rejecting most payloads, so the problem is likely "+
+			"specific to its jobs or infrastructure", failing[0])
+	}
+}
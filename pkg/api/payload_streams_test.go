@@ -0,0 +1,77 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestComparePayloadStreams(t *testing.T) {
+	failures := []payloadStreamJobFailures{
+		{Stream: "nightly", JobName: "aws-ovn-serial", Runs: 10, Failures: 6},
+		{Stream: "nightly", JobName: "aws-ovn-upgrade", Runs: 10, Failures: 2},
+		{Stream: "nightly", JobName: "gcp-ovn", Runs: 10},
+		{Stream: "ci", JobName: "aws-ovn-serial", Runs: 10, Failures: 5},
+		{Stream: "ci", JobName: "gcp-ovn", Runs: 10},
+	}
+	stream := func(name string, accepted, rejected int) apitype.PayloadStreamHealth {
+		return apitype.PayloadStreamHealth{Stream: name, Payloads: accepted + rejected, Accepted: accepted,
+			Rejected: rejected}
+	}
+
+	tests := []struct {
+		name    string
+		streams []apitype.PayloadStreamHealth
+		verdict string
+		reason  string
+	}{
+		{
+			name:    "both healthy",
+			streams: []apitype.PayloadStreamHealth{stream("nightly", 8, 2), stream("ci", 9, 1)},
+			verdict: apitype.PayloadStreamsHealthy,
+		},
+		{
+			name:    "both failing",
+			streams: []apitype.PayloadStreamHealth{stream("nightly", 2, 8), stream("ci", 1, 9)},
+			verdict: apitype.PayloadStreamsContentProblem,
+			reason:  "1 blocking jobs are failing in both",
+		},
+		{
+			name:    "only ci failing",
+			streams: []apitype.PayloadStreamHealth{stream("nightly", 8, 2), stream("ci", 1, 9)},
+			verdict: apitype.PayloadStreamsStreamProblem,
+			reason:  "only the ci stream",
+		},
+		{
+			name:    "too few payloads",
+			streams: []apitype.PayloadStreamHealth{stream("nightly", 8, 2), stream("ci", 1, 1)},
+			verdict: apitype.PayloadStreamsInsufficientData,
+			reason:  "the ci stream finished 2 payloads",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := &apitype.PayloadStreamComparison{}
+			comparePayloadStreams(comparison, tt.streams, failures)
+			assert.Equal(t, tt.verdict, comparison.Verdict)
+			assert.Contains(t, comparison.Reason, tt.reason)
+		})
+	}
+
+	comparison := &apitype.PayloadStreamComparison{}
+	comparePayloadStreams(comparison, []apitype.PayloadStreamHealth{stream("nightly", 3, 1), stream("ci", 0, 0)},
+		failures)
+	require.Len(t, comparison.Streams, 2)
+	assert.Equal(t, 75.0, comparison.Streams[0].AcceptancePercentage)
+	assert.Zero(t, comparison.Streams[1].AcceptancePercentage)
+	assert.Equal(t, []apitype.PayloadStreamJobFailures{
+		{JobName: "aws-ovn-serial", Runs: 10, Failures: 6},
+		{JobName: "aws-ovn-upgrade", Runs: 10, Failures: 2},
+	}, comparison.Streams[0].BlockingFailures)
+	assert.Equal(t, []apitype.PayloadStreamSharedFailure{
+		{JobName: "aws-ovn-serial", NightlyFailures: 6, CIFailures: 5},
+	}, comparison.SharedFailures)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// RequestIDHeader is the header used to correlate a request with its log lines and any error response.
+	RequestIDHeader = "X-Request-ID"
+
+	problemContentType = "application/problem+json"
+	problemTypeBase    = "/api/problems/"
+)
+
+// ProblemType identifies a category of error. Clients should program against the type rather than the
+// human-readable title or detail, which may change over time.
+type ProblemType string
+
+const (
+	ProblemTypeBadRequest         ProblemType = problemTypeBase + "bad-request"
+	ProblemTypeUnauthorized       ProblemType = problemTypeBase + "unauthorized"
+	ProblemTypeForbidden          ProblemType = problemTypeBase + "forbidden"
+	ProblemTypeNotFound           ProblemType = problemTypeBase + "not-found"
+	ProblemTypeMethodNotAllowed   ProblemType = problemTypeBase + "method-not-allowed"
+	ProblemTypeConflict           ProblemType = problemTypeBase + "conflict"
+	ProblemTypeTooManyRequests    ProblemType = problemTypeBase + "too-many-requests"
+	ProblemTypeInternal           ProblemType = problemTypeBase + "internal-error"
+	ProblemTypeNotImplemented     ProblemType = problemTypeBase + "not-implemented"
+	ProblemTypeServiceUnavailable ProblemType = problemTypeBase + "service-unavailable"
+	ProblemTypeTimeout            ProblemType = problemTypeBase + "timeout"
+)
+
+var problemTitles = map[ProblemType]string{
+	ProblemTypeBadRequest:         "Bad request",
+	ProblemTypeUnauthorized:       "Unauthorized",
+	ProblemTypeForbidden:          "Forbidden",
+	ProblemTypeNotFound:           "Not found",
+	ProblemTypeMethodNotAllowed:   "Method not allowed",
+	ProblemTypeConflict:           "Conflict",
+	ProblemTypeTooManyRequests:    "Too many requests",
+	ProblemTypeInternal:           "Internal server error",
+	ProblemTypeNotImplemented:     "Not implemented",
+	ProblemTypeServiceUnavailable: "Service unavailable",
+	ProblemTypeTimeout:            "Timeout",
+}
+
+// Problem is an RFC7807 problem details object, returned by every API endpoint on error.
+type Problem struct {
+	Type      ProblemType `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	RequestID string      `json:"requestID,omitempty"`
+}
+
+func (p Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return fmt.Sprintf("%s: %s", p.Title, p.Detail)
+}
+
+// ProblemTypeForStatus maps an HTTP status code to the problem type used when a handler does not
+// specify a more precise one.
+func ProblemTypeForStatus(statusCode int) ProblemType {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ProblemTypeBadRequest
+	case http.StatusUnauthorized:
+		return ProblemTypeUnauthorized
+	case http.StatusForbidden:
+		return ProblemTypeForbidden
+	case http.StatusNotFound:
+		return ProblemTypeNotFound
+	case http.StatusMethodNotAllowed:
+		return ProblemTypeMethodNotAllowed
+	case http.StatusConflict:
+		return ProblemTypeConflict
+	case http.StatusTooManyRequests:
+		return ProblemTypeTooManyRequests
+	case http.StatusNotImplemented:
+		return ProblemTypeNotImplemented
+	case http.StatusServiceUnavailable:
+		return ProblemTypeServiceUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return ProblemTypeTimeout
+	}
+
+	if statusCode >= 400 && statusCode < 500 {
+		return ProblemTypeBadRequest
+	}
+	return ProblemTypeInternal
+}
+
+// NewProblem builds a problem with the default type and title for the given status code.
+func NewProblem(statusCode int, detail string) Problem {
+	problemType := ProblemTypeForStatus(statusCode)
+	return Problem{
+		Type:   problemType,
+		Title:  problemTitles[problemType],
+		Status: statusCode,
+		Detail: detail,
+	}
+}
+
+// RespondWithError writes an RFC7807 problem response with the default problem type for the status code.
+func RespondWithError(statusCode int, w http.ResponseWriter, detail string) {
+	RespondWithProblem(w, NewProblem(statusCode, detail))
+}
+
+// RespondWithProblem writes the given problem as an application/problem+json response. The request ID
+// is read from the response headers, where it is placed by the request ID middleware before the
+// handler is invoked.
+func RespondWithProblem(w http.ResponseWriter, problem Problem) {
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Type == "" {
+		problem.Type = ProblemTypeForStatus(problem.Status)
+	}
+	if problem.Title == "" {
+		problem.Title = problemTitles[problem.Type]
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = w.Header().Get(RequestIDHeader)
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.WithError(err).Warningf("could not marshal problem response")
+	}
+}
+
+// RespondWithProblemOrError responds with the Problem err wraps, i.e. a request the API refused to apply, otherwise it
+// logs err and responds with an internal error saying what failed, i.e. "saving test quarantine".
+func RespondWithProblemOrError(w http.ResponseWriter, err error, what string) {
+	var problem Problem
+	if errors.As(err, &problem) {
+		RespondWithProblem(w, problem)
+		return
+	}
+	log.WithError(err).Error("error " + what)
+	RespondWithError(http.StatusInternalServerError, w, "error "+what)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespondWithProblem(t *testing.T) {
+	tests := []struct {
+		name            string
+		requestID       string
+		problem         Problem
+		expectedStatus  int
+		expectedProblem Problem
+	}{
+		{
+			name:           "defaults derived from status",
+			requestID:      "abc-123",
+			problem:        NewProblem(http.StatusBadRequest, "release is required"),
+			expectedStatus: http.StatusBadRequest,
+			expectedProblem: Problem{
+				Type:      ProblemTypeBadRequest,
+				Title:     "Bad request",
+				Status:    http.StatusBadRequest,
+				Detail:    "release is required",
+				RequestID: "abc-123",
+			},
+		},
+		{
+			name:           "explicit type keeps its title",
+			problem:        Problem{Type: ProblemTypeServiceUnavailable, Status: http.StatusServiceUnavailable},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedProblem: Problem{
+				Type:   ProblemTypeServiceUnavailable,
+				Title:  "Service unavailable",
+				Status: http.StatusServiceUnavailable,
+			},
+		},
+		{
+			name:           "missing status is an internal error",
+			problem:        Problem{Detail: "boom"},
+			expectedStatus: http.StatusInternalServerError,
+			expectedProblem: Problem{
+				Type:   ProblemTypeInternal,
+				Title:  "Internal server error",
+				Status: http.StatusInternalServerError,
+				Detail: "boom",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if tc.requestID != "" {
+				w.Header().Set(RequestIDHeader, tc.requestID)
+			}
+
+			RespondWithProblem(w, tc.problem)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			var got Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tc.expectedProblem, got)
+		})
+	}
+}
+
+func TestProblemTypeForStatus(t *testing.T) {
+	assert.Equal(t, ProblemTypeNotFound, ProblemTypeForStatus(http.StatusNotFound))
+	assert.Equal(t, ProblemTypeBadRequest, ProblemTypeForStatus(http.StatusUnprocessableEntity))
+	assert.Equal(t, ProblemTypeInternal, ProblemTypeForStatus(http.StatusBadGateway))
+}
+
+func TestRespondWithProblemOrError(t *testing.T) {
+	w := httptest.NewRecorder()
+	RespondWithProblemOrError(w, fmt.Errorf("saving: %w", NewProblem(http.StatusConflict, "already exists")), "saving")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var got Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "already exists", got.Detail)
+
+	w = httptest.NewRecorder()
+	RespondWithProblemOrError(w, errors.New("connection reset"), "saving")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "error saving", got.Detail, "database errors aren't shown to the client")
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// pullRequestImpactPayloads is how many payloads either side of a pull request's are compared.
+	pullRequestImpactPayloads = 5
+	// pullRequestImpactWindow is how long job runs and test regressions are compared either side of a payload.
+	pullRequestImpactWindow = 48 * time.Hour
+
+	pullRequestImpactConfidence = 95
+	maxPullRequestImpacts       = 100
+)
+
+// GetPullRequestImpactFromDB lists the pull requests first included in the stream's payloads between start and end
+// that were followed by more rejected payloads, regressed jobs or new test regressions, those most correlated with a
+// regression first.
+func GetPullRequestImpactFromDB(dbc *db.DB, release, stream, arch string, start, end time.Time) ([]apitype.PullRequestImpact, error) {
+	// payloads before the window are loaded to compare the first ones in it to
+	payloads, err := query.GetPayloadTags(dbc.DB, release, stream, arch, start.AddDate(0, 0, -7), end)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0)
+	for _, p := range payloads {
+		if !p.ReleaseTime.Before(start) {
+			ids = append(ids, p.ID)
+		}
+	}
+	prs, err := query.GetPullRequestsForPayloads(dbc.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := query.PeriodicJobRunResults(dbc, release, start.Add(-pullRequestImpactWindow), end)
+	if err != nil {
+		return nil, err
+	}
+
+	regressions := make([]models.TestRegression, 0)
+	res := dbc.DB.Where("release = ? AND first_seen BETWEEN ? AND ?", release, start, end.Add(pullRequestImpactWindow)).
+		Find(&regressions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return pullRequestImpacts(payloads, prs, runs, regressions), nil
+}
+
+type payloadImpact struct {
+	payloadsBefore, rejectedBefore int
+	payloadsAfter, rejectedAfter   int
+	regressedJobs                  []string
+	newTestRegressions             int
+}
+
+func (p payloadImpact) rejectionIncrease() float64 {
+	var before, after float64
+	if p.payloadsBefore > 0 {
+		before = float64(p.rejectedBefore) / float64(p.payloadsBefore)
+	}
+	if p.payloadsAfter > 0 {
+		after = float64(p.rejectedAfter) / float64(p.payloadsAfter)
+	}
+	return after - before
+}
+
+func (p payloadImpact) correlated() bool {
+	return len(p.regressedJobs) > 0 || p.newTestRegressions > 0 || p.rejectionIncrease() > 0
+}
+
+// pullRequestImpacts compares each payload's neighbours, and the job runs and test regressions around it, for the
+// payloads pull requests were first included in. Payloads must be oldest first.
+func pullRequestImpacts(payloads []models.ReleaseTag, prs []models.PayloadPullRequest, runs []query.JobRunResult,
+	regressions []models.TestRegression) []apitype.PullRequestImpact {
+	index := map[string]int{}
+	for i, p := range payloads {
+		index[p.ReleaseTag] = i
+	}
+
+	impacts := map[string]payloadImpact{}
+	results := make([]apitype.PullRequestImpact, 0)
+	for _, pr := range prs {
+		i, ok := index[pr.ReleaseTag]
+		if !ok {
+			continue
+		}
+		payload := payloads[i]
+		impact, ok := impacts[pr.ReleaseTag]
+		if !ok {
+			impact = newPayloadImpact(payloads, i, runs, regressions)
+			impacts[pr.ReleaseTag] = impact
+		}
+		if !impact.correlated() {
+			continue
+		}
+
+		results = append(results, apitype.PullRequestImpact{
+			URL:                pr.URL,
+			PullRequestID:      pr.PullRequestID,
+			Name:               pr.Name,
+			Description:        pr.Description,
+			BugURL:             pr.BugURL,
+			Payload:            payload.ReleaseTag,
+			PayloadPhase:       payload.Phase,
+			PayloadTime:        payload.ReleaseTime,
+			PayloadsBefore:     impact.payloadsBefore,
+			RejectedBefore:     impact.rejectedBefore,
+			PayloadsAfter:      impact.payloadsAfter,
+			RejectedAfter:      impact.rejectedAfter,
+			RegressedJobs:      impact.regressedJobs,
+			NewTestRegressions: impact.newTestRegressions,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if len(a.RegressedJobs) != len(b.RegressedJobs) {
+			return len(a.RegressedJobs) > len(b.RegressedJobs)
+		}
+		if a.NewTestRegressions != b.NewTestRegressions {
+			return a.NewTestRegressions > b.NewTestRegressions
+		}
+		ai := impacts[a.Payload].rejectionIncrease()
+		bi := impacts[b.Payload].rejectionIncrease()
+		if ai != bi {
+			return ai > bi
+		}
+		return a.PayloadTime.After(b.PayloadTime)
+	})
+	if len(results) > maxPullRequestImpacts {
+		results = results[:maxPullRequestImpacts]
+	}
+	return results
+}
+
+func newPayloadImpact(payloads []models.ReleaseTag, i int, runs []query.JobRunResult, regressions []models.TestRegression) payloadImpact {
+	impact := payloadImpact{regressedJobs: make([]string, 0)}
+	for j := i - pullRequestImpactPayloads; j < i+pullRequestImpactPayloads; j++ {
+		if j < 0 || j >= len(payloads) {
+			continue
+		}
+		rejected := payloads[j].Phase == apitype.PayloadRejected
+		if j < i {
+			impact.payloadsBefore++
+			if rejected {
+				impact.rejectedBefore++
+			}
+		} else {
+			impact.payloadsAfter++
+			if rejected {
+				impact.rejectedAfter++
+			}
+		}
+	}
+
+	at := payloads[i].ReleaseTime
+	type counts struct {
+		beforePasses, beforeFailures, afterPasses, afterFailures int
+	}
+	jobs := map[string]*counts{}
+	for _, run := range runs {
+		if run.Timestamp.Before(at.Add(-pullRequestImpactWindow)) || run.Timestamp.After(at.Add(pullRequestImpactWindow)) {
+			continue
+		}
+		c, ok := jobs[run.JobName]
+		if !ok {
+			c = &counts{}
+			jobs[run.JobName] = c
+		}
+		switch {
+		case run.Timestamp.Before(at) && run.Succeeded:
+			c.beforePasses++
+		case run.Timestamp.Before(at):
+			c.beforeFailures++
+		case run.Succeeded:
+			c.afterPasses++
+		default:
+			c.afterFailures++
+		}
+	}
+	for name, c := range jobs {
+		if _, worse := SignificantlyWorse(c.beforeFailures, c.beforePasses, c.afterFailures, c.afterPasses,
+			pullRequestImpactConfidence); worse {
+			impact.regressedJobs = append(impact.regressedJobs, name)
+		}
+	}
+	sort.Strings(impact.regressedJobs)
+
+	for _, r := range regressions {
+		if r.FirstSeen.After(at) && !r.FirstSeen.After(at.Add(pullRequestImpactWindow)) {
+			impact.newTestRegressions++
+		}
+	}
+	return impact
+}
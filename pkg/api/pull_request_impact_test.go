@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestPullRequestImpacts(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	payloads := make([]models.ReleaseTag, 0)
+	for i, phase := range []string{"Accepted", "Accepted", "Accepted", "Rejected", "Rejected", "Accepted"} {
+		payloads = append(payloads, models.ReleaseTag{
+			Model:       models.Model{ID: uint(i + 1)},
+			ReleaseTag:  []string{"p0", "p1", "p2", "p3", "p4", "p5"}[i],
+			Phase:       phase,
+			ReleaseTime: start.Add(time.Duration(i) * 12 * time.Hour),
+		})
+	}
+	prs := []models.PayloadPullRequest{
+		{ReleaseTag: "p1", URL: "https://github.com/openshift/a/pull/1"},
+		{ReleaseTag: "p3", URL: "https://github.com/openshift/b/pull/2"},
+		{ReleaseTag: "p3", URL: "https://github.com/openshift/c/pull/3"},
+	}
+
+	// the job always passed before p3, and mostly failed after
+	p3 := payloads[3].ReleaseTime
+	runs := make([]query.JobRunResult, 0)
+	for h := -47; h <= 47; h += 2 {
+		at := p3.Add(time.Duration(h) * time.Hour)
+		runs = append(runs,
+			query.JobRunResult{JobName: "e2e-aws", Timestamp: at, Succeeded: h < 0 || h%4 == 1},
+			query.JobRunResult{JobName: "e2e-gcp", Timestamp: at, Succeeded: true})
+	}
+	regressions := []models.TestRegression{{FirstSeen: p3.Add(6 * time.Hour)}}
+
+	impacts := pullRequestImpacts(payloads, prs, runs, regressions)
+	// p1 was followed by the rejected payloads and the test regression too, but no job regressed after it
+	require.Len(t, impacts, 3)
+	assert.Equal(t, "p1", impacts[2].Payload)
+	assert.Empty(t, impacts[2].RegressedJobs)
+	for _, impact := range impacts[:2] {
+		assert.Equal(t, "p3", impact.Payload)
+		assert.Equal(t, []string{"e2e-aws"}, impact.RegressedJobs)
+		assert.Equal(t, 1, impact.NewTestRegressions)
+		assert.Equal(t, 3, impact.PayloadsBefore)
+		assert.Equal(t, 0, impact.RejectedBefore)
+		assert.Equal(t, 3, impact.PayloadsAfter)
+		assert.Equal(t, 2, impact.RejectedAfter)
+	}
+}
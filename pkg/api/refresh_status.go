@@ -0,0 +1,37 @@
+package api
+
+import (
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// GetRefreshStatusFromDB returns the refresh in progress and the last one to finish. A refresh still running when a
+// later one finished was interrupted, i.e. its process exited, so it isn't the one in progress.
+func GetRefreshStatusFromDB(dbc *db.DB) (apitype.RefreshStatus, error) {
+	status := apitype.RefreshStatus{}
+
+	last := &models.RefreshRun{}
+	res := dbc.DB.Where("status <> ?", models.RefreshRunning).Order("started_at DESC").Limit(1).Find(last)
+	if res.Error != nil {
+		return status, res.Error
+	}
+	if res.RowsAffected > 0 {
+		status.Last = last
+	}
+
+	current := &models.RefreshRun{}
+	q := dbc.DB.Where("status = ?", models.RefreshRunning)
+	if status.Last != nil {
+		q = q.Where("started_at > ?", status.Last.StartedAt)
+	}
+	res = q.Order("started_at DESC").Limit(1).Find(current)
+	if res.Error != nil {
+		return status, res.Error
+	}
+	if res.RowsAffected > 0 {
+		status.Current = current
+	}
+
+	return status, nil
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/forecast"
+)
+
+const (
+	// minForecastDays is the fewest days with runs a job's trend is fitted to.
+	minForecastDays = 5
+	// minForecastRuns is the fewest runs a job's trend is fitted to.
+	minForecastRuns = 10
+)
+
+// jobDayCounts are a job's runs and successes on a day.
+type jobDayCounts struct {
+	ProwJobID uint
+	JobName   string
+	Day       time.Time
+	Runs      int
+	Successes int
+}
+
+// GetReleaseReadinessForecastFromDB forecasts the pass rate at GA of each of the release's blocking jobs, from their
+// daily pass rates between start and end.
+func GetReleaseReadinessForecastFromDB(dbc *db.DB, release string, ga, start, end time.Time,
+	threshold float64) (*apitype.ReleaseReadinessForecast, error) {
+	counts := make([]jobDayCounts, 0)
+	res := dbc.DB.Raw(`SELECT prow_jobs.id AS prow_job_id, prow_jobs.name AS job_name,
+			date_trunc('day', prow_job_runs.timestamp) AS day,
+			COUNT(*) AS runs, COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS successes
+		FROM prow_job_runs
+		JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+		WHERE prow_jobs.release = ? AND prow_jobs.tier = ? AND prow_jobs.deleted_at IS NULL
+			AND prow_job_runs.timestamp >= ? AND prow_job_runs.timestamp < ?
+		GROUP BY prow_jobs.id, prow_jobs.name, day`,
+		release, models.JobTierBlocking, start, end).Scan(&counts)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &apitype.ReleaseReadinessForecast{
+		Release:      release,
+		GA:           ga,
+		HistoryStart: start,
+		HistoryEnd:   end,
+		Threshold:    threshold,
+		Jobs:         forecastJobPassRates(counts, ga, threshold),
+	}, nil
+}
+
+// forecastJobPassRates fits a trend to each job's daily pass rates, weighted by the day's runs, and projects it to GA.
+func forecastJobPassRates(counts []jobDayCounts, ga time.Time, threshold float64) []apitype.JobPassRateForecast {
+	byJob := map[uint][]jobDayCounts{}
+	for _, c := range counts {
+		byJob[c.ProwJobID] = append(byJob[c.ProwJobID], c)
+	}
+
+	jobs := make([]apitype.JobPassRateForecast, 0, len(byJob))
+	for id, days := range byJob {
+		job := apitype.JobPassRateForecast{JobID: id, JobName: days[0].JobName, Status: apitype.ReleaseForecastInsufficientData}
+		sort.Slice(days, func(i, j int) bool { return days[i].Day.Before(days[j].Day) })
+
+		points := make([]forecast.Point, 0, len(days))
+		var successes int
+		for _, day := range days {
+			job.Runs += day.Runs
+			successes += day.Successes
+			if day.Runs > 0 {
+				points = append(points, forecast.Point{
+					Time:   day.Day,
+					Value:  float64(day.Successes) * 100 / float64(day.Runs),
+					Weight: float64(day.Runs),
+				})
+			}
+		}
+		if job.Runs > 0 {
+			job.PassPercentage = float64(successes) * 100 / float64(job.Runs)
+		}
+
+		if len(points) >= minForecastDays && job.Runs >= minForecastRuns {
+			if trend, ok := forecast.Linear(points); ok {
+				low, high := trend.Interval(ga)
+				job.TrendPerDay = trend.SlopePerDay()
+				job.ForecastPassPercentage = clampPercentage(trend.At(ga))
+				job.ForecastLow, job.ForecastHigh = clampPercentage(low), clampPercentage(high)
+				job.Status = apitype.ReleaseForecastOnTrack
+				if job.ForecastLow < threshold {
+					job.Status = apitype.ReleaseForecastAtRisk
+				}
+			}
+		}
+		jobs = append(jobs, job)
+	}
+
+	rank := map[string]int{
+		apitype.ReleaseForecastAtRisk:           0,
+		apitype.ReleaseForecastOnTrack:          1,
+		apitype.ReleaseForecastInsufficientData: 2,
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if rank[jobs[i].Status] != rank[jobs[j].Status] {
+			return rank[jobs[i].Status] < rank[jobs[j].Status]
+		}
+		if jobs[i].ForecastPassPercentage != jobs[j].ForecastPassPercentage {
+			return jobs[i].ForecastPassPercentage < jobs[j].ForecastPassPercentage
+		}
+		return jobs[i].JobName < jobs[j].JobName
+	})
+	return jobs
+}
+
+func clampPercentage(p float64) float64 {
+	return math.Max(0, math.Min(100, p))
+}
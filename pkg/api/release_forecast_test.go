@@ -0,0 +1,61 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestForecastJobPassRates(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	ga := start.AddDate(0, 0, 30)
+	days := func(id uint, name string, successes ...int) []jobDayCounts {
+		counts := make([]jobDayCounts, 0, len(successes))
+		for i, s := range successes {
+			counts = append(counts, jobDayCounts{ProwJobID: id, JobName: name, Day: start.AddDate(0, 0, i), Runs: 10,
+				Successes: s})
+		}
+		return counts
+	}
+
+	var counts []jobDayCounts
+	counts = append(counts, days(1, "aws-ovn", 10, 10, 10, 10, 10, 10, 10)...)
+	counts = append(counts, days(2, "gcp-ovn-serial", 10, 9, 9, 8, 8, 7, 7)...)
+	counts = append(counts, days(3, "metal-ipi", 9, 9, 9)...)
+	counts = append(counts, days(4, "azure-ovn-upgrade", 2, 4, 5, 6, 7, 8, 9)...)
+
+	jobs := forecastJobPassRates(counts, ga, 80)
+	require.Len(t, jobs, 4)
+
+	byName := map[string]apitype.JobPassRateForecast{}
+	for _, job := range jobs {
+		byName[job.JobName] = job
+	}
+
+	steady := byName["aws-ovn"]
+	assert.Equal(t, apitype.ReleaseForecastOnTrack, steady.Status)
+	assert.InDelta(t, 100, steady.ForecastPassPercentage, 0.001)
+	assert.InDelta(t, 0, steady.TrendPerDay, 0.001)
+
+	declining := byName["gcp-ovn-serial"]
+	assert.Equal(t, apitype.ReleaseForecastAtRisk, declining.Status)
+	assert.Less(t, declining.TrendPerDay, 0.0)
+	assert.Zero(t, declining.ForecastPassPercentage, "forecast is clamped to 0")
+	assert.InDelta(t, 58*100.0/70, declining.PassPercentage, 0.001)
+
+	improving := byName["azure-ovn-upgrade"]
+	assert.Equal(t, apitype.ReleaseForecastOnTrack, improving.Status)
+	assert.Equal(t, 100.0, improving.ForecastPassPercentage, "forecast is clamped to 100")
+
+	short := byName["metal-ipi"]
+	assert.Equal(t, apitype.ReleaseForecastInsufficientData, short.Status)
+	assert.Equal(t, 30, short.Runs)
+	assert.Zero(t, short.ForecastPassPercentage)
+
+	assert.Equal(t, "gcp-ovn-serial", jobs[0].JobName, "at risk jobs sort first")
+	assert.Equal(t, "metal-ipi", jobs[3].JobName, "jobs without a forecast sort last")
+}
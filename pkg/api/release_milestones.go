@@ -0,0 +1,112 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+const (
+	// MilestoneFeatureFreeze, MilestoneCodeFreeze and MilestoneGA are a release's milestones, PhaseDevelopment is
+	// the phase of a release before any of them.
+	MilestoneFeatureFreeze = "feature_freeze"
+	MilestoneCodeFreeze    = "code_freeze"
+	MilestoneGA            = "ga"
+	PhaseDevelopment       = "development"
+)
+
+// ReleaseMilestones returns the release's configured milestones in order, GA defaulting to its known GA date, marking
+// those the report's end is past.
+func ReleaseMilestones(release string, config v1.ReleaseMilestones, reportEnd time.Time) []apitype.ReleaseMilestone {
+	if config.GA.IsZero() {
+		config.GA = releaseloader.GADateMap[release]
+	}
+	dates := map[string]time.Time{
+		MilestoneFeatureFreeze: config.FeatureFreeze,
+		MilestoneCodeFreeze:    config.CodeFreeze,
+		MilestoneGA:            config.GA,
+	}
+
+	milestones := make([]apitype.ReleaseMilestone, 0, len(dates))
+	for name, date := range dates {
+		if !date.IsZero() {
+			milestones = append(milestones, apitype.ReleaseMilestone{Name: name, Date: date, Reached: !date.After(reportEnd)})
+		}
+	}
+	sort.Slice(milestones, func(i, j int) bool {
+		return milestones[i].Date.Before(milestones[j].Date)
+	})
+	return milestones
+}
+
+// releasePhase returns the last milestone reached, or development if none has been.
+func releasePhase(milestones []apitype.ReleaseMilestone) string {
+	phase := PhaseDevelopment
+	for _, m := range milestones {
+		if m.Reached {
+			phase = m.Name
+		}
+	}
+	return phase
+}
+
+// GetReleaseMilestoneReportFromDB returns the release's milestones and phase at the report's end, with its job runs,
+// payloads and regressions since each milestone reached, which release managers otherwise count by hand.
+func GetReleaseMilestoneReportFromDB(dbc *db.DB, release string, config v1.ReleaseMilestones,
+	reportEnd time.Time) (*apitype.ReleaseMilestoneReport, error) {
+	milestones := ReleaseMilestones(release, config, reportEnd)
+	report := &apitype.ReleaseMilestoneReport{
+		Release:    release,
+		Phase:      releasePhase(milestones),
+		Milestones: milestones,
+		Since:      make([]apitype.MilestoneAggregate, 0),
+	}
+
+	for _, m := range milestones {
+		if !m.Reached {
+			continue
+		}
+		aggregate := apitype.MilestoneAggregate{Milestone: m.Name, Date: m.Date}
+
+		res := dbc.DB.Table("prow_job_runs").
+			Select(`COUNT(*) AS job_runs,
+				COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS successful_runs,
+				COUNT(*) FILTER (WHERE prow_job_runs.infrastructure_failure) AS infrastructure_failures`).
+			Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+			Where("prow_jobs.release = ? AND prow_job_runs.deleted_at IS NULL", release).
+			Where("prow_job_runs.timestamp >= ? AND prow_job_runs.timestamp < ?", m.Date, reportEnd).
+			Scan(&aggregate)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if aggregate.JobRuns > 0 {
+			aggregate.PassPercentage = float64(aggregate.SuccessfulRuns) / float64(aggregate.JobRuns) * 100
+		}
+
+		res = dbc.DB.Table("release_tags").
+			Select(`COUNT(*) FILTER (WHERE phase = ?) AS accepted_payloads,
+				COUNT(*) FILTER (WHERE phase = ?) AS rejected_payloads`, apitype.PayloadAccepted, apitype.PayloadRejected).
+			Where("release = ? AND deleted_at IS NULL", release).
+			Where("release_time >= ? AND release_time < ?", m.Date, reportEnd).
+			Scan(&aggregate)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+
+		res = dbc.DB.Table("test_regressions").
+			Select("COUNT(*) AS regressions_opened").
+			Where("release = ? AND deleted_at IS NULL", release).
+			Where("first_seen >= ? AND first_seen < ?", m.Date, reportEnd).
+			Scan(&aggregate)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+
+		report.Since = append(report.Since, aggregate)
+	}
+	return report, nil
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+func TestReleaseMilestones(t *testing.T) {
+	featureFreeze := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	codeFreeze := time.Date(2024, 1, 26, 0, 0, 0, 0, time.UTC)
+	ga := time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		release   string
+		config    v1.ReleaseMilestones
+		reportEnd time.Time
+		want      []apitype.ReleaseMilestone
+		wantPhase string
+	}{
+		{
+			name:      "before any milestone",
+			release:   "4.99",
+			config:    v1.ReleaseMilestones{CodeFreeze: codeFreeze, FeatureFreeze: featureFreeze},
+			reportEnd: featureFreeze.Add(-time.Hour),
+			want: []apitype.ReleaseMilestone{
+				{Name: MilestoneFeatureFreeze, Date: featureFreeze},
+				{Name: MilestoneCodeFreeze, Date: codeFreeze},
+			},
+			wantPhase: PhaseDevelopment,
+		},
+		{
+			name:      "GA defaults to the known GA date",
+			release:   "4.15",
+			config:    v1.ReleaseMilestones{FeatureFreeze: featureFreeze, CodeFreeze: codeFreeze},
+			reportEnd: codeFreeze,
+			want: []apitype.ReleaseMilestone{
+				{Name: MilestoneFeatureFreeze, Date: featureFreeze, Reached: true},
+				{Name: MilestoneCodeFreeze, Date: codeFreeze, Reached: true},
+				{Name: MilestoneGA, Date: ga},
+			},
+			wantPhase: MilestoneCodeFreeze,
+		},
+		{
+			name:      "configured GA",
+			release:   "4.99",
+			config:    v1.ReleaseMilestones{GA: ga},
+			reportEnd: ga.AddDate(0, 1, 0),
+			want:      []apitype.ReleaseMilestone{{Name: MilestoneGA, Date: ga, Reached: true}},
+			wantPhase: MilestoneGA,
+		},
+		{
+			name:      "no milestones",
+			release:   "4.99",
+			want:      []apitype.ReleaseMilestone{},
+			wantPhase: PhaseDevelopment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			milestones := ReleaseMilestones(tt.release, tt.config, tt.reportEnd)
+			assert.Equal(t, tt.want, milestones)
+			assert.Equal(t, tt.wantPhase, releasePhase(milestones))
+		})
+	}
+}
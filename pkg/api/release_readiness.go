@@ -0,0 +1,266 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	fischer "github.com/glycerine/golang-fisher-exact"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/apis/cache"
+	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// extremeReadinessDrop is how many points a test's pass percentage must drop, on top of being significant, to turn its
+// cell red rather than yellow.
+const extremeReadinessDrop = 15
+
+// readinessTestCounts are a test's results in the jobs of one variant.
+type readinessTestCounts struct {
+	TestID    uint
+	TestName  string
+	Component string
+	Variant   string
+	Successes int
+	Flakes    int
+	Failures  int
+}
+
+func (c readinessTestCounts) passes() int {
+	return c.Successes + c.Flakes
+}
+
+func (c readinessTestCounts) runs() int {
+	return c.Successes + c.Flakes + c.Failures
+}
+
+func (c readinessTestCounts) passPercentage() float64 {
+	if c.runs() == 0 {
+		return 0
+	}
+	return float64(c.passes()) / float64(c.runs()) * 100
+}
+
+// ReleaseReadinessBasisCacheKey caches a basis' test results, which don't change once its end is in the past.
+type ReleaseReadinessBasisCacheKey struct {
+	Query   string
+	Release string
+	Start   time.Time
+	End     time.Time
+}
+
+// DefaultReleaseReadinessBasis is the four weeks up to the previous release's GA, like component readiness defaults to.
+func DefaultReleaseReadinessBasis(release string) (string, time.Time, time.Time, error) {
+	basisRelease, err := previousRelease(release)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("couldn't determine the release before %s: %w", release, err)
+	}
+	ga, ok := releaseloader.GADateMap[basisRelease]
+	if !ok {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("%s has no GA date, a basis must be given", basisRelease)
+	}
+	return basisRelease, ga.AddDate(0, 0, -27), ga.AddDate(0, 0, 1).Add(-1 * time.Second), nil
+}
+
+// GetReleaseReadinessReport compares the tests of each component and variant in a release's sample to the basis.
+func GetReleaseReadinessReport(dbc *db.DB, c cache.Cache, options apitype.ReleaseReadinessRequestOptions) (apitype.ReleaseReadinessReport, []error) {
+	basisKey := ReleaseReadinessBasisCacheKey{
+		Query:   "ReleaseReadinessBasis",
+		Release: options.BasisRelease,
+		Start:   options.BasisStart,
+		End:     options.BasisEnd,
+	}
+	basis, errs := getReportFromCacheOrGenerate[[]readinessTestCounts](dbc.DB.Statement.Context, c, cache.RequestOptions{}, basisKey,
+		func(context.Context) ([]readinessTestCounts, []error) {
+			counts, err := readinessTestCountsFromDB(dbc, options.BasisRelease, options.BasisStart, options.BasisEnd)
+			if err != nil {
+				return nil, []error{err}
+			}
+			return counts, nil
+		}, nil)
+	if len(errs) > 0 {
+		return apitype.ReleaseReadinessReport{}, errs
+	}
+
+	sample, err := readinessTestCountsFromDB(dbc, options.Release, options.SampleStart, options.SampleEnd)
+	if err != nil {
+		return apitype.ReleaseReadinessReport{}, []error{err}
+	}
+
+	return buildReleaseReadinessReport(options, basis, sample), nil
+}
+
+// readinessTestCountsFromDB counts the results of each test with a component in the release's jobs, once for every
+// variant of the jobs. The basis is usually old enough to have been compacted, so the passes of compacted days are
+// summed from the daily results.
+func readinessTestCountsFromDB(dbc *db.DB, release string, start, end time.Time) ([]readinessTestCounts, error) {
+	counts := make([]readinessTestCounts, 0)
+	res := dbc.DB.Raw(`
+		WITH results AS (
+			SELECT
+				prow_job_run_tests.test_id,
+				prow_job_run_tests.suite_id,
+				prow_jobs.variants,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1) AS successes,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13) AS flakes,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12) AS failures
+			FROM prow_job_run_tests
+				JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+				JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_jobs.release = @release
+				AND prow_job_runs.timestamp BETWEEN @start AND @end
+				AND prow_job_run_tests.deleted_at IS NULL
+			GROUP BY prow_job_run_tests.test_id, prow_job_run_tests.suite_id, prow_jobs.variants
+			UNION ALL
+			SELECT test_id, suite_id, variants, SUM(successes), 0, 0
+			FROM test_daily_results
+			WHERE release = @release
+				AND date BETWEEN DATE(@start) AND DATE(@end)
+				AND deleted_at IS NULL
+			GROUP BY test_id, suite_id, variants
+		)
+		SELECT
+			tests.id AS test_id,
+			tests.name AS test_name,
+			test_ownerships.component,
+			variant,
+			SUM(results.successes)::bigint AS successes,
+			SUM(results.flakes)::bigint AS flakes,
+			SUM(results.failures)::bigint AS failures
+		FROM results
+			JOIN tests ON tests.id = results.test_id
+			JOIN test_ownerships ON tests.id = test_ownerships.test_id
+				AND results.suite_id = test_ownerships.suite_id
+			CROSS JOIN UNNEST(results.variants) AS variant
+		WHERE test_ownerships.component <> ''
+		GROUP BY tests.id, tests.name, test_ownerships.component, variant`,
+		map[string]interface{}{"release": release, "start": start, "end": end}).Scan(&counts)
+	return counts, res.Error
+}
+
+type readinessCellKey struct {
+	component string
+	variant   string
+}
+
+type readinessTestKey struct {
+	readinessCellKey
+	testID uint
+}
+
+type readinessCell struct {
+	compared       int
+	extreme        bool
+	regressedTests []apitype.ReleaseReadinessRegressedTest
+}
+
+func (c *readinessCell) status() apitype.ReleaseReadinessStatus {
+	switch {
+	case c == nil || c.compared == 0:
+		return apitype.ReleaseReadinessMissing
+	case c.extreme:
+		return apitype.ReleaseReadinessRed
+	case len(c.regressedTests) > 0:
+		return apitype.ReleaseReadinessYellow
+	default:
+		return apitype.ReleaseReadinessGreen
+	}
+}
+
+func buildReleaseReadinessReport(options apitype.ReleaseReadinessRequestOptions, basis, sample []readinessTestCounts) apitype.ReleaseReadinessReport {
+	basisCounts := map[readinessTestKey]readinessTestCounts{}
+	components, variants := map[string]bool{}, map[string]bool{}
+	for _, b := range basis {
+		basisCounts[readinessTestKey{readinessCellKey{b.Component, b.Variant}, b.TestID}] = b
+		components[b.Component], variants[b.Variant] = true, true
+	}
+
+	cells := map[readinessCellKey]*readinessCell{}
+	for _, s := range sample {
+		components[s.Component], variants[s.Variant] = true, true
+		cellKey := readinessCellKey{s.Component, s.Variant}
+		b, ok := basisCounts[readinessTestKey{cellKey, s.TestID}]
+		if !ok || b.runs() == 0 || s.runs() == 0 {
+			// new tests, and those that didn't run, have nothing to compare
+			continue
+		}
+
+		cell, ok := cells[cellKey]
+		if !ok {
+			cell = &readinessCell{}
+			cells[cellKey] = cell
+		}
+		cell.compared++
+
+		pValue, worse := SignificantlyWorse(b.Failures, b.passes(), s.Failures, s.passes(), options.Confidence)
+		if !worse {
+			continue
+		}
+		cell.regressedTests = append(cell.regressedTests, apitype.ReleaseReadinessRegressedTest{
+			TestID:               s.TestID,
+			TestName:             s.TestName,
+			BasisPassPercentage:  b.passPercentage(),
+			BasisRuns:            b.runs(),
+			SamplePassPercentage: s.passPercentage(),
+			SampleRuns:           s.runs(),
+			PValue:               pValue,
+		})
+		if b.passPercentage()-s.passPercentage() > extremeReadinessDrop {
+			cell.extreme = true
+		}
+	}
+
+	report := apitype.ReleaseReadinessReport{
+		Release:      options.Release,
+		SampleStart:  options.SampleStart,
+		SampleEnd:    options.SampleEnd,
+		BasisRelease: options.BasisRelease,
+		BasisStart:   options.BasisStart,
+		BasisEnd:     options.BasisEnd,
+		Variants:     sortedKeys(variants),
+		Rows:         make([]apitype.ReleaseReadinessRow, 0, len(components)),
+	}
+	for _, component := range sortedKeys(components) {
+		row := apitype.ReleaseReadinessRow{Component: component}
+		for _, variant := range report.Variants {
+			cell := cells[readinessCellKey{component, variant}]
+			reportCell := apitype.ReleaseReadinessCell{Variant: variant, Status: cell.status()}
+			if cell != nil {
+				sort.Slice(cell.regressedTests, func(i, j int) bool {
+					return cell.regressedTests[i].TestName < cell.regressedTests[j].TestName
+				})
+				reportCell.RegressedTests = cell.regressedTests
+			}
+			row.Cells = append(row.Cells, reportCell)
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return report
+}
+
+// SignificantlyWorse compares a sample's failures to a basis' with Fisher's exact test, returning the p-value and
+// whether the sample's pass rate is lower at the percent confidence. Improvements are never significant.
+func SignificantlyWorse(basisFailures, basisPasses, sampleFailures, samplePasses, confidence int) (float64, bool) {
+	basisTotal, sampleTotal := basisFailures+basisPasses, sampleFailures+samplePasses
+	if basisTotal == 0 || sampleTotal == 0 {
+		return 0, false
+	}
+	if float64(samplePasses)/float64(sampleTotal) >= float64(basisPasses)/float64(basisTotal) {
+		return 0, false
+	}
+
+	_, _, _, pValue := fischer.FisherExactTest(sampleFailures, samplePasses, basisFailures, basisPasses)
+	return pValue, pValue < 1-float64(confidence)/100
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestBuildReleaseReadinessReport(t *testing.T) {
+	basis := []readinessTestCounts{
+		{TestID: 1, TestName: "steady", Component: "Networking", Variant: "aws", Successes: 100},
+		{TestID: 2, TestName: "slightly worse", Component: "Networking", Variant: "aws", Successes: 98, Failures: 2},
+		{TestID: 2, TestName: "slightly worse", Component: "Networking", Variant: "gcp", Successes: 990, Failures: 10},
+		{TestID: 3, TestName: "broken", Component: "Storage", Variant: "aws", Successes: 100},
+		{TestID: 4, TestName: "gone", Component: "Storage", Variant: "azure", Successes: 100},
+	}
+	sample := []readinessTestCounts{
+		{TestID: 1, TestName: "steady", Component: "Networking", Variant: "aws", Successes: 99, Flakes: 1},
+		{TestID: 2, TestName: "slightly worse", Component: "Networking", Variant: "aws", Successes: 95, Failures: 5},
+		{TestID: 2, TestName: "slightly worse", Component: "Networking", Variant: "gcp", Successes: 950, Failures: 50},
+		{TestID: 3, TestName: "broken", Component: "Storage", Variant: "aws", Successes: 50, Failures: 50},
+		{TestID: 5, TestName: "new", Component: "Storage", Variant: "gcp", Failures: 10},
+	}
+
+	report := buildReleaseReadinessReport(apitype.ReleaseReadinessRequestOptions{Release: "4.16", BasisRelease: "4.15", Confidence: 95}, basis, sample)
+	assert.Equal(t, []string{"aws", "azure", "gcp"}, report.Variants)
+	require.Len(t, report.Rows, 2)
+
+	statuses := map[string][]apitype.ReleaseReadinessStatus{}
+	for _, row := range report.Rows {
+		for _, cell := range row.Cells {
+			statuses[row.Component] = append(statuses[row.Component], cell.Status)
+		}
+	}
+	assert.Equal(t, map[string][]apitype.ReleaseReadinessStatus{
+		// a 3 point drop over 100 runs isn't significant, but is over 1000
+		"Networking": {apitype.ReleaseReadinessGreen, apitype.ReleaseReadinessMissing, apitype.ReleaseReadinessYellow},
+		// new tests have nothing to compare to
+		"Storage": {apitype.ReleaseReadinessRed, apitype.ReleaseReadinessMissing, apitype.ReleaseReadinessMissing},
+	}, statuses)
+
+	gcp := report.Rows[0].Cells[2]
+	require.Len(t, gcp.RegressedTests, 1)
+	assert.Equal(t, "slightly worse", gcp.RegressedTests[0].TestName)
+	assert.Equal(t, 99.0, gcp.RegressedTests[0].BasisPassPercentage)
+	assert.Equal(t, 95.0, gcp.RegressedTests[0].SamplePassPercentage)
+	assert.Equal(t, 1000, gcp.RegressedTests[0].SampleRuns)
+}
+
+func TestSignificantlyWorse(t *testing.T) {
+	_, worse := SignificantlyWorse(2, 98, 30, 70, 95)
+	assert.True(t, worse)
+
+	_, worse = SignificantlyWorse(30, 70, 2, 98, 95)
+	assert.False(t, worse, "improvements are never significant")
+
+	_, worse = SignificantlyWorse(0, 0, 10, 0, 95)
+	assert.False(t, worse, "nothing to compare to")
+}
@@ -30,15 +30,12 @@ func PrintPullRequestsReport(w http.ResponseWriter, req *http.Request, dbClient
 	q = q.Joins(`INNER JOIN release_tag_pull_requests ON release_tag_pull_requests.release_pull_request_id = release_pull_requests.id JOIN release_tags on release_tags.id = release_tag_pull_requests.release_tag_id`)
 	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, err.Error())
 		return
 	}
 	q, err = filter.FilterableDBResult(q, filterOpts, nil)
 	if err != nil {
-		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": err.Error(),
-		})
+		RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 
@@ -317,15 +314,12 @@ func PrintReleasesReport(w http.ResponseWriter, req *http.Request, dbClient *db.
 
 	filterOpts, err := filter.FilterOptionsFromRequest(req, "release_tag", apitype.SortDescending)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job run report:" + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Error building job run report:"+err.Error())
 		return
 	}
 	q, err := filter.FilterableDBResult(releaseFilter(req, dbClient.DB), filterOpts, nil)
 	if err != nil {
-		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": err.Error(),
-		})
+		RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 
@@ -468,6 +462,7 @@ func ScanReleaseHealthForRHCOSVersionMisMatches(payloadHealth []apitype.ReleaseH
 }
 
 func releaseFilter(req *http.Request, dbc *gorm.DB) *gorm.DB {
+	dbc = tenantFilter(req, dbc)
 	releaseFilter := req.URL.Query().Get("release")
 	if releaseFilter != "" {
 		return dbc.Where("release = ?", releaseFilter)
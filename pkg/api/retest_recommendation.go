@@ -0,0 +1,225 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api/failureclusters"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/failureclassification"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+const (
+	// RetestLikelyToPass, RetestRealFailure and RetestNotNeeded are the recommendations for a run, and the outcomes
+	// factors favor, along with RetestNeutral.
+	RetestLikelyToPass = "retest"
+	RetestRealFailure  = "real_failure"
+	RetestNotNeeded    = "none"
+	RetestNeutral      = "neutral"
+
+	// retestSimilarFailureDays is how far back failures of the run's tests in other jobs are compared to its own.
+	retestSimilarFailureDays = 7
+	// retestSimilarityThreshold is the estimated similarity of two outputs for them to be the same failure, as for
+	// failure clusters.
+	retestSimilarityThreshold = 0.8
+	// retestMinSimilarJobs is how many other jobs a test must have failed similarly in for its failure not to be
+	// specific to the run.
+	retestMinSimilarJobs = 2
+)
+
+// GetRetestRecommendationFromDB recommends whether the failed run is likely to pass when retested, from its risk
+// analysis, its failure classification, and whether its tests failed similarly in other jobs in the week before now,
+// are quarantined or have open bugs.
+func GetRetestRecommendationFromDB(dbc *db.DB, jobRunID int64, now time.Time,
+	logger *log.Entry) (*apitype.RetestRecommendation, error) {
+	jobRun, jobRunTestCount, err := FetchJobRun(dbc, jobRunID, logger)
+	if err != nil {
+		return nil, err
+	}
+	risk, err := JobRunRiskAnalysis(dbc, jobRun, jobRunTestCount, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make([]models.ProwJobRunTest, 0, len(jobRun.Tests))
+	testIDs, runTestIDs := make([]uint, 0, len(jobRun.Tests)), make([]uint, 0, len(jobRun.Tests))
+	for _, ft := range jobRun.Tests {
+		if ft.Test.Name == testidentification.OpenShiftTestsName || testidentification.IsIgnoredTest(ft.Test.Name) {
+			continue
+		}
+		failed = append(failed, ft)
+		testIDs = append(testIDs, ft.TestID)
+		runTestIDs = append(runTestIDs, ft.ID)
+	}
+
+	outputs := map[uint]string{}
+	candidates := map[string][]failureclusters.Failure{}
+	if len(failed) > 0 {
+		rows := make([]models.ProwJobRunTestOutput, 0)
+		if res := dbc.DB.Where("prow_job_run_test_id IN ?", runTestIDs).Find(&rows); res.Error != nil {
+			return nil, res.Error
+		}
+		for _, row := range rows {
+			outputs[row.ProwJobRunTestID] = row.Output
+		}
+
+		others, err := failureclusters.TestFailuresFromDB(dbc, testIDs, jobRun.ProwJobID,
+			now.AddDate(0, 0, -retestSimilarFailureDays), now)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range others {
+			candidates[other.TestName] = append(candidates[other.TestName], other)
+		}
+	}
+
+	quarantines, err := ListTestQuarantinesFromDB(dbc, false, now)
+	if err != nil {
+		return nil, err
+	}
+	quarantined := map[string]bool{}
+	for _, q := range quarantines {
+		quarantined[q.TestName] = true
+	}
+
+	risks := map[string]apitype.ProwJobRunTestRiskAnalysis{}
+	for _, testRisk := range risk.Tests {
+		risks[testRisk.Name] = testRisk
+	}
+
+	tests := make([]apitype.RetestTest, 0, len(failed))
+	for _, ft := range failed {
+		test := apitype.RetestTest{
+			Name:        ft.Test.Name,
+			RiskLevel:   apitype.FailureRiskLevelUnknown.Name,
+			RiskReasons: []string{},
+			Quarantined: quarantined[ft.Test.Name],
+			OpenBugs:    len(ft.Test.Bugs),
+		}
+		if testRisk, ok := risks[ft.Test.Name]; ok {
+			test.RiskLevel, test.RiskReasons = testRisk.Risk.Level.Name, testRisk.Risk.Reasons
+			test.OpenBugs = len(testRisk.OpenBugs)
+		}
+		if output, ok := outputs[ft.ID]; ok && output != "" {
+			failure := failureclusters.Failure{TestName: ft.Test.Name, JobName: jobRun.ProwJob.Name, Output: output}
+			jobs := map[string]bool{}
+			for _, similar := range failureclusters.Matching(failure, candidates[ft.Test.Name], retestSimilarityThreshold) {
+				test.SimilarFailures++
+				jobs[similar.JobName] = true
+			}
+			test.SimilarJobs = len(jobs)
+		}
+		tests = append(tests, test)
+	}
+
+	recommendation := recommendRetest(jobRun, risk, tests)
+	return &recommendation, nil
+}
+
+// recommendRetest weighs the factors of the run's failure, recommending a retest when they favor one overall.
+func recommendRetest(jobRun *models.ProwJobRun, risk apitype.ProwJobRunRiskAnalysis,
+	tests []apitype.RetestTest) apitype.RetestRecommendation {
+	recommendation := apitype.RetestRecommendation{
+		ProwJobRunID: jobRun.ID,
+		ProwJobName:  jobRun.ProwJob.Name,
+		Release:      jobRun.ProwJob.Release,
+		RiskLevel:    risk.OverallRisk.Level.Name,
+		Factors:      []apitype.RetestFactor{},
+		Tests:        tests,
+	}
+	if jobRun.Succeeded {
+		recommendation.Recommendation = RetestNotNeeded
+		return recommendation
+	}
+
+	add := func(name string, weight int, detail string) {
+		favors := RetestNeutral
+		switch {
+		case weight > 0:
+			favors = RetestLikelyToPass
+		case weight < 0:
+			favors = RetestRealFailure
+		}
+		recommendation.Factors = append(recommendation.Factors,
+			apitype.RetestFactor{Name: name, Favors: favors, Weight: weight, Detail: detail})
+		recommendation.Score += weight
+	}
+
+	switch {
+	case jobRun.InfrastructureFailure || jobRun.FailureClassification == string(failureclassification.InfraFailure):
+		add("classification", 3, "the run failed due to CI infrastructure")
+	case jobRun.FailureClassification == string(failureclassification.InstallFailure):
+		add("classification", 2, "the cluster failed to install, which is rarely caused by the change")
+	case jobRun.FailureClassification == string(failureclassification.ProductFailure):
+		add("classification", 0, "the run's tests failed")
+	}
+
+	reasons := strings.Join(risk.OverallRisk.Reasons, " ")
+	switch risk.OverallRisk.Level.Level {
+	case apitype.FailureRiskLevelHigh.Level:
+		add("risk analysis", -3, "the failed tests rarely fail elsewhere: "+reasons)
+	case apitype.FailureRiskLevelMedium.Level:
+		add("risk analysis", -1, "the failed tests usually pass elsewhere: "+reasons)
+	case apitype.FailureRiskLevelLow.Level:
+		add("risk analysis", 2, "the failed tests often fail elsewhere too: "+reasons)
+	case apitype.FailureRiskLevelIncompleteTests.Level, apitype.FailureRiskLevelMissingData.Level:
+		add("risk analysis", 2, "fewer tests ran than usual, pointing at infrastructure, install or upgrade problems: "+reasons)
+	case apitype.FailureRiskLevelNone.Level:
+		add("risk analysis", 1, "no tests failed, so the run failed outside of them")
+	default:
+		add("risk analysis", 0, "the failed tests' results elsewhere are unknown: "+reasons)
+	}
+
+	if len(tests) > 0 {
+		var similar, quarantined, withBugs int
+		for _, test := range tests {
+			if test.SimilarJobs >= retestMinSimilarJobs {
+				similar++
+			}
+			if test.Quarantined {
+				quarantined++
+			}
+			if test.OpenBugs > 0 {
+				withBugs++
+			}
+		}
+
+		switch similar {
+		case len(tests):
+			add("similar failures", 2, fmt.Sprintf("every failed test failed with a similar output in at least %d other jobs in the last %d days",
+				retestMinSimilarJobs, retestSimilarFailureDays))
+		case 0:
+			add("similar failures", -1, fmt.Sprintf("no failed test failed with a similar output in other jobs in the last %d days",
+				retestSimilarFailureDays))
+		default:
+			add("similar failures", 0, fmt.Sprintf("%d of %d failed tests failed with a similar output in at least %d other jobs in the last %d days",
+				similar, len(tests), retestMinSimilarJobs, retestSimilarFailureDays))
+		}
+
+		switch {
+		case quarantined == len(tests):
+			add("quarantine", 2, "every failed test is quarantined")
+		case quarantined > 0:
+			add("quarantine", 1, fmt.Sprintf("%d of %d failed tests are quarantined", quarantined, len(tests)))
+		}
+		if withBugs == len(tests) {
+			add("open bugs", 1, "every failed test has open bugs")
+		}
+	}
+
+	if jobRun.KnownFailure {
+		add("known failure", 1, "a bug already filed likely explains the failure")
+	}
+
+	recommendation.Recommendation = RetestRealFailure
+	if recommendation.Score > 0 {
+		recommendation.Recommendation = RetestLikelyToPass
+	}
+	return recommendation
+}
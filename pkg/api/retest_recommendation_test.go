@@ -0,0 +1,87 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/failureclassification"
+)
+
+func TestRecommendRetest(t *testing.T) {
+	risk := func(level apitype.RiskLevel) apitype.ProwJobRunRiskAnalysis {
+		return apitype.ProwJobRunRiskAnalysis{OverallRisk: apitype.FailureRisk{Level: level, Reasons: []string{"reason"}}}
+	}
+
+	tests := []struct {
+		name           string
+		jobRun         models.ProwJobRun
+		risk           apitype.ProwJobRunRiskAnalysis
+		tests          []apitype.RetestTest
+		recommendation string
+		score          int
+	}{
+		{
+			name:           "succeeded",
+			jobRun:         models.ProwJobRun{Succeeded: true},
+			risk:           risk(apitype.FailureRiskLevelNone),
+			recommendation: RetestNotNeeded,
+		},
+		{
+			name:           "infrastructure failure",
+			jobRun:         models.ProwJobRun{Failed: true, InfrastructureFailure: true},
+			risk:           risk(apitype.FailureRiskLevelIncompleteTests),
+			recommendation: RetestLikelyToPass,
+			score:          5,
+		},
+		{
+			name:   "tests that usually pass failed like nowhere else",
+			jobRun: models.ProwJobRun{Failed: true, FailureClassification: string(failureclassification.ProductFailure)},
+			risk:   risk(apitype.FailureRiskLevelHigh),
+			tests: []apitype.RetestTest{
+				{Name: "test a", SimilarFailures: 1, SimilarJobs: 1},
+				{Name: "test b"},
+			},
+			recommendation: RetestRealFailure,
+			score:          -4,
+		},
+		{
+			name:   "flaky tests failing across jobs",
+			jobRun: models.ProwJobRun{Failed: true, FailureClassification: string(failureclassification.ProductFailure)},
+			risk:   risk(apitype.FailureRiskLevelLow),
+			tests: []apitype.RetestTest{
+				{Name: "test a", SimilarFailures: 12, SimilarJobs: 5, OpenBugs: 1},
+				{Name: "test b", SimilarFailures: 3, SimilarJobs: 2, Quarantined: true, OpenBugs: 2},
+			},
+			recommendation: RetestLikelyToPass,
+			score:          6,
+		},
+		{
+			name:   "quarantined tests don't outweigh high risk",
+			jobRun: models.ProwJobRun{Failed: true},
+			risk:   risk(apitype.FailureRiskLevelHigh),
+			tests: []apitype.RetestTest{
+				{Name: "test a", Quarantined: true},
+				{Name: "test b"},
+			},
+			recommendation: RetestRealFailure,
+			score:          -3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recommendation := recommendRetest(&tc.jobRun, tc.risk, tc.tests)
+			assert.Equal(t, tc.recommendation, recommendation.Recommendation)
+			assert.Equal(t, tc.score, recommendation.Score)
+
+			sum := 0
+			for _, factor := range recommendation.Factors {
+				sum += factor.Weight
+			}
+			assert.Equal(t, recommendation.Score, sum, "the score should be the sum of the factors")
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgtype"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+var (
+	slugRegexp        = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Slugify makes a slug from a view's name, i.e. "4.16 metal techpreview" becomes "4-16-metal-techpreview".
+func Slugify(name string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// NewSavedView validates the request, returning the view it describes.
+func NewSavedView(owner string, req apitype.SavedViewRequest) (*models.SavedView, error) {
+	view := &models.SavedView{
+		Slug:    req.Slug,
+		Name:    strings.TrimSpace(req.Name),
+		Owner:   owner,
+		Global:  req.Global,
+		Release: req.Release,
+		Variant: req.Variant,
+		Filter:  pgtype.JSONB{Status: pgtype.Null},
+	}
+	if view.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if view.Release == "" {
+		return nil, fmt.Errorf("release is required")
+	}
+	if view.Slug == "" {
+		view.Slug = Slugify(view.Name)
+	}
+	if !slugRegexp.MatchString(view.Slug) {
+		return nil, fmt.Errorf("slug %q must be lowercase letters and numbers separated by dashes", view.Slug)
+	}
+
+	if len(req.Filter) > 0 && !bytes.Equal(req.Filter, []byte("null")) {
+		f := filter.Filter{}
+		decoder := json.NewDecoder(bytes.NewReader(req.Filter))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&f); err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+		switch f.LinkOperator {
+		case "", filter.LinkOperatorAnd, filter.LinkOperatorOr:
+		default:
+			return nil, fmt.Errorf("invalid filter: unknown link operator %q", f.LinkOperator)
+		}
+		if err := view.Filter.Set([]byte(req.Filter)); err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+	}
+	return view, nil
+}
+
+// canEditSavedView returns true if the user may change or delete the view.
+func canEditSavedView(view *models.SavedView, user string) bool {
+	return view.Global || view.Owner == user
+}
+
+// ListSavedViewsFromDB returns the global views and the user's personal views, by name.
+func ListSavedViewsFromDB(dbc *db.DB, user string) ([]models.SavedView, error) {
+	views := make([]models.SavedView, 0)
+	res := dbc.DB.Where("global OR owner = ?", user).Order("name, slug").Find(&views)
+	return views, res.Error
+}
+
+// GetSavedViewFromDB returns the view with the slug, or nil if there's none.
+func GetSavedViewFromDB(dbc *db.DB, slug string) (*models.SavedView, error) {
+	view := &models.SavedView{}
+	res := dbc.DB.Where("slug = ?", slug).Limit(1).Find(view)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+	return view, nil
+}
+
+// CreateSavedView stores a new view, failing if its slug is taken.
+func CreateSavedView(dbc *db.DB, view *models.SavedView) error {
+	existing, err := GetSavedViewFromDB(dbc, view.Slug)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return NewProblem(http.StatusConflict, fmt.Sprintf("saved view %q already exists", view.Slug))
+	}
+	return dbc.DB.Create(view).Error
+}
+
+// UpdateSavedView replaces the view's name, scope, release, variant and filter with the updated view's, if the user
+// may edit it. Only the owner can change whether a view is global, and the slug can't be changed.
+func UpdateSavedView(dbc *db.DB, view, updated *models.SavedView, user string) error {
+	if !canEditSavedView(view, user) || (updated.Global != view.Global && view.Owner != user) {
+		return NewProblem(http.StatusForbidden,
+			fmt.Sprintf("saved view %q can only be changed by %s", view.Slug, view.Owner))
+	}
+	if updated.Slug != view.Slug {
+		return NewProblem(http.StatusBadRequest, "a saved view's slug can't be changed")
+	}
+	view.Name = updated.Name
+	view.Global = updated.Global
+	view.Release = updated.Release
+	view.Variant = updated.Variant
+	view.Filter = updated.Filter
+	return dbc.DB.Model(view).Select("name", "global", "release", "variant", "filter", "updated_at").Updates(view).Error
+}
+
+// DeleteSavedView deletes the view, freeing its slug, if the user may edit it.
+func DeleteSavedView(dbc *db.DB, view *models.SavedView, user string) error {
+	if !canEditSavedView(view, user) {
+		return NewProblem(http.StatusForbidden,
+			fmt.Sprintf("saved view %q can only be deleted by %s", view.Slug, view.Owner))
+	}
+	return dbc.DB.Delete(view).Error
+}
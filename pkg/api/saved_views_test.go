@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "4-16-metal-techpreview", Slugify("4.16 metal techpreview"))
+	assert.Equal(t, "sdn-on-aws", Slugify("  SDN on AWS!! "))
+	assert.Equal(t, "", Slugify("..."))
+}
+
+func TestNewSavedView(t *testing.T) {
+	filter := json.RawMessage(`{"items":[{"columnField":"variants","operatorValue":"contains","value":"metal"}],"linkOperator":"and"}`)
+
+	tests := []struct {
+		name     string
+		req      apitype.SavedViewRequest
+		wantSlug string
+		wantErr  string
+	}{
+		{
+			name:     "slug from name",
+			req:      apitype.SavedViewRequest{Name: "4.16 metal techpreview", Release: "4.16", Filter: filter},
+			wantSlug: "4-16-metal-techpreview",
+		},
+		{
+			name:     "explicit slug",
+			req:      apitype.SavedViewRequest{Name: "Metal", Slug: "metal-tp", Release: "4.16"},
+			wantSlug: "metal-tp",
+		},
+		{
+			name:    "invalid slug",
+			req:     apitype.SavedViewRequest{Name: "Metal", Slug: "Metal TP", Release: "4.16"},
+			wantErr: "must be lowercase letters and numbers",
+		},
+		{
+			name:    "name without slug characters",
+			req:     apitype.SavedViewRequest{Name: "!!", Release: "4.16"},
+			wantErr: "must be lowercase letters and numbers",
+		},
+		{
+			name:    "missing release",
+			req:     apitype.SavedViewRequest{Name: "Metal"},
+			wantErr: "release is required",
+		},
+		{
+			name:    "unknown filter field",
+			req:     apitype.SavedViewRequest{Name: "Metal", Release: "4.16", Filter: json.RawMessage(`{"itmes":[]}`)},
+			wantErr: "invalid filter",
+		},
+		{
+			name:    "unknown link operator",
+			req:     apitype.SavedViewRequest{Name: "Metal", Release: "4.16", Filter: json.RawMessage(`{"items":[],"linkOperator":"xor"}`)},
+			wantErr: "unknown link operator",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view, err := NewSavedView("alice", tt.req)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSlug, view.Slug)
+			assert.Equal(t, "alice", view.Owner)
+			if tt.req.Filter != nil {
+				assert.Equal(t, pgtype.Present, view.Filter.Status)
+				assert.JSONEq(t, string(tt.req.Filter), string(view.Filter.Bytes))
+			} else {
+				assert.Equal(t, pgtype.Null, view.Filter.Status)
+			}
+		})
+	}
+}
+
+func TestSavedViewPermissions(t *testing.T) {
+	personal := &models.SavedView{Slug: "mine", Owner: "alice"}
+	global := &models.SavedView{Slug: "ours", Owner: "alice", Global: true}
+
+	var problem Problem
+	require.ErrorAs(t, UpdateSavedView(nil, personal, &models.SavedView{Slug: "mine"}, "bob"), &problem)
+	assert.Equal(t, 403, problem.Status)
+	require.ErrorAs(t, UpdateSavedView(nil, global, &models.SavedView{Slug: "ours"}, "bob"), &problem)
+	assert.Equal(t, 403, problem.Status, "only the owner can make a global view personal")
+	require.ErrorAs(t, UpdateSavedView(nil, personal, &models.SavedView{Slug: "renamed"}, "alice"), &problem)
+	assert.Equal(t, 400, problem.Status)
+	require.ErrorAs(t, DeleteSavedView(nil, personal, "bob"), &problem)
+	assert.Equal(t, 403, problem.Status)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+const (
+	// SearchTests, SearchJobs and SearchFailures are what can be searched.
+	SearchTests    = "tests"
+	SearchJobs     = "jobs"
+	SearchFailures = "failures"
+
+	// MinSearchLength is the shortest search, pg_trgm can't match anything shorter than a trigram.
+	MinSearchLength = 3
+
+	// DefaultSearchLimit and MaxSearchLimit are how many of each kind are returned by default, and at most.
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	// searchFailureDays is how far back failure messages are searched, outputs are only kept for recent runs and
+	// searching all of them is slow.
+	searchFailureDays = 14
+
+	// snippetContext is how much of a failure message is returned either side of the match.
+	snippetContext = 100
+)
+
+// SearchFromDB fuzzily searches the names of tests and jobs, and the failure messages of the last two weeks, for the
+// query, returning up to limit of each kind that's wanted, best match first. Jobs and failures are limited to the
+// release when it's set.
+func SearchFromDB(dbc *db.DB, q, release string, kinds []string, limit int, reportEnd time.Time) (*apitype.SearchResults, error) {
+	results := &apitype.SearchResults{
+		Query:    q,
+		Tests:    make([]apitype.SearchResult, 0),
+		Jobs:     make([]apitype.SearchResult, 0),
+		Failures: make([]apitype.FailureSearchResult, 0),
+	}
+	like := fmt.Sprintf("%%%s%%", q)
+
+	for _, kind := range kinds {
+		switch kind {
+		case SearchTests:
+			res := dbc.DB.Table("tests").
+				Select("id, name, GREATEST(similarity(name, ?), word_similarity(?, name)) AS score", q, q).
+				Where("deleted_at IS NULL").
+				Where("name % ? OR ? <% name OR name ILIKE ?", q, q, like).
+				Order("score DESC, name").
+				Limit(limit).
+				Scan(&results.Tests)
+			if res.Error != nil {
+				return nil, res.Error
+			}
+		case SearchJobs:
+			jq := dbc.DB.Table("prow_jobs").
+				Select("id, name, release, GREATEST(similarity(name, ?), word_similarity(?, name)) AS score", q, q).
+				Where("deleted_at IS NULL").
+				Where("name % ? OR ? <% name OR name ILIKE ?", q, q, like)
+			if release != "" {
+				jq = jq.Where("release = ?", release)
+			}
+			res := jq.Order("score DESC, name").Limit(limit).Scan(&results.Jobs)
+			if res.Error != nil {
+				return nil, res.Error
+			}
+		case SearchFailures:
+			type failureMatch struct {
+				apitype.FailureSearchResult
+				Output string
+			}
+			matches := make([]failureMatch, 0)
+			fq := dbc.DB.Table("prow_job_run_test_outputs outputs").
+				Select(`prow_job_runs.id AS prow_job_run_id, prow_jobs.name AS job_name, prow_jobs.release,
+					tests.id AS test_id, tests.name AS test_name, prow_job_runs.timestamp, outputs.output,
+					word_similarity(?, outputs.output) AS score`, q).
+				Joins("JOIN prow_job_run_tests ON prow_job_run_tests.id = outputs.prow_job_run_test_id").
+				Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+				Joins("JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id").
+				Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+				Where("outputs.deleted_at IS NULL").
+				Where("? <% outputs.output OR outputs.output ILIKE ?", q, like).
+				Where("prow_job_runs.timestamp >= ?", reportEnd.AddDate(0, 0, -searchFailureDays))
+			if release != "" {
+				fq = fq.Where("prow_jobs.release = ?", release)
+			}
+			res := fq.Order("score DESC, prow_job_runs.timestamp DESC").Limit(limit).Scan(&matches)
+			if res.Error != nil {
+				return nil, res.Error
+			}
+			for _, m := range matches {
+				m.Snippet = snippet(m.Output, q)
+				results.Failures = append(results.Failures, m.FailureSearchResult)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// snippet returns the part of the output around the query, or its start when the query was only matched fuzzily.
+func snippet(output, q string) string {
+	start, end := 0, 2*snippetContext
+	if loc := regexp.MustCompile("(?i)" + regexp.QuoteMeta(q)).FindStringIndex(output); loc != nil {
+		start, end = loc[0]-snippetContext, loc[1]+snippetContext
+	}
+
+	prefix, suffix := "...", "..."
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(output) {
+		end, suffix = len(output), ""
+	}
+	return prefix + strings.ToValidUTF8(output[start:end], "") + suffix
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", 150) + "Timed out waiting for etcd" + strings.Repeat("b", 150)
+
+	tests := []struct {
+		name   string
+		output string
+		q      string
+		want   string
+	}{
+		{
+			name:   "short output",
+			output: "error: Timed out waiting for etcd",
+			q:      "timed out",
+			want:   "error: Timed out waiting for etcd",
+		},
+		{
+			name:   "match in long output",
+			output: long,
+			q:      "waiting for ETCD",
+			want:   "..." + strings.Repeat("a", 90) + "Timed out waiting for etcd" + strings.Repeat("b", 100) + "...",
+		},
+		{
+			name:   "fuzzy match",
+			output: long,
+			q:      "waitng for etcd",
+			want:   strings.Repeat("a", 150) + "Timed out waiting for etcd" + strings.Repeat("b", 24) + "...",
+		},
+		{
+			name:   "regexp characters",
+			output: "expected [1] (got 2)",
+			q:      "(got",
+			want:   "expected [1] (got 2)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, snippet(tt.output, tt.q))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"sort"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// maxSigHealthFailingTests caps how many of a sig's failing tests are listed.
+const maxSigHealthFailingTests = 10
+
+// sigTestCounts are a sig's test's results in the last week and the week before, across its suites and variants.
+type sigTestCounts struct {
+	TestID            uint
+	TestName          string
+	CurrentRuns       int
+	CurrentSuccesses  int
+	CurrentFlakes     int
+	CurrentFailures   int
+	PreviousRuns      int
+	PreviousSuccesses int
+	PreviousFlakes    int
+}
+
+// GetSigHealthFromDB summarizes the sig's tests in the release, or returns nil if none of them ran in the last two
+// weeks.
+func GetSigHealthFromDB(dbc *db.DB, sig, release string) (*apitype.SigHealth, error) {
+	counts := make([]sigTestCounts, 0)
+	res := dbc.DB.Raw(`
+		SELECT
+			report.id AS test_id,
+			report.name AS test_name,
+			SUM(report.current_runs) AS current_runs,
+			SUM(report.current_successes) AS current_successes,
+			SUM(report.current_flakes) AS current_flakes,
+			SUM(report.current_failures) AS current_failures,
+			SUM(report.previous_runs) AS previous_runs,
+			SUM(report.previous_successes) AS previous_successes,
+			SUM(report.previous_flakes) AS previous_flakes
+		FROM prow_test_report_7d_matview report
+		WHERE report.release = @release AND report.sig = @sig
+		GROUP BY report.id, report.name
+		HAVING SUM(report.current_runs) + SUM(report.previous_runs) > 0`,
+		map[string]interface{}{"release": release, "sig": sig}).Scan(&counts)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+
+	regressions := make([]models.TestRegression, 0)
+	res = dbc.DB.Where("release = ? AND status = ?", release, models.TestRegressionOpen).
+		Where("test_id IN (SELECT id FROM tests WHERE sig = ?)", sig).
+		Order("severity DESC, first_seen").
+		Find(&regressions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	var openBugs int
+	res = dbc.DB.Raw(`
+		SELECT COUNT(DISTINCT bugs.id)
+		FROM bug_tests
+			JOIN bugs ON bugs.id = bug_tests.bug_id
+			JOIN tests ON tests.id = bug_tests.test_id
+		WHERE tests.sig = ? AND lower(bugs.status) <> 'closed'`, sig).Scan(&openBugs)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	health := buildSigHealth(sig, release, counts, regressions)
+	health.OpenBugs = openBugs
+	return health, nil
+}
+
+func buildSigHealth(sig, release string, counts []sigTestCounts, regressions []models.TestRegression) *apitype.SigHealth {
+	health := &apitype.SigHealth{
+		Sig:             sig,
+		Release:         release,
+		Tests:           len(counts),
+		OpenRegressions: len(regressions),
+		Regressions:     regressions,
+		FailingTests:    make([]apitype.SigHealthTest, 0),
+	}
+	var currentSuccesses, currentFlakes, previousSuccesses, previousFlakes int
+	for _, c := range counts {
+		health.CurrentRuns += c.CurrentRuns
+		health.PreviousRuns += c.PreviousRuns
+		currentSuccesses += c.CurrentSuccesses
+		currentFlakes += c.CurrentFlakes
+		previousSuccesses += c.PreviousSuccesses
+		previousFlakes += c.PreviousFlakes
+
+		if c.CurrentFailures == 0 {
+			continue
+		}
+		test := apitype.SigHealthTest{
+			TestID:                 c.TestID,
+			TestName:               c.TestName,
+			CurrentRuns:            c.CurrentRuns,
+			CurrentFailures:        c.CurrentFailures,
+			CurrentFlakes:          c.CurrentFlakes,
+			CurrentPassPercentage:  percentage(c.CurrentSuccesses, c.CurrentRuns),
+			PreviousRuns:           c.PreviousRuns,
+			PreviousPassPercentage: percentage(c.PreviousSuccesses, c.PreviousRuns),
+		}
+		if c.PreviousRuns > 0 {
+			test.NetImprovement = test.CurrentPassPercentage - test.PreviousPassPercentage
+		}
+		health.FailingTests = append(health.FailingTests, test)
+	}
+
+	health.CurrentPassPercentage = percentage(currentSuccesses, health.CurrentRuns)
+	health.CurrentFlakePercentage = percentage(currentFlakes, health.CurrentRuns)
+	health.PreviousPassPercentage = percentage(previousSuccesses, health.PreviousRuns)
+	health.PreviousFlakePercentage = percentage(previousFlakes, health.PreviousRuns)
+	if health.CurrentRuns > 0 && health.PreviousRuns > 0 {
+		health.NetImprovement = health.CurrentPassPercentage - health.PreviousPassPercentage
+	}
+
+	sort.Slice(health.FailingTests, func(i, j int) bool {
+		a, b := health.FailingTests[i], health.FailingTests[j]
+		if a.CurrentFailures != b.CurrentFailures {
+			return a.CurrentFailures > b.CurrentFailures
+		}
+		return a.TestName < b.TestName
+	})
+	if len(health.FailingTests) > maxSigHealthFailingTests {
+		health.FailingTests = health.FailingTests[:maxSigHealthFailingTests]
+	}
+	return health
+}
+
+// percentage is part as a percentage of total, or 0 when total is.
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestBuildSigHealth(t *testing.T) {
+	counts := []sigTestCounts{
+		{TestID: 1, TestName: "[sig-network] passes", CurrentRuns: 100, CurrentSuccesses: 100, PreviousRuns: 100,
+			PreviousSuccesses: 100},
+		{TestID: 2, TestName: "[sig-network] regressed", CurrentRuns: 50, CurrentSuccesses: 30, CurrentFlakes: 10,
+			CurrentFailures: 10, PreviousRuns: 50, PreviousSuccesses: 50},
+		{TestID: 3, TestName: "[sig-network] new", CurrentRuns: 50, CurrentSuccesses: 40, CurrentFailures: 10},
+	}
+	regressions := []models.TestRegression{{TestID: 2, TestName: "[sig-network] regressed"}}
+
+	health := buildSigHealth("sig-network", "4.16", counts, regressions)
+	assert.Equal(t, 3, health.Tests)
+	assert.Equal(t, 1, health.OpenRegressions)
+	assert.Equal(t, 200, health.CurrentRuns)
+	assert.Equal(t, 85.0, health.CurrentPassPercentage)
+	assert.Equal(t, 5.0, health.CurrentFlakePercentage)
+	assert.Equal(t, 150, health.PreviousRuns)
+	assert.Equal(t, 100.0, health.PreviousPassPercentage)
+	assert.Equal(t, -15.0, health.NetImprovement)
+
+	if assert.Len(t, health.FailingTests, 2) {
+		assert.Equal(t, "[sig-network] new", health.FailingTests[0].TestName, "ties are ordered by name")
+		assert.Zero(t, health.FailingTests[0].NetImprovement, "a test without previous runs hasn't improved")
+		assert.Equal(t, "[sig-network] regressed", health.FailingTests[1].TestName)
+		assert.Equal(t, 60.0, health.FailingTests[1].CurrentPassPercentage)
+		assert.Equal(t, -40.0, health.FailingTests[1].NetImprovement)
+	}
+}
+
+func TestBuildSigHealthLimitsFailingTests(t *testing.T) {
+	counts := make([]sigTestCounts, 0)
+	for i := 1; i <= maxSigHealthFailingTests+5; i++ {
+		counts = append(counts, sigTestCounts{TestID: uint(i), TestName: fmt.Sprintf("test %02d", i), CurrentRuns: 20,
+			CurrentFailures: i})
+	}
+
+	health := buildSigHealth("sig-node", "4.16", counts, nil)
+	assert.Len(t, health.FailingTests, maxSigHealthFailingTests)
+	assert.Equal(t, maxSigHealthFailingTests+5, health.FailingTests[0].CurrentFailures)
+	assert.Zero(t, health.NetImprovement, "there were no previous runs")
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// GetSilentJobsFromDB returns the release's jobs that stopped reporting and haven't run since, the longest silent
+// first.
+func GetSilentJobsFromDB(dbc *db.DB, release string) ([]models.SilentJob, error) {
+	silent := make([]models.SilentJob, 0)
+	res := dbc.DB.Where("release = ? AND status = ?", release, models.SilentJobOpen).
+		Order("last_run, job_name").
+		Find(&silent)
+	return silent, res.Error
+}
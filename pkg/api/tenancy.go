@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// TenantHeader scopes a request to a tenant, like the tenant query parameter.
+const TenantHeader = "X-Sippy-Tenant"
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx scoped to the tenant.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// Tenant returns the tenant ctx is scoped to, or an empty string if it isn't.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// tenantFilter limits a query of a table with a tenant column to the request's tenant, if any.
+func tenantFilter(req *http.Request, dbc *gorm.DB) *gorm.DB {
+	if tenant := Tenant(req.Context()); tenant != "" {
+		return dbc.Where("tenant = ?", tenant)
+	}
+	return dbc
+}
@@ -23,10 +23,8 @@ type CountByDate struct {
 
 func GetTestAnalysisOverallFromDB(dbc *db.DB, filters *filter.Filter, release, testName string, reportEnd time.Time) (map[string][]CountByDate, error) {
 	var rows []CountByDate
-	jq := dbc.DB.Table("prow_test_analysis_by_job_14d_matview").
-		Select(`test_id,
-			test_name,
-			to_date((date at time zone 'UTC')::text, 'YYYY-MM-DD'::text)::text as date,
+	jq := dbc.DB.Table("test_analysis_by_job_days").
+		Select(`to_date((date at time zone 'UTC')::text, 'YYYY-MM-DD'::text)::text as date,
 			'overall' as group,
 			SUM(runs) as runs,
 			SUM(passes) as passes,
@@ -35,11 +33,10 @@ func GetTestAnalysisOverallFromDB(dbc *db.DB, filters *filter.Filter, release, t
 			SUM(passes) * 100.0 / NULLIF(SUM(runs), 0) AS pass_percentage,
 			SUM(flakes) * 100.0 / NULLIF(SUM(runs), 0) AS flake_percentage,
 			SUM(failures) * 100.0 / NULLIF(SUM(runs), 0) AS fail_percentage`).
-		Joins("JOIN prow_jobs on prow_jobs.name = job_name").
-		Where("prow_test_analysis_by_job_14d_matview.release = ?", release).
+		Where("release = ?", release).
 		Where("test_name = ?", testName).
 		Order("date ASC").
-		Group("date, test_id, test_name, prow_test_analysis_by_job_14d_matview.release")
+		Group("date")
 
 	var allowedVariants, blockedVariants []string
 	if filters != nil {
@@ -55,11 +52,11 @@ func GetTestAnalysisOverallFromDB(dbc *db.DB, filters *filter.Filter, release, t
 	}
 
 	for _, bv := range blockedVariants {
-		jq = jq.Where("? != ANY(prow_jobs.variants)", bv)
+		jq = jq.Where("? != ANY(variants)", bv)
 	}
 
 	for _, av := range allowedVariants {
-		jq = jq.Where("? = ANY(prow_jobs.variants)", av)
+		jq = jq.Where("? = ANY(variants)", av)
 	}
 
 	r := jq.Scan(&rows)
@@ -85,11 +82,8 @@ func GetTestAnalysisByJobFromDB(dbc *db.DB, filters *filter.Filter, release, tes
 		results["overall"] = overall
 	}
 
-	jq := dbc.DB.Table("prow_test_analysis_by_job_14d_matview").
-		Select(`test_id,
-			test_name,
-			to_date((date at time zone 'UTC')::text, 'YYYY-MM-DD'::text)::text as date,
-			prow_jobs.release,
+	jq := dbc.DB.Table("test_analysis_by_job_days").
+		Select(`to_date((date at time zone 'UTC')::text, 'YYYY-MM-DD'::text)::text as date,
 			job_name as group,
 			runs,
 			passes,
@@ -99,8 +93,7 @@ func GetTestAnalysisByJobFromDB(dbc *db.DB, filters *filter.Filter, release, tes
 			passes * 100.0 / NULLIF(runs, 0) AS pass_percentage,
 			flakes * 100.0 / NULLIF(runs, 0) AS flake_percentage,
 			failures * 100.0 / NULLIF(runs, 0) AS fail_percentage`).
-		Joins("INNER JOIN prow_jobs on prow_jobs.name = job_name").
-		Where("prow_jobs.release = ?", release).
+		Where("release = ?", release).
 		Where("test_name = ?", testName).
 		Where("date <= ?", reportEnd).
 		Order("date ASC")
@@ -151,7 +144,7 @@ func GetTestAnalysisByVariantFromDB(dbc *db.DB, filters *filter.Filter, release,
 		results["overall"] = overall
 	}
 
-	vq := dbc.DB.Table("prow_test_analysis_by_variant_14d_matview").
+	vq := dbc.DB.Table("test_analysis_by_variant_days").
 		Where("release = ?", release).
 		Where("test_name = ?", testName).
 		Where("date <= ?", reportEnd).
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"sort"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// testOwnershipCounts are a test's runs and failures in the last week, and whether the test mapping gave it a
+// component.
+type testOwnershipCounts struct {
+	TestID    uint
+	TestName  string
+	SuiteName string
+	Runs      int
+	Failures  int
+	Owned     bool
+}
+
+// GetTestOwnershipCoverageFromDB reports how many of the release's tests that ran in the last week the test mapping
+// assigned a component, listing the ones it didn't.
+func GetTestOwnershipCoverageFromDB(dbc *db.DB, release string) (apitype.TestOwnershipCoverage, error) {
+	counts := make([]testOwnershipCounts, 0)
+	res := dbc.DB.Raw(`
+		SELECT
+			report.id AS test_id,
+			report.name AS test_name,
+			COALESCE(report.suite_name, '') AS suite_name,
+			SUM(report.current_runs) AS runs,
+			SUM(report.current_failures) AS failures,
+			BOOL_OR(COALESCE(test_ownerships.component, '') <> '') AS owned
+		FROM prow_test_report_7d_matview report
+			LEFT JOIN test_ownerships ON test_ownerships.test_id = report.id
+				AND test_ownerships.suite_id = report.suite_id
+		WHERE report.release = @release
+		GROUP BY report.id, report.name, report.suite_name
+		HAVING SUM(report.current_runs) > 0`,
+		map[string]interface{}{"release": release}).Scan(&counts)
+	if res.Error != nil {
+		return apitype.TestOwnershipCoverage{}, res.Error
+	}
+
+	return buildTestOwnershipCoverage(release, counts), nil
+}
+
+func buildTestOwnershipCoverage(release string, counts []testOwnershipCounts) apitype.TestOwnershipCoverage {
+	coverage := apitype.TestOwnershipCoverage{
+		Release:      release,
+		UnownedTests: make([]apitype.UnownedTest, 0),
+	}
+	for _, c := range counts {
+		coverage.Tests++
+		coverage.Runs += c.Runs
+		if c.Owned {
+			coverage.OwnedTests++
+			coverage.OwnedRuns += c.Runs
+			continue
+		}
+		unowned := apitype.UnownedTest{
+			TestID:    c.TestID,
+			TestName:  c.TestName,
+			SuiteName: c.SuiteName,
+			Runs:      c.Runs,
+			Failures:  c.Failures,
+		}
+		if c.Runs > 0 {
+			unowned.FailurePercentage = float64(c.Failures) / float64(c.Runs) * 100
+		}
+		coverage.UnownedTests = append(coverage.UnownedTests, unowned)
+	}
+	if coverage.Tests > 0 {
+		coverage.CoveragePercentage = float64(coverage.OwnedTests) / float64(coverage.Tests) * 100
+	}
+	if coverage.Runs > 0 {
+		coverage.RunCoveragePercentage = float64(coverage.OwnedRuns) / float64(coverage.Runs) * 100
+	}
+
+	sort.Slice(coverage.UnownedTests, func(i, j int) bool {
+		a, b := coverage.UnownedTests[i], coverage.UnownedTests[j]
+		if a.Runs != b.Runs {
+			return a.Runs > b.Runs
+		}
+		if a.FailurePercentage != b.FailurePercentage {
+			return a.FailurePercentage > b.FailurePercentage
+		}
+		if a.TestName != b.TestName {
+			return a.TestName < b.TestName
+		}
+		return a.SuiteName < b.SuiteName
+	})
+	return coverage
+}
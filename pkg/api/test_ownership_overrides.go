@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lib/pq"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// NewTestOwnershipOverride validates the request, returning the override it describes.
+func NewTestOwnershipOverride(user string, req apitype.TestOwnershipOverrideRequest) (*models.TestOwnershipOverride,
+	error) {
+	override := &models.TestOwnershipOverride{
+		TestName:      strings.TrimSpace(req.TestName),
+		Suite:         strings.TrimSpace(req.Suite),
+		Component:     strings.TrimSpace(req.Component),
+		JiraComponent: strings.TrimSpace(req.JiraComponent),
+		Reason:        strings.TrimSpace(req.Reason),
+		CreatedBy:     user,
+		UpdatedBy:     user,
+	}
+	for _, capability := range req.Capabilities {
+		if capability = strings.TrimSpace(capability); capability != "" {
+			override.Capabilities = append(override.Capabilities, capability)
+		}
+	}
+	if override.Capabilities == nil {
+		override.Capabilities = pq.StringArray{}
+	}
+
+	if override.TestName == "" {
+		return nil, fmt.Errorf("test_name is required")
+	}
+	if override.JiraComponent == "" {
+		return nil, fmt.Errorf("jira_component is required")
+	}
+	if override.Reason == "" {
+		return nil, fmt.Errorf("reason is required, so others know why the mapping was overridden")
+	}
+	return override, nil
+}
+
+// ListTestOwnershipOverridesFromDB returns every override, by test.
+func ListTestOwnershipOverridesFromDB(dbc *db.DB) ([]models.TestOwnershipOverride, error) {
+	overrides := make([]models.TestOwnershipOverride, 0)
+	res := dbc.DB.Order("test_name, suite").Find(&overrides)
+	return overrides, res.Error
+}
+
+// GetTestOwnershipOverrideFromDB returns the override with the ID, or nil if there's none.
+func GetTestOwnershipOverrideFromDB(dbc *db.DB, id uint) (*models.TestOwnershipOverride, error) {
+	override := &models.TestOwnershipOverride{}
+	res := dbc.DB.Where("id = ?", id).Limit(1).Find(override)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+	return override, nil
+}
+
+// SetTestOwnershipOverride stores the override, replacing any override of the same test and suite, and applies it to
+// the test's ownership. It returns the override it replaced, if any. The test and JIRA component must be known to
+// sippy, to catch typos.
+func SetTestOwnershipOverride(dbc *db.DB, override *models.TestOwnershipOverride) (*models.TestOwnershipOverride,
+	error) {
+	var count int64
+	if res := dbc.DB.Model(&models.Test{}).Where("name = ?", override.TestName).Count(&count); res.Error != nil {
+		return nil, res.Error
+	}
+	if count == 0 {
+		return nil, NewProblem(http.StatusBadRequest, fmt.Sprintf("unknown test %q", override.TestName))
+	}
+	if res := dbc.DB.Model(&models.JiraComponent{}).Where("name = ?", override.JiraComponent).Count(&count); res.Error != nil {
+		return nil, res.Error
+	}
+	if count == 0 {
+		return nil, NewProblem(http.StatusBadRequest, fmt.Sprintf("unknown jira component %q", override.JiraComponent))
+	}
+
+	existing := &models.TestOwnershipOverride{}
+	res := dbc.DB.Where("test_name = ? AND suite = ?", override.TestName, override.Suite).Limit(1).Find(existing)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		if res := dbc.DB.Create(override); res.Error != nil {
+			return nil, res.Error
+		}
+		return nil, dbc.ApplyTestOwnershipOverrides()
+	}
+
+	override.ID, override.CreatedAt, override.CreatedBy = existing.ID, existing.CreatedAt, existing.CreatedBy
+	res = dbc.DB.Model(override).Select("component", "jira_component", "capabilities", "reason", "updated_by",
+		"updated_at").Updates(override)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return existing, dbc.ApplyTestOwnershipOverrides()
+}
+
+// DeleteTestOwnershipOverride deletes the override. The test's ownership goes back to the mapping's the next time the
+// mapping is loaded.
+func DeleteTestOwnershipOverride(dbc *db.DB, override *models.TestOwnershipOverride) error {
+	// deleted outright, so the test can be overridden again
+	return dbc.DB.Unscoped().Delete(override).Error
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestBuildTestOwnershipCoverage(t *testing.T) {
+	coverage := buildTestOwnershipCoverage("4.16", []testOwnershipCounts{
+		{TestID: 1, TestName: "owned", Runs: 300, Failures: 3, Owned: true},
+		{TestID: 2, TestName: "rarely run", Runs: 10, Failures: 5},
+		{TestID: 3, TestName: "passing", Runs: 50},
+		{TestID: 4, TestName: "failing", Runs: 50, Failures: 10},
+	})
+
+	assert.Equal(t, 4, coverage.Tests)
+	assert.Equal(t, 1, coverage.OwnedTests)
+	assert.Equal(t, 25.0, coverage.CoveragePercentage)
+	assert.Equal(t, 410, coverage.Runs)
+	assert.Equal(t, 300, coverage.OwnedRuns)
+	assert.InDelta(t, 73.17, coverage.RunCoveragePercentage, 0.01)
+
+	require.Len(t, coverage.UnownedTests, 3)
+	assert.Equal(t, "failing", coverage.UnownedTests[0].TestName)
+	assert.Equal(t, 20.0, coverage.UnownedTests[0].FailurePercentage)
+	assert.Equal(t, "passing", coverage.UnownedTests[1].TestName)
+	assert.Equal(t, "rarely run", coverage.UnownedTests[2].TestName)
+}
+
+func TestNewTestOwnershipOverride(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     apitype.TestOwnershipOverrideRequest
+		wantErr string
+	}{
+		{
+			name: "valid",
+			req: apitype.TestOwnershipOverrideRequest{TestName: " [sig-cli] oc adm must-gather ", JiraComponent: "oc",
+				Capabilities: []string{"must-gather", " "}, Reason: "the mapping assigns it to Unknown"},
+		},
+		{
+			name:    "missing test",
+			req:     apitype.TestOwnershipOverrideRequest{JiraComponent: "oc", Reason: "misrouted"},
+			wantErr: "test_name is required",
+		},
+		{
+			name:    "missing jira component",
+			req:     apitype.TestOwnershipOverrideRequest{TestName: "test", Reason: "misrouted"},
+			wantErr: "jira_component is required",
+		},
+		{
+			name:    "missing reason",
+			req:     apitype.TestOwnershipOverrideRequest{TestName: "test", JiraComponent: "oc", Reason: "  "},
+			wantErr: "reason is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			override, err := NewTestOwnershipOverride("alice@example.com", tt.req)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "[sig-cli] oc adm must-gather", override.TestName)
+			assert.Equal(t, []string{"must-gather"}, []string(override.Capabilities))
+			assert.Equal(t, "alice@example.com", override.CreatedBy)
+			assert.Equal(t, "alice@example.com", override.UpdatedBy)
+		})
+	}
+}
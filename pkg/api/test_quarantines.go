@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// maxTestQuarantine is the longest a test can be quarantined for at once, so quarantines are revisited.
+const maxTestQuarantine = 90 * 24 * time.Hour
+
+// jiraIssuePath matches the path of a JIRA issue's URL, i.e. /browse/OCPBUGS-1234.
+var jiraIssuePath = regexp.MustCompile(`^/browse/[A-Z][A-Z0-9_]*-[0-9]+$`)
+
+// NewTestQuarantine validates the request, returning the quarantine it describes. A quarantine must link the JIRA
+// issue tracking the test's fix, and expire in the next 90 days.
+func NewTestQuarantine(user string, req apitype.TestQuarantineRequest, now time.Time) (*models.TestQuarantine, error) {
+	quarantine := &models.TestQuarantine{
+		TestName:  strings.TrimSpace(req.TestName),
+		JiraURL:   strings.TrimSpace(req.JiraURL),
+		Reason:    strings.TrimSpace(req.Reason),
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: user,
+		UpdatedBy: user,
+	}
+
+	if quarantine.TestName == "" {
+		return nil, fmt.Errorf("test_name is required")
+	}
+	if quarantine.JiraURL == "" {
+		return nil, fmt.Errorf("jira_url is required, linking the issue tracking the test's fix")
+	}
+	if u, err := url.Parse(quarantine.JiraURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+		!jiraIssuePath.MatchString(u.Path) {
+		return nil, fmt.Errorf("jira_url must link a JIRA issue, i.e. https://issues.redhat.com/browse/OCPBUGS-1234")
+	}
+	if quarantine.Reason == "" {
+		return nil, fmt.Errorf("reason is required, so others know why the test was quarantined")
+	}
+	if !quarantine.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+	if quarantine.ExpiresAt.After(now.Add(maxTestQuarantine)) {
+		return nil, fmt.Errorf("expires_at must be within 90 days, extend the quarantine when it expires")
+	}
+	return quarantine, nil
+}
+
+// ListTestQuarantinesFromDB returns the quarantines, those expiring first first, only those still active at now unless
+// expired quarantines are wanted too.
+func ListTestQuarantinesFromDB(dbc *db.DB, includeExpired bool, now time.Time) ([]models.TestQuarantine, error) {
+	quarantines := make([]models.TestQuarantine, 0)
+	q := dbc.DB.Order("expires_at, test_name")
+	if !includeExpired {
+		q = q.Where("expires_at > ?", now)
+	}
+	res := q.Find(&quarantines)
+	return quarantines, res.Error
+}
+
+// GetTestQuarantineFromDB returns the quarantine with the ID, or nil if there's none.
+func GetTestQuarantineFromDB(dbc *db.DB, id uint) (*models.TestQuarantine, error) {
+	quarantine := &models.TestQuarantine{}
+	res := dbc.DB.Where("id = ?", id).Limit(1).Find(quarantine)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+	return quarantine, nil
+}
+
+// SetTestQuarantine stores the quarantine, replacing any quarantine of the same test, i.e. to extend it. It returns the
+// quarantine it replaced, if any. The test must be known to sippy, to catch typos.
+func SetTestQuarantine(dbc *db.DB, quarantine *models.TestQuarantine) (*models.TestQuarantine, error) {
+	var count int64
+	if res := dbc.DB.Model(&models.Test{}).Where("name = ?", quarantine.TestName).Count(&count); res.Error != nil {
+		return nil, res.Error
+	}
+	if count == 0 {
+		return nil, NewProblem(http.StatusBadRequest, fmt.Sprintf("unknown test %q", quarantine.TestName))
+	}
+
+	existing := &models.TestQuarantine{}
+	res := dbc.DB.Where("test_name = ?", quarantine.TestName).Limit(1).Find(existing)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, dbc.DB.Create(quarantine).Error
+	}
+
+	// the new expiry is notified again
+	quarantine.ID, quarantine.CreatedAt, quarantine.CreatedBy = existing.ID, existing.CreatedAt, existing.CreatedBy
+	res = dbc.DB.Model(quarantine).Select("jira_url", "reason", "expires_at", "expiry_notified_at", "updated_by",
+		"updated_at").Updates(quarantine)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return existing, nil
+}
+
+// DeleteTestQuarantine lifts the quarantine, so the test's failures count against its jobs again.
+func DeleteTestQuarantine(dbc *db.DB, quarantine *models.TestQuarantine) error {
+	// deleted outright, so the test can be quarantined again
+	return dbc.DB.Unscoped().Delete(quarantine).Error
+}
+
+// quarantinedTestsFromDB returns the names of the tests quarantined at the time.
+func quarantinedTestsFromDB(dbc *db.DB, at time.Time) (sets.String, error) {
+	names := make([]string, 0)
+	res := dbc.DB.Model(&models.TestQuarantine{}).Where("expires_at > ?", at).Pluck("test_name", &names)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return sets.NewString(names...), nil
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestNewTestQuarantine(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	valid := func() apitype.TestQuarantineRequest {
+		return apitype.TestQuarantineRequest{TestName: " [sig-network] pods should be reachable ",
+			JiraURL: "https://issues.redhat.com/browse/OCPBUGS-1234", Reason: "flakes on metal",
+			ExpiresAt: now.AddDate(0, 0, 14)}
+	}
+
+	tests := []struct {
+		name    string
+		req     func(req *apitype.TestQuarantineRequest)
+		wantErr string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:    "missing test",
+			req:     func(req *apitype.TestQuarantineRequest) { req.TestName = "" },
+			wantErr: "test_name is required",
+		},
+		{
+			name:    "missing jira link",
+			req:     func(req *apitype.TestQuarantineRequest) { req.JiraURL = " " },
+			wantErr: "jira_url is required",
+		},
+		{
+			name:    "jira link isn't an issue",
+			req:     func(req *apitype.TestQuarantineRequest) { req.JiraURL = "https://issues.redhat.com/projects/OCPBUGS" },
+			wantErr: "jira_url must link a JIRA issue",
+		},
+		{
+			name:    "jira link isn't a URL",
+			req:     func(req *apitype.TestQuarantineRequest) { req.JiraURL = "OCPBUGS-1234" },
+			wantErr: "jira_url must link a JIRA issue",
+		},
+		{
+			name:    "missing reason",
+			req:     func(req *apitype.TestQuarantineRequest) { req.Reason = "" },
+			wantErr: "reason is required",
+		},
+		{
+			name:    "already expired",
+			req:     func(req *apitype.TestQuarantineRequest) { req.ExpiresAt = now },
+			wantErr: "expires_at must be in the future",
+		},
+		{
+			name:    "too long",
+			req:     func(req *apitype.TestQuarantineRequest) { req.ExpiresAt = now.AddDate(0, 0, 91) },
+			wantErr: "expires_at must be within 90 days",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			if tt.req != nil {
+				tt.req(&req)
+			}
+			quarantine, err := NewTestQuarantine("alice@example.com", req, now)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "[sig-network] pods should be reachable", quarantine.TestName)
+			assert.Equal(t, "alice@example.com", quarantine.CreatedBy)
+			assert.True(t, quarantine.Active(now))
+			assert.False(t, quarantine.Active(req.ExpiresAt))
+		})
+	}
+}
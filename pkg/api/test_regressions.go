@@ -0,0 +1,18 @@
+package api
+
+import (
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// GetTestRegressionsFromDB returns the release's test regressions with the status, or all of them when status is
+// empty, the most severe first.
+func GetTestRegressionsFromDB(dbc *db.DB, release, status string) ([]models.TestRegression, error) {
+	regressions := make([]models.TestRegression, 0)
+	q := dbc.DB.Where("release = ?", release)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	res := q.Order("severity DESC, first_seen").Find(&regressions)
+	return regressions, res.Error
+}
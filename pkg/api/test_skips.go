@@ -0,0 +1,136 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// testVariantCounts counts a test's results, or skips, in a variant's jobs in the last week and the week before.
+type testVariantCounts struct {
+	TestID   uint
+	TestName string
+	Variant  string
+	Current  int
+	Previous int
+	Message  string
+}
+
+type testVariantKey struct {
+	testID  uint
+	variant string
+}
+
+// GetTestSkipRatesFromDB returns the tests skipped in a variant of the release at least minIncrease points more often
+// in the week up to the report's end than in the week before, with at least minRuns runs or skips in the last week,
+// biggest increase first.
+func GetTestSkipRatesFromDB(dbc *db.DB, release string, minRuns int, minIncrease float64,
+	reportEnd time.Time) ([]apitype.TestSkipRate, error) {
+	boundary := reportEnd.AddDate(0, 0, -7)
+	start := boundary.AddDate(0, 0, -7)
+
+	skips := make([]testVariantCounts, 0)
+	res := dbc.DB.Raw(`
+		SELECT
+			tests.id AS test_id,
+			tests.name AS test_name,
+			variant,
+			COUNT(*) FILTER (WHERE prow_job_runs.timestamp >= @boundary) AS current,
+			COUNT(*) FILTER (WHERE prow_job_runs.timestamp < @boundary) AS previous,
+			COALESCE(MODE() WITHIN GROUP (ORDER BY skips.message)
+				FILTER (WHERE prow_job_runs.timestamp >= @boundary), '') AS message
+		FROM prow_job_run_skipped_tests skips
+			JOIN tests ON tests.id = skips.test_id
+			JOIN prow_job_runs ON prow_job_runs.id = skips.prow_job_run_id
+			JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			CROSS JOIN UNNEST(prow_jobs.variants) AS variant
+		WHERE prow_jobs.release = @release
+			AND prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+			AND skips.deleted_at IS NULL
+		GROUP BY tests.id, tests.name, variant`,
+		map[string]interface{}{"release": release, "start": start, "boundary": boundary, "end": reportEnd}).
+		Scan(&skips)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(skips) == 0 {
+		return []apitype.TestSkipRate{}, nil
+	}
+
+	testIDs := make([]uint, 0, len(skips))
+	for _, s := range skips {
+		testIDs = append(testIDs, s.TestID)
+	}
+	runs := make([]testVariantCounts, 0)
+	res = dbc.DB.Raw(`
+		SELECT
+			prow_job_run_tests.test_id,
+			variant,
+			COUNT(*) FILTER (WHERE prow_job_runs.timestamp >= @boundary) AS current,
+			COUNT(*) FILTER (WHERE prow_job_runs.timestamp < @boundary) AS previous
+		FROM prow_job_run_tests
+			JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+			JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			CROSS JOIN UNNEST(prow_jobs.variants) AS variant
+		WHERE prow_jobs.release = @release
+			AND prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+			AND prow_job_run_tests.test_id IN @tests
+			AND prow_job_run_tests.deleted_at IS NULL
+		GROUP BY prow_job_run_tests.test_id, variant`,
+		map[string]interface{}{"release": release, "start": start, "boundary": boundary, "end": reportEnd,
+			"tests": testIDs}).
+		Scan(&runs)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return testSkipRates(skips, runs, minRuns, minIncrease), nil
+}
+
+// testSkipRates compares each test's skip percentage in a variant in the last week to the week before, keeping those
+// that rose by at least minIncrease points with at least minRuns runs or skips in the last week.
+func testSkipRates(skips, runs []testVariantCounts, minRuns int, minIncrease float64) []apitype.TestSkipRate {
+	ran := map[testVariantKey]testVariantCounts{}
+	for _, r := range runs {
+		ran[testVariantKey{r.TestID, r.Variant}] = r
+	}
+
+	rates := make([]apitype.TestSkipRate, 0)
+	for _, s := range skips {
+		r := ran[testVariantKey{s.TestID, s.Variant}]
+		rate := apitype.TestSkipRate{
+			TestID:        s.TestID,
+			TestName:      s.TestName,
+			Variant:       s.Variant,
+			Skips:         s.Current,
+			Runs:          s.Current + r.Current,
+			PreviousSkips: s.Previous,
+			PreviousRuns:  s.Previous + r.Previous,
+			Message:       s.Message,
+		}
+		// without runs the week before there's nothing to compare to, i.e. for a new job
+		if rate.Runs < minRuns || rate.PreviousRuns == 0 {
+			continue
+		}
+		rate.SkipPercentage = float64(rate.Skips) / float64(rate.Runs) * 100
+		rate.PreviousSkipPercentage = float64(rate.PreviousSkips) / float64(rate.PreviousRuns) * 100
+		rate.SkipPercentageIncrease = rate.SkipPercentage - rate.PreviousSkipPercentage
+		if rate.SkipPercentageIncrease < minIncrease {
+			continue
+		}
+		rates = append(rates, rate)
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].SkipPercentageIncrease != rates[j].SkipPercentageIncrease {
+			return rates[i].SkipPercentageIncrease > rates[j].SkipPercentageIncrease
+		}
+		if rates[i].TestName != rates[j].TestName {
+			return rates[i].TestName < rates[j].TestName
+		}
+		return rates[i].Variant < rates[j].Variant
+	})
+	return rates
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestSkipRates(t *testing.T) {
+	skips := []testVariantCounts{
+		{TestID: 1, TestName: "newly skipped", Variant: "metal", Current: 8, Previous: 0, Message: "no GPUs"},
+		{TestID: 1, TestName: "newly skipped", Variant: "aws", Current: 1, Previous: 1},
+		{TestID: 2, TestName: "always skipped", Variant: "metal", Current: 10, Previous: 10},
+		{TestID: 3, TestName: "rarely run", Variant: "metal", Current: 3, Previous: 0},
+		{TestID: 4, TestName: "new job", Variant: "metal", Current: 10, Previous: 0},
+	}
+	runs := []testVariantCounts{
+		{TestID: 1, Variant: "metal", Current: 2, Previous: 10},
+		{TestID: 1, Variant: "aws", Current: 9, Previous: 9},
+		{TestID: 3, Variant: "metal", Current: 1, Previous: 4},
+	}
+
+	rates := testSkipRates(skips, runs, 10, 20)
+	if assert.Len(t, rates, 1) {
+		rate := rates[0]
+		assert.Equal(t, "newly skipped", rate.TestName)
+		assert.Equal(t, "metal", rate.Variant)
+		assert.Equal(t, 8, rate.Skips)
+		assert.Equal(t, 10, rate.Runs)
+		assert.InDelta(t, 80, rate.SkipPercentage, 0.01)
+		assert.InDelta(t, 0, rate.PreviousSkipPercentage, 0.01)
+		assert.InDelta(t, 80, rate.SkipPercentageIncrease, 0.01)
+		assert.Equal(t, "no GPUs", rate.Message)
+	}
+
+	assert.Len(t, testSkipRates(skips, runs, 1, 0), 4, "tests without runs the week before aren't compared")
+}
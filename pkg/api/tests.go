@@ -10,9 +10,11 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	v1sippyprocessing "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/compaction"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/query"
 	"github.com/openshift/sippy/pkg/filter"
@@ -23,12 +25,17 @@ const (
 	testReport7dMatView          = "prow_test_report_7d_matview"
 	testReport2dMatView          = "prow_test_report_2d_matview"
 	payloadFailedTests14dMatView = "payload_test_failures_14d_matview"
+
+	// testReportWindowTable is the temporary table a test report between arbitrary windows is computed into.
+	testReportWindowTable = "test_report_window"
+	// maxTestReportWindow is the furthest apart the start and end of a test report between arbitrary windows can be.
+	maxTestReportWindow = 28 * 24 * time.Hour
 )
 
 func PrintTestsDetailsJSONFromDB(w http.ResponseWriter, release string, testSubstrings []string, dbc *db.DB) {
 	responseStr, err := installhtml.TestDetailTestsFromDB(dbc, release, testSubstrings)
 	if err != nil {
-		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 	RespondWithJSON(http.StatusOK, w, responseStr)
@@ -68,6 +75,19 @@ func GetTestDurationsFromDB(dbc *db.DB, release, test string, filters *filter.Fi
 	return query.TestDurations(dbc, release, test, includedVariants, excludedVariants)
 }
 
+// GetTestDurationRegressionsFromDB compares the P95 durations of tests in the release to the base release, defaulting to
+// the previous release.
+func GetTestDurationRegressionsFromDB(dbc *db.DB, release, baseRelease string, minRuns int, minGrowth, minIncrease float64) ([]apitype.TestDurationRegression, error) {
+	if baseRelease == "" {
+		prev, err := previousRelease(release)
+		if err != nil || prev == release {
+			return nil, fmt.Errorf("couldn't determine the release before %s, a base release must be given", release)
+		}
+		baseRelease = prev
+	}
+	return query.TestDurationRegressions(dbc, release, baseRelease, minRuns, minGrowth, minIncrease)
+}
+
 type testsAPIResult []apitype.Test
 
 func (tests testsAPIResult) sort(req *http.Request) testsAPIResult {
@@ -101,20 +121,20 @@ func (tests testsAPIResult) limit(req *http.Request) testsAPIResult {
 	return tests[:limit]
 }
 
-func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
-	var fil *filter.Filter
-
+// testsReportOptions parses the options common to the test reports: whether to collapse variants, include an overall
+// result, and how to filter, returning an error if the filter is invalid.
+func testsReportOptions(req *http.Request) (collapse, includeOverall bool, fil *filter.Filter, err error) {
 	// Collapse means to produce an aggregated test result of all variant (NURP+ - network, upgrade, release, platform)
 	// combos. Uncollapsed results shows you the per-NURP+ result for each test (currently approx. 50,000 rows: filtering
 	// is advised)
 	collapseStr := req.URL.Query().Get("collapse")
-	collapse := true
+	collapse = true
 	if collapseStr == "false" {
 		collapse = false
 	}
 
 	overallStr := req.URL.Query().Get("overall")
-	includeOverall := !collapse
+	includeOverall = !collapse
 	if overallStr != "" {
 		includeOverall, _ = strconv.ParseBool(overallStr)
 	}
@@ -123,22 +143,30 @@ func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Reque
 	if queryFilter != "" {
 		fil = &filter.Filter{}
 		if err := json.Unmarshal([]byte(queryFilter), fil); err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
-			return
+			return false, false, nil, err
 		}
 	}
+	return collapse, includeOverall, fil, nil
+}
+
+func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
+	collapse, includeOverall, fil, err := testsReportOptions(req)
+	if err != nil {
+		RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
+		return
+	}
 
 	// If requesting a two day report, we make the comparison between the last
 	// period (typically 7 days) and the last two days.
 	period := req.URL.Query().Get("period")
 	if period != "" && period != "default" && period != "current" && period != "twoDay" {
-		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Unknown period"})
+		RespondWithError(http.StatusBadRequest, w, "Unknown period")
 		return
 	}
 
 	testsResult, overall, err := BuildTestsResults(dbc, release, period, collapse, includeOverall, fil)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Error building job report:"+err.Error())
 		return
 	}
 
@@ -150,6 +178,91 @@ func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Reque
 	RespondWithJSON(http.StatusOK, w, testsResult)
 }
 
+// PrintTestsDiffJSONFromDB renders the test report comparing arbitrary windows, the results between the start and
+// boundary params to those between boundary and end, which defaults to the report's end.
+func PrintTestsDiffJSONFromDB(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB, reportEnd time.Time) {
+	collapse, includeOverall, fil, err := testsReportOptions(req)
+	if err != nil {
+		RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
+		return
+	}
+
+	var start, boundary, end time.Time
+	for _, param := range []struct {
+		name     string
+		value    *time.Time
+		required bool
+	}{
+		{name: "start", value: &start, required: true},
+		{name: "boundary", value: &boundary, required: true},
+		{name: "end", value: &end},
+	} {
+		value := req.URL.Query().Get(param.name)
+		if value == "" {
+			if param.required {
+				RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("%s is required", param.name))
+				return
+			}
+			continue
+		}
+		if *param.value, err = parseReportTime(value); err != nil {
+			RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("Error decoding %s param: %s", param.name, err.Error()))
+			return
+		}
+	}
+	if end.IsZero() {
+		end = reportEnd
+	}
+	compactedThrough, err := compaction.CompactedThrough(dbc)
+	if err != nil {
+		log.WithError(err).Error("error querying compacted test results")
+		RespondWithError(http.StatusInternalServerError, w, "error querying compacted test results")
+		return
+	}
+	if err := validateTestReportWindows(start, boundary, end, compactedThrough); err != nil {
+		RespondWithError(http.StatusBadRequest, w, err.Error())
+		return
+	}
+
+	testsResult, overall, err := BuildTestsResultsBetween(dbc, release, start, boundary, end, collapse, includeOverall, fil)
+	if err != nil {
+		RespondWithError(http.StatusInternalServerError, w, "Error building test report:"+err.Error())
+		return
+	}
+
+	testsResult = testsResult.sort(req).limit(req)
+	if overall != nil {
+		testsResult = append([]apitype.Test{*overall}, testsResult...)
+	}
+
+	RespondWithJSON(http.StatusOK, w, testsResult)
+}
+
+// parseReportTime parses a time as RFC3339, or a date as midnight UTC.
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// validateTestReportWindows checks the windows are in order, and together no longer than maxTestReportWindow, as
+// they're computed on the fly rather than read from a materialized view. They can't start before compactedThrough, as
+// the passing results of the runs before it have been compacted.
+func validateTestReportWindows(start, boundary, end, compactedThrough time.Time) error {
+	if !start.Before(boundary) || !boundary.Before(end) {
+		return fmt.Errorf("start must be before boundary, which must be before end")
+	}
+	if start.Before(compactedThrough) {
+		return fmt.Errorf("start can't be before %s, the passing test results of older job runs have been compacted",
+			compactedThrough.Format("2006-01-02"))
+	}
+	if end.Sub(start) > maxTestReportWindow {
+		return fmt.Errorf("start and end can be at most %d days apart", int(maxTestReportWindow.Hours()/24))
+	}
+	return nil
+}
+
 func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
 	f := filter.Filter{
 		Items: []filter.FilterItem{
@@ -163,7 +276,7 @@ func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
 
 	results, _, err := BuildTestsResults(dbc, release, "default", true, false, &f)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building test report:" + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Error building test report:"+err.Error())
 		return
 	}
 
@@ -174,6 +287,42 @@ func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
 }
 
 func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOverall bool, fil *filter.Filter) (testsAPIResult, *apitype.Test, error) { //lint:ignore
+	table := testReport7dMatView
+	if period == "twoDay" {
+		table = testReport2dMatView
+	}
+	return buildTestsResults(dbc, table, release, collapse, includeOverall, fil)
+}
+
+// BuildTestsResultsBetween builds the test report comparing the results between start and boundary to those between
+// boundary and end. The report is computed into a temporary table for the duration of a transaction, rather than read
+// from a materialized view.
+func BuildTestsResultsBetween(dbc *db.DB, release string, start, boundary, end time.Time, collapse, includeOverall bool,
+	fil *filter.Filter) (testsAPIResult, *apitype.Test, error) {
+	var testsResult testsAPIResult
+	var overall *apitype.Test
+	reportQuery := db.TestReportQuery(start, boundary, end)
+	err := dbc.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(fmt.Sprintf("CREATE TEMPORARY TABLE %s ON COMMIT DROP AS %s WITH NO DATA",
+			testReportWindowTable, reportQuery))
+		if res.Error != nil {
+			return res.Error
+		}
+		res = tx.Exec(fmt.Sprintf("INSERT INTO %s SELECT * FROM (%s) AS report WHERE release = ?",
+			testReportWindowTable, reportQuery), release)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var err error
+		testsResult, overall, err = buildTestsResults(&db.DB{DB: tx, BatchSize: dbc.BatchSize}, testReportWindowTable,
+			release, collapse, includeOverall, fil)
+		return err
+	})
+	return testsResult, overall, err
+}
+
+func buildTestsResults(dbc *db.DB, table, release string, collapse, includeOverall bool, fil *filter.Filter) (testsAPIResult, *apitype.Test, error) {
 	now := time.Now()
 
 	// Test results are generated by using two subqueries, which need to be filtered separately. Once during
@@ -181,12 +330,8 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 	// assembled our final temporary table.
 	var rawFilter, processedFilter *filter.Filter
 	if fil != nil {
-		rawFilter, processedFilter = fil.Split([]string{"name", "variants"})
-	}
-
-	table := testReport7dMatView
-	if period == "twoDay" {
-		table = testReport2dMatView
+		rawFilter, processedFilter = fil.Split([]string{"name", "variants", "suite_name", "suite_id", "sig",
+			"feature_gates", "capabilities"})
 	}
 
 	rawQuery := dbc.DB.
@@ -196,10 +341,11 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 	// Collapse groups the test results together -- otherwise we return the test results per-variant combo (NURP+)
 	variantSelect := ""
 	if collapse {
-		rawQuery = rawQuery.Select(`name,watchlist,jira_component,jira_component_id,` + query.QueryTestSummer).Group("name,watchlist,jira_component,jira_component_id")
+		rawQuery = rawQuery.Select(`name,watchlist,sig,feature_gates,capabilities,jira_component,jira_component_id,` + query.QueryTestSummer).
+			Group("name,watchlist,sig,feature_gates,capabilities,jira_component,jira_component_id")
 	} else {
 		rawQuery = query.TestsByNURPAndStandardDeviation(dbc, release, table)
-		variantSelect = "suite_name, variants," +
+		variantSelect = "suite_name, suite_id, variants," +
 			"delta_from_working_average, working_average, working_standard_deviation, " +
 			"delta_from_passing_average, passing_average, passing_standard_deviation, " +
 			"delta_from_flake_average, flake_average, flake_standard_deviation, "
@@ -213,7 +359,8 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 	testReports := make([]apitype.Test, 0)
 	// FIXME: Add test id to matview, for now generate with ROW_NUMBER OVER
 	processedResults := dbc.DB.Table("(?) as results", rawQuery).
-		Select(`ROW_NUMBER() OVER() as id, watchlist, name, jira_component, jira_component_id,` + variantSelect + query.QueryTestSummarizer).
+		Select(`ROW_NUMBER() OVER() as id, watchlist, name, sig, feature_gates, capabilities, jira_component, jira_component_id,` +
+			variantSelect + query.QueryTestSummarizer).
 		Where("current_runs > 0 or previous_runs > 0")
 
 	finalResults := dbc.DB.Table("(?) as final_results", processedResults)
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReportTime(t *testing.T) {
+	parsed, err := parseReportTime("2024-03-14")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC), parsed)
+
+	parsed, err = parseReportTime("2024-03-14T06:30:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 14, 6, 30, 0, 0, time.UTC), parsed)
+
+	_, err = parseReportTime("14/03/2024")
+	assert.Error(t, err)
+}
+
+func TestValidateTestReportWindows(t *testing.T) {
+	migration := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name                 string
+		start, boundary, end time.Time
+		compactedThrough     time.Time
+		valid                bool
+	}{
+		{
+			name:     "a week either side",
+			start:    migration.AddDate(0, 0, -7),
+			boundary: migration,
+			end:      migration.AddDate(0, 0, 7),
+			valid:    true,
+		},
+		{
+			name:     "longest windows",
+			start:    migration.AddDate(0, 0, -20),
+			boundary: migration,
+			end:      migration.AddDate(0, 0, 8),
+			valid:    true,
+		},
+		{
+			name:     "windows too long",
+			start:    migration.AddDate(0, 0, -21),
+			boundary: migration,
+			end:      migration.AddDate(0, 0, 8),
+		},
+		{
+			name:     "boundary before start",
+			start:    migration,
+			boundary: migration.AddDate(0, 0, -1),
+			end:      migration.AddDate(0, 0, 7),
+		},
+		{
+			name:             "starts after the compacted runs",
+			start:            migration.AddDate(0, 0, -7),
+			boundary:         migration,
+			end:              migration.AddDate(0, 0, 7),
+			compactedThrough: migration.AddDate(0, 0, -7),
+			valid:            true,
+		},
+		{
+			name:             "starts among the compacted runs",
+			start:            migration.AddDate(0, 0, -7),
+			boundary:         migration,
+			end:              migration.AddDate(0, 0, 7),
+			compactedThrough: migration.AddDate(0, 0, -6),
+		},
+		{
+			name:     "empty current window",
+			start:    migration.AddDate(0, 0, -7),
+			boundary: migration,
+			end:      migration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTestReportWindows(tt.start, tt.boundary, tt.end, tt.compactedThrough)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/history"
+)
+
+const (
+	// TimeseriesDay and TimeseriesWeek are the granularities of a test's timeseries.
+	TimeseriesDay  = "day"
+	TimeseriesWeek = "week"
+
+	// MaxTimeseriesDays is how far back a daily timeseries can start, as the daily analysis only covers the last two
+	// weeks. Weekly timeseries are read from the snapshots, which are kept for good.
+	MaxTimeseriesDays = 14
+)
+
+// timeseriesCounts are a test's results during the day, or week, starting on the date.
+type timeseriesCounts struct {
+	Date      time.Time
+	Runs      int
+	Successes int
+	Flakes    int
+	Failures  int
+}
+
+// GetTestTimeseriesFromDB returns the test's results in the release's jobs of the variant, a point per day or week
+// since the time until the report end, or nil if there's no such test. Daily points are read from the daily analysis
+// of the last two weeks, weekly points from the weekly snapshots, which only record complete weeks.
+func GetTestTimeseriesFromDB(dbc *db.DB, testID uint, release, variant, granularity string, since,
+	reportEnd time.Time) (*apitype.TestTimeseries, error) {
+	test := models.Test{}
+	res := dbc.DB.Select("id", "name").Where("id = ?", testID).Limit(1).Find(&test)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var start time.Time
+	var periods, step int
+	counts := make([]timeseriesCounts, 0)
+	if granularity == TimeseriesWeek {
+		start = history.WeekStart(since)
+		end := history.WeekStart(reportEnd)
+		periods, step = int(end.Sub(start)/(7*24*time.Hour)), 7
+		res = dbc.DB.Model(&models.TestWeeklyResult{}).
+			Select("week AS date, runs, successes, flakes, failures").
+			Where("release = ? AND test_id = ? AND variant = ?", release, testID, variant).
+			Where("week >= ? AND week < ?", start, end).
+			Scan(&counts)
+	} else {
+		start = since.UTC().Truncate(24 * time.Hour)
+		periods, step = int(reportEnd.UTC().Truncate(24*time.Hour).Sub(start)/(24*time.Hour))+1, 1
+		q := dbc.DB.Table("test_analysis_by_job_days")
+		if variant != "All" {
+			q = dbc.DB.Table("test_analysis_by_variant_days").Where("variant = ?", variant)
+		}
+		res = q.Select(`date, SUM(runs) AS runs, SUM(passes) AS successes, SUM(flakes) AS flakes,
+				SUM(failures) AS failures`).
+			Where("release = ? AND test_id = ? AND date >= ?", release, testID, start).
+			Group("date").
+			Scan(&counts)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return &apitype.TestTimeseries{
+		TestID:      test.ID,
+		TestName:    test.Name,
+		Release:     release,
+		Variant:     variant,
+		Granularity: granularity,
+		Since:       start.Format("2006-01-02"),
+		Points:      timeseriesPoints(counts, start, periods, step),
+	}, nil
+}
+
+// timeseriesPoints places the counts on a point for each of the periods of step days from start, leaving periods
+// without counts empty.
+func timeseriesPoints(counts []timeseriesCounts, start time.Time, periods, step int) []apitype.TimeseriesPoint {
+	if periods < 0 {
+		periods = 0
+	}
+	points := make([]apitype.TimeseriesPoint, periods)
+	for i := range points {
+		points[i].Date = start.AddDate(0, 0, i*step).Format("2006-01-02")
+	}
+	for _, c := range counts {
+		date := c.Date.UTC().Truncate(24 * time.Hour)
+		if date.Before(start) {
+			continue
+		}
+		i := int(date.Sub(start)/(24*time.Hour)) / step
+		if i >= periods {
+			continue
+		}
+		points[i].Runs += c.Runs
+		points[i].Successes += c.Successes
+		points[i].Flakes += c.Flakes
+		points[i].Failures += c.Failures
+	}
+	for i := range points {
+		if points[i].Runs > 0 {
+			passPercentage := float64(points[i].Successes+points[i].Flakes) / float64(points[i].Runs) * 100
+			points[i].PassPercentage = &passPercentage
+		}
+	}
+	return points
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeseriesPoints(t *testing.T) {
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	t.Run("daily", func(t *testing.T) {
+		points := timeseriesPoints([]timeseriesCounts{
+			{Date: start, Runs: 10, Successes: 8, Flakes: 1, Failures: 1},
+			{Date: start.AddDate(0, 0, 2), Runs: 4, Successes: 1, Failures: 3},
+			{Date: start.AddDate(0, 0, -1), Runs: 5, Successes: 5},
+			{Date: start.AddDate(0, 0, 3), Runs: 5, Successes: 5},
+		}, start, 3, 1)
+		require.Len(t, points, 3)
+		assert.Equal(t, []string{"2024-03-04", "2024-03-05", "2024-03-06"},
+			[]string{points[0].Date, points[1].Date, points[2].Date})
+
+		require.NotNil(t, points[0].PassPercentage)
+		assert.InDelta(t, 90, *points[0].PassPercentage, 0.001)
+		assert.Equal(t, 0, points[1].Runs)
+		assert.Nil(t, points[1].PassPercentage)
+		require.NotNil(t, points[2].PassPercentage)
+		assert.InDelta(t, 25, *points[2].PassPercentage, 0.001)
+	})
+
+	t.Run("weekly", func(t *testing.T) {
+		points := timeseriesPoints([]timeseriesCounts{
+			{Date: start, Runs: 10, Successes: 10},
+			{Date: start.AddDate(0, 0, 14), Runs: 10, Successes: 5, Failures: 5},
+		}, start, 3, 7)
+		require.Len(t, points, 3)
+		assert.Equal(t, "2024-03-18", points[2].Date)
+		assert.Equal(t, 10, points[0].Runs)
+		assert.Nil(t, points[1].PassPercentage)
+		require.NotNil(t, points[2].PassPercentage)
+		assert.InDelta(t, 50, *points[2].PassPercentage, 0.001)
+	})
+
+	t.Run("no periods", func(t *testing.T) {
+		assert.Empty(t, timeseriesPoints(nil, start, -1, 7))
+	})
+}
@@ -13,7 +13,7 @@ import (
 )
 
 // PrintUpgradeJSONReportFromDB reports on the success/fail of operator upgrades.
-func PrintUpgradeJSONReportFromDB(w http.ResponseWriter, req *http.Request, dbc *db.DB, release string) {
+func PrintUpgradeJSONReportFromDB(w http.ResponseWriter, req *http.Request, dbc *db.DB, release string, invalidVariants []string) {
 
 	exactTestNames := sets.NewString(
 		testidentification.UpgradeTestName,
@@ -30,10 +30,10 @@ func PrintUpgradeJSONReportFromDB(w http.ResponseWriter, req *http.Request, dbc
 	)
 
 	variantColumns, tests, err := VariantTestsReport(dbc, release, v1.CurrentReport,
-		exactTestNames, testPrefixes, testSubStrings, testidentification.DefaultExcludedVariants)
+		exactTestNames, testPrefixes, testSubStrings, testidentification.DefaultExcludedVariants, invalidVariants)
 	if err != nil {
 		log.WithError(err).Error("could not generate upgrade report")
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Could not generate install report: " + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Could not generate install report: "+err.Error())
 		return
 	}
 
@@ -48,7 +48,7 @@ func PrintUpgradeJSONReportFromDB(w http.ResponseWriter, req *http.Request, dbc
 	result, err := json.Marshal(summary)
 	if err != nil {
 		log.WithError(err).Error("could not generate install report")
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Could not generate install report: " + err.Error()})
+		RespondWithError(http.StatusInternalServerError, w, "Could not generate install report: "+err.Error())
 		return
 	}
 
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -16,7 +17,7 @@ var (
 )
 
 // getReportFromCacheOrGenerate attempts to find a cached record otherwise generates a new report.
-func getReportFromCacheOrGenerate[T any](c cache.Cache, cacheOptions cache.RequestOptions, cacheKey interface{}, generateFn func() (T, []error), defaultVal T) (T, []error) {
+func getReportFromCacheOrGenerate[T any](ctx context.Context, c cache.Cache, cacheOptions cache.RequestOptions, cacheKey interface{}, generateFn func(context.Context) (T, []error), defaultVal T) (T, []error) {
 	// If someone is giving us an uncacheable cacheKey, we should panic so it gets detected in testing
 	if isStructWithNoPublicFields(cacheKey) {
 		panic(fmt.Sprintf("you cannot use struct %s with no exported fields as a cache key", reflect.TypeOf(cacheKey)))
@@ -46,7 +47,7 @@ func getReportFromCacheOrGenerate[T any](c cache.Cache, cacheOptions cache.Reque
 			}
 			log.Infof("cache miss for cache key: %s", string(jsonCacheKey))
 		}
-		result, errs := generateFn()
+		result, errs := generateFn(ctx)
 		if len(errs) == 0 {
 			cr, err := json.Marshal(result)
 			if err == nil {
@@ -66,7 +67,7 @@ func getReportFromCacheOrGenerate[T any](c cache.Cache, cacheOptions cache.Reque
 		return result, errs
 	}
 
-	return generateFn()
+	return generateFn(ctx)
 }
 
 // isStructWithNoPublicFields checks if the given interface is a struct with no public fields.
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+// variantCounts are a test's passes, counting flakes, and failures in the jobs with the variants, in the last week and
+// the week before.
+type variantCounts struct {
+	Variants         pq.StringArray `gorm:"type:text[]"`
+	CurrentPasses    int
+	CurrentFailures  int
+	PreviousPasses   int
+	PreviousFailures int
+}
+
+func (c *variantCounts) add(other variantCounts) {
+	c.CurrentPasses += other.CurrentPasses
+	c.CurrentFailures += other.CurrentFailures
+	c.PreviousPasses += other.PreviousPasses
+	c.PreviousFailures += other.PreviousFailures
+}
+
+func (c variantCounts) ran() bool {
+	return c.CurrentPasses+c.CurrentFailures > 0 && c.PreviousPasses+c.PreviousFailures > 0
+}
+
+func (c variantCounts) interaction(variants []string, confidence int) apitype.VariantInteraction {
+	i := apitype.VariantInteraction{
+		Variants:     variants,
+		CurrentRuns:  c.CurrentPasses + c.CurrentFailures,
+		PreviousRuns: c.PreviousPasses + c.PreviousFailures,
+	}
+	if i.CurrentRuns > 0 {
+		i.CurrentPassPercentage = float64(c.CurrentPasses) / float64(i.CurrentRuns) * 100
+	}
+	if i.PreviousRuns > 0 {
+		i.PreviousPassPercentage = float64(c.PreviousPasses) / float64(i.PreviousRuns) * 100
+	}
+	i.PValue, i.Regressed = SignificantlyWorse(c.PreviousFailures, c.PreviousPasses, c.CurrentFailures, c.CurrentPasses, confidence)
+	return i
+}
+
+// GetVariantInteractionsFromDB compares the test's last week to the week before in each variant, from the by-variant
+// matview, and in each pair of variants, from the test report matview as it keeps every job's variants together.
+func GetVariantInteractionsFromDB(dbc *db.DB, release, testName string, reportEnd time.Time, confidence int) (apitype.VariantInteractionAnalysis, error) {
+	byVariant := make([]variantCounts, 0)
+	res := dbc.DB.Raw(`
+		SELECT
+			ARRAY[variant] AS variants,
+			COALESCE(SUM(passes + flakes) FILTER (WHERE date > @boundary), 0) AS current_passes,
+			COALESCE(SUM(failures) FILTER (WHERE date > @boundary), 0) AS current_failures,
+			COALESCE(SUM(passes + flakes) FILTER (WHERE date > @start AND date <= @boundary), 0) AS previous_passes,
+			COALESCE(SUM(failures) FILTER (WHERE date > @start AND date <= @boundary), 0) AS previous_failures
+		FROM prow_test_analysis_by_variant_14d_matview
+		WHERE release = @release AND test_name = @test AND date <= @end
+		GROUP BY variant`,
+		map[string]interface{}{
+			"release":  release,
+			"test":     testName,
+			"start":    reportEnd.AddDate(0, 0, -14),
+			"boundary": reportEnd.AddDate(0, 0, -7),
+			"end":      reportEnd,
+		}).Scan(&byVariant)
+	if res.Error != nil {
+		return apitype.VariantInteractionAnalysis{}, res.Error
+	}
+
+	byJobVariants := make([]variantCounts, 0)
+	res = dbc.DB.Table("prow_test_report_7d_matview").
+		Where("release = ? AND name = ?", release, testName).
+		Select(`variants,
+			SUM(current_successes + current_flakes) AS current_passes,
+			SUM(current_failures) AS current_failures,
+			SUM(previous_successes + previous_flakes) AS previous_passes,
+			SUM(previous_failures) AS previous_failures`).
+		Group("variants").
+		Scan(&byJobVariants)
+	if res.Error != nil {
+		return apitype.VariantInteractionAnalysis{}, res.Error
+	}
+
+	return buildVariantInteractionAnalysis(release, testName, byVariant, byJobVariants, confidence), nil
+}
+
+func buildVariantInteractionAnalysis(release, testName string, byVariant, byJobVariants []variantCounts, confidence int) apitype.VariantInteractionAnalysis {
+	analysis := apitype.VariantInteractionAnalysis{
+		Release:  release,
+		TestName: testName,
+		Variants: make([]apitype.VariantInteraction, 0, len(byVariant)),
+		Drivers:  make([]apitype.VariantInteraction, 0),
+	}
+	for _, c := range byVariant {
+		analysis.Variants = append(analysis.Variants, c.interaction(c.Variants, confidence))
+	}
+	sort.Slice(analysis.Variants, func(i, j int) bool {
+		a, b := analysis.Variants[i], analysis.Variants[j]
+		if a.Regressed != b.Regressed {
+			return a.Regressed
+		}
+		if a.CurrentPassPercentage != b.CurrentPassPercentage {
+			return a.CurrentPassPercentage < b.CurrentPassPercentage
+		}
+		return a.Variants[0] < b.Variants[0]
+	})
+
+	seen := map[string]bool{}
+	for _, c := range byJobVariants {
+		for _, v := range c.Variants {
+			seen[v] = true
+		}
+	}
+	variants := sortedKeys(seen)
+
+	// a pair drives the regression when the test regressed in jobs with both variants, but not in the jobs with
+	// either one alone, which must have run for the comparison to mean anything
+	for i, first := range variants {
+		for _, second := range variants[i+1:] {
+			var both, onlyFirst, onlySecond variantCounts
+			for _, c := range byJobVariants {
+				hasFirst, hasSecond := util.StrSliceContains(c.Variants, first), util.StrSliceContains(c.Variants, second)
+				switch {
+				case hasFirst && hasSecond:
+					both.add(c)
+				case hasFirst:
+					onlyFirst.add(c)
+				case hasSecond:
+					onlySecond.add(c)
+				}
+			}
+			if !onlyFirst.ran() || !onlySecond.ran() {
+				continue
+			}
+			pair := both.interaction([]string{first, second}, confidence)
+			if !pair.Regressed ||
+				onlyFirst.interaction(nil, confidence).Regressed || onlySecond.interaction(nil, confidence).Regressed {
+				continue
+			}
+			analysis.Drivers = append(analysis.Drivers, pair)
+		}
+	}
+	sort.SliceStable(analysis.Drivers, func(i, j int) bool {
+		return analysis.Drivers[i].PValue < analysis.Drivers[j].PValue
+	})
+	return analysis
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildVariantInteractionAnalysis(t *testing.T) {
+	counts := func(variants []string, currentPasses, currentFailures int) variantCounts {
+		return variantCounts{
+			Variants:        variants,
+			CurrentPasses:   currentPasses,
+			CurrentFailures: currentFailures,
+			PreviousPasses:  100,
+		}
+	}
+	byVariant := []variantCounts{
+		counts([]string{"aws"}, 100, 0),
+		counts([]string{"metal"}, 70, 30),
+		counts([]string{"ipv6"}, 60, 40),
+	}
+	// only metal jobs with ipv6 regressed
+	byJobVariants := []variantCounts{
+		counts([]string{"aws", "ipv4"}, 100, 0),
+		counts([]string{"aws", "ipv6"}, 100, 0),
+		counts([]string{"metal", "ipv4"}, 100, 0),
+		counts([]string{"metal", "ipv6"}, 60, 40),
+	}
+
+	analysis := buildVariantInteractionAnalysis("4.16", "test", byVariant, byJobVariants, 95)
+	require.Len(t, analysis.Variants, 3)
+	assert.Equal(t, []string{"ipv6"}, analysis.Variants[0].Variants)
+	assert.True(t, analysis.Variants[0].Regressed)
+	assert.Equal(t, []string{"aws"}, analysis.Variants[2].Variants)
+	assert.False(t, analysis.Variants[2].Regressed)
+
+	require.Len(t, analysis.Drivers, 1)
+	assert.Equal(t, []string{"ipv6", "metal"}, analysis.Drivers[0].Variants)
+	assert.Equal(t, 60.0, analysis.Drivers[0].CurrentPassPercentage)
+	assert.Equal(t, 100.0, analysis.Drivers[0].PreviousPassPercentage)
+}
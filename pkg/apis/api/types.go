@@ -2,9 +2,11 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -23,6 +25,19 @@ const (
 	ColumnTypeTimestamp
 )
 
+// VariantDimensionsField holds a job's variants keyed by dimension. Filter on a single dimension with a field like
+// "variant_dimensions.Platform".
+const VariantDimensionsField = "variant_dimensions"
+
+// VariantDimensionKey returns the dimension named by a field like "variant_dimensions.Platform".
+func VariantDimensionKey(field string) (string, bool) {
+	prefix := VariantDimensionsField + "."
+	if !strings.HasPrefix(field, prefix) || len(field) == len(prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(field, prefix), true
+}
+
 type Sort string
 
 const (
@@ -210,6 +225,25 @@ type Variant struct {
 	NetImprovement float64 `json:"net_improvement"`
 }
 
+// VariantsMetadata describes the known variants, so UIs can build their filters without hard-coding them.
+type VariantsMetadata struct {
+	Variants []VariantMetadata `json:"variants"`
+
+	// Dimensions maps each dimension to its values, i.e. Platform: [aws, azure, gcp].
+	Dimensions map[string][]string `json:"dimensions"`
+}
+
+type VariantMetadata struct {
+	Name           string `json:"name"`
+	Dimension      string `json:"dimension,omitempty"`
+	DimensionValue string `json:"dimension_value,omitempty"`
+	Description    string `json:"description,omitempty"`
+	Platform       bool   `json:"platform"`
+
+	// JobCount is the number of jobs in the variant in the database.
+	JobCount int `json:"job_count"`
+}
+
 // Job contains the full accounting of a job's history, with a synthetic ID. The format of
 // this struct is suitable for use in a data table.
 // TODO: with move to database, IDs will no longer be synthetic, although they will change in the event
@@ -223,6 +257,8 @@ type Job struct {
 	Variants  pq.StringArray `json:"variants" gorm:"type:text[]"`
 	LastPass  *time.Time     `json:"last_pass,omitempty"`
 
+	VariantDimensions models.VariantDimensions `json:"variant_dimensions" gorm:"type:jsonb"`
+
 	AverageRetestsToMerge          float64 `json:"average_retests_to_merge"`
 	CurrentPassPercentage          float64 `json:"current_pass_percentage"`
 	CurrentProjectedPassPercentage float64 `json:"current_projected_pass_percentage"`
@@ -239,11 +275,18 @@ type Job struct {
 	PreviousInfraFails              int     `json:"previous_infra_fails,omitempty"`
 	NetImprovement                  float64 `json:"net_improvement"`
 
-	TestGridURL string `json:"test_grid_url"`
-	OpenBugs    int    `json:"open_bugs"`
+	TestGridURL    string `json:"test_grid_url"`
+	OpenBugs       int    `json:"open_bugs"`
+	ClusterProfile string `json:"cluster_profile,omitempty"`
+	// Tier is the job's tier, blocking, informing or experimental, empty when it has none.
+	Tier string `json:"tier,omitempty"`
 }
 
 func (job Job) GetFieldType(param string) ColumnType {
+	if _, ok := VariantDimensionKey(param); ok {
+		return ColumnTypeString
+	}
+
 	switch param {
 	//nolint:goconst
 	case "name":
@@ -265,12 +308,20 @@ func (job Job) GetFieldType(param string) ColumnType {
 	//nolint:goconst
 	case "test_grid_url":
 		return ColumnTypeString
+	case "cluster_profile":
+		return ColumnTypeString
+	case "tier":
+		return ColumnTypeString
 	default:
 		return ColumnTypeNumerical
 	}
 }
 
 func (job Job) GetStringValue(param string) (string, error) {
+	if dimension, ok := VariantDimensionKey(param); ok {
+		return job.VariantDimensions[dimension], nil
+	}
+
 	switch param {
 	case "name":
 		return job.Name, nil
@@ -284,6 +335,10 @@ func (job Job) GetStringValue(param string) (string, error) {
 	//nolint:goconst
 	case "repo":
 		return job.Repo, nil
+	case "cluster_profile":
+		return job.ClusterProfile, nil
+	case "tier":
+		return job.Tier, nil
 	default:
 		return "", fmt.Errorf("unknown string field %s", param)
 	}
@@ -329,30 +384,33 @@ func (job Job) GetArrayValue(param string) ([]string, error) {
 
 // JobRun contains a full accounting of a job run's history, with a synthetic ID.
 type JobRun struct {
-	ID                    int                 `json:"id"`
-	BriefName             string              `json:"brief_name"`
-	Variants              pq.StringArray      `json:"variants" gorm:"type:text[]"`
-	Tags                  pq.StringArray      `json:"tags" gorm:"type:text[]"`
-	TestGridURL           string              `json:"test_grid_url"`
-	ProwID                uint                `json:"prow_id"`
-	Job                   string              `json:"job"`
-	Cluster               string              `json:"cluster"`
-	URL                   string              `json:"url"`
-	TestFlakes            int                 `json:"test_flakes"`
-	FlakedTestNames       pq.StringArray      `json:"flaked_test_names" gorm:"type:text[]"`
-	TestFailures          int                 `json:"test_failures"`
-	FailedTestNames       pq.StringArray      `json:"failed_test_names" gorm:"type:text[]"`
-	Failed                bool                `json:"failed"`
-	InfrastructureFailure bool                `json:"infrastructure_failure"`
-	KnownFailure          bool                `json:"known_failure"`
-	Succeeded             bool                `json:"succeeded"`
-	Timestamp             int                 `json:"timestamp"`
-	OverallResult         v1.JobOverallResult `json:"overall_result"`
-	PullRequestOrg        string              `json:"pull_request_org"`
-	PullRequestRepo       string              `json:"pull_request_repo"`
-	PullRequestLink       string              `json:"pull_request_link"`
-	PullRequestSHA        string              `json:"pull_request_sha"`
-	PullRequestAuthor     string              `json:"pull_request_author"`
+	ID                    int                  `json:"id"`
+	BriefName             string               `json:"brief_name"`
+	Variants              pq.StringArray       `json:"variants" gorm:"type:text[]"`
+	Tags                  pq.StringArray       `json:"tags" gorm:"type:text[]"`
+	TestGridURL           string               `json:"test_grid_url"`
+	ProwID                uint                 `json:"prow_id"`
+	Job                   string               `json:"job"`
+	Cluster               string               `json:"cluster"`
+	CloudRegion           string               `json:"cloud_region"`
+	ClusterProfile        string               `json:"cluster_profile"`
+	URL                   string               `json:"url"`
+	Artifacts             models.ArtifactLinks `json:"artifacts"`
+	TestFlakes            int                  `json:"test_flakes"`
+	FlakedTestNames       pq.StringArray       `json:"flaked_test_names" gorm:"type:text[]"`
+	TestFailures          int                  `json:"test_failures"`
+	FailedTestNames       pq.StringArray       `json:"failed_test_names" gorm:"type:text[]"`
+	Failed                bool                 `json:"failed"`
+	InfrastructureFailure bool                 `json:"infrastructure_failure"`
+	KnownFailure          bool                 `json:"known_failure"`
+	Succeeded             bool                 `json:"succeeded"`
+	Timestamp             int                  `json:"timestamp"`
+	OverallResult         v1.JobOverallResult  `json:"overall_result"`
+	PullRequestOrg        string               `json:"pull_request_org"`
+	PullRequestRepo       string               `json:"pull_request_repo"`
+	PullRequestLink       string               `json:"pull_request_link"`
+	PullRequestSHA        string               `json:"pull_request_sha"`
+	PullRequestAuthor     string               `json:"pull_request_author"`
 }
 
 func (run JobRun) GetFieldType(param string) ColumnType {
@@ -361,6 +419,10 @@ func (run JobRun) GetFieldType(param string) ColumnType {
 		return ColumnTypeString
 	case "cluster":
 		return ColumnTypeString
+	case "cloud_region":
+		return ColumnTypeString
+	case "cluster_profile":
+		return ColumnTypeString
 	case "tags":
 		return ColumnTypeArray
 	case "job":
@@ -398,6 +460,10 @@ func (run JobRun) GetStringValue(param string) (string, error) {
 		return run.Job, nil
 	case "cluster":
 		return run.Cluster, nil
+	case "cloud_region":
+		return run.CloudRegion, nil
+	case "cluster_profile":
+		return run.ClusterProfile, nil
 	case "overall_result":
 		return string(run.OverallResult), nil
 	case "test_grid_url":
@@ -451,29 +517,39 @@ type Test struct {
 	ID        int            `json:"id,omitempty"`
 	Name      string         `json:"name"`
 	SuiteName string         `json:"suite_name"`
+	SuiteID   int            `json:"suite_id,omitempty"`
 	Variant   string         `json:"variant,omitempty"`
 	Variants  pq.StringArray `json:"variants" gorm:"type:text[]"`
 
 	JiraComponent   string `json:"jira_component"`
 	JiraComponentID int    `json:"jira_component_id"`
 
+	Sig          string         `json:"sig,omitempty"`
+	FeatureGates pq.StringArray `json:"feature_gates,omitempty" gorm:"type:text[]"`
+	Capabilities pq.StringArray `json:"capabilities,omitempty" gorm:"type:text[]"`
+
 	CurrentSuccesses         int     `json:"current_successes"`
 	CurrentFailures          int     `json:"current_failures"`
 	CurrentFlakes            int     `json:"current_flakes"`
 	CurrentPassPercentage    float64 `json:"current_pass_percentage"`
 	CurrentFailurePercentage float64 `json:"current_failure_percentage"`
 	CurrentFlakePercentage   float64 `json:"current_flake_percentage"`
-	CurrentWorkingPercentage float64 `json:"current_working_percentage"`
-	CurrentRuns              int     `json:"current_runs"`
-
-	PreviousSuccesses         int     `json:"previous_successes"`
-	PreviousFailures          int     `json:"previous_failures"`
-	PreviousFlakes            int     `json:"previous_flakes"`
-	PreviousPassPercentage    float64 `json:"previous_pass_percentage"`
-	PreviousFailurePercentage float64 `json:"previous_failure_percentage"`
-	PreviousFlakePercentage   float64 `json:"previous_flake_percentage"`
-	PreviousWorkingPercentage float64 `json:"previous_working_percentage"`
-	PreviousRuns              int     `json:"previous_runs"`
+	// CurrentRetryFlakes are the flakes that failed and then passed on a retry within the run.
+	CurrentRetryFlakes          int     `json:"current_retry_flakes"`
+	CurrentRetryFlakePercentage float64 `json:"current_retry_flake_percentage"`
+	CurrentWorkingPercentage    float64 `json:"current_working_percentage"`
+	CurrentRuns                 int     `json:"current_runs"`
+
+	PreviousSuccesses            int     `json:"previous_successes"`
+	PreviousFailures             int     `json:"previous_failures"`
+	PreviousFlakes               int     `json:"previous_flakes"`
+	PreviousPassPercentage       float64 `json:"previous_pass_percentage"`
+	PreviousFailurePercentage    float64 `json:"previous_failure_percentage"`
+	PreviousFlakePercentage      float64 `json:"previous_flake_percentage"`
+	PreviousRetryFlakes          int     `json:"previous_retry_flakes"`
+	PreviousRetryFlakePercentage float64 `json:"previous_retry_flake_percentage"`
+	PreviousWorkingPercentage    float64 `json:"previous_working_percentage"`
+	PreviousRuns                 int     `json:"previous_runs"`
 
 	NetFailureImprovement float64 `json:"net_failure_improvement"`
 	NetFlakeImprovement   float64 `json:"net_flake_improvement"`
@@ -499,6 +575,14 @@ func (test Test) GetFieldType(param string) ColumnType {
 	switch param {
 	case "name":
 		return ColumnTypeString
+	case "suite_name":
+		return ColumnTypeString
+	case "sig":
+		return ColumnTypeString
+	case "feature_gates":
+		return ColumnTypeArray
+	case "capabilities":
+		return ColumnTypeArray
 	case "tags":
 		return ColumnTypeArray
 	case "variant":
@@ -516,6 +600,10 @@ func (test Test) GetStringValue(param string) (string, error) {
 	switch param {
 	case "name":
 		return test.Name, nil
+	case "suite_name":
+		return test.SuiteName, nil
+	case "sig":
+		return test.Sig, nil
 	case "variant":
 		return test.Variant, nil
 	case "watchlist":
@@ -530,6 +618,8 @@ func (test Test) GetNumericalValue(param string) (float64, error) {
 	switch param {
 	case "id":
 		return float64(test.ID), nil
+	case "suite_id":
+		return float64(test.SuiteID), nil
 	case "current_successes":
 		return float64(test.CurrentSuccesses), nil
 	case "current_failures":
@@ -540,6 +630,10 @@ func (test Test) GetNumericalValue(param string) (float64, error) {
 		return test.CurrentPassPercentage, nil
 	case "current_flake_percentage":
 		return test.CurrentFlakePercentage, nil
+	case "current_retry_flakes":
+		return float64(test.CurrentRetryFlakes), nil
+	case "current_retry_flake_percentage":
+		return test.CurrentRetryFlakePercentage, nil
 	case "current_failure_percentage":
 		return test.CurrentFailurePercentage, nil
 	case "current_working_percentage":
@@ -556,6 +650,10 @@ func (test Test) GetNumericalValue(param string) (float64, error) {
 		return test.PreviousPassPercentage, nil
 	case "previous_flake_percentage":
 		return test.PreviousFlakePercentage, nil
+	case "previous_retry_flakes":
+		return float64(test.PreviousRetryFlakes), nil
+	case "previous_retry_flake_percentage":
+		return test.PreviousRetryFlakePercentage, nil
 	case "previous_failure_percentage":
 		return test.PreviousFailurePercentage, nil
 	case "previous_working_percentage":
@@ -601,6 +699,10 @@ func (test Test) GetArrayValue(param string) ([]string, error) {
 		return test.Tags, nil
 	case "variants":
 		return test.Variants, nil
+	case "feature_gates":
+		return test.FeatureGates, nil
+	case "capabilities":
+		return test.Capabilities, nil
 	default:
 		return nil, fmt.Errorf("unknown array value field %s", param)
 	}
@@ -711,15 +813,125 @@ type JobAnalysisResult struct {
 	ByPeriod map[string]AnalysisResult `json:"by_period"`
 }
 
+// TestDurationPercentiles summarize the durations, in seconds, of a test's passing runs in a release over the last two
+// weeks.
+type TestDurationPercentiles struct {
+	Release  string  `json:"release"`
+	TestID   uint    `json:"test_id"`
+	TestName string  `json:"test_name"`
+	Runs     int     `json:"runs"`
+	P50      float64 `json:"p50"`
+	P95      float64 `json:"p95"`
+}
+
+// TestDurationRegression is a test whose P95 duration grew from the base release.
+type TestDurationRegression struct {
+	TestID      uint    `json:"test_id"`
+	TestName    string  `json:"test_name"`
+	Runs        int     `json:"runs"`
+	P50         float64 `json:"p50"`
+	P95         float64 `json:"p95"`
+	BaseRuns    int     `json:"base_runs"`
+	BaseP50     float64 `json:"base_p50"`
+	BaseP95     float64 `json:"base_p95"`
+	P95Increase float64 `json:"p95_increase"`
+	// P95Growth is the percentage the P95 grew by, i.e. 50 when it took half as long again.
+	P95Growth float64 `json:"p95_growth"`
+}
+
+// TestSkipRate is a test skipped in a variant's jobs at a higher rate in the last week than the week before, which
+// hides the coverage it lost. Runs count the times the test ran or was skipped.
+type TestSkipRate struct {
+	TestID   uint   `json:"test_id"`
+	TestName string `json:"test_name"`
+	Variant  string `json:"variant"`
+
+	Skips          int     `json:"skips"`
+	Runs           int     `json:"runs"`
+	SkipPercentage float64 `json:"skip_percentage"`
+
+	PreviousSkips          int     `json:"previous_skips"`
+	PreviousRuns           int     `json:"previous_runs"`
+	PreviousSkipPercentage float64 `json:"previous_skip_percentage"`
+
+	// SkipPercentageIncrease is how many points the skip percentage rose by.
+	SkipPercentageIncrease float64 `json:"skip_percentage_increase"`
+	// Message is the most common reason the test gave for skipping in the last week.
+	Message string `json:"message"`
+}
+
+// NewTest is a test that never ran before it first appeared in a release's jobs, with its results since.
+type NewTest struct {
+	TestID         uint      `json:"test_id"`
+	TestName       string    `json:"test_name"`
+	FirstSeen      time.Time `json:"first_seen"`
+	Jobs           int       `json:"jobs"`
+	Runs           int       `json:"runs"`
+	Successes      int       `json:"successes"`
+	Flakes         int       `json:"flakes"`
+	Failures       int       `json:"failures"`
+	PassPercentage float64   `json:"pass_percentage"`
+}
+
 type TestOutput struct {
 	URL    string `json:"url"`
 	Output string `json:"output"`
 }
 
+// JobRunTest is a test that failed or flaked in a job run, with its output.
+type JobRunTest struct {
+	ID        uint    `json:"id"`
+	TestName  string  `json:"test_name"`
+	SuiteName string  `json:"suite_name"`
+	Status    string  `json:"status"`
+	Duration  float64 `json:"duration"`
+	// Output is the start of the failure output, kept as long as the result.
+	Output string `json:"output"`
+	// HasLog is set when the test's full failure output and system-out are still kept. They're only included when a
+	// single test is requested.
+	HasLog        bool   `json:"has_log"`
+	FailureOutput string `json:"failure_output,omitempty"`
+	SystemOut     string `json:"system_out,omitempty"`
+	// LogTruncated is set when the kept outputs were cut to their maximum length.
+	LogTruncated bool `json:"log_truncated,omitempty"`
+}
+
 type Releases struct {
 	Releases    []string             `json:"releases"`
 	GADates     map[string]time.Time `json:"ga_dates"`
 	LastUpdated time.Time            `json:"last_updated"`
+	// Milestones are the milestones of the releases that have any, in order, including their GA dates.
+	Milestones map[string][]ReleaseMilestone `json:"milestones,omitempty"`
+}
+
+// ReleaseMilestone is a milestone of a release, feature_freeze, code_freeze or ga, and whether the report's end is past
+// it.
+type ReleaseMilestone struct {
+	Name    string    `json:"name"`
+	Date    time.Time `json:"date"`
+	Reached bool      `json:"reached"`
+}
+
+// ReleaseMilestoneReport annotates a release's report window with its milestones and phase, the last milestone
+// reached or development before any, and aggregates the release's results since each milestone reached.
+type ReleaseMilestoneReport struct {
+	Release    string               `json:"release"`
+	Phase      string               `json:"phase"`
+	Milestones []ReleaseMilestone   `json:"milestones"`
+	Since      []MilestoneAggregate `json:"since"`
+}
+
+// MilestoneAggregate counts a release's job runs, payloads and regressions since a milestone.
+type MilestoneAggregate struct {
+	Milestone              string    `json:"milestone"`
+	Date                   time.Time `json:"date"`
+	JobRuns                int       `json:"job_runs"`
+	SuccessfulRuns         int       `json:"successful_runs"`
+	InfrastructureFailures int       `json:"infrastructure_failures"`
+	PassPercentage         float64   `json:"pass_percentage"`
+	AcceptedPayloads       int       `json:"accepted_payloads"`
+	RejectedPayloads       int       `json:"rejected_payloads"`
+	RegressionsOpened      int       `json:"regressions_opened"`
 }
 
 type Indicator struct {
@@ -1028,3 +1240,716 @@ type DisruptionReportRow struct {
 	Architecture             string  `json:"architecture"`
 	Relevance                int     `json:"relevance"`
 }
+
+// ReleaseReadinessRequestOptions selects the sample of a release to compare to a historical basis, which may be
+// another release.
+type ReleaseReadinessRequestOptions struct {
+	Release      string
+	SampleStart  time.Time
+	SampleEnd    time.Time
+	BasisRelease string
+	BasisStart   time.Time
+	BasisEnd     time.Time
+	// Confidence is the percent confidence a drop in pass rate must be significant at.
+	Confidence int
+}
+
+type ReleaseReadinessStatus string
+
+const (
+	ReleaseReadinessGreen  ReleaseReadinessStatus = "green"
+	ReleaseReadinessYellow ReleaseReadinessStatus = "yellow"
+	ReleaseReadinessRed    ReleaseReadinessStatus = "red"
+	// ReleaseReadinessMissing cells have no runs in the sample, or none in the basis to compare them to.
+	ReleaseReadinessMissing ReleaseReadinessStatus = "missing"
+)
+
+// ReleaseReadinessReport is a grid of components and variants, comparing each cell's tests in the sample to the basis.
+type ReleaseReadinessReport struct {
+	Release      string                `json:"release"`
+	SampleStart  time.Time             `json:"sample_start"`
+	SampleEnd    time.Time             `json:"sample_end"`
+	BasisRelease string                `json:"basis_release"`
+	BasisStart   time.Time             `json:"basis_start"`
+	BasisEnd     time.Time             `json:"basis_end"`
+	Variants     []string              `json:"variants"`
+	Rows         []ReleaseReadinessRow `json:"rows"`
+}
+
+// ReleaseReadinessRow has a cell for each of the report's variants, in the same order.
+type ReleaseReadinessRow struct {
+	Component string                 `json:"component"`
+	Cells     []ReleaseReadinessCell `json:"cells"`
+}
+
+type ReleaseReadinessCell struct {
+	Variant        string                          `json:"variant"`
+	Status         ReleaseReadinessStatus          `json:"status"`
+	RegressedTests []ReleaseReadinessRegressedTest `json:"regressed_tests,omitempty"`
+}
+
+type ReleaseReadinessRegressedTest struct {
+	TestID               uint    `json:"test_id"`
+	TestName             string  `json:"test_name"`
+	BasisPassPercentage  float64 `json:"basis_pass_percentage"`
+	BasisRuns            int     `json:"basis_runs"`
+	SamplePassPercentage float64 `json:"sample_pass_percentage"`
+	SampleRuns           int     `json:"sample_runs"`
+	PValue               float64 `json:"p_value"`
+}
+
+const (
+	ReleaseForecastOnTrack          = "on-track"
+	ReleaseForecastAtRisk           = "at-risk"
+	ReleaseForecastInsufficientData = "insufficient-data"
+)
+
+// ReleaseReadinessForecast projects each of a release's blocking jobs' pass rate at GA from its recent trend, to warn
+// about jobs heading below the threshold before they get there.
+type ReleaseReadinessForecast struct {
+	Release      string    `json:"release"`
+	GA           time.Time `json:"ga"`
+	HistoryStart time.Time `json:"history_start"`
+	HistoryEnd   time.Time `json:"history_end"`
+	// Threshold is the pass percentage a job is at risk of falling below.
+	Threshold float64 `json:"threshold"`
+	// Jobs are the blocking jobs, those at risk first, then by forecast pass percentage.
+	Jobs []JobPassRateForecast `json:"jobs"`
+}
+
+// JobPassRateForecast is a job's pass rate over the history, its trend, and where the trend puts it at GA.
+type JobPassRateForecast struct {
+	JobID          uint    `json:"job_id"`
+	JobName        string  `json:"job_name"`
+	Runs           int     `json:"runs"`
+	PassPercentage float64 `json:"pass_percentage"`
+	// Status is at-risk when the forecast, or the low end of its 95% interval, is below the threshold.
+	Status string `json:"status"`
+	// TrendPerDay is the change in pass percentage each day, and the forecast and its 95% interval are clamped to 0
+	// and 100. They're left out with insufficient data.
+	TrendPerDay            float64 `json:"trend_per_day"`
+	ForecastPassPercentage float64 `json:"forecast_pass_percentage"`
+	ForecastLow            float64 `json:"forecast_low"`
+	ForecastHigh           float64 `json:"forecast_high"`
+}
+
+// JobTimeoutRisk is a job whose runs are getting close to its prow timeout. Durations are in seconds.
+type JobTimeoutRisk struct {
+	JobID   uint    `json:"job_id"`
+	JobName string  `json:"job_name"`
+	Timeout float64 `json:"timeout"`
+	// DefaultTimeout is set when the job's timeout wasn't recorded, and the prow default was assumed.
+	DefaultTimeout bool `json:"default_timeout"`
+
+	CurrentRuns int     `json:"current_runs"`
+	CurrentP95  float64 `json:"current_p95"`
+	CurrentMax  float64 `json:"current_max"`
+	PreviousP95 float64 `json:"previous_p95"`
+	// TimeoutPercentage is the current P95 as a percentage of the timeout.
+	TimeoutPercentage    float64 `json:"timeout_percentage"`
+	CurrentTimedOutRuns  int     `json:"current_timed_out_runs"`
+	PreviousTimedOutRuns int     `json:"previous_timed_out_runs"`
+
+	// SlowestStep is the ci-operator step taking longest on average, and its average duration.
+	SlowestStep         string  `json:"slowest_step,omitempty"`
+	SlowestStepDuration float64 `json:"slowest_step_duration,omitempty"`
+}
+
+// FailureCluster is a group of test failures with near-identical output, across tests and jobs.
+type FailureCluster struct {
+	// Failures is how many failures are in the cluster.
+	Failures int `json:"failures"`
+	// Output is the output of one of the failures, trimmed.
+	Output string `json:"output"`
+	// Tests and Jobs are the names of the tests and jobs that failed, most failures first, limited to the top ten.
+	TestCount int      `json:"test_count"`
+	Tests     []string `json:"tests"`
+	JobCount  int      `json:"job_count"`
+	Jobs      []string `json:"jobs"`
+	// URLs are the job runs of the most recent failures.
+	URLs []string `json:"urls"`
+}
+
+// FunnelDay is how many install or upgrade attempts a release's jobs of a variant made on a day, and why those that
+// failed did.
+type FunnelDay struct {
+	Date              string  `json:"date"`
+	Variant           string  `json:"variant"`
+	Attempts          int     `json:"attempts"`
+	Successes         int     `json:"successes"`
+	SuccessPercentage float64 `json:"success_percentage"`
+	// FailureReasons are the stages failed attempts failed at, most failures first.
+	FailureReasons []FunnelFailureReason `json:"failure_reasons"`
+}
+
+// FunnelFailureReason is the stage an install or upgrade failed at, and how many times it did.
+type FunnelFailureReason struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// PayloadBisect narrows down the payloads where a test's pass rate dropped, and the pull requests they included.
+type PayloadBisect struct {
+	TestName     string `json:"test_name"`
+	Release      string `json:"release"`
+	Stream       string `json:"stream"`
+	Architecture string `json:"architecture"`
+	// Payloads are the test's results in each of the stream's payloads, oldest first.
+	Payloads []PayloadTestResult `json:"payloads"`
+	// LastGoodPayload and FirstBadPayload are the payloads running the test either side of the drop, both empty when
+	// the pass rate didn't drop significantly.
+	LastGoodPayload      string  `json:"last_good_payload"`
+	FirstBadPayload      string  `json:"first_bad_payload"`
+	BeforePassPercentage float64 `json:"before_pass_percentage"`
+	AfterPassPercentage  float64 `json:"after_pass_percentage"`
+	PValue               float64 `json:"p_value"`
+	// SuspectPayloads are the payloads after the last good one, up to and including the first bad one.
+	SuspectPayloads []string `json:"suspect_payloads"`
+	// PullRequests are the pull requests first included in the suspect payloads.
+	PullRequests []PayloadBisectPullRequest `json:"pull_requests"`
+}
+
+// PayloadTestResult is a test's results in the job runs of a payload.
+type PayloadTestResult struct {
+	ReleaseTag  string    `json:"release_tag"`
+	Phase       string    `json:"phase"`
+	ReleaseTime time.Time `json:"release_time"`
+	Passes      int       `json:"passes"`
+	Failures    int       `json:"failures"`
+}
+
+// PayloadBisectPullRequest is a pull request first included in a suspect payload.
+type PayloadBisectPullRequest struct {
+	ReleaseTag    string `json:"release_tag"`
+	URL           string `json:"url"`
+	PullRequestID string `json:"pull_request_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	BugURL        string `json:"bug_url"`
+}
+
+const (
+	PayloadGateAccept  = "accept"
+	PayloadGateReject  = "reject"
+	PayloadGatePending = "pending"
+)
+
+// PayloadGateRequest asks whether a payload that isn't loaded into sippy yet should be accepted, given the results of
+// its blocking jobs keyed by name, as the release controller reports them.
+type PayloadGateRequest struct {
+	ReleaseTag   string                            `json:"release_tag"`
+	Release      string                            `json:"release"`
+	Stream       string                            `json:"stream"`
+	Architecture string                            `json:"architecture"`
+	BlockingJobs map[string]PayloadGateJobRunState `json:"blocking_jobs"`
+}
+
+// PayloadGateJobRunState is a blocking job's run in a payload, and its state, i.e. Succeeded, Failed or Pending.
+type PayloadGateJobRunState struct {
+	State string `json:"state"`
+	URL   string `json:"url"`
+}
+
+// PayloadGate is sippy's advice on whether a payload should be accepted, from the results of its blocking jobs, their
+// recent health, and the release's open regressions.
+type PayloadGate struct {
+	ReleaseTag   string `json:"release_tag"`
+	Release      string `json:"release"`
+	Stream       string `json:"stream"`
+	Architecture string `json:"architecture"`
+	// Verdict is accept when no blocking job failed in a way specific to the payload, reject when one did, and pending
+	// when jobs are still running or should be retried.
+	Verdict string `json:"verdict"`
+	// Reasons explain the verdict, one per blocking job that didn't pass.
+	Reasons []string         `json:"reasons"`
+	Jobs    []PayloadGateJob `json:"jobs"`
+}
+
+// PayloadGateJob is the advice on one of the payload's blocking jobs.
+type PayloadGateJob struct {
+	Name         string `json:"name"`
+	ProwJobName  string `json:"prow_job_name,omitempty"`
+	ProwJobRunID uint   `json:"prow_job_run_id"`
+	URL          string `json:"url"`
+	State        string `json:"state"`
+	Verdict      string `json:"verdict"`
+	Reason       string `json:"reason,omitempty"`
+	// PassPercentage and Runs are the job's results over the last week, excluding this run.
+	PassPercentage float64 `json:"pass_percentage"`
+	Runs           int     `json:"runs"`
+	// FailedTests are the tests that failed in the run, KnownRegressions those of them regressed in the release, and
+	// QuarantinedTests those of them quarantined.
+	FailedTests      []string `json:"failed_tests,omitempty"`
+	KnownRegressions []string `json:"known_regressions,omitempty"`
+	QuarantinedTests []string `json:"quarantined_tests,omitempty"`
+}
+
+const (
+	PayloadStreamsHealthy          = "healthy"
+	PayloadStreamsContentProblem   = "payload-content"
+	PayloadStreamsStreamProblem    = "stream-specific"
+	PayloadStreamsInsufficientData = "insufficient-data"
+)
+
+// PayloadStreamComparison compares the health of a release's nightly and ci payload streams on an architecture, to
+// tell whether a problem is in the content of the payloads, which both streams build, or specific to one stream.
+type PayloadStreamComparison struct {
+	Release      string    `json:"release"`
+	Architecture string    `json:"architecture"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	// Verdict is healthy when both streams accept payloads, payload-content when both are failing, stream-specific
+	// when only one is, and insufficient-data when a stream has too few payloads to tell.
+	Verdict string                `json:"verdict"`
+	Reason  string                `json:"reason"`
+	Streams []PayloadStreamHealth `json:"streams"`
+	// SharedFailures are the blocking jobs failing in both streams, the most failures first.
+	SharedFailures []PayloadStreamSharedFailure `json:"shared_failures"`
+}
+
+// PayloadStreamHealth is how many of a stream's payloads were accepted, and which of its blocking jobs failed.
+type PayloadStreamHealth struct {
+	Stream   string `json:"stream"`
+	Payloads int    `json:"payloads"`
+	Accepted int    `json:"accepted"`
+	Rejected int    `json:"rejected"`
+	// AcceptancePercentage is the share of the payloads that finished, accepted or rejected, that were accepted.
+	AcceptancePercentage float64 `json:"acceptance_percentage"`
+	// BlockingFailures are the stream's distinct failing blocking jobs, the most failures first.
+	BlockingFailures []PayloadStreamJobFailures `json:"blocking_failures"`
+}
+
+// PayloadStreamJobFailures is how often a blocking job, by its name in the release controller, failed in a stream's
+// payloads.
+type PayloadStreamJobFailures struct {
+	JobName  string `json:"job_name"`
+	Runs     int    `json:"runs"`
+	Failures int    `json:"failures"`
+}
+
+// PayloadStreamSharedFailure is a blocking job failing in both streams.
+type PayloadStreamSharedFailure struct {
+	JobName         string `json:"job_name"`
+	NightlyFailures int    `json:"nightly_failures"`
+	CIFailures      int    `json:"ci_failures"`
+}
+
+// LoaderErrorJob summarizes a job's runs that failed to import, so jobs that consistently fail to import stand out.
+type LoaderErrorJob struct {
+	JobName string `json:"job_name"`
+	Release string `json:"release"`
+	// Runs is how many distinct runs failed to import, and Errors how many times they failed.
+	Runs      int       `json:"runs"`
+	Errors    int       `json:"errors"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Classes counts the errors by class, i.e. junit or database.
+	Classes map[string]int `json:"classes"`
+	// LastMessage and LastPath are the latest error's message, and the path of the run it was importing.
+	LastMessage string `json:"last_message"`
+	LastPath    string `json:"last_path"`
+}
+
+// VariantInteractionAnalysis breaks a test's regression down by variant, and finds the combinations of variants driving
+// it, comparing the last week to the week before.
+type VariantInteractionAnalysis struct {
+	Release  string `json:"release"`
+	TestName string `json:"test_name"`
+	// Variants are the test's results in each variant, regressed first.
+	Variants []VariantInteraction `json:"variants"`
+	// Drivers are the pairs of variants the test regressed in while it didn't regress in jobs with either variant
+	// alone, most significant first.
+	Drivers []VariantInteraction `json:"drivers"`
+}
+
+// VariantInteraction is a test's results in the jobs with all the variants.
+type VariantInteraction struct {
+	Variants               []string `json:"variants"`
+	CurrentRuns            int      `json:"current_runs"`
+	CurrentPassPercentage  float64  `json:"current_pass_percentage"`
+	PreviousRuns           int      `json:"previous_runs"`
+	PreviousPassPercentage float64  `json:"previous_pass_percentage"`
+	PValue                 float64  `json:"p_value"`
+	Regressed              bool     `json:"regressed"`
+}
+
+// TestOwnershipOverrideRequest creates or replaces the override of a test's component. The component defaults to the
+// JIRA component, and the test keeps the capabilities the mapping gave it when none are given.
+type TestOwnershipOverrideRequest struct {
+	TestName      string   `json:"test_name"`
+	Suite         string   `json:"suite,omitempty"`
+	Component     string   `json:"component,omitempty"`
+	JiraComponent string   `json:"jira_component"`
+	Capabilities  []string `json:"capabilities,omitempty"`
+	Reason        string   `json:"reason"`
+}
+
+// TestQuarantineRequest quarantines a test until it expires, or replaces its quarantine. The JIRA link is to the issue
+// tracking the test's fix.
+type TestQuarantineRequest struct {
+	TestName  string    `json:"test_name"`
+	JiraURL   string    `json:"jira_url"`
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TestOwnershipCoverage is how many of a release's tests that ran in the last week map to a component, and the tests
+// that don't.
+type TestOwnershipCoverage struct {
+	Release            string  `json:"release"`
+	Tests              int     `json:"tests"`
+	OwnedTests         int     `json:"owned_tests"`
+	CoveragePercentage float64 `json:"coverage_percentage"`
+	// Runs and OwnedRuns weigh coverage by how often tests run.
+	Runs                  int     `json:"runs"`
+	OwnedRuns             int     `json:"owned_runs"`
+	RunCoveragePercentage float64 `json:"run_coverage_percentage"`
+	// UnownedTests are the tests with no component, most frequently run first, then most failing.
+	UnownedTests []UnownedTest `json:"unowned_tests"`
+}
+
+// UnownedTest is a test with no component mapping and its results in the last week.
+type UnownedTest struct {
+	TestID            uint    `json:"test_id"`
+	TestName          string  `json:"test_name"`
+	SuiteName         string  `json:"suite_name"`
+	Runs              int     `json:"runs"`
+	Failures          int     `json:"failures"`
+	FailurePercentage float64 `json:"failure_percentage"`
+}
+
+// ComponentHierarchy rolls a release's test results in the last week and the week before up the hierarchy of Jira
+// components, from components to their sub-components to the capabilities their tests cover.
+type ComponentHierarchy struct {
+	Release    string          `json:"release"`
+	Components []ComponentNode `json:"components"`
+}
+
+// ComponentNode is a component, sub-component or capability, and the results of its tests. Capabilities are under
+// the sub-component of their tests, or the component for tests without one. A test covering several capabilities
+// counts towards each.
+type ComponentNode struct {
+	Name string `json:"name"`
+	// Level is component, sub-component or capability.
+	Level                  string          `json:"level"`
+	Tests                  int             `json:"tests"`
+	CurrentRuns            int             `json:"current_runs"`
+	CurrentFailures        int             `json:"current_failures"`
+	CurrentPassPercentage  float64         `json:"current_pass_percentage"`
+	PreviousRuns           int             `json:"previous_runs"`
+	PreviousPassPercentage float64         `json:"previous_pass_percentage"`
+	NetImprovement         float64         `json:"net_improvement"`
+	Children               []ComponentNode `json:"children,omitempty"`
+}
+
+// SigHealth summarizes a sig's tests in a release for its leads' weekly review: their pass and flake rates in the last
+// week and the week before, and their open regressions and bugs.
+type SigHealth struct {
+	Sig                     string  `json:"sig"`
+	Release                 string  `json:"release"`
+	Tests                   int     `json:"tests"`
+	CurrentRuns             int     `json:"current_runs"`
+	CurrentPassPercentage   float64 `json:"current_pass_percentage"`
+	CurrentFlakePercentage  float64 `json:"current_flake_percentage"`
+	PreviousRuns            int     `json:"previous_runs"`
+	PreviousPassPercentage  float64 `json:"previous_pass_percentage"`
+	PreviousFlakePercentage float64 `json:"previous_flake_percentage"`
+	NetImprovement          float64 `json:"net_improvement"`
+	OpenRegressions         int     `json:"open_regressions"`
+	// OpenBugs are the open bugs linked to the sig's tests, in any release.
+	OpenBugs    int                     `json:"open_bugs"`
+	Regressions []models.TestRegression `json:"regressions"`
+	// FailingTests are the tests that failed most in the last week, most failures first.
+	FailingTests []SigHealthTest `json:"failing_tests"`
+}
+
+// SigHealthTest is one of a sig's tests and its results in the last week and the week before.
+type SigHealthTest struct {
+	TestID                 uint    `json:"test_id"`
+	TestName               string  `json:"test_name"`
+	CurrentRuns            int     `json:"current_runs"`
+	CurrentFailures        int     `json:"current_failures"`
+	CurrentFlakes          int     `json:"current_flakes"`
+	CurrentPassPercentage  float64 `json:"current_pass_percentage"`
+	PreviousRuns           int     `json:"previous_runs"`
+	PreviousPassPercentage float64 `json:"previous_pass_percentage"`
+	NetImprovement         float64 `json:"net_improvement"`
+}
+
+// WeeklyResult is a test's or job's results during the week starting on the Monday.
+type WeeklyResult struct {
+	Week           string  `json:"week"`
+	Runs           int     `json:"runs"`
+	Successes      int     `json:"successes"`
+	Flakes         int     `json:"flakes,omitempty"`
+	Failures       int     `json:"failures"`
+	PassPercentage float64 `json:"pass_percentage"`
+	// InfrastructureFailures are job runs that failed due to CI infrastructure.
+	InfrastructureFailures int `json:"infrastructure_failures,omitempty"`
+}
+
+// TestTimeseries is a test's results in a release over time, a point per day or week, with a point for every period
+// since the start so it can be charted as is.
+type TestTimeseries struct {
+	TestID      uint              `json:"test_id"`
+	TestName    string            `json:"test_name"`
+	Release     string            `json:"release"`
+	Variant     string            `json:"variant"`
+	Granularity string            `json:"granularity"`
+	Since       string            `json:"since"`
+	Points      []TimeseriesPoint `json:"points"`
+}
+
+// TimeseriesPoint is a test's results during the day, or the week starting on the Monday. PassPercentage counts flakes
+// as passes, and is null for periods the test didn't run in, so charts leave a gap rather than drop to zero.
+type TimeseriesPoint struct {
+	Date           string   `json:"date"`
+	Runs           int      `json:"runs"`
+	Successes      int      `json:"successes"`
+	Flakes         int      `json:"flakes"`
+	Failures       int      `json:"failures"`
+	PassPercentage *float64 `json:"pass_percentage"`
+}
+
+// JobRunHistory is a job's runs over its last days, with their results summarized per day, for the job's page.
+type JobRunHistory struct {
+	ID       uint           `json:"id"`
+	Name     string         `json:"name"`
+	Release  string         `json:"release"`
+	Variants pq.StringArray `json:"variants"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	// Runs are the job's runs, newest first.
+	Runs []JobHistoryRun `json:"runs"`
+	// Days summarize the runs of each day, oldest first, including days without runs, for a pass rate sparkline.
+	Days []JobHistoryDay `json:"days"`
+}
+
+// JobHistoryRun is a run of a job, and how it failed.
+type JobHistoryRun struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Timestamp time.Time `json:"timestamp"`
+	// Duration is how long the run took, in seconds.
+	Duration      float64             `json:"duration"`
+	Cluster       string              `json:"cluster"`
+	OverallResult v1.JobOverallResult `json:"overall_result"`
+	// Classification names the overall result, i.e. succeeded, infrastructure, install, upgrade or test.
+	Classification        string `json:"classification"`
+	InfrastructureFailure bool   `json:"infrastructure_failure"`
+	KnownFailure          bool   `json:"known_failure"`
+	TestFailures          int    `json:"test_failures"`
+	TestFlakes            int    `json:"test_flakes"`
+	// FailureClassification labels why the run failed, product-failure, infra-failure or install-failure.
+	FailureClassification string `json:"failure_classification,omitempty"`
+	// ConfigHash hashes the job's configuration the run used, empty when it's unknown.
+	ConfigHash string `json:"config_hash,omitempty"`
+	// ConfigChanged is set on the first run using a configuration of the job different from the run before it.
+	ConfigChanged bool `json:"config_changed"`
+}
+
+// JobHistoryDay is a job's results on a day, in UTC.
+type JobHistoryDay struct {
+	Date                   string  `json:"date"`
+	Runs                   int     `json:"runs"`
+	Successes              int     `json:"successes"`
+	Failures               int     `json:"failures"`
+	InfrastructureFailures int     `json:"infrastructure_failures"`
+	PassPercentage         float64 `json:"pass_percentage"`
+	// ConfigChanged is set when the job's configuration changed in one of the day's runs.
+	ConfigChanged bool `json:"config_changed"`
+}
+
+// PayloadChangelog is what changed in a payload since the payload its changelog is based on, grouped by the
+// components whose images were bumped, next to the test regressions opened after it.
+type PayloadChangelog struct {
+	ReleaseTag   string    `json:"release_tag"`
+	Release      string    `json:"release"`
+	Stream       string    `json:"stream"`
+	Architecture string    `json:"architecture"`
+	Phase        string    `json:"phase"`
+	ReleaseTime  time.Time `json:"release_time"`
+	// PreviousReleaseTag is the payload the changelog is based on, the stream's previous accepted payload.
+	PreviousReleaseTag string `json:"previous_release_tag"`
+
+	KubernetesVersion string `json:"kubernetes_version"`
+	CurrentOSVersion  string `json:"current_os_version"`
+	PreviousOSVersion string `json:"previous_os_version"`
+	OSDiffURL         string `json:"os_diff_url"`
+
+	// PullRequests counts the pull requests across all components.
+	PullRequests int                         `json:"pull_requests"`
+	Components   []PayloadChangelogComponent `json:"components"`
+	// Regressions are the release's test regressions opened in the two days after the payload.
+	Regressions []models.TestRegression `json:"regressions"`
+}
+
+// PayloadChangelogComponent is a repository whose images were bumped in a payload, and the pull requests bumping
+// them.
+type PayloadChangelogComponent struct {
+	// Name is the images built from the repository, as known in the payload.
+	Name         string                        `json:"name"`
+	FromCommit   string                        `json:"from_commit,omitempty"`
+	ToCommit     string                        `json:"to_commit,omitempty"`
+	DiffURL      string                        `json:"diff_url,omitempty"`
+	PullRequests []PayloadChangelogPullRequest `json:"pull_requests"`
+}
+
+// PayloadChangelogPullRequest is a pull request included in a payload.
+type PayloadChangelogPullRequest struct {
+	URL           string `json:"url"`
+	PullRequestID string `json:"pull_request_id"`
+	Description   string `json:"description"`
+	BugURL        string `json:"bug_url,omitempty"`
+}
+
+// PullRequestImpact is a pull request first included in a payload, and how payloads and jobs fared around it.
+type PullRequestImpact struct {
+	URL           string `json:"url"`
+	PullRequestID string `json:"pull_request_id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	BugURL        string `json:"bug_url"`
+
+	Payload      string    `json:"payload"`
+	PayloadPhase string    `json:"payload_phase"`
+	PayloadTime  time.Time `json:"payload_time"`
+
+	// PayloadsBefore and PayloadsAfter count the stream's payloads either side of the pull request's, which is
+	// counted after, and how many were rejected.
+	PayloadsBefore int `json:"payloads_before"`
+	RejectedBefore int `json:"rejected_before"`
+	PayloadsAfter  int `json:"payloads_after"`
+	RejectedAfter  int `json:"rejected_after"`
+	// RegressedJobs are the periodic jobs whose pass rate dropped significantly after the payload.
+	RegressedJobs []string `json:"regressed_jobs"`
+	// NewTestRegressions counts the test regressions opened after the payload.
+	NewTestRegressions int `json:"new_test_regressions"`
+}
+
+// SearchResults are the tests, jobs and failure messages fuzzily matching a search, best match first.
+type SearchResults struct {
+	Query    string                `json:"query"`
+	Tests    []SearchResult        `json:"tests"`
+	Jobs     []SearchResult        `json:"jobs"`
+	Failures []FailureSearchResult `json:"failures"`
+}
+
+// SearchResult is a test or job matching a search. Score is the pg_trgm similarity of the search to the name, from 0 to
+// 1.
+type SearchResult struct {
+	ID      uint    `json:"id"`
+	Name    string  `json:"name"`
+	Release string  `json:"release,omitempty"`
+	Score   float64 `json:"score"`
+}
+
+// FailureSearchResult is a failed test whose output matches a search, with the part of the output around the match.
+type FailureSearchResult struct {
+	ProwJobRunID uint      `json:"prow_job_run_id"`
+	JobName      string    `json:"job_name"`
+	Release      string    `json:"release"`
+	TestID       uint      `json:"test_id"`
+	TestName     string    `json:"test_name"`
+	Timestamp    time.Time `json:"timestamp"`
+	Snippet      string    `json:"snippet"`
+	Score        float64   `json:"score"`
+}
+
+// SavedViewRequest creates or updates a saved view. The slug defaults to one made from the name, and can't be changed
+// once the view is created.
+type SavedViewRequest struct {
+	Name    string          `json:"name"`
+	Slug    string          `json:"slug,omitempty"`
+	Global  bool            `json:"global"`
+	Release string          `json:"release"`
+	Variant string          `json:"variant,omitempty"`
+	Filter  json.RawMessage `json:"filter,omitempty"`
+}
+
+// FederatedResponse merges the responses of this and the remote sippy instances to a query. Rows merges responses that
+// are lists of rows, or pages of them, tagging each row with the origin it came from. Other responses are returned
+// as they are in Results, by origin.
+type FederatedResponse struct {
+	Origins []FederatedOrigin          `json:"origins"`
+	Rows    []map[string]interface{}   `json:"rows"`
+	Results map[string]json.RawMessage `json:"results,omitempty"`
+}
+
+// FederatedOrigin is an instance queried, with its response's status, and an error if it failed.
+type FederatedOrigin struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RefreshStatus is the refresh of the materialized views and computed data in progress, if any, and the last one to
+// finish.
+type RefreshStatus struct {
+	Current *models.RefreshRun `json:"current"`
+	Last    *models.RefreshRun `json:"last"`
+	// InFlight is whether this server is refreshing, so a refresh requested from the API would be refused.
+	InFlight bool `json:"in_flight"`
+}
+
+// CrossReleaseTestHistory is a test's weekly results across releases as one series, oldest release first, following
+// the test through renames.
+type CrossReleaseTestHistory struct {
+	TestName string                     `json:"test_name"`
+	Variant  string                     `json:"variant"`
+	Releases []ReleaseTestHistory       `json:"releases"`
+	Series   []CrossReleaseWeeklyResult `json:"series"`
+}
+
+// ReleaseTestHistory is the name the test had in a release, and how it was matched to the test: exact when it's the
+// name asked for, rename when a configured rename led to it, and fuzzy when it's the most similar name, with its score
+// from 0 to 1. Names matched from a renamed or fuzzily matched name keep its match.
+type ReleaseTestHistory struct {
+	Release  string  `json:"release"`
+	TestName string  `json:"test_name"`
+	Match    string  `json:"match"`
+	Score    float64 `json:"score,omitempty"`
+	Weeks    int     `json:"weeks"`
+}
+
+// CrossReleaseWeeklyResult is the test's results in a week of a release, under its name in the release.
+type CrossReleaseWeeklyResult struct {
+	Release  string `json:"release"`
+	TestName string `json:"test_name"`
+	WeeklyResult
+}
+
+// RetestRecommendation recommends whether a failed job run, i.e. a presubmit, is likely to pass when retested, or
+// failed for a real reason, with the factors the decision was made from. Factors with a positive weight favor a
+// retest, and those with a negative weight a real failure, the recommendation follows their sum, the score.
+type RetestRecommendation struct {
+	ProwJobRunID   uint           `json:"prow_job_run_id"`
+	ProwJobName    string         `json:"prow_job_name"`
+	Release        string         `json:"release"`
+	Recommendation string         `json:"recommendation"`
+	Score          int            `json:"score"`
+	RiskLevel      string         `json:"risk_level"`
+	Factors        []RetestFactor `json:"factors"`
+	Tests          []RetestTest   `json:"tests"`
+}
+
+// RetestFactor is a factor of a retest recommendation, with the outcome it favors.
+type RetestFactor struct {
+	Name   string `json:"name"`
+	Favors string `json:"favors"`
+	Weight int    `json:"weight"`
+	Detail string `json:"detail"`
+}
+
+// RetestTest is a test that failed in the run, with its risk, how many recent failures of it in other jobs had a
+// similar output, and whether it's quarantined or has open bugs.
+type RetestTest struct {
+	Name            string   `json:"name"`
+	RiskLevel       string   `json:"risk_level"`
+	RiskReasons     []string `json:"risk_reasons"`
+	SimilarFailures int      `json:"similar_failures"`
+	SimilarJobs     int      `json:"similar_jobs"`
+	Quarantined     bool     `json:"quarantined"`
+	OpenBugs        int      `json:"open_bugs"`
+}
@@ -13,6 +13,8 @@ type Cache interface {
 type APIResponse struct {
 	Headers  http.Header
 	Response []byte
+	// StatusCode is the response's status, zero for responses cached before it was recorded, which were all 200s.
+	StatusCode int
 }
 
 // RequestOptions specifies options for an individual
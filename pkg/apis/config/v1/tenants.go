@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// JobTenants assigns jobs to the tenants they belong to, by release and job name.
+type JobTenants struct {
+	tenants []jobTenant
+}
+
+type jobTenant struct {
+	name     string
+	releases map[string]bool
+	jobs     []*regexp.Regexp
+}
+
+// JobTenants returns the tenant assignments of the config, failing when a tenant's job regexp doesn't compile.
+func (c *SippyConfig) JobTenants() (*JobTenants, error) {
+	names := make([]string, 0, len(c.Tenants))
+	for name := range c.Tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	jt := &JobTenants{}
+	for _, name := range names {
+		tenant := jobTenant{name: name, releases: map[string]bool{}}
+		for _, release := range c.Tenants[name].Releases {
+			tenant.releases[release] = true
+		}
+		for _, job := range c.Tenants[name].Jobs {
+			re, err := regexp.Compile(job)
+			if err != nil {
+				return nil, fmt.Errorf("tenant %s has invalid job regexp %q: %v", name, job, err)
+			}
+			tenant.jobs = append(tenant.jobs, re)
+		}
+		jt.tenants = append(jt.tenants, tenant)
+	}
+	return jt, nil
+}
+
+// Tenant returns the tenant the job of the release belongs to, or an empty string if it has none. A job belongs to
+// the first tenant, by name, having its release and either no job regexps or one matching the job.
+func (jt *JobTenants) Tenant(release, job string) string {
+	if jt == nil {
+		return ""
+	}
+	for _, tenant := range jt.tenants {
+		if !tenant.releases[release] {
+			continue
+		}
+		if len(tenant.jobs) == 0 {
+			return tenant.name
+		}
+		for _, re := range tenant.jobs {
+			if re.MatchString(job) {
+				return tenant.name
+			}
+		}
+	}
+	return ""
+}
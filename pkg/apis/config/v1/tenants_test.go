@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobTenants(t *testing.T) {
+	config := &SippyConfig{Tenants: map[string]TenantConfig{
+		"ocp": {Releases: []string{"4.16"}, Jobs: []string{"-ocp-"}},
+		"okd": {Releases: []string{"4.16"}, Jobs: []string{"-okd-"}},
+		"hcp": {Releases: []string{"4.15"}},
+	}}
+	jt, err := config.JobTenants()
+	require.NoError(t, err)
+
+	assert.Equal(t, "ocp", jt.Tenant("4.16", "periodic-ci-4.16-ocp-e2e-aws"))
+	assert.Equal(t, "okd", jt.Tenant("4.16", "periodic-ci-4.16-okd-e2e-aws"))
+	assert.Empty(t, jt.Tenant("4.16", "periodic-ci-4.16-e2e-aws"))
+	assert.Equal(t, "hcp", jt.Tenant("4.15", "periodic-ci-4.15-e2e-aws"))
+	assert.Empty(t, jt.Tenant("4.14", "periodic-ci-4.14-okd-e2e-aws"))
+
+	var none *JobTenants
+	assert.Empty(t, none.Tenant("4.16", "periodic-ci-4.16-ocp-e2e-aws"))
+
+	config.Tenants["okd"] = TenantConfig{Releases: []string{"4.16"}, Jobs: []string{"(okd"}}
+	_, err = config.JobTenants()
+	assert.Error(t, err)
+}
@@ -1,8 +1,27 @@
 package v1
 
+import "time"
+
 type SippyConfig struct {
-	Prow     ProwConfig               `yaml:"prow"`
-	Releases map[string]ReleaseConfig `yaml:"releases"`
+	Prow           ProwConfig               `yaml:"prow"`
+	Releases       map[string]ReleaseConfig `yaml:"releases"`
+	NeverStable    NeverStableConfig        `yaml:"neverStable,omitempty"`
+	SyntheticTests []SyntheticTestConfig    `yaml:"syntheticTests,omitempty"`
+	Commenter      CommenterConfig          `yaml:"commenter,omitempty"`
+	Notifications  NotificationConfig       `yaml:"notifications,omitempty"`
+	Digest         DigestConfig             `yaml:"digest,omitempty"`
+	Alerting       AlertingConfig           `yaml:"alerting,omitempty"`
+	Elasticsearch  ElasticsearchConfig      `yaml:"elasticsearch,omitempty"`
+	JiraFiling     JiraFilingConfig         `yaml:"jiraFiling,omitempty"`
+	Tenants        map[string]TenantConfig  `yaml:"tenants,omitempty"`
+
+	RegressionDetection   RegressionDetectionConfig   `yaml:"regressionDetection,omitempty"`
+	FailureClassification FailureClassificationConfig `yaml:"failureClassification,omitempty"`
+	SilentJobDetection    SilentJobDetectionConfig    `yaml:"silentJobDetection,omitempty"`
+	Federation            FederationConfig            `yaml:"federation,omitempty"`
+
+	// TestRenames stitch the history of renamed tests across releases.
+	TestRenames []TestRenameConfig `yaml:"testRenames,omitempty"`
 }
 
 type ProwConfig struct {
@@ -23,4 +42,411 @@ type ReleaseConfig struct {
 
 	// InformingJobs is the list of informing payload jobs
 	InformingJobs []string `yaml:"informingJobs,omitempty"`
+
+	// ExperimentalJobs are jobs still being brought up, which are left out of default reports and whose regressions
+	// weigh least.
+	ExperimentalJobs []string `yaml:"experimentalJobs,omitempty"`
+
+	// InvalidVariants is a list of variants that don't apply to the release, i.e. sdn from 4.17 on. They are never
+	// identified for the release's jobs, and are left out of its variant reports.
+	InvalidVariants []string `yaml:"invalidVariants,omitempty"`
+
+	// Milestones are the release's freeze and GA dates, reports annotate their windows with them and aggregate the
+	// release's results since each of them.
+	Milestones ReleaseMilestones `yaml:"milestones,omitempty"`
+}
+
+// ReleaseMilestones are the dates of a release's milestones, as YAML dates, i.e. 2024-05-21. Unset milestones are
+// left out, except GA, which defaults to the release's known GA date.
+type ReleaseMilestones struct {
+	FeatureFreeze time.Time `yaml:"featureFreeze,omitempty"`
+	CodeFreeze    time.Time `yaml:"codeFreeze,omitempty"`
+	GA            time.Time `yaml:"ga,omitempty"`
+}
+
+// TenantConfig scopes a product sharing the sippy instance to its own jobs. Its jobs and payloads are tagged with the
+// tenant when imported, and API requests for the tenant only see them and their releases.
+type TenantConfig struct {
+	// Releases are the releases the tenant has jobs in. Tenants can share a release when each of them has job regexps.
+	Releases []string `yaml:"releases"`
+
+	// Jobs are regexps of the tenant's job names, when empty every job of its releases belongs to it.
+	Jobs []string `yaml:"jobs,omitempty"`
+
+	// Prow overrides the prow instance the tenant's jobs are imported from.
+	Prow ProwConfig `yaml:"prow,omitempty"`
+
+	// GCSBucket overrides the bucket the tenant's job artifacts are read from.
+	GCSBucket string `yaml:"gcsBucket,omitempty"`
+}
+
+// SyntheticTestConfig defines a synthetic test derived from job run metadata. The test is recorded for every matching
+// job run, failing when any of its conditions is not met.
+type SyntheticTestConfig struct {
+	// Name is the name of the synthetic test.
+	Name string `yaml:"name"`
+
+	// JobRegexp limits the test to jobs whose names match, defaulting to all jobs.
+	JobRegexp string `yaml:"jobRegexp,omitempty"`
+
+	// MaxDuration fails the test when the job run took longer, i.e. "2h30m".
+	MaxDuration time.Duration `yaml:"maxDuration,omitempty"`
+
+	// RequiredArtifact fails the test when no artifact path of the job run matches this regexp.
+	RequiredArtifact string `yaml:"requiredArtifact,omitempty"`
+
+	// ForbiddenArtifact fails the test when an artifact path of the job run matches this regexp.
+	ForbiddenArtifact string `yaml:"forbiddenArtifact,omitempty"`
+}
+
+// FailureClassificationConfig configures how failed job runs are labelled as product, infrastructure or install
+// failures when they're loaded. Runs no rule matches are labelled from their synthetic tests.
+type FailureClassificationConfig struct {
+	// Rules are evaluated in order, and the first one matching a failed run labels it.
+	Rules []FailureClassificationRule `yaml:"rules,omitempty"`
+}
+
+// FailureClassificationRule labels the failed runs of matching jobs with a failed test matching its regexps. A rule
+// without test regexps matches every failed run of its jobs.
+type FailureClassificationRule struct {
+	// Classification is product-failure, infra-failure or install-failure.
+	Classification string `yaml:"classification"`
+
+	// JobRegexp limits the rule to jobs whose names match, defaulting to all jobs.
+	JobRegexp string `yaml:"jobRegexp,omitempty"`
+
+	// TestRegexp matches runs with a failed test whose name matches.
+	TestRegexp string `yaml:"testRegexp,omitempty"`
+
+	// OutputRegexp matches runs with a failed test whose output matches, i.e. "dial tcp .*: i/o timeout".
+	OutputRegexp string `yaml:"outputRegexp,omitempty"`
+}
+
+// CommenterConfig customizes the comments sippy-daemon adds to PRs.
+type CommenterConfig struct {
+	// RiskAnalysisTemplate is a Go template rendering risk analysis comments in place of the built-in comment.
+	RiskAnalysisTemplate string `yaml:"riskAnalysisTemplate,omitempty"`
+
+	// OrgRiskAnalysisTemplates maps a GitHub organization to the template used for its PRs in place of
+	// RiskAnalysisTemplate.
+	OrgRiskAnalysisTemplates map[string]string `yaml:"orgRiskAnalysisTemplates,omitempty"`
+}
+
+// NotificationConfig configures where Sippy posts notifications about new regressions, rejected payloads and failed
+// loaders. Routes send the events matching their filters to named sinks, so each team only hears about what they own.
+type NotificationConfig struct {
+	// Slack is a shorthand for Slack channels with their own route.
+	Slack []SlackChannelConfig `yaml:"slack,omitempty"`
+
+	// Sinks are the destinations notifications can be sent to.
+	Sinks []NotificationSinkConfig `yaml:"sinks,omitempty"`
+
+	// Routes send each event to the sinks of every route it matches, a sink matched by several routes receives the
+	// event once.
+	Routes []NotificationRouteConfig `yaml:"routes,omitempty"`
+}
+
+// NotificationFilter matches events. An event matches when it matches every filter, where an empty filter matches
+// everything.
+type NotificationFilter struct {
+	// Events limits the filter to the event types, i.e. regression, payload-rejected or loader-failed.
+	Events []string `yaml:"events,omitempty"`
+
+	// Releases limits the filter to events for the releases.
+	Releases []string `yaml:"releases,omitempty"`
+
+	// Components limits the filter to events for the components.
+	Components []string `yaml:"components,omitempty"`
+
+	// Variants limits the filter to events involving at least one of the variants, i.e. aws or arm64.
+	Variants []string `yaml:"variants,omitempty"`
+}
+
+// SlackChannelConfig is a Slack channel and the notifications posted to it.
+type SlackChannelConfig struct {
+	SlackSinkConfig    `yaml:",inline"`
+	NotificationFilter `yaml:",inline"`
+}
+
+// NotificationRouteConfig sends the events matching its filter to sinks.
+type NotificationRouteConfig struct {
+	NotificationFilter `yaml:",inline"`
+
+	// Sinks are the names of the sinks notified.
+	Sinks []string `yaml:"sinks"`
+}
+
+// NotificationSinkConfig is a named destination for notifications, with exactly one of its destination types set.
+type NotificationSinkConfig struct {
+	Name string `yaml:"name"`
+
+	Slack   *SlackSinkConfig   `yaml:"slack,omitempty"`
+	Teams   *TeamsSinkConfig   `yaml:"teams,omitempty"`
+	Email   *EmailSinkConfig   `yaml:"email,omitempty"`
+	Webhook *WebhookSinkConfig `yaml:"webhook,omitempty"`
+}
+
+type SlackSinkConfig struct {
+	// Channel is posted to with the bot token from the SLACK_BOT_TOKEN environment variable, i.e. #forum-sippy.
+	Channel string `yaml:"channel,omitempty"`
+
+	// WebhookURL is a Slack incoming webhook, posted to in place of Channel.
+	WebhookURL string `yaml:"webhookURL,omitempty"`
+}
+
+// TeamsSinkConfig posts notifications to a Microsoft Teams channel as adaptive cards.
+type TeamsSinkConfig struct {
+	// WebhookURL is the channel's incoming webhook, or a Power Automate workflow accepting webhook requests.
+	WebhookURL string `yaml:"webhookURL"`
+}
+
+// EmailSinkConfig mails notifications, authenticating with the SIPPY_SMTP_PASSWORD environment variable when the
+// SMTP username is set.
+type EmailSinkConfig struct {
+	SMTP SMTPConfig `yaml:"smtp"`
+	From string     `yaml:"from"`
+	To   []string   `yaml:"to"`
+}
+
+// WebhookSinkConfig posts notifications as JSON to a URL.
+type WebhookSinkConfig struct {
+	URL string `yaml:"url"`
+}
+
+// DigestConfig configures the email digests sippy-daemon sends component owners, summarizing their tests' pass rate
+// changes, newly failing tests and linked bugs.
+type DigestConfig struct {
+	// SMTP is the mail server digests are sent through.
+	SMTP SMTPConfig `yaml:"smtp"`
+
+	// From is the sender address of digests.
+	From string `yaml:"from"`
+
+	// Schedule is daily or weekly, weekly digests are sent on Mondays. Defaults to weekly.
+	Schedule string `yaml:"schedule,omitempty"`
+
+	// Hour is the hour of the day, in UTC, digests are sent at.
+	Hour int `yaml:"hour,omitempty"`
+
+	// Releases are the releases summarized in each digest.
+	Releases []string `yaml:"releases"`
+
+	// Recipients maps a Jira component to the addresses of its owners.
+	Recipients map[string][]string `yaml:"recipients"`
+
+	// PassRateChangeThreshold is the change in pass percentage reported as an improvement or regression. Defaults
+	// to 5.
+	PassRateChangeThreshold float64 `yaml:"passRateChangeThreshold,omitempty"`
+
+	// SippyURL is the sippy instance digests link to.
+	SippyURL string `yaml:"sippyURL,omitempty"`
+}
+
+// SMTPConfig is a mail server. The password is read from the SIPPY_SMTP_PASSWORD environment variable.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port,omitempty"`
+	Username string `yaml:"username,omitempty"`
+}
+
+// AlertingConfig configures the alerts raised after each load, when release blocking jobs or payload streams are
+// unhealthy.
+type AlertingConfig struct {
+	// PagerDuty sends alerts to PagerDuty, with the Events API routing key from the SIPPY_PAGERDUTY_ROUTING_KEY
+	// environment variable.
+	PagerDuty bool `yaml:"pagerDuty,omitempty"`
+
+	// AlertmanagerURL sends alerts to the Alertmanager, i.e. http://alertmanager:9093.
+	AlertmanagerURL string `yaml:"alertmanagerURL,omitempty"`
+
+	// BlockingJobPassThreshold alerts when a release's blocking job's pass percentage drops below it, zero disables
+	// the alert.
+	BlockingJobPassThreshold float64 `yaml:"blockingJobPassThreshold,omitempty"`
+
+	// HoursWithoutAcceptedPayload alerts when a payload stream goes this long without an accepted payload, zero
+	// disables the alert.
+	HoursWithoutAcceptedPayload float64 `yaml:"hoursWithoutAcceptedPayload,omitempty"`
+}
+
+// ElasticsearchConfig configures indexing job runs and their test results into an Elasticsearch cluster after each
+// load.
+type ElasticsearchConfig struct {
+	// URL of the cluster, i.e. https://elasticsearch:9200. Authenticated with the ELASTICSEARCH_API_KEY, or
+	// ELASTICSEARCH_USERNAME and ELASTICSEARCH_PASSWORD environment variables. Leaving it empty disables the export.
+	URL string `yaml:"url,omitempty"`
+
+	// IndexPrefix prefixes the job run and test indices, defaults to sippy.
+	IndexPrefix string `yaml:"indexPrefix,omitempty"`
+
+	// LookbackHours exports the job runs loaded in the last hours, defaults to 24. Documents are indexed by their
+	// database IDs, so runs exported by a previous load are overwritten rather than duplicated.
+	LookbackHours int `yaml:"lookbackHours,omitempty"`
+}
+
+// JiraFilingConfig configures filing jira issues for tests that stay regressed, in the owning component's project.
+// Issues are filed with the JIRA_TOKEN environment variable.
+type JiraFilingConfig struct {
+	// URL of the jira instance, defaults to https://issues.redhat.com.
+	URL string `yaml:"url,omitempty"`
+
+	// Releases whose tests are checked, filing is disabled when empty.
+	Releases []string `yaml:"releases,omitempty"`
+
+	// Days a test must stay regressed before an issue is filed, defaults to 3.
+	Days int `yaml:"days,omitempty"`
+
+	// RegressionThreshold is how far a test's pass percentage must drop from the previous week to be regressed,
+	// defaults to 10.
+	RegressionThreshold float64 `yaml:"regressionThreshold,omitempty"`
+
+	// MinRuns ignores tests with fewer runs in the current week, defaults to 10.
+	MinRuns int `yaml:"minRuns,omitempty"`
+
+	// ComponentProjects maps jira components to the project their issues are filed in, components not listed use
+	// DefaultProject, or are skipped if it is empty.
+	ComponentProjects map[string]string `yaml:"componentProjects,omitempty"`
+	DefaultProject    string            `yaml:"defaultProject,omitempty"`
+
+	// IssueType defaults to Bug.
+	IssueType string `yaml:"issueType,omitempty"`
+
+	// Labels are added to every issue filed, along with sippy-auto-filed.
+	Labels []string `yaml:"labels,omitempty"`
+
+	// SippyURL is used to link to the test in sippy.
+	SippyURL string `yaml:"sippyURL,omitempty"`
+}
+
+// NeverStableConfig tunes how jobs are flagged as never-stable from their pass rates.
+type NeverStableConfig struct {
+	// PassRateThreshold is the pass percentage a job must stay below to be flagged. Defaults to 10.
+	PassRateThreshold float64 `yaml:"passRateThreshold,omitempty"`
+
+	// Weeks is the number of consecutive complete weeks a job must stay below the threshold. Defaults to 2.
+	Weeks int `yaml:"weeks,omitempty"`
+
+	// Include are jobs always flagged, regardless of their pass rate.
+	Include []string `yaml:"include,omitempty"`
+
+	// Exclude are jobs never flagged, i.e. jobs which are expected to fail while being brought up.
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// VariantConfig defines how jobs are bucketed into variants, allowing new platforms and variants to be introduced
+// without a code change.
+type VariantConfig struct {
+	// Variants is the list of every variant a rule may assign.
+	Variants []string `yaml:"variants"`
+
+	// Platforms is the subset of Variants that describe the platform a job runs on.
+	Platforms []string `yaml:"platforms,omitempty"`
+
+	// Dimensions maps a variant to the dimension it belongs to, i.e. aws: Platform, so jobs can be queried by
+	// dimension. Variants without a dimension are only available as flat variants.
+	Dimensions map[string]string `yaml:"dimensions,omitempty"`
+
+	// Descriptions maps a variant to a human-readable description shown in the UI.
+	Descriptions map[string]string `yaml:"descriptions,omitempty"`
+
+	// NeverStableJobs are jobs excluded from all other variants, see IsJobNeverStable.
+	NeverStableJobs []string `yaml:"neverStableJobs,omitempty"`
+
+	// Rules are evaluated in order against each job name, and every matching rule contributes its variants.
+	Rules []VariantRule `yaml:"rules"`
+
+	// ReleaseOverrides maps a release to the rules used for its jobs in place of Rules.
+	ReleaseOverrides map[string][]VariantRule `yaml:"releaseOverrides,omitempty"`
+}
+
+type VariantRule struct {
+	// Regexp is matched against the job name.
+	Regexp string `yaml:"regexp"`
+
+	// Variants are assigned to jobs matching Regexp.
+	Variants []string `yaml:"variants"`
+
+	// Terminal stops evaluation at this rule when it matches, so the job is only in this rule's variants.
+	Terminal bool `yaml:"terminal,omitempty"`
+}
+
+// RegressionDetectionConfig configures detecting regressed tests when data is refreshed, by comparing each test's
+// failures in the last week to the week before with Fisher's exact test.
+type RegressionDetectionConfig struct {
+	// Releases whose tests are checked, detection is disabled when empty.
+	Releases []string `yaml:"releases,omitempty"`
+
+	// Confidence is the percent confidence a drop in pass rate must be significant at, defaults to 95.
+	Confidence int `yaml:"confidence,omitempty"`
+
+	// MinRuns ignores tests with fewer runs in either week, defaults to 10.
+	MinRuns int `yaml:"minRuns,omitempty"`
+
+	// BaselinesFile is a YAML TestBaselinesConfig of the pass rates component owners expect of their tests, which
+	// the tests are compared to instead of the previous week. It's read each time regressions are detected.
+	BaselinesFile string `yaml:"baselinesFile,omitempty"`
+}
+
+// TestBaselinesConfig declares the expected pass rates of tests.
+type TestBaselinesConfig struct {
+	Baselines []TestBaseline `yaml:"baselines"`
+}
+
+// TestBaseline is the pass rate expected or accepted of a test in a release's jobs, or only in its jobs with all of
+// the variants. A test with a baseline for all jobs isn't compared to the previous week, it's regressed when it passes
+// significantly less than the baseline. Flakes count as passes.
+type TestBaseline struct {
+	Release        string   `yaml:"release"`
+	TestName       string   `yaml:"testName"`
+	Variants       []string `yaml:"variants,omitempty"`
+	PassPercentage float64  `yaml:"passPercentage"`
+
+	// Owner and Reason say who declared the baseline and why, i.e. a known platform limitation.
+	Owner  string `yaml:"owner,omitempty"`
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// SilentJobDetectionConfig configures detecting jobs that stopped reporting when data is refreshed: jobs that ran
+// regularly in the two weeks before, but have had no runs for a while.
+type SilentJobDetectionConfig struct {
+	// Releases whose jobs are checked, detection is disabled when empty.
+	Releases []string `yaml:"releases,omitempty"`
+
+	// MinRunsPerDay only checks jobs that averaged at least this many runs a day in the two weeks before, defaults
+	// to 1.
+	MinRunsPerDay float64 `yaml:"minRunsPerDay,omitempty"`
+
+	// Hours a job must go without runs to be silent, defaults to 48.
+	Hours float64 `yaml:"hours,omitempty"`
+}
+
+// TestRenameConfig maps a test's name before a release to its name from that release on, so the test's history across
+// releases continues through the rename.
+type TestRenameConfig struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	// Release is the first release the test is named To in.
+	Release string `yaml:"release"`
+}
+
+// FederationConfig configures /api/federated, which merges read queries to this instance with the same queries to
+// other sippy instances, i.e. the OKD and OCP instances, tagging each result with the instance it came from.
+type FederationConfig struct {
+	// Name tags this instance's results, defaults to local.
+	Name string `yaml:"name,omitempty"`
+
+	// Remotes are the other instances queried, federation is disabled when empty.
+	Remotes []FederationRemoteConfig `yaml:"remotes,omitempty"`
+
+	// TimeoutSeconds is how long a remote instance has to respond, defaults to 30. Instances that don't respond in
+	// time are reported as failed, the others' results are still returned.
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty"`
+}
+
+// FederationRemoteConfig is a remote sippy instance.
+type FederationRemoteConfig struct {
+	// Name tags the instance's results, i.e. okd.
+	Name string `yaml:"name"`
+
+	// URL of the instance, i.e. https://sippy.dptools.openshift.org. Its API is queried at <url>/api/...
+	URL string `yaml:"url"`
 }
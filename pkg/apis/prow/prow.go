@@ -1,6 +1,11 @@
 package prow
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
 
 // ProwJobState specifies whether the job is running
 type ProwJobState string
@@ -64,6 +69,83 @@ type ProwJobSpec struct {
 
 	// Refs is the code under test, determined at runtime by Prow itself
 	Refs *Refs `json:"refs,omitempty"`
+
+	DecorationConfig *DecorationConfig `json:"decoration_config,omitempty"`
+
+	// ConfigHash hashes the job's configuration in the spec it was read from, empty when it wasn't read from one, i.e.
+	// for jobs loaded from bigquery.
+	ConfigHash string `json:"-"`
+}
+
+// runSpecFields are set in a prow job's spec for each run, rather than by the job's configuration, so they're left out
+// of its hash. The build cluster is recorded with each run.
+var runSpecFields = []string{"refs", "cluster"}
+
+func (s *ProwJobSpec) UnmarshalJSON(b []byte) error {
+	type spec ProwJobSpec
+	if err := json.Unmarshal(b, (*spec)(s)); err != nil {
+		return err
+	}
+	hash, err := configHash(b)
+	if err != nil {
+		return err
+	}
+	s.ConfigHash = hash
+	return nil
+}
+
+// configHash hashes a prow job's spec without the fields set for each run, so runs of the same configuration hash the
+// same however the spec's JSON is ordered.
+func configHash(spec []byte) (string, error) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(spec, &fields); err != nil {
+		return "", err
+	}
+	for _, field := range runSpecFields {
+		delete(fields, field)
+	}
+	// maps are marshaled with sorted keys
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type DecorationConfig struct {
+	// Timeout is how long the job may run before prow kills it.
+	Timeout *Duration `json:"timeout,omitempty"`
+}
+
+// Duration is a time.Duration serialized as a string, i.e. "4h0m0s".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Duration.String())
+}
+
+// Timeout returns the job's timeout, or zero when the spec doesn't set one.
+func (s ProwJobSpec) Timeout() time.Duration {
+	if s.DecorationConfig == nil || s.DecorationConfig.Timeout == nil {
+		return 0
+	}
+	return s.DecorationConfig.Timeout.Duration
 }
 
 type ProwJobStatus struct {
@@ -79,7 +161,22 @@ type ProwJobStatus struct {
 	PrevReportStates map[string]ProwJobState `json:"prev_report_states,omitempty"`
 }
 
+// ClusterProfileLabel is the label ci-operator sets on a job with the cluster profile it leases its cloud account
+// from, i.e. aws-2.
+const ClusterProfileLabel = "ci-operator.openshift.io/cloud-cluster-profile"
+
+// ObjectMeta is the part of a prow job's metadata sippy reads.
+type ObjectMeta struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
 type ProwJob struct {
-	Spec   ProwJobSpec   `json:"spec,omitempty"`
-	Status ProwJobStatus `json:"status,omitempty"`
+	Metadata ObjectMeta    `json:"metadata,omitempty"`
+	Spec     ProwJobSpec   `json:"spec,omitempty"`
+	Status   ProwJobStatus `json:"status,omitempty"`
+}
+
+// ClusterProfile returns the job's cluster profile, or an empty string when it doesn't lease a cloud account.
+func (pj ProwJob) ClusterProfile() string {
+	return pj.Metadata.Labels[ClusterProfileLabel]
 }
@@ -0,0 +1,27 @@
+package prow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProwJobSpecConfigHash(t *testing.T) {
+	hash := func(spec string) string {
+		pj := ProwJob{}
+		require.NoError(t, json.Unmarshal([]byte(`{"spec": `+spec+`}`), &pj))
+		return pj.Spec.ConfigHash
+	}
+
+	original := hash(`{"type": "periodic", "job": "e2e-aws", "cluster": "build01",
+		"pod_spec": {"containers": [{"args": ["--target=e2e-aws"]}]}}`)
+	assert.NotEmpty(t, original)
+	assert.Equal(t, original, hash(`{"job": "e2e-aws", "type": "periodic", "cluster": "build02",
+		"refs": {"org": "openshift", "repo": "origin", "base_ref": "master"},
+		"pod_spec": {"containers": [{"args": ["--target=e2e-aws"]}]}}`),
+		"the build cluster, refs and field order don't change the hash")
+	assert.NotEqual(t, original, hash(`{"type": "periodic", "job": "e2e-aws", "cluster": "build01",
+		"pod_spec": {"containers": [{"args": ["--target=e2e-aws-ovn"]}]}}`))
+}
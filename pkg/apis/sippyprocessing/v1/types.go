@@ -3,6 +3,8 @@
 package v1
 
 import (
+	"time"
+
 	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
 )
 
@@ -164,11 +166,22 @@ type RawJobRunResult struct {
 	// OpenShiftTestsStatus can be "", "Success", "Failure"
 	OpenShiftTestsStatus string
 
+	// HostedControlPlane is true for runs of hosted control planes, i.e. hypershift. Their control plane runs in a
+	// management cluster, so the cluster has no machine config pools to upgrade, and the hosted cluster's creation
+	// reports the install without the operator results other installs are judged by.
+	HostedControlPlane bool
+
 	// Overall result
 	OverallResult JobOverallResult
 
 	// Timestamp
 	Timestamp int
+
+	// Duration is how long the job run took, zero when it is unknown.
+	// Used to create config defined synthetic tests.
+	Duration time.Duration
+	// Artifacts are the paths of the job run's artifacts that matched a synthetic test's artifact rules.
+	Artifacts []string
 }
 
 type OperatorState struct {
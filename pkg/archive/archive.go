@@ -0,0 +1,234 @@
+// Package archive moves the raw data of old job runs out of postgres. Runs and their test results are written to
+// parquet files in object storage, a batch of runs at a time, and deleted from the database, leaving a row per run
+// recording which files hold it, so an archived run can still be read back on demand.
+package archive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	// MinAgeMonths is the youngest job runs can be archived at, older than any report, history or regression reads.
+	MinAgeMonths = 3
+
+	// DefaultBatchSize is the default number of job runs archived to each pair of files.
+	DefaultBatchSize = 100
+)
+
+// Result counts the job runs and test results archived, and the files written, or that would be with a dry run.
+type Result struct {
+	JobRuns     int64 `json:"job_runs"`
+	TestResults int64 `json:"test_results"`
+	Files       int   `json:"files"`
+}
+
+// Run is an archived job run, with its job's name and release, and its test results when read back.
+type Run struct {
+	ID                    uint                               `json:"id"`
+	ProwJobID             uint                               `json:"prow_job_id"`
+	ProwJobName           string                             `json:"prow_job_name"`
+	Release               string                             `json:"release"`
+	Cluster               string                             `json:"cluster"`
+	CloudRegion           string                             `json:"cloud_region"`
+	URL                   string                             `json:"url"`
+	Timestamp             time.Time                          `json:"timestamp"`
+	Duration              time.Duration                      `json:"duration"`
+	OverallResult         sippyprocessingv1.JobOverallResult `json:"overall_result"`
+	Succeeded             bool                               `json:"succeeded"`
+	Failed                bool                               `json:"failed"`
+	InfrastructureFailure bool                               `json:"infrastructure_failure"`
+	KnownFailure          bool                               `json:"known_failure"`
+	TestFailures          int                                `json:"test_failures"`
+	TestFlakes            int                                `json:"test_flakes"`
+	Tests                 []Test                             `json:"tests" gorm:"-"`
+}
+
+// Test is an archived test result, with the names of its test and suite, and its output if it failed.
+type Test struct {
+	ProwJobRunID uint    `json:"-"`
+	TestID       uint    `json:"test_id"`
+	Name         string  `json:"name"`
+	SuiteID      *uint   `json:"suite_id,omitempty"`
+	Suite        string  `json:"suite,omitempty"`
+	Status       int     `json:"status"`
+	Duration     float64 `json:"duration"`
+	RetryFlake   bool    `json:"retry_flake"`
+	Output       string  `json:"output,omitempty"`
+}
+
+const runsQuery = `SELECT prow_job_runs.id, prow_job_runs.prow_job_id, prow_jobs.name AS prow_job_name, prow_jobs.release,
+	prow_job_runs.cluster, prow_job_runs.cloud_region, prow_job_runs.url, prow_job_runs.timestamp, prow_job_runs.duration,
+	prow_job_runs.overall_result, prow_job_runs.succeeded, prow_job_runs.failed,
+	prow_job_runs.infrastructure_failure, prow_job_runs.known_failure, prow_job_runs.test_failures,
+	prow_job_runs.test_flakes
+	FROM prow_job_runs
+	JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+	WHERE prow_job_runs.id IN ?
+	ORDER BY prow_job_runs.id`
+
+const testsQuery = `SELECT prow_job_run_tests.prow_job_run_id, prow_job_run_tests.test_id, tests.name,
+	prow_job_run_tests.suite_id, COALESCE(suites.name, '') AS suite, prow_job_run_tests.status,
+	prow_job_run_tests.duration, prow_job_run_tests.retry_flake,
+	COALESCE(prow_job_run_test_outputs.output, '') AS output
+	FROM prow_job_run_tests
+	JOIN tests ON tests.id = prow_job_run_tests.test_id
+	LEFT JOIN suites ON suites.id = prow_job_run_tests.suite_id
+	LEFT JOIN prow_job_run_test_outputs ON prow_job_run_test_outputs.prow_job_run_test_id = prow_job_run_tests.id
+	WHERE prow_job_run_tests.prow_job_run_id IN ? AND prow_job_run_tests.deleted_at IS NULL
+	ORDER BY prow_job_run_tests.prow_job_run_id, prow_job_run_tests.id`
+
+// Archive writes the job runs older than olderThanMonths, and their test results, to the store, batchSize runs at a
+// time, and deletes them from the database. With dryRun, it only counts what would be archived.
+func Archive(ctx context.Context, dbc *db.DB, store Store, olderThanMonths int, now time.Time, batchSize int,
+	dryRun bool) (*Result, error) {
+	if olderThanMonths < MinAgeMonths {
+		return nil, fmt.Errorf("job runs must be at least %d months old to be archived", MinAgeMonths)
+	}
+	if batchSize < 1 {
+		return nil, fmt.Errorf("batch size must be positive")
+	}
+	cutoff := now.UTC().AddDate(0, -olderThanMonths, 0)
+
+	result := &Result{}
+	if dryRun {
+		res := dbc.DB.WithContext(ctx).Raw(`SELECT COUNT(*) AS job_runs, COALESCE(SUM((
+			SELECT COUNT(*) FROM prow_job_run_tests WHERE prow_job_run_tests.prow_job_run_id = prow_job_runs.id
+			AND prow_job_run_tests.deleted_at IS NULL)), 0) AS test_results
+			FROM prow_job_runs WHERE timestamp < ?`, cutoff).Scan(result)
+		result.Files = 2 * int((result.JobRuns+int64(batchSize)-1)/int64(batchSize))
+		return result, res.Error
+	}
+
+	for {
+		ids := make([]uint, 0, batchSize)
+		res := dbc.DB.WithContext(ctx).Raw(`SELECT id FROM prow_job_runs WHERE timestamp < ? ORDER BY id LIMIT ?`,
+			cutoff, batchSize).Scan(&ids)
+		if res.Error != nil {
+			return result, res.Error
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		tests, err := archiveBatch(ctx, dbc, store, ids)
+		if err != nil {
+			return result, err
+		}
+		result.JobRuns += int64(len(ids))
+		result.TestResults += int64(tests)
+		result.Files += 2
+		log.Infof("archived %d job runs with %d test results", result.JobRuns, result.TestResults)
+	}
+	return result, nil
+}
+
+// archiveBatch writes the runs and their test results to a file each, then records where they were written and
+// deletes them, which cascades to their test results, in one transaction. Files of a batch that fails to be deleted
+// are written again by the next attempt.
+func archiveBatch(ctx context.Context, dbc *db.DB, store Store, ids []uint) (int, error) {
+	runs := make([]Run, 0, len(ids))
+	if res := dbc.DB.WithContext(ctx).Raw(runsQuery, ids).Scan(&runs); res.Error != nil {
+		return 0, res.Error
+	}
+	if len(runs) != len(ids) {
+		return 0, fmt.Errorf("found %d of %d job runs to archive", len(runs), len(ids))
+	}
+	tests := make([]Test, 0)
+	if res := dbc.DB.WithContext(ctx).Raw(testsQuery, ids).Scan(&tests); res.Error != nil {
+		return 0, res.Error
+	}
+
+	runsData, err := encodeRuns(runs)
+	if err != nil {
+		return 0, err
+	}
+	testsData, err := encodeTests(tests)
+	if err != nil {
+		return 0, err
+	}
+	batch := fmt.Sprintf("%s/%d-%d.parquet", runs[0].Timestamp.UTC().Format("2006-01"), ids[0], ids[len(ids)-1])
+	runsObject, testsObject := "prow_job_runs/"+batch, "prow_job_run_tests/"+batch
+	if err := store.Put(ctx, runsObject, runsData); err != nil {
+		return 0, fmt.Errorf("couldn't write %s: %v", runsObject, err)
+	}
+	if err := store.Put(ctx, testsObject, testsData); err != nil {
+		return 0, fmt.Errorf("couldn't write %s: %v", testsObject, err)
+	}
+
+	archived := make([]models.ArchivedProwJobRun, len(runs))
+	now := time.Now()
+	for i, run := range runs {
+		archived[i] = models.ArchivedProwJobRun{
+			ID:          run.ID,
+			ProwJobID:   run.ProwJobID,
+			Timestamp:   run.Timestamp,
+			ArchivedAt:  now,
+			RunsObject:  runsObject,
+			TestsObject: testsObject,
+		}
+	}
+	err = dbc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if res := tx.CreateInBatches(archived, dbc.BatchSize); res.Error != nil {
+			return res.Error
+		}
+		return tx.Unscoped().Delete(&models.ProwJobRun{}, ids).Error
+	})
+	return len(tests), err
+}
+
+// Get reads the archived job run with its test results back from the store, or returns nil if the run isn't
+// archived.
+func Get(ctx context.Context, dbc *db.DB, store Store, id uint) (*Run, error) {
+	archived := models.ArchivedProwJobRun{}
+	res := dbc.DB.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&archived)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	data, err := store.Get(ctx, archived.RunsObject)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %v", archived.RunsObject, err)
+	}
+	runs, err := decodeRuns(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode %s: %v", archived.RunsObject, err)
+	}
+	var run *Run
+	for i := range runs {
+		if runs[i].ID == id {
+			run = &runs[i]
+			break
+		}
+	}
+	if run == nil {
+		return nil, fmt.Errorf("job run %d is missing from %s", id, archived.RunsObject)
+	}
+
+	data, err = store.Get(ctx, archived.TestsObject)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %v", archived.TestsObject, err)
+	}
+	tests, err := decodeTests(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode %s: %v", archived.TestsObject, err)
+	}
+	run.Tests = make([]Test, 0)
+	for _, test := range tests {
+		if test.ProwJobRunID == id {
+			run.Tests = append(run.Tests, test)
+		}
+	}
+	return run, nil
+}
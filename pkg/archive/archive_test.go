@@ -0,0 +1,88 @@
+package archive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func TestArchiveValidatesArguments(t *testing.T) {
+	_, err := Archive(context.Background(), nil, nil, 1, time.Now(), DefaultBatchSize, true)
+	assert.EqualError(t, err, "job runs must be at least 3 months old to be archived")
+
+	_, err = Archive(context.Background(), nil, nil, 6, time.Now(), 0, true)
+	assert.EqualError(t, err, "batch size must be positive")
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	runs := []Run{
+		{
+			ID:            1001,
+			ProwJobID:     7,
+			ProwJobName:   "periodic-ci-openshift-release-master-ci-4.14-e2e-aws-ovn",
+			Release:       "4.14",
+			Cluster:       "build01",
+			CloudRegion:   "us-east-1",
+			URL:           "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/job/1001",
+			Timestamp:     time.Date(2023, 1, 2, 3, 4, 5, 6000, time.UTC),
+			Duration:      90 * time.Minute,
+			OverallResult: sippyprocessingv1.JobTestFailure,
+			Failed:        true,
+			TestFailures:  1,
+		},
+		{ID: 1002, ProwJobID: 7, Succeeded: true, OverallResult: sippyprocessingv1.JobSucceeded},
+	}
+	data, err := encodeRuns(runs)
+	require.NoError(t, err)
+	decodedRuns, err := decodeRuns(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, runs[0], decodedRuns[0])
+	assert.Equal(t, runs[1].ID, decodedRuns[1].ID)
+	assert.True(t, decodedRuns[1].Succeeded)
+
+	suiteID := uint(3)
+	tests := []Test{
+		{ProwJobRunID: 1001, TestID: 10, Name: "failed test", SuiteID: &suiteID, Suite: "openshift-tests", Status: 12,
+			Duration: 1.5, Output: "timed out"},
+		{ProwJobRunID: 1001, TestID: 11, Name: "unsuited test", Status: 1},
+	}
+	data, err = encodeTests(tests)
+	require.NoError(t, err)
+	decodedTests, err := decodeTests(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, tests, decodedTests)
+}
+
+func TestDirStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewStore(t.TempDir(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "prow_job_runs/2023-01/1-2.parquet", []byte("data")))
+	data, err := store.Get(ctx, "prow_job_runs/2023-01/1-2.parquet")
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	_, err = store.Get(ctx, "missing.parquet")
+	assert.Error(t, err)
+}
+
+func TestNewStore(t *testing.T) {
+	_, err := NewStore("", nil)
+	assert.EqualError(t, err, "archive location is required")
+
+	_, err = NewStore("gs://bucket/prefix", nil)
+	assert.EqualError(t, err, `a GCS client is required for archive location "gs://bucket/prefix"`)
+
+	_, err = NewStore("gs:///prefix", nil)
+	assert.EqualError(t, err, `archive location "gs:///prefix" has no bucket`)
+
+	_, err = NewStore("s3://bucket", nil)
+	assert.EqualError(t, err, `unsupported archive location "s3://bucket", expected gs://bucket/prefix or a directory`)
+}
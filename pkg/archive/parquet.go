@@ -0,0 +1,271 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/compress"
+	"github.com/apache/arrow/go/v12/parquet/file"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+var timestampType = &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+
+// runsSchema and testsSchema are the columns of the archive's files, which are read back by name.
+var runsSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "prow_job_id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "prow_job_name", Type: arrow.BinaryTypes.String},
+	{Name: "release", Type: arrow.BinaryTypes.String},
+	{Name: "cluster", Type: arrow.BinaryTypes.String},
+	{Name: "url", Type: arrow.BinaryTypes.String},
+	{Name: "timestamp", Type: timestampType},
+	{Name: "duration", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "overall_result", Type: arrow.BinaryTypes.String},
+	{Name: "succeeded", Type: arrow.FixedWidthTypes.Boolean},
+	{Name: "failed", Type: arrow.FixedWidthTypes.Boolean},
+	{Name: "infrastructure_failure", Type: arrow.FixedWidthTypes.Boolean},
+	{Name: "known_failure", Type: arrow.FixedWidthTypes.Boolean},
+	{Name: "test_failures", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "test_flakes", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "cloud_region", Type: arrow.BinaryTypes.String},
+}, nil)
+
+var testsSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "prow_job_run_id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "test_id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "name", Type: arrow.BinaryTypes.String},
+	{Name: "suite_id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "suite", Type: arrow.BinaryTypes.String},
+	{Name: "status", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "duration", Type: arrow.PrimitiveTypes.Float64},
+	{Name: "retry_flake", Type: arrow.FixedWidthTypes.Boolean},
+	{Name: "output", Type: arrow.BinaryTypes.String},
+}, nil)
+
+func encodeRuns(runs []Run) ([]byte, error) {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, runsSchema)
+	defer b.Release()
+	for _, run := range runs {
+		b.Field(0).(*array.Int64Builder).Append(int64(run.ID))
+		b.Field(1).(*array.Int64Builder).Append(int64(run.ProwJobID))
+		b.Field(2).(*array.StringBuilder).Append(run.ProwJobName)
+		b.Field(3).(*array.StringBuilder).Append(run.Release)
+		b.Field(4).(*array.StringBuilder).Append(run.Cluster)
+		b.Field(5).(*array.StringBuilder).Append(run.URL)
+		b.Field(6).(*array.TimestampBuilder).Append(arrow.Timestamp(run.Timestamp.UnixMicro()))
+		b.Field(7).(*array.Int64Builder).Append(int64(run.Duration))
+		b.Field(8).(*array.StringBuilder).Append(string(run.OverallResult))
+		b.Field(9).(*array.BooleanBuilder).Append(run.Succeeded)
+		b.Field(10).(*array.BooleanBuilder).Append(run.Failed)
+		b.Field(11).(*array.BooleanBuilder).Append(run.InfrastructureFailure)
+		b.Field(12).(*array.BooleanBuilder).Append(run.KnownFailure)
+		b.Field(13).(*array.Int64Builder).Append(int64(run.TestFailures))
+		b.Field(14).(*array.Int64Builder).Append(int64(run.TestFlakes))
+		b.Field(15).(*array.StringBuilder).Append(run.CloudRegion)
+	}
+	return encode(runsSchema, b.NewRecord())
+}
+
+func encodeTests(tests []Test) ([]byte, error) {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, testsSchema)
+	defer b.Release()
+	for _, test := range tests {
+		b.Field(0).(*array.Int64Builder).Append(int64(test.ProwJobRunID))
+		b.Field(1).(*array.Int64Builder).Append(int64(test.TestID))
+		b.Field(2).(*array.StringBuilder).Append(test.Name)
+		if test.SuiteID != nil {
+			b.Field(3).(*array.Int64Builder).Append(int64(*test.SuiteID))
+		} else {
+			b.Field(3).AppendNull()
+		}
+		b.Field(4).(*array.StringBuilder).Append(test.Suite)
+		b.Field(5).(*array.Int64Builder).Append(int64(test.Status))
+		b.Field(6).(*array.Float64Builder).Append(test.Duration)
+		b.Field(7).(*array.BooleanBuilder).Append(test.RetryFlake)
+		b.Field(8).(*array.StringBuilder).Append(test.Output)
+	}
+	return encode(testsSchema, b.NewRecord())
+}
+
+// encode writes the record to a zstd compressed parquet file.
+func encode(schema *arrow.Schema, rec arrow.Record) ([]byte, error) {
+	defer rec.Release()
+	buf := &bytes.Buffer{}
+	w, err := pqarrow.NewFileWriter(schema, buf,
+		parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Zstd)),
+		pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Write(rec); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeRuns(ctx context.Context, data []byte) ([]Run, error) {
+	runs := make([]Run, 0)
+	err := decode(ctx, data, func(rec arrow.Record) error {
+		cols := columns{rec: rec}
+		id := column[*array.Int64](&cols, "id")
+		prowJobID := column[*array.Int64](&cols, "prow_job_id")
+		prowJobName := column[*array.String](&cols, "prow_job_name")
+		release := column[*array.String](&cols, "release")
+		cluster := column[*array.String](&cols, "cluster")
+		url := column[*array.String](&cols, "url")
+		timestamp := column[*array.Timestamp](&cols, "timestamp")
+		duration := column[*array.Int64](&cols, "duration")
+		overallResult := column[*array.String](&cols, "overall_result")
+		succeeded := column[*array.Boolean](&cols, "succeeded")
+		failed := column[*array.Boolean](&cols, "failed")
+		infrastructureFailure := column[*array.Boolean](&cols, "infrastructure_failure")
+		knownFailure := column[*array.Boolean](&cols, "known_failure")
+		testFailures := column[*array.Int64](&cols, "test_failures")
+		testFlakes := column[*array.Int64](&cols, "test_flakes")
+		// archived before runs recorded their cloud region
+		cloudRegion := optionalColumn[*array.String](&cols, "cloud_region")
+		if cols.err != nil {
+			return cols.err
+		}
+
+		for i := 0; i < int(rec.NumRows()); i++ {
+			runs = append(runs, Run{
+				ID:                    uint(id.Value(i)),
+				ProwJobID:             uint(prowJobID.Value(i)),
+				ProwJobName:           prowJobName.Value(i),
+				Release:               release.Value(i),
+				Cluster:               cluster.Value(i),
+				URL:                   url.Value(i),
+				Timestamp:             time.UnixMicro(int64(timestamp.Value(i))).UTC(),
+				Duration:              time.Duration(duration.Value(i)),
+				OverallResult:         sippyprocessingv1.JobOverallResult(overallResult.Value(i)),
+				Succeeded:             succeeded.Value(i),
+				Failed:                failed.Value(i),
+				InfrastructureFailure: infrastructureFailure.Value(i),
+				KnownFailure:          knownFailure.Value(i),
+				TestFailures:          int(testFailures.Value(i)),
+				TestFlakes:            int(testFlakes.Value(i)),
+			})
+			if cloudRegion != nil {
+				runs[len(runs)-1].CloudRegion = cloudRegion.Value(i)
+			}
+		}
+		return nil
+	})
+	return runs, err
+}
+
+func decodeTests(ctx context.Context, data []byte) ([]Test, error) {
+	tests := make([]Test, 0)
+	err := decode(ctx, data, func(rec arrow.Record) error {
+		cols := columns{rec: rec}
+		prowJobRunID := column[*array.Int64](&cols, "prow_job_run_id")
+		testID := column[*array.Int64](&cols, "test_id")
+		name := column[*array.String](&cols, "name")
+		suiteID := column[*array.Int64](&cols, "suite_id")
+		suite := column[*array.String](&cols, "suite")
+		status := column[*array.Int64](&cols, "status")
+		duration := column[*array.Float64](&cols, "duration")
+		retryFlake := column[*array.Boolean](&cols, "retry_flake")
+		output := column[*array.String](&cols, "output")
+		if cols.err != nil {
+			return cols.err
+		}
+
+		for i := 0; i < int(rec.NumRows()); i++ {
+			test := Test{
+				ProwJobRunID: uint(prowJobRunID.Value(i)),
+				TestID:       uint(testID.Value(i)),
+				Name:         name.Value(i),
+				Suite:        suite.Value(i),
+				Status:       int(status.Value(i)),
+				Duration:     duration.Value(i),
+				RetryFlake:   retryFlake.Value(i),
+				Output:       output.Value(i),
+			}
+			if suiteID.IsValid(i) {
+				id := uint(suiteID.Value(i))
+				test.SuiteID = &id
+			}
+			tests = append(tests, test)
+		}
+		return nil
+	})
+	return tests, err
+}
+
+// decode reads a parquet file, passing its records to fn.
+func decode(ctx context.Context, data []byte, fn func(arrow.Record) error) error {
+	pf, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		return err
+	}
+	table, err := fr.ReadTable(ctx)
+	if err != nil {
+		return err
+	}
+	defer table.Release()
+
+	tr := array.NewTableReader(table, 0)
+	defer tr.Release()
+	for tr.Next() {
+		if err := fn(tr.Record()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columns looks up a record's columns by name, keeping the first error so they can all be checked at once.
+type columns struct {
+	rec arrow.Record
+	err error
+}
+
+func column[T arrow.Array](cols *columns, name string) T {
+	var col T
+	if cols.err != nil {
+		return col
+	}
+	if !cols.rec.Schema().HasField(name) {
+		cols.err = fmt.Errorf("column %s is missing", name)
+		return col
+	}
+	return optionalColumn[T](cols, name)
+}
+
+// optionalColumn is like column, but returns nil when the column is missing, for columns added to the archive after
+// files were written without them.
+func optionalColumn[T arrow.Array](cols *columns, name string) T {
+	var col T
+	if cols.err != nil {
+		return col
+	}
+	indices := cols.rec.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return col
+	}
+	col, ok := cols.rec.Column(indices[0]).(T)
+	if !ok {
+		cols.err = fmt.Errorf("column %s has unexpected type %s", name, cols.rec.Column(indices[0]).DataType())
+	}
+	return col
+}
@@ -0,0 +1,81 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+)
+
+// Store holds the archive's files.
+type Store interface {
+	// Put writes the named file, replacing it if it exists.
+	Put(ctx context.Context, name string, data []byte) error
+	// Get reads the named file.
+	Get(ctx context.Context, name string) ([]byte, error)
+}
+
+// NewStore returns the store at location, either a GCS bucket and optional prefix as gs://bucket/prefix, which is
+// read and written with the client, or a local directory.
+func NewStore(location string, client *storage.Client) (Store, error) {
+	if location == "" {
+		return nil, fmt.Errorf("archive location is required")
+	}
+	if strings.HasPrefix(location, "gs://") {
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(location, "gs://"), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("archive location %q has no bucket", location)
+		}
+		if client == nil {
+			return nil, fmt.Errorf("a GCS client is required for archive location %q", location)
+		}
+		return &gcsStore{bucket: client.Bucket(bucket), prefix: prefix}, nil
+	}
+	if strings.Contains(location, "://") {
+		return nil, fmt.Errorf("unsupported archive location %q, expected gs://bucket/prefix or a directory", location)
+	}
+	return dirStore(location), nil
+}
+
+type gcsStore struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+func (s *gcsStore) Put(ctx context.Context, name string, data []byte) error {
+	w := s.bucket.Object(path.Join(s.prefix, name)).NewWriter(ctx)
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (s *gcsStore) Get(ctx context.Context, name string) ([]byte, error) {
+	r, err := s.bucket.Object(path.Join(s.prefix, name)).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// dirStore keeps the archive in a local directory, for development.
+type dirStore string
+
+func (s dirStore) Put(_ context.Context, name string, data []byte) error {
+	file := filepath.Join(string(s), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644) //nolint:gosec
+}
+
+func (s dirStore) Get(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(string(s), filepath.FromSlash(name)))
+}
@@ -0,0 +1,118 @@
+// Package memory is an in-memory cache, used on its own or as the first tier in front of a shared cache such as redis,
+// so each replica answers its most requested reports without a round trip.
+package memory
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/openshift/sippy/pkg/apis/cache"
+)
+
+// promotedTTL is how long an entry found in the next tier is kept in memory, the next tier doesn't say when it expires.
+const promotedTTL = 10 * time.Minute
+
+// ErrMiss is returned by Get for keys that aren't cached.
+var ErrMiss = errors.New("not cached")
+
+type entry struct {
+	content []byte
+	expires time.Time
+}
+
+type Cache struct {
+	lock       sync.Mutex
+	entries    map[string]entry
+	maxEntries int
+	next       cache.Cache
+	now        func() time.Time
+}
+
+// NewCache returns a cache holding up to maxEntries in memory, in front of next if it isn't nil. When full, the entry
+// closest to expiring is evicted.
+func NewCache(maxEntries int, next cache.Cache) *Cache {
+	return &Cache{
+		entries:    map[string]entry{},
+		maxEntries: maxEntries,
+		next:       next,
+		now:        time.Now,
+	}
+}
+
+func (c *Cache) Get(key string) ([]byte, error) {
+	c.lock.Lock()
+	e, ok := c.entries[key]
+	if ok && !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.lock.Unlock()
+	if ok {
+		return e.content, nil
+	}
+
+	if c.next == nil {
+		return nil, ErrMiss
+	}
+	content, err := c.next.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	c.set(key, content, promotedTTL)
+	return content, nil
+}
+
+func (c *Cache) Set(key string, content []byte, duration time.Duration) error {
+	c.set(key, content, duration)
+	if c.next != nil {
+		return c.next.Set(key, content, duration)
+	}
+	return nil
+}
+
+// Clear empties the in-memory tier, the next tier is left alone.
+func (c *Cache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = map[string]entry{}
+}
+
+// Len returns the number of entries in memory, including expired ones not yet evicted.
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache) set(key string, content []byte, duration time.Duration) {
+	if c.maxEntries < 1 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = entry{content: content, expires: now.Add(duration)}
+}
+
+// evict removes the expired entries, or if there are none, the one closest to expiring.
+func (c *Cache) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || e.expires.Before(oldest) {
+			oldestKey, oldest = key, e.expires
+		}
+	}
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mapCache map[string][]byte
+
+func (m mapCache) Get(key string) ([]byte, error) {
+	if content, ok := m[key]; ok {
+		return content, nil
+	}
+	return nil, errors.New("redis: nil")
+}
+
+func (m mapCache) Set(key string, content []byte, _ time.Duration) error {
+	m[key] = content
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCache(2, nil)
+	c.now = func() time.Time { return now }
+
+	_, err := c.Get("a")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, c.Set("a", []byte("1"), time.Hour))
+	require.NoError(t, c.Set("b", []byte("2"), 2*time.Hour))
+	content, err := c.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(content))
+
+	// full, so the entry closest to expiring is evicted
+	require.NoError(t, c.Set("c", []byte("3"), 3*time.Hour))
+	assert.Equal(t, 2, c.Len())
+	_, err = c.Get("a")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	now = now.Add(2 * time.Hour)
+	_, err = c.Get("b")
+	assert.ErrorIs(t, err, ErrMiss, "expired entries aren't returned")
+	content, err = c.Get("c")
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(content))
+
+	c.Clear()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCacheTiers(t *testing.T) {
+	next := mapCache{"shared": []byte("from redis")}
+	c := NewCache(10, next)
+
+	content, err := c.Get("shared")
+	require.NoError(t, err)
+	assert.Equal(t, "from redis", string(content))
+	assert.Equal(t, 1, c.Len(), "entries found in the next tier are kept in memory")
+
+	require.NoError(t, c.Set("report", []byte("{}"), time.Hour))
+	assert.Equal(t, "{}", string(next["report"]), "sets write through to the next tier")
+
+	c.Clear()
+	_, err = c.Get("report")
+	assert.NoError(t, err, "clearing memory leaves the next tier alone")
+
+	_, err = c.Get("missing")
+	assert.EqualError(t, err, "redis: nil")
+}
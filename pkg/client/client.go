@@ -0,0 +1,325 @@
+// Package client is a Go client for the sippy API, so tools reading sippy's reports don't each build their own
+// requests. Requests are retried when the server is unavailable or overloaded, and are cancelled with their context.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+const (
+	defaultTimeout    = time.Minute
+	defaultMaxRetries = 3
+	defaultRetryWait  = time.Second
+
+	// maxRetryWait caps the wait between retries, including what the server asks for with Retry-After.
+	maxRetryWait = time.Minute
+
+	// maxErrorBytes caps how much of an error response is read.
+	maxErrorBytes = 64 * 1024
+)
+
+// Options configures a client. The zero value is a client with the defaults.
+type Options struct {
+	// HTTPClient sends the requests, a client with a one minute timeout when nil.
+	HTTPClient *http.Client
+
+	// Token is sent as a bearer token, for instances behind an authenticating proxy.
+	Token string
+
+	// Tenant scopes every request to a tenant of a multi-tenant instance.
+	Tenant string
+
+	// MaxRetries is how many times a request is retried after a network error or a 429 or 5xx response, 3 when zero.
+	// Negative disables retries.
+	MaxRetries int
+
+	// RetryWait is the wait before the first retry, doubling for each retry after it, one second when zero.
+	RetryWait time.Duration
+}
+
+// Client queries a sippy instance's API.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	tenant     string
+	maxRetries int
+	retryWait  time.Duration
+}
+
+// APIError is an error response from the API. Type and RequestID are empty when the response isn't an API problem,
+// i.e. when it came from a proxy in front of sippy.
+type APIError struct {
+	StatusCode int
+	Type       api.ProblemType
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("sippy returned status %d: %s (request %s)", e.StatusCode, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("sippy returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// New returns a client for the sippy instance at baseURL, i.e. https://sippy.dptools.openshift.org.
+func New(baseURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sippy URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("sippy URL %q must be an http or https URL", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: opts.HTTPClient,
+		token:      opts.Token,
+		tenant:     opts.Tenant,
+		maxRetries: opts.MaxRetries,
+		retryWait:  opts.RetryWait,
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = defaultMaxRetries
+	} else if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.retryWait <= 0 {
+		c.retryWait = defaultRetryWait
+	}
+	return c, nil
+}
+
+// ListOptions filters, sorts and limits the rows of a report. The zero value returns the report's rows in its default
+// order.
+type ListOptions struct {
+	Filter    *filter.Filter
+	SortField string
+	Sort      apitype.Sort
+	Limit     int
+}
+
+func (o *ListOptions) values(release string) (url.Values, error) {
+	values := url.Values{}
+	if release != "" {
+		values.Set("release", release)
+	}
+	if o == nil {
+		return values, nil
+	}
+	if o.Filter != nil && len(o.Filter.Items) > 0 {
+		f, err := json.Marshal(o.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding filter: %w", err)
+		}
+		values.Set("filter", string(f))
+	}
+	if o.SortField != "" {
+		values.Set("sortField", o.SortField)
+	}
+	if o.Sort != "" {
+		values.Set("sort", string(o.Sort))
+	}
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return values, nil
+}
+
+// Tests returns the release's test report, comparing the last week to the week before.
+func (c *Client) Tests(ctx context.Context, release string, opts *ListOptions) ([]apitype.Test, error) {
+	values, err := opts.values(release)
+	if err != nil {
+		return nil, err
+	}
+	tests := make([]apitype.Test, 0)
+	return tests, c.get(ctx, "/api/tests", values, &tests)
+}
+
+// Jobs returns the release's job report, comparing the last week to the week before.
+func (c *Client) Jobs(ctx context.Context, release string, opts *ListOptions) ([]apitype.Job, error) {
+	values, err := opts.values(release)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]apitype.Job, 0)
+	return jobs, c.get(ctx, "/api/jobs", values, &jobs)
+}
+
+// JobRunsPage is a page of job runs, and how many there are in all.
+type JobRunsPage struct {
+	Rows      []apitype.JobRun `json:"rows"`
+	PageSize  int              `json:"page_size"`
+	Page      int              `json:"page"`
+	TotalRows int64            `json:"total_rows"`
+}
+
+// JobRuns returns a page of the release's job runs, the latest first unless sorted otherwise, or all of them when
+// pagination is nil. An empty release returns the runs of every release.
+func (c *Client) JobRuns(ctx context.Context, release string, opts *ListOptions,
+	pagination *apitype.Pagination) (*JobRunsPage, error) {
+	values, err := opts.values(release)
+	if err != nil {
+		return nil, err
+	}
+	if pagination != nil {
+		values.Set("perPage", strconv.Itoa(pagination.PerPage))
+		values.Set("page", strconv.Itoa(pagination.Page))
+	}
+	page := &JobRunsPage{}
+	if err := c.get(ctx, "/api/jobs/runs", values, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Payload is a release payload, with the names of its failed blocking jobs.
+type Payload struct {
+	models.ReleaseTag
+	FailedJobNames []string `json:"failed_job_names,omitempty"`
+}
+
+// Payloads returns the release's payloads, the latest first unless sorted otherwise.
+func (c *Client) Payloads(ctx context.Context, release string, opts *ListOptions) ([]Payload, error) {
+	values, err := opts.values(release)
+	if err != nil {
+		return nil, err
+	}
+	payloads := make([]Payload, 0)
+	return payloads, c.get(ctx, "/api/releases/tags", values, &payloads)
+}
+
+// PayloadGate returns the verdict on whether the payload, i.e. 4.16.0-0.nightly-2024-03-14-123456, can be accepted.
+func (c *Client) PayloadGate(ctx context.Context, releaseTag string) (*apitype.PayloadGate, error) {
+	gate := &apitype.PayloadGate{}
+	if err := c.get(ctx, "/api/payloads/gate", url.Values{"release_tag": {releaseTag}}, gate); err != nil {
+		return nil, err
+	}
+	return gate, nil
+}
+
+// Regressions returns the release's test regressions with the status, models.TestRegressionOpen or
+// models.TestRegressionClosed, or all of them when status is empty, the most severe first.
+func (c *Client) Regressions(ctx context.Context, release, status string) ([]models.TestRegression, error) {
+	values := url.Values{"release": {release}}
+	if status != "" {
+		values.Set("status", status)
+	}
+	regressions := make([]models.TestRegression, 0)
+	return regressions, c.get(ctx, "/api/tests/regressions", values, &regressions)
+}
+
+// get decodes the JSON response to a GET of the API path into out, retrying when the request may succeed later.
+func (c *Client) get(ctx context.Context, path string, values url.Values, out interface{}) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = values.Encode()
+
+	wait := c.retryWait
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.do(ctx, u.String(), out)
+		if err == nil || attempt >= c.maxRetries || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// do sends one request, returning how long the server asked to wait before retrying, if it did.
+func (c *Client) do(ctx context.Context, u string, out interface{}) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.tenant != "" {
+		req.Header.Set(api.TenantHeader, c.tenant)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		return retryAfter(resp.Header.Get("Retry-After")), newAPIError(resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("error decoding response from %s: %w", req.URL.Path, err)
+	}
+	return 0, nil
+}
+
+// retryable returns true for errors a later request may not get: network errors, and 429 and 5xx responses other than
+// 501.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests ||
+			(apiErr.StatusCode >= 500 && apiErr.StatusCode != http.StatusNotImplemented)
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// retryAfter parses a Retry-After header given in seconds, the form sippy and the proxies in front of it use.
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// newAPIError returns the error for a response, with the problem's details if it's an API problem, or the start of
+// the body if it isn't.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	problem := api.Problem{}
+	if err := json.Unmarshal(body, &problem); err == nil && problem.Type != "" {
+		apiErr.Type, apiErr.Message, apiErr.RequestID = problem.Type, problem.Error(), problem.RequestID
+		return apiErr
+	}
+	if len(body) > 200 {
+		body = body[:200]
+	}
+	apiErr.Message = strings.TrimSpace(string(body))
+	return apiErr
+}
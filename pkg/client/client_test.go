@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts Options) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	if opts.RetryWait == 0 {
+		opts.RetryWait = time.Millisecond
+	}
+	c, err := New(server.URL+"/", opts)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("sippy.example.com", Options{})
+	assert.Error(t, err)
+
+	c, err := New("https://sippy.example.com/", Options{MaxRetries: -1})
+	require.NoError(t, err)
+	assert.Equal(t, "https://sippy.example.com", c.baseURL.String())
+	assert.Zero(t, c.maxRetries)
+	assert.Equal(t, defaultRetryWait, c.retryWait)
+}
+
+func TestTests(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/tests", req.URL.Path)
+		assert.Equal(t, "4.16", req.URL.Query().Get("release"))
+		assert.Equal(t, "current_pass_percentage", req.URL.Query().Get("sortField"))
+		assert.Equal(t, "asc", req.URL.Query().Get("sort"))
+		assert.Equal(t, "10", req.URL.Query().Get("limit"))
+		assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+		assert.Equal(t, "okd", req.Header.Get(api.TenantHeader))
+
+		f := filter.Filter{}
+		require.NoError(t, json.Unmarshal([]byte(req.URL.Query().Get("filter")), &f))
+		assert.Equal(t, "name", f.Items[0].Field)
+
+		api.RespondWithJSON(http.StatusOK, w, []apitype.Test{{ID: 1, Name: "[sig-network] pods should be reachable"}})
+	}, Options{Token: "secret", Tenant: "okd"})
+
+	tests, err := c.Tests(context.Background(), "4.16", &ListOptions{
+		Filter:    &filter.Filter{Items: []filter.FilterItem{{Field: "name", Operator: filter.OperatorContains, Value: "sig-network"}}},
+		SortField: "current_pass_percentage",
+		Sort:      apitype.SortAscending,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, tests, 1)
+	assert.Equal(t, "[sig-network] pods should be reachable", tests[0].Name)
+}
+
+func TestJobRuns(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/jobs/runs", req.URL.Path)
+		assert.Empty(t, req.URL.Query().Get("release"))
+		assert.Empty(t, req.URL.Query().Get("filter"))
+		assert.Equal(t, "50", req.URL.Query().Get("perPage"))
+		assert.Equal(t, "2", req.URL.Query().Get("page"))
+		api.RespondWithJSON(http.StatusOK, w, apitype.PaginationResult{
+			Rows:      []apitype.JobRun{{ID: 1750000000000000000, Job: "periodic-e2e"}},
+			PageSize:  50,
+			Page:      2,
+			TotalRows: 101,
+		})
+	}, Options{})
+
+	page, err := c.JobRuns(context.Background(), "", nil, &apitype.Pagination{PerPage: 50, Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), page.TotalRows)
+	require.Len(t, page.Rows, 1)
+	assert.Equal(t, 1750000000000000000, page.Rows[0].ID)
+}
+
+func TestRegressions(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/tests/regressions", req.URL.Path)
+		assert.Equal(t, models.TestRegressionOpen, req.URL.Query().Get("status"))
+		api.RespondWithJSON(http.StatusOK, w, []models.TestRegression{{Release: "4.16", TestName: "test", Severity: 10}})
+	}, Options{})
+
+	regressions, err := c.Regressions(context.Background(), "4.16", models.TestRegressionOpen)
+	require.NoError(t, err)
+	require.Len(t, regressions, 1)
+	assert.Equal(t, 10.0, regressions[0].Severity)
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		requests   int32
+		status     int
+	}{
+		{name: "succeeds after unavailable", statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}, requests: 3},
+		{name: "succeeds after too many requests", statuses: []int{http.StatusTooManyRequests}, requests: 2},
+		{name: "gives up after max retries", statuses: []int{500, 500, 500, 500}, maxRetries: 2, requests: 3, status: 500},
+		{name: "doesn't retry bad requests", statuses: []int{http.StatusBadRequest}, requests: 1, status: 400},
+		{name: "doesn't retry not implemented", statuses: []int{http.StatusNotImplemented}, requests: 1, status: 501},
+		{name: "retries disabled", statuses: []int{http.StatusServiceUnavailable}, maxRetries: -1, requests: 1, status: 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				if int(n) <= len(tt.statuses) {
+					api.RespondWithError(tt.statuses[n-1], w, "try again")
+					return
+				}
+				api.RespondWithJSON(http.StatusOK, w, []apitype.Job{{Name: "periodic-e2e"}})
+			}, Options{MaxRetries: tt.maxRetries})
+
+			jobs, err := c.Jobs(context.Background(), "4.16", nil)
+			assert.Equal(t, tt.requests, atomic.LoadInt32(&requests))
+			if tt.status == 0 {
+				require.NoError(t, err)
+				assert.Len(t, jobs, 1)
+				return
+			}
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr), "unexpected error %v", err)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, api.ProblemTypeForStatus(tt.status), apiErr.Type)
+			assert.Contains(t, apiErr.Message, "try again")
+		})
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		cancel()
+		w.Header().Set("Retry-After", "30")
+		api.RespondWithError(http.StatusServiceUnavailable, w, "down for maintenance")
+	}, Options{})
+
+	start := time.Now()
+	_, err := c.Payloads(ctx, "4.16", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestNewAPIError(t *testing.T) {
+	err := newAPIError(http.StatusBadGateway, []byte("<html>bad gateway</html>\n"))
+	assert.Equal(t, "<html>bad gateway</html>", err.Message)
+	assert.Empty(t, err.Type)
+
+	problem, _ := json.Marshal(api.Problem{Type: api.ProblemTypeNotFound, Title: "Not found", Status: 404,
+		Detail: "payload 4.16.0 not found", RequestID: "abc"})
+	err = newAPIError(http.StatusNotFound, problem)
+	assert.Equal(t, api.ProblemTypeNotFound, err.Type)
+	assert.Equal(t, "sippy returned status 404: Not found: payload 4.16.0 not found (request abc)", err.Error())
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryAfter("30"))
+	assert.Zero(t, retryAfter(""))
+	assert.Zero(t, retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
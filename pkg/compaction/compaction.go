@@ -0,0 +1,163 @@
+// Package compaction downsamples the test results of old job runs. A run has thousands of test results, nearly all of
+// them passes, which reports stop reading once the run is older than two weeks and its week's history has been
+// recorded. Compaction collapses the passing results of each day into a result per test and job, and keeps failed and
+// flaky results, with their outputs, as they are.
+//
+// Reports that read the per-run results of compacted days would only see their failures, so they must either sum the
+// daily results of those days, as the release readiness basis does, or refuse to read before CompactedThrough, as the
+// test diff report does.
+package compaction
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	day = 24 * time.Hour
+
+	// MinAge is the youngest job runs can be compacted at. The materialized views read every test result of the last
+	// two weeks, and the weekly history re-records the latest week, so this leaves a margin for both.
+	MinAge = 28 * day
+)
+
+// compactedRows selects the test results of the day's runs that are compacted, i.e. those that didn't fail or flake.
+const compactedRows = `
+	FROM prow_job_run_tests
+	JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+	JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+	WHERE prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+	AND prow_job_run_tests.status NOT IN (@failure, @flake)
+	AND prow_job_run_tests.deleted_at IS NULL`
+
+// Result counts the days compacted, the test results removed and the daily results that replaced them, or that would
+// be with a dry run.
+type Result struct {
+	Days         int   `json:"days"`
+	TestResults  int64 `json:"test_results"`
+	DailyResults int64 `json:"daily_results"`
+}
+
+// Compact collapses the passing test results of job runs older than olderThan into daily results, a day at a time.
+// Days in or after the latest week of recorded history are left alone, so the history is never recorded from
+// compacted runs. With dryRun, it only counts what would be compacted.
+func Compact(dbc *db.DB, olderThan time.Duration, now time.Time, dryRun bool) (*Result, error) {
+	if olderThan < MinAge {
+		return nil, fmt.Errorf("job runs must be at least %d days old to be compacted", int(MinAge/day))
+	}
+
+	cutoff := now.UTC().Add(-olderThan).Truncate(day)
+	latest := models.TestWeeklyResult{}
+	if res := dbc.DB.Order("week DESC").Limit(1).Find(&latest); res.Error != nil {
+		return nil, res.Error
+	}
+	if latest.ID == 0 {
+		return nil, fmt.Errorf("no weekly history has been recorded yet, refresh the data before compacting")
+	}
+	if latest.Week.Before(cutoff) {
+		cutoff = latest.Week.UTC().Truncate(day)
+	}
+
+	var oldest *time.Time
+	res := dbc.DB.Raw(`SELECT MIN(prow_job_runs.timestamp) FROM prow_job_runs
+		WHERE prow_job_runs.timestamp < ? AND EXISTS (
+			SELECT 1 FROM prow_job_run_tests WHERE prow_job_run_tests.prow_job_run_id = prow_job_runs.id
+			AND prow_job_run_tests.status NOT IN ?)`,
+		cutoff, []sippyprocessingv1.TestStatus{sippyprocessingv1.TestStatusFailure, sippyprocessingv1.TestStatusFlake}).
+		Scan(&oldest)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	result := &Result{}
+	if oldest == nil {
+		log.Infof("no job runs before %s to compact", cutoff.Format("2006-01-02"))
+		return result, nil
+	}
+
+	for start := oldest.UTC().Truncate(day); start.Before(cutoff); start = start.Add(day) {
+		testResults, dailyResults, err := compactDay(dbc, start, dryRun)
+		if err != nil {
+			return result, err
+		}
+		if testResults == 0 {
+			continue
+		}
+		result.Days++
+		result.TestResults += testResults
+		result.DailyResults += dailyResults
+		log.Infof("compacted %d test results of %s into %d daily results", testResults, start.Format("2006-01-02"),
+			dailyResults)
+	}
+	return result, nil
+}
+
+// CompactedThrough returns the end of the latest compacted day, or the zero time when nothing has been compacted. The
+// per-run test results before it no longer include passes.
+func CompactedThrough(dbc *db.DB) (time.Time, error) {
+	var latest *time.Time
+	if res := dbc.DB.Model(&models.TestDailyResult{}).Select("MAX(date)").Scan(&latest); res.Error != nil {
+		return time.Time{}, res.Error
+	}
+	if latest == nil {
+		return time.Time{}, nil
+	}
+	return latest.UTC().Truncate(day).Add(day), nil
+}
+
+// compactDay replaces the compacted test results of the day's runs with daily results, in one transaction.
+func compactDay(dbc *db.DB, start time.Time, dryRun bool) (int64, int64, error) {
+	args := map[string]interface{}{
+		"start":   start,
+		"end":     start.Add(day),
+		"failure": sippyprocessingv1.TestStatusFailure,
+		"flake":   sippyprocessingv1.TestStatusFlake,
+		"success": sippyprocessingv1.TestStatusSuccess,
+	}
+
+	if dryRun {
+		var counts struct {
+			TestResults  int64
+			DailyResults int64
+		}
+		res := dbc.DB.Raw(`SELECT COUNT(*) AS test_results,
+			COUNT(DISTINCT (prow_job_run_tests.test_id, prow_job_run_tests.suite_id, prow_job_runs.prow_job_id)) AS daily_results`+
+			compactedRows, args).Scan(&counts)
+		return counts.TestResults, counts.DailyResults, res.Error
+	}
+
+	var testResults, dailyResults int64
+	err := dbc.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`INSERT INTO test_daily_results
+			(created_at, updated_at, date, test_id, suite_id, prow_job_id, release, variants, runs, successes, duration)
+			SELECT NOW(), NOW(), DATE(@start), prow_job_run_tests.test_id, prow_job_run_tests.suite_id,
+				prow_job_runs.prow_job_id, prow_jobs.release, prow_jobs.variants, COUNT(*),
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = @success), SUM(prow_job_run_tests.duration)`+
+			compactedRows+`
+			GROUP BY prow_job_run_tests.test_id, prow_job_run_tests.suite_id, prow_job_runs.prow_job_id,
+				prow_jobs.release, prow_jobs.variants`, args)
+		if res.Error != nil {
+			return res.Error
+		}
+		dailyResults = res.RowsAffected
+
+		res = tx.Exec(`DELETE FROM prow_job_run_tests USING prow_job_runs
+			WHERE prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+			AND prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+			AND prow_job_run_tests.status NOT IN (@failure, @flake)
+			AND prow_job_run_tests.deleted_at IS NULL`, args)
+		if res.Error != nil {
+			return res.Error
+		}
+		testResults = res.RowsAffected
+		return nil
+	})
+	return testResults, dailyResults, err
+}
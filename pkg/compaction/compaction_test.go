@@ -0,0 +1,13 @@
+package compaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactRequiresMinAge(t *testing.T) {
+	_, err := Compact(nil, 14*day, time.Now(), true)
+	assert.EqualError(t, err, "job runs must be at least 28 days old to be compacted")
+}
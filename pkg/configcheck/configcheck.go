@@ -0,0 +1,395 @@
+// Package configcheck verifies a sippy configuration before it's deployed: that the config file is valid, and that the
+// credentials it and the command line rely on can reach their services. Checks are collected into a report rather
+// than stopping at the first failure, so every problem is found in one run.
+package configcheck
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/sippy/pkg/alerting"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/digest"
+	"github.com/openshift/sippy/pkg/failureclassification"
+	"github.com/openshift/sippy/pkg/notify"
+	"github.com/openshift/sippy/pkg/regressiondetection"
+	"github.com/openshift/sippy/pkg/testidentification"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	// StatusSkip is a check that doesn't apply, i.e. for a feature that isn't configured.
+	StatusSkip Status = "skip"
+)
+
+// Check is the result of verifying one part of the configuration.
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the result of every check, it passed when none failed.
+type Report struct {
+	Checks []Check `json:"checks"`
+	Passed bool    `json:"passed"`
+}
+
+func NewReport() *Report {
+	return &Report{Checks: make([]Check, 0), Passed: true}
+}
+
+// Add records a check, failing it with the error if there is one.
+func (r *Report) Add(name string, err error) {
+	if err != nil {
+		r.Checks = append(r.Checks, Check{Name: name, Status: StatusFail, Message: err.Error()})
+		r.Passed = false
+		return
+	}
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusPass})
+}
+
+// Skip records a check that doesn't apply, and why.
+func (r *Report) Skip(name, reason string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: StatusSkip, Message: reason})
+}
+
+// WriteText writes the report as a table, followed by a summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMESSAGE")
+	counts := map[Status]int{}
+	for _, c := range r.Checks {
+		counts[c.Status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, strings.ToUpper(string(c.Status)), c.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	result := "PASSED"
+	if !r.Passed {
+		result = "FAILED"
+	}
+	_, err := fmt.Fprintf(w, "\n%s: %d passed, %d failed, %d skipped\n",
+		result, counts[StatusPass], counts[StatusFail], counts[StatusSkip])
+	return err
+}
+
+// CheckConfig verifies the config file, without contacting any services.
+func CheckConfig(r *Report, config *v1.SippyConfig) {
+	r.Add("prow", checkProw(config.Prow))
+
+	releases := make([]string, 0, len(config.Releases))
+	for release := range config.Releases {
+		releases = append(releases, release)
+	}
+	sort.Strings(releases)
+	if len(releases) == 0 {
+		r.Skip("releases", "no releases configured")
+	}
+	for _, release := range releases {
+		r.Add("release "+release, checkRelease(config.Releases[release]))
+	}
+
+	if len(config.Tenants) > 0 {
+		r.Add("tenants", checkTenants(config))
+	}
+
+	r.Add("synthetic tests", checkSyntheticTests(config.SyntheticTests))
+	_, err := failureclassification.NewClassifier(config.FailureClassification)
+	r.Add("failure classification", err)
+	r.Add("never-stable jobs", checkNeverStable(config.NeverStable))
+	r.Add("regression detection", checkRegressionDetection(config.RegressionDetection))
+	r.Add("silent job detection", checkSilentJobDetection(config.SilentJobDetection))
+	if len(config.TestRenames) > 0 {
+		r.Add("test renames", checkTestRenames(config.TestRenames))
+	}
+
+	_, err = notify.NewNotifier(config.Notifications)
+	r.Add("notifications", err)
+
+	if !config.Alerting.PagerDuty && config.Alerting.AlertmanagerURL == "" {
+		r.Skip("alerting", "no alert destinations configured")
+	} else {
+		r.Add("alerting", checkAlerting(config.Alerting))
+	}
+
+	if config.Digest.From == "" && len(config.Digest.Recipients) == 0 {
+		r.Skip("email digests", "digests not configured")
+	} else {
+		_, err := digest.NewProcessor(nil, config.Digest)
+		r.Add("email digests", err)
+	}
+
+	if config.Elasticsearch.URL == "" {
+		r.Skip("elasticsearch", "export not configured")
+	} else {
+		r.Add("elasticsearch", checkURL(config.Elasticsearch.URL))
+	}
+
+	if len(config.Federation.Remotes) == 0 {
+		r.Skip("federation", "no remote instances configured")
+	} else {
+		r.Add("federation", checkFederation(config.Federation))
+	}
+}
+
+// CheckVariants verifies the variant manager for the mode could be created, which validates its rules, and that the
+// variants releases mark invalid are known to it.
+func CheckVariants(r *Report, vm testidentification.VariantManager, vmErr error, config *v1.SippyConfig) {
+	if vmErr != nil {
+		r.Add("variant rules", vmErr)
+		return
+	}
+
+	var errs []string
+	known := vm.AllVariants()
+	releases := make([]string, 0, len(config.Releases))
+	for release := range config.Releases {
+		releases = append(releases, release)
+	}
+	sort.Strings(releases)
+	for _, release := range releases {
+		for _, variant := range config.Releases[release].InvalidVariants {
+			if !known.Has(variant) {
+				errs = append(errs, fmt.Sprintf("release %s marks unknown variant %q invalid", release, variant))
+			}
+		}
+	}
+	r.Add("variant rules", joinErrors(errs))
+}
+
+func checkProw(config v1.ProwConfig) error {
+	if config.URL == "" {
+		return fmt.Errorf("prow url is required")
+	}
+	return checkURL(config.URL)
+}
+
+func checkRelease(config v1.ReleaseConfig) error {
+	var errs []string
+	if len(config.Jobs) == 0 && len(config.Regexp) == 0 {
+		errs = append(errs, "no jobs or regexps match jobs to the release")
+	}
+	for _, re := range config.Regexp {
+		if _, err := regexp.Compile(re); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid regexp %q: %s", re, err))
+		}
+	}
+	blocking := sets.NewString(config.BlockingJobs...)
+	for _, job := range config.InformingJobs {
+		if blocking.Has(job) {
+			errs = append(errs, fmt.Sprintf("job %s is both blocking and informing", job))
+		}
+	}
+	informing := sets.NewString(config.InformingJobs...)
+	for _, job := range config.ExperimentalJobs {
+		if blocking.Has(job) || informing.Has(job) {
+			errs = append(errs, fmt.Sprintf("payload job %s can't be experimental", job))
+		}
+	}
+	for _, job := range append(append(append([]string{}, config.BlockingJobs...), config.InformingJobs...),
+		config.ExperimentalJobs...) {
+		if strings.TrimSpace(job) == "" {
+			errs = append(errs, "job names must not be empty")
+			break
+		}
+	}
+	m := config.Milestones
+	if !m.FeatureFreeze.IsZero() && !m.CodeFreeze.IsZero() && m.CodeFreeze.Before(m.FeatureFreeze) {
+		errs = append(errs, "code freeze is before feature freeze")
+	}
+	if !m.GA.IsZero() && (m.GA.Before(m.FeatureFreeze) || m.GA.Before(m.CodeFreeze)) {
+		errs = append(errs, "GA is before a freeze")
+	}
+	return joinErrors(errs)
+}
+
+func checkTenants(config *v1.SippyConfig) error {
+	var errs []string
+	tenants := make([]string, 0, len(config.Tenants))
+	for tenant := range config.Tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	owners := map[string]string{}
+	for _, tenant := range tenants {
+		tenantConfig := config.Tenants[tenant]
+		if len(tenantConfig.Releases) == 0 {
+			errs = append(errs, fmt.Sprintf("tenant %s has no releases", tenant))
+		}
+		for _, release := range tenantConfig.Releases {
+			if _, ok := config.Releases[release]; !ok {
+				errs = append(errs, fmt.Sprintf("tenant %s has unknown release %s", tenant, release))
+			}
+			// a shared release needs the job regexps of both tenants to tell their jobs apart
+			if owner, ok := owners[release]; ok && (len(tenantConfig.Jobs) == 0 || len(config.Tenants[owner].Jobs) == 0) {
+				errs = append(errs, fmt.Sprintf("release %s belongs to tenants %s and %s, which both need job regexps to share it",
+					release, owner, tenant))
+			}
+			owners[release] = tenant
+		}
+		for _, job := range tenantConfig.Jobs {
+			if _, err := regexp.Compile(job); err != nil {
+				errs = append(errs, fmt.Sprintf("tenant %s job regexp %q: %s", tenant, job, err))
+			}
+		}
+		if tenantConfig.Prow.URL != "" {
+			if err := checkURL(tenantConfig.Prow.URL); err != nil {
+				errs = append(errs, fmt.Sprintf("tenant %s prow url: %s", tenant, err))
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+func checkSyntheticTests(tests []v1.SyntheticTestConfig) error {
+	var errs []string
+	names := sets.NewString()
+	for i, test := range tests {
+		if test.Name == "" {
+			errs = append(errs, fmt.Sprintf("synthetic test %d has no name", i))
+		} else if names.Has(test.Name) {
+			errs = append(errs, fmt.Sprintf("synthetic test %q is defined more than once", test.Name))
+		}
+		names.Insert(test.Name)
+		for field, re := range map[string]string{
+			"jobRegexp":         test.JobRegexp,
+			"requiredArtifact":  test.RequiredArtifact,
+			"forbiddenArtifact": test.ForbiddenArtifact,
+		} {
+			if _, err := regexp.Compile(re); err != nil {
+				errs = append(errs, fmt.Sprintf("synthetic test %q has an invalid %s: %s", test.Name, field, err))
+			}
+		}
+		if test.MaxDuration < 0 {
+			errs = append(errs, fmt.Sprintf("synthetic test %q has a negative maxDuration", test.Name))
+		}
+	}
+	sort.Strings(errs)
+	return joinErrors(errs)
+}
+
+func checkNeverStable(config v1.NeverStableConfig) error {
+	var errs []string
+	if config.PassRateThreshold < 0 || config.PassRateThreshold > 100 {
+		errs = append(errs, "passRateThreshold must be a percentage")
+	}
+	if config.Weeks < 0 {
+		errs = append(errs, "weeks must not be negative")
+	}
+	excluded := sets.NewString(config.Exclude...)
+	for _, job := range config.Include {
+		if excluded.Has(job) {
+			errs = append(errs, fmt.Sprintf("job %s is both included and excluded", job))
+		}
+	}
+	return joinErrors(errs)
+}
+
+func checkRegressionDetection(config v1.RegressionDetectionConfig) error {
+	var errs []string
+	if config.Confidence != 0 && (config.Confidence < 1 || config.Confidence > 99) {
+		errs = append(errs, "confidence must be between 1 and 99")
+	}
+	if config.MinRuns < 0 {
+		errs = append(errs, "minRuns must not be negative")
+	}
+	if config.BaselinesFile != "" {
+		if _, err := regressiondetection.LoadBaselines(config.BaselinesFile); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return joinErrors(errs)
+}
+
+func checkSilentJobDetection(config v1.SilentJobDetectionConfig) error {
+	var errs []string
+	if config.MinRunsPerDay < 0 {
+		errs = append(errs, "minRunsPerDay must not be negative")
+	}
+	if config.Hours < 0 {
+		errs = append(errs, "hours must not be negative")
+	}
+	return joinErrors(errs)
+}
+
+func checkTestRenames(renames []v1.TestRenameConfig) error {
+	var errs []string
+	renamed := map[string]bool{}
+	for _, rename := range renames {
+		switch {
+		case rename.From == "" || rename.To == "" || rename.Release == "":
+			errs = append(errs, fmt.Sprintf("rename of %q to %q needs from, to and release", rename.From, rename.To))
+		case rename.From == rename.To:
+			errs = append(errs, fmt.Sprintf("test %q is renamed to itself", rename.From))
+		}
+		key := rename.To + "\x00" + rename.Release
+		if renamed[key] {
+			errs = append(errs, fmt.Sprintf("test %q is renamed more than once in %s", rename.To, rename.Release))
+		}
+		renamed[key] = true
+	}
+	return joinErrors(errs)
+}
+
+func checkFederation(config v1.FederationConfig) error {
+	var errs []string
+	names := map[string]bool{config.Name: true}
+	if config.Name == "" {
+		names["local"] = true
+	}
+	for _, remote := range config.Remotes {
+		if remote.Name == "" {
+			errs = append(errs, fmt.Sprintf("remote %q has no name", remote.URL))
+		} else if names[remote.Name] {
+			errs = append(errs, fmt.Sprintf("remote name %q is used more than once", remote.Name))
+		}
+		names[remote.Name] = true
+		if err := checkURL(remote.URL); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if config.TimeoutSeconds < 0 {
+		errs = append(errs, "timeoutSeconds must not be negative")
+	}
+	return joinErrors(errs)
+}
+
+func checkAlerting(config v1.AlertingConfig) error {
+	if config.AlertmanagerURL != "" {
+		if err := checkURL(config.AlertmanagerURL); err != nil {
+			return err
+		}
+	}
+	_, err := alerting.NewSenders(config)
+	return err
+}
+
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%q must be an http or https URL", raw)
+	}
+	return nil
+}
+
+func joinErrors(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(errs, "; "))
+}
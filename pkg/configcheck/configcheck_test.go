@@ -0,0 +1,213 @@
+package configcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+func checksByName(r *Report) map[string]Check {
+	checks := map[string]Check{}
+	for _, c := range r.Checks {
+		checks[c.Name] = c
+	}
+	return checks
+}
+
+func TestCheckConfig(t *testing.T) {
+	config := &v1.SippyConfig{
+		Prow: v1.ProwConfig{URL: "https://prow.ci.openshift.org/prowjobs.js"},
+		Releases: map[string]v1.ReleaseConfig{
+			"4.16": {Regexp: []string{`-4\.16-`}, BlockingJobs: []string{"aws"}, InformingJobs: []string{"gcp"}},
+			"4.15": {Regexp: []string{`-4\.15-(`}},
+			"4.14": {BlockingJobs: []string{"aws"}, InformingJobs: []string{"aws"}, ExperimentalJobs: []string{"aws"},
+				Milestones: v1.ReleaseMilestones{
+					FeatureFreeze: time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+					CodeFreeze:    time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC),
+				}},
+		},
+		SyntheticTests: []v1.SyntheticTestConfig{
+			{Name: "job should finish quickly", JobRegexp: "e2e"},
+			{Name: "job should finish quickly", RequiredArtifact: "["},
+		},
+		Notifications: v1.NotificationConfig{
+			Routes: []v1.NotificationRouteConfig{{NotificationFilter: v1.NotificationFilter{Events: []string{"nonsense"}}}},
+		},
+		FailureClassification: v1.FailureClassificationConfig{
+			Rules: []v1.FailureClassificationRule{{Classification: "flaky-failure"}},
+		},
+		Digest:              v1.DigestConfig{From: "sippy@example.com", Schedule: "hourly"},
+		RegressionDetection: v1.RegressionDetectionConfig{Confidence: 95},
+		SilentJobDetection:  v1.SilentJobDetectionConfig{Hours: -1},
+		Federation: v1.FederationConfig{Name: "ocp", Remotes: []v1.FederationRemoteConfig{
+			{Name: "okd", URL: "https://sippy.example.com"},
+			{Name: "ocp", URL: "sippy.example.com"},
+		}},
+	}
+
+	r := NewReport()
+	CheckConfig(r, config)
+	assert.False(t, r.Passed)
+
+	checks := checksByName(r)
+	assert.Equal(t, StatusPass, checks["prow"].Status)
+	assert.Equal(t, StatusPass, checks["release 4.16"].Status)
+	assert.Equal(t, StatusFail, checks["release 4.15"].Status)
+	assert.Contains(t, checks["release 4.15"].Message, "invalid regexp")
+	assert.Equal(t, StatusFail, checks["release 4.14"].Status)
+	assert.Contains(t, checks["release 4.14"].Message, "no jobs or regexps")
+	assert.Contains(t, checks["release 4.14"].Message, "both blocking and informing")
+	assert.Contains(t, checks["release 4.14"].Message, "payload job aws can't be experimental")
+	assert.Contains(t, checks["release 4.14"].Message, "code freeze is before feature freeze")
+	assert.Equal(t, StatusFail, checks["synthetic tests"].Status)
+	assert.Contains(t, checks["synthetic tests"].Message, "defined more than once")
+	assert.Contains(t, checks["synthetic tests"].Message, "invalid requiredArtifact")
+	assert.Equal(t, StatusFail, checks["failure classification"].Status)
+	assert.Contains(t, checks["failure classification"].Message, "unknown classification")
+	assert.Equal(t, StatusFail, checks["notifications"].Status)
+	assert.Equal(t, StatusFail, checks["email digests"].Status)
+	assert.Equal(t, StatusPass, checks["regression detection"].Status)
+	assert.Equal(t, StatusFail, checks["silent job detection"].Status)
+	assert.Contains(t, checks["silent job detection"].Message, "hours must not be negative")
+	assert.Equal(t, StatusSkip, checks["alerting"].Status)
+	assert.Equal(t, StatusSkip, checks["elasticsearch"].Status)
+	assert.Equal(t, StatusFail, checks["federation"].Status)
+	assert.Contains(t, checks["federation"].Message, `remote name "ocp" is used more than once`)
+	assert.Contains(t, checks["federation"].Message, `"sippy.example.com" must be an http or https URL`)
+
+	// releases are checked in order, so reports are stable
+	names := make([]string, 0)
+	for _, c := range r.Checks {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"prow", "release 4.14", "release 4.15", "release 4.16"}, names[:4])
+}
+
+func TestCheckTenants(t *testing.T) {
+	config := &v1.SippyConfig{
+		Releases: map[string]v1.ReleaseConfig{"4.16": {}, "4.15": {}},
+		Tenants: map[string]v1.TenantConfig{
+			"ocp": {Releases: []string{"4.16", "4.15"}},
+			"okd": {Releases: []string{"4.16", "4.14"}, Prow: v1.ProwConfig{URL: "prow"}},
+			"hcp": {Jobs: []string{"(hypershift"}},
+		},
+	}
+
+	err := checkTenants(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tenant hcp has no releases")
+	assert.Contains(t, err.Error(), "tenant okd has unknown release 4.14")
+	assert.Contains(t, err.Error(), "release 4.16 belongs to tenants ocp and okd, which both need job regexps")
+	assert.Contains(t, err.Error(), "tenant okd prow url")
+	assert.Contains(t, err.Error(), `tenant hcp job regexp "(hypershift"`)
+
+	assert.NoError(t, checkTenants(&v1.SippyConfig{
+		Releases: map[string]v1.ReleaseConfig{"4.16": {}, "4.15": {}},
+		Tenants: map[string]v1.TenantConfig{
+			"ocp": {Releases: []string{"4.16"}},
+			"okd": {Releases: []string{"4.15"}},
+		},
+	}))
+	assert.NoError(t, checkTenants(&v1.SippyConfig{
+		Releases: map[string]v1.ReleaseConfig{"4.16": {}},
+		Tenants: map[string]v1.TenantConfig{
+			"ocp": {Releases: []string{"4.16"}, Jobs: []string{"-ocp-"}},
+			"okd": {Releases: []string{"4.16"}, Jobs: []string{"-okd-"}},
+		},
+	}))
+}
+
+func TestCheckRegressionDetection(t *testing.T) {
+	assert.NoError(t, checkRegressionDetection(v1.RegressionDetectionConfig{Confidence: 95}))
+
+	err := checkRegressionDetection(v1.RegressionDetectionConfig{Confidence: 100, BaselinesFile: "missing.yaml"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "confidence must be between 1 and 99")
+	assert.Contains(t, err.Error(), "error reading test baselines")
+}
+
+func TestCheckTestRenames(t *testing.T) {
+	assert.NoError(t, checkTestRenames([]v1.TestRenameConfig{
+		{From: "old name", To: "new name", Release: "4.16"},
+		{From: "older name", To: "old name", Release: "4.14"},
+	}))
+
+	err := checkTestRenames([]v1.TestRenameConfig{
+		{From: "a", To: "a", Release: "4.16"},
+		{From: "b", To: "c"},
+		{From: "d", To: "e", Release: "4.16"},
+		{From: "f", To: "e", Release: "4.16"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `test "a" is renamed to itself`)
+	assert.Contains(t, err.Error(), `rename of "b" to "c" needs from, to and release`)
+	assert.Contains(t, err.Error(), `test "e" is renamed more than once in 4.16`)
+}
+
+func TestCheckConfigPasses(t *testing.T) {
+	r := NewReport()
+	CheckConfig(r, &v1.SippyConfig{
+		Prow:     v1.ProwConfig{URL: "https://prow.ci.openshift.org/prowjobs.js"},
+		Releases: map[string]v1.ReleaseConfig{"4.16": {Jobs: map[string]bool{"periodic-e2e": true}}},
+	})
+	assert.True(t, r.Passed)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteText(&buf))
+	assert.Contains(t, buf.String(), "PASSED:")
+}
+
+func TestCheckTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	r := NewReport()
+	CheckGitHub(ctx, r, server.Client(), server.URL, "")
+	CheckGitHub(ctx, r, server.Client(), server.URL, "good")
+	assert.Equal(t, []Status{StatusSkip, StatusPass}, []Status{r.Checks[0].Status, r.Checks[1].Status})
+	assert.True(t, r.Passed)
+
+	CheckGitHub(ctx, r, server.Client(), server.URL, "bad")
+	assert.Equal(t, StatusFail, r.Checks[2].Status)
+	assert.Contains(t, r.Checks[2].Message, "401")
+	assert.False(t, r.Passed)
+
+	jira := v1.JiraFilingConfig{URL: server.URL, Releases: []string{"4.16"}, DefaultProject: "OCPBUGS"}
+	r = NewReport()
+	CheckJira(ctx, r, server.Client(), v1.JiraFilingConfig{}, "")
+	CheckJira(ctx, r, server.Client(), jira, "good")
+	CheckJira(ctx, r, server.Client(), jira, "")
+	assert.Equal(t, []Status{StatusSkip, StatusPass, StatusFail},
+		[]Status{r.Checks[0].Status, r.Checks[1].Status, r.Checks[2].Status})
+}
+
+func TestCheckVariants(t *testing.T) {
+	config := &v1.SippyConfig{Releases: map[string]v1.ReleaseConfig{"4.17": {InvalidVariants: []string{"sdn", "nonsense"}}}}
+
+	r := NewReport()
+	CheckVariants(r, testidentification.NewOpenshiftVariantManager(), nil, config)
+	require.Len(t, r.Checks, 1)
+	assert.Equal(t, StatusFail, r.Checks[0].Status)
+	assert.Equal(t, `release 4.17 marks unknown variant "nonsense" invalid`, r.Checks[0].Message)
+
+	r = NewReport()
+	CheckVariants(r, nil, fmt.Errorf("invalid rule regexp"), config)
+	assert.Equal(t, "invalid rule regexp", r.Checks[0].Message)
+}
@@ -0,0 +1,108 @@
+package configcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+	"github.com/openshift/sippy/pkg/jirafiling"
+)
+
+// CheckGCS verifies the credentials can list the bucket's objects, as the prow loader does.
+func CheckGCS(ctx context.Context, r *Report, credentialFile, bucket string) {
+	if credentialFile == "" {
+		r.Skip("gcs", "no google service account credential file")
+		return
+	}
+	client, err := gcs.NewGCSClient(ctx, credentialFile, "")
+	if err != nil {
+		r.Add("gcs", err)
+		return
+	}
+	defer client.Close()
+
+	_, err = client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: "logs/"}).Next()
+	if err == iterator.Done {
+		err = nil
+	}
+	if err != nil {
+		err = fmt.Errorf("couldn't list bucket %s: %w", bucket, err)
+	}
+	r.Add("gcs", err)
+}
+
+// CheckBigQuery verifies the credentials can read the dataset's metadata.
+func CheckBigQuery(ctx context.Context, r *Report, credentialFile, project, dataset string) {
+	if credentialFile == "" {
+		r.Skip("bigquery", "no google service account credential file")
+		return
+	}
+	client, err := bigquery.NewClient(ctx, project, option.WithCredentialsFile(credentialFile))
+	if err != nil {
+		r.Add("bigquery", err)
+		return
+	}
+	defer client.Close()
+
+	if _, err := client.Dataset(dataset).Metadata(ctx); err != nil {
+		r.Add("bigquery", fmt.Errorf("couldn't read dataset %s.%s: %w", project, dataset, err))
+		return
+	}
+	r.Add("bigquery", nil)
+}
+
+// CheckGitHub verifies the token is accepted by the GitHub API at apiURL, i.e. https://api.github.com.
+func CheckGitHub(ctx context.Context, r *Report, client *http.Client, apiURL, token string) {
+	if token == "" {
+		r.Skip("github", "no GITHUB_TOKEN, requests will be unauthenticated and heavily rate limited")
+		return
+	}
+	r.Add("github", checkToken(ctx, client, strings.TrimSuffix(apiURL, "/")+"/rate_limit", token))
+}
+
+// CheckJira verifies the token is accepted by the jira instance issues are filed in.
+func CheckJira(ctx context.Context, r *Report, client *http.Client, config v1.JiraFilingConfig, token string) {
+	if len(config.Releases) == 0 {
+		r.Skip("jira", "jira filing not configured")
+		return
+	}
+	if token == "" {
+		r.Add("jira", fmt.Errorf("jira filing requires the JIRA_TOKEN environment variable"))
+		return
+	}
+	if config.DefaultProject == "" && len(config.ComponentProjects) == 0 {
+		r.Add("jira", fmt.Errorf("jira filing requires a defaultProject or componentProjects"))
+		return
+	}
+	jiraURL := config.URL
+	if jiraURL == "" {
+		jiraURL = jirafiling.DefaultURL
+	}
+	r.Add("jira", checkToken(ctx, client, strings.TrimSuffix(jiraURL, "/")+"/rest/api/2/myself", token))
+}
+
+// checkToken requests the URL with the bearer token, failing unless it succeeds.
+func checkToken(ctx context.Context, client *http.Client, u, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", u, resp.Status)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package bugloader
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"sort"
@@ -43,7 +44,7 @@ func (bl *BugLoader) Errors() []error {
 }
 
 // LoadBugs does a bulk query of all our tests and jobs, 50 at a time, to search.ci and then syncs the associations to the db.
-func (bl *BugLoader) Load() {
+func (bl *BugLoader) Load(_ context.Context) {
 	testCache, err := loadTestCache(bl.dbc, []string{})
 	if err != nil {
 		bl.errors = append(bl.errors, err)
@@ -82,6 +83,8 @@ func (bl *BugLoader) Load() {
 
 	// Merge the test/job bugs into one list, associated with each failing test or job, mapped to our db model for the bug.
 	dbExpectedBugs := map[int64]*models.Bug{}
+	// Descriptions aren't stored, only used to correlate bugs with their tests' failures.
+	descriptions := map[uint]string{}
 
 	for testName, apiBugArr := range testIssues {
 		for _, apiBug := range apiBugArr {
@@ -96,6 +99,7 @@ func (bl *BugLoader) Load() {
 				log.Debugf("converting issue: %+v", apiBug)
 				newBug := convertAPIIssueToDBIssue(issueID, apiBug)
 				dbExpectedBugs[issueID] = newBug
+				descriptions[newBug.ID] = apiBug.Fields.Description
 			}
 			if _, ok := testCache[testName]; !ok {
 				// Shouldn't be possible, if it is we want to know.
@@ -174,6 +178,10 @@ func (bl *BugLoader) Load() {
 	}
 	log.Infof("deleted %d stale bugs", res.RowsAffected)
 
+	if err := correlateBugsWithTests(bl.dbc, dbExpectedBugs, descriptions, time.Now()); err != nil {
+		bl.errors = append(bl.errors, err)
+	}
+
 	// Update watch list
 	if err := updateWatchlist(bl.dbc); err != nil {
 		bl.errors = append(bl.errors, err...)
@@ -189,8 +197,12 @@ func convertAPIIssueToDBIssue(issueID int64, apiIssue jira.Issue) *models.Bug {
 		LastChangeTime: time.Time(apiIssue.Fields.Updated),
 		Summary:        apiIssue.Fields.Summary,
 		URL:            fmt.Sprintf("https://issues.redhat.com/browse/%s", apiIssue.Key),
+		FiledAt:        time.Time(apiIssue.Fields.Created),
 		Tests:          []models.Test{},
 	}
+	if resolved := time.Time(apiIssue.Fields.Resolutiondate); !resolved.IsZero() {
+		newBug.ResolvedAt = &resolved
+	}
 
 	// The version and components fields may typically or always be just one value, but we're told it
 	// may not be possible to actually prevent someone adding multiple, so we'll be ready for the possibility.
@@ -0,0 +1,220 @@
+package bugloader
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+const (
+	// correlationLinkWeight is the confidence in a bug linked to a test by its name alone, and correlationMessageWeight
+	// and correlationTimingWeight weigh the message and timing scores on top of it.
+	correlationLinkWeight    = 0.2
+	correlationMessageWeight = 0.4
+	correlationTimingWeight  = 0.4
+
+	// correlationWindow is how far back a test's failures are compared to its bugs, and maxCorrelatedFailures caps how
+	// many of its most recent failures are.
+	correlationWindow     = 14 * 24 * time.Hour
+	maxCorrelatedFailures = 50
+
+	// correlationLeadTime is how long before a bug was filed a test's failures still count towards it, since bugs are
+	// filed after failures are noticed.
+	correlationLeadTime = 72 * time.Hour
+
+	// minQuotedMessageLength is the shortest failure message that counts as quoted by a bug.
+	minQuotedMessageLength = 20
+	// maxFailureOutput caps how much of a failure's output is matched to a bug.
+	maxFailureOutput = 2000
+)
+
+// correlationStopWords are too common in bug summaries and failure output to tell failures apart.
+var correlationStopWords = sets.NewString("the", "and", "for", "with", "when", "while", "should", "test", "tests",
+	"fail", "fails", "failed", "failing", "failure", "failures", "flake", "flakes", "flaky", "error", "errors", "sig")
+
+// testFailure is a recent failure of a test, with its output if it was recorded.
+type testFailure struct {
+	TestID    uint
+	Timestamp time.Time
+	Output    string
+}
+
+// correlateBugsWithTests scores each bug's links to its tests, replacing the previous scores. descriptions are the
+// bugs' descriptions, by ID, which aren't stored.
+func correlateBugsWithTests(dbc *db.DB, bugs map[int64]*models.Bug, descriptions map[uint]string, now time.Time) error {
+	seen := map[uint]bool{}
+	testIDs := make([]uint, 0)
+	for _, bug := range bugs {
+		for _, test := range bug.Tests {
+			if !seen[test.ID] {
+				seen[test.ID] = true
+				testIDs = append(testIDs, test.ID)
+			}
+		}
+	}
+	failures, err := loadTestFailures(dbc, testIDs, now.Add(-correlationWindow))
+	if err != nil {
+		return errors.Wrap(err, "error loading test failures to correlate with bugs")
+	}
+
+	correlations := make([]models.BugTestCorrelation, 0)
+	for _, bug := range bugs {
+		correlated := map[uint]bool{}
+		for _, test := range bug.Tests {
+			if correlated[test.ID] {
+				continue
+			}
+			correlated[test.ID] = true
+			correlations = append(correlations,
+				correlateBugWithTest(bug, descriptions[bug.ID], test, failures[test.ID]))
+		}
+	}
+
+	err = dbc.DB.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Exec("DELETE FROM bug_test_correlations"); res.Error != nil {
+			return res.Error
+		}
+		if len(correlations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(correlations, dbc.BatchSize).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, "error saving bug test correlations")
+	}
+	log.Infof("correlated %d bug test links", len(correlations))
+	return nil
+}
+
+// loadTestFailures returns the tests' most recent failures since the time, by test.
+func loadTestFailures(dbc *db.DB, testIDs []uint, since time.Time) (map[uint][]testFailure, error) {
+	results := map[uint][]testFailure{}
+	for start := 0; start < len(testIDs); start += 5000 {
+		end := start + 5000
+		if end > len(testIDs) {
+			end = len(testIDs)
+		}
+		failures := make([]testFailure, 0)
+		res := dbc.DB.Raw(`SELECT test_id, timestamp, output FROM (
+				SELECT prow_job_run_tests.test_id, prow_job_runs.timestamp,
+					COALESCE(prow_job_run_test_outputs.output, '') AS output,
+					ROW_NUMBER() OVER (PARTITION BY prow_job_run_tests.test_id ORDER BY prow_job_runs.timestamp DESC) AS n
+				FROM prow_job_run_tests
+					JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+					LEFT JOIN prow_job_run_test_outputs ON prow_job_run_test_outputs.prow_job_run_test_id = prow_job_run_tests.id
+				WHERE prow_job_run_tests.test_id IN ? AND prow_job_run_tests.status = ?
+					AND prow_job_run_tests.deleted_at IS NULL AND prow_job_runs.timestamp >= ?
+			) failures
+			WHERE n <= ?`, testIDs[start:end], int(v1.TestStatusFailure), since, maxCorrelatedFailures).
+			Scan(&failures)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		for _, f := range failures {
+			results[f.TestID] = append(results[f.TestID], f)
+		}
+	}
+	return results, nil
+}
+
+// correlateBugWithTest scores the bug's link to the test from the test's recent failures.
+func correlateBugWithTest(bug *models.Bug, description string, test models.Test,
+	failures []testFailure) models.BugTestCorrelation {
+	outputs := make([]string, 0, len(failures))
+	times := make([]time.Time, 0, len(failures))
+	for _, f := range failures {
+		if f.Output != "" {
+			outputs = append(outputs, f.Output)
+		}
+		times = append(times, f.Timestamp)
+	}
+
+	correlation := models.BugTestCorrelation{
+		BugID:        bug.ID,
+		TestID:       test.ID,
+		MessageScore: messageScore(bug.Summary, description, test.Name, outputs),
+		TimingScore:  timingScore(bug.FiledAt, bug.ResolvedAt, times),
+	}
+	correlation.Confidence = correlationLinkWeight +
+		correlationMessageWeight*correlation.MessageScore +
+		correlationTimingWeight*correlation.TimingScore
+	return correlation
+}
+
+// messageScore is 1 if the bug quotes one of the failures' messages, the first line of its output, or otherwise the
+// largest share of the words in the bug's summary that are in a failure's output. The test's name, which the bug was
+// found by, isn't counted.
+func messageScore(summary, description, testName string, outputs []string) float64 {
+	words := correlationWords(summary).Difference(correlationWords(testName))
+	var best float64
+	for _, output := range outputs {
+		if len(output) > maxFailureOutput {
+			output = output[:maxFailureOutput]
+		}
+		message := failureMessage(output)
+		if len(message) >= minQuotedMessageLength &&
+			(strings.Contains(summary, message) || strings.Contains(description, message)) {
+			return 1
+		}
+		if words.Len() == 0 {
+			continue
+		}
+		score := float64(words.Intersection(correlationWords(output)).Len()) / float64(words.Len())
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// timingScore is the share of the failures from shortly before the bug was filed until it was resolved.
+func timingScore(filed time.Time, resolved *time.Time, failures []time.Time) float64 {
+	if filed.IsZero() || len(failures) == 0 {
+		return 0
+	}
+	var during int
+	for _, failure := range failures {
+		if failure.Before(filed.Add(-correlationLeadTime)) {
+			continue
+		}
+		if resolved != nil && failure.After(*resolved) {
+			continue
+		}
+		during++
+	}
+	return float64(during) / float64(len(failures))
+}
+
+// failureMessage is the first non-empty line of a failure's output.
+func failureMessage(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// correlationWords are the lower cased words of at least three letters in the text, leaving out stop words and any
+// containing digits, like IDs, addresses and timestamps.
+func correlationWords(text string) sets.String {
+	words := sets.NewString()
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) < 3 || correlationStopWords.Has(word) || strings.ContainsAny(word, "0123456789") {
+			continue
+		}
+		words.Insert(word)
+	}
+	return words
+}
@@ -0,0 +1,98 @@
+package bugloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestMessageScore(t *testing.T) {
+	testName := "[sig-network] pods should be reachable"
+	output := `fail [github.com/openshift/origin/test/extended/networking/pods.go:120]: dial tcp 10.0.0.1:443: i/o timeout
+Ginkgo exit error 1: exit with code 1`
+
+	tests := []struct {
+		name        string
+		summary     string
+		description string
+		outputs     []string
+		want        float64
+	}{
+		{
+			name:    "summary matches the failure",
+			summary: "[sig-network] pods should be reachable fails with dial tcp i/o timeout",
+			outputs: []string{output},
+			want:    1,
+		},
+		{
+			name:    "summary partly matches the failure",
+			summary: "[sig-network] pods should be reachable fails with dial tcp connection refused",
+			outputs: []string{output},
+			want:    0.5,
+		},
+		{
+			name:        "description quotes the failure message",
+			summary:     "pods unreachable on ovn",
+			description: "Seen in CI:\n" + output,
+			outputs:     []string{output},
+			want:        1,
+		},
+		{
+			name:    "summary only names the test",
+			summary: "[sig-network] pods should be reachable is failing",
+			outputs: []string{output},
+			want:    0,
+		},
+		{
+			name:    "no failure output",
+			summary: "[sig-network] pods should be reachable fails with dial tcp i/o timeout",
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, messageScore(tt.summary, tt.description, testName, tt.outputs), 0.001)
+		})
+	}
+}
+
+func TestTimingScore(t *testing.T) {
+	filed := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	resolved := filed.AddDate(0, 0, 3)
+	failures := []time.Time{
+		filed.AddDate(0, 0, -10), // long before the bug was filed
+		filed.AddDate(0, 0, -1),  // shortly before it was filed
+		filed.AddDate(0, 0, 1),
+		filed.AddDate(0, 0, 5), // after it was resolved
+	}
+
+	assert.InDelta(t, 0.75, timingScore(filed, nil, failures), 0.001)
+	assert.InDelta(t, 0.5, timingScore(filed, &resolved, failures), 0.001)
+	assert.Equal(t, float64(0), timingScore(time.Time{}, nil, failures), "bugs without a filed time don't score")
+	assert.Equal(t, float64(0), timingScore(filed, nil, nil))
+}
+
+func TestCorrelateBugWithTest(t *testing.T) {
+	filed := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	bug := &models.Bug{ID: 1, Summary: "[sig-network] pods should be reachable fails with dial tcp i/o timeout",
+		FiledAt: filed}
+	test := models.Test{Name: "[sig-network] pods should be reachable"}
+	test.ID = 2
+
+	correlation := correlateBugWithTest(bug, "", test, []testFailure{
+		{TestID: 2, Timestamp: filed.AddDate(0, 0, 1), Output: "dial tcp 10.0.0.1:443: i/o timeout"},
+		{TestID: 2, Timestamp: filed.AddDate(0, 0, -10)},
+	})
+	assert.Equal(t, uint(1), correlation.BugID)
+	assert.Equal(t, uint(2), correlation.TestID)
+	assert.InDelta(t, 1, correlation.MessageScore, 0.001)
+	assert.InDelta(t, 0.5, correlation.TimingScore, 0.001)
+	assert.InDelta(t, 0.2+0.4+0.2, correlation.Confidence, 0.001)
+
+	unrelated := correlateBugWithTest(&models.Bug{ID: 3, Summary: "pods should be reachable is failing"}, "", test, nil)
+	assert.InDelta(t, correlationLinkWeight, unrelated.Confidence, 0.001,
+		"a bug found by the test's name alone keeps the link's confidence")
+}
@@ -1,11 +1,13 @@
 package dataloader
 
+import "context"
+
 type DataLoader interface {
 	// Name returns a friendly name identifier
 	Name() string
 
-	// Load initiates the data loading process.
-	Load()
+	// Load initiates the data loading process, ctx bounds the load and parents its trace spans.
+	Load(ctx context.Context)
 
 	// Errors returns a slice of errors that occurred during the data loading process.
 	Errors() []error
@@ -0,0 +1,132 @@
+package elasticsearchloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxBulkBytes splits large exports into several bulk requests, well under Elasticsearch's default 100MB limit on
+// request bodies.
+const maxBulkBytes = 10 * 1024 * 1024
+
+// document is a document to index, replacing any existing document with the same ID.
+type document struct {
+	Index string
+	ID    string
+	Body  interface{}
+}
+
+// bulkClient indexes documents with the Elasticsearch bulk API.
+type bulkClient struct {
+	url        string
+	apiKey     string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+func newBulkClient(url, apiKey, username, password string) *bulkClient {
+	return &bulkClient{
+		url:        strings.TrimSuffix(url, "/") + "/_bulk",
+		apiKey:     apiKey,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+type bulkAction struct {
+	Index bulkActionMetadata `json:"index"`
+}
+
+type bulkActionMetadata struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+func (c *bulkClient) index(docs []document) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		// Encode terminates every line with a newline, as the bulk API requires
+		if err := enc.Encode(bulkAction{Index: bulkActionMetadata{Index: doc.Index, ID: doc.ID}}); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc.Body); err != nil {
+			return fmt.Errorf("error encoding document %s/%s: %w", doc.Index, doc.ID, err)
+		}
+
+		if body.Len() >= maxBulkBytes {
+			if err := c.send(body.Bytes()); err != nil {
+				return err
+			}
+			body.Reset()
+		}
+	}
+
+	if body.Len() == 0 {
+		return nil
+	}
+	return c.send(body.Bytes())
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string          `json:"_id"`
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error,omitempty"`
+	} `json:"items"`
+}
+
+func (c *bulkClient) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	} else if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned status %d: %s", c.url, resp.StatusCode, msg)
+	}
+
+	// the bulk API succeeds as a whole even when individual documents fail
+	var result bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first string
+	for _, item := range result.Items {
+		for _, action := range item {
+			if action.Status < 200 || action.Status > 299 {
+				if failed == 0 {
+					first = fmt.Sprintf("document %s returned status %d: %s", action.ID, action.Status, action.Error)
+				}
+				failed++
+			}
+		}
+	}
+	return fmt.Errorf("%d documents failed to index, the first was %s", failed, first)
+}
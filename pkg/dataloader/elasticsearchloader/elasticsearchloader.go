@@ -0,0 +1,226 @@
+package elasticsearchloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	defaultIndexPrefix   = "sippy"
+	defaultLookbackHours = 24
+
+	// runBatchSize bounds how many job runs, and so their tests, are held in memory and sent in one bulk request.
+	runBatchSize = 50
+)
+
+// ElasticsearchLoader indexes recently loaded job runs and their test results into an Elasticsearch cluster, so they
+// can be searched and graphed in Kibana. Documents use the database IDs, so re-exporting a run overwrites it.
+type ElasticsearchLoader struct {
+	dbc    *db.DB
+	client *bulkClient
+	config v1.ElasticsearchConfig
+	now    time.Time
+	errors []error
+}
+
+func New(dbc *db.DB, config v1.ElasticsearchConfig) *ElasticsearchLoader {
+	if config.IndexPrefix == "" {
+		config.IndexPrefix = defaultIndexPrefix
+	}
+	if config.LookbackHours <= 0 {
+		config.LookbackHours = defaultLookbackHours
+	}
+
+	return &ElasticsearchLoader{
+		dbc: dbc,
+		client: newBulkClient(config.URL,
+			os.Getenv("ELASTICSEARCH_API_KEY"),
+			os.Getenv("ELASTICSEARCH_USERNAME"),
+			os.Getenv("ELASTICSEARCH_PASSWORD")),
+		config: config,
+		now:    time.Now(),
+	}
+}
+
+func (l *ElasticsearchLoader) Name() string {
+	return "elasticsearch"
+}
+
+func (l *ElasticsearchLoader) Errors() []error {
+	return l.errors
+}
+
+func (l *ElasticsearchLoader) Load(_ context.Context) {
+	since := l.now.Add(-time.Duration(l.config.LookbackHours) * time.Hour)
+
+	var runIDs []uint
+	res := l.dbc.DB.Model(&models.ProwJobRun{}).
+		Where("created_at > ?", since).
+		Order("id").
+		Pluck("id", &runIDs)
+	if res.Error != nil {
+		l.errors = append(l.errors, errors.Wrap(res.Error, "error querying job runs to export"))
+		return
+	}
+	log.Infof("exporting %d job runs to elasticsearch", len(runIDs))
+
+	for start := 0; start < len(runIDs); start += runBatchSize {
+		end := start + runBatchSize
+		if end > len(runIDs) {
+			end = len(runIDs)
+		}
+		if err := l.export(runIDs[start:end]); err != nil {
+			l.errors = append(l.errors, err)
+			// the cluster is likely unreachable, so don't keep retrying for every batch
+			return
+		}
+	}
+}
+
+func (l *ElasticsearchLoader) export(runIDs []uint) error {
+	var runs []models.ProwJobRun
+	if res := l.dbc.DB.Preload("ProwJob").Where("id IN ?", runIDs).Find(&runs); res.Error != nil {
+		return errors.Wrap(res.Error, "error querying job runs")
+	}
+
+	var tests []testRow
+	res := l.dbc.DB.Table("prow_job_run_tests").
+		Select(`prow_job_run_tests.id, prow_job_run_tests.prow_job_run_id, tests.name AS test_name,
+			suites.name AS suite_name, prow_job_run_tests.status, prow_job_run_tests.duration,
+			prow_job_run_test_outputs.output`).
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("LEFT JOIN suites ON suites.id = prow_job_run_tests.suite_id").
+		Joins("LEFT JOIN prow_job_run_test_outputs ON prow_job_run_test_outputs.prow_job_run_test_id = prow_job_run_tests.id").
+		Where("prow_job_run_tests.prow_job_run_id IN ?", runIDs).
+		Where("prow_job_run_tests.deleted_at IS NULL").
+		Scan(&tests)
+	if res.Error != nil {
+		return errors.Wrap(res.Error, "error querying job run tests")
+	}
+
+	if err := l.client.index(buildDocuments(l.config.IndexPrefix, runs, tests)); err != nil {
+		return errors.Wrap(err, "error indexing job runs")
+	}
+	return nil
+}
+
+// testRow is a job run test with the fields denormalized into its document.
+type testRow struct {
+	ID           uint
+	ProwJobRunID uint
+	TestName     string
+	SuiteName    string
+	Status       int
+	Duration     float64
+	Output       string
+}
+
+type jobRunDocument struct {
+	ID                    uint      `json:"id"`
+	Job                   string    `json:"job"`
+	Release               string    `json:"release"`
+	Variants              []string  `json:"variants"`
+	Cluster               string    `json:"cluster"`
+	URL                   string    `json:"url"`
+	Timestamp             time.Time `json:"timestamp"`
+	DurationSeconds       float64   `json:"duration_seconds"`
+	OverallResult         string    `json:"overall_result"`
+	Succeeded             bool      `json:"succeeded"`
+	Failed                bool      `json:"failed"`
+	InfrastructureFailure bool      `json:"infrastructure_failure"`
+	KnownFailure          bool      `json:"known_failure"`
+	TestFailures          int       `json:"test_failures"`
+}
+
+type jobRunTestDocument struct {
+	ID              uint      `json:"id"`
+	ProwJobRunID    uint      `json:"prow_job_run_id"`
+	Job             string    `json:"job"`
+	Release         string    `json:"release"`
+	Variants        []string  `json:"variants"`
+	Timestamp       time.Time `json:"timestamp"`
+	Test            string    `json:"test"`
+	Suite           string    `json:"suite,omitempty"`
+	Status          string    `json:"status"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Output          string    `json:"output,omitempty"`
+}
+
+// buildDocuments returns the documents for the runs and their tests, tests inherit their run's job and timestamp so
+// they can be filtered without a join.
+func buildDocuments(indexPrefix string, runs []models.ProwJobRun, tests []testRow) []document {
+	runIndex := indexPrefix + "-job-runs"
+	testIndex := indexPrefix + "-job-run-tests"
+
+	docs := make([]document, 0, len(runs)+len(tests))
+	runDocs := make(map[uint]jobRunDocument, len(runs))
+	for _, run := range runs {
+		doc := jobRunDocument{
+			ID:                    run.ID,
+			Job:                   run.ProwJob.Name,
+			Release:               run.ProwJob.Release,
+			Variants:              run.ProwJob.Variants,
+			Cluster:               run.Cluster,
+			URL:                   run.URL,
+			Timestamp:             run.Timestamp,
+			DurationSeconds:       run.Duration.Seconds(),
+			OverallResult:         string(run.OverallResult),
+			Succeeded:             run.Succeeded,
+			Failed:                run.Failed,
+			InfrastructureFailure: run.InfrastructureFailure,
+			KnownFailure:          run.KnownFailure,
+			TestFailures:          run.TestFailures,
+		}
+		runDocs[run.ID] = doc
+		docs = append(docs, document{Index: runIndex, ID: strconv.FormatUint(uint64(run.ID), 10), Body: doc})
+	}
+
+	for _, test := range tests {
+		run := runDocs[test.ProwJobRunID]
+		docs = append(docs, document{
+			Index: testIndex,
+			ID:    strconv.FormatUint(uint64(test.ID), 10),
+			Body: jobRunTestDocument{
+				ID:              test.ID,
+				ProwJobRunID:    test.ProwJobRunID,
+				Job:             run.Job,
+				Release:         run.Release,
+				Variants:        run.Variants,
+				Timestamp:       run.Timestamp,
+				Test:            test.TestName,
+				Suite:           test.SuiteName,
+				Status:          testStatus(sippyprocessingv1.TestStatus(test.Status)),
+				DurationSeconds: test.Duration,
+				Output:          test.Output,
+			},
+		})
+	}
+	return docs
+}
+
+func testStatus(status sippyprocessingv1.TestStatus) string {
+	switch status {
+	case sippyprocessingv1.TestStatusSuccess:
+		return "success"
+	case sippyprocessingv1.TestStatusFailure:
+		return "failure"
+	case sippyprocessingv1.TestStatusFlake:
+		return "flake"
+	case sippyprocessingv1.TestStatusRunning:
+		return "running"
+	case sippyprocessingv1.TestStatusAbsent:
+		return "absent"
+	}
+	return fmt.Sprintf("unknown-%d", status)
+}
@@ -0,0 +1,101 @@
+package elasticsearchloader
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestBuildDocuments(t *testing.T) {
+	timestamp := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	runs := []models.ProwJobRun{
+		{
+			Model:         gorm.Model{ID: 10},
+			ProwJob:       models.ProwJob{Name: "periodic-ci-e2e-aws", Release: "4.15", Variants: []string{"aws"}},
+			URL:           "https://prow.example.com/10",
+			Timestamp:     timestamp,
+			Duration:      90 * time.Minute,
+			OverallResult: "F",
+			Failed:        true,
+			TestFailures:  1,
+		},
+	}
+	tests := []testRow{
+		{ID: 100, ProwJobRunID: 10, TestName: "test a", SuiteName: "openshift-tests", Status: 12, Duration: 3.5, Output: "boom"},
+		{ID: 101, ProwJobRunID: 10, TestName: "test b", Status: 1},
+	}
+
+	docs := buildDocuments("sippy", runs, tests)
+	require.Len(t, docs, 3)
+
+	assert.Equal(t, "sippy-job-runs", docs[0].Index)
+	assert.Equal(t, "10", docs[0].ID)
+	run := docs[0].Body.(jobRunDocument)
+	assert.Equal(t, "periodic-ci-e2e-aws", run.Job)
+	assert.Equal(t, 5400.0, run.DurationSeconds)
+	assert.Equal(t, "F", run.OverallResult)
+
+	assert.Equal(t, "sippy-job-run-tests", docs[1].Index)
+	assert.Equal(t, "100", docs[1].ID)
+	assert.Equal(t, jobRunTestDocument{
+		ID:              100,
+		ProwJobRunID:    10,
+		Job:             "periodic-ci-e2e-aws",
+		Release:         "4.15",
+		Variants:        []string{"aws"},
+		Timestamp:       timestamp,
+		Test:            "test a",
+		Suite:           "openshift-tests",
+		Status:          "failure",
+		DurationSeconds: 3.5,
+		Output:          "boom",
+	}, docs[1].Body)
+	assert.Equal(t, "success", docs[2].Body.(jobRunTestDocument).Status)
+}
+
+func TestBulkIndex(t *testing.T) {
+	var lines []string
+	var auth string
+	response := `{"errors": false, "items": []}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		auth = r.Header.Get("Authorization")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	client := newBulkClient(server.URL+"/", "key", "", "")
+	docs := []document{
+		{Index: "sippy-job-runs", ID: "1", Body: map[string]string{"job": "a"}},
+		{Index: "sippy-job-runs", ID: "2", Body: map[string]string{"job": "b"}},
+	}
+	require.NoError(t, client.index(docs))
+	assert.Equal(t, "ApiKey key", auth)
+	assert.Equal(t, []string{
+		`{"index":{"_index":"sippy-job-runs","_id":"1"}}`,
+		`{"job":"a"}`,
+		`{"index":{"_index":"sippy-job-runs","_id":"2"}}`,
+		`{"job":"b"}`,
+	}, lines)
+
+	response = `{"errors": true, "items": [
+		{"index": {"_id": "1", "status": 201}},
+		{"index": {"_id": "2", "status": 400, "error": {"type": "mapper_parsing_exception"}}}
+	]}`
+	err := client.index(docs)
+	require.Error(t, err)
+	assert.Equal(t, `1 documents failed to index, the first was document 2 returned status 400: {"type": "mapper_parsing_exception"}`, err.Error())
+}
@@ -1,6 +1,7 @@
 package incidentloader
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -34,7 +35,7 @@ func (jl *IncidentLoader) Name() string {
 	return "jira"
 }
 
-func (jl *IncidentLoader) Load() {
+func (jl *IncidentLoader) Load(_ context.Context) {
 	start := time.Now()
 	log.Infof("populating unresolved jira incident cache...")
 	var dbIssues []string
@@ -1,6 +1,7 @@
 package jiraloader
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,7 +38,7 @@ func (jl *JiraLoader) Name() string {
 	return "jira"
 }
 
-func (jl *JiraLoader) Load() {
+func (jl *JiraLoader) Load(_ context.Context) {
 	// Load components into DB
 	jl.componentLoader()
 
@@ -1,6 +1,7 @@
 package loaderwithmetrics
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -10,8 +11,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/push"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm/clause"
 
 	"github.com/openshift/sippy/pkg/dataloader"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/tracing"
 )
 
 var loadMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
@@ -27,13 +33,17 @@ var errorMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
 }, []string{"loader"})
 
 type LoaderWithMetrics struct {
+	dbc        *db.DB
 	loaders    []dataloader.DataLoader
 	promPusher *push.Pusher
+	durations  map[string]time.Duration
 }
 
-func New(wrappedLoaders []dataloader.DataLoader) *LoaderWithMetrics {
+func New(dbc *db.DB, wrappedLoaders []dataloader.DataLoader) *LoaderWithMetrics {
 	loader := &LoaderWithMetrics{
-		loaders: wrappedLoaders,
+		dbc:       dbc,
+		loaders:   wrappedLoaders,
+		durations: map[string]time.Duration{},
 	}
 
 	if pushgateway := os.Getenv("SIPPY_PROMETHEUS_PUSHGATEWAY"); pushgateway != "" {
@@ -45,18 +55,25 @@ func New(wrappedLoaders []dataloader.DataLoader) *LoaderWithMetrics {
 	return loader
 }
 
-func (l *LoaderWithMetrics) Load() {
+func (l *LoaderWithMetrics) Load(ctx context.Context) {
 	overallStart := time.Now()
 	log.Infof("starting %d loaders...", len(l.loaders))
 	for _, loader := range l.loaders {
 		log.Infof("starting loader %q with metrics wrapper", loader.Name())
 		start := time.Now()
-		loader.Load()
+		loaderCtx, span := tracing.Tracer().Start(ctx, "load "+loader.Name())
+		loader.Load(loaderCtx)
+		span.SetAttributes(attribute.Int("sippy.loader.errors", len(loader.Errors())))
+		span.End()
 		totalTime := time.Since(start)
+		l.durations[loader.Name()] = totalTime
 		log.Infof("loader %q complete after %+v", loader.Name(), totalTime)
 
 		loadMetric.WithLabelValues(loader.Name()).Observe(float64(totalTime.Milliseconds()))
 		errorMetric.WithLabelValues(loader.Name()).Observe(float64(len(loader.Errors())))
+		if len(loader.Errors()) == 0 {
+			l.recordSuccess(loader.Name())
+		}
 	}
 	overallDuration := time.Since(overallStart)
 	log.Infof("%d loaders finished in %+v...", len(l.loaders), overallDuration)
@@ -72,6 +89,19 @@ func (l *LoaderWithMetrics) Load() {
 	}
 }
 
+// recordSuccess stores when the named loader last completed without errors, which the server reports as a metric.
+func (l *LoaderWithMetrics) recordSuccess(name string) {
+	success := models.LoaderSuccess{Loader: name, SucceededAt: time.Now()}
+	if res := l.dbc.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&success); res.Error != nil {
+		log.WithError(res.Error).Warningf("error recording the success of loader %q", name)
+	}
+}
+
+// Duration returns how long the named loader took to load.
+func (l *LoaderWithMetrics) Duration(name string) time.Duration {
+	return l.durations[name]
+}
+
 func (l *LoaderWithMetrics) Errors() []error {
 	var errs []error
 	for _, loader := range l.loaders {
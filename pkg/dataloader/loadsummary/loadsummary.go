@@ -0,0 +1,138 @@
+// Package loadsummary summarizes a load, i.e. the rows written to each table, each loader's duration and errors, and
+// how recent the loaded data is, as a JSON artifact orchestration can check the load's quality with.
+package loadsummary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/openshift/sippy/pkg/archive"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// Summary is the outcome of a load.
+type Summary struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Releases   []string  `json:"releases,omitempty"`
+	Tenant     string    `json:"tenant,omitempty"`
+	Loaders    []Loader  `json:"loaders"`
+	Tables     []Table   `json:"tables"`
+	// Watermarks are the latest times of the data in the loaded tables, i.e. the newest job run or release tag, by
+	// table.
+	Watermarks map[string]*time.Time `json:"watermarks"`
+	// Errors counts every error of the load, including those outside of the loaders, and Success is whether there were
+	// none.
+	Errors  int  `json:"errors"`
+	Success bool `json:"success"`
+}
+
+// Loader is a loader's duration and errors.
+type Loader struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+	Errors     int    `json:"errors"`
+	FirstError string `json:"first_error,omitempty"`
+}
+
+// Table counts the rows written to a table during the load, from postgres' statistics, which are updated shortly
+// after each transaction, so they're approximate.
+type Table struct {
+	Name     string `json:"name"`
+	Inserted int64  `json:"inserted"`
+	Updated  int64  `json:"updated"`
+	Deleted  int64  `json:"deleted"`
+}
+
+// watermarkColumns are the columns holding the time of the data in tables, whose latest value is the table's
+// watermark.
+var watermarkColumns = map[string]string{
+	"prow_job_runs": "timestamp",
+	"release_tags":  "release_time",
+	"bugs":          "last_change_time",
+}
+
+// TableStats are the cumulative rows written to each table, to take those written during the load from.
+type TableStats map[string]Table
+
+// GetTableStats returns the rows written to the tables since postgres' statistics were last reset.
+func GetTableStats(dbc *db.DB, tables []string) (TableStats, error) {
+	rows := []Table{}
+	res := dbc.DB.Raw(`SELECT relname AS name, n_tup_ins AS inserted, n_tup_upd AS updated, n_tup_del AS deleted
+		FROM pg_stat_user_tables WHERE relname IN (?)`, tables).Scan(&rows)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	stats := TableStats{}
+	for _, row := range rows {
+		stats[row.Name] = row
+	}
+	return stats, nil
+}
+
+// Since returns the rows written to each table after the earlier stats, by table name.
+func (s TableStats) Since(earlier TableStats) []Table {
+	tables := make([]Table, 0, len(s))
+	for name, table := range s {
+		before := earlier[name]
+		tables = append(tables, Table{
+			Name:     name,
+			Inserted: table.Inserted - before.Inserted,
+			Updated:  table.Updated - before.Updated,
+			Deleted:  table.Deleted - before.Deleted,
+		})
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables
+}
+
+// GetWatermarks returns the latest time of the data in each of the tables that has one, nil for an empty table.
+func GetWatermarks(dbc *db.DB, tables []string) (map[string]*time.Time, error) {
+	watermarks := map[string]*time.Time{}
+	for _, table := range tables {
+		column, ok := watermarkColumns[table]
+		if !ok {
+			continue
+		}
+		var watermark *time.Time
+		if res := dbc.DB.Raw(fmt.Sprintf("SELECT MAX(%s) FROM %s", column, table)).Scan(&watermark); res.Error != nil {
+			return nil, res.Error
+		}
+		watermarks[table] = watermark
+	}
+	return watermarks, nil
+}
+
+// Write writes the summary as JSON to the location, a gs://bucket/path object written with the client, or a local
+// file.
+func Write(ctx context.Context, location string, client *storage.Client, summary *Summary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir, name := path.Split(location)
+	if name == "" {
+		return fmt.Errorf("load summary location %q is a directory, expected a file", location)
+	}
+	switch dir {
+	case "":
+		dir = "."
+	case "/":
+	default:
+		dir = strings.TrimSuffix(dir, "/")
+	}
+	store, err := archive.NewStore(dir, client)
+	if err != nil {
+		return err
+	}
+	return store.Put(ctx, name, data)
+}
@@ -0,0 +1,48 @@
+package loadsummary
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStatsSince(t *testing.T) {
+	before := TableStats{
+		"prow_job_runs": {Name: "prow_job_runs", Inserted: 100, Updated: 10, Deleted: 1},
+	}
+	after := TableStats{
+		"prow_job_runs":      {Name: "prow_job_runs", Inserted: 150, Updated: 12, Deleted: 1},
+		"prow_job_run_tests": {Name: "prow_job_run_tests", Inserted: 5000},
+	}
+
+	assert.Equal(t, []Table{
+		{Name: "prow_job_run_tests", Inserted: 5000},
+		{Name: "prow_job_runs", Inserted: 50, Updated: 2},
+	}, after.Since(before))
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	summary := &Summary{
+		Loaders: []Loader{{Name: "prow", DurationMS: 1200, Errors: 1, FirstError: "couldn't read junit"}},
+		Tables:  []Table{{Name: "prow_job_runs", Inserted: 50}},
+		Errors:  1,
+	}
+
+	location := filepath.Join(dir, "load", "summary.json")
+	require.NoError(t, Write(context.Background(), location, nil, summary))
+
+	data, err := os.ReadFile(location)
+	require.NoError(t, err)
+	written := &Summary{}
+	require.NoError(t, json.Unmarshal(data, written))
+	assert.Equal(t, summary, written)
+
+	assert.Error(t, Write(context.Background(), dir+"/", nil, summary), "a directory isn't a summary location")
+	assert.Error(t, Write(context.Background(), "gs://bucket/summary.json", nil, summary), "gs:// requires a client")
+}
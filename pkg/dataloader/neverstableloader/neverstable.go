@@ -0,0 +1,128 @@
+package neverstableloader
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+const (
+	defaultPassRateThreshold = 10.0
+	defaultWeeks             = 2
+)
+
+// NeverStableLoader flags jobs whose pass rate has stayed below a threshold for several consecutive weeks as
+// never-stable. The flag is persisted on the ProwJob, and excludes the job from normal variants the next time the
+// prow loader runs.
+type NeverStableLoader struct {
+	dbc    *db.DB
+	config v1.NeverStableConfig
+	now    time.Time
+	errors []error
+}
+
+func New(dbc *db.DB, config v1.NeverStableConfig) *NeverStableLoader {
+	if config.PassRateThreshold <= 0 {
+		config.PassRateThreshold = defaultPassRateThreshold
+	}
+	if config.Weeks <= 0 {
+		config.Weeks = defaultWeeks
+	}
+
+	return &NeverStableLoader{
+		dbc:    dbc,
+		config: config,
+		now:    time.Now(),
+	}
+}
+
+func (l *NeverStableLoader) Name() string {
+	return "never-stable"
+}
+
+func (l *NeverStableLoader) Errors() []error {
+	return l.errors
+}
+
+func (l *NeverStableLoader) Load(_ context.Context) {
+	// Only complete weeks are considered, so a bad start to the current week doesn't flag a job.
+	end := startOfWeek(l.now)
+	start := end.AddDate(0, 0, -7*l.config.Weeks)
+
+	passRates, err := query.JobWeeklyPassRates(l.dbc, start, end)
+	if err != nil {
+		l.errors = append(l.errors, errors.Wrap(err, "error querying weekly job pass rates"))
+		return
+	}
+
+	neverStable := classify(passRates, start, l.config)
+	log.WithField("count", neverStable.Len()).Info("classified never-stable jobs")
+
+	if err := l.update(neverStable); err != nil {
+		l.errors = append(l.errors, err)
+	}
+}
+
+func (l *NeverStableLoader) update(neverStable sets.String) error {
+	res := l.dbc.DB.Model(&models.ProwJob{}).
+		Where("never_stable = ?", true).
+		Where("name NOT IN ?", query.NotInList(neverStable.List())).
+		UpdateColumn("never_stable", false)
+	if res.Error != nil {
+		return errors.Wrap(res.Error, "error clearing never-stable jobs")
+	}
+	log.Infof("%d jobs are no longer never-stable", res.RowsAffected)
+
+	if neverStable.Len() == 0 {
+		return nil
+	}
+	res = l.dbc.DB.Model(&models.ProwJob{}).
+		Where("never_stable = ?", false).
+		Where("name IN ?", neverStable.List()).
+		UpdateColumn("never_stable", true)
+	if res.Error != nil {
+		return errors.Wrap(res.Error, "error flagging never-stable jobs")
+	}
+	log.Infof("%d jobs are newly never-stable", res.RowsAffected)
+
+	return nil
+}
+
+// classify returns the jobs with runs in every week from start, whose pass rate was below the threshold in each of
+// them, adjusted by the configured includes and excludes.
+func classify(passRates []query.JobWeeklyPassRate, start time.Time, config v1.NeverStableConfig) sets.String {
+	lowWeeks := map[string]sets.String{}
+	for _, pr := range passRates {
+		if pr.Week.Before(start) || pr.Runs == 0 || pr.PassPercentage >= config.PassRateThreshold {
+			continue
+		}
+		if _, ok := lowWeeks[pr.Name]; !ok {
+			lowWeeks[pr.Name] = sets.NewString()
+		}
+		lowWeeks[pr.Name].Insert(pr.Week.UTC().Format("2006-01-02"))
+	}
+
+	neverStable := sets.NewString(config.Include...)
+	for name, weeks := range lowWeeks {
+		if weeks.Len() >= config.Weeks {
+			neverStable.Insert(name)
+		}
+	}
+
+	return neverStable.Delete(config.Exclude...)
+}
+
+// startOfWeek returns midnight UTC on the Monday of t's week, matching postgres' date_trunc('week').
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,81 @@
+package neverstableloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestClassify(t *testing.T) {
+	start := time.Date(2023, 10, 16, 0, 0, 0, 0, time.UTC)
+	week1, week2 := start, start.AddDate(0, 0, 7)
+	config := v1.NeverStableConfig{PassRateThreshold: 10, Weeks: 2}
+
+	tests := []struct {
+		name      string
+		passRates []query.JobWeeklyPassRate
+		include   []string
+		exclude   []string
+		want      []string
+	}{
+		{
+			name: "low pass rate every week",
+			passRates: []query.JobWeeklyPassRate{
+				{Name: "job-a", Week: week1, Runs: 10, PassPercentage: 0},
+				{Name: "job-a", Week: week2, Runs: 8, PassPercentage: 5},
+			},
+			want: []string{"job-a"},
+		},
+		{
+			name: "recovered in one week",
+			passRates: []query.JobWeeklyPassRate{
+				{Name: "job-a", Week: week1, Runs: 10, PassPercentage: 0},
+				{Name: "job-a", Week: week2, Runs: 8, PassPercentage: 50},
+			},
+			want: []string{},
+		},
+		{
+			name: "no runs in one week",
+			passRates: []query.JobWeeklyPassRate{
+				{Name: "job-a", Week: week2, Runs: 8, PassPercentage: 0},
+			},
+			want: []string{},
+		},
+		{
+			name: "week before the window is ignored",
+			passRates: []query.JobWeeklyPassRate{
+				{Name: "job-a", Week: week1.AddDate(0, 0, -7), Runs: 10, PassPercentage: 0},
+				{Name: "job-a", Week: week2, Runs: 8, PassPercentage: 0},
+			},
+			want: []string{},
+		},
+		{
+			name: "includes and excludes",
+			passRates: []query.JobWeeklyPassRate{
+				{Name: "job-a", Week: week1, Runs: 10, PassPercentage: 0},
+				{Name: "job-a", Week: week2, Runs: 8, PassPercentage: 0},
+			},
+			include: []string{"job-b"},
+			exclude: []string{"job-a"},
+			want:    []string{"job-b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config
+			c.Include = tt.include
+			c.Exclude = tt.exclude
+			assert.Equal(t, tt.want, classify(tt.passRates, start, c).List())
+		})
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	monday := time.Date(2023, 10, 16, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, startOfWeek(time.Date(2023, 10, 22, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, monday, startOfWeek(monday.Add(time.Hour)))
+}
@@ -0,0 +1,179 @@
+package prowloader
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+)
+
+// snowflakeEpoch is the epoch of prow's snowflake build IDs, in milliseconds. The build ID shifted right 22 bits is
+// the milliseconds since then the ID was allocated.
+const snowflakeEpoch = 1288834974657
+
+// backfillMargin allows for the time between a build ID being allocated and its job starting, when build IDs are used
+// to skip runs outside the backfill window without reading them.
+const backfillMargin = time.Hour
+
+// SetBackfillWindow makes the loader import the runs that started in [from, to) by walking the job directories in the
+// GCS bucket, instead of the jobs prow or BigQuery report completed since the last import. Runs already in the
+// database are skipped, so a window can be backfilled again to fill gaps.
+func (pl *ProwLoader) SetBackfillWindow(from, to time.Time) {
+	pl.backfillFrom = from
+	pl.backfillTo = to
+}
+
+func (pl *ProwLoader) backfilling() bool {
+	return !pl.backfillTo.IsZero()
+}
+
+// fetchProwJobsFromGCS reads the prowjob.json of every run of a configured job in the backfill window. Only the
+// periodic and postsubmit runs under logs/ are walked, presubmits are stored by pull request.
+func (pl *ProwLoader) fetchProwJobsFromGCS(ctx context.Context) ([]prow.ProwJob, []error) {
+	log.Infof("Backfilling prow jobs from gs://%s started from %s to %s", pl.bktName,
+		pl.backfillFrom.UTC().Format(time.RFC3339), pl.backfillTo.UTC().Format(time.RFC3339))
+
+	jobDirs, err := pl.listGCSDirs(ctx, "logs/")
+	if err != nil {
+		return nil, []error{errors.Wrap(err, "error listing jobs in gcs")}
+	}
+
+	errs := []error{}
+	var prowJobPaths []string
+	for _, jobDir := range jobDirs {
+		if pl.releaseForJob(path.Base(jobDir)) == "" {
+			continue
+		}
+		buildDirs, err := pl.listGCSDirs(ctx, jobDir)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error listing runs of %s in gcs", path.Base(jobDir)))
+			continue
+		}
+		for _, buildDir := range buildDirs {
+			if !pl.skipBackfillBuild(path.Base(buildDir)) {
+				prowJobPaths = append(prowJobPaths, buildDir+"prowjob.json")
+			}
+		}
+	}
+	log.Infof("reading %d job runs from gcs", len(prowJobPaths))
+
+	queue := make(chan string)
+	go func() {
+		defer close(queue)
+		for _, p := range prowJobPaths {
+			select {
+			case queue <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	prowJobs := make([]prow.ProwJob, 0)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < pl.maxConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range queue {
+				pj, err := pl.readProwJob(ctx, p)
+				lock.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else if pj != nil && pl.inBackfillWindow(pj.Status.StartTime) {
+					prowJobs = append(prowJobs, *pj)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	log.Infof("found %d job runs in gcs started in the backfill window", len(prowJobs))
+	return prowJobs, errs
+}
+
+// listGCSDirs returns the "directories" directly under the prefix, each ending in a slash.
+func (pl *ProwLoader) listGCSDirs(ctx context.Context, prefix string) ([]string, error) {
+	dirs := make([]string, 0)
+	it := pl.bkt.Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attrs.Prefix != "" {
+			dirs = append(dirs, attrs.Prefix)
+		}
+	}
+	return dirs, nil
+}
+
+// readProwJob reads a run's prowjob.json, returning nil if the run doesn't have one, i.e. it was aborted before prow
+// uploaded it.
+func (pl *ProwLoader) readProwJob(ctx context.Context, prowJobPath string) (*prow.ProwJob, error) {
+	data, err := gcs.NewGCSJobRun(pl.bkt, path.Dir(prowJobPath)).GetContent(ctx, prowJobPath)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		log.Warningf("skipping %s, it has no prowjob.json", path.Dir(prowJobPath))
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s", prowJobPath)
+	}
+
+	pj := &prow.ProwJob{}
+	if err := json.Unmarshal(data, pj); err != nil {
+		return nil, errors.Wrapf(err, "error decoding %s", prowJobPath)
+	}
+	return pj, nil
+}
+
+// skipBackfillBuild reports whether a run can be skipped without reading it, because it's already imported, or its
+// build ID shows it started outside the backfill window.
+func (pl *ProwLoader) skipBackfillBuild(buildID string) bool {
+	id, err := strconv.ParseUint(buildID, 10, 64)
+	if err != nil {
+		return true
+	}
+
+	pl.prowJobRunCacheLock.RLock()
+	imported := pl.prowJobRunCache[uint(id)]
+	pl.prowJobRunCacheLock.RUnlock()
+	if imported {
+		return true
+	}
+
+	allocated, ok := buildIDTime(id)
+	if !ok {
+		// not a snowflake ID, the run has to be read to find when it started
+		return false
+	}
+	return allocated.Before(pl.backfillFrom.Add(-backfillMargin)) || !allocated.Before(pl.backfillTo.Add(backfillMargin))
+}
+
+func (pl *ProwLoader) inBackfillWindow(start time.Time) bool {
+	return !start.Before(pl.backfillFrom) && start.Before(pl.backfillTo)
+}
+
+// buildIDTime returns when a snowflake build ID was allocated. Older prow build IDs were sequential numbers, which
+// decode to a time before snowflake IDs were used and aren't reported as ok.
+func buildIDTime(id uint64) (time.Time, bool) {
+	allocated := time.UnixMilli(int64(id>>22) + snowflakeEpoch).UTC()
+	if allocated.Before(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		return time.Time{}, false
+	}
+	return allocated, true
+}
@@ -0,0 +1,43 @@
+package prowloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildIDTime(t *testing.T) {
+	allocated, ok := buildIDTime(1737420379221135360)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2023, 12, 20, 10, 31, 0, 0, time.UTC), allocated.Truncate(time.Minute))
+
+	_, ok = buildIDTime(4521)
+	assert.False(t, ok, "sequential build IDs don't encode a time")
+}
+
+func TestSkipBackfillBuild(t *testing.T) {
+	pl := &ProwLoader{prowJobRunCache: map[uint]bool{1737420379221135360: true}}
+	pl.SetBackfillWindow(time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC), time.Date(2023, 12, 21, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		buildID string
+		skip    bool
+	}{
+		{name: "already imported", buildID: "1737420379221135360", skip: true},
+		{name: "in window", buildID: "1737420379221135361", skip: false},
+		{name: "before window", buildID: "1736000000000000000", skip: true},
+		{name: "after window", buildID: "1738000000000000000", skip: true},
+		{name: "sequential id", buildID: "4521", skip: false},
+		{name: "not a build", buildID: "latest-build.txt", skip: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.skip, pl.skipBackfillBuild(tt.buildID))
+		})
+	}
+
+	assert.True(t, pl.inBackfillWindow(time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, pl.inBackfillWindow(time.Date(2023, 12, 21, 0, 0, 0, 0, time.UTC)))
+}
@@ -11,9 +11,10 @@ import (
 	"google.golang.org/api/iterator"
 
 	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/tracing"
 )
 
-func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []error) {
+func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery(ctx context.Context) ([]prow.ProwJob, []error) {
 	errs := []error{}
 
 	// Figure out our last imported job timestamp:
@@ -59,7 +60,9 @@ func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []er
 			Value: lastProwJobRun,
 		},
 	}
-	it, err := query.Read(context.TODO())
+	ctx, span := tracing.StartQuerySpan(ctx, "bigquery", query.Q)
+	defer span.End()
+	it, err := query.Read(ctx)
 	if err != nil {
 		errs = append(errs, err)
 		log.WithError(err).Error("error querying jobs from bigquery")
@@ -1,29 +1,36 @@
 package gcs
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
 	"fmt"
-	"io"
 	"regexp"
+	"sync"
 
 	"cloud.google.com/go/storage"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/api/iterator"
 
 	"github.com/openshift/sippy/pkg/apis/junit"
+	"github.com/openshift/sippy/pkg/tracing"
 )
 
 const TestFailureSummaryFilePrefix = "risk-analysis"
 const ClusterDataFilePrefix = "cluster-data_"
 const JunitRegExStr = "\\/junit.*xml"
 const intervalFilesRegExStr = "\\/e2e-events.*json"
+const buildLogRegExStr = "\\/build-log\\.txt$"
+const mustGatherRegExStr = "\\/must-gather[^/]*\\.tar(\\.gz)?$"
 
 var (
 	defaultRiskAnalysisSummaryFileRegEx *regexp.Regexp
 	defaultClusterDataFileRegEx         *regexp.Regexp
 	defaultJunitFileRegEx               *regexp.Regexp
 	intervalFilesRegex                  *regexp.Regexp
+	buildLogRegex                       *regexp.Regexp
+	mustGatherRegex                     *regexp.Regexp
 )
 
 func GetDefaultRiskAnalysisSummaryFile() *regexp.Regexp {
@@ -54,6 +61,32 @@ func GetIntervalFile() *regexp.Regexp {
 	return intervalFilesRegex
 }
 
+// GetBuildLogFile matches the build logs of a run, the run's own build-log.txt and those of each of its steps.
+func GetBuildLogFile() *regexp.Regexp {
+	if buildLogRegex == nil {
+		buildLogRegex = regexp.MustCompile(buildLogRegExStr)
+	}
+	return buildLogRegex
+}
+
+// GetMustGatherFile matches the must-gather archives gathered from a run's cluster.
+func GetMustGatherFile() *regexp.Regexp {
+	if mustGatherRegex == nil {
+		mustGatherRegex = regexp.MustCompile(mustGatherRegExStr)
+	}
+	return mustGatherRegex
+}
+
+// maxPooledJUnitBuffer is the largest buffer kept to read the next junit file into, so one huge file doesn't hold on
+// to its memory for the rest of the load.
+const maxPooledJUnitBuffer = 16 * 1024 * 1024
+
+// junitBuffers are reused to read junit files, which are discarded once parsed, rather than allocating a buffer the
+// size of every file.
+var junitBuffers = sync.Pool{
+	New: func() interface{} { return &bytes.Buffer{} },
+}
+
 type GCSJobRun struct {
 	// retrieval mechanisms
 	bkt *storage.BucketHandle
@@ -62,6 +95,9 @@ type GCSJobRun struct {
 	gcsJunitPaths  []string
 
 	pathToContent map[string][]byte
+
+	// junitRecorder is called with each junit file read from the bucket.
+	junitRecorder func(path string, content []byte)
 }
 
 func NewGCSJobRun(bkt *storage.BucketHandle, path string) *GCSJobRun {
@@ -75,6 +111,21 @@ func (j *GCSJobRun) SetGCSJunitPaths(paths []string) {
 	j.gcsJunitPaths = paths
 }
 
+// SetContent sets the content of an object, which is then used instead of reading the object from the bucket, i.e. to
+// parse junit files kept from an earlier load.
+func (j *GCSJobRun) SetContent(path string, content []byte) {
+	if j.pathToContent == nil {
+		j.pathToContent = map[string][]byte{}
+	}
+	j.pathToContent[path] = content
+}
+
+// SetJUnitRecorder sets a function called with each junit file read from the bucket, before it's parsed. The content
+// is only valid during the call, its buffer is reused for the next file.
+func (j *GCSJobRun) SetJUnitRecorder(recorder func(path string, content []byte)) {
+	j.junitRecorder = recorder
+}
+
 func (j *GCSJobRun) GetGCSJunitPaths() []string {
 	if len(j.gcsJunitPaths) == 0 {
 		matches := j.FindAllMatches([]*regexp.Regexp{GetDefaultJunitFile()})
@@ -88,64 +139,166 @@ func (j *GCSJobRun) GetGCSJunitPaths() []string {
 }
 
 func (j *GCSJobRun) GetCombinedJUnitTestSuites(ctx context.Context) (*junit.TestSuites, error) {
+	buf := junitBuffers.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledJUnitBuffer {
+			junitBuffers.Put(buf)
+		}
+	}()
+
 	testSuites := &junit.TestSuites{}
 	for _, junitFile := range j.GetGCSJunitPaths() {
-		junitContent, err := j.GetContent(ctx, junitFile)
-		if err != nil {
-			return nil, fmt.Errorf("error getting content for jobrun %w", err)
+		content, ok := j.pathToContent[junitFile]
+		if !ok {
+			buf.Reset()
+			if err := j.readContent(ctx, junitFile, buf); err != nil {
+				return nil, fmt.Errorf("error getting content for jobrun %w", err)
+			}
+			content = buf.Bytes()
+			if j.junitRecorder != nil {
+				j.junitRecorder(junitFile, content)
+			}
 		}
 		// if the file was retrieve, but the content was empty, there is no work to be done.
-		if len(junitContent) == 0 {
+		if len(content) == 0 {
 			continue
 		}
 
-		// try as testsuites first just in case we are one
-		currTestSuites := &junit.TestSuites{}
-		testSuitesErr := xml.Unmarshal(junitContent, currTestSuites)
-		if testSuitesErr == nil {
-			testSuites.Suites = append(testSuites.Suites, currTestSuites.Suites...)
+		suites, err := parseJUnit(content)
+		if err != nil {
+			log.WithError(err).Warningf("error parsing content for jobrun in file %s path %s", junitFile, j.gcsProwJobPath)
 			continue
 		}
+		testSuites.Suites = append(testSuites.Suites, suites...)
+	}
+
+	return testSuites, nil
+}
 
-		currTestSuite := &junit.TestSuite{}
-		if testSuiteErr := xml.Unmarshal(junitContent, currTestSuite); testSuiteErr != nil {
-			log.WithError(testSuiteErr).Warningf("error parsing content for jobrun in file %s path %s", junitFile, j.gcsProwJobPath)
+// parseJUnit parses a junit file, whose root is either a <testsuites> or a single <testsuite>. The output tests write
+// to stderr, and suites to stdout, isn't kept, sippy doesn't use it and it's often most of the file. The stdout of
+// test cases is kept for the logs of failed tests.
+func parseJUnit(content []byte) ([]*junit.TestSuite, error) {
+	decoder := xml.NewTokenDecoder(&skipElements{
+		decoder:    xml.NewDecoder(bytes.NewReader(content)),
+		skip:       map[string]bool{"system-out": true, "system-err": true},
+		keepWithin: map[string]string{"system-out": "testcase"},
+	})
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		root, ok := token.(xml.StartElement)
+		if !ok {
 			continue
 		}
-		testSuites.Suites = append(testSuites.Suites, currTestSuite)
+
+		switch root.Name.Local {
+		case "testsuites":
+			suites := &junit.TestSuites{}
+			if err := decoder.DecodeElement(suites, &root); err != nil {
+				return nil, err
+			}
+			return suites.Suites, nil
+		case "testsuite":
+			suite := &junit.TestSuite{}
+			if err := decoder.DecodeElement(suite, &root); err != nil {
+				return nil, err
+			}
+			return []*junit.TestSuite{suite}, nil
+		default:
+			return nil, fmt.Errorf("unexpected junit root element <%s>", root.Name.Local)
+		}
 	}
+}
 
-	return testSuites, nil
+// skipElements passes on the tokens of an XML document, except for the elements to skip and their contents, so they
+// aren't decoded into strings that are discarded anyway. An element to skip is still passed on when its parent is the
+// one keepWithin names for it.
+type skipElements struct {
+	decoder    *xml.Decoder
+	skip       map[string]bool
+	keepWithin map[string]string
+	parents    []string
 }
 
-func (j *GCSJobRun) GetContent(ctx context.Context, path string) ([]byte, error) {
-	if len(path) == 0 {
-		return nil, fmt.Errorf("missing path to GCS content for jobrun")
+func (s *skipElements) Token() (xml.Token, error) {
+	for {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return token, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if s.skip[t.Name.Local] && !s.keep(t.Name.Local) {
+				if err := s.decoder.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			s.parents = append(s.parents, t.Name.Local)
+		case xml.EndElement:
+			if len(s.parents) > 0 {
+				s.parents = s.parents[:len(s.parents)-1]
+			}
+		}
+		return token, nil
 	}
+}
+
+func (s *skipElements) keep(name string) bool {
+	parent, ok := s.keepWithin[name]
+	return ok && len(s.parents) > 0 && s.parents[len(s.parents)-1] == parent
+}
+
+func (j *GCSJobRun) GetContent(ctx context.Context, path string) (content []byte, err error) {
 	if content, ok := j.pathToContent[path]; ok {
 		return content, nil
 	}
 
+	buf := &bytes.Buffer{}
+	if err := j.readContent(ctx, path, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readContent reads an object into the buffer, growing it once to the object's size.
+func (j *GCSJobRun) readContent(ctx context.Context, path string, buf *bytes.Buffer) (err error) {
+	if len(path) == 0 {
+		return fmt.Errorf("missing path to GCS content for jobrun")
+	}
+
 	// Get an Object handle for the path
 	obj := j.bkt.Object(path)
 
+	ctx, span := tracing.Tracer().Start(ctx, "gcs.GetContent")
+	span.SetAttributes(attribute.String("gcs.bucket", obj.BucketName()), attribute.String("gcs.object", path))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	// use the object attributes to try to get the latest generation to try to retrieve the data without getting a cached
 	// version of data that does not match the latest content.  I don't know if this will work, but in the easy case
 	// it doesn't seem to fail.
 	objAttrs, err := obj.Attrs(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error reading GCS attributes for jobrun: %w", err)
+		return fmt.Errorf("error reading GCS attributes for jobrun: %w", err)
 	}
 	obj = obj.Generation(objAttrs.Generation)
 
 	// Get an io.Reader for the object.
 	gcsReader, err := obj.NewReader(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error reading GCS content for jobrun: %w", err)
+		return fmt.Errorf("error reading GCS content for jobrun: %w", err)
 	}
 	defer gcsReader.Close()
 
-	return io.ReadAll(gcsReader)
+	// ReadFrom grows the buffer whenever it has less than MinRead bytes free, including once the object is read
+	if objAttrs.Size > 0 {
+		buf.Grow(int(objAttrs.Size) + bytes.MinRead)
+	}
+	_, err = buf.ReadFrom(gcsReader)
+	return err
 }
 
 func (j *GCSJobRun) ContentExists(ctx context.Context, path string) bool {
@@ -0,0 +1,112 @@
+package gcs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJUnit(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		expectedSuites []string
+		expectedTests  int
+		expectedErr    bool
+	}{
+		{
+			name: "testsuites",
+			content: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="openshift-tests" tests="2">
+    <testcase name="passes" time="1.5"><system-out>lots of logs</system-out></testcase>
+    <testcase name="fails"><failure message="boom">stack</failure><system-err>more logs</system-err></testcase>
+  </testsuite>
+  <testsuite name="cluster install" tests="1">
+    <testcase name="install should succeed"></testcase>
+    <system-out>suite logs</system-out>
+  </testsuite>
+</testsuites>`,
+			expectedSuites: []string{"openshift-tests", "cluster install"},
+			expectedTests:  3,
+		},
+		{
+			name: "testsuite",
+			content: `<testsuite name="openshift-tests" tests="1">
+  <testcase name="passes"><system-out><![CDATA[<not xml>]]></system-out></testcase>
+</testsuite>`,
+			expectedSuites: []string{"openshift-tests"},
+			expectedTests:  1,
+		},
+		{
+			name:        "not junit",
+			content:     `<html><body>not found</body></html>`,
+			expectedErr: true,
+		},
+		{
+			name:        "truncated",
+			content:     `<testsuite name="openshift-tests"><testcase name="passes">`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			suites, err := parseJUnit([]byte(tc.content))
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(suites))
+			tests := 0
+			for _, suite := range suites {
+				names = append(names, suite.Name)
+				for _, testCase := range suite.TestCases {
+					tests++
+					assert.Empty(t, testCase.SystemErr)
+				}
+			}
+			assert.Equal(t, tc.expectedSuites, names)
+			assert.Equal(t, tc.expectedTests, tests)
+		})
+	}
+}
+
+func TestParseJUnitFailure(t *testing.T) {
+	suites, err := parseJUnit([]byte(`<testsuite name="s"><testcase name="fails" time="2">` +
+		`<failure message="boom">stack trace</failure><system-out>logs</system-out>` +
+		`<system-err>errors</system-err></testcase><system-out>suite logs</system-out></testsuite>`))
+	require.NoError(t, err)
+	require.Len(t, suites, 1)
+	require.Len(t, suites[0].TestCases, 1)
+
+	testCase := suites[0].TestCases[0]
+	assert.Equal(t, "fails", testCase.Name)
+	assert.Equal(t, 2.0, testCase.Duration)
+	require.NotNil(t, testCase.FailureOutput)
+	assert.Equal(t, "boom", testCase.FailureOutput.Message)
+	assert.Equal(t, "stack trace", testCase.FailureOutput.Output)
+	assert.Equal(t, "logs", testCase.SystemOut, "the system-out of test cases is kept for the failed tests' logs")
+	assert.Empty(t, testCase.SystemErr)
+}
+
+func TestGetCombinedJUnitTestSuitesSetContent(t *testing.T) {
+	// the content is set, so the nil bucket is never read
+	jobRun := NewGCSJobRun(nil, "logs/job/1/")
+	jobRun.SetGCSJunitPaths([]string{"logs/job/1/artifacts/junit_e2e.xml", "logs/job/1/artifacts/junit_empty.xml"})
+	jobRun.SetContent("logs/job/1/artifacts/junit_e2e.xml",
+		[]byte(`<testsuite name="openshift-tests"><testcase name="passes"></testcase></testsuite>`))
+	jobRun.SetContent("logs/job/1/artifacts/junit_empty.xml", []byte{})
+	recorded := 0
+	jobRun.SetJUnitRecorder(func(path string, content []byte) { recorded++ })
+
+	suites, err := jobRun.GetCombinedJUnitTestSuites(context.Background())
+	require.NoError(t, err)
+	require.Len(t, suites.Suites, 1)
+	assert.Equal(t, "openshift-tests", suites.Suites[0].Name)
+	assert.Zero(t, recorded, "only files read from the bucket are recorded")
+}
@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v45/github"
+	log "github.com/sirupsen/logrus"
+)
+
+// prBatchSize is how many pull requests are fetched with each GraphQL query. GitHub charges a query by the nodes it
+// may return, a point for each hundred, so a batch costs a single point of the GraphQL rate limit.
+const prBatchSize = 100
+
+// graphQLPR is the subset of a GraphQL PullRequest a PREntry is made from.
+type graphQLPR struct {
+	Number     int        `json:"number"`
+	Title      string     `json:"title"`
+	URL        string     `json:"url"`
+	State      string     `json:"state"`
+	MergedAt   *time.Time `json:"mergedAt"`
+	HeadRefOid string     `json:"headRefOid"`
+	Author     *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+type graphQLError struct {
+	Type    string        `json:"type"`
+	Path    []interface{} `json:"path"`
+	Message string        `json:"message"`
+}
+
+type prBatchResponse struct {
+	Data struct {
+		Repository map[string]*graphQLPR `json:"repository"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+// prBatchQuery returns a query for the repository's pull requests, aliasing each as pr<number>.
+func prBatchQuery(numbers []int) string {
+	var query strings.Builder
+	query.WriteString("query($owner: String!, $name: String!) {\n  repository(owner: $owner, name: $name) {\n")
+	for _, number := range numbers {
+		fmt.Fprintf(&query, "    pr%d: pullRequest(number: %d) { ...pr }\n", number, number)
+	}
+	query.WriteString("  }\n}\n")
+	query.WriteString("fragment pr on PullRequest { number title url state mergedAt headRefOid author { login } }")
+	return query.String()
+}
+
+// fetchPREntries fetches the repository's pull requests with a single GraphQL query.
+func fetchPREntries(ctx context.Context, ghc *gh.Client, org, repo string, numbers []int) (map[int]*PREntry, error) {
+	query := map[string]interface{}{
+		"query":     prBatchQuery(numbers),
+		"variables": map[string]string{"owner": org, "name": repo},
+	}
+	req, err := ghc.NewRequest(http.MethodPost, "graphql", query)
+	if err != nil {
+		return nil, err
+	}
+	var result prBatchResponse
+	if _, err := ghc.Do(ctx, req, &result); err != nil {
+		return nil, err
+	}
+	return prEntriesFromBatch(numbers, result)
+}
+
+// prEntriesFromBatch returns the entries of the pull requests in the response, and nil for those that don't exist. It
+// fails if anything else went wrong, as the missing pull requests may exist.
+func prEntriesFromBatch(numbers []int, result prBatchResponse) (map[int]*PREntry, error) {
+	for _, e := range result.Errors {
+		if e.Type != "NOT_FOUND" {
+			return nil, fmt.Errorf("error fetching pull requests: %s", e.Message)
+		}
+	}
+
+	entries := make(map[int]*PREntry, len(numbers))
+	for _, number := range numbers {
+		pr := result.Data.Repository[fmt.Sprintf("pr%d", number)]
+		if pr == nil {
+			entries[number] = nil
+			continue
+		}
+
+		// the REST API, which PREntry was first filled from, calls merged PRs closed
+		state := strings.ToLower(pr.State)
+		if state == "merged" {
+			state = "closed"
+		}
+		entry := &PREntry{
+			MergedAt: pr.MergedAt,
+			SHA:      pr.HeadRefOid,
+			Title:    gh.String(pr.Title),
+			URL:      gh.String(pr.URL),
+			State:    gh.String(state),
+		}
+		if pr.Author != nil {
+			entry.Login = gh.String(pr.Author.Login)
+		}
+		entries[number] = entry
+	}
+	return entries, nil
+}
+
+// PrefetchPREntries fetches the repository's pull requests that aren't cached yet in batches, so the PR lookups that
+// follow don't each call GitHub.
+func (c *Client) PrefetchPREntries(org, repo string, numbers []int) error {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+
+	wanted := map[int]bool{}
+	for _, number := range numbers {
+		if _, ok := c.cache[prlocator{org: org, repo: repo, number: number}]; !ok {
+			wanted[number] = true
+		}
+	}
+	missing := make([]int, 0, len(wanted))
+	for number := range wanted {
+		missing = append(missing, number)
+	}
+	sort.Ints(missing)
+
+	for start := 0; start < len(missing); start += prBatchSize {
+		end := start + prBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		entries, err := c.prBatchFetch(org, repo, missing[start:end])
+		if err != nil {
+			return fmt.Errorf("error fetching pull requests of %s/%s: %w", org, repo, err)
+		}
+		for number, entry := range entries {
+			c.cache[prlocator{org: org, repo: repo, number: number}] = entry
+		}
+	}
+	if len(missing) > 0 {
+		log.Debugf("fetched %d pull requests of %s/%s from GitHub", len(missing), org, repo)
+	}
+	return nil
+}
+
+// CacheMergedPR caches a merged pull request's entry, i.e. from the database. A merged pull request doesn't change, so
+// it never has to be fetched from GitHub again.
+func (c *Client) CacheMergedPR(org, repo string, number int, entry *PREntry) {
+	if entry == nil || entry.MergedAt == nil {
+		return
+	}
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	c.cache[prlocator{org: org, repo: repo, number: number}] = entry
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v45/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPRBatchQuery(t *testing.T) {
+	query := prBatchQuery([]int{12, 345})
+	assert.Contains(t, query, "pr12: pullRequest(number: 12) { ...pr }")
+	assert.Contains(t, query, "pr345: pullRequest(number: 345) { ...pr }")
+	assert.True(t, strings.HasPrefix(query, "query($owner: String!, $name: String!)"))
+}
+
+func TestPREntriesFromBatch(t *testing.T) {
+	var result prBatchResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "data": {"repository": {
+    "pr1": {"number": 1, "title": "Fix the thing", "url": "https://github.com/openshift/origin/pull/1",
+      "state": "MERGED", "mergedAt": "2024-03-14T12:00:00Z", "headRefOid": "abc", "author": {"login": "dev"}},
+    "pr2": {"number": 2, "title": "WIP", "url": "https://github.com/openshift/origin/pull/2",
+      "state": "OPEN", "mergedAt": null, "headRefOid": "def", "author": null},
+    "pr3": null
+  }},
+  "errors": [{"type": "NOT_FOUND", "path": ["repository", "pr3"], "message": "Could not resolve to a PullRequest"}]
+}`), &result))
+
+	entries, err := prEntriesFromBatch([]int{1, 2, 3}, result)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	merged := entries[1]
+	require.NotNil(t, merged)
+	assert.Equal(t, time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC), *merged.MergedAt)
+	assert.Equal(t, "abc", merged.SHA)
+	assert.Equal(t, "closed", *merged.State)
+	assert.Equal(t, "dev", *merged.Login)
+
+	open := entries[2]
+	require.NotNil(t, open)
+	assert.Nil(t, open.MergedAt)
+	assert.Equal(t, "open", *open.State)
+	assert.Nil(t, open.Login)
+
+	assert.Nil(t, entries[3])
+
+	_, err = prEntriesFromBatch([]int{1}, prBatchResponse{Errors: []graphQLError{{Type: "RATE_LIMITED",
+		Message: "API rate limit exceeded"}}})
+	assert.Error(t, err)
+}
+
+func TestPrefetchPREntries(t *testing.T) {
+	merged := time.Now()
+	var batches [][]int
+	client := &Client{
+		ctx:   context.TODO(),
+		cache: make(map[prlocator]*PREntry),
+		prBatchFetch: func(org, repo string, numbers []int) (map[int]*PREntry, error) {
+			batches = append(batches, numbers)
+			entries := map[int]*PREntry{}
+			for _, number := range numbers {
+				if number%2 == 0 {
+					entries[number] = &PREntry{SHA: "sha"}
+				} else {
+					entries[number] = nil
+				}
+			}
+			return entries, nil
+		},
+		prFetch: func(org, repo string, number int) (*gh.PullRequest, error) {
+			t.Errorf("unexpected fetch of %s/%s#%d", org, repo, number)
+			return nil, nil
+		},
+	}
+
+	client.CacheMergedPR(openshift, kubernetes, 1000, &PREntry{SHA: "merged", MergedAt: &merged})
+	numbers := []int{1000, 1000}
+	for i := 1; i <= 150; i++ {
+		numbers = append(numbers, i)
+	}
+	require.NoError(t, client.PrefetchPREntries(openshift, kubernetes, numbers))
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], prBatchSize)
+	assert.Len(t, batches[1], 50)
+
+	// everything is cached, merged PRs from the database included
+	require.NoError(t, client.PrefetchPREntries(openshift, kubernetes, numbers))
+	assert.Len(t, batches, 2)
+
+	mergedAt, err := client.GetPRSHAMerged(openshift, kubernetes, 1000, "merged")
+	require.NoError(t, err)
+	assert.Equal(t, &merged, mergedAt)
+	entry, err := client.GetPREntry(openshift, kubernetes, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "sha", entry.SHA)
+	entry, err = client.GetPREntry(openshift, kubernetes, 3)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
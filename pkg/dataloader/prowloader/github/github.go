@@ -11,6 +11,8 @@ import (
 	"time"
 
 	gh "github.com/google/go-github/v45/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	log "github.com/sirupsen/logrus"
 	"github.com/tcnksm/go-gitconfig"
 	"golang.org/x/oauth2"
@@ -21,6 +23,18 @@ const commentIDRegex = `META\s*=\s*{(?P<meta>[^}]*)`
 // if we have fewer than this threshold remaining we will report rate limited
 const rateLimitThreshold = 500
 
+var (
+	rateLimitMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sippy_github_api_rate_limit",
+		Help: "The GitHub core API rate limit for our token",
+	})
+
+	rateRemainingMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sippy_github_api_rate_remaining",
+		Help: "The GitHub core API requests remaining for our token in the current rate limit window",
+	})
+)
+
 type prlocator struct {
 	org    string
 	repo   string
@@ -43,9 +57,13 @@ type Client struct {
 	closedCache         map[string]map[string]map[int]*gh.PullRequest
 	closedCacheLock     sync.RWMutex
 	prFetch             func(org, repo string, number int) (*gh.PullRequest, error)
+	prBatchFetch        func(org, repo string, numbers []int) (map[int]*PREntry, error)
 	prCommentsFetch     func(org, repo string, number int) ([]*gh.IssueComment, error)
 	prCommentCreate     func(org, repo string, number int, comment string) (*gh.IssueComment, error)
 	prCommentDelete     func(org, repo string, updateID int64) error
+	prCommentEdit       func(org, repo string, updateID int64, comment string) error
+	commentMinimize     func(nodeID, reason string) error
+	checkRunCreate      func(org, repo string, opts gh.CreateCheckRunOptions) (*gh.CheckRun, error)
 	gitHubCoreRateFetch func() (*gh.Rate, error)
 	gitHubListClosedPRs func(org, repo string) (map[int]*gh.PullRequest, error)
 	commentMetaRegEx    *regexp.Regexp
@@ -87,6 +105,10 @@ func New(ctx context.Context) *Client {
 		return pr, err
 	}
 
+	client.prBatchFetch = func(org, repo string, numbers []int) (map[int]*PREntry, error) {
+		return fetchPREntries(client.ctx, ghc, org, repo, numbers)
+	}
+
 	client.prCommentCreate = func(org, repo string, number int, comment string) (*gh.IssueComment, error) {
 		ghComment := &gh.IssueComment{Body: &comment}
 		commentResponse, _, err := ghc.Issues.CreateComment(client.ctx, org, repo, number, ghComment)
@@ -98,6 +120,42 @@ func New(ctx context.Context) *Client {
 		return err
 	}
 
+	client.prCommentEdit = func(org, repo string, updateID int64, comment string) error {
+		_, _, err := ghc.Issues.EditComment(client.ctx, org, repo, updateID, &gh.IssueComment{Body: &comment})
+		return err
+	}
+
+	// minimizing a comment is only available through the GraphQL API
+	client.commentMinimize = func(nodeID, reason string) error {
+		query := map[string]interface{}{
+			"query": `mutation($id: ID!, $reason: ReportedContentClassifiers!) {
+  minimizeComment(input: {subjectId: $id, classifier: $reason}) { minimizedComment { isMinimized } }
+}`,
+			"variables": map[string]string{"id": nodeID, "reason": reason},
+		}
+		req, err := ghc.NewRequest(http.MethodPost, "graphql", query)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if _, err := ghc.Do(client.ctx, req, &result); err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("error minimizing comment %s: %s", nodeID, result.Errors[0].Message)
+		}
+		return nil
+	}
+
+	client.checkRunCreate = func(org, repo string, opts gh.CreateCheckRunOptions) (*gh.CheckRun, error) {
+		checkRun, _, err := ghc.Checks.CreateCheckRun(client.ctx, org, repo, opts)
+		return checkRun, err
+	}
+
 	client.prCommentsFetch = func(org, repo string, number int) ([]*gh.IssueComment, error) {
 		issueCommentOptions := &gh.IssueListCommentsOptions{}
 		issueComments, _, err := ghc.Issues.ListComments(client.ctx, org, repo, number, issueCommentOptions)
@@ -194,6 +252,8 @@ func (c *Client) IsWithinRateLimitThreshold() bool {
 	}
 
 	log.Infof("Github Limit:%d, Remaining:%d", rate.Limit, rate.Remaining)
+	rateLimitMetric.Set(float64(rate.Limit))
+	rateRemainingMetric.Set(float64(rate.Remaining))
 
 	return rate.Remaining < rateLimitThreshold
 }
@@ -298,9 +358,20 @@ func (c *Client) PRFetch(org, repo string, number int) (prEntry *PREntry, err er
 	return prEntry, nil
 }
 
-func (c *Client) CreatePRComment(org, repo string, number int, comment string) error {
-	_, err := c.prCommentCreate(org, repo, number, comment)
-	return err
+// CreatePRComment adds the comment to the PR, returning the created comment.
+func (c *Client) CreatePRComment(org, repo string, number int, comment string) (*gh.IssueComment, error) {
+	return c.prCommentCreate(org, repo, number, comment)
+}
+
+// EditPRComment replaces the body of an existing comment.
+func (c *Client) EditPRComment(org, repo string, updateID int64, comment string) error {
+	return c.prCommentEdit(org, repo, updateID, comment)
+}
+
+// MinimizeComment hides the comment with the GraphQL node ID on GitHub, reason is a ReportedContentClassifiers value,
+// i.e. OUTDATED.
+func (c *Client) MinimizeComment(nodeID, reason string) error {
+	return c.commentMinimize(nodeID, reason)
 }
 
 func (c *Client) DeletePRComment(org, repo string, updateID int64) error {
@@ -308,6 +379,27 @@ func (c *Client) DeletePRComment(org, repo string, updateID int64) error {
 	return err
 }
 
+// CreateCheckRun publishes a completed check run on the sha. GitHub only allows GitHub Apps to create check runs.
+func (c *Client) CreateCheckRun(org, repo, sha, name, conclusion, title, summary, detailsURL string) error {
+	now := gh.Timestamp{Time: time.Now()}
+	opts := gh.CreateCheckRunOptions{
+		Name:        name,
+		HeadSHA:     sha,
+		Status:      gh.String("completed"),
+		Conclusion:  gh.String(conclusion),
+		CompletedAt: &now,
+		Output: &gh.CheckRunOutput{
+			Title:   gh.String(title),
+			Summary: gh.String(summary),
+		},
+	}
+	if detailsURL != "" {
+		opts.DetailsURL = gh.String(detailsURL)
+	}
+	_, err := c.checkRunCreate(org, repo, opts)
+	return err
+}
+
 func (c *Client) FindCommentID(org, repo string, number int, commentKey, commentID string) (*int64, *string, error) {
 	comments, err := c.prCommentsFetch(org, repo, number)
 
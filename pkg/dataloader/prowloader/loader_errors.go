@@ -0,0 +1,88 @@
+package prowloader
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// loaderErrorRetention is how long the errors of failed imports are kept.
+const loaderErrorRetention = 30 * 24 * time.Hour
+
+// importError is an error importing a job run, with the class it's recorded under.
+type importError struct {
+	class string
+	err   error
+}
+
+func (e *importError) Error() string {
+	return e.err.Error()
+}
+
+func (e *importError) Unwrap() error {
+	return e.err
+}
+
+// classified returns the error with the class it's recorded under, or nil if err is nil.
+func classified(class string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &importError{class: class, err: err}
+}
+
+// errorClass returns the class of an error importing a job run, models.LoaderErrorOther if it wasn't classified.
+func errorClass(err error) string {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return models.LoaderErrorCancelled
+	}
+	var importErr *importError
+	if errors.As(err, &importErr) {
+		return importErr.class
+	}
+	return models.LoaderErrorOther
+}
+
+// loaderError returns the record of the job run's failed import.
+func (pl *ProwLoader) loaderError(pj *prow.ProwJob, err error) models.LoaderError {
+	loaderErr := models.LoaderError{
+		Loader:  pl.Name(),
+		Release: pl.releaseForJob(pj.Spec.Job),
+		JobName: pj.Spec.Job,
+		Path:    pj.Status.URL,
+		Class:   errorClass(err),
+		Message: err.Error(),
+	}
+	if id, err := strconv.ParseUint(pj.Status.BuildID, 0, 64); err == nil {
+		loaderErr.ProwJobRunID = uint(id)
+	}
+	if pjURL, err := url.Parse(pj.Status.URL); err == nil {
+		if path := gcsPathStrip.ReplaceAllString(pjURL.Path, ""); path != "" && len(path) != len(pjURL.Path) {
+			loaderErr.Path = path
+		}
+	}
+	return loaderErr
+}
+
+// recordLoaderErrors stores the errors of the job runs that failed to import, and forgets those older than the
+// retention period.
+func (pl *ProwLoader) recordLoaderErrors(loaderErrs []models.LoaderError) {
+	if len(loaderErrs) > 0 {
+		if res := pl.dbc.DB.CreateInBatches(loaderErrs, 1000); res.Error != nil {
+			log.WithError(res.Error).Warning("error recording loader errors")
+		}
+	}
+
+	res := pl.dbc.DB.Unscoped().Where("loader = ? AND created_at < ?", pl.Name(),
+		time.Now().Add(-loaderErrorRetention)).Delete(&models.LoaderError{})
+	if res.Error != nil {
+		log.WithError(res.Error).Warning("error deleting old loader errors")
+	}
+}
@@ -0,0 +1,48 @@
+package prowloader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestErrorClass(t *testing.T) {
+	junitErr := classified(models.LoaderErrorJUnit, fmt.Errorf("unexpected EOF"))
+	assert.Equal(t, models.LoaderErrorJUnit, errorClass(junitErr))
+	assert.Equal(t, models.LoaderErrorJUnit, errorClass(errors.Wrap(junitErr, "error converting prow job to job run")))
+	assert.Equal(t, models.LoaderErrorCancelled, errorClass(errors.Wrap(context.Canceled, "error loading")))
+	assert.Equal(t, models.LoaderErrorOther, errorClass(fmt.Errorf("something else")))
+	assert.Nil(t, classified(models.LoaderErrorDatabase, nil))
+}
+
+func TestLoaderError(t *testing.T) {
+	pl := &ProwLoader{}
+	pj := &prow.ProwJob{
+		Spec: prow.ProwJobSpec{Job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"},
+		Status: prow.ProwJobStatus{
+			BuildID: "1763000000000000000",
+			URL: "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/" +
+				"periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1763000000000000000",
+		},
+	}
+
+	loaderErr := pl.loaderError(pj, classified(models.LoaderErrorJUnit, fmt.Errorf("unexpected EOF")))
+	assert.Equal(t, "prow", loaderErr.Loader)
+	assert.Equal(t, pj.Spec.Job, loaderErr.JobName)
+	assert.Equal(t, uint(1763000000000000000), loaderErr.ProwJobRunID)
+	assert.Equal(t, "logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1763000000000000000",
+		loaderErr.Path)
+	assert.Equal(t, models.LoaderErrorJUnit, loaderErr.Class)
+	assert.Equal(t, "unexpected EOF", loaderErr.Message)
+
+	pj.Status.URL = "https://example.com/run/1"
+	loaderErr = pl.loaderError(pj, fmt.Errorf("gcs path empty"))
+	assert.Equal(t, "https://example.com/run/1", loaderErr.Path)
+	assert.Equal(t, models.LoaderErrorOther, loaderErr.Class)
+}
@@ -0,0 +1,85 @@
+package prowloader
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// gcsLocation matches a run's bucket and path in gs:// URLs, prow job URLs (/view/gs/<bucket>/...) and gcsweb URLs
+// (/gcs/<bucket>/...).
+var gcsLocation = regexp.MustCompile(`(?:^gs://|/gc?s/)([^/]+)/(.+)$`)
+
+// LoadRun imports a single job run given its path in the bucket, or a gs://, prow or gcsweb URL for it. A run that was
+// already imported is an error, unless replace is set, when it's deleted and imported again. Each decision made
+// parsing the run is logged at debug level.
+func (pl *ProwLoader) LoadRun(ctx context.Context, location string, replace bool) error {
+	runPath, err := gcsRunPath(location, pl.bktName)
+	if err != nil {
+		return err
+	}
+	runLog := log.WithField("path", runPath)
+
+	pj, err := pl.readProwJob(ctx, runPath+"prowjob.json")
+	if err != nil {
+		return err
+	}
+	if pj == nil {
+		return fmt.Errorf("%s has no prowjob.json", runPath)
+	}
+	runLog.Infof("read prowjob.json: job %s, type %s, state %s, started %s",
+		pj.Spec.Job, pj.Spec.Type, pj.Status.State, pj.Status.StartTime)
+
+	release := pl.releaseForJob(pj.Spec.Job)
+	if release == "" {
+		return fmt.Errorf("job %s doesn't match releases %v in the sippy config", pj.Spec.Job, pl.releases)
+	}
+	runLog.Infof("job matches release %s", release)
+
+	id, err := strconv.ParseUint(pj.Status.BuildID, 0, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid build ID %q", pj.Status.BuildID)
+	}
+	if pl.prowJobRunCache[uint(id)] {
+		if !replace {
+			return fmt.Errorf("job run %d was already imported, replace it to import it again", id)
+		}
+		// tests, their outputs and pull request links are deleted by the foreign key cascades
+		if res := pl.dbc.DB.WithContext(ctx).Unscoped().Delete(&models.ProwJobRun{}, id); res.Error != nil {
+			return errors.Wrapf(res.Error, "error deleting job run %d", id)
+		}
+		delete(pl.prowJobRunCache, uint(id))
+		runLog.Infof("deleted previously imported job run %d", id)
+	}
+
+	return pl.prowJobToJobRun(ctx, pj, release)
+}
+
+// gcsRunPath returns the path of a run in the bucket, ending in a slash.
+func gcsRunPath(location, bucket string) (string, error) {
+	runPath := strings.TrimSpace(location)
+	if u, err := url.Parse(runPath); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		runPath = u.Path
+	}
+	if m := gcsLocation.FindStringSubmatch(runPath); m != nil {
+		if m[1] != bucket {
+			return "", fmt.Errorf("job run is in bucket %s, not %s", m[1], bucket)
+		}
+		runPath = m[2]
+	}
+
+	runPath = strings.Trim(runPath, "/")
+	if _, err := strconv.ParseUint(path.Base(runPath), 10, 64); err != nil || !strings.Contains(runPath, "/") {
+		return "", fmt.Errorf("%q doesn't look like a job run, expected a path like logs/<job>/<build id>", location)
+	}
+	return runPath + "/", nil
+}
@@ -0,0 +1,63 @@
+package prowloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCSRunPath(t *testing.T) {
+	const bucket = "test-platform-results"
+	const runPath = "logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1737420379221135360/"
+
+	tests := []struct {
+		name     string
+		location string
+		expected string
+		err      string
+	}{
+		{
+			name:     "path",
+			location: "logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1737420379221135360",
+			expected: runPath,
+		},
+		{
+			name:     "gs url",
+			location: "gs://test-platform-results/logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1737420379221135360/",
+			expected: runPath,
+		},
+		{
+			name:     "prow url",
+			location: "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1737420379221135360",
+			expected: runPath,
+		},
+		{
+			name:     "gcsweb url",
+			location: "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/pr-logs/pull/openshift_origin/28000/pull-ci-openshift-origin-master-e2e-aws/1737420379221135360/",
+			expected: "pr-logs/pull/openshift_origin/28000/pull-ci-openshift-origin-master-e2e-aws/1737420379221135360/",
+		},
+		{
+			name:     "other bucket",
+			location: "gs://origin-ci-test/logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1737420379221135360",
+			err:      "bucket origin-ci-test",
+		},
+		{
+			name:     "not a run",
+			location: "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn",
+			err:      "doesn't look like a job run",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := gcsRunPath(tt.location, bucket)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -9,14 +9,18 @@ import (
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"cloud.google.com/go/bigquery"
 	"cloud.google.com/go/storage"
 	"github.com/jackc/pgtype"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -31,6 +35,7 @@ import (
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/testconversion"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/failureclassification"
 	"github.com/openshift/sippy/pkg/github/commenter"
 	"github.com/openshift/sippy/pkg/synthetictests"
 	"github.com/openshift/sippy/pkg/testidentification"
@@ -42,8 +47,10 @@ import (
 // from the path "/view/gs/origin-ci-test/logs/periodic-ci-openshift-release-master-nightly-4.14-e2e-gcp-sdn/1737420379221135360"
 var gcsPathStrip = regexp.MustCompile(`.*/gs/[^/]+/`)
 
+// gcsWebURL is where the objects in a bucket can be browsed, followed by the bucket and the object's name.
+const gcsWebURL = "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/"
+
 type ProwLoader struct {
-	ctx                     context.Context
 	dbc                     *db.DB
 	bkt                     *storage.BucketHandle
 	bktName                 string
@@ -61,14 +68,20 @@ type ProwLoader struct {
 	suiteCache              map[string]*uint
 	suiteCacheLock          sync.RWMutex
 	syntheticTestManager    synthetictests.SyntheticTestManager
+	failureClassifier       *failureclassification.Classifier
 	releases                []string
 	config                  *v1config.SippyConfig
+	jobTenants              *v1config.JobTenants
 	ghCommenter             *commenter.GitHubCommenter
 	jobsImportedCount       atomic.Int32
+	backfillFrom            time.Time
+	backfillTo              time.Time
+	storeJUnit              bool
+	testLogRetention        time.Duration
+	payloadJobKinds         map[string]string
 }
 
 func New(
-	ctx context.Context,
 	dbc *db.DB,
 	gcsClient *storage.Client,
 	bigQueryClient *bigquery.Client,
@@ -76,6 +89,7 @@ func New(
 	githubClient *github.Client,
 	variantManager testidentification.VariantManager,
 	syntheticTestManager synthetictests.SyntheticTestManager,
+	failureClassifier *failureclassification.Classifier,
 	releases []string,
 	config *v1config.SippyConfig,
 	ghCommenter *commenter.GitHubCommenter) *ProwLoader {
@@ -83,7 +97,6 @@ func New(
 	bkt := gcsClient.Bucket(gcsBucket)
 
 	return &ProwLoader{
-		ctx:                  ctx,
 		dbc:                  dbc,
 		bkt:                  bkt,
 		bktName:              gcsBucket,
@@ -92,9 +105,11 @@ func New(
 		maxConcurrency:       10,
 		prowJobRunCache:      loadProwJobRunCache(dbc),
 		prowJobCache:         loadProwJobCache(dbc),
+		payloadJobKinds:      loadPayloadJobKinds(dbc),
 		prowJobRunTestCache:  make(map[string]uint),
 		suiteCache:           make(map[string]*uint),
 		syntheticTestManager: syntheticTestManager,
+		failureClassifier:    failureClassifier,
 		variantManager:       variantManager,
 		releases:             releases,
 		config:               config,
@@ -102,6 +117,15 @@ func New(
 	}
 }
 
+// SetJobTenants makes the loader tag the jobs it imports with their tenant.
+func (pl *ProwLoader) SetJobTenants(jobTenants *v1config.JobTenants) {
+	pl.jobTenants = jobTenants
+}
+
+// maxFailureOutputLength limits the failure output stored for a test, some tests fail with megabytes of logs and the
+// start of the output is enough to tell failures apart.
+const maxFailureOutputLength = 32 * 1024
+
 var clusterDataDateTimeName = regexp.MustCompile(`cluster-data_(?P<DATE>.*)-(?P<TIME>.*).json`)
 
 type DateTimeName struct {
@@ -123,6 +147,30 @@ func loadProwJobCache(dbc *db.DB) map[string]*models.ProwJob {
 	return prowJobCache
 }
 
+// backfillVariantDimensions sets variant dimensions on jobs loaded before they were tracked. Jobs that are still
+// running have their dimensions refreshed from cluster data as new runs are imported.
+func (pl *ProwLoader) backfillVariantDimensions() error {
+	pl.prowJobCacheLock.Lock()
+	defer pl.prowJobCacheLock.Unlock()
+
+	count := 0
+	for _, job := range pl.prowJobCache {
+		if job.VariantDimensions != nil {
+			continue
+		}
+		job.VariantDimensions = pl.variantManager.IdentifyVariantDimensions(job.Name, job.Release, models.ClusterData{})
+		if res := pl.dbc.DB.Model(job).UpdateColumn("variant_dimensions", job.VariantDimensions); res.Error != nil {
+			return errors.Wrapf(res.Error, "error updating variant dimensions for %s", job.Name)
+		}
+		count++
+	}
+	if count > 0 {
+		log.Infof("backfilled variant dimensions for %d prow jobs", count)
+	}
+
+	return nil
+}
+
 // Cache the IDs of all known ProwJobRuns. Will be used to skip job run and test
 // results we've already processed.
 // TODO: over 800k in our db now, should we only cache those within last two weeks?
@@ -146,7 +194,7 @@ func (pl *ProwLoader) Errors() []error {
 	return pl.errors
 }
 
-func (pl *ProwLoader) Load() {
+func (pl *ProwLoader) Load(ctx context.Context) {
 	start := time.Now()
 	log.Infof("started loading prow jobs to DB...")
 
@@ -155,13 +203,26 @@ func (pl *ProwLoader) Load() {
 		pl.errors = append(pl.errors, errors.Wrap(err, "error in syncPRStatus"))
 	}
 
+	if err := pl.backfillVariantDimensions(); err != nil {
+		pl.errors = append(pl.errors, errors.Wrap(err, "error in backfillVariantDimensions"))
+	}
+
+	// Import the runs that failed to import before, in case they only failed for the time being
+	pl.retryFailedImports(ctx)
+
 	// Grab the ProwJob definitions from prow or CI bigquery. Note that these are the Kube
 	// ProwJob CRDs, not our sippy db model ProwJob.
 	var prowJobs []prow.ProwJob
 	// Fetch/update job data
-	if pl.bigQueryClient != nil {
+	if pl.backfilling() {
+		var gcsErrs []error
+		prowJobs, gcsErrs = pl.fetchProwJobsFromGCS(ctx)
+		if len(gcsErrs) > 0 {
+			pl.errors = append(pl.errors, gcsErrs...)
+		}
+	} else if pl.bigQueryClient != nil {
 		var bqErrs []error
-		prowJobs, bqErrs = pl.fetchProwJobsFromOpenShiftBigQuery()
+		prowJobs, bqErrs = pl.fetchProwJobsFromOpenShiftBigQuery(ctx)
 		if len(bqErrs) > 0 {
 			pl.errors = append(pl.errors, bqErrs...)
 		}
@@ -178,12 +239,15 @@ func (pl *ProwLoader) Load() {
 		}
 	}
 
+	pl.prefetchPullRequests(prowJobs)
+
 	queue := make(chan *prow.ProwJob)
 	errsCh := make(chan error, len(prowJobs))
+	loaderErrsCh := make(chan models.LoaderError, len(prowJobs))
 	total := len(prowJobs)
 
 	// Producer to keep feeding the queue
-	go prowJobsProducer(pl.ctx, queue, prowJobs)
+	go prowJobsProducer(ctx, queue, prowJobs)
 
 	// Start pl.maxConcurrency consumers
 	var wg sync.WaitGroup
@@ -199,12 +263,13 @@ func (pl *ProwLoader) Load() {
 				}
 				if err := pl.processProwJob(ctx, job); err != nil {
 					errsCh <- err
+					loaderErrsCh <- pl.loaderError(job, err)
 					log.WithError(err).Warningf("couldn't import job %s/%s, continuing", job.Spec.Job, job.Status.BuildID)
 				}
 				pl.jobsImportedCount.Add(1)
 				log.Infof("%d of %d job runs processed", pl.jobsImportedCount.Load(), total)
 			}
-		}(pl.ctx)
+		}(ctx)
 	}
 
 	wg.Wait()
@@ -212,6 +277,14 @@ func (pl *ProwLoader) Load() {
 	for err := range errsCh {
 		pl.errors = append(pl.errors, err)
 	}
+	close(loaderErrsCh)
+	loaderErrs := make([]models.LoaderError, 0, len(loaderErrsCh))
+	for loaderErr := range loaderErrsCh {
+		loaderErrs = append(loaderErrs, loaderErr)
+	}
+	pl.recordLoaderErrors(loaderErrs)
+
+	pl.pruneTestLogs()
 
 	if len(pl.errors) > 0 {
 		log.Warningf("encountered %d errors while importing job runs", len(pl.errors))
@@ -236,6 +309,22 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 		"buildID": pj.Status.BuildID,
 	})
 
+	release := pl.releaseForJob(pj.Spec.Job)
+	if release == "" {
+		pjLog.Debugf("no match for release in sippy configuration, skipping")
+		return nil
+	}
+
+	if err := pl.prowJobToJobRun(ctx, pj, release); err != nil {
+		err = errors.Wrapf(err, "error converting prow job to job run: %s", pj.Spec.Job)
+		pjLog.WithError(err).Warning("prow import error")
+		return err
+	}
+	return nil
+}
+
+// releaseForJob returns the first release whose configuration lists or matches the job, or an empty string if none do.
+func (pl *ProwLoader) releaseForJob(jobName string) string {
 	for _, release := range pl.releases {
 		cfg, ok := pl.config.Releases[release]
 		if !ok {
@@ -243,13 +332,8 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 			continue
 		}
 
-		if val, ok := cfg.Jobs[pj.Spec.Job]; val && ok {
-			if err := pl.prowJobToJobRun(ctx, pj, release); err != nil {
-				err = errors.Wrapf(err, "error converting prow job to job run: %s", pj.Spec.Job)
-				pjLog.WithError(err).Warning("prow import error")
-				return err
-			}
-			return nil
+		if val, ok := cfg.Jobs[jobName]; val && ok {
+			return release
 		}
 
 		for _, expr := range cfg.Regexp {
@@ -260,19 +344,13 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 				continue
 			}
 
-			if re.MatchString(pj.Spec.Job) {
-				if err := pl.prowJobToJobRun(ctx, pj, release); err != nil {
-					err = errors.Wrapf(err, "error converting prow job to job run: %s", pj.Spec.Job)
-					pjLog.WithError(err).Warning("prow import error")
-					return err
-				}
-				return nil
+			if re.MatchString(jobName) {
+				return release
 			}
 		}
 	}
 
-	pjLog.Debugf("no match for release in sippy configuration, skipping")
-	return nil
+	return ""
 }
 
 func (pl *ProwLoader) syncPRStatus() error {
@@ -515,7 +593,7 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 	// now, any concerns?
 	pjURL, err := url.Parse(pj.Status.URL)
 	if err != nil {
-		return err
+		return classified(models.LoaderErrorInvalidURL, err)
 	}
 
 	// Get the path in the gcs bucket, strip out the bucket name and anything before it
@@ -524,7 +602,7 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 	if path == "" || len(path) == len(pjURL.Path) {
 		msg := fmt.Sprintf("not continuing, gcs path empty or does not contain expected prefix original=%+v stripped=%+v", pjURL.Path, path)
 		pjLog.Warningf(msg)
-		return fmt.Errorf(msg)
+		return classified(models.LoaderErrorGCSPath, fmt.Errorf(msg))
 	}
 
 	// find all files here then pass to getClusterData
@@ -532,15 +610,32 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 	// add more regexes if we require more
 	// results from scanning for file names
 	gcsJobRun := gcs.NewGCSJobRun(pl.bkt, path)
-	allMatches := gcsJobRun.FindAllMatches([]*regexp.Regexp{gcs.GetDefaultClusterDataFile(), gcs.GetDefaultJunitFile()})
+	fileRegexps := []*regexp.Regexp{gcs.GetDefaultClusterDataFile(), gcs.GetDefaultJunitFile(), gcs.GetBuildLogFile(),
+		gcs.GetMustGatherFile(), gcs.GetIntervalFile()}
+	if am, ok := pl.syntheticTestManager.(synthetictests.ArtifactMatcher); ok {
+		fileRegexps = append(fileRegexps, am.ArtifactRegexps()...)
+	}
+	allMatches := gcsJobRun.FindAllMatches(fileRegexps)
 	var clusterMatches []string
 	var junitMatches []string
+	var artifactMatches []string
+	var artifacts models.ArtifactLinks
 	if len(allMatches) > 0 {
 		clusterMatches = allMatches[0]
 		junitMatches = allMatches[1]
+		artifacts = artifactLinks(pl.bktName, path, junitMatches, allMatches[2], allMatches[3], allMatches[4])
+		for _, matches := range allMatches[5:] {
+			artifactMatches = append(artifactMatches, matches...)
+		}
 	}
 
+	pjLog.Debugf("found %d junit files, %d cluster data files and %d synthetic test artifacts",
+		len(junitMatches), len(clusterMatches), len(artifactMatches))
+
 	clusterData := pl.getClusterData(ctx, path, clusterMatches)
+	pjLog.Debugf("cluster data: %+v", clusterData)
+
+	tier := jobTier(pl.config.Releases[release], pl.payloadJobKinds, pj.Spec.Job)
 
 	// Lock the whole prow job block to avoid trying to create the pj multiple times concurrently\
 	// (resulting in a DB error)
@@ -549,15 +644,20 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 	if !foundProwJob {
 		pjLog.Info("creating new ProwJob")
 		dbProwJob = &models.ProwJob{
-			Name:        pj.Spec.Job,
-			Kind:        models.ProwKind(pj.Spec.Type),
-			Release:     release,
-			Variants:    pl.variantManager.IdentifyVariants(pj.Spec.Job, release, clusterData),
-			TestGridURL: pl.generateTestGridURL(release, pj.Spec.Job).String(),
+			Name:              pj.Spec.Job,
+			Kind:              models.ProwKind(pj.Spec.Type),
+			Release:           release,
+			Tenant:            pl.jobTenants.Tenant(release, pj.Spec.Job),
+			Tier:              tier,
+			Variants:          pl.variantManager.IdentifyVariants(pj.Spec.Job, release, clusterData),
+			VariantDimensions: pl.variantManager.IdentifyVariantDimensions(pj.Spec.Job, release, clusterData),
+			Timeout:           pj.Spec.Timeout(),
+			ClusterProfile:    pj.ClusterProfile(),
+			TestGridURL:       pl.generateTestGridURL(release, pj.Spec.Job).String(),
 		}
 		err := pl.dbc.DB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(dbProwJob).Error
 		if err != nil {
-			return errors.Wrapf(err, "error loading prow job into db: %s", pj.Spec.Job)
+			return classified(models.LoaderErrorDatabase, errors.Wrapf(err, "error loading prow job into db: %s", pj.Spec.Job))
 		}
 		pl.prowJobCache[pj.Spec.Job] = dbProwJob
 	} else {
@@ -568,6 +668,23 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 			dbProwJob.Variants = newVariants
 			saveDB = true
 		}
+		newDimensions := pl.variantManager.IdentifyVariantDimensions(pj.Spec.Job, release, clusterData)
+		if !reflect.DeepEqual(newDimensions, dbProwJob.VariantDimensions) {
+			dbProwJob.VariantDimensions = newDimensions
+			saveDB = true
+		}
+		if timeout := pj.Spec.Timeout(); timeout != 0 && timeout != dbProwJob.Timeout {
+			dbProwJob.Timeout = timeout
+			saveDB = true
+		}
+		if tier != dbProwJob.Tier {
+			dbProwJob.Tier = tier
+			saveDB = true
+		}
+		if profile := pj.ClusterProfile(); profile != "" && profile != dbProwJob.ClusterProfile {
+			dbProwJob.ClusterProfile = profile
+			saveDB = true
+		}
 		if len(dbProwJob.TestGridURL) == 0 {
 			dbProwJob.TestGridURL = pl.generateTestGridURL(release, pj.Spec.Job).String()
 			if len(dbProwJob.TestGridURL) > 0 {
@@ -576,10 +693,11 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 		}
 		if saveDB {
 			if res := pl.dbc.DB.WithContext(ctx).Save(&dbProwJob); res.Error != nil {
-				return res.Error
+				return classified(models.LoaderErrorDatabase, res.Error)
 			}
 		}
 	}
+	pjLog.Debugf("identified variants %v, dimensions %v", dbProwJob.Variants, dbProwJob.VariantDimensions)
 	pl.prowJobCacheLock.Unlock()
 
 	pl.prowJobRunCacheLock.RLock()
@@ -590,7 +708,20 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 	} else {
 		pjLog.Info("processing GCS bucket")
 
-		tests, failures, overallResult, err := pl.prowJobRunTestsFromGCS(ctx, pj, uint(id), path, junitMatches)
+		gcsJobRun.SetGCSJunitPaths(junitMatches)
+		var junits []models.ProwJobRunJUnit
+		if pl.storeJUnit {
+			gcsJobRun.SetJUnitRecorder(func(junitPath string, content []byte) {
+				compressed, err := gzipJUnit(content)
+				if err != nil {
+					pjLog.WithError(err).Warningf("couldn't compress junit file %s to keep it", junitPath)
+					return
+				}
+				junits = append(junits, models.ProwJobRunJUnit{Path: junitPath, Content: compressed})
+			})
+		}
+		tests, skipped, overallResult, err := pl.prowJobRunTestsFromGCS(ctx, pj, uint(id), gcsJobRun, artifactMatches,
+			dbProwJob.VariantDimensions.HostedControlPlane())
 		if err != nil {
 			return err
 		}
@@ -602,23 +733,34 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 			duration = pj.Status.CompletionTime.Sub(pj.Status.StartTime)
 		}
 
-		err = pl.dbc.DB.WithContext(ctx).Create(&models.ProwJobRun{
+		classification := pl.failureClassifier.Classify(pj.Spec.Job, overallResult, failedTests(tests))
+		run := &models.ProwJobRun{
 			Model: gorm.Model{
 				ID: uint(id),
 			},
 			Cluster:       pj.Spec.Cluster,
+			CloudRegion:   clusterData.CloudRegion,
+			ConfigHash:    pl.configHash(ctx, pj, path),
 			Duration:      duration,
 			ProwJob:       *dbProwJob,
 			ProwJobID:     dbProwJob.ID,
 			URL:           pj.Status.URL,
+			Artifacts:     artifacts,
+			JUnitPaths:    append(pq.StringArray{}, junitMatches...),
+			ArtifactPaths: append(pq.StringArray{}, artifactMatches...),
+			JUnits:        junits,
 			Timestamp:     pj.Status.StartTime,
 			OverallResult: overallResult,
 			PullRequests:  pulls,
-			TestFailures:  failures,
 			Succeeded:     overallResult == sippyprocessingv1.JobSucceeded,
-		}).Error
+
+			FailureClassification: string(classification),
+			InfrastructureFailure: classification == failureclassification.InfraFailure,
+		}
+		run.SetTestSummary(tests)
+		err = pl.dbc.DB.WithContext(ctx).Create(run).Error
 		if err != nil {
-			return err
+			return classified(models.LoaderErrorDatabase, err)
 		}
 		// Looks like sometimes, we might be getting duplicate entries from bigquery:
 		pl.prowJobRunCacheLock.Lock()
@@ -627,7 +769,12 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 
 		err = pl.dbc.DB.WithContext(ctx).Debug().CreateInBatches(tests, 1000).Error
 		if err != nil {
-			return err
+			return classified(models.LoaderErrorDatabase, err)
+		}
+		if len(skipped) > 0 {
+			if err := pl.dbc.DB.WithContext(ctx).CreateInBatches(skipped, 1000).Error; err != nil {
+				return classified(models.LoaderErrorDatabase, err)
+			}
 		}
 	}
 
@@ -635,6 +782,59 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 	return nil
 }
 
+// artifactLinks links to the run's key artifacts in the bucket, so the UI doesn't have to work out where they are:
+// the run's own build log, the directory of its first junit file, and the first must-gather and intervals file.
+func artifactLinks(bucket, path string, junits, buildLogs, mustGathers, intervals []string) models.ArtifactLinks {
+	link := func(object string) string {
+		return gcsWebURL + bucket + "/" + object
+	}
+
+	links := models.ArtifactLinks{}
+	buildLog := strings.TrimSuffix(path, "/") + "/build-log.txt"
+	for _, match := range buildLogs {
+		if match == buildLog {
+			links[models.ArtifactBuildLog] = link(match)
+		}
+	}
+	if first := firstSorted(junits); first != "" {
+		links[models.ArtifactJUnit] = link(first[:strings.LastIndex(first, "/")+1])
+	}
+	if first := firstSorted(mustGathers); first != "" {
+		links[models.ArtifactMustGather] = link(first)
+	}
+	if first := firstSorted(intervals); first != "" {
+		links[models.ArtifactIntervals] = link(first)
+	}
+	return links
+}
+
+// firstSorted returns the first of the object names in order, or "" if there are none.
+func firstSorted(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	return sorted[0]
+}
+
+// configHash returns the hash of the run's job configuration, reading the run's prowjob.json when the job wasn't
+// read from one, i.e. it was loaded from bigquery.
+func (pl *ProwLoader) configHash(ctx context.Context, pj *prow.ProwJob, path string) string {
+	if pj.Spec.ConfigHash != "" {
+		return pj.Spec.ConfigHash
+	}
+	spec, err := pl.readProwJob(ctx, strings.TrimSuffix(path, "/")+"/prowjob.json")
+	if err != nil {
+		log.WithError(err).Warningf("couldn't read the job configuration of %s", path)
+		return ""
+	}
+	if spec == nil {
+		return ""
+	}
+	return spec.Spec.ConfigHash
+}
+
 func (pl *ProwLoader) findOrAddPullRequests(refs *prow.Refs, pjPath string) []models.ProwPullRequest {
 	if refs == nil || pl.githubClient == nil {
 		if refs == nil {
@@ -736,6 +936,7 @@ func (pl *ProwLoader) findOrAddTest(name string) (uint, error) {
 	pl.dbc.DB.Where("name = ?", name).Find(&test)
 	if test.ID == 0 {
 		test.Name = name
+		test.SetTags()
 		tx := pl.dbc.DB.Save(test)
 		if tx.Error != nil {
 			log.WithError(tx.Error).Warningf("failed to create test %q", name)
@@ -772,17 +973,17 @@ func (pl *ProwLoader) findSuite(name string) *uint {
 	return pl.suiteCache[name]
 }
 
-func (pl *ProwLoader) prowJobRunTestsFromGCS(ctx context.Context, pj *prow.ProwJob, id uint, path string, junitPaths []string) ([]*models.ProwJobRunTest, int, sippyprocessingv1.JobOverallResult, error) {
-	failures := 0
-
-	gcsJobRun := gcs.NewGCSJobRun(pl.bkt, path)
-	gcsJobRun.SetGCSJunitPaths(junitPaths)
+// prowJobRunTestsFromGCS returns the run's test results and the tests it skipped, from the junit files of the GCS job
+// run and synthetic tests, with the run's overall result. The synthetic tests of hosted control planes account for
+// their topology.
+func (pl *ProwLoader) prowJobRunTestsFromGCS(ctx context.Context, pj *prow.ProwJob, id uint, gcsJobRun *gcs.GCSJobRun, artifactPaths []string, hostedControlPlane bool) ([]*models.ProwJobRunTest, []*models.ProwJobRunSkippedTest, sippyprocessingv1.JobOverallResult, error) {
 	suites, err := gcsJobRun.GetCombinedJUnitTestSuites(ctx)
 	if err != nil {
 		log.Warningf("failed to get junit test suites: %s", err.Error())
-		return []*models.ProwJobRunTest{}, 0, "", err
+		return []*models.ProwJobRunTest{}, nil, "", classified(models.LoaderErrorJUnit, err)
 	}
 	testCases := make(map[string]*models.ProwJobRunTest)
+	skippedCases := make(map[string]*models.ProwJobRunSkippedTest)
 	for _, suite := range suites.Suites {
 		suiteID := pl.findSuite(suite.Name)
 		if suiteID == nil {
@@ -790,10 +991,14 @@ func (pl *ProwLoader) prowJobRunTestsFromGCS(ctx context.Context, pj *prow.ProwJ
 			continue
 		}
 
+		before := len(testCases)
 		pl.extractTestCases(suite, suiteID, testCases)
+		pl.extractSkippedTestCases(suite, suiteID, skippedCases)
+		log.Debugf("imported %d tests from suite %q", len(testCases)-before, suite.Name)
 	}
 
-	syntheticSuite, jobResult := testconversion.ConvertProwJobRunToSyntheticTests(*pj, testCases, pl.syntheticTestManager)
+	syntheticSuite, jobResult := testconversion.ConvertProwJobRunToSyntheticTests(*pj, testCases, artifactPaths, hostedControlPlane,
+		pl.syntheticTestManager)
 
 	suiteID := pl.findSuite(syntheticSuite.Name)
 	if suiteID == nil {
@@ -802,21 +1007,97 @@ func (pl *ProwLoader) prowJobRunTestsFromGCS(ctx context.Context, pj *prow.ProwJ
 	}
 	pl.extractTestCases(syntheticSuite, suiteID, testCases)
 	log.Infof("synthetic suite had %d tests", syntheticSuite.NumTests)
+	for _, tc := range syntheticSuite.TestCases {
+		status := "passed"
+		if tc.FailureOutput != nil {
+			status = "failed"
+		}
+		log.Debugf("synthetic test %q %s", tc.Name, status)
+	}
+	log.Debugf("job run overall result is %s", jobResult)
 
 	results := make([]*models.ProwJobRunTest, 0)
 	for k := range testCases {
 		if testidentification.IsIgnoredTest(k) {
+			log.Debugf("ignoring test %q", k)
 			continue
 		}
 
 		testCases[k].ProwJobRunID = id
 		results = append(results, testCases[k])
-		if testCases[k].Status == 12 {
-			failures++
+	}
+
+	skipped := make([]*models.ProwJobRunSkippedTest, 0)
+	for k, skip := range skippedCases {
+		// a test skipped and then run, i.e. on a retry, ran
+		if _, ran := testCases[k]; ran || testidentification.IsIgnoredTest(k) {
+			continue
 		}
+		skip.ProwJobRunID = id
+		skipped = append(skipped, skip)
 	}
 
-	return results, failures, jobResult, nil
+	return results, skipped, jobResult, nil
+}
+
+// failedTests are the run's failed tests, with their outputs, to classify the run's failure.
+func failedTests(tests []*models.ProwJobRunTest) []failureclassification.FailedTest {
+	failed := make([]failureclassification.FailedTest, 0)
+	for _, test := range tests {
+		if sippyprocessingv1.TestStatus(test.Status) != sippyprocessingv1.TestStatusFailure {
+			continue
+		}
+		ft := failureclassification.FailedTest{Name: test.TestName}
+		if test.ProwJobRunTestOutput != nil {
+			ft.Output = test.ProwJobRunTestOutput.Output
+		}
+		failed = append(failed, ft)
+	}
+	return failed
+}
+
+// truncateOutput cuts the output to at most max bytes, without splitting a UTF-8 character. A cut output is copied,
+// so the rest of a long output isn't kept in memory until the run is saved.
+func truncateOutput(output string, max int) string {
+	if len(output) <= max {
+		return output
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	var truncated strings.Builder
+	truncated.Grow(cut)
+	truncated.WriteString(output[:cut])
+	return truncated.String()
+}
+
+// extractSkippedTestCases records the tests the suite and its children skipped, with their skip reasons.
+func (pl *ProwLoader) extractSkippedTestCases(suite *junit.TestSuite, suiteID *uint,
+	skipped map[string]*models.ProwJobRunSkippedTest) {
+	for _, tc := range suite.TestCases {
+		if tc.SkipMessage == nil {
+			continue
+		}
+		testCacheKey := fmt.Sprintf("%s.%s", suite.Name, tc.Name)
+		if _, ok := skipped[testCacheKey]; ok {
+			continue
+		}
+		testID, err := pl.findOrAddTest(tc.Name)
+		if err != nil {
+			log.WithError(err).Warningf("could not find or create test %q", tc.Name)
+			continue
+		}
+		skipped[testCacheKey] = &models.ProwJobRunSkippedTest{
+			TestID:  testID,
+			SuiteID: suiteID,
+			Message: truncateOutput(tc.SkipMessage.Message, maxFailureOutputLength),
+		}
+	}
+
+	for _, c := range suite.Children {
+		pl.extractSkippedTestCases(c, suiteID, skipped)
+	}
 }
 
 func (pl *ProwLoader) extractTestCases(suite *junit.TestSuite, suiteID *uint, testCases map[string]*models.ProwJobRunTest) {
@@ -825,14 +1106,16 @@ func (pl *ProwLoader) extractTestCases(suite *junit.TestSuite, suiteID *uint, te
 	for _, tc := range suite.TestCases {
 		status := sippyprocessingv1.TestStatusFailure
 		var failureOutput *models.ProwJobRunTestOutput
+		var testLog *models.ProwJobRunTestLog
 		if tc.SkipMessage != nil {
 			continue
 		} else if tc.FailureOutput == nil {
 			status = sippyprocessingv1.TestStatusSuccess
 		} else {
 			failureOutput = &models.ProwJobRunTestOutput{
-				Output: tc.FailureOutput.Output,
+				Output: truncateOutput(tc.FailureOutput.Output, maxFailureOutputLength),
 			}
+			testLog = pl.testLog(tc.FailureOutput.Output, tc.SystemOut)
 		}
 
 		// Cache key should always have the suite name, so we don't combine
@@ -842,7 +1125,7 @@ func (pl *ProwLoader) extractTestCases(suite *junit.TestSuite, suiteID *uint, te
 		if failureOutput != nil {
 			// Check if this test is configured to extract metadata from it's output, and if so, create it
 			// in the db.
-			extractedMetadata := testOutputMetadataExtractor.ExtractMetadata(tc.Name, failureOutput.Output)
+			extractedMetadata := testOutputMetadataExtractor.ExtractMetadata(tc.Name, tc.FailureOutput.Output)
 			if len(extractedMetadata) > 0 {
 				failureOutput.Metadata = make([]models.ProwJobRunTestOutputMetadata, 0, len(extractedMetadata))
 				for _, m := range extractedMetadata {
@@ -866,17 +1149,28 @@ func (pl *ProwLoader) extractTestCases(suite *junit.TestSuite, suiteID *uint, te
 
 			testCases[testCacheKey] = &models.ProwJobRunTest{
 				TestID:               testID,
+				TestName:             tc.Name,
 				SuiteID:              suiteID,
 				Status:               int(status),
 				Duration:             tc.Duration,
 				ProwJobRunTestOutput: failureOutput,
+				ProwJobRunTestLog:    testLog,
 			}
-		} else if (existing.Status == int(sippyprocessingv1.TestStatusFailure) && status == sippyprocessingv1.TestStatusSuccess) ||
-			(existing.Status == int(sippyprocessingv1.TestStatusSuccess) && status == sippyprocessingv1.TestStatusFailure) {
-			// One pass among failures makes this a flake
-			existing.Status = int(sippyprocessingv1.TestStatusFlake)
-			if existing.ProwJobRunTestOutput == nil {
-				existing.ProwJobRunTestOutput = failureOutput
+		} else {
+			if existing.Status != int(sippyprocessingv1.TestStatusSuccess) && status == sippyprocessingv1.TestStatusSuccess {
+				// A pass after a failure is a retry that succeeded, a true flake
+				existing.RetryFlake = true
+			}
+			if (existing.Status == int(sippyprocessingv1.TestStatusFailure) && status == sippyprocessingv1.TestStatusSuccess) ||
+				(existing.Status == int(sippyprocessingv1.TestStatusSuccess) && status == sippyprocessingv1.TestStatusFailure) {
+				// One pass among failures makes this a flake
+				existing.Status = int(sippyprocessingv1.TestStatusFlake)
+				if existing.ProwJobRunTestOutput == nil {
+					existing.ProwJobRunTestOutput = failureOutput
+				}
+				if existing.ProwJobRunTestLog == nil {
+					existing.ProwJobRunTestLog = testLog
+				}
 			}
 		}
 	}
@@ -2,8 +2,13 @@ package prowloader
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/apis/junit"
+	"github.com/openshift/sippy/pkg/db/models"
 )
 
 func TestDateTimeNameComparisons(t *testing.T) {
@@ -59,3 +64,165 @@ func TestDateTimeNameComparisons(t *testing.T) {
 		})
 	}
 }
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "short", truncateOutput("short", 10))
+	assert.Equal(t, "0123456789", truncateOutput("0123456789abc", 10))
+	// the two byte é would be split at 5, so is dropped
+	assert.Equal(t, "abcd", truncateOutput("abcdé", 5))
+}
+
+func TestExtractTestCasesRetryFlakes(t *testing.T) {
+	passed := func(name string) *junit.TestCase { return &junit.TestCase{Name: name} }
+	failed := func(name string) *junit.TestCase {
+		return &junit.TestCase{Name: name, FailureOutput: &junit.FailureOutput{Output: "failed"}}
+	}
+	suite := &junit.TestSuite{
+		Name: "suite",
+		TestCases: []*junit.TestCase{
+			failed("retried"), passed("retried"),
+			passed("passed then failed"), failed("passed then failed"),
+			failed("failed twice"), failed("failed twice"),
+			failed("failed then passed twice"), passed("failed then passed twice"), passed("failed then passed twice"),
+		},
+	}
+	pl := &ProwLoader{prowJobRunTestCache: map[string]uint{
+		"retried": 1, "passed then failed": 2, "failed twice": 3, "failed then passed twice": 4,
+	}}
+
+	testCases := map[string]*models.ProwJobRunTest{}
+	pl.extractTestCases(suite, nil, testCases)
+
+	results := map[string][2]interface{}{}
+	for key, tc := range testCases {
+		results[key] = [2]interface{}{tc.Status, tc.RetryFlake}
+	}
+	assert.Equal(t, map[string][2]interface{}{
+		"suite.retried":                  {13, true},
+		"suite.passed then failed":       {13, false},
+		"suite.failed twice":             {12, false},
+		"suite.failed then passed twice": {13, true},
+	}, results)
+}
+
+func TestExtractTestCasesTestLogs(t *testing.T) {
+	suite := &junit.TestSuite{
+		Name: "suite",
+		TestCases: []*junit.TestCase{
+			{Name: "passed", SystemOut: "all good"},
+			{Name: "failed", FailureOutput: &junit.FailureOutput{Output: "expected 1, got 2"}, SystemOut: "starting"},
+		},
+	}
+	cache := map[string]uint{"passed": 1, "failed": 2}
+
+	testCases := map[string]*models.ProwJobRunTest{}
+	(&ProwLoader{prowJobRunTestCache: cache}).extractTestCases(suite, nil, testCases)
+	assert.Nil(t, testCases["suite.failed"].ProwJobRunTestLog, "logs are only kept with a retention")
+
+	testCases = map[string]*models.ProwJobRunTest{}
+	(&ProwLoader{prowJobRunTestCache: cache, testLogRetention: time.Hour}).extractTestCases(suite, nil, testCases)
+	assert.Nil(t, testCases["suite.passed"].ProwJobRunTestLog)
+	testLog := testCases["suite.failed"].ProwJobRunTestLog
+	require.NotNil(t, testLog)
+	failureOutput, systemOut, err := testLog.Outputs()
+	require.NoError(t, err)
+	assert.Equal(t, "expected 1, got 2", failureOutput)
+	assert.Equal(t, "starting", systemOut)
+}
+
+func TestExtractSkippedTestCases(t *testing.T) {
+	skipped := func(name, message string) *junit.TestCase {
+		return &junit.TestCase{Name: name, SkipMessage: &junit.SkipMessage{Message: message}}
+	}
+	suite := &junit.TestSuite{
+		Name:      "suite",
+		TestCases: []*junit.TestCase{skipped("skipped", "no GPUs"), {Name: "passed"}, skipped("skipped twice", "first")},
+		Children: []*junit.TestSuite{
+			{Name: "child", TestCases: []*junit.TestCase{skipped("nested", "[Disabled:Broken]"), skipped("skipped twice", "second")}},
+		},
+	}
+	pl := &ProwLoader{prowJobRunTestCache: map[string]uint{"skipped": 1, "skipped twice": 2, "nested": 3}}
+
+	skips := map[string]*models.ProwJobRunSkippedTest{}
+	pl.extractSkippedTestCases(suite, nil, skips)
+
+	messages := map[string]string{}
+	for key, skip := range skips {
+		messages[key] = skip.Message
+	}
+	assert.Equal(t, map[string]string{
+		"suite.skipped":       "no GPUs",
+		"suite.skipped twice": "first",
+		"child.nested":        "[Disabled:Broken]",
+		"child.skipped twice": "second",
+	}, messages)
+}
+
+func TestJobRunTestSummary(t *testing.T) {
+	suite := &junit.TestSuite{
+		Name: "suite",
+		TestCases: []*junit.TestCase{
+			{Name: "passed"},
+			{Name: "b failed", FailureOutput: &junit.FailureOutput{Output: "failed"}},
+			{Name: "a failed", FailureOutput: &junit.FailureOutput{Output: "failed"}},
+			{Name: "flaked", FailureOutput: &junit.FailureOutput{Output: "failed"}},
+			{Name: "flaked"},
+		},
+	}
+	pl := &ProwLoader{prowJobRunTestCache: map[string]uint{"passed": 1, "b failed": 2, "a failed": 3, "flaked": 4}}
+
+	testCases := map[string]*models.ProwJobRunTest{}
+	pl.extractTestCases(suite, nil, testCases)
+	tests := make([]*models.ProwJobRunTest, 0, len(testCases))
+	for _, tc := range testCases {
+		tests = append(tests, tc)
+	}
+
+	run := &models.ProwJobRun{}
+	run.SetTestSummary(tests)
+	assert.Equal(t, 2, run.TestFailures)
+	assert.Equal(t, 1, run.TestFlakes)
+	assert.Equal(t, []string{"a failed", "b failed"}, []string(run.FailedTestNames))
+	assert.Equal(t, []string{"flaked"}, []string(run.FlakedTestNames))
+
+	run.SetTestSummary(nil)
+	assert.Equal(t, 0, run.TestFailures)
+	assert.NotNil(t, run.FailedTestNames, "runs without failures are summarized, not backfilled again")
+	assert.Empty(t, run.FailedTestNames)
+}
+
+func TestJobsJSONClusterProfile(t *testing.T) {
+	jobs, err := jobsJSONToProwJobs([]byte(`{"items": [
+		{"metadata": {"labels": {"ci-operator.openshift.io/cloud-cluster-profile": "aws-2"}},
+		 "spec": {"job": "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn", "cluster": "build05"}},
+		{"spec": {"job": "periodic-ci-openshift-release-master-ci-4.16-unit", "cluster": "build01"}}
+	]}`))
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, "aws-2", jobs[0].ClusterProfile())
+	assert.Equal(t, "build05", jobs[0].Spec.Cluster)
+	assert.Empty(t, jobs[1].ClusterProfile())
+}
+
+func TestArtifactLinks(t *testing.T) {
+	path := "logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1790000000000000000/"
+	step := path + "artifacts/e2e-aws-ovn/"
+	gcsweb := gcsWebURL + "test-platform-results/"
+
+	links := artifactLinks("test-platform-results", path,
+		[]string{step + "openshift-e2e-test/artifacts/junit/junit_e2e_2.xml",
+			step + "openshift-e2e-test/artifacts/junit/junit_e2e_1.xml"},
+		[]string{step + "openshift-e2e-test/build-log.txt", path + "build-log.txt"},
+		[]string{step + "gather-must-gather/artifacts/must-gather.tar"},
+		[]string{step + "openshift-e2e-test/artifacts/junit/e2e-events_20240501-101010.json"})
+	assert.Equal(t, models.ArtifactLinks{
+		models.ArtifactBuildLog:   gcsweb + path + "build-log.txt",
+		models.ArtifactJUnit:      gcsweb + step + "openshift-e2e-test/artifacts/junit/",
+		models.ArtifactMustGather: gcsweb + step + "gather-must-gather/artifacts/must-gather.tar",
+		models.ArtifactIntervals:  gcsweb + step + "openshift-e2e-test/artifacts/junit/e2e-events_20240501-101010.json",
+	}, links)
+
+	links = artifactLinks("test-platform-results", path, nil, []string{step + "openshift-e2e-test/build-log.txt"}, nil,
+		nil)
+	assert.Empty(t, links, "only the run's own build log is linked")
+}
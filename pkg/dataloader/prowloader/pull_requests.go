@@ -0,0 +1,111 @@
+package prowloader
+
+import (
+	"sort"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/github"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// repoPulls are the pull request numbers wanted from a repository.
+type repoPulls struct {
+	org     string
+	repo    string
+	numbers []int
+}
+
+// pullsToPrefetch returns the pull requests tested by the runs that will be imported, by repository. Runs already
+// imported, and runs that haven't finished or don't belong to a release, are skipped as their PRs aren't looked up.
+func (pl *ProwLoader) pullsToPrefetch(prowJobs []prow.ProwJob) []repoPulls {
+	byRepo := map[[2]string]map[int]bool{}
+	for _, pj := range prowJobs {
+		refs := pj.Spec.Refs
+		if refs == nil || len(refs.Pulls) == 0 {
+			continue
+		}
+		if pj.Status.State == prow.PendingState || pj.Status.State == prow.TriggeredState {
+			continue
+		}
+		id, err := strconv.ParseUint(pj.Status.BuildID, 0, 64)
+		if err != nil {
+			continue
+		}
+		pl.prowJobRunCacheLock.RLock()
+		imported := pl.prowJobRunCache[uint(id)]
+		pl.prowJobRunCacheLock.RUnlock()
+		if imported || pl.releaseForJob(pj.Spec.Job) == "" {
+			continue
+		}
+
+		key := [2]string{refs.Org, refs.Repo}
+		if byRepo[key] == nil {
+			byRepo[key] = map[int]bool{}
+		}
+		for _, pr := range refs.Pulls {
+			byRepo[key][pr.Number] = true
+		}
+	}
+
+	pulls := make([]repoPulls, 0, len(byRepo))
+	for key, numbers := range byRepo {
+		rp := repoPulls{org: key[0], repo: key[1]}
+		for number := range numbers {
+			rp.numbers = append(rp.numbers, number)
+		}
+		sort.Ints(rp.numbers)
+		pulls = append(pulls, rp)
+	}
+	sort.Slice(pulls, func(i, j int) bool {
+		if pulls[i].org != pulls[j].org {
+			return pulls[i].org < pulls[j].org
+		}
+		return pulls[i].repo < pulls[j].repo
+	})
+	return pulls
+}
+
+// prefetchPullRequests looks up the pull requests the runs to import tested before they're imported, so each PR
+// doesn't cost a GitHub API call. Merged pull requests already in the database are taken from there, as they can't
+// change, and the others are fetched from GitHub in batches. Pull requests that couldn't be prefetched are still looked
+// up one at a time as their runs are imported.
+func (pl *ProwLoader) prefetchPullRequests(prowJobs []prow.ProwJob) {
+	if pl.githubClient == nil {
+		return
+	}
+
+	for _, rp := range pl.pullsToPrefetch(prowJobs) {
+		merged := make([]models.ProwPullRequest, 0)
+		res := pl.dbc.DB.Where("org = ? AND repo = ? AND number IN ? AND merged_at IS NOT NULL", rp.org, rp.repo,
+			rp.numbers).Find(&merged)
+		if res.Error != nil {
+			log.WithError(res.Error).Warningf("error querying merged pull requests of %s/%s", rp.org, rp.repo)
+		}
+		for _, pr := range merged {
+			pl.githubClient.CacheMergedPR(pr.Org, pr.Repo, pr.Number, mergedPREntry(pr))
+		}
+
+		if err := pl.githubClient.PrefetchPREntries(rp.org, rp.repo, rp.numbers); err != nil {
+			log.WithError(err).Warningf("couldn't prefetch pull requests, they'll be fetched one at a time")
+		}
+	}
+}
+
+// mergedPREntry returns the GitHub entry of a merged pull request from its database row.
+func mergedPREntry(pr models.ProwPullRequest) *github.PREntry {
+	state := "closed"
+	entry := &github.PREntry{
+		MergedAt: pr.MergedAt,
+		SHA:      pr.SHA,
+		Title:    &pr.Title,
+		URL:      &pr.Link,
+		State:    &state,
+	}
+	if pr.Author != "" {
+		entry.Login = &pr.Author
+	}
+	return entry
+}
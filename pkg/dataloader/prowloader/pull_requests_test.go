@@ -0,0 +1,59 @@
+package prowloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1config "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestPullsToPrefetch(t *testing.T) {
+	pl := &ProwLoader{
+		releases:        []string{"4.16"},
+		config:          &v1config.SippyConfig{Releases: map[string]v1config.ReleaseConfig{"4.16": {Regexp: []string{`-4\.16-`}}}},
+		prowJobRunCache: map[uint]bool{1: true},
+	}
+	job := func(name, buildID string, state prow.ProwJobState, repo string, numbers ...int) prow.ProwJob {
+		pj := prow.ProwJob{
+			Spec:   prow.ProwJobSpec{Job: name},
+			Status: prow.ProwJobStatus{BuildID: buildID, State: state},
+		}
+		if repo != "" {
+			pj.Spec.Refs = &prow.Refs{Org: "openshift", Repo: repo}
+			for _, number := range numbers {
+				pj.Spec.Refs.Pulls = append(pj.Spec.Refs.Pulls, prow.Pull{Number: number})
+			}
+		}
+		return pj
+	}
+
+	pulls := pl.pullsToPrefetch([]prow.ProwJob{
+		job("pull-ci-origin-4.16-e2e", "2", prow.FailureState, "origin", 30, 10),
+		job("pull-ci-origin-4.16-e2e", "3", prow.SuccessState, "origin", 10, 20),
+		job("pull-ci-installer-4.16-e2e", "4", prow.SuccessState, "installer", 5),
+		job("pull-ci-origin-4.16-e2e", "1", prow.SuccessState, "origin", 40),
+		job("pull-ci-origin-4.16-e2e", "5", prow.PendingState, "origin", 50),
+		job("pull-ci-origin-4.15-e2e", "6", prow.SuccessState, "origin", 60),
+		job("periodic-ci-4.16-e2e", "7", prow.SuccessState, ""),
+	})
+	assert.Equal(t, []repoPulls{
+		{org: "openshift", repo: "installer", numbers: []int{5}},
+		{org: "openshift", repo: "origin", numbers: []int{10, 20, 30}},
+	}, pulls)
+}
+
+func TestMergedPREntry(t *testing.T) {
+	merged := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	entry := mergedPREntry(models.ProwPullRequest{Org: "openshift", Repo: "origin", Number: 1, Author: "dev",
+		Title: "Fix the thing", SHA: "abc", Link: "https://github.com/openshift/origin/pull/1", MergedAt: &merged})
+	assert.Equal(t, &merged, entry.MergedAt)
+	assert.Equal(t, "abc", entry.SHA)
+	assert.Equal(t, "Fix the thing", *entry.Title)
+	assert.Equal(t, "https://github.com/openshift/origin/pull/1", *entry.URL)
+	assert.Equal(t, "closed", *entry.State)
+	assert.Equal(t, "dev", *entry.Login)
+}
@@ -0,0 +1,145 @@
+package prowloader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/failureclassification"
+)
+
+// SetStoreJUnit makes the loader keep the junit files of the runs it imports, gzipped, so they can be reprocessed
+// without reading them from the bucket again. The paths of the junit files are always kept.
+func (pl *ProwLoader) SetStoreJUnit(storeJUnit bool) {
+	pl.storeJUnit = storeJUnit
+}
+
+// RunsToReprocess returns the IDs of the runs of the releases' jobs that started since the time, oldest first.
+func (pl *ProwLoader) RunsToReprocess(ctx context.Context, releases []string, since time.Time) ([]uint, error) {
+	ids := make([]uint, 0)
+	res := pl.dbc.DB.WithContext(ctx).Table("prow_job_runs").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_jobs.release IN ? AND prow_job_runs.timestamp >= ?", releases, since).
+		Where("prow_job_runs.deleted_at IS NULL").
+		Order("prow_job_runs.timestamp").
+		Pluck("prow_job_runs.id", &ids)
+	return ids, res.Error
+}
+
+// ReprocessRun parses an imported run's junit files and creates its synthetic tests again, replacing its tests,
+// skipped tests and overall result, i.e. after the parsing changed. The junit files kept when the run was imported are
+// used, otherwise they're read from the paths found then, so the bucket isn't listed again. Only the run's prowjob.json
+// and synthetic test artifacts are read from the bucket.
+func (pl *ProwLoader) ReprocessRun(ctx context.Context, id uint) error {
+	run := &models.ProwJobRun{}
+	res := pl.dbc.DB.WithContext(ctx).Preload("ProwJob").Preload("JUnits").Where("id = ?", id).Limit(1).Find(run)
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "error reading job run %d", id)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("job run %d wasn't imported", id)
+	}
+	if run.JUnitPaths == nil {
+		return fmt.Errorf("job run %d was imported before its junit files were recorded, import it again with load-run --replace", id)
+	}
+	runLog := log.WithFields(log.Fields{"job": run.ProwJob.Name, "run": id})
+
+	runURL, err := url.Parse(run.URL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URL for job run %d", id)
+	}
+	path := strings.TrimSuffix(gcsPathStrip.ReplaceAllString(runURL.Path, ""), "/") + "/"
+	pj, err := pl.readProwJob(ctx, path+"prowjob.json")
+	if err != nil {
+		return err
+	}
+	if pj == nil {
+		return fmt.Errorf("%s has no prowjob.json", path)
+	}
+
+	gcsJobRun := gcs.NewGCSJobRun(pl.bkt, path)
+	gcsJobRun.SetGCSJunitPaths(run.JUnitPaths)
+	for _, file := range run.JUnits {
+		content, err := gunzipJUnit(file.Content)
+		if err != nil {
+			return errors.Wrapf(err, "error decompressing junit file %s of job run %d", file.Path, id)
+		}
+		gcsJobRun.SetContent(file.Path, content)
+	}
+	runLog.Infof("reprocessing %d junit files, %d kept", len(run.JUnitPaths), len(run.JUnits))
+
+	tests, skipped, overallResult, err := pl.prowJobRunTestsFromGCS(ctx, pj, id, gcsJobRun, run.ArtifactPaths,
+		run.ProwJob.VariantDimensions.HostedControlPlane())
+	if err != nil {
+		return err
+	}
+	classification := pl.failureClassifier.Classify(run.ProwJob.Name, overallResult, failedTests(tests))
+	run.OverallResult = overallResult
+	run.Succeeded = overallResult == sippyprocessingv1.JobSucceeded
+	run.FailureClassification = string(classification)
+	run.InfrastructureFailure = classification == failureclassification.InfraFailure
+	run.SetTestSummary(tests)
+
+	err = pl.dbc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the tests' outputs are deleted by the foreign key cascade
+		if res := tx.Unscoped().Where("prow_job_run_id = ?", id).Delete(&models.ProwJobRunTest{}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Unscoped().Where("prow_job_run_id = ?", id).Delete(&models.ProwJobRunSkippedTest{}); res.Error != nil {
+			return res.Error
+		}
+		res := tx.Model(run).Select("overall_result", "succeeded", "failure_classification",
+			"infrastructure_failure", "test_failures", "test_flakes", "failed_test_names", "flaked_test_names",
+			"updated_at").Updates(run)
+		if res.Error != nil {
+			return res.Error
+		}
+		if err := tx.CreateInBatches(tests, 1000).Error; err != nil {
+			return err
+		}
+		if len(skipped) > 0 {
+			return tx.CreateInBatches(skipped, 1000).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error replacing the tests of job run %d", id)
+	}
+	runLog.Infof("reprocessed %d tests, %d skipped, overall result %s", len(tests), len(skipped), overallResult)
+	return nil
+}
+
+// gzipJUnit compresses a junit file to keep it.
+func gzipJUnit(content []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzipJUnit decompresses a kept junit file.
+func gunzipJUnit(compressed []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
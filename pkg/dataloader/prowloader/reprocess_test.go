@@ -0,0 +1,25 @@
+package prowloader
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipJUnit(t *testing.T) {
+	content := []byte(`<testsuite name="openshift-tests">` +
+		string(bytes.Repeat([]byte(`<testcase name="passes"></testcase>`), 1000)) + `</testsuite>`)
+
+	compressed, err := gzipJUnit(content)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(content)/10)
+
+	decompressed, err := gunzipJUnit(compressed)
+	require.NoError(t, err)
+	assert.Equal(t, content, decompressed)
+
+	_, err = gunzipJUnit(content)
+	assert.Error(t, err, "content that wasn't kept gzipped")
+}
@@ -0,0 +1,138 @@
+package prowloader
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	// maxImportAttempts is how many times a job run is imported before it's given up on.
+	maxImportAttempts = 3
+
+	// maxRetriesPerLoad caps how many failed job runs each load imports again, so a bad day doesn't slow every load
+	// after it.
+	maxRetriesPerLoad = 100
+)
+
+// retryableClasses are the classes of errors a later import may not get, i.e. timeouts reading from GCS and artifacts
+// that were only partly uploaded.
+var retryableClasses = []string{
+	models.LoaderErrorGCS,
+	models.LoaderErrorJUnit,
+	models.LoaderErrorDatabase,
+	models.LoaderErrorCancelled,
+}
+
+// failedImport is a job run that failed to import, and how many times it did.
+type failedImport struct {
+	ProwJobRunID uint
+	JobName      string
+	Path         string
+	Attempts     int
+	// Permanent counts the attempts that failed with an error that isn't retryable.
+	Permanent   int
+	LastAttempt time.Time
+}
+
+// retryFailedImports imports the job runs that previously failed with retryable errors again, recording the error of
+// any that fail again. A run is given up on once it has failed maxImportAttempts times.
+func (pl *ProwLoader) retryFailedImports(ctx context.Context) {
+	if pl.bkt == nil {
+		return
+	}
+
+	failed := make([]failedImport, 0)
+	res := pl.dbc.DB.WithContext(ctx).Raw(`SELECT prow_job_run_id, MAX(job_name) AS job_name, MAX(path) AS path,
+			COUNT(*) AS attempts, COUNT(*) FILTER (WHERE class NOT IN ?) AS permanent, MAX(created_at) AS last_attempt
+		FROM loader_errors
+		WHERE loader = ? AND deleted_at IS NULL AND prow_job_run_id <> 0
+			AND prow_job_run_id NOT IN (SELECT id FROM prow_job_runs)
+		GROUP BY prow_job_run_id`, retryableClasses, pl.Name()).Scan(&failed)
+	if res.Error != nil {
+		log.WithError(res.Error).Warning("error querying failed job run imports to retry")
+		return
+	}
+
+	retries := pl.importsToRetry(failed)
+	if len(retries) == 0 {
+		return
+	}
+	log.Infof("retrying %d job runs that failed to import", len(retries))
+
+	loaderErrs := make([]models.LoaderError, 0)
+	var imported int
+	for _, retry := range retries {
+		if ctx.Err() != nil {
+			break
+		}
+		pj, err := pl.readFailedProwJob(ctx, retry)
+		if err == nil {
+			err = pl.processProwJob(ctx, pj)
+		}
+		if err != nil {
+			log.WithError(err).Warningf("retry %d of job run %s/%d failed", retry.Attempts+1, retry.JobName,
+				retry.ProwJobRunID)
+			loaderErrs = append(loaderErrs, pl.loaderError(pj, err))
+			continue
+		}
+		imported++
+	}
+	if len(loaderErrs) > 0 {
+		if res := pl.dbc.DB.CreateInBatches(loaderErrs, 1000); res.Error != nil {
+			log.WithError(res.Error).Warning("error recording loader errors")
+		}
+	}
+	log.Infof("imported %d of %d job runs that failed to import before", imported, len(retries))
+}
+
+// importsToRetry returns the failed imports to retry, the most recent failures first: those that only failed with
+// retryable errors, fewer than maxImportAttempts times, and weren't imported since.
+func (pl *ProwLoader) importsToRetry(failed []failedImport) []failedImport {
+	retries := make([]failedImport, 0, len(failed))
+	for _, f := range failed {
+		if f.Permanent > 0 || f.Attempts >= maxImportAttempts {
+			continue
+		}
+		pl.prowJobRunCacheLock.RLock()
+		imported := pl.prowJobRunCache[f.ProwJobRunID]
+		pl.prowJobRunCacheLock.RUnlock()
+		if !imported {
+			retries = append(retries, f)
+		}
+	}
+	sort.Slice(retries, func(i, j int) bool {
+		return retries[i].LastAttempt.After(retries[j].LastAttempt)
+	})
+	if len(retries) > maxRetriesPerLoad {
+		retries = retries[:maxRetriesPerLoad]
+	}
+	return retries
+}
+
+// readFailedProwJob reads the prowjob.json of a job run that failed to import. On failure it returns a ProwJob with
+// what's known of the run, so the failure can be recorded against it.
+func (pl *ProwLoader) readFailedProwJob(ctx context.Context, retry failedImport) (*prow.ProwJob, error) {
+	known := &prow.ProwJob{
+		Spec:   prow.ProwJobSpec{Job: retry.JobName},
+		Status: prow.ProwJobStatus{BuildID: fmt.Sprint(retry.ProwJobRunID), URL: retry.Path},
+	}
+	runPath, err := gcsRunPath(retry.Path, pl.bktName)
+	if err != nil {
+		return known, classified(models.LoaderErrorGCSPath, err)
+	}
+	pj, err := pl.readProwJob(ctx, runPath+"prowjob.json")
+	if err != nil {
+		return known, classified(models.LoaderErrorGCS, err)
+	}
+	if pj == nil {
+		return known, classified(models.LoaderErrorGCSPath, fmt.Errorf("%s has no prowjob.json", runPath))
+	}
+	return pj, nil
+}
@@ -0,0 +1,33 @@
+package prowloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportsToRetry(t *testing.T) {
+	now := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+	pl := &ProwLoader{prowJobRunCache: map[uint]bool{4: true}}
+
+	retries := pl.importsToRetry([]failedImport{
+		{ProwJobRunID: 1, Attempts: 1, LastAttempt: now.Add(-2 * time.Hour)},
+		{ProwJobRunID: 2, Attempts: maxImportAttempts, LastAttempt: now},
+		{ProwJobRunID: 3, Attempts: 1, Permanent: 1, LastAttempt: now},
+		{ProwJobRunID: 4, Attempts: 1, LastAttempt: now},
+		{ProwJobRunID: 5, Attempts: 2, LastAttempt: now.Add(-time.Hour)},
+	})
+
+	ids := make([]uint, 0, len(retries))
+	for _, retry := range retries {
+		ids = append(ids, retry.ProwJobRunID)
+	}
+	assert.Equal(t, []uint{5, 1}, ids)
+
+	many := make([]failedImport, maxRetriesPerLoad+10)
+	for i := range many {
+		many[i] = failedImport{ProwJobRunID: uint(100 + i), Attempts: 1}
+	}
+	assert.Len(t, pl.importsToRetry(many), maxRetriesPerLoad)
+}
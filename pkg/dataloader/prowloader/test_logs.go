@@ -0,0 +1,37 @@
+package prowloader
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// SetTestLogRetention makes the loader keep the failure output and system-out of the failed tests of the runs it
+// imports, zstd-compressed, for the retention period. Logs older than that are deleted at the end of each load; zero
+// doesn't keep them.
+func (pl *ProwLoader) SetTestLogRetention(retention time.Duration) {
+	pl.testLogRetention = retention
+}
+
+// testLog returns the log to keep of a failed test's outputs, or nil if logs aren't kept.
+func (pl *ProwLoader) testLog(failureOutput, systemOut string) *models.ProwJobRunTestLog {
+	if pl.testLogRetention <= 0 {
+		return nil
+	}
+	return models.NewProwJobRunTestLog(failureOutput, systemOut)
+}
+
+// pruneTestLogs deletes the test logs older than the retention period. The test results they belong to are kept.
+func (pl *ProwLoader) pruneTestLogs() {
+	if pl.testLogRetention <= 0 {
+		return
+	}
+	res := pl.dbc.DB.Where("created_at < ?", time.Now().Add(-pl.testLogRetention)).Delete(&models.ProwJobRunTestLog{})
+	if res.Error != nil {
+		log.WithError(res.Error).Warning("error deleting old test logs")
+		return
+	}
+	log.Infof("deleted %d test logs older than %s", res.RowsAffected, pl.testLogRetention)
+}
@@ -9,13 +9,18 @@ import (
 	"github.com/openshift/sippy/pkg/testidentification"
 )
 
-func ConvertProwJobRunToSyntheticTests(pj prow.ProwJob, tests map[string]*models.ProwJobRunTest, manager synthetictests.SyntheticTestManager) (*junit.TestSuite, v1.JobOverallResult) {
+func ConvertProwJobRunToSyntheticTests(pj prow.ProwJob, tests map[string]*models.ProwJobRunTest, artifacts []string, hostedControlPlane bool, manager synthetictests.SyntheticTestManager) (*junit.TestSuite, v1.JobOverallResult) {
 	jrr := v1.RawJobRunResult{
-		Job:       pj.Spec.Job,
-		Errored:   pj.Status.State == prow.ErrorState,
-		Failed:    pj.Status.State == prow.FailureState,
-		Succeeded: pj.Status.State == prow.SuccessState,
-		Aborted:   pj.Status.State == prow.AbortedState,
+		Job:                pj.Spec.Job,
+		Errored:            pj.Status.State == prow.ErrorState,
+		Failed:             pj.Status.State == prow.FailureState,
+		Succeeded:          pj.Status.State == prow.SuccessState,
+		Aborted:            pj.Status.State == prow.AbortedState,
+		Artifacts:          artifacts,
+		HostedControlPlane: hostedControlPlane,
+	}
+	if pj.Status.CompletionTime != nil {
+		jrr.Duration = pj.Status.CompletionTime.Sub(pj.Status.StartTime)
 	}
 	testsToRawJobRunResult(&jrr, tests)
 	syntheticTests := manager.CreateSyntheticTests(&jrr)
@@ -0,0 +1,56 @@
+package prowloader
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	v1config "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// releaseControllerTiers maps the kinds of the payload jobs the release controller runs to their tiers.
+var releaseControllerTiers = map[string]string{
+	"Blocking":  models.JobTierBlocking,
+	"Informing": models.JobTierInforming,
+}
+
+// loadPayloadJobKinds returns the kind of each job the release controller last ran on a payload.
+func loadPayloadJobKinds(dbc *db.DB) map[string]string {
+	type jobKind struct {
+		JobName string
+		Kind    string
+	}
+	kinds := make([]jobKind, 0)
+	res := dbc.DB.Raw(`SELECT DISTINCT ON (job_name) job_name, kind FROM release_job_runs
+		WHERE deleted_at IS NULL ORDER BY job_name, transition_time DESC`).Scan(&kinds)
+	if res.Error != nil {
+		log.WithError(res.Error).Warning("error loading payload job kinds, job tiers are only taken from the config")
+	}
+
+	payloadJobKinds := map[string]string{}
+	for _, kind := range kinds {
+		payloadJobKinds[kind.JobName] = kind.Kind
+	}
+	return payloadJobKinds
+}
+
+// jobTier returns the job's tier: the most important tier the release's configuration lists it under, otherwise that
+// of the kind of payload job the release controller ran it as, or an empty string when it's neither.
+func jobTier(releaseConfig v1config.ReleaseConfig, payloadJobKinds map[string]string, jobName string) string {
+	configured := []struct {
+		tier string
+		jobs []string
+	}{
+		{models.JobTierBlocking, releaseConfig.BlockingJobs},
+		{models.JobTierInforming, releaseConfig.InformingJobs},
+		{models.JobTierExperimental, releaseConfig.ExperimentalJobs},
+	}
+	for _, c := range configured {
+		for _, job := range c.jobs {
+			if job == jobName {
+				return c.tier
+			}
+		}
+	}
+	return releaseControllerTiers[payloadJobKinds[jobName]]
+}
@@ -0,0 +1,43 @@
+package prowloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1config "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestJobTier(t *testing.T) {
+	releaseConfig := v1config.ReleaseConfig{
+		BlockingJobs:     []string{"e2e-aws", "e2e-both"},
+		InformingJobs:    []string{"e2e-gcp", "e2e-both"},
+		ExperimentalJobs: []string{"e2e-new"},
+	}
+	payloadJobKinds := map[string]string{
+		"e2e-gcp":   "Blocking",
+		"e2e-azure": "Informing",
+		"e2e-metal": "Blocking",
+		"e2e-other": "Unknown",
+	}
+
+	tests := []struct {
+		job          string
+		expectedTier string
+	}{
+		{job: "e2e-aws", expectedTier: models.JobTierBlocking},
+		{job: "e2e-both", expectedTier: models.JobTierBlocking},
+		{job: "e2e-gcp", expectedTier: models.JobTierInforming},
+		{job: "e2e-new", expectedTier: models.JobTierExperimental},
+		{job: "e2e-azure", expectedTier: models.JobTierInforming},
+		{job: "e2e-metal", expectedTier: models.JobTierBlocking},
+		{job: "e2e-other", expectedTier: ""},
+		{job: "e2e-unknown", expectedTier: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.job, func(t *testing.T) {
+			assert.Equal(t, tc.expectedTier, jobTier(releaseConfig, payloadJobKinds, tc.job))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package releaseloader
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,12 +19,17 @@ import (
 	"github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/notify"
 )
 
 const (
 	releaseTagsTable = "release_tags"
 	succeeded        = "Succeeded"
 	failed           = "Failed"
+
+	// rejectionNotificationWindow limits notifications to recently rejected payloads, so an initial load doesn't
+	// notify about every historical rejection
+	rejectionNotificationWindow = 24 * time.Hour
 )
 
 type ReleaseLoader struct {
@@ -32,9 +38,10 @@ type ReleaseLoader struct {
 	releases      []string
 	architectures []string
 	errors        []error
+	notifier      notify.Notifier
 }
 
-func New(dbc *db.DB, releases, architectures []string) *ReleaseLoader {
+func New(dbc *db.DB, releases, architectures []string, notifier notify.Notifier) *ReleaseLoader {
 	releaseStreams := make([]string, 0)
 	for _, release := range releases {
 		for _, stream := range []string{"nightly", "ci"} {
@@ -47,6 +54,7 @@ func New(dbc *db.DB, releases, architectures []string) *ReleaseLoader {
 		releases:      releaseStreams,
 		architectures: architectures,
 		httpClient:    &http.Client{Timeout: 60 * time.Second},
+		notifier:      notifier,
 	}
 }
 
@@ -58,7 +66,7 @@ func (r *ReleaseLoader) Errors() []error {
 	return r.errors
 }
 
-func (r *ReleaseLoader) Load() {
+func (r *ReleaseLoader) Load(_ context.Context) {
 	for _, release := range r.releases {
 		log.Infof("Fetching release %s from release controller...", release)
 		allTags := r.fetchReleaseTags(release)
@@ -76,6 +84,8 @@ func (r *ReleaseLoader) Load() {
 						if err := r.db.DB.Clauses(clause.OnConflict{UpdateAll: true}).Table(releaseTagsTable).Save(mReleaseTag).Error; err != nil {
 							log.WithError(err).Errorf("error updating release tag")
 							r.errors = append(r.errors, errors.Wrapf(err, "error updating release tag %s for new phase: %s -> %s", tag.Name, mReleaseTag.Phase, tag.Phase))
+						} else {
+							r.notifyRejected(&mReleaseTag)
 						}
 					}
 					continue
@@ -90,12 +100,38 @@ func (r *ReleaseLoader) Load() {
 
 				if err := r.db.DB.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&releaseTag, 100).Error; err != nil {
 					r.errors = append(r.errors, errors.Wrapf(err, "error creating release tag: %s", releaseTag.ReleaseTag))
+				} else {
+					r.notifyRejected(releaseTag)
 				}
 			}
 		}
 	}
 }
 
+func (r *ReleaseLoader) notifyRejected(releaseTag *models.ReleaseTag) {
+	if releaseTag.Phase != api.PayloadRejected || time.Since(releaseTag.ReleaseTime) > rejectionNotificationWindow {
+		return
+	}
+
+	releaseName := fmt.Sprintf("%s.0-0.%s", releaseTag.Release, releaseTag.Stream)
+	if releaseTag.Architecture != "amd64" {
+		releaseName += "-" + releaseTag.Architecture
+	}
+
+	err := r.notifier.Notify(notify.Event{
+		Type:     notify.EventPayloadRejected,
+		Release:  releaseTag.Release,
+		Variants: []string{releaseTag.Architecture},
+		Title:    fmt.Sprintf("Payload %s was rejected", releaseTag.ReleaseTag),
+		Message:  fmt.Sprintf("%s %s payload for %s", releaseTag.Stream, releaseTag.Architecture, releaseTag.Release),
+		URL: fmt.Sprintf("https://%s.ocp.releases.ci.openshift.org/releasestream/%s/release/%s",
+			releaseTag.Architecture, releaseName, releaseTag.ReleaseTag),
+	})
+	if err != nil {
+		log.WithError(err).Warningf("error notifying about rejected payload %s", releaseTag.ReleaseTag)
+	}
+}
+
 func (r *ReleaseLoader) buildReleaseTag(architecture, release string, tag ReleaseTag) *models.ReleaseTag {
 	releaseDetails := r.fetchReleaseDetails(architecture, release, tag)
 	releaseTag := releaseDetailsToDB(architecture, tag, releaseDetails)
@@ -224,6 +260,9 @@ func releaseDetailsToDB(architecture string, tag ReleaseTag, details ReleaseDeta
 		release.Repositories = changelog.Repositories()
 		release.PullRequests = changelog.PullRequests()
 	}
+	for i := range release.Repositories {
+		release.Repositories[i].FromCommit, release.Repositories[i].ToCommit = compareCommits(release.Repositories[i].DiffURL)
+	}
 	release.JobRuns = releaseJobRunsToDB(details)
 
 	// set forced flag
@@ -332,6 +371,20 @@ func parseChangeLogJSON(releaseTag string, changeLogJSON ChangeLog) models.Relea
 	return releaseChangeLogJSON
 }
 
+// compareCommits returns the commits a GitHub compare URL diffs, e.g.
+// https://github.com/openshift/installer/compare/ba940311c8cb...303c9d494a15, or empty strings for any other URL.
+func compareCommits(diffURL string) (from, to string) {
+	_, commits, ok := strings.Cut(diffURL, "/compare/")
+	if !ok {
+		return "", ""
+	}
+	from, to, ok = strings.Cut(commits, "...")
+	if !ok {
+		return "", ""
+	}
+	return from, to
+}
+
 func releaseJobRunsToDB(details ReleaseDetails) []models.ReleaseJobRun {
 	rows := make([]models.ReleaseJobRun, 0)
 	results := make(map[uint]models.ReleaseJobRun)
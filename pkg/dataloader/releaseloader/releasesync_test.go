@@ -286,3 +286,25 @@ func TestChangeLog(t *testing.T) {
 		}
 	}
 }
+
+func TestCompareCommits(t *testing.T) {
+	tests := []struct {
+		url      string
+		from, to string
+	}{
+		{
+			url:  "https://github.com/openshift/installer/compare/ba940311c8cb2a07173725e5c2f668df7c61924c...303c9d494a1503c134b92b15c5da8ac8ca01295d",
+			from: "ba940311c8cb2a07173725e5c2f668df7c61924c",
+			to:   "303c9d494a1503c134b92b15c5da8ac8ca01295d",
+		},
+		{
+			url: "https://github.com/openshift/installer/tree/303c9d494a1503c134b92b15c5da8ac8ca01295d",
+		},
+	}
+	for _, tt := range tests {
+		from, to := compareCommits(tt.url)
+		if from != tt.from || to != tt.to {
+			t.Errorf("compareCommits(%q) = %q, %q, want %q, %q", tt.url, from, to, tt.from, tt.to)
+		}
+	}
+}
@@ -43,7 +43,7 @@ func (tol *TestOwnershipLoader) Name() string {
 	return "test ownership"
 }
 
-func (tol *TestOwnershipLoader) Load() {
+func (tol *TestOwnershipLoader) Load(_ context.Context) {
 	mappings, err := tol.mappingTableMgr.ListMappings()
 	if err != nil {
 		tol.errors = append(tol.errors, err)
@@ -123,6 +123,7 @@ func (tol *TestOwnershipLoader) Load() {
 			SuiteID:               suiteID,
 			JiraComponentID:       jiraComponentID,
 		}
+		tom.SetComponentHierarchy()
 		known++
 		res = tol.dbc.DB.Model(&models.TestOwnership{}).Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "name"}, {Name: "suite"}},
@@ -143,6 +144,11 @@ func (tol *TestOwnershipLoader) Load() {
 		tol.errors = append(tol.errors, oldRecords.Error)
 	}
 
+	if err := tol.dbc.ApplyTestOwnershipOverrides(); err != nil {
+		log.WithError(err).Warningf("couldn't apply test ownership overrides")
+		tol.errors = append(tol.errors, errors.Wrap(err, "error applying test ownership overrides"))
+	}
+
 	log.WithFields(log.Fields{
 		"known":    known,
 		"unknown":  unknown,
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"gorm.io/gorm"
+)
+
+// registerAroundCallbacks registers a pair of callbacks surrounding each of gorm's statement processors, so that
+// instrumentation can observe every create, query, update, delete, row and raw statement.
+func registerAroundCallbacks(db *gorm.DB, name string, before, after func(operation string) func(*gorm.DB)) error {
+	callbacks := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("gorm:create").Register, callbacks.Create().After("gorm:create").Register},
+		{"query", callbacks.Query().Before("gorm:query").Register, callbacks.Query().After("gorm:query").Register},
+		{"update", callbacks.Update().Before("gorm:update").Register, callbacks.Update().After("gorm:update").Register},
+		{"delete", callbacks.Delete().Before("gorm:delete").Register, callbacks.Delete().After("gorm:delete").Register},
+		{"row", callbacks.Row().Before("gorm:row").Register, callbacks.Row().After("gorm:row").Register},
+		{"raw", callbacks.Raw().Before("gorm:raw").Register, callbacks.Raw().After("gorm:raw").Register},
+	}
+	for _, p := range processors {
+		if err := p.before("sippy:"+name+"_before_"+p.operation, before(p.operation)); err != nil {
+			return err
+		}
+		if err := p.after("sippy:"+name+"_after_"+p.operation, after(p.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
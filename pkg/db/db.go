@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	gormlogger "gorm.io/gorm/logger"
 
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
 )
 
 type SchemaHashType string
@@ -20,6 +22,7 @@ const (
 	hashTypeMatView      SchemaHashType = "matview"
 	hashTypeMatViewIndex SchemaHashType = "matview_index"
 	hashTypeFunction     SchemaHashType = "function"
+	hashTypeIndex        SchemaHashType = "index"
 )
 
 type DB struct {
@@ -40,29 +43,83 @@ func (w log2LogrusWriter) Printf(msg string, args ...interface{}) {
 	w.entry.Debugf(msg, args...)
 }
 
-func New(dsn string, logLevel gormlogger.LogLevel) (*DB, error) {
-	gormLogger := gormlogger.New(
-		log2LogrusWriter{entry: log.WithField("source", "gorm")},
-		gormlogger.Config{
+// contextLogger is a gorm logger whose messages include the request ID of the statement's context, so they can be
+// traced back to the API call or loader run that made them.
+type contextLogger struct {
+	config gormlogger.Config
+}
+
+func (l contextLogger) logger(ctx context.Context) gormlogger.Interface {
+	return gormlogger.New(log2LogrusWriter{entry: logging.FromContext(ctx).WithField("source", "gorm")}, l.config)
+}
+
+func (l contextLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	l.config.LogLevel = level
+	return l
+}
+
+func (l contextLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.logger(ctx).Info(ctx, msg, args...)
+}
+
+func (l contextLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.logger(ctx).Warn(ctx, msg, args...)
+}
+
+func (l contextLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.logger(ctx).Error(ctx, msg, args...)
+}
+
+func (l contextLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.logger(ctx).Trace(ctx, begin, fc, err)
+}
+
+// New connects to the database. Up to statementCacheSize statements are kept prepared on each connection, 0 disables
+// preparing them.
+func New(dsn string, logLevel gormlogger.LogLevel, statementCacheSize int) (*DB, error) {
+	gormLogger := contextLogger{
+		config: gormlogger.Config{
 			SlowThreshold:             2 * time.Second,
 			LogLevel:                  logLevel,
 			IgnoreRecordNotFoundError: true,
 			Colorful:                  false,
 		},
-	)
+	}
 
+	dsn, err := statementCacheDSN(dsn, statementCacheSize)
+	if err != nil {
+		return nil, err
+	}
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: gormLogger,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := registerMetricsCallbacks(db); err != nil {
+		return nil, err
+	}
+	if err := registerTracingCallbacks(db); err != nil {
+		return nil, err
+	}
 	return &DB{
 		DB:        db,
 		BatchSize: 1024,
 	}, nil
 }
 
+// WithContext returns a copy of the DB whose statements run with ctx, so that they are traced as part of the
+// caller's span. A nil DB is returned unchanged.
+func (d *DB) WithContext(ctx context.Context) *DB {
+	if d == nil {
+		return nil
+	}
+	return &DB{
+		DB:        d.DB.WithContext(ctx),
+		BatchSize: d.BatchSize,
+	}
+}
+
 func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 
 	if err := d.DB.AutoMigrate(&models.ReleaseTag{}); err != nil {
@@ -105,10 +162,22 @@ func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.ProwJobRunTestLog{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.ProwJobRunTestOutputMetadata{}); err != nil {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.ProwJobRunSkippedTest{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.ProwJobRunJUnit{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.APISnapshot{}); err != nil {
 		return err
 	}
@@ -117,6 +186,10 @@ func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.BugTestCorrelation{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.ProwPullRequest{}); err != nil {
 		return err
 	}
@@ -129,10 +202,66 @@ func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.PostedComment{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.JiraIncident{}); err != nil {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.SustainedRegression{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.TestRegression{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.SilentJob{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.LoaderError{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.LoaderSuccess{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.FunnelDay{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.TestWeeklyResult{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.TestAnalysisByJobDay{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.TestAnalysisByVariantDay{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.JobWeeklyResult{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.TestDailyResult{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.AsyncJob{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.ArchivedProwJobRun{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.JiraComponent{}); err != nil {
 		return err
 	}
@@ -141,10 +270,42 @@ func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.TestOwnershipOverride{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.TestQuarantine{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.RefreshRun{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.AuditLog{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.SavedView{}); err != nil {
+		return err
+	}
+
 	if err := populateTestSuitesInDB(d.DB); err != nil {
 		return err
 	}
 
+	if err := backfillJobRunTestSummaries(d.DB); err != nil {
+		return err
+	}
+
+	if err := backfillTestTags(d.DB); err != nil {
+		return err
+	}
+
+	if err := syncTrigramIndexes(d.DB); err != nil {
+		return err
+	}
+
 	if err := syncPostgresMaterializedViews(d.DB, reportEnd); err != nil {
 		return err
 	}
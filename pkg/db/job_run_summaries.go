@@ -0,0 +1,46 @@
+package db
+
+import (
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// jobRunSummaryBackfillBatchSize is how many job runs are summarized per statement, keeping each transaction short on
+// large databases.
+const jobRunSummaryBackfillBatchSize = 10000
+
+// backfillJobRunTestSummaries summarizes the failed and flaked tests of job runs loaded before the summaries were
+// stored on them, which have no failed test names. Runs loaded since have them set by the loader, so this is a no-op
+// once the backfill completes.
+func backfillJobRunTestSummaries(db *gorm.DB) error {
+	total := int64(0)
+	for {
+		res := db.Exec(`
+UPDATE prow_job_runs SET
+	failed_test_names = COALESCE((
+		SELECT array_agg(tests.name ORDER BY tests.name) FROM prow_job_run_tests
+			JOIN tests ON tests.id = prow_job_run_tests.test_id
+		WHERE prow_job_run_tests.prow_job_run_id = prow_job_runs.id AND prow_job_run_tests.status = 12), '{}'),
+	flaked_test_names = COALESCE((
+		SELECT array_agg(tests.name ORDER BY tests.name) FROM prow_job_run_tests
+			JOIN tests ON tests.id = prow_job_run_tests.test_id
+		WHERE prow_job_run_tests.prow_job_run_id = prow_job_runs.id AND prow_job_run_tests.status = 13), '{}'),
+	test_failures = (
+		SELECT COUNT(*) FROM prow_job_run_tests
+		WHERE prow_job_run_tests.prow_job_run_id = prow_job_runs.id AND prow_job_run_tests.status = 12),
+	test_flakes = (
+		SELECT COUNT(*) FROM prow_job_run_tests
+		WHERE prow_job_run_tests.prow_job_run_id = prow_job_runs.id AND prow_job_run_tests.status = 13)
+WHERE prow_job_runs.id IN (SELECT id FROM prow_job_runs WHERE failed_test_names IS NULL LIMIT ?)`,
+			jobRunSummaryBackfillBatchSize)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			break
+		}
+		total += res.RowsAffected
+		log.WithField("runs", total).Info("backfilled job run test summaries")
+	}
+	return nil
+}
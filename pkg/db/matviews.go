@@ -16,7 +16,9 @@ var PostgresMatViews = []PostgresMaterializedView{
 	{
 		Name:         "prow_test_report_7d_matview",
 		Definition:   testReportMatView,
-		IndexColumns: []string{"id", "name", "release", "variants", "suite_name"},
+		IndexColumns: []string{"id", "name", "release", "variants", "suite_id"},
+		Tables: []string{"prow_job_run_tests", "prow_job_runs", "prow_jobs", "tests", "suites", "bugs", "bug_tests",
+			"test_ownerships", "jira_components"},
 		ReplaceStrings: map[string]string{
 			"|||START|||":    "|||TIMENOW||| - INTERVAL '14 DAY'",
 			"|||BOUNDARY|||": "|||TIMENOW||| - INTERVAL '7 DAY'",
@@ -26,7 +28,9 @@ var PostgresMatViews = []PostgresMaterializedView{
 	{
 		Name:         "prow_test_report_2d_matview",
 		Definition:   testReportMatView,
-		IndexColumns: []string{"id", "name", "release", "variants", "suite_name"},
+		IndexColumns: []string{"id", "name", "release", "variants", "suite_id"},
+		Tables: []string{"prow_job_run_tests", "prow_job_runs", "prow_jobs", "tests", "suites", "bugs", "bug_tests",
+			"test_ownerships", "jira_components"},
 		ReplaceStrings: map[string]string{
 			"|||START|||":    "|||TIMENOW||| - INTERVAL '9 DAY'",
 			"|||BOUNDARY|||": "|||TIMENOW||| - INTERVAL '2 DAY'",
@@ -37,21 +41,25 @@ var PostgresMatViews = []PostgresMaterializedView{
 		Name:         "prow_test_analysis_by_variant_14d_matview",
 		Definition:   testAnalysisByVariantMatView,
 		IndexColumns: []string{"test_id", "test_name", "date", "variant", "release"},
+		Tables:       []string{"prow_job_run_tests", "prow_job_runs", "prow_jobs", "tests"},
 	},
 	{
 		Name:         "prow_test_analysis_by_job_14d_matview",
 		Definition:   testAnalysisByJobMatView,
 		IndexColumns: []string{"test_id", "test_name", "date", "job_name"},
+		Tables:       []string{"prow_job_run_tests", "prow_job_runs", "prow_jobs", "tests"},
 	},
 	{
 		Name:         "prow_job_runs_report_matview",
 		Definition:   jobRunsReportMatView,
 		IndexColumns: []string{"id"},
+		Tables:       []string{"prow_job_runs", "prow_jobs", "prow_pull_requests", "prow_job_run_prow_pull_requests"},
 	},
 	{
 		Name:         "prow_job_failed_tests_by_day_matview",
 		Definition:   prowJobFailedTestsMatView,
 		IndexColumns: []string{"period", "prow_job_id", "test_name"},
+		Tables:       []string{"prow_job_run_tests", "prow_job_runs", "tests"},
 		ReplaceStrings: map[string]string{
 			"|||BY|||": "day",
 		},
@@ -60,6 +68,7 @@ var PostgresMatViews = []PostgresMaterializedView{
 		Name:         "prow_job_failed_tests_by_hour_matview",
 		Definition:   prowJobFailedTestsMatView,
 		IndexColumns: []string{"period", "prow_job_id", "test_name"},
+		Tables:       []string{"prow_job_run_tests", "prow_job_runs", "tests"},
 		ReplaceStrings: map[string]string{
 			"|||BY|||": "hour",
 		},
@@ -67,11 +76,19 @@ var PostgresMatViews = []PostgresMaterializedView{
 	{
 		// TODO: this probably doesn't need to be a matview anymore since we only keep 3 months of data,
 		// metrics show this refreshing in .6s a lot of the time, occasionally up to 5s.
-		Name:           "payload_test_failures_14d_matview",
-		Definition:     payloadTestFailuresMatView,
-		IndexColumns:   []string{"release", "architecture", "stream", "prow_job_run_id", "test_id", "suite_id"},
+		Name:         "payload_test_failures_14d_matview",
+		Definition:   payloadTestFailuresMatView,
+		IndexColumns: []string{"release", "architecture", "stream", "prow_job_run_id", "test_id", "suite_id"},
+		Tables: []string{"release_tags", "release_job_runs", "prow_job_run_tests", "prow_job_runs", "prow_jobs",
+			"tests"},
 		ReplaceStrings: map[string]string{},
 	},
+	{
+		Name:         "prow_test_durations_14d_matview",
+		Definition:   testDurationsMatView,
+		IndexColumns: []string{"release", "test_id"},
+		Tables:       []string{"prow_job_run_tests", "prow_job_runs", "prow_jobs", "tests"},
+	},
 }
 
 type PostgresMaterializedView struct {
@@ -85,6 +102,55 @@ type PostgresMaterializedView struct {
 	// replaced if changes are made to these values. IndexColumns are required as we need them defined to be able to
 	// refresh materialized views concurrently. (avoiding locking reads for several minutes while we update)
 	IndexColumns []string
+	// Tables are the tables the view is computed from, so only the views whose data changed are refreshed after a
+	// load.
+	Tables []string
+	// DependsOn are the materialized views the view is computed from, which are refreshed before it. A view can only
+	// depend on views listed before it.
+	DependsOn []string
+}
+
+// MatViewsForTables returns the names of the materialized views computed from any of the tables, and of the views
+// depending on them, in the order of PostgresMatViews.
+func MatViewsForTables(tables []string) []string {
+	changed := map[string]bool{}
+	for _, table := range tables {
+		changed[table] = true
+	}
+
+	views := make([]string, 0)
+	for _, pmv := range PostgresMatViews {
+		affected := false
+		for _, name := range pmv.Tables {
+			affected = affected || changed[name]
+		}
+		for _, name := range pmv.DependsOn {
+			affected = affected || changed[name]
+		}
+		if affected {
+			// views only depend on those listed before them, so a dependent is found after its dependencies
+			changed[pmv.Name] = true
+			views = append(views, pmv.Name)
+		}
+	}
+	return views
+}
+
+// timestampSQL returns t as a SQL timestamp literal.
+func timestampSQL(t time.Time) string {
+	return "TO_TIMESTAMP('" + t.UTC().Format(timestampFormat) + "', 'YYYY-MM-DD HH24:MI:SS')"
+}
+
+// TestReportQuery returns the query of the test report views for arbitrary windows, comparing the results between
+// start and boundary to those between boundary and end, and leaving out runs after end.
+func TestReportQuery(start, boundary, end time.Time) string {
+	query := strings.Replace(testReportMatView, testReportStartCondition,
+		testReportStartCondition+" AND prow_job_runs.timestamp <= |||END|||", 1)
+	return strings.NewReplacer(
+		"|||START|||", timestampSQL(start),
+		"|||BOUNDARY|||", timestampSQL(boundary),
+		"|||END|||", timestampSQL(end),
+	).Replace(query)
 }
 
 func syncPostgresMaterializedViews(db *gorm.DB, reportEnd *time.Time) error {
@@ -93,7 +159,7 @@ func syncPostgresMaterializedViews(db *gorm.DB, reportEnd *time.Time) error {
 	reportEndFmt := "NOW()"
 
 	if reportEnd != nil {
-		reportEndFmt = "TO_TIMESTAMP('" + reportEnd.UTC().Format(timestampFormat) + "', 'YYYY-MM-DD HH24:MI:SS')"
+		reportEndFmt = timestampSQL(*reportEnd)
 	}
 
 	for _, pmv := range PostgresMatViews {
@@ -126,26 +192,7 @@ func syncPostgresMaterializedViews(db *gorm.DB, reportEnd *time.Time) error {
 }
 
 const jobRunsReportMatView = `
-WITH failed_test_results AS (
-	SELECT prow_job_run_tests.prow_job_run_id,
-		array_agg(tests.id) AS test_ids,
-		count(tests.id) AS test_count,
-		array_agg(tests.name) AS test_names
-	FROM prow_job_run_tests
-		JOIN tests ON tests.id = prow_job_run_tests.test_id
-	WHERE prow_job_run_tests.status = 12
-	GROUP BY prow_job_run_tests.prow_job_run_id
-), flaked_test_results AS (
-	SELECT prow_job_run_tests.prow_job_run_id,
-		array_agg(tests.id) AS test_ids,
-		count(tests.id) AS test_count,
-		array_agg(tests.name) AS test_names
-	FROM prow_job_run_tests
-		JOIN tests ON tests.id = prow_job_run_tests.test_id
-	WHERE prow_job_run_tests.status = 13
-	GROUP BY prow_job_run_tests.prow_job_run_id
-),
-pull_requests AS (
+WITH pull_requests AS (
 	SELECT
 		DISTINCT ON(prow_job_runs.id)
 		prow_job_runs.id as id,
@@ -171,28 +218,32 @@ SELECT prow_job_runs.id,
    prow_job_runs.overall_result,
    prow_job_runs.url AS test_grid_url,
    prow_job_runs.url,
+   prow_job_runs.artifacts,
    prow_job_runs.succeeded,
    prow_job_runs.infrastructure_failure,
    prow_job_runs.known_failure,
    (EXTRACT(epoch FROM (prow_job_runs."timestamp" AT TIME ZONE 'utc'::text)) * 1000::numeric)::bigint AS "timestamp",
    prow_job_runs.id AS prow_id,
    prow_job_runs.cluster AS cluster,
-   flaked_test_results.test_names AS flaked_test_names,
-   flaked_test_results.test_count AS test_flakes,
-   failed_test_results.test_names AS failed_test_names,
-   failed_test_results.test_count AS test_failures,
+   prow_job_runs.cloud_region,
+   prow_jobs.cluster_profile,
+   prow_job_runs.flaked_test_names,
+   prow_job_runs.test_flakes,
+   prow_job_runs.failed_test_names,
+   prow_job_runs.test_failures,
    pull_requests.link as pull_request_link,
    pull_requests.sha as pull_request_sha,
    pull_requests.org as pull_request_org,
    pull_requests.repo as pull_request_repo,
    pull_requests.author as pull_request_author
 FROM prow_job_runs
-   LEFT JOIN failed_test_results ON failed_test_results.prow_job_run_id = prow_job_runs.id
-   LEFT JOIN flaked_test_results ON flaked_test_results.prow_job_run_id = prow_job_runs.id
    LEFT JOIN pull_requests ON pull_requests.id = prow_job_runs.id
    JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id
 `
 
+// testReportStartCondition limits the test report views to the runs they count.
+const testReportStartCondition = "WHERE prow_job_runs.timestamp >= |||START|||"
+
 const testReportMatView = `
 WITH open_bugs AS (
   SELECT
@@ -210,7 +261,11 @@ WITH open_bugs AS (
 SELECT tests.id,
    tests.name,
    tests.watchlist, 
+   tests.sig,
+   tests.feature_gates,
+   tests.capabilities,
    suites.name as suite_name,
+   prow_job_run_tests.suite_id,
    jira_components.name AS jira_component,
    jira_components.id AS jira_component_id,   
    COALESCE(count(
@@ -223,6 +278,11 @@ SELECT tests.id,
            WHEN prow_job_run_tests.status = 13 AND prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY||| THEN 1
            ELSE NULL::integer
        END), 0::bigint) AS previous_flakes,
+   COALESCE(count(
+       CASE
+           WHEN prow_job_run_tests.retry_flake AND prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY||| THEN 1
+           ELSE NULL::integer
+       END), 0::bigint) AS previous_retry_flakes,
    COALESCE(count(
        CASE
            WHEN prow_job_run_tests.status = 12 AND prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY||| THEN 1
@@ -243,6 +303,11 @@ SELECT tests.id,
            WHEN prow_job_run_tests.status = 13 AND prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END||| THEN 1
            ELSE NULL::integer
        END), 0::bigint) AS current_flakes,
+   COALESCE(count(
+       CASE
+           WHEN prow_job_run_tests.retry_flake AND prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END||| THEN 1
+           ELSE NULL::integer
+       END), 0::bigint) AS current_retry_flakes,
    COALESCE(count(
        CASE
            WHEN prow_job_run_tests.status = 12 AND prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END||| THEN 1
@@ -265,8 +330,8 @@ FROM prow_job_run_tests
    LEFT JOIN jira_components ON test_ownerships.jira_component = jira_components.name
    JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
    JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id
-WHERE prow_job_runs.timestamp >= |||START|||
-GROUP BY tests.id, tests.name, jira_components.name, jira_components.id, suites.name, open_bugs.open_bugs, prow_jobs.variants, prow_jobs.release
+` + testReportStartCondition + `
+GROUP BY tests.id, tests.name, tests.sig, tests.feature_gates, tests.capabilities, jira_components.name, jira_components.id, prow_job_run_tests.suite_id, suites.name, open_bugs.open_bugs, prow_jobs.variants, prow_jobs.release
 `
 
 const testAnalysisByVariantMatView = `
@@ -385,3 +450,22 @@ WHERE
     AND pj.id = pjr.prow_job_id
 ORDER BY pjrt.id DESC
 `
+
+// testDurationsMatView summarizes the durations of the passing runs of each test in each release over the last two
+// weeks. Failed runs are left out, as they often end early or time out.
+const testDurationsMatView = `
+SELECT prow_jobs.release,
+    tests.id AS test_id,
+    tests.name AS test_name,
+    COUNT(*) AS runs,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY prow_job_run_tests.duration) AS p50,
+    percentile_cont(0.95) WITHIN GROUP (ORDER BY prow_job_run_tests.duration) AS p95
+FROM prow_job_run_tests
+    JOIN tests ON tests.id = prow_job_run_tests.test_id
+    JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+    JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE prow_job_runs.timestamp >= |||TIMENOW||| - INTERVAL '14 DAY'
+    AND prow_job_run_tests.status = 1
+    AND prow_job_run_tests.duration > 0
+GROUP BY prow_jobs.release, tests.id, tests.name
+`
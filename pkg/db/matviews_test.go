@@ -0,0 +1,37 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatViewDependenciesAreListedFirst(t *testing.T) {
+	listed := map[string]bool{}
+	for _, pmv := range PostgresMatViews {
+		assert.NotEmpty(t, pmv.Tables, "%s has no tables", pmv.Name)
+		for _, dependency := range pmv.DependsOn {
+			assert.True(t, listed[dependency], "%s depends on %s, which isn't listed before it", pmv.Name, dependency)
+		}
+		listed[pmv.Name] = true
+	}
+}
+
+func TestMatViewsForTables(t *testing.T) {
+	assert.Equal(t, []string{"payload_test_failures_14d_matview"}, MatViewsForTables([]string{"release_tags"}))
+	assert.Equal(t, []string{"prow_test_report_7d_matview", "prow_test_report_2d_matview"},
+		MatViewsForTables([]string{"bugs", "test_ownerships"}))
+	assert.Len(t, MatViewsForTables([]string{"prow_job_runs"}), len(PostgresMatViews))
+	assert.Empty(t, MatViewsForTables(nil))
+}
+
+func TestTestReportQuery(t *testing.T) {
+	start := time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC)
+	query := TestReportQuery(start, start.AddDate(0, 0, 7), start.AddDate(0, 0, 14))
+	assert.NotContains(t, query, "|||")
+	assert.Contains(t, query, "prow_job_runs.timestamp >= TO_TIMESTAMP('2024-03-07 00:00:00', 'YYYY-MM-DD HH24:MI:SS') "+
+		"AND prow_job_runs.timestamp <= TO_TIMESTAMP('2024-03-21 00:00:00', 'YYYY-MM-DD HH24:MI:SS')")
+	assert.Contains(t, query, "BETWEEN TO_TIMESTAMP('2024-03-14 00:00:00', 'YYYY-MM-DD HH24:MI:SS') AND "+
+		"TO_TIMESTAMP('2024-03-21 00:00:00', 'YYYY-MM-DD HH24:MI:SS')")
+}
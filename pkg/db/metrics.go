@@ -0,0 +1,47 @@
+package db
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "sippy:query_start"
+
+var queryDurationMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "sippy_db_query_millis",
+	Help:    "Milliseconds to execute postgresql queries, by operation and table",
+	Buckets: []float64{1, 5, 10, 50, 100, 500, 1000, 5000, 10000, 30000, 60000},
+}, []string{"operation", "table"})
+
+// registerMetricsCallbacks hooks into each of gorm's callback chains to record how long every statement takes.
+func registerMetricsCallbacks(db *gorm.DB) error {
+	before := func(string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			tx.InstanceSet(queryStartKey, time.Now())
+		}
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			start, ok := tx.InstanceGet(queryStartKey)
+			if !ok {
+				return
+			}
+			table := tx.Statement.Table
+			if table == "" {
+				table = "raw"
+			}
+			queryDurationMetric.WithLabelValues(operation, table).
+				Observe(float64(time.Since(start.(time.Time)).Milliseconds()))
+		}
+	}
+
+	if err := registerAroundCallbacks(db, "metrics", before, after); err != nil {
+		return err
+	}
+	log.Debug("registered gorm metrics callbacks")
+	return nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ArchivedProwJobRun records where a job run, archived to object storage and deleted from the database, can be read
+// back from.
+type ArchivedProwJobRun struct {
+	// ID is the ID the run had, its prow build ID.
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	ProwJobID  uint      `json:"prow_job_id" gorm:"index"`
+	Timestamp  time.Time `json:"timestamp" gorm:"index"`
+	ArchivedAt time.Time `json:"archived_at"`
+
+	// RunsObject and TestsObject are the names of the parquet files holding the run, and its test results, relative
+	// to the archive's location.
+	RunsObject  string `json:"runs_object"`
+	TestsObject string `json:"tests_object"`
+}
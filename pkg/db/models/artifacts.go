@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// ArtifactBuildLog, ArtifactJUnit, ArtifactMustGather and ArtifactIntervals are the artifacts linked from a run.
+	ArtifactBuildLog   = "build-log"
+	ArtifactJUnit      = "junit"
+	ArtifactMustGather = "must-gather"
+	ArtifactIntervals  = "intervals"
+)
+
+// ArtifactLinks maps an artifact of a job run to a link to it in the run's bucket, i.e. build-log to the run's
+// build-log.txt. Artifacts that weren't found are left out. It is stored as a jsonb object.
+type ArtifactLinks map[string]string
+
+// Value implements driver.Valuer.
+func (a ArtifactLinks) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements sql.Scanner.
+func (a *ArtifactLinks) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("cannot scan %T into ArtifactLinks", src)
+	}
+
+	links := ArtifactLinks{}
+	if err := json.Unmarshal(data, &links); err != nil {
+		return err
+	}
+	*a = links
+	return nil
+}
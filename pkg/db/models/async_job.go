@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	AsyncJobPending   = "pending"
+	AsyncJobRunning   = "running"
+	AsyncJobSucceeded = "succeeded"
+	AsyncJobFailed    = "failed"
+)
+
+// AsyncJob is an API request run in the background, for reports that take longer to compute than clients can wait.
+// Jobs are kept in the database, so any replica can report a job's status and result.
+type AsyncJob struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	// Key identifies identical requests, which share a job rather than repeating the work.
+	Key    string `json:"-" gorm:"index"`
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Tenant string `json:"tenant,omitempty"`
+
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	ContentType string `json:"content_type,omitempty"`
+	Result      []byte `json:"-"`
+}
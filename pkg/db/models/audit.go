@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+// AuditLog records a mutating API request, so that changes made through sippy can be reviewed later.
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	// User is the authenticated user as reported by the proxy in front of sippy, or "anonymous".
+	User       string `json:"user" gorm:"index"`
+	Method     string `json:"method"`
+	Endpoint   string `json:"endpoint" gorm:"index"`
+	RequestID  string `json:"request_id"`
+	StatusCode int    `json:"status_code"`
+
+	// Before is the state of the resource prior to the change, when the handler provides it.
+	Before pgtype.JSONB `json:"before" gorm:"type:jsonb"`
+	// After is the state of the resource following the change, defaulting to the request payload.
+	After pgtype.JSONB `json:"after" gorm:"type:jsonb"`
+}
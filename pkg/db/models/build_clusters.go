@@ -9,13 +9,24 @@ type BuildClusterHealthReport struct {
 	CurrentRuns           int     `json:"current_runs"`
 	CurrentPasses         int     `json:"current_passes,omitempty"`
 	CurrentFails          int     `json:"current_fails,omitempty"`
+	// CurrentInfraFails are the failures due to CI infrastructure, or before the job's setup.
+	CurrentInfraFails          int     `json:"current_infra_fails,omitempty"`
+	CurrentInfraFailPercentage float64 `json:"current_infra_fail_percentage"`
 
-	PreviousPassPercentage float64 `json:"previous_pass_percentage"`
-	PreviousRuns           int     `json:"previous_runs"`
-	PreviousPasses         int     `json:"previous_passes,omitempty"`
-	PreviousFails          int     `json:"previous_fails,omitempty"`
+	PreviousPassPercentage      float64 `json:"previous_pass_percentage"`
+	PreviousRuns                int     `json:"previous_runs"`
+	PreviousPasses              int     `json:"previous_passes,omitempty"`
+	PreviousFails               int     `json:"previous_fails,omitempty"`
+	PreviousInfraFails          int     `json:"previous_infra_fails,omitempty"`
+	PreviousInfraFailPercentage float64 `json:"previous_infra_fail_percentage"`
 
 	NetImprovement float64 `json:"net_improvement"`
+
+	// FailPValue and InfraFailPValue compare the cluster's current failures, and infrastructure failures, to those of
+	// every other cluster. Outlier is set when either is significantly worse, as a bad cluster skews job results.
+	FailPValue      float64 `json:"fail_p_value" gorm:"-"`
+	InfraFailPValue float64 `json:"infra_fail_p_value" gorm:"-"`
+	Outlier         bool    `json:"outlier" gorm:"-"`
 }
 
 type BuildClusterHealth struct {
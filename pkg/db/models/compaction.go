@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TestDailyResult counts a test's passing results in a job's runs on a day, once the per-run rows have been compacted
+// into it. Failed and flaky results are kept as rows. A day compacted more than once, because runs loaded late, has
+// more than one result, so readers should sum them.
+type TestDailyResult struct {
+	Model
+
+	Date      time.Time `json:"date" gorm:"type:date;index:idx_test_daily_results_key,priority:1"`
+	TestID    uint      `json:"test_id" gorm:"index:idx_test_daily_results_key,priority:2"`
+	SuiteID   *uint     `json:"suite_id"`
+	ProwJobID uint      `json:"prow_job_id" gorm:"index"`
+	// Release and Variants are copied from the job, so results can be aggregated without joining jobs.
+	Release  string         `json:"release" gorm:"index"`
+	Variants pq.StringArray `json:"variants" gorm:"type:text[]"`
+
+	Runs      int `json:"runs"`
+	Successes int `json:"successes"`
+	// Duration is the total duration of the runs, in seconds.
+	Duration float64 `json:"duration"`
+}
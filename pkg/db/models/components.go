@@ -1,6 +1,10 @@
 package models
 
-import "github.com/lib/pq"
+import (
+	"strings"
+
+	"github.com/lib/pq"
+)
 
 type JiraComponent struct {
 	Model
@@ -76,4 +80,39 @@ type TestOwnership struct {
 
 	// JiraComponent specifies the JIRA component that this test belongs to.
 	JiraComponentID *uint `gorm:"index"`
+
+	// JiraParentComponent and JiraSubComponent place JiraComponent in its project's hierarchy. Jira components can't
+	// be nested, so projects name sub-components after their parent, i.e. "Networking / ovn-kubernetes" is the
+	// ovn-kubernetes sub-component of Networking. Components without a sub-component only have a parent. They're set
+	// by the test mapping loader.
+	JiraParentComponent string `gorm:"index"`
+	JiraSubComponent    string
+}
+
+// SetComponentHierarchy splits the test's JIRA component into its parent component and sub-component, anything
+// after a second separator staying in the sub-component.
+func (t *TestOwnership) SetComponentHierarchy() {
+	parent, sub, _ := strings.Cut(t.JiraComponent, "/")
+	t.JiraParentComponent, t.JiraSubComponent = strings.TrimSpace(parent), strings.TrimSpace(sub)
+}
+
+// TestOwnershipOverride corrects the component the test mapping gives a test, or gives one to a test the mapping
+// doesn't know, as the mapping can lag weeks behind a reorganization. Overrides are kept apart from the loaded
+// ownerships, and applied to them again each time the mapping is loaded.
+type TestOwnershipOverride struct {
+	Model
+
+	TestName string `json:"test_name" gorm:"uniqueIndex:idx_test_ownership_override_name_suite"`
+	// Suite is the junit suite of the test, empty for tests without one.
+	Suite string `json:"suite" gorm:"uniqueIndex:idx_test_ownership_override_name_suite"`
+
+	// Component and JiraComponent replace those of the test's ownership. Component defaults to the JIRA component.
+	Component     string         `json:"component"`
+	JiraComponent string         `json:"jira_component"`
+	Capabilities  pq.StringArray `json:"capabilities" gorm:"type:text[]"`
+
+	// Reason explains the override, i.e. the reorganization the mapping hasn't caught up with.
+	Reason    string `json:"reason"`
+	CreatedBy string `json:"created_by"`
+	UpdatedBy string `json:"updated_by"`
 }
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestOwnershipSetComponentHierarchy(t *testing.T) {
+	tests := []struct {
+		jiraComponent string
+		parent        string
+		sub           string
+	}{
+		{jiraComponent: "Networking / ovn-kubernetes", parent: "Networking", sub: "ovn-kubernetes"},
+		{jiraComponent: "Storage / Kubernetes External Components / CSI", parent: "Storage", sub: "Kubernetes External Components / CSI"},
+		{jiraComponent: "Etcd", parent: "Etcd"},
+		{jiraComponent: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jiraComponent, func(t *testing.T) {
+			ownership := TestOwnership{JiraComponent: tt.jiraComponent}
+			ownership.SetComponentHierarchy()
+			assert.Equal(t, tt.parent, ownership.JiraParentComponent)
+			assert.Equal(t, tt.sub, ownership.JiraSubComponent)
+		})
+	}
+}
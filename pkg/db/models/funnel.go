@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	FunnelInstall = "install"
+	FunnelUpgrade = "upgrade"
+)
+
+// FunnelDay counts the install or upgrade attempts of a release's jobs in a variant on a day, computed from the
+// synthetic install and upgrade tests. The variant "All" counts every job.
+type FunnelDay struct {
+	Model
+
+	Kind    string    `json:"kind" gorm:"uniqueIndex:idx_funnel_days_key"`
+	Release string    `json:"release" gorm:"uniqueIndex:idx_funnel_days_key"`
+	Variant string    `json:"variant" gorm:"uniqueIndex:idx_funnel_days_key"`
+	Date    time.Time `json:"date" gorm:"type:date;uniqueIndex:idx_funnel_days_key"`
+
+	Attempts  int `json:"attempts"`
+	Successes int `json:"successes"`
+
+	// FailureReasons counts failed attempts by the first stage test that failed.
+	FailureReasons FailureReasons `json:"failure_reasons" gorm:"type:jsonb"`
+}
+
+// FailureReasons maps a failure reason to how many attempts failed for it.
+type FailureReasons map[string]int
+
+// Value implements driver.Valuer.
+func (f FailureReasons) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan implements sql.Scanner.
+func (f *FailureReasons) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("cannot scan %T into FailureReasons", src)
+	}
+
+	reasons := FailureReasons{}
+	if err := json.Unmarshal(data, &reasons); err != nil {
+		return err
+	}
+	*f = reasons
+	return nil
+}
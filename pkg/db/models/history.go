@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// TestWeeklyResult is a test's results in a release's jobs of a variant during a week, kept after the job runs are
+// pruned so trends can be graphed over the long term. The variant "All" counts every job.
+type TestWeeklyResult struct {
+	Model
+
+	Release string `json:"release" gorm:"uniqueIndex:idx_test_weekly_results_key"`
+	TestID  uint   `json:"test_id" gorm:"uniqueIndex:idx_test_weekly_results_key;index"`
+	Variant string `json:"variant" gorm:"uniqueIndex:idx_test_weekly_results_key"`
+	// Week is the Monday, in UTC, the week starts on.
+	Week time.Time `json:"week" gorm:"type:date;uniqueIndex:idx_test_weekly_results_key"`
+
+	Runs      int `json:"runs"`
+	Successes int `json:"successes"`
+	Flakes    int `json:"flakes"`
+	Failures  int `json:"failures"`
+}
+
+// JobWeeklyResult is a job's run results during a week, kept after the job runs are pruned so trends can be graphed
+// over the long term.
+type JobWeeklyResult struct {
+	Model
+
+	ProwJobID uint `json:"prow_job_id" gorm:"uniqueIndex:idx_job_weekly_results_key"`
+	// Week is the Monday, in UTC, the week starts on.
+	Week time.Time `json:"week" gorm:"type:date;uniqueIndex:idx_job_weekly_results_key"`
+
+	Runs                   int `json:"runs"`
+	Successes              int `json:"successes"`
+	Failures               int `json:"failures"`
+	InfrastructureFailures int `json:"infrastructure_failures"`
+}
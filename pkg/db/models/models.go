@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgtype"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -74,3 +75,121 @@ type JiraIncident struct {
 	// ResolutionTime is the time the issue was resolved
 	ResolutionTime *time.Time `json:"resolution_time" gorm:"index"`
 }
+
+// SustainedRegression tracks a test that is regressed in a release, so an issue can be filed once it has stayed
+// regressed for long enough.
+type SustainedRegression struct {
+	Model
+
+	Release  string `json:"release" gorm:"uniqueIndex:idx_sustained_regressions_release_test"`
+	TestName string `json:"test_name" gorm:"uniqueIndex:idx_sustained_regressions_release_test"`
+
+	// FirstRegressed is when the test was first seen regressed, the row is deleted once the test recovers.
+	FirstRegressed time.Time `json:"first_regressed"`
+
+	// IssueKey is the jira issue tracking the regression, i.e. OCPBUGS-1234. AutoFiled is set when sippy filed it,
+	// rather than finding an open issue already linked to the test, and only those issues are updated.
+	IssueKey  string `json:"issue_key"`
+	AutoFiled bool   `json:"auto_filed"`
+
+	// IssueUpdated is when the issue was filed or last commented on.
+	IssueUpdated time.Time `json:"issue_updated"`
+}
+
+const (
+	TestRegressionOpen   = "open"
+	TestRegressionClosed = "closed"
+)
+
+// TestRegression is a statistically significant drop in a test's pass rate in a release. It stays open while the test
+// is regressed, and is closed once it isn't; a later regression of the same test opens a new row.
+type TestRegression struct {
+	Model
+
+	Release  string `json:"release" gorm:"index:idx_test_regressions_release_status"`
+	TestID   uint   `json:"test_id" gorm:"index"`
+	TestName string `json:"test_name"`
+	// Variants are those of the jobs the test regressed in, when it regressed from a baseline declared for them, and
+	// empty when it regressed in all the release's jobs.
+	Variants pq.StringArray `json:"variants" gorm:"type:text[]"`
+	Status   string         `json:"status" gorm:"index:idx_test_regressions_release_status"`
+
+	// FirstSeen and LastSeen are the first and last refreshes the test was regressed in.
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+	ClosedAt  *time.Time `json:"closed_at"`
+
+	// The pass percentages and runs of the previous (basis) and current (sample) week, and the p-value of their
+	// comparison, as of LastSeen. When Baseline is set the test was compared to the pass percentage declared for it
+	// instead of the previous week, which is the basis pass percentage, with no basis runs.
+	Baseline             bool    `json:"baseline"`
+	BasisPassPercentage  float64 `json:"basis_pass_percentage"`
+	BasisRuns            int     `json:"basis_runs"`
+	SamplePassPercentage float64 `json:"sample_pass_percentage"`
+	SampleRuns           int     `json:"sample_runs"`
+	PValue               float64 `json:"p_value"`
+
+	// Tier is the most important tier of the jobs the test failed in, as of LastSeen, and Severity is the test's pass
+	// percentage drop weighted by it, so regressions in blocking jobs rank above those in experimental jobs.
+	Tier     string  `json:"tier"`
+	Severity float64 `json:"severity"`
+}
+
+const (
+	SilentJobOpen   = "open"
+	SilentJobClosed = "closed"
+)
+
+// SilentJob is a job that ran regularly but stopped reporting runs. It stays open while the job is silent, and is
+// closed once it runs again; a later silence of the same job opens a new row.
+type SilentJob struct {
+	Model
+
+	Release   string `json:"release" gorm:"index:idx_silent_jobs_release_status"`
+	ProwJobID uint   `json:"prow_job_id" gorm:"index"`
+	JobName   string `json:"job_name"`
+	Status    string `json:"status" gorm:"index:idx_silent_jobs_release_status"`
+
+	// ExpectedRunsPerDay is how many runs a day the job averaged in the two weeks before it went silent.
+	ExpectedRunsPerDay float64 `json:"expected_runs_per_day"`
+	// LastRun is when the job's last run started.
+	LastRun time.Time `json:"last_run"`
+
+	// FirstSeen and LastSeen are the first and last refreshes the job was silent in.
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+	ClosedAt  *time.Time `json:"closed_at"`
+}
+
+const (
+	LoaderErrorInvalidURL = "invalid-url"
+	LoaderErrorGCSPath    = "gcs-path"
+	LoaderErrorGCS        = "gcs"
+	LoaderErrorJUnit      = "junit"
+	LoaderErrorDatabase   = "database"
+	LoaderErrorCancelled  = "cancelled"
+	LoaderErrorOther      = "other"
+)
+
+// LoaderError is a job run a loader failed to import, kept so jobs that consistently fail to import can be found
+// without searching the logs.
+type LoaderError struct {
+	Model
+
+	Loader       string `json:"loader" gorm:"index"`
+	Release      string `json:"release"`
+	JobName      string `json:"job_name" gorm:"index"`
+	ProwJobRunID uint   `json:"prow_job_run_id"`
+	// Path is the run's path in the bucket, or its URL when the path couldn't be worked out.
+	Path string `json:"path"`
+	// Class is the kind of failure, i.e. LoaderErrorJUnit when the run's junit files couldn't be read.
+	Class   string `json:"class" gorm:"index"`
+	Message string `json:"message"`
+}
+
+// LoaderSuccess is when a loader last completed without errors, kept so the server can report how fresh each
+// loader's data is.
+type LoaderSuccess struct {
+	Loader      string    `json:"loader" gorm:"primaryKey"`
+	SucceededAt time.Time `json:"succeeded_at"`
+}
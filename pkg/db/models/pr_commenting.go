@@ -21,4 +21,31 @@ type PullRequestComment struct {
 	ProwJobRoot           string    `json:"prowJobRoot"`
 	LastCommentAttempt    time.Time `json:"lastCommentAttempt"`
 	FailedCommentAttempts int       `json:"failedCommentAttempts"`
+
+	// CommentedAt is set once a comment has been posted for the SHA, so we only comment once per head SHA. The
+	// record is kept until the PR merges or a new SHA is pushed.
+	CommentedAt *time.Time `json:"commentedAt"`
+}
+
+// PostedComment records a comment sippy posted on a PR, so later comments of the same type can update or minimize it
+// rather than piling on new comments.
+type PostedComment struct {
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CommentID   int64  `json:"commentID" gorm:"primaryKey;autoIncrement:false"`
+	NodeID      string `json:"nodeID"`
+	Org         string `json:"org" gorm:"index:idx_posted_comments_pr"`
+	Repo        string `json:"repo" gorm:"index:idx_posted_comments_pr"`
+	PullNumber  int    `json:"pullNumber" gorm:"index:idx_posted_comments_pr"`
+	CommentType int    `json:"commentType" gorm:"index:idx_posted_comments_pr"`
+
+	// SHA is the sha the comment currently describes, comments are updated in place for later shas with the same
+	// verdict.
+	SHA string `json:"sha"`
+
+	// Verdict summarizes the comment, i.e. the overall risk level of a risk analysis comment.
+	Verdict string `json:"verdict"`
+
+	// Minimized is set once the comment has been hidden as outdated on GitHub.
+	Minimized bool `json:"minimized"`
 }
@@ -1,9 +1,13 @@
 package models
 
 import (
+	"regexp"
+	"sort"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jackc/pgtype"
+	"github.com/klauspost/compress/zstd"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 
@@ -15,17 +19,42 @@ type ProwKind string
 const ProwPeriodic ProwKind = "periodic"
 const ProwPresubmit ProwKind = "presubmit"
 
+// Job tiers say how much a job matters to a release. Blocking jobs must pass for its payloads to be accepted,
+// informing jobs run on its payloads without gating them, and experimental jobs are left out of default reports. Jobs
+// without a tier are none of these.
+const (
+	JobTierBlocking     = "blocking"
+	JobTierInforming    = "informing"
+	JobTierExperimental = "experimental"
+)
+
 // ProwJob represents a prow job with various fields inferred from it's name. (release, variants, etc)
 type ProwJob struct {
 	gorm.Model
 
-	Kind        ProwKind
-	Name        string         `gorm:"unique"`
-	Release     string         `gorm:"varchar(10)"`
-	Variants    pq.StringArray `gorm:"index;type:text[]"`
-	TestGridURL string
-	Bugs        []Bug        `gorm:"many2many:bug_jobs;"`
-	JobRuns     []ProwJobRun `gorm:"constraint:OnDelete:CASCADE;"`
+	Kind     ProwKind
+	Name     string         `gorm:"unique"`
+	Release  string         `gorm:"varchar(10)"`
+	Variants pq.StringArray `gorm:"index;type:text[]"`
+	// Tenant is the product the job belongs to, empty for jobs without a tenant.
+	Tenant string `gorm:"index"`
+	// Tier is the job's JobTier, from the release's configured jobs or the payload jobs the release controller ran,
+	// empty when it's neither.
+	Tier string `gorm:"index"`
+	// VariantDimensions holds the job's variants keyed by dimension, i.e. Platform=aws.
+	VariantDimensions VariantDimensions `gorm:"index:idx_prow_jobs_variant_dimensions,type:gin;type:jsonb"`
+	// NeverStable is set when the job's pass rate has stayed low for long enough that it is excluded from
+	// normal variants, see the never-stable loader.
+	NeverStable bool
+	// Timeout is how long prow lets the job run, zero when it wasn't in the job spec, i.e. for jobs loaded from
+	// bigquery.
+	Timeout time.Duration
+	// ClusterProfile is the cluster profile the job leases its cloud account from, i.e. aws-2, empty when it doesn't
+	// lease one or was loaded from bigquery.
+	ClusterProfile string `gorm:"index"`
+	TestGridURL    string
+	Bugs           []Bug        `gorm:"many2many:bug_jobs;"`
+	JobRuns        []ProwJobRun `gorm:"constraint:OnDelete:CASCADE;"`
 }
 
 // IDName is a partial struct to query limited fields we need for caching. Can be used
@@ -45,31 +74,113 @@ type ProwJobRun struct {
 
 	// Cluster is the cluster where the prow job was run.
 	Cluster string
+	// CloudRegion is the region of the cluster the job installed, from its cluster data, empty when the job didn't
+	// report one.
+	CloudRegion string `gorm:"index"`
+	// ConfigHash hashes the configuration of the job in the run's prow job spec, so runs after the job's configuration
+	// changed can be told apart, empty when the spec couldn't be read.
+	ConfigHash string
 
-	URL          string
+	URL string
+	// Artifacts links to the run's build log, junit directory, must-gather and intervals in its bucket, as found when
+	// the run was loaded. It is nil for runs loaded before artifacts were linked.
+	Artifacts ArtifactLinks `gorm:"type:jsonb"`
+	// JUnitPaths and ArtifactPaths are the run's junit files and synthetic test artifacts in its bucket, as found when
+	// the run was loaded, so it can be reprocessed without listing the bucket again. They are nil for runs loaded
+	// before the paths were kept.
+	JUnitPaths    pq.StringArray `gorm:"type:text[]"`
+	ArtifactPaths pq.StringArray `gorm:"type:text[]"`
+	// JUnits are the run's junit files, only stored when the run was loaded with them kept.
+	JUnits       []ProwJobRunJUnit `gorm:"constraint:OnDelete:CASCADE;"`
 	TestFailures int
-	Tests        []ProwJobRunTest  `gorm:"constraint:OnDelete:CASCADE;"`
-	PullRequests []ProwPullRequest `gorm:"many2many:prow_job_run_prow_pull_requests;constraint:OnDelete:CASCADE;"`
+	Tests        []ProwJobRunTest `gorm:"constraint:OnDelete:CASCADE;"`
+	// SkippedTests are the tests the run skipped, kept apart from Tests so skips aren't counted as runs of the tests.
+	SkippedTests []ProwJobRunSkippedTest `gorm:"constraint:OnDelete:CASCADE;"`
+	PullRequests []ProwPullRequest       `gorm:"many2many:prow_job_run_prow_pull_requests;constraint:OnDelete:CASCADE;"`
 	Failed       bool
 	// InfrastructureFailure is true if the job run failed, for reasons which appear to be related to test/CI infra.
 	InfrastructureFailure bool
+	// FailureClassification labels why the run failed, product-failure, infra-failure or install-failure, empty for
+	// runs that didn't fail or were loaded before runs were classified.
+	FailureClassification string `gorm:"index"`
 	// KnownFailure is true if the job run failed, but we found a bug that is likely related already filed.
 	KnownFailure  bool
 	Succeeded     bool
 	Timestamp     time.Time `gorm:"index;index:idx_prow_job_runs_timestamp_date,expression:DATE(timestamp AT TIME ZONE 'UTC')"`
 	Duration      time.Duration
 	OverallResult v1.JobOverallResult `gorm:"index"`
+	// TestFlakes, FailedTestNames and FlakedTestNames summarize the run's tests along with TestFailures, so listing
+	// runs doesn't aggregate their test results. The names are nil for runs loaded before they were added, until the
+	// schema migration fills them in.
+	TestFlakes      int
+	FailedTestNames pq.StringArray `gorm:"type:text[]"`
+	FlakedTestNames pq.StringArray `gorm:"type:text[]"`
 	// used to pass the TestCount in via the api, we have the actual tests in the db and can calculate it here so don't persist
 	TestCount   int         `gorm:"-"`
 	ClusterData ClusterData `gorm:"-"`
 }
 
+// SetTestSummary sets the counts and sorted names of the run's failed and flaked tests, from its test results with
+// their TestName set.
+func (r *ProwJobRun) SetTestSummary(tests []*ProwJobRunTest) {
+	r.TestFailures, r.TestFlakes = 0, 0
+	r.FailedTestNames, r.FlakedTestNames = pq.StringArray{}, pq.StringArray{}
+	for _, test := range tests {
+		switch v1.TestStatus(test.Status) {
+		case v1.TestStatusFailure:
+			r.TestFailures++
+			r.FailedTestNames = append(r.FailedTestNames, test.TestName)
+		case v1.TestStatusFlake:
+			r.TestFlakes++
+			r.FlakedTestNames = append(r.FlakedTestNames, test.TestName)
+		}
+	}
+	sort.Strings(r.FailedTestNames)
+	sort.Strings(r.FlakedTestNames)
+}
+
 type Test struct {
 	gorm.Model
 	Name string `gorm:"uniqueIndex"`
 	Bugs []Bug  `gorm:"many2many:bug_tests;"`
 	// Watchlist are tests TRT is interested in keeping an eye on.
 	Watchlist bool
+	// Sig, FeatureGates and Capabilities are tagged in the test's name, i.e. [sig-network], [FeatureGate:X] or
+	// [OCPFeatureGate:X], and [Feature:X]. The arrays are nil for tests loaded before tags were parsed, until the
+	// schema migration fills them in.
+	Sig          string         `gorm:"index"`
+	FeatureGates pq.StringArray `gorm:"index:idx_tests_feature_gates,type:gin;type:text[]"`
+	Capabilities pq.StringArray `gorm:"index:idx_tests_capabilities,type:gin;type:text[]"`
+}
+
+var (
+	testSigTag         = regexp.MustCompile(`\[(sig-[^\]]+)\]`)
+	testFeatureGateTag = regexp.MustCompile(`\[(?:OCP)?FeatureGate:([^\]]+)\]`)
+	testCapabilityTag  = regexp.MustCompile(`\[Feature:([^\]]+)\]`)
+)
+
+// SetTags sets the sig, feature gates and capabilities tagged in the test's name, the first sig if it has several.
+func (t *Test) SetTags() {
+	t.Sig = ""
+	if match := testSigTag.FindStringSubmatch(t.Name); match != nil {
+		t.Sig = match[1]
+	}
+	t.FeatureGates = testTags(testFeatureGateTag, t.Name)
+	t.Capabilities = testTags(testCapabilityTag, t.Name)
+}
+
+// testTags returns the sorted, distinct values of the tag in the name.
+func testTags(tag *regexp.Regexp, name string) pq.StringArray {
+	tags := pq.StringArray{}
+	seen := map[string]bool{}
+	for _, match := range tag.FindAllStringSubmatch(name, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			tags = append(tags, match[1])
+		}
+	}
+	sort.Strings(tags)
+	return tags
 }
 
 // ProwJobRunTest defines a join table linking tests to the job runs they execute in, along with the status for
@@ -88,9 +199,106 @@ type ProwJobRunTest struct {
 	CreatedAt time.Time
 	DeletedAt gorm.DeletedAt
 
+	// TestName is the test's name, set by the loader to summarize the run's results, it's stored with the Test.
+	TestName string `gorm:"-"`
+
+	// RetryFlake is set when the test failed and then passed on a retry within the run. Status is also a flake when
+	// the test passed and then failed, such as in two separate invocations, which isn't a retry.
+	RetryFlake bool
+
 	// ProwJobRunTestOutput collect the output of a failed test run. This is stored as a separate object in the DB, so
 	// we can keep the test result for a longer period of time than we keep the full failure output.
 	ProwJobRunTestOutput *ProwJobRunTestOutput `gorm:"constraint:OnDelete:CASCADE;"`
+
+	// ProwJobRunTestLog is the full failure output and system-out of a failed test, only kept when the loader is
+	// configured to, and for less time than the result.
+	ProwJobRunTestLog *ProwJobRunTestLog `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// ProwJobRunSkippedTest is a test a job run skipped, with the reason it gave. Tests skipped in one suite but run in
+// another aren't recorded.
+type ProwJobRunSkippedTest struct {
+	gorm.Model
+	ProwJobRunID uint `gorm:"index"`
+	TestID       uint `gorm:"index"`
+	Test         Test
+	SuiteID      *uint
+	// Message is the skip reason from the junit, truncated like failure outputs.
+	Message string
+}
+
+// MaxTestLogLength caps each of the outputs kept in a ProwJobRunTestLog, before they're compressed.
+const MaxTestLogLength = 1024 * 1024
+
+var (
+	testLogEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	testLogDecoder, _ = zstd.NewReader(nil)
+)
+
+// ProwJobRunTestLog is the failure output and system-out of a failed test, zstd-compressed, so users can read why a
+// test failed without opening the run's artifacts. It's pruned separately from the test result, which outlives it.
+type ProwJobRunTestLog struct {
+	ID               uint      `gorm:"primaryKey"`
+	CreatedAt        time.Time `gorm:"index"`
+	ProwJobRunTestID uint      `gorm:"uniqueIndex"`
+	FailureOutput    []byte
+	SystemOut        []byte
+	// Truncated is set when either output was cut to MaxTestLogLength.
+	Truncated bool
+}
+
+// NewProwJobRunTestLog returns the log of a failed test's outputs, compressed and capped at MaxTestLogLength each.
+func NewProwJobRunTestLog(failureOutput, systemOut string) *ProwJobRunTestLog {
+	testLog := &ProwJobRunTestLog{Truncated: len(failureOutput) > MaxTestLogLength || len(systemOut) > MaxTestLogLength}
+	testLog.FailureOutput = compressTestLog(failureOutput)
+	testLog.SystemOut = compressTestLog(systemOut)
+	return testLog
+}
+
+// Outputs returns the log's failure output and system-out, decompressed.
+func (l *ProwJobRunTestLog) Outputs() (string, string, error) {
+	failureOutput, err := decompressTestLog(l.FailureOutput)
+	if err != nil {
+		return "", "", err
+	}
+	systemOut, err := decompressTestLog(l.SystemOut)
+	if err != nil {
+		return "", "", err
+	}
+	return failureOutput, systemOut, nil
+}
+
+func compressTestLog(output string) []byte {
+	if output == "" {
+		return nil
+	}
+	if len(output) > MaxTestLogLength {
+		cut := MaxTestLogLength
+		for cut > 0 && !utf8.RuneStart(output[cut]) {
+			cut--
+		}
+		output = output[:cut]
+	}
+	return testLogEncoder.EncodeAll([]byte(output), nil)
+}
+
+func decompressTestLog(compressed []byte) (string, error) {
+	if len(compressed) == 0 {
+		return "", nil
+	}
+	output, err := testLogDecoder.DecodeAll(compressed, nil)
+	return string(output), err
+}
+
+// ProwJobRunJUnit is one of a run's junit files, gzipped, kept so the run's tests can be parsed again after the
+// parsing changes without reading the file from the bucket.
+type ProwJobRunJUnit struct {
+	gorm.Model
+	ProwJobRunID uint `gorm:"index"`
+	// Path is the file's path in the run's bucket.
+	Path string
+	// Content is the gzipped file.
+	Content []byte
 }
 
 type ProwJobRunTestOutput struct {
@@ -150,6 +358,27 @@ type Bug struct {
 	URL             string         `json:"url"`
 	Tests           []Test         `json:"-" gorm:"many2many:bug_tests;constraint:OnDelete:CASCADE;"`
 	Jobs            []ProwJob      `json:"-" gorm:"many2many:bug_jobs;constraint:OnDelete:CASCADE;"`
+
+	// FiledAt and ResolvedAt are when the bug was created and resolved in Jira, ResolvedAt nil while it's unresolved.
+	FiledAt    time.Time  `json:"filed_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	// TestCorrelations score the bug's links to its tests.
+	TestCorrelations []BugTestCorrelation `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+	// Confidence is how likely the bug is to be the cause of a test's failures, set when bugs are loaded for a test.
+	Confidence float64 `json:"confidence,omitempty" gorm:"-"`
+}
+
+// BugTestCorrelation scores how likely a bug linked to a test is to be the cause of the test's failures. Bugs are
+// linked to tests by searching for the test's name, so the score adds how well the bug's summary matches the test's
+// recent failure messages, and how many of its recent failures happened while the bug was open. Scores are from 0 to
+// 1.
+type BugTestCorrelation struct {
+	BugID        uint      `json:"bug_id" gorm:"primaryKey"`
+	TestID       uint      `json:"test_id" gorm:"primaryKey;index"`
+	MessageScore float64   `json:"message_score"`
+	TimingScore  float64   `json:"timing_score"`
+	Confidence   float64   `json:"confidence"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // ProwPullRequest represents a GitHub pull request, there can be multiple entries
@@ -188,4 +417,6 @@ type ClusterData struct {
 	CloudRegion           string
 	CloudZone             string
 	ClusterVersionHistory []string
+	// FIPS is whether the cluster was installed in FIPS mode, nil when the job didn't report it.
+	FIPS *bool
 }
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestSetTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		sig          string
+		featureGates []string
+		capabilities []string
+	}{
+		{
+			name:         "[sig-network][OCPFeatureGate:GatewayAPI][Feature:Router] Gateway API should route traffic [Suite:openshift/conformance/parallel]",
+			sig:          "sig-network",
+			featureGates: []string{"GatewayAPI"},
+			capabilities: []string{"Router"},
+		},
+		{
+			name:         "[sig-storage] [FeatureGate:VolumeGroupSnapshot] [FeatureGate:CSIVolumeHealth] [FeatureGate:VolumeGroupSnapshot] snapshots",
+			sig:          "sig-storage",
+			featureGates: []string{"CSIVolumeHealth", "VolumeGroupSnapshot"},
+			capabilities: []string{},
+		},
+		{
+			name:         "install should succeed: overall",
+			featureGates: []string{},
+			capabilities: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := Test{Name: tt.name}
+			test.SetTags()
+			assert.Equal(t, tt.sig, test.Sig)
+			assert.Equal(t, tt.featureGates, []string(test.FeatureGates))
+			assert.Equal(t, tt.capabilities, []string(test.Capabilities))
+		})
+	}
+}
+
+func TestProwJobRunTestLog(t *testing.T) {
+	testLog := NewProwJobRunTestLog("fail: expected 1, got 2", "")
+	assert.False(t, testLog.Truncated)
+	assert.Nil(t, testLog.SystemOut)
+	failureOutput, systemOut, err := testLog.Outputs()
+	assert.NoError(t, err)
+	assert.Equal(t, "fail: expected 1, got 2", failureOutput)
+	assert.Empty(t, systemOut)
+
+	// the three byte ✓ straddling the cap is dropped rather than split
+	long := strings.Repeat("a", MaxTestLogLength-1) + "✓" + strings.Repeat("b", 1000)
+	testLog = NewProwJobRunTestLog("failed", long)
+	assert.True(t, testLog.Truncated)
+	assert.Less(t, len(testLog.SystemOut), 1024)
+	_, systemOut, err = testLog.Outputs()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a", MaxTestLogLength-1), systemOut)
+}
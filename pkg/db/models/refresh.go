@@ -0,0 +1,82 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	RefreshRunning   = "running"
+	RefreshSucceeded = "succeeded"
+	RefreshFailed    = "failed"
+	RefreshSkipped   = "skipped"
+
+	// RefreshMatView and RefreshComputation are the kinds of a refresh's steps: refreshing a materialized view, and
+	// computing data from the views or tables, i.e. detecting regressions.
+	RefreshMatView     = "matview"
+	RefreshComputation = "computation"
+)
+
+// RefreshRun records a refresh of the materialized views and of the data computed from them, as it progresses, so
+// the refreshes of every sippy process can be followed from the API.
+type RefreshRun struct {
+	Model
+
+	// Trigger is what started the refresh, i.e. load, serve or api.
+	Trigger    string     `json:"trigger"`
+	Status     string     `json:"status" gorm:"index"`
+	StartedAt  time.Time  `json:"started_at" gorm:"index"`
+	FinishedAt *time.Time `json:"finished_at"`
+
+	Steps RefreshSteps `json:"steps" gorm:"type:jsonb"`
+	// Errors counts the steps that failed.
+	Errors int `json:"errors"`
+	// OpenRegressions is how many test regressions were open once they were recomputed, nil when they weren't.
+	OpenRegressions *int `json:"open_regressions"`
+}
+
+// RefreshStep is a materialized view refreshed, or data computed, by a refresh.
+type RefreshStep struct {
+	Kind       string     `json:"kind"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	DurationMS int64      `json:"duration_ms"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// RefreshSteps are a refresh's steps, in the order they started.
+type RefreshSteps []RefreshStep
+
+// Value implements driver.Valuer.
+func (s RefreshSteps) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner.
+func (s *RefreshSteps) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RefreshSteps", src)
+	}
+
+	steps := RefreshSteps{}
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return err
+	}
+	*s = steps
+	return nil
+}
@@ -15,6 +15,9 @@ type ReleaseTag struct {
 	// Release contains the release X.Y version, e.g. 4.8
 	Release string `json:"release" gorm:"column:release"`
 
+	// Tenant contains the product the payload's jobs belong to, empty for payloads without a tenant.
+	Tenant string `json:"tenant,omitempty" gorm:"column:tenant;index"`
+
 	// Stream contains the payload stream, e.g. nightly or ci.
 	Stream string `json:"stream" gorm:"column:stream"`
 
@@ -110,6 +113,10 @@ type ReleaseRepository struct {
 
 	// DiffURL is a link to the git diff.
 	DiffURL string `json:"url" gorm:"column:diff_url"`
+
+	// FromCommit and ToCommit are the commits the repository's images were bumped from and to, as compared by DiffURL.
+	FromCommit string `json:"from_commit" gorm:"column:from_commit"`
+	ToCommit   string `json:"to_commit" gorm:"column:to_commit"`
 }
 
 type ReleaseJobRun struct {
@@ -154,3 +161,23 @@ type PayloadFailedTest struct {
 	ProwJobRunURL string
 	ProwJobName   string
 }
+
+// PayloadTestCounts are a test's results in the job runs of a payload.
+type PayloadTestCounts struct {
+	ID          uint
+	ReleaseTag  string
+	Phase       string
+	ReleaseTime time.Time
+	Passes      int
+	Failures    int
+}
+
+// PayloadPullRequest is a pull request included for the first time in a payload.
+type PayloadPullRequest struct {
+	ReleaseTag    string
+	URL           string
+	PullRequestID string
+	Name          string
+	Description   string
+	BugURL        string
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+// SavedView is a named report filter, a release, variant and filter expression, shared by its slug so teams can
+// link to their canonical views.
+type SavedView struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Slug identifies the view in URLs, it's unique across personal and global views.
+	Slug string `json:"slug" gorm:"uniqueIndex"`
+	Name string `json:"name"`
+
+	// Owner is the user who created the view. Personal views can only be changed by their owner, global views by any
+	// authenticated user.
+	Owner  string `json:"owner" gorm:"index"`
+	Global bool   `json:"global"`
+
+	Release string `json:"release"`
+	Variant string `json:"variant,omitempty"`
+	// Filter is the filter expression, in the same format as the filter parameter of the reports.
+	Filter pgtype.JSONB `json:"filter" gorm:"type:jsonb"`
+}
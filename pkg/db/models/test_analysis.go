@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TestAnalysisByJobDay summarizes a test's results in a job on a day of the last two weeks, with the job's variants
+// copied in so the test analysis endpoints can filter by variant without joining jobs. The table is rebuilt from the
+// materialized views each time they're refreshed.
+type TestAnalysisByJobDay struct {
+	Release  string         `gorm:"index:idx_test_analysis_by_job_days_lookup,priority:1"`
+	TestName string         `gorm:"index:idx_test_analysis_by_job_days_lookup,priority:2"`
+	Date     time.Time      `gorm:"type:date;index:idx_test_analysis_by_job_days_lookup,priority:3"`
+	TestID   uint           `gorm:"column:test_id"`
+	JobName  string         `gorm:"column:job_name"`
+	Variants pq.StringArray `gorm:"type:text[]"`
+
+	Runs     int
+	Passes   int
+	Flakes   int
+	Failures int
+}
+
+// TestAnalysisByVariantDay summarizes a test's results in a variant on a day of the last two weeks. The table is
+// rebuilt from the materialized views each time they're refreshed.
+type TestAnalysisByVariantDay struct {
+	Release  string    `gorm:"index:idx_test_analysis_by_variant_days_lookup,priority:1"`
+	TestName string    `gorm:"index:idx_test_analysis_by_variant_days_lookup,priority:2"`
+	Date     time.Time `gorm:"type:date;index:idx_test_analysis_by_variant_days_lookup,priority:3"`
+	TestID   uint      `gorm:"column:test_id"`
+	Variant  string
+
+	Runs     int
+	Passes   int
+	Flakes   int
+	Failures int
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TestQuarantine excludes a test's failures from judging its jobs' runs, while its results are still recorded and
+// reported, until the issue tracking its fix is resolved. Quarantines expire, so a test isn't forgotten in quarantine,
+// and an event is sent when one does.
+type TestQuarantine struct {
+	Model
+
+	TestName string `json:"test_name" gorm:"uniqueIndex"`
+	// JiraURL links the issue tracking the test's fix.
+	JiraURL string `json:"jira_url"`
+	Reason  string `json:"reason"`
+
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	// ExpiryNotifiedAt is when the quarantine's expiry was notified, nil until then.
+	ExpiryNotifiedAt *time.Time `json:"expiry_notified_at"`
+
+	CreatedBy string `json:"created_by"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+// Active returns whether the quarantine still applies at the time.
+func (q TestQuarantine) Active(at time.Time) bool {
+	return at.Before(q.ExpiresAt)
+}
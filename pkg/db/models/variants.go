@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// VariantDimensions maps a variant dimension to a job's value for it, i.e. Platform=aws, Network=ovn, Upgrade=minor.
+// It is stored as a jsonb object so individual dimensions can be queried.
+type VariantDimensions map[string]string
+
+// TopologyExternal is the Topology of jobs whose clusters run hosted control planes, i.e. hypershift, where the control
+// plane runs in a management cluster rather than on the cluster's own nodes.
+const TopologyExternal = "external"
+
+// HostedControlPlane returns whether the job's clusters run hosted control planes.
+func (v VariantDimensions) HostedControlPlane() bool {
+	return v["Topology"] == TopologyExternal
+}
+
+// Value implements driver.Valuer.
+func (v VariantDimensions) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// Scan implements sql.Scanner.
+func (v *VariantDimensions) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("cannot scan %T into VariantDimensions", src)
+	}
+
+	dimensions := VariantDimensions{}
+	if err := json.Unmarshal(data, &dimensions); err != nil {
+		return err
+	}
+	*v = dimensions
+	return nil
+}
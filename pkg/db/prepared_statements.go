@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultStatementCacheSize is the default number of prepared statements kept, enough for every report query the API
+// issues repeatedly.
+const DefaultStatementCacheSize = 512
+
+// statementCacheDSN sets how many statements pgx keeps prepared on each connection, so postgres plans the repetitive
+// report queries once per connection rather than on every request. pgx evicts the least recently used statements
+// beyond the size, and those whose plan went stale when a matview they read was recreated. With a size of 0,
+// statements are described rather than prepared, and planned every time they run.
+func statementCacheDSN(dsn string, size int) (string, error) {
+	if size < 0 {
+		return "", fmt.Errorf("statement cache size must not be negative")
+	}
+	params := map[string]string{"statement_cache_capacity": strconv.Itoa(size)}
+	if size == 0 {
+		params["statement_cache_mode"] = "describe"
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("invalid database dsn: %v", err)
+		}
+		query := u.Query()
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	// a keyword/value connection string, where later settings override earlier ones
+	for _, k := range []string{"statement_cache_capacity", "statement_cache_mode"} {
+		if v, ok := params[k]; ok {
+			dsn += fmt.Sprintf(" %s=%s", k, v)
+		}
+	}
+	return strings.TrimSpace(dsn), nil
+}
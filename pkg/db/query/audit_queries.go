@@ -0,0 +1,26 @@
+package query
+
+import (
+	"time"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// AuditLogs returns audit log entries created between start and end, newest first, optionally restricted to a
+// user and endpoint.
+func AuditLogs(dbc *db.DB, user, endpoint string, start, end time.Time, limit int) ([]models.AuditLog, error) {
+	results := make([]models.AuditLog, 0)
+	q := dbc.DB.Where("created_at BETWEEN ? AND ?", start, end)
+	if user != "" {
+		q = q.Where("\"user\" = ?", user)
+	}
+	if endpoint != "" {
+		q = q.Where("endpoint = ?", endpoint)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	res := q.Order("created_at DESC").Find(&results)
+	return results, res.Error
+}
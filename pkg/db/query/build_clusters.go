@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
 )
@@ -22,12 +23,15 @@ func BuildClusterHealth(dbc *db.DB, start, boundary, end time.Time) ([]models.Bu
 		ROW_NUMBER() OVER() AS id,
 		cluster,
 		coalesce(count(case when succeeded = true AND timestamp BETWEEN @start AND @boundary then 1 end), 0) as previous_passes,
-		coalesce(count(case when succeeded = false AND timestamp BETWEEN @start AND @boundary then 1 end), 0) as previous_failures,
+		coalesce(count(case when succeeded = false AND timestamp BETWEEN @start AND @boundary then 1 end), 0) as previous_fails,
+		coalesce(count(case when overall_result IN @infra AND timestamp BETWEEN @start AND @boundary then 1 end), 0) as previous_infra_fails,
 		coalesce(count(case when timestamp BETWEEN @start AND @boundary then 1 end), 0) as previous_runs,
 		coalesce(count(case when succeeded = true AND timestamp BETWEEN @boundary AND @end then 1 end), 0) as current_passes,
 		coalesce(count(case when succeeded = false AND timestamp BETWEEN @boundary AND @end then 1 end), 0) as current_fails,
+		coalesce(count(case when overall_result IN @infra AND timestamp BETWEEN @boundary AND @end then 1 end), 0) as current_infra_fails,
 		coalesce(count(case when timestamp BETWEEN @boundary AND @end then 1 end), 0) as current_runs
-`, sql.Named("start", start), sql.Named("boundary", boundary), sql.Named("end", end)).
+`, sql.Named("start", start), sql.Named("boundary", boundary), sql.Named("end", end),
+		sql.Named("infra", []v1.JobOverallResult{v1.JobInfrastructureFailure, v1.JobFailureBeforeSetup})).
 		Table("prow_job_runs").
 		Joins("JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id").
 		Where(`cluster != '' AND cluster IS NOT NULL`).
@@ -38,6 +42,8 @@ func BuildClusterHealth(dbc *db.DB, start, boundary, end time.Time) ([]models.Bu
 		Select(`*,
 		current_passes * 100.0 / NULLIF(current_runs, 0) AS current_pass_percentage,
        previous_passes * 100.0 / NULLIF(previous_runs, 0) AS previous_pass_percentage,
+       current_infra_fails * 100.0 / NULLIF(current_runs, 0) AS current_infra_fail_percentage,
+       previous_infra_fails * 100.0 / NULLIF(previous_runs, 0) AS previous_infra_fail_percentage,
        (current_passes * 100.0 / NULLIF(current_runs, 0)) - (previous_passes * 100.0 / NULLIF(previous_runs, 0)) AS net_improvement
 `).Scan(&results)
 
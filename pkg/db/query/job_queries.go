@@ -7,6 +7,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/filter"
@@ -75,14 +76,49 @@ func ProwJobHistoricalTestCounts(dbc *db.DB, prowJobID uint) (int, error) {
 	return int(historicalProwJobRunTestCount), nil
 }
 
-func JobReports(dbc *db.DB, filterOpts *filter.FilterOptions, release string, start, boundary, end time.Time) ([]apitype.Job, error) {
+// jobResultsWithoutInfraFailures is job_results with the runs that failed due to CI infrastructure left out of the
+// runs, failures and pass rates.
+const jobResultsWithoutInfraFailures = `
+SELECT pj_name, pj_variants, org, repo, average_retests_to_merge,
+	previous_passes,
+	previous_failures - previous_infra_fails AS previous_failures,
+	previous_runs - previous_infra_fails AS previous_runs,
+	previous_infra_fails,
+	current_passes,
+	current_fails - current_infra_fails AS current_fails,
+	current_runs - current_infra_fails AS current_runs,
+	current_infra_fails,
+	id, created_at, updated_at, deleted_at, name, release, variants, variant_dimensions, test_grid_url, kind, brief_name,
+	current_passes * 100.0 / NULLIF(current_runs - current_infra_fails, 0) AS current_pass_percentage,
+	current_passes * 100.0 / NULLIF(current_runs - current_infra_fails, 0) AS current_projected_pass_percentage,
+	(current_fails - current_infra_fails) * 100.0 / NULLIF(current_runs - current_infra_fails, 0) AS current_failure_percentage,
+	previous_passes * 100.0 / NULLIF(previous_runs - previous_infra_fails, 0) AS previous_pass_percentage,
+	previous_passes * 100.0 / NULLIF(previous_runs - previous_infra_fails, 0) AS previous_projected_pass_percentage,
+	(previous_failures - previous_infra_fails) * 100.0 / NULLIF(previous_runs - previous_infra_fails, 0) AS previous_failure_percentage,
+	(current_passes * 100.0 / NULLIF(current_runs - current_infra_fails, 0)) -
+		(previous_passes * 100.0 / NULLIF(previous_runs - previous_infra_fails, 0)) AS net_improvement,
+	open_bugs, last_pass, cluster_profile, tier
+FROM job_results(?, ?, ?, ?)`
+
+// JobReports returns the pass rates of the release's jobs in the windows either side of the boundary, only those of
+// the tenant when it's set. With excludeInfraFailures, runs that failed due to CI infrastructure aren't counted as
+// runs.
+func JobReports(dbc *db.DB, filterOpts *filter.FilterOptions, release, tenant string, start, boundary, end time.Time,
+	excludeInfraFailures bool) ([]apitype.Job, error) {
 	now := time.Now()
 	jobReports := make([]apitype.Job, 0)
 
 	table := dbc.DB.Table("job_results(?, ?, ?, ?)", release, start, boundary, end)
+	if excludeInfraFailures {
+		table = dbc.DB.Table("(?) AS job_results",
+			dbc.DB.Raw(jobResultsWithoutInfraFailures, release, start, boundary, end))
+	}
 	if table.Error != nil {
 		return jobReports, table.Error
 	}
+	if tenant != "" {
+		table = table.Where("id IN (?)", dbc.DB.Model(&models.ProwJob{}).Select("id").Where("tenant = ?", tenant))
+	}
 
 	q, err := filter.FilterableDBResult(table, filterOpts, apitype.Job{})
 	if err != nil {
@@ -96,7 +132,8 @@ func JobReports(dbc *db.DB, filterOpts *filter.FilterOptions, release string, st
 	return jobReports, nil
 }
 
-func VariantReports(dbc *db.DB, release string, start, boundary, end time.Time) ([]apitype.Variant, error) {
+// VariantReports returns pass rates for each variant of the release's jobs, leaving out any invalid variants.
+func VariantReports(dbc *db.DB, release string, start, boundary, end time.Time, invalidVariants []string) ([]apitype.Variant, error) {
 	variantResults := make([]apitype.Variant, 0)
 	q := dbc.DB.Raw(`
 WITH results AS (
@@ -127,8 +164,10 @@ SELECT variant as name,
     previous_fails * 100.0 / NULLIF(previous_runs, 0) AS previous_failure_percentage,
     (current_passes * 100.0 / NULLIF(current_runs, 0)) - (previous_passes * 100.0 / NULLIF(previous_runs, 0)) AS net_improvement
 FROM results
+WHERE variant NOT IN @invalid
 ORDER BY current_pass_percentage ASC;
-`, sql.Named("release", release), sql.Named("start", start), sql.Named("boundary", boundary), sql.Named("end", end))
+`, sql.Named("release", release), sql.Named("start", start), sql.Named("boundary", boundary), sql.Named("end", end),
+		sql.Named("invalid", NotInList(invalidVariants)))
 	if q.Error != nil {
 		return nil, q.Error
 	}
@@ -170,3 +209,163 @@ func LoadBugsForJobs(dbc *db.DB,
 	log.Infof("found %d bugs for job", len(job.Bugs))
 	return job.Bugs, nil
 }
+
+// JobWeeklyPassRate is a job's pass percentage over the week beginning at Week.
+type JobWeeklyPassRate struct {
+	Name           string
+	Week           time.Time
+	Runs           int
+	PassPercentage float64
+}
+
+// JobWeeklyPassRates returns the weekly pass rate of every job with runs between start and end.
+func JobWeeklyPassRates(dbc *db.DB, start, end time.Time) ([]JobWeeklyPassRate, error) {
+	results := make([]JobWeeklyPassRate, 0)
+	res := dbc.DB.Raw(`
+SELECT prow_jobs.name,
+       date_trunc('week', prow_job_runs.timestamp) AS week,
+       COUNT(*) AS runs,
+       COUNT(CASE WHEN prow_job_runs.succeeded = true THEN 1 END) * 100.0 / COUNT(*) AS pass_percentage
+FROM prow_job_runs
+         JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+GROUP BY prow_jobs.name, week`, sql.Named("start", start), sql.Named("end", end)).Scan(&results)
+	return results, res.Error
+}
+
+// NeverStableJobNames returns the jobs flagged as never-stable by the never-stable loader.
+func NeverStableJobNames(dbc *db.DB) ([]string, error) {
+	names := make([]string, 0)
+	res := dbc.DB.Model(&models.ProwJob{}).Where("never_stable = ?", true).Pluck("name", &names)
+	return names, res.Error
+}
+
+// VariantJobCounts returns the number of jobs in each variant, limited to a release unless it is empty.
+func VariantJobCounts(dbc *db.DB, release string) (map[string]int, error) {
+	var rows []struct {
+		Variant string
+		Jobs    int
+	}
+	q := dbc.DB.Model(&models.ProwJob{}).Select("unnest(variants) AS variant, COUNT(*) AS jobs")
+	if release != "" {
+		q = q.Where("release = ?", release)
+	}
+	if res := q.Group("variant").Scan(&rows); res.Error != nil {
+		return nil, res.Error
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Variant] = row.Jobs
+	}
+	return counts, nil
+}
+
+// JobDurationStats are a job's run durations, in seconds, in the week to the report end and the week before.
+type JobDurationStats struct {
+	ID   uint
+	Name string
+	// Timeout is zero when the job's timeout wasn't recorded.
+	Timeout              time.Duration
+	CurrentRuns          int
+	CurrentP95           float64
+	CurrentMax           float64
+	PreviousP95          float64
+	CurrentTimedOutRuns  int
+	PreviousTimedOutRuns int
+}
+
+// JobDurations returns the duration stats of the release's jobs with runs in the last week. Runs of jobs without a
+// recorded timeout are counted as timed out against the default timeout.
+func JobDurations(dbc *db.DB, release string, defaultTimeout time.Duration, reportEnd time.Time) ([]JobDurationStats, error) {
+	results := make([]JobDurationStats, 0)
+	res := dbc.DB.Raw(`
+WITH runs AS (
+    SELECT prow_jobs.id,
+           prow_jobs.name,
+           prow_jobs.timeout,
+           COALESCE(NULLIF(prow_jobs.timeout, 0), @default_timeout) AS effective_timeout,
+           prow_job_runs.duration,
+           prow_job_runs.timestamp >= @boundary AS current
+    FROM prow_job_runs
+             JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+    WHERE prow_jobs.release = @release
+      AND prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+      AND prow_job_runs.duration > 0
+)
+SELECT id,
+       name,
+       timeout,
+       COUNT(*) FILTER (WHERE current) AS current_runs,
+       percentile_cont(0.95) WITHIN GROUP (ORDER BY duration) FILTER (WHERE current) / 1e9 AS current_p95,
+       MAX(duration) FILTER (WHERE current) / 1e9 AS current_max,
+       percentile_cont(0.95) WITHIN GROUP (ORDER BY duration) FILTER (WHERE NOT current) / 1e9 AS previous_p95,
+       COUNT(*) FILTER (WHERE current AND duration >= effective_timeout) AS current_timed_out_runs,
+       COUNT(*) FILTER (WHERE NOT current AND duration >= effective_timeout) AS previous_timed_out_runs
+FROM runs
+GROUP BY id, name, timeout
+HAVING COUNT(*) FILTER (WHERE current) > 0`,
+		sql.Named("release", release),
+		sql.Named("default_timeout", defaultTimeout),
+		sql.Named("start", reportEnd.Add(-14*24*time.Hour)),
+		sql.Named("boundary", reportEnd.Add(-7*24*time.Hour)),
+		sql.Named("end", reportEnd)).Scan(&results)
+	return results, res.Error
+}
+
+// JobStep is a ci-operator step of a job, and its average duration in seconds.
+type JobStep struct {
+	ProwJobID uint
+	Name      string
+	Duration  float64
+}
+
+// SlowestJobSteps returns each job's ci-operator step with the longest average duration since the start. Steps are
+// recorded in the junit as "Run multi-stage test <test> - <step> container test".
+func SlowestJobSteps(dbc *db.DB, jobIDs []uint, start time.Time) (map[uint]JobStep, error) {
+	steps := make([]JobStep, 0)
+	res := dbc.DB.Raw(`
+SELECT DISTINCT ON (prow_job_runs.prow_job_id) prow_job_runs.prow_job_id,
+       tests.name,
+       AVG(prow_job_run_tests.duration) AS duration
+FROM prow_job_run_tests
+         JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+         JOIN tests ON tests.id = prow_job_run_tests.test_id
+WHERE prow_job_runs.prow_job_id IN @job_ids
+  AND prow_job_runs.timestamp >= @start
+  AND tests.name LIKE '% container test'
+GROUP BY prow_job_runs.prow_job_id, tests.name
+ORDER BY prow_job_runs.prow_job_id, duration DESC`,
+		sql.Named("job_ids", jobIDs), sql.Named("start", start)).Scan(&steps)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	slowest := make(map[uint]JobStep, len(steps))
+	for _, step := range steps {
+		slowest[step.ProwJobID] = step
+	}
+	return slowest, nil
+}
+
+// JobRunResult is whether a job run succeeded.
+type JobRunResult struct {
+	ProwJobID uint
+	JobName   string
+	Timestamp time.Time
+	Succeeded bool
+}
+
+// PeriodicJobRunResults returns the results of the release's periodic job runs between start and end, oldest first.
+func PeriodicJobRunResults(dbc *db.DB, release string, start, end time.Time) ([]JobRunResult, error) {
+	results := make([]JobRunResult, 0)
+	res := dbc.DB.Table("prow_job_runs").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_jobs.release = ? AND prow_jobs.kind = 'periodic'", release).
+		Where("prow_job_runs.timestamp BETWEEN ? AND ?", start, end).
+		Where("prow_job_runs.overall_result != ?", v1.JobRunning).
+		Select("prow_job_runs.prow_job_id, prow_jobs.name AS job_name, prow_job_runs.timestamp, prow_job_runs.succeeded").
+		Order("prow_job_runs.timestamp").
+		Scan(&results)
+	return results, res.Error
+}
@@ -53,3 +53,21 @@ func PlatformInfraSuccess(dbc *db.DB, platforms sets.String, period string) (map
 	}).Info("PlatformInfraSuccess completed")
 	return results, q.Error
 }
+
+// LastProwJobRunCreated returns when the most recent prow job run was inserted, which we treat as the last time
+// data was loaded.
+func LastProwJobRunCreated(dbc *db.DB) (time.Time, error) {
+	var lastUpdated struct {
+		Max time.Time
+	}
+	res := dbc.DB.Raw("SELECT MAX(created_at) FROM prow_job_runs").Scan(&lastUpdated)
+	return lastUpdated.Max, res.Error
+}
+
+// NotInList returns the values to bind to a NOT IN placeholder. gorm renders an empty list as NULL, which matches
+// nothing, so "" is added to keep the list non-empty. The values are copied so the caller's slice isn't appended to.
+func NotInList(values []string) []string {
+	list := make([]string, 0, len(values)+1)
+	list = append(list, values...)
+	return append(list, "")
+}
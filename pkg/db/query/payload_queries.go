@@ -198,3 +198,80 @@ func GetPayloadAcceptanceStatistics(db *gorm.DB, release, architecture, stream s
 
 	return results, q.Error
 }
+
+// GetPayloadTestCounts returns the test's passes and failures in each of the stream's payloads released between start
+// and end, oldest first. Payloads whose job runs didn't run the test are included with no results.
+func GetPayloadTestCounts(db *gorm.DB, release, stream, arch, testName string, start, end time.Time) ([]models.PayloadTestCounts, error) {
+	results := make([]models.PayloadTestCounts, 0)
+	// release_job_runs may have duplicates, so test results are counted distinctly
+	result := db.Raw(`SELECT
+		rt.id,
+		rt.release_tag,
+		rt.phase,
+		rt.release_time,
+		COUNT(DISTINCT pjrt.id) FILTER (WHERE pjrt.status IN (1, 13)) AS passes,
+		COUNT(DISTINCT pjrt.id) FILTER (WHERE pjrt.status = 12) AS failures
+	FROM
+		release_tags rt
+		LEFT JOIN release_job_runs rjr ON rjr.release_tag_id = rt.id
+		LEFT JOIN prow_job_run_tests pjrt ON pjrt.prow_job_run_id = rjr.prow_job_run_id
+			AND pjrt.test_id IN (SELECT id FROM tests WHERE name = @test)
+	WHERE
+		rt.release = @release
+		AND rt.stream = @stream
+		AND rt.architecture = @arch
+		AND rt.release_time BETWEEN @start AND @end
+	GROUP BY rt.id, rt.release_tag, rt.phase, rt.release_time
+	ORDER BY rt.release_time`,
+		map[string]interface{}{
+			"test":    testName,
+			"release": release,
+			"stream":  stream,
+			"arch":    arch,
+			"start":   start,
+			"end":     end,
+		}).Scan(&results)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return results, nil
+}
+
+// GetPullRequestsForPayloads returns the pull requests first included in the payloads, by payload release time.
+func GetPullRequestsForPayloads(db *gorm.DB, payloadIDs []uint) ([]models.PayloadPullRequest, error) {
+	results := make([]models.PayloadPullRequest, 0)
+	if len(payloadIDs) == 0 {
+		return results, nil
+	}
+
+	result := db.Table("release_tag_pull_requests").
+		Joins("JOIN release_pull_requests ON release_pull_requests.id = release_tag_pull_requests.release_pull_request_id").
+		Joins("JOIN release_tags ON release_tags.id = release_tag_pull_requests.release_tag_id").
+		Where("release_tags.id IN ?", payloadIDs).
+		Select(`release_tags.release_tag, release_pull_requests.url, release_pull_requests.pull_request_id,
+			release_pull_requests.name, release_pull_requests.description, release_pull_requests.bug_url`).
+		Order("release_tags.release_time, release_pull_requests.name, release_pull_requests.url").
+		Scan(&results)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return results, nil
+}
+
+// GetPayloadTags returns the stream's payload tags released between start and end, oldest first.
+func GetPayloadTags(db *gorm.DB, release, stream, arch string, start, end time.Time) ([]models.ReleaseTag, error) {
+	results := []models.ReleaseTag{}
+
+	result := db.Where("release = ?", release).
+		Where("stream = ?", stream).
+		Where("architecture = ?", arch).
+		Where("release_time BETWEEN ? AND ?", start, end).
+		Order("release_time").Find(&results)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return results, nil
+}
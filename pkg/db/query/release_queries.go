@@ -22,3 +22,18 @@ func ReleasesFromDB(dbClient *db.DB) ([]Release, error) {
 	}
 	return releases, nil
 }
+
+// TenantReleasesFromDB returns the releases of the tenant's jobs, sorted like ReleasesFromDB.
+func TenantReleasesFromDB(dbClient *db.DB, tenant string) ([]Release, error) {
+	var releases []Release
+	res := dbClient.DB.Raw(`
+		SELECT DISTINCT(release), case when position('.' in release) != 0 then string_to_array(release, '.')::int[] end as sortable_release
+                FROM prow_jobs
+                WHERE tenant = ?
+                ORDER BY sortable_release desc NULLS LAST`, tenant).Scan(&releases)
+	if res.Error != nil {
+		log.Errorf("error querying releases of tenant %s from db: %v", tenant, res.Error)
+		return releases, res.Error
+	}
+	return releases, nil
+}
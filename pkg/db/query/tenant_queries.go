@@ -0,0 +1,40 @@
+package query
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// TenantHasJob returns whether the job with the ID belongs to the tenant.
+func TenantHasJob(dbc *db.DB, tenant string, jobID uint) (bool, error) {
+	return exists(dbc.DB.Model(&models.ProwJob{}).Where("id = ? AND tenant = ?", jobID, tenant))
+}
+
+// TenantHasJobName returns whether a job with the name belongs to the tenant.
+func TenantHasJobName(dbc *db.DB, tenant, name string) (bool, error) {
+	return exists(dbc.DB.Model(&models.ProwJob{}).Where("name = ? AND tenant = ?", name, tenant))
+}
+
+// TenantHasJobRun returns whether the job run with the ID, archived or not, is of a job of the tenant.
+func TenantHasJobRun(dbc *db.DB, tenant string, jobRunID uint) (bool, error) {
+	return exists(dbc.DB.Model(&models.ProwJob{}).
+		Where("tenant = ?", tenant).
+		Where("id IN (?) OR id IN (?)",
+			dbc.DB.Model(&models.ProwJobRun{}).Select("prow_job_id").Where("id = ?", jobRunID),
+			dbc.DB.Model(&models.ArchivedProwJobRun{}).Select("prow_job_id").Where("id = ?", jobRunID)))
+}
+
+// TenantHasPayload returns whether the payload with the release tag belongs to the tenant.
+func TenantHasPayload(dbc *db.DB, tenant, releaseTag string) (bool, error) {
+	return exists(dbc.DB.Model(&models.ReleaseTag{}).Where("release_tag = ? AND tenant = ?", releaseTag, tenant))
+}
+
+func exists(q *gorm.DB) (bool, error) {
+	var count int64
+	if res := q.Count(&count); res.Error != nil {
+		return false, res.Error
+	}
+	return count > 0, nil
+}
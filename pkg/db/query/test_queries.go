@@ -22,10 +22,12 @@ const (
            sum(current_successes)  AS current_successes,
            sum(current_failures)   AS current_failures,
            sum(current_flakes)     AS current_flakes,
+           sum(current_retry_flakes) AS current_retry_flakes,
            sum(previous_runs)      AS previous_runs,
            sum(previous_successes) AS previous_successes,
            sum(previous_failures)  AS previous_failures,
            sum(previous_flakes)    AS previous_flakes,
+           sum(previous_retry_flakes) AS previous_retry_flakes,
            (array_agg(open_bugs))[1] AS open_bugs`
 
 	QueryTestFields = `
@@ -33,20 +35,24 @@ const (
 		current_successes,
 		current_failures,
 		current_flakes,
+		current_retry_flakes,
 		previous_runs,
 		previous_successes,
 		previous_failures,
 		previous_flakes,
+		previous_retry_flakes,
 		open_bugs`
 
 	QueryTestPercentages = `
 		current_successes * 100.0 / NULLIF(current_runs, 0) AS current_pass_percentage,
 		current_failures * 100.0 / NULLIF(current_runs, 0) AS current_failure_percentage,
 		current_flakes * 100.0 / NULLIF(current_runs, 0) AS current_flake_percentage,
+		current_retry_flakes * 100.0 / NULLIF(current_runs, 0) AS current_retry_flake_percentage,
 		(current_successes + current_flakes) * 100.0 / NULLIF(current_runs, 0) AS current_working_percentage,
 		previous_successes * 100.0 / NULLIF(previous_runs, 0) AS previous_pass_percentage,
 		previous_failures * 100.0 / NULLIF(previous_runs, 0) AS previous_failure_percentage,
 		previous_flakes * 100.0 / NULLIF(previous_runs, 0) AS previous_flake_percentage,
+		previous_retry_flakes * 100.0 / NULLIF(previous_runs, 0) AS previous_retry_flake_percentage,
 		(previous_successes + previous_flakes) * 100.0 / NULLIF(previous_runs, 0) AS previous_working_percentage,
 		(previous_failures * 100.0 / NULLIF(previous_runs, 0)) - (current_failures * 100.0 / NULLIF(current_runs, 0)) AS net_failure_improvement,
 		(previous_flakes * 100.0 / NULLIF(previous_runs, 0)) - (current_flakes * 100.0 / NULLIF(current_runs, 0)) AS net_flake_improvement,
@@ -59,12 +65,14 @@ const (
 )
 
 // TestReportsByVariant returns a test report for every test in the db matching the given substrings, separated by variant.
+// Jobs with any of excludeVariants are left out entirely, while invalidVariants only drops the reports for those variants.
 func TestReportsByVariant(
 	dbc *db.DB,
 	release string,
 	reportType v1.ReportType, // defaults to "current" or last 7 days vs prev 7 days
 	testSubStrings []string,
 	excludeVariants []string,
+	invalidVariants []string,
 ) ([]api.Test, error) {
 	now := time.Now()
 
@@ -102,7 +110,8 @@ SELECT *,
        previous_successes * 100.0 / NULLIF(previous_runs, 0) AS previous_pass_percentage,
        previous_failures * 100.0 / NULLIF(previous_runs, 0) AS previous_failure_percentage,
        (current_successes * 100.0 / NULLIF(current_runs, 0)) - (previous_successes * 100.0 / NULLIF(previous_runs, 0)) AS net_improvement
-FROM results;
+FROM results
+WHERE variant NOT IN @invalid;
 `
 
 	q = fmt.Sprintf(q, excludeVariantsQuery)
@@ -112,7 +121,8 @@ FROM results;
 	}
 	r := dbc.DB.Raw(q,
 		sql.Named("release", release),
-		sql.Named("testsubstrings", testSubstringFilter)).Scan(&testReports)
+		sql.Named("testsubstrings", testSubstringFilter),
+		sql.Named("invalid", NotInList(invalidVariants))).Scan(&testReports)
 	if r.Error != nil {
 		log.Error(r.Error)
 		return testReports, r.Error
@@ -150,7 +160,9 @@ func TestReportExcludeVariants(
            sum(previous_runs)      AS previous_runs,
            sum(previous_successes) AS previous_successes,
            sum(previous_failures)  AS previous_failures,
-           sum(previous_flakes)    AS previous_flakes
+           sum(previous_flakes)    AS previous_flakes,
+           sum(current_retry_flakes)  AS current_retry_flakes,
+           sum(previous_retry_flakes) AS previous_retry_flakes
     FROM prow_test_report_7d_matview
     WHERE release = @release AND name = @testname %s
     GROUP BY name, release
@@ -170,23 +182,38 @@ func TestReportExcludeVariants(
 	return testReport, nil
 }
 
-// LoadBugsForTest returns all bugs in the database for the given test, across all releases.
+// LoadBugsForTest returns all bugs in the database for the given test, across all releases, those most likely to be
+// the cause of the test's failures first, with their confidence set.
 func LoadBugsForTest(dbc *db.DB, testName string, filterClosed bool) ([]models.Bug, error) {
-	results := []models.Bug{}
+	type testBug struct {
+		models.Bug
+		Correlation float64
+	}
 
-	test := models.Test{}
-	q := dbc.DB.Where("name = ?", testName)
+	testBugs := make([]testBug, 0)
+	q := dbc.DB.Table("bugs").
+		Select("bugs.*, COALESCE(bug_test_correlations.confidence, 0) AS correlation").
+		Joins("INNER JOIN bug_tests ON bug_tests.bug_id = bugs.id").
+		Joins("INNER JOIN tests ON tests.id = bug_tests.test_id").
+		Joins("LEFT JOIN bug_test_correlations ON bug_test_correlations.bug_id = bugs.id AND bug_test_correlations.test_id = tests.id").
+		Where("tests.name = ?", testName).
+		Where("bugs.deleted_at IS NULL")
 	if filterClosed {
-		q = q.Preload("Bugs", "UPPER(status) != 'CLOSED' and UPPER(status) != 'VERIFIED'")
-	} else {
-		q = q.Preload("Bugs")
+		q = q.Where("UPPER(bugs.status) != 'CLOSED' AND UPPER(bugs.status) != 'VERIFIED'")
 	}
-	res := q.First(&test)
+	res := q.Order("correlation DESC, bugs.id").Scan(&testBugs)
 	if res.Error != nil {
-		return results, res.Error
+		return nil, res.Error
+	}
+
+	results := make([]models.Bug, 0, len(testBugs))
+	for _, tb := range testBugs {
+		bug := tb.Bug
+		bug.Confidence = tb.Correlation
+		results = append(results, bug)
 	}
-	log.Infof("found %d bugs for test", len(test.Bugs))
-	return test.Bugs, nil
+	log.Infof("found %d bugs for test", len(results))
+	return results, nil
 }
 
 // TestsByNURPAndStandardDeviation returns a test report for every test in the db matching the given substrings, separated by variant.
@@ -299,3 +326,139 @@ func TestDurations(dbc *db.DB, release, test string, includedVariants, excludedV
 
 	return results, res.Error
 }
+
+// TestDurationPercentiles returns the named test's duration percentiles in each release, newest first.
+func TestDurationPercentiles(dbc *db.DB, testName string) ([]api.TestDurationPercentiles, error) {
+	percentiles := make([]api.TestDurationPercentiles, 0)
+	res := dbc.DB.Table("prow_test_durations_14d_matview").
+		Where("test_name = ?", testName).
+		Order("case when position('.' in release) != 0 then string_to_array(release, '.')::int[] end DESC NULLS LAST").
+		Scan(&percentiles)
+	return percentiles, res.Error
+}
+
+// TestDurationRegressions returns the tests with at least minRuns passing runs in both releases, whose P95 duration
+// grew by at least minGrowth percent and minIncrease seconds from the base release, most grown first.
+func TestDurationRegressions(dbc *db.DB, release, baseRelease string, minRuns int, minGrowth, minIncrease float64) ([]api.TestDurationRegression, error) {
+	regressions := make([]api.TestDurationRegression, 0)
+	res := dbc.DB.Raw(`
+		SELECT
+			sample.test_id,
+			sample.test_name,
+			sample.runs,
+			sample.p50,
+			sample.p95,
+			base.runs AS base_runs,
+			base.p50 AS base_p50,
+			base.p95 AS base_p95,
+			sample.p95 - base.p95 AS p95_increase,
+			(sample.p95 - base.p95) * 100.0 / base.p95 AS p95_growth
+		FROM prow_test_durations_14d_matview sample
+			JOIN prow_test_durations_14d_matview base ON base.test_id = sample.test_id AND base.release = @base_release
+		WHERE sample.release = @release
+			AND sample.runs >= @min_runs
+			AND base.runs >= @min_runs
+			AND base.p95 > 0
+			AND sample.p95 - base.p95 >= @min_increase
+			AND (sample.p95 - base.p95) * 100.0 / base.p95 >= @min_growth
+		ORDER BY p95_growth DESC`,
+		map[string]interface{}{
+			"release":      release,
+			"base_release": baseRelease,
+			"min_runs":     minRuns,
+			"min_growth":   minGrowth,
+			"min_increase": minIncrease,
+		}).Scan(&regressions)
+	return regressions, res.Error
+}
+
+// OpenBugsForTests returns the open bugs linked to each of the named tests.
+func OpenBugsForTests(dbc *db.DB, testNames []string) (map[string][]models.Bug, error) {
+	type testBug struct {
+		models.Bug
+		TestName string
+	}
+
+	results := map[string][]models.Bug{}
+	if len(testNames) == 0 {
+		return results, nil
+	}
+
+	testBugs := make([]testBug, 0)
+	res := dbc.DB.Table("bugs").
+		Select("bugs.*, tests.name AS test_name").
+		Joins("INNER JOIN bug_tests ON bug_tests.bug_id = bugs.id").
+		Joins("INNER JOIN tests ON tests.id = bug_tests.test_id").
+		Where("tests.name IN ?", testNames).
+		Where("bugs.deleted_at IS NULL").
+		Where("UPPER(bugs.status) != 'CLOSED' AND UPPER(bugs.status) != 'VERIFIED'").
+		Scan(&testBugs)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	for _, tb := range testBugs {
+		results[tb.TestName] = append(results[tb.TestName], tb.Bug)
+	}
+	return results, nil
+}
+
+// RecentFailedRunsForTest returns the release's most recent job runs the test failed in, with their jobs.
+func RecentFailedRunsForTest(dbc *db.DB, release, testName string, limit int) ([]models.ProwJobRun, error) {
+	runs := make([]models.ProwJobRun, 0)
+	res := dbc.DB.
+		Preload("ProwJob").
+		Joins("INNER JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Joins("INNER JOIN prow_job_run_tests ON prow_job_run_tests.prow_job_run_id = prow_job_runs.id").
+		Joins("INNER JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Where("prow_jobs.release = ?", release).
+		Where("tests.name = ?", testName).
+		Where("prow_job_run_tests.status = ?", v1.TestStatusFailure).
+		Order("prow_job_runs.timestamp DESC").
+		Limit(limit).
+		Find(&runs)
+	return runs, res.Error
+}
+
+// NewTests returns the tests first seen in the release's jobs between since and end that never ran in any job before,
+// lowest pass percentage first, so new tests arriving already failing stand out.
+func NewTests(dbc *db.DB, release string, since, end time.Time) ([]api.NewTest, error) {
+	tests := make([]api.NewTest, 0)
+	res := dbc.DB.Raw(`
+		WITH release_tests AS (
+			SELECT
+				prow_job_run_tests.test_id,
+				MIN(prow_job_runs.timestamp) AS first_seen,
+				COUNT(DISTINCT prow_jobs.id) AS jobs,
+				COUNT(*) AS runs,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1) AS successes,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13) AS flakes,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12) AS failures
+			FROM prow_job_run_tests
+				JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+				JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_jobs.release = @release
+				AND prow_job_runs.timestamp BETWEEN @since AND @end
+			GROUP BY prow_job_run_tests.test_id
+		)
+		SELECT
+			release_tests.*,
+			tests.name AS test_name,
+			(successes + flakes) * 100.0 / NULLIF(runs, 0) AS pass_percentage
+		FROM release_tests
+			JOIN tests ON tests.id = release_tests.test_id
+		WHERE NOT EXISTS (
+			SELECT 1
+			FROM prow_job_run_tests earlier
+				JOIN prow_job_runs ON prow_job_runs.id = earlier.prow_job_run_id
+			WHERE earlier.test_id = release_tests.test_id
+				AND prow_job_runs.timestamp < @since
+		)
+		ORDER BY pass_percentage, runs DESC, test_name`,
+		map[string]interface{}{
+			"release": release,
+			"since":   since,
+			"end":     end,
+		}).Scan(&tests)
+	return tests, res.Error
+}
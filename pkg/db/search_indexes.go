@@ -0,0 +1,38 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// trigramIndex is a pg_trgm GIN index on a text column, which lets fuzzy and ILIKE searches of the column use an index.
+type trigramIndex struct {
+	Name   string
+	Table  string
+	Column string
+}
+
+// trigramIndexes are the columns /api/search looks in.
+var trigramIndexes = []trigramIndex{
+	{Name: "idx_tests_name_trgm", Table: "tests", Column: "name"},
+	{Name: "idx_prow_jobs_name_trgm", Table: "prow_jobs", Column: "name"},
+	{Name: "idx_prow_job_run_test_outputs_output_trgm", Table: "prow_job_run_test_outputs", Column: "output"},
+}
+
+// syncTrigramIndexes enables the pg_trgm extension and creates the trigram indexes. The index on the test outputs
+// takes a while to build the first time on a large database.
+func syncTrigramIndexes(db *gorm.DB) error {
+	if res := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm"); res.Error != nil {
+		return res.Error
+	}
+	for _, idx := range trigramIndexes {
+		schema := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING gin (%s gin_trgm_ops)", idx.Name, idx.Table,
+			idx.Column)
+		dropSQL := fmt.Sprintf("DROP INDEX IF EXISTS %s", idx.Name)
+		if _, err := syncSchema(db, hashTypeIndex, idx.Name, schema, dropSQL, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// AssignTenants tags each job with the tenant jobTenant returns for it, and each payload with the tenant of its jobs,
+// so that changes to the tenants config apply to data already imported. Payloads without job runs yet take the tenant
+// of their release's jobs when they all belong to the same one.
+func (d *DB) AssignTenants(jobTenant func(release, job string) string) error {
+	var jobs []models.ProwJob
+	if res := d.DB.Select("id", "name", "release", "tenant").Find(&jobs); res.Error != nil {
+		return res.Error
+	}
+
+	assigned := map[string][]uint{}
+	for _, job := range jobs {
+		if tenant := jobTenant(job.Release, job.Name); tenant != job.Tenant {
+			assigned[tenant] = append(assigned[tenant], job.ID)
+		}
+	}
+	for tenant, ids := range assigned {
+		if res := d.DB.Model(&models.ProwJob{}).Where("id IN ?", ids).Update("tenant", tenant); res.Error != nil {
+			return res.Error
+		}
+		log.Infof("assigned %d jobs to tenant %q", len(ids), tenant)
+	}
+
+	res := d.DB.Exec(`
+		WITH payload_tenants AS (
+			SELECT release_tags.id,
+				COALESCE(
+					(SELECT MIN(prow_jobs.tenant) FROM release_job_runs
+						JOIN prow_job_runs ON prow_job_runs.id = release_job_runs.prow_job_run_id
+						JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+						WHERE release_job_runs.release_tag_id = release_tags.id),
+					(SELECT MIN(prow_jobs.tenant) FROM prow_jobs
+						WHERE prow_jobs.release = release_tags.release
+						HAVING COUNT(DISTINCT prow_jobs.tenant) = 1),
+					'') AS tenant
+			FROM release_tags
+		)
+		UPDATE release_tags SET tenant = payload_tenants.tenant
+		FROM payload_tenants
+		WHERE release_tags.id = payload_tenants.id AND release_tags.tenant IS DISTINCT FROM payload_tenants.tenant`)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		log.Infof("assigned %d payloads to tenants", res.RowsAffected)
+	}
+	return nil
+}
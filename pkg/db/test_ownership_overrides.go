@@ -0,0 +1,80 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// ApplyTestOwnershipOverrides replaces the component of each overridden test's ownership with its override's, adding
+// an ownership for tests the mapping doesn't know. Overrides of tests sippy hasn't seen yet are applied once it has.
+func (d *DB) ApplyTestOwnershipOverrides() error {
+	overrides := make([]models.TestOwnershipOverride, 0)
+	if res := d.DB.Find(&overrides); res.Error != nil {
+		return res.Error
+	}
+
+	return d.DB.Transaction(func(tx *gorm.DB) error {
+		for _, o := range overrides {
+			test := models.Test{}
+			res := tx.Where("name = ?", o.TestName).Limit(1).Find(&test)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+
+			jiraComponent := models.JiraComponent{}
+			res = tx.Where("name = ?", o.JiraComponent).Limit(1).Find(&jiraComponent)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("override of %q has unknown jira component %q", o.TestName, o.JiraComponent)
+			}
+
+			ownership := models.TestOwnership{
+				Name:            o.TestName,
+				Suite:           o.Suite,
+				TestID:          test.ID,
+				Component:       o.Component,
+				JiraComponent:   o.JiraComponent,
+				JiraComponentID: &jiraComponent.ID,
+				Capabilities:    o.Capabilities,
+			}
+			if ownership.Component == "" {
+				ownership.Component = o.JiraComponent
+			}
+			if o.Suite != "" {
+				suite := models.Suite{}
+				res = tx.Where("name = ?", o.Suite).Limit(1).Find(&suite)
+				if res.Error != nil {
+					return res.Error
+				}
+				if res.RowsAffected > 0 {
+					ownership.SuiteID = &suite.ID
+				}
+			}
+			ownership.SetComponentHierarchy()
+
+			// the test keeps the capabilities the mapping gave it unless the override has its own
+			columns := []string{"updated_at", "deleted_at", "test_id", "suite_id", "component", "jira_component",
+				"jira_component_id", "jira_parent_component", "jira_sub_component"}
+			if len(o.Capabilities) > 0 {
+				columns = append(columns, "capabilities")
+			}
+			res = tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "name"}, {Name: "suite"}},
+				DoUpdates: clause.AssignmentColumns(columns),
+			}).Create(&ownership)
+			if res.Error != nil {
+				return res.Error
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// testTagBackfillBatchSize is how many tests are tagged per transaction.
+const testTagBackfillBatchSize = 5000
+
+// backfillTestTags tags the tests loaded before tags were parsed from their names, which have no feature gates. Tests
+// loaded since are tagged by the loader, so this is a no-op once the backfill completes.
+func backfillTestTags(db *gorm.DB) error {
+	total := 0
+	for {
+		tests := make([]models.Test, 0, testTagBackfillBatchSize)
+		res := db.Select("id", "name").Where("feature_gates IS NULL").Order("id").
+			Limit(testTagBackfillBatchSize).Find(&tests)
+		if res.Error != nil {
+			return res.Error
+		}
+		if len(tests) == 0 {
+			break
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for i := range tests {
+				tests[i].SetTags()
+				res := tx.Model(&tests[i]).Select("sig", "feature_gates", "capabilities").Updates(&tests[i])
+				if res.Error != nil {
+					return res.Error
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		total += len(tests)
+		log.WithField("tests", total).Info("backfilled test tags")
+	}
+	return nil
+}
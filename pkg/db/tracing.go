@@ -0,0 +1,44 @@
+package db
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/tracing"
+)
+
+const querySpanKey = "sippy:query_span"
+
+// registerTracingCallbacks wraps every statement in a span, parented to whatever span is on the statement's context.
+// Callers that want their queries attributed to a request should use DB.WithContext.
+func registerTracingCallbacks(db *gorm.DB) error {
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			_, span := tracing.StartQuerySpan(tx.Statement.Context, "postgresql", "")
+			span.SetAttributes(attribute.String("db.operation", operation))
+			tx.InstanceSet(querySpanKey, span)
+		}
+	}
+	after := func(string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			val, ok := tx.InstanceGet(querySpanKey)
+			if !ok {
+				return
+			}
+			span := val.(trace.Span)
+			span.SetAttributes(
+				attribute.String("db.statement", tx.Statement.SQL.String()),
+				attribute.String("db.sql.table", tx.Statement.Table),
+				attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+			)
+			if tx.Error == gorm.ErrRecordNotFound {
+				span.End()
+				return
+			}
+			tracing.EndSpan(span, tx.Error)
+		}
+	}
+
+	return registerAroundCallbacks(db, "tracing", before, after)
+}
@@ -0,0 +1,108 @@
+package digest
+
+import (
+	"sort"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// ComponentDigest summarizes a component's tests in a release.
+type ComponentDigest struct {
+	Component string
+	Release   string
+
+	// NewlyFailing are tests that failed in the current period, but not in the previous period.
+	NewlyFailing []apitype.Test
+
+	// Regressed and Improved are tests whose pass rate changed by at least the threshold, largest change first.
+	Regressed []apitype.Test
+	Improved  []apitype.Test
+
+	// Bugs are the open bugs linked to the tests above, by test name.
+	Bugs map[string][]models.Bug
+}
+
+// Empty returns true if there is nothing to report.
+func (d *ComponentDigest) Empty() bool {
+	return len(d.NewlyFailing) == 0 && len(d.Regressed) == 0 && len(d.Improved) == 0
+}
+
+// BuildDigests summarizes the release's tests for each component, from the same test report as the tests API.
+func BuildDigests(dbc *db.DB, release, period string, components []string, threshold float64) ([]*ComponentDigest, error) {
+	tests, _, err := api.BuildTestsResults(dbc, release, period, true, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := classifyTests(release, tests, components, threshold)
+
+	testNames := make([]string, 0)
+	for _, d := range digests {
+		for _, list := range [][]apitype.Test{d.NewlyFailing, d.Regressed, d.Improved} {
+			for _, test := range list {
+				testNames = append(testNames, test.Name)
+			}
+		}
+	}
+	bugs, err := query.OpenBugsForTests(dbc, testNames)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range digests {
+		for _, list := range [][]apitype.Test{d.NewlyFailing, d.Regressed, d.Improved} {
+			for _, test := range list {
+				if testBugs, ok := bugs[test.Name]; ok {
+					d.Bugs[test.Name] = testBugs
+				}
+			}
+		}
+	}
+
+	return digests, nil
+}
+
+// classifyTests buckets the components' tests into digests, sorted by component.
+func classifyTests(release string, tests []apitype.Test, components []string, threshold float64) []*ComponentDigest {
+	wanted := sets.NewString(components...)
+	byComponent := map[string]*ComponentDigest{}
+	for _, component := range wanted.List() {
+		byComponent[component] = &ComponentDigest{Component: component, Release: release, Bugs: map[string][]models.Bug{}}
+	}
+
+	for _, test := range tests {
+		d, ok := byComponent[test.JiraComponent]
+		if !ok || test.CurrentRuns == 0 || test.PreviousRuns == 0 {
+			continue
+		}
+
+		switch {
+		case test.PreviousFailures == 0 && test.CurrentFailures > 0:
+			d.NewlyFailing = append(d.NewlyFailing, test)
+		case test.NetImprovement <= -threshold:
+			d.Regressed = append(d.Regressed, test)
+		case test.NetImprovement >= threshold:
+			d.Improved = append(d.Improved, test)
+		}
+	}
+
+	digests := make([]*ComponentDigest, 0, len(byComponent))
+	for _, component := range wanted.List() {
+		d := byComponent[component]
+		sort.SliceStable(d.NewlyFailing, func(i, j int) bool {
+			return d.NewlyFailing[i].CurrentPassPercentage < d.NewlyFailing[j].CurrentPassPercentage
+		})
+		sort.SliceStable(d.Regressed, func(i, j int) bool {
+			return d.Regressed[i].NetImprovement < d.Regressed[j].NetImprovement
+		})
+		sort.SliceStable(d.Improved, func(i, j int) bool {
+			return d.Improved[i].NetImprovement > d.Improved[j].NetImprovement
+		})
+		digests = append(digests, d)
+	}
+	return digests
+}
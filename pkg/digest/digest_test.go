@@ -0,0 +1,113 @@
+package digest
+
+import (
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestClassifyTests(t *testing.T) {
+	test := func(name, component string, previousFailures, currentFailures int, netImprovement float64) apitype.Test {
+		return apitype.Test{
+			Name:             name,
+			JiraComponent:    component,
+			CurrentRuns:      10,
+			PreviousRuns:     10,
+			PreviousFailures: previousFailures,
+			CurrentFailures:  currentFailures,
+			NetImprovement:   netImprovement,
+		}
+	}
+
+	tests := []apitype.Test{
+		test("newly failing", "Networking", 0, 2, -20),
+		test("small regression", "Networking", 1, 4, -30),
+		test("large regression", "Networking", 1, 8, -70),
+		test("unchanged", "Networking", 1, 1, 0),
+		test("improved", "Networking", 5, 1, 40),
+		test("other component", "Storage", 0, 5, -50),
+		{Name: "no current runs", JiraComponent: "Networking", PreviousRuns: 10, NetImprovement: -100},
+	}
+
+	digests := classifyTests("4.15", tests, []string{"Networking", "etcd"}, 5)
+	require.Len(t, digests, 2)
+
+	networking := digests[0]
+	assert.Equal(t, "Networking", networking.Component)
+	assert.Equal(t, "4.15", networking.Release)
+	assert.Equal(t, []string{"newly failing"}, testNames(networking.NewlyFailing))
+	assert.Equal(t, []string{"large regression", "small regression"}, testNames(networking.Regressed))
+	assert.Equal(t, []string{"improved"}, testNames(networking.Improved))
+
+	assert.Equal(t, "etcd", digests[1].Component)
+	assert.True(t, digests[1].Empty())
+}
+
+func TestRender(t *testing.T) {
+	digests := []*ComponentDigest{
+		{
+			Component:    "Networking",
+			Release:      "4.15",
+			NewlyFailing: []apitype.Test{{Name: "test <a>", CurrentPassPercentage: 80}},
+			Bugs: map[string][]models.Bug{
+				"test <a>": {{Key: "OCPBUGS-1", URL: "https://issues.example.com/OCPBUGS-1"}},
+			},
+		},
+		{Component: "Networking", Release: "4.14"},
+	}
+
+	subject, body, err := Render(ScheduleWeekly, "Networking", "https://sippy.example.com/", digests)
+	require.NoError(t, err)
+	assert.Equal(t, "Sippy weekly digest for Networking", subject)
+	assert.Contains(t, body, `<a href="https://sippy.example.com/sippy-ng/tests/4.15/analysis?test=test%20%3ca%3e">test &lt;a&gt;</a>: 80.0% passing (<a href="https://issues.example.com/OCPBUGS-1">OCPBUGS-1</a>)`)
+	assert.Contains(t, body, "<h3>4.14</h3>\n<p>No pass rate changes.</p>")
+}
+
+func TestNextDigestTime(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2023, 11, 1, 10, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC), nextDigestTime(now, ScheduleDaily, 12))
+	assert.Equal(t, time.Date(2023, 11, 2, 9, 0, 0, 0, time.UTC), nextDigestTime(now, ScheduleDaily, 9))
+	assert.Equal(t, time.Date(2023, 11, 6, 12, 0, 0, 0, time.UTC), nextDigestTime(now, ScheduleWeekly, 12))
+
+	monday := time.Date(2023, 11, 6, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2023, 11, 13, 12, 0, 0, 0, time.UTC), nextDigestTime(monday, ScheduleWeekly, 12))
+}
+
+func TestSend(t *testing.T) {
+	p, err := NewProcessor(nil, v1.DigestConfig{SMTP: v1.SMTPConfig{Host: "smtp.example.com"}, From: "sippy@example.com"})
+	require.NoError(t, err)
+
+	var sentAddr string
+	var sentTo []string
+	var sentMsg string
+	p.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentAddr, sentTo, sentMsg = addr, to, string(msg)
+		return nil
+	}
+
+	require.NoError(t, p.send([]string{"a@example.com", "b@example.com"}, "subject", "<p>body</p>"))
+	assert.Equal(t, "smtp.example.com:587", sentAddr)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, sentTo)
+	assert.Contains(t, sentMsg, "To: a@example.com, b@example.com\r\nSubject: subject\r\n")
+	assert.Contains(t, sentMsg, "\r\n\r\n<p>body</p>")
+
+	_, err = NewProcessor(nil, v1.DigestConfig{SMTP: v1.SMTPConfig{Host: "smtp.example.com"}, From: "sippy@example.com", Schedule: "monthly"})
+	assert.Error(t, err)
+}
+
+func testNames(tests []apitype.Test) []string {
+	names := make([]string, 0, len(tests))
+	for _, test := range tests {
+		names = append(names, test.Name)
+	}
+	return names
+}
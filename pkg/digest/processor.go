@@ -0,0 +1,139 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+const (
+	ScheduleDaily  = "daily"
+	ScheduleWeekly = "weekly"
+
+	defaultPassRateChangeThreshold = 5
+	defaultSMTPPort                = 587
+)
+
+// sendMailFunc matches smtp.SendMail, so tests can capture mail.
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// Processor is a daemon process emailing component owners their digests on a schedule.
+type Processor struct {
+	dbc      *db.DB
+	config   v1.DigestConfig
+	auth     smtp.Auth
+	sendMail sendMailFunc
+}
+
+func NewProcessor(dbc *db.DB, config v1.DigestConfig) (*Processor, error) {
+	if config.Schedule == "" {
+		config.Schedule = ScheduleWeekly
+	}
+	if config.Schedule != ScheduleDaily && config.Schedule != ScheduleWeekly {
+		return nil, fmt.Errorf("invalid digest schedule %q, must be %s or %s", config.Schedule, ScheduleDaily, ScheduleWeekly)
+	}
+	if config.SMTP.Host == "" || config.From == "" {
+		return nil, fmt.Errorf("digests require an SMTP host and from address")
+	}
+	if config.SMTP.Port == 0 {
+		config.SMTP.Port = defaultSMTPPort
+	}
+	if config.PassRateChangeThreshold == 0 {
+		config.PassRateChangeThreshold = defaultPassRateChangeThreshold
+	}
+
+	p := &Processor{dbc: dbc, config: config, sendMail: smtp.SendMail}
+	if config.SMTP.Username != "" {
+		p.auth = smtp.PlainAuth("", config.SMTP.Username, os.Getenv("SIPPY_SMTP_PASSWORD"), config.SMTP.Host)
+	}
+	return p, nil
+}
+
+func (p *Processor) Run(ctx context.Context) {
+	for {
+		next := nextDigestTime(time.Now().UTC(), p.config.Schedule, p.config.Hour)
+		log.Infof("next %s digest at %s", p.config.Schedule, next)
+
+		select {
+		case <-ctx.Done():
+			log.Info("Exiting digest processor")
+			return
+		case <-time.After(time.Until(next)):
+			p.sendDigests()
+		}
+	}
+}
+
+// sendDigests emails each component's owners one digest covering every release.
+func (p *Processor) sendDigests() {
+	period := "default"
+	if p.config.Schedule == ScheduleDaily {
+		period = "twoDay"
+	}
+
+	components := make([]string, 0, len(p.config.Recipients))
+	for component := range p.config.Recipients {
+		components = append(components, component)
+	}
+
+	byComponent := map[string][]*ComponentDigest{}
+	for _, release := range p.config.Releases {
+		digests, err := BuildDigests(p.dbc, release, period, components, p.config.PassRateChangeThreshold)
+		if err != nil {
+			log.WithError(err).Errorf("error building %s digests", release)
+			continue
+		}
+		for _, d := range digests {
+			byComponent[d.Component] = append(byComponent[d.Component], d)
+		}
+	}
+
+	for component, digests := range byComponent {
+		subject, body, err := Render(p.config.Schedule, component, p.config.SippyURL, digests)
+		if err != nil {
+			log.WithError(err).Errorf("error rendering %s digest", component)
+			continue
+		}
+		if err := p.send(p.config.Recipients[component], subject, body); err != nil {
+			log.WithError(err).Errorf("error sending %s digest", component)
+			continue
+		}
+		log.Infof("sent %s digest to %d recipients", component, len(p.config.Recipients[component]))
+	}
+}
+
+func (p *Processor) send(to []string, subject, body string) error {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("From: %s\r\n", p.config.From))
+	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(to, ", ")))
+	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n\r\n")
+	msg.WriteString(body)
+
+	addr := fmt.Sprintf("%s:%d", p.config.SMTP.Host, p.config.SMTP.Port)
+	return p.sendMail(addr, p.auth, p.config.From, to, []byte(msg.String()))
+}
+
+// nextDigestTime returns the next time after now a digest is due, at the hour of every day for daily digests, or
+// of Mondays for weekly digests.
+func nextDigestTime(now time.Time, schedule string, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if schedule == ScheduleWeekly {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
@@ -0,0 +1,90 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+// maxTestsPerSection keeps digests short enough to read.
+const maxTestsPerSection = 20
+
+var digestTemplate = template.Must(template.New("digest").Parse(`<html>
+<body>
+<h2>Sippy {{ .Schedule }} digest for {{ .Component }}</h2>
+{{- range .Digests }}
+{{- $release := .Release }}
+{{- $bugs := .Bugs }}
+<h3>{{ .Release }}</h3>
+{{- if .Empty }}
+<p>No pass rate changes.</p>
+{{- end }}
+{{- with .NewlyFailing }}
+<h4>Newly failing tests</h4>
+<ul>
+{{- range . }}
+<li><a href="{{ $.SippyURL }}/sippy-ng/tests/{{ $release }}/analysis?test={{ .Name }}">{{ .Name }}</a>: {{ printf "%.1f" .CurrentPassPercentage }}% passing
+{{- range index $bugs .Name }} (<a href="{{ .URL }}">{{ .Key }}</a>){{ end }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- with .Regressed }}
+<h4>Pass rate drops</h4>
+<ul>
+{{- range . }}
+<li><a href="{{ $.SippyURL }}/sippy-ng/tests/{{ $release }}/analysis?test={{ .Name }}">{{ .Name }}</a>: {{ printf "%.1f" .PreviousPassPercentage }}% to {{ printf "%.1f" .CurrentPassPercentage }}%
+{{- range index $bugs .Name }} (<a href="{{ .URL }}">{{ .Key }}</a>){{ end }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- with .Improved }}
+<h4>Pass rate improvements</h4>
+<ul>
+{{- range . }}
+<li><a href="{{ $.SippyURL }}/sippy-ng/tests/{{ $release }}/analysis?test={{ .Name }}">{{ .Name }}</a>: {{ printf "%.1f" .PreviousPassPercentage }}% to {{ printf "%.1f" .CurrentPassPercentage }}%</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+
+type digestData struct {
+	Schedule  string
+	Component string
+	SippyURL  string
+	Digests   []*ComponentDigest
+}
+
+// Render renders a component's digests for its releases as an HTML email, returning its subject and body.
+func Render(schedule, component, sippyURL string, digests []*ComponentDigest) (string, string, error) {
+	for _, d := range digests {
+		d.NewlyFailing = truncate(d.NewlyFailing)
+		d.Regressed = truncate(d.Regressed)
+		d.Improved = truncate(d.Improved)
+	}
+
+	var body bytes.Buffer
+	err := digestTemplate.Execute(&body, digestData{
+		Schedule:  schedule,
+		Component: component,
+		SippyURL:  strings.TrimSuffix(sippyURL, "/"),
+		Digests:   digests,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return fmt.Sprintf("Sippy %s digest for %s", schedule, component), body.String(), nil
+}
+
+func truncate(tests []apitype.Test) []apitype.Test {
+	if len(tests) > maxTestsPerSection {
+		return tests[:maxTestsPerSection]
+	}
+	return tests
+}
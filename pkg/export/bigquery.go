@@ -0,0 +1,161 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// periods are the test report periods exported, matching the tests API.
+var periods = []string{"default", "twoDay"}
+
+// TestReportRow is a test's results for a combination of variants in a release, as shown in the tests API, with the
+// regression flags sippy computes. Every export appends a new set of rows with the same export time.
+type TestReportRow struct {
+	ExportTime    time.Time `json:"export_time" bigquery:"export_time"`
+	Release       string    `json:"release" bigquery:"release"`
+	Period        string    `json:"period" bigquery:"period"`
+	Name          string    `json:"name" bigquery:"name"`
+	SuiteName     string    `json:"suite_name" bigquery:"suite_name"`
+	Variants      []string  `json:"variants" bigquery:"variants"`
+	JiraComponent string    `json:"jira_component" bigquery:"jira_component"`
+
+	CurrentSuccesses      int     `json:"current_successes" bigquery:"current_successes"`
+	CurrentFailures       int     `json:"current_failures" bigquery:"current_failures"`
+	CurrentFlakes         int     `json:"current_flakes" bigquery:"current_flakes"`
+	CurrentRuns           int     `json:"current_runs" bigquery:"current_runs"`
+	CurrentPassPercentage float64 `json:"current_pass_percentage" bigquery:"current_pass_percentage"`
+
+	PreviousSuccesses      int     `json:"previous_successes" bigquery:"previous_successes"`
+	PreviousFailures       int     `json:"previous_failures" bigquery:"previous_failures"`
+	PreviousFlakes         int     `json:"previous_flakes" bigquery:"previous_flakes"`
+	PreviousRuns           int     `json:"previous_runs" bigquery:"previous_runs"`
+	PreviousPassPercentage float64 `json:"previous_pass_percentage" bigquery:"previous_pass_percentage"`
+
+	NetImprovement          float64 `json:"net_improvement" bigquery:"net_improvement"`
+	DeltaFromPassingAverage float64 `json:"delta_from_passing_average" bigquery:"delta_from_passing_average"`
+	OpenBugs                int     `json:"open_bugs" bigquery:"open_bugs"`
+
+	// Regressed is set when the pass rate dropped by at least the regression threshold between the periods.
+	Regressed bool `json:"regressed" bigquery:"regressed"`
+	// NewlyFailing is set when the test failed in the current period, but not the previous one.
+	NewlyFailing bool `json:"newly_failing" bigquery:"newly_failing"`
+}
+
+// BigQueryExporter appends the computed test reports to a BigQuery table, partitioned by export time.
+type BigQueryExporter struct {
+	dbc                 *db.DB
+	table               *bigquery.Table
+	releases            []string
+	regressionThreshold float64
+}
+
+func NewBigQueryExporter(dbc *db.DB, client *bigquery.Client, dataset, table string, releases []string, regressionThreshold float64) *BigQueryExporter {
+	return &BigQueryExporter{
+		dbc:                 dbc,
+		table:               client.Dataset(dataset).Table(table),
+		releases:            releases,
+		regressionThreshold: regressionThreshold,
+	}
+}
+
+// Export loads the current test reports for every release and period into the table, creating it if needed.
+func (e *BigQueryExporter) Export(ctx context.Context, exportTime time.Time) error {
+	var rows []TestReportRow
+	for _, release := range e.releases {
+		for _, period := range periods {
+			tests, _, err := api.BuildTestsResults(e.dbc, release, period, false, false, nil)
+			if err != nil {
+				return fmt.Errorf("error building %s %s test report: %w", release, period, err)
+			}
+			rows = append(rows, testReportRows(exportTime, release, period, tests, e.regressionThreshold)...)
+		}
+	}
+	if len(rows) == 0 {
+		log.Info("no test reports to export")
+		return nil
+	}
+
+	schema, err := bigquery.InferSchema(TestReportRow{})
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	// A load job rather than streaming inserts, which are billed and slow for hundreds of thousands of rows
+	source := bigquery.NewReaderSource(&body)
+	source.SourceFormat = bigquery.JSON
+	source.Schema = schema
+	loader := e.table.LoaderFrom(source)
+	loader.CreateDisposition = bigquery.CreateIfNeeded
+	loader.WriteDisposition = bigquery.WriteAppend
+	loader.TimePartitioning = &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "export_time"}
+
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting load job: %w", err)
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("error waiting for load job %s: %w", job.ID(), err)
+	}
+	if err := status.Err(); err != nil {
+		return fmt.Errorf("load job %s failed: %w", job.ID(), err)
+	}
+
+	log.Infof("exported %d test report rows to %s.%s", len(rows), e.table.DatasetID, e.table.TableID)
+	return nil
+}
+
+func testReportRows(exportTime time.Time, release, period string, tests []apitype.Test, regressionThreshold float64) []TestReportRow {
+	rows := make([]TestReportRow, 0, len(tests))
+	for _, test := range tests {
+		compared := test.CurrentRuns > 0 && test.PreviousRuns > 0
+		// BigQuery rejects null for repeated fields
+		variants := []string(test.Variants)
+		if variants == nil {
+			variants = []string{}
+		}
+		rows = append(rows, TestReportRow{
+			ExportTime:              exportTime,
+			Release:                 release,
+			Period:                  period,
+			Name:                    test.Name,
+			SuiteName:               test.SuiteName,
+			Variants:                variants,
+			JiraComponent:           test.JiraComponent,
+			CurrentSuccesses:        test.CurrentSuccesses,
+			CurrentFailures:         test.CurrentFailures,
+			CurrentFlakes:           test.CurrentFlakes,
+			CurrentRuns:             test.CurrentRuns,
+			CurrentPassPercentage:   test.CurrentPassPercentage,
+			PreviousSuccesses:       test.PreviousSuccesses,
+			PreviousFailures:        test.PreviousFailures,
+			PreviousFlakes:          test.PreviousFlakes,
+			PreviousRuns:            test.PreviousRuns,
+			PreviousPassPercentage:  test.PreviousPassPercentage,
+			NetImprovement:          test.NetImprovement,
+			DeltaFromPassingAverage: test.DeltaFromPassingAverage,
+			OpenBugs:                test.OpenBugs,
+			Regressed:               compared && test.NetImprovement <= -regressionThreshold,
+			NewlyFailing:            compared && test.PreviousFailures == 0 && test.CurrentFailures > 0,
+		})
+	}
+	return rows
+}
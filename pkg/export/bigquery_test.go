@@ -0,0 +1,36 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestTestReportRows(t *testing.T) {
+	exportTime := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	tests := []apitype.Test{
+		{Name: "regressed", Variants: []string{"aws"}, CurrentRuns: 10, PreviousRuns: 10, PreviousFailures: 1, CurrentFailures: 3, NetImprovement: -20},
+		{Name: "newly failing", CurrentRuns: 10, PreviousRuns: 10, CurrentFailures: 1, NetImprovement: -1},
+		{Name: "no previous runs", CurrentRuns: 10, CurrentFailures: 10, NetImprovement: -100},
+	}
+
+	rows := testReportRows(exportTime, "4.15", "default", tests, 5)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, exportTime, rows[0].ExportTime)
+	assert.Equal(t, "4.15", rows[0].Release)
+	assert.Equal(t, []string{"aws"}, rows[0].Variants)
+	assert.True(t, rows[0].Regressed)
+	assert.False(t, rows[0].NewlyFailing)
+
+	assert.Equal(t, []string{}, rows[1].Variants)
+	assert.False(t, rows[1].Regressed)
+	assert.True(t, rows[1].NewlyFailing)
+
+	assert.False(t, rows[2].Regressed)
+	assert.False(t, rows[2].NewlyFailing)
+}
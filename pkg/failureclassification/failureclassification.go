@@ -0,0 +1,131 @@
+// Package failureclassification labels why a failed job run failed: a product failure, a failure of the CI
+// infrastructure, or a failure to install the cluster. Rules in the sippy config are tried first, so known
+// infrastructure problems can be recognized by their failure output, then heuristics on the run's synthetic tests.
+package failureclassification
+
+import (
+	"fmt"
+	"regexp"
+
+	v1config "github.com/openshift/sippy/pkg/apis/config/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// Classification is why a job run failed.
+type Classification string
+
+const (
+	ProductFailure Classification = "product-failure"
+	InfraFailure   Classification = "infra-failure"
+	InstallFailure Classification = "install-failure"
+)
+
+var classifications = map[Classification]bool{
+	ProductFailure: true,
+	InfraFailure:   true,
+	InstallFailure: true,
+}
+
+// FailedTest is a test that failed in the run, with its output if it was recorded.
+type FailedTest struct {
+	Name   string
+	Output string
+}
+
+type rule struct {
+	classification Classification
+	job            *regexp.Regexp
+	test           *regexp.Regexp
+	output         *regexp.Regexp
+}
+
+// Classifier labels failed job runs with the configured rules and the built-in heuristics.
+type Classifier struct {
+	rules []rule
+}
+
+// NewClassifier validates the configured rules, and returns a classifier trying them in order.
+func NewClassifier(config v1config.FailureClassificationConfig) (*Classifier, error) {
+	c := &Classifier{}
+	for i, r := range config.Rules {
+		compiled := rule{classification: Classification(r.Classification)}
+		if !classifications[compiled.classification] {
+			return nil, fmt.Errorf("failure classification rule %d has unknown classification %q", i, r.Classification)
+		}
+		var err error
+		if compiled.job, err = compileOptional(r.JobRegexp); err != nil {
+			return nil, fmt.Errorf("failure classification rule %d has an invalid jobRegexp: %w", i, err)
+		}
+		if compiled.test, err = compileOptional(r.TestRegexp); err != nil {
+			return nil, fmt.Errorf("failure classification rule %d has an invalid testRegexp: %w", i, err)
+		}
+		if compiled.output, err = compileOptional(r.OutputRegexp); err != nil {
+			return nil, fmt.Errorf("failure classification rule %d has an invalid outputRegexp: %w", i, err)
+		}
+		c.rules = append(c.rules, compiled)
+	}
+	return c, nil
+}
+
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// Classify labels the run of the job from its overall result and failed tests, returning an empty classification for
+// runs that didn't fail.
+func (c *Classifier) Classify(jobName string, result sippyprocessingv1.JobOverallResult, failed []FailedTest) Classification {
+	switch result {
+	case sippyprocessingv1.JobSucceeded, sippyprocessingv1.JobRunning, sippyprocessingv1.JobAborted, "":
+		return ""
+	}
+
+	for _, r := range c.rules {
+		if r.matches(jobName, failed) {
+			return r.classification
+		}
+	}
+
+	switch result {
+	case sippyprocessingv1.JobInfrastructureFailure, sippyprocessingv1.JobFailureBeforeSetup:
+		return InfraFailure
+	case sippyprocessingv1.JobInstallFailure:
+		return InstallFailure
+	}
+	var install bool
+	for _, test := range failed {
+		switch test.Name {
+		case testidentification.InfrastructureTestName, testidentification.NewInfrastructureTestName:
+			return InfraFailure
+		case testidentification.InstallTestName, testidentification.InstallTimeoutTestName,
+			testidentification.NewInstallTestName:
+			install = true
+		}
+	}
+	if install {
+		return InstallFailure
+	}
+	return ProductFailure
+}
+
+func (r rule) matches(jobName string, failed []FailedTest) bool {
+	if r.job != nil && !r.job.MatchString(jobName) {
+		return false
+	}
+	if r.test == nil && r.output == nil {
+		return true
+	}
+	for _, test := range failed {
+		if r.test != nil && !r.test.MatchString(test.Name) {
+			continue
+		}
+		if r.output != nil && !r.output.MatchString(test.Output) {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package failureclassification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1config "github.com/openshift/sippy/pkg/apis/config/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+func TestNewClassifier(t *testing.T) {
+	_, err := NewClassifier(v1config.FailureClassificationConfig{
+		Rules: []v1config.FailureClassificationRule{{Classification: "flaky-failure"}},
+	})
+	assert.ErrorContains(t, err, "unknown classification")
+
+	_, err = NewClassifier(v1config.FailureClassificationConfig{
+		Rules: []v1config.FailureClassificationRule{{Classification: "infra-failure", OutputRegexp: "("}},
+	})
+	assert.ErrorContains(t, err, "invalid outputRegexp")
+}
+
+func TestClassify(t *testing.T) {
+	c, err := NewClassifier(v1config.FailureClassificationConfig{
+		Rules: []v1config.FailureClassificationRule{
+			{Classification: "infra-failure", OutputRegexp: `dial tcp .*: i/o timeout`},
+			{Classification: "infra-failure", JobRegexp: `-metal-`, TestRegexp: `^\[sig-sippy\] install`},
+			{Classification: "product-failure", JobRegexp: `-upgrade-`, TestRegexp: `^\[sig-sippy\] install should work`},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		job    string
+		result sippyprocessingv1.JobOverallResult
+		failed []FailedTest
+		want   Classification
+	}{
+		{
+			name:   "succeeded",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobSucceeded,
+		},
+		{
+			name:   "aborted",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobAborted,
+			failed: []FailedTest{{Name: "test", Output: "dial tcp 10.0.0.1:443: i/o timeout"}},
+		},
+		{
+			name:   "failed tests",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobTestFailure,
+			failed: []FailedTest{{Name: "[sig-network] pods should work", Output: "expected 1 got 2"}},
+			want:   ProductFailure,
+		},
+		{
+			name:   "output rule",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobTestFailure,
+			failed: []FailedTest{
+				{Name: "[sig-network] pods should work", Output: "expected 1 got 2"},
+				{Name: "[sig-api] apis should work", Output: "dial tcp 10.0.0.1:443: i/o timeout"},
+			},
+			want: InfraFailure,
+		},
+		{
+			name:   "job and test rule",
+			job:    "e2e-metal-ipi",
+			result: sippyprocessingv1.JobInstallFailure,
+			failed: []FailedTest{{Name: testidentification.InstallTestName}},
+			want:   InfraFailure,
+		},
+		{
+			name:   "rule overrides heuristics",
+			job:    "e2e-aws-upgrade-ovn",
+			result: sippyprocessingv1.JobInstallFailure,
+			failed: []FailedTest{{Name: testidentification.InstallTestName}},
+			want:   ProductFailure,
+		},
+		{
+			name:   "infrastructure result",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobInfrastructureFailure,
+			want:   InfraFailure,
+		},
+		{
+			name:   "failed before setup",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobFailureBeforeSetup,
+			want:   InfraFailure,
+		},
+		{
+			name:   "install result",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobInstallFailure,
+			want:   InstallFailure,
+		},
+		{
+			name:   "infrastructure synthetic test",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobUnknown,
+			failed: []FailedTest{
+				{Name: testidentification.NewInstallTestName},
+				{Name: testidentification.NewInfrastructureTestName},
+			},
+			want: InfraFailure,
+		},
+		{
+			name:   "install synthetic test",
+			job:    "e2e-aws",
+			result: sippyprocessingv1.JobUnknown,
+			failed: []FailedTest{{Name: testidentification.InstallTimeoutTestName}},
+			want:   InstallFailure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.Classify(tt.job, tt.result, tt.failed))
+		})
+	}
+}
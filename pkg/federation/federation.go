@@ -0,0 +1,207 @@
+// Package federation merges read queries to this sippy instance with the same queries to remote instances, so the
+// reports of separate instances, i.e. OKD's and OCP's, can be seen together.
+package federation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+const (
+	defaultName           = "local"
+	defaultTimeoutSeconds = 30
+
+	// OriginField is added to each merged row, naming the instance it came from.
+	OriginField = "origin"
+
+	// maxResponseBytes caps how much of a remote instance's response is read.
+	maxResponseBytes = 64 * 1024 * 1024
+)
+
+// Federation queries this and the remote instances.
+type Federation struct {
+	name       string
+	remotes    []v1.FederationRemoteConfig
+	httpClient *http.Client
+}
+
+// New returns the configured federation, or nil if there are no remote instances.
+func New(config v1.FederationConfig) *Federation {
+	if len(config.Remotes) == 0 {
+		return nil
+	}
+	if config.Name == "" {
+		config.Name = defaultName
+	}
+	if config.TimeoutSeconds <= 0 {
+		config.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	return &Federation{
+		name:       config.Name,
+		remotes:    config.Remotes,
+		httpClient: &http.Client{Timeout: time.Duration(config.TimeoutSeconds) * time.Second},
+	}
+}
+
+// response is an instance's response to a query.
+type response struct {
+	origin apitype.FederatedOrigin
+	body   []byte
+}
+
+// Query sends the request's query to the API path of this instance, through the local handler, and of every remote
+// instance at once, merging their responses. The local query keeps the request's tenant.
+func (f *Federation) Query(req *http.Request, local http.Handler, path string) *apitype.FederatedResponse {
+	responses := make([]response, len(f.remotes)+1)
+	responses[0] = f.queryLocal(req, local, path)
+
+	var wg sync.WaitGroup
+	for i, remote := range f.remotes {
+		wg.Add(1)
+		go func(i int, remote v1.FederationRemoteConfig) {
+			defer wg.Done()
+			responses[i+1] = f.queryRemote(req.Context(), remote, path, req.URL.RawQuery)
+		}(i, remote)
+	}
+	wg.Wait()
+
+	return merge(responses)
+}
+
+func (f *Federation) queryLocal(req *http.Request, local http.Handler, path string) response {
+	localReq := httptest.NewRequest(http.MethodGet, path+"?"+req.URL.RawQuery, nil).WithContext(req.Context())
+	if tenant := req.Header.Get(api.TenantHeader); tenant != "" {
+		localReq.Header.Set(api.TenantHeader, tenant)
+	}
+	rec := httptest.NewRecorder()
+	local.ServeHTTP(rec, localReq)
+
+	r := response{origin: apitype.FederatedOrigin{Name: f.name, Status: rec.Code}, body: rec.Body.Bytes()}
+	if rec.Code != http.StatusOK {
+		r.origin.Error = errorMessage(rec.Body.Bytes())
+	}
+	return r
+}
+
+func (f *Federation) queryRemote(ctx context.Context, remote v1.FederationRemoteConfig, path, rawQuery string) response {
+	r := response{origin: apitype.FederatedOrigin{Name: remote.Name, URL: remote.URL}}
+	u, err := url.Parse(remote.URL)
+	if err != nil {
+		r.origin.Error = err.Error()
+		return r
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = rawQuery
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		r.origin.Error = err.Error()
+		return r
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		log.WithError(err).Warningf("error querying federated instance %s", remote.Name)
+		r.origin.Error = err.Error()
+		return r
+	}
+	defer resp.Body.Close()
+
+	r.origin.Status = resp.StatusCode
+	r.body, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		r.origin.Error = err.Error()
+	} else if resp.StatusCode != http.StatusOK {
+		r.origin.Error = errorMessage(r.body)
+	}
+	return r
+}
+
+// errorMessage returns the title and detail of an API problem response, or the start of the body if it isn't one.
+func errorMessage(body []byte) string {
+	problem := api.Problem{}
+	if err := json.Unmarshal(body, &problem); err == nil && problem.Type != "" {
+		return problem.Error()
+	}
+	if len(body) > 200 {
+		body = body[:200]
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// merge tags the rows of the responses that are lists of rows, or pages of them, with their origin, returning the
+// other responses as they are. Failed responses are left out, with their error on their origin.
+func merge(responses []response) *apitype.FederatedResponse {
+	merged := &apitype.FederatedResponse{
+		Origins: make([]apitype.FederatedOrigin, 0, len(responses)),
+		Rows:    make([]map[string]interface{}, 0),
+	}
+	for _, r := range responses {
+		if r.origin.Error == "" {
+			rows, err := decodeRows(r.body)
+			switch {
+			case err != nil:
+				r.origin.Error = fmt.Sprintf("invalid response: %v", err)
+			case rows != nil:
+				for _, row := range rows {
+					row[OriginField] = r.origin.Name
+				}
+				merged.Rows = append(merged.Rows, rows...)
+			default:
+				if merged.Results == nil {
+					merged.Results = map[string]json.RawMessage{}
+				}
+				merged.Results[r.origin.Name] = r.body
+			}
+		}
+		merged.Origins = append(merged.Origins, r.origin)
+	}
+	return merged
+}
+
+// decodeRows returns the rows of a response that's a list of rows or a page of them, or nil if it's something else.
+// Numbers are kept as they are, as IDs such as prow job run IDs don't fit in a float.
+func decodeRows(body []byte) ([]map[string]interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		page, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil, nil
+		}
+		if list, ok = page["rows"].([]interface{}); !ok {
+			return nil, nil
+		}
+	}
+
+	rows := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
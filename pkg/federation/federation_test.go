@@ -0,0 +1,135 @@
+package federation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+func origin(name string, status int) apitype.FederatedOrigin {
+	return apitype.FederatedOrigin{Name: name, Status: status}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		responses   []response
+		wantRows    string
+		wantResults []string
+		wantErrors  map[string]string
+	}{
+		{
+			name: "lists are merged and tagged",
+			responses: []response{
+				{origin: origin("ocp", 200), body: []byte(`[{"name": "a", "id": 1790000000000000001}]`)},
+				{origin: origin("okd", 200), body: []byte(`[{"name": "b", "origin": "wrong"}]`)},
+			},
+			wantRows: `[{"name": "a", "id": 1790000000000000001, "origin": "ocp"}, {"name": "b", "origin": "okd"}]`,
+		},
+		{
+			name: "pages are merged",
+			responses: []response{
+				{origin: origin("ocp", 200), body: []byte(`{"rows": [{"name": "a"}], "total_rows": 10}`)},
+				{origin: origin("okd", 200), body: []byte(`[]`)},
+			},
+			wantRows: `[{"name": "a", "origin": "ocp"}]`,
+		},
+		{
+			name: "other responses are returned by origin",
+			responses: []response{
+				{origin: origin("ocp", 200), body: []byte(`{"name": "4.16"}`)},
+				{origin: origin("okd", 200), body: []byte(`["4.16"]`)},
+			},
+			wantRows:    `[]`,
+			wantResults: []string{"ocp", "okd"},
+		},
+		{
+			name: "failures are left out",
+			responses: []response{
+				{origin: origin("ocp", 200), body: []byte(`[{"name": "a"}]`)},
+				{origin: apitype.FederatedOrigin{Name: "okd", Status: 502, Error: "bad gateway"}},
+				{origin: origin("broken", 200), body: []byte(`[{"name"`)},
+			},
+			wantRows:   `[{"name": "a", "origin": "ocp"}]`,
+			wantErrors: map[string]string{"okd": "bad gateway", "broken": "invalid response: unexpected EOF"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := merge(tt.responses)
+
+			rows, err := json.Marshal(merged.Rows)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantRows, string(rows))
+
+			results := make([]string, 0)
+			for name := range merged.Results {
+				results = append(results, name)
+			}
+			assert.ElementsMatch(t, tt.wantResults, results)
+
+			assert.Len(t, merged.Origins, len(tt.responses))
+			for _, o := range merged.Origins {
+				assert.Equal(t, tt.wantErrors[o.Name], o.Error, o.Name)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sippy/api/jobs", r.URL.Path)
+		api.RespondWithJSON(http.StatusOK, w, []map[string]string{{"name": "okd-job", "release": r.URL.Query().Get("release")}})
+	}))
+	defer remote.Close()
+	local := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/jobs", r.URL.Path)
+		assert.Equal(t, "ocp", r.Header.Get(api.TenantHeader))
+		api.RespondWithJSON(http.StatusOK, w, []map[string]string{{"name": "ocp-job", "release": r.URL.Query().Get("release")}})
+	})
+
+	f := New(v1.FederationConfig{Name: "ocp", Remotes: []v1.FederationRemoteConfig{{Name: "okd", URL: remote.URL + "/sippy/"}}})
+	req := httptest.NewRequest(http.MethodGet, "/api/federated/jobs?release=4.16", nil)
+	req.Header.Set(api.TenantHeader, "ocp")
+	merged := f.Query(req, local, "/api/jobs")
+
+	rows, err := json.Marshal(merged.Rows)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"name": "ocp-job", "release": "4.16", "origin": "ocp"},
+		{"name": "okd-job", "release": "4.16", "origin": "okd"}]`, string(rows))
+	assert.Equal(t, "ocp", merged.Origins[0].Name)
+	assert.Equal(t, http.StatusOK, merged.Origins[1].Status)
+	assert.Empty(t, merged.Origins[1].Error)
+
+	assert.Nil(t, New(v1.FederationConfig{}), "federation is disabled without remotes")
+}
+
+func TestQueryErrors(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.RespondWithError(http.StatusNotFound, w, "release 4.99 not found")
+	}))
+	defer remote.Close()
+	local := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying jobs")
+	})
+
+	f := New(v1.FederationConfig{Name: "ocp", Remotes: []v1.FederationRemoteConfig{{Name: "okd", URL: remote.URL}}})
+	merged := f.Query(httptest.NewRequest(http.MethodGet, "/api/federated/jobs?release=4.99", nil), local, "/api/jobs")
+
+	require.Len(t, merged.Origins, 2)
+	assert.Equal(t, http.StatusInternalServerError, merged.Origins[0].Status)
+	assert.Equal(t, "Internal server error: error querying jobs", merged.Origins[0].Error)
+	assert.Equal(t, http.StatusNotFound, merged.Origins[1].Status)
+	assert.Equal(t, "Not found: release 4.99 not found", merged.Origins[1].Error)
+	assert.Empty(t, merged.Rows)
+
+	assert.Equal(t, "<html>bad gateway</html>", errorMessage([]byte("<html>bad gateway</html>\n")))
+}
@@ -61,11 +61,20 @@ type FilterItem struct {
 	Value    string   `json:"value"`
 }
 
-func (f FilterItem) orFilterToSQL(db *gorm.DB, filterable Filterable) (orFilter string, orParams interface{}) { //nolint
-	field := fmt.Sprintf("%q", f.Field)
+// fieldToSQL returns the SQL expression selecting the item's field. A field like "variant_dimensions.Platform" selects
+// a single dimension from the variant_dimensions jsonb column.
+func (f FilterItem) fieldToSQL(filterable Filterable) string {
+	if dimension, ok := apitype.VariantDimensionKey(f.Field); ok {
+		return fmt.Sprintf("%s->>%s", pq.QuoteIdentifier(apitype.VariantDimensionsField), pq.QuoteLiteral(dimension))
+	}
 	if filterable != nil && filterable.GetFieldType(f.Field) == apitype.ColumnTypeTimestamp {
-		field = fmt.Sprintf("extract(epoch from %s at time zone 'utc') * 1000", f.Field)
+		return fmt.Sprintf("extract(epoch from %s at time zone 'utc') * 1000", f.Field)
 	}
+	return fmt.Sprintf("%q", f.Field)
+}
+
+func (f FilterItem) orFilterToSQL(db *gorm.DB, filterable Filterable) (orFilter string, orParams interface{}) { //nolint
+	field := f.fieldToSQL(filterable)
 
 	switch f.Operator {
 	case OperatorContains:
@@ -138,10 +147,7 @@ func (f FilterItem) orFilterToSQL(db *gorm.DB, filterable Filterable) (orFilter
 }
 
 func (f FilterItem) andFilterToSQL(db *gorm.DB, filterable Filterable) *gorm.DB { //nolint
-	field := fmt.Sprintf("%q", f.Field)
-	if filterable != nil && filterable.GetFieldType(f.Field) == apitype.ColumnTypeTimestamp {
-		field = fmt.Sprintf("extract(epoch from %s at time zone 'utc') * 1000", f.Field)
-	}
+	field := f.fieldToSQL(filterable)
 
 	switch f.Operator {
 	case OperatorContains:
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
 )
 
 func TestLinkOperator(t *testing.T) {
@@ -306,6 +307,36 @@ func TestFilterableStrings(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "filter_by_variant_dimension_equals_true",
+			job:  apitype.Job{Name: "e2e-test", VariantDimensions: models.VariantDimensions{"Platform": "aws"}},
+			filter: Filter{
+				Items: []FilterItem{
+					{
+						Field:    "variant_dimensions.Platform",
+						Operator: "equals",
+						Value:    "aws",
+					},
+				},
+				LinkOperator: LinkOperatorAnd,
+			},
+			expected: true,
+		},
+		{
+			name: "filter_by_variant_dimension_equals_false",
+			job:  apitype.Job{Name: "e2e-test", VariantDimensions: models.VariantDimensions{"Platform": "aws"}},
+			filter: Filter{
+				Items: []FilterItem{
+					{
+						Field:    "variant_dimensions.Network",
+						Operator: "equals",
+						Value:    "ovn",
+					},
+				},
+				LinkOperator: LinkOperatorAnd,
+			},
+			expected: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,43 @@
+package flags
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/archive"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+)
+
+// ArchiveFlags holds the location old job runs are archived to.
+type ArchiveFlags struct {
+	Location string
+}
+
+func NewArchiveFlags() *ArchiveFlags {
+	return &ArchiveFlags{}
+}
+
+func (f *ArchiveFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.Location,
+		"archive-location",
+		f.Location,
+		"Where old job runs are archived to, a GCS bucket and prefix as gs://bucket/prefix, or a local directory")
+}
+
+// GetStore returns the archive's store, authenticating to GCS with the google flags' credentials, or nil if no
+// location is set.
+func (f *ArchiveFlags) GetStore(ctx context.Context, googleFlags *GoogleCloudFlags) (archive.Store, error) {
+	if f.Location == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(f.Location, "gs://") {
+		return archive.NewStore(f.Location, nil)
+	}
+	client, err := gcs.NewGCSClient(ctx, googleFlags.ServiceAccountCredentialFile, googleFlags.OAuthClientCredentialFile)
+	if err != nil {
+		return nil, err
+	}
+	return archive.NewStore(f.Location, client)
+}
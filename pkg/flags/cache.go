@@ -6,6 +6,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/openshift/sippy/pkg/apis/cache"
+	"github.com/openshift/sippy/pkg/cache/memory"
 	"github.com/openshift/sippy/pkg/cache/redis"
 )
 
@@ -13,10 +14,14 @@ import (
 // of its configuration file.
 type CacheFlags struct {
 	RedisURL string
+	// MemoryEntries is how many API responses are cached in memory, in front of redis if it's configured.
+	MemoryEntries int
 }
 
 func NewCacheFlags() *CacheFlags {
-	return &CacheFlags{}
+	return &CacheFlags{
+		MemoryEntries: 1000,
+	}
 }
 
 func (f *CacheFlags) BindFlags(fs *pflag.FlagSet) {
@@ -24,6 +29,10 @@ func (f *CacheFlags) BindFlags(fs *pflag.FlagSet) {
 		"redis-url",
 		os.Getenv("REDIS_URL"),
 		"Redis URL for caching")
+	fs.IntVar(&f.MemoryEntries,
+		"memory-cache-entries",
+		f.MemoryEntries,
+		"How many API responses to cache in memory, in front of redis if configured (0 disables)")
 }
 
 func (f *CacheFlags) GetCacheClient() (cache.Cache, error) {
@@ -33,3 +42,12 @@ func (f *CacheFlags) GetCacheClient() (cache.Cache, error) {
 
 	return nil, nil
 }
+
+// GetMemoryCache returns the in-memory cache for API responses, in front of next if it isn't nil, or next when the
+// memory cache is disabled.
+func (f *CacheFlags) GetMemoryCache(next cache.Cache) cache.Cache {
+	if f.MemoryEntries < 1 {
+		return next
+	}
+	return memory.NewCache(f.MemoryEntries, next)
+}
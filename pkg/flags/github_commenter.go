@@ -2,6 +2,8 @@ package flags
 
 import (
 	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/github/commenter"
 )
 
 var commentProcessingDryRunDefault = true
@@ -12,10 +14,29 @@ type GithubCommenterFlags struct {
 	ExcludeReposCommenting  []string
 	CommentProcessing       bool
 	CommentProcessingDryRun bool
+	SippyURL                string
+	APIRequestsPerHour      int
+	APIBurst                int
+	DailyCommentBudget      int
+	CheckRuns               bool
 }
 
 func NewGithubCommenterFlags() *GithubCommenterFlags {
-	return &GithubCommenterFlags{}
+	return &GithubCommenterFlags{
+		SippyURL:           "https://sippy.dptools.openshift.org",
+		APIRequestsPerHour: 1000,
+		APIBurst:           10,
+		DailyCommentBudget: 200,
+	}
+}
+
+// GetCommentLimits returns the limits for the GitHub commenter.
+func (f *GithubCommenterFlags) GetCommentLimits() commenter.CommentLimits {
+	return commenter.CommentLimits{
+		APIRequestsPerHour: f.APIRequestsPerHour,
+		APIBurst:           f.APIBurst,
+		DailyBudget:        f.DailyCommentBudget,
+	}
 }
 
 func (f *GithubCommenterFlags) BindFlags(fs *pflag.FlagSet) {
@@ -23,4 +44,9 @@ func (f *GithubCommenterFlags) BindFlags(fs *pflag.FlagSet) {
 	fs.StringArrayVar(&f.ExcludeReposCommenting, "exclude-repo-commenting", f.ExcludeReposCommenting, "Which repos do we skip for pr commenting (one repo per arg instance  org/repo or just repo if openshift org)")
 	fs.BoolVar(&f.CommentProcessing, "comment-processing", f.CommentProcessing, "Enable comment processing for github repos")
 	fs.BoolVar(&f.CommentProcessingDryRun, "comment-processing-dry-run", commentProcessingDryRunDefault, "Enable github comment interaction for comment processing, disabled by default")
+	fs.StringVar(&f.SippyURL, "comment-sippy-url", f.SippyURL, "Sippy instance that PR comments link to for test details, empty to disable links")
+	fs.IntVar(&f.APIRequestsPerHour, "comment-github-requests-per-hour", f.APIRequestsPerHour, "Maximum GitHub API requests an hour made while commenting, 0 for no limit")
+	fs.IntVar(&f.APIBurst, "comment-github-burst", f.APIBurst, "Maximum burst of GitHub API requests made while commenting")
+	fs.BoolVar(&f.CheckRuns, "comment-check-runs", f.CheckRuns, "Publish risk analysis as GitHub check runs instead of PR comments, requires a GitHub App token")
+	fs.IntVar(&f.DailyCommentBudget, "comment-daily-budget", f.DailyCommentBudget, "Maximum number of PR comments added a day, 0 for no limit")
 }
@@ -1,6 +1,9 @@
 package flags
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/pflag"
 
 	"github.com/openshift/sippy/pkg/sippyserver"
@@ -9,12 +12,17 @@ import (
 )
 
 type ModeFlags struct {
-	Mode string
+	Mode              string
+	VariantConfigPath string
 }
 
+// Modes select a VariantManager by its registered name, any name registered with
+// testidentification.RegisterVariantManager may be used.
 const (
-	ModeOpenshift = "ocp"
-	ModeNone      = "none"
+	ModeOpenshift = testidentification.OpenshiftVariantManagerName
+	ModeNone      = testidentification.EmptyVariantManagerName
+	// ModeConfig identifies variants from the rules in --variant-config, otherwise behaving like ocp.
+	ModeConfig = testidentification.ConfigVariantManagerName
 )
 
 func NewModeFlags() *ModeFlags {
@@ -24,30 +32,29 @@ func NewModeFlags() *ModeFlags {
 }
 
 func (f *ModeFlags) BindFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&f.Mode, "mode", f.Mode, "Mode to use: {ocp,none}")
+	fs.StringVar(&f.Mode, "mode", f.Mode,
+		fmt.Sprintf("Mode to use: {%s}", strings.Join(testidentification.RegisteredVariantManagers(), ",")))
+	fs.StringVar(&f.VariantConfigPath, "variant-config", f.VariantConfigPath, "Variant definitions file, required with --mode=config")
 }
 
 func (f *ModeFlags) GetServerMode() sippyserver.Mode {
-	if f.Mode == ModeOpenshift {
+	if f.Mode == ModeOpenshift || f.Mode == ModeConfig {
 		return sippyserver.ModeOpenShift
 	}
 
 	return sippyserver.ModeKubernetes
 }
 
-func (f *ModeFlags) GetVariantManager() testidentification.VariantManager {
-	switch f.Mode {
-	case ModeOpenshift:
-		return testidentification.NewOpenshiftVariantManager()
-	case ModeNone:
-		return testidentification.NewEmptyVariantManager()
-	default:
-		panic("only ocp or none is allowed")
-	}
+// GetVariantManager returns the VariantManager registered for the mode, see
+// testidentification.RegisterVariantManager.
+func (f *ModeFlags) GetVariantManager() (testidentification.VariantManager, error) {
+	return testidentification.NewVariantManager(f.Mode, testidentification.VariantManagerOptions{
+		ConfigPath: f.VariantConfigPath,
+	})
 }
 
 func (f *ModeFlags) GetSyntheticTestManager() synthetictests.SyntheticTestManager {
-	if f.Mode == ModeOpenshift {
+	if f.Mode == ModeOpenshift || f.Mode == ModeConfig {
 		return synthetictests.NewOpenshiftSyntheticTestManager()
 	}
 
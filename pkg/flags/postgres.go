@@ -96,6 +96,8 @@ func (f *PostgresFlags) GetPinnedTime() *time.Time {
 type PostgresFlags struct {
 	LogLevel logLevel
 	DSN      string
+	// StatementCacheSize is how many statements are kept prepared, 0 disables preparing them.
+	StatementCacheSize int
 
 	// pinnedTime should not be exported. Use GetPinnedTime() instead.
 	pinnedTime PinnedTime
@@ -108,19 +110,21 @@ func NewPostgresDatabaseFlags() *PostgresFlags {
 	}
 
 	return &PostgresFlags{
-		LogLevel: logLevel(logger.Info),
-		DSN:      dsn,
+		LogLevel:           logLevel(logger.Info),
+		DSN:                dsn,
+		StatementCacheSize: db.DefaultStatementCacheSize,
 	}
 }
 
 func (f *PostgresFlags) BindFlags(fs *pflag.FlagSet) {
 	fs.Var(&f.LogLevel, "db-log-level", "GORM database log level")
 	fs.StringVar(&f.DSN, "database-dsn", f.DSN, "Database DSN for connecting to Postgres")
+	fs.IntVar(&f.StatementCacheSize, "db-statement-cache-size", f.StatementCacheSize, "How many statements to keep prepared, so postgres doesn't plan repeated queries every time (0 disables)")
 	fs.Var(&f.pinnedTime, "pinned-date-time", "Pin database results to a fixed end date/time")
 }
 
 func (f *PostgresFlags) GetDBClient() (*db.DB, error) {
-	dbc, err := db.New(f.DSN, logger.LogLevel(f.LogLevel))
+	dbc, err := db.New(f.DSN, logger.LogLevel(f.LogLevel), f.StatementCacheSize)
 	if err != nil {
 		log.WithError(err).Fatal("could not connect to db")
 		return nil, err
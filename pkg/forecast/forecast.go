@@ -0,0 +1,113 @@
+// Package forecast projects a series of daily values, like a job's pass rate, forward with a weighted linear trend, so
+// reports can warn about where a value is heading rather than only where it is.
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// z95 is the standard normal quantile of a two-sided 95% interval.
+const z95 = 1.96
+
+// Point is a value observed at a time, weighted by how much it's trusted, i.e. the number of runs behind a pass rate.
+type Point struct {
+	Time   time.Time
+	Value  float64
+	Weight float64
+}
+
+// Trend is the weighted least squares line through a series of points.
+type Trend struct {
+	// origin is the weighted mean time of the points, slope is per day and intercept is the value at origin.
+	origin    time.Time
+	slope     float64
+	intercept float64
+	// residual is the weighted standard deviation of the points around the line, n how many points there were and
+	// sxx the weighted spread of their times, in days squared, all to size the prediction interval.
+	residual float64
+	n        int
+	sxx      float64
+}
+
+// Linear fits a line through the points, returning false if they can't define one: fewer than two points with
+// weight, or all at the same time.
+func Linear(points []Point) (Trend, bool) {
+	var totalWeight float64
+	var n int
+	for _, p := range points {
+		if p.Weight > 0 {
+			totalWeight += p.Weight
+			n++
+		}
+	}
+	if n < 2 {
+		return Trend{}, false
+	}
+
+	ref := points[0].Time
+	var meanX, meanY float64
+	for _, p := range points {
+		if p.Weight > 0 {
+			meanX += p.Weight * days(p.Time.Sub(ref))
+			meanY += p.Weight * p.Value
+		}
+	}
+	meanX /= totalWeight
+	meanY /= totalWeight
+
+	var sxx, sxy float64
+	for _, p := range points {
+		if p.Weight > 0 {
+			dx := days(p.Time.Sub(ref)) - meanX
+			sxx += p.Weight * dx * dx
+			sxy += p.Weight * dx * (p.Value - meanY)
+		}
+	}
+	if sxx == 0 {
+		return Trend{}, false
+	}
+
+	t := Trend{
+		origin:    ref.Add(time.Duration(meanX * float64(24*time.Hour))),
+		slope:     sxy / sxx,
+		intercept: meanY,
+		n:         n,
+		// normalize the spread to the points' mean weight, so weights only shift the line, not the interval
+		sxx: sxx * float64(n) / totalWeight,
+	}
+	if n > 2 {
+		var sse float64
+		for _, p := range points {
+			if p.Weight > 0 {
+				residual := p.Value - t.At(p.Time)
+				sse += p.Weight * residual * residual
+			}
+		}
+		t.residual = math.Sqrt(sse / totalWeight * float64(n) / float64(n-2))
+	}
+	return t, true
+}
+
+// SlopePerDay is how much the value changes each day.
+func (t Trend) SlopePerDay() float64 {
+	return t.slope
+}
+
+// At returns the trend's value at the time.
+func (t Trend) At(at time.Time) float64 {
+	return t.intercept + t.slope*days(at.Sub(t.origin))
+}
+
+// Interval returns the 95% prediction interval of a value observed at the time, which widens the further the time is
+// from the points the trend was fitted to.
+func (t Trend) Interval(at time.Time) (float64, float64) {
+	x := days(at.Sub(t.origin))
+	margin := z95 * t.residual * math.Sqrt(1+1/float64(t.n)+x*x/t.sxx)
+	value := t.At(at)
+	return value - margin, value + margin
+}
+
+func days(d time.Duration) float64 {
+	return d.Hours() / 24
+}
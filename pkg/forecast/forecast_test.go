@@ -0,0 +1,54 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinear(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return start.AddDate(0, 0, d) }
+
+	// falling a point a day from 90
+	points := make([]Point, 0)
+	for d := 0; d < 10; d++ {
+		points = append(points, Point{Time: day(d), Value: 90 - float64(d), Weight: 10})
+	}
+	trend, ok := Linear(points)
+	require.True(t, ok)
+	assert.InDelta(t, -1, trend.SlopePerDay(), 1e-9)
+	assert.InDelta(t, 70, trend.At(day(20)), 1e-9)
+	low, high := trend.Interval(day(20))
+	assert.InDelta(t, 70, low, 1e-9, "a perfect fit has no spread")
+	assert.InDelta(t, 70, high, 1e-9)
+
+	// noise widens the interval, more so further out
+	points[3].Value += 4
+	points[6].Value -= 4
+	trend, ok = Linear(points)
+	require.True(t, ok)
+	nearLow, nearHigh := trend.Interval(day(10))
+	farLow, farHigh := trend.Interval(day(40))
+	assert.Less(t, nearLow, trend.At(day(10)))
+	assert.Greater(t, nearHigh, trend.At(day(10)))
+	assert.Greater(t, farHigh-farLow, nearHigh-nearLow)
+
+	// heavier points pull the line towards them
+	trend, ok = Linear([]Point{
+		{Time: day(0), Value: 100, Weight: 1},
+		{Time: day(1), Value: 50, Weight: 1},
+		{Time: day(2), Value: 50, Weight: 100},
+	})
+	require.True(t, ok)
+	assert.InDelta(t, 50, trend.At(day(2)), 1)
+
+	_, ok = Linear([]Point{{Time: day(0), Value: 90, Weight: 1}})
+	assert.False(t, ok, "one point")
+	_, ok = Linear([]Point{{Time: day(0), Value: 90, Weight: 1}, {Time: day(0), Value: 80, Weight: 1}})
+	assert.False(t, ok, "no spread in time")
+	_, ok = Linear([]Point{{Time: day(0), Value: 90, Weight: 1}, {Time: day(1), Value: 80}})
+	assert.False(t, ok, "points without weight are ignored")
+}
@@ -0,0 +1,228 @@
+// Package funnel counts install and upgrade attempts, and the stage each failed one failed at, per release, variant
+// and day, from the synthetic install and upgrade tests of each job run. Days are kept after their job runs are
+// pruned, so success rates can be compared over longer periods.
+package funnel
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/testidentification"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+const (
+	// refreshDays are recomputed on each refresh, as job runs for them may still be loading
+	refreshDays = 14
+
+	allVariants   = "All"
+	unknownReason = "unknown"
+
+	// upgrade tests are sometimes reported under the upgrade suite
+	upgradeSuitePrefix = "Cluster upgrade."
+)
+
+type stage struct {
+	testName string
+	reason   string
+}
+
+// definition describes a funnel: a job run attempted it when it has one of the overall tests, and a failed attempt
+// failed at the first stage whose test failed.
+type definition struct {
+	kind    string
+	overall sets.String
+	stages  []stage
+}
+
+var definitions = []definition{
+	{
+		kind:    models.FunnelInstall,
+		overall: sets.NewString(testidentification.NewInstallTestName, testidentification.InstallTestName),
+		stages: []stage{
+			{testidentification.InstallConfigTestName, "configuration"},
+			{testidentification.NewInfrastructureTestName, "infrastructure"},
+			{testidentification.InstallBootstrapTestName, "cluster bootstrap"},
+			{testidentification.InstallOtherTestName, "other"},
+		},
+	},
+	{
+		kind:    models.FunnelUpgrade,
+		overall: sets.NewString(testidentification.UpgradeTestName),
+		stages: []stage{
+			{testidentification.CVOAcknowledgesUpgradeTest, "cluster version operator acknowledges upgrade"},
+			{testidentification.OperatorsUpgradedTest, "operators upgrade"},
+			{testidentification.MachineConfigsUpgradedTest, "machine config pools upgrade"},
+		},
+	},
+}
+
+// runTestResult is the result of one of the funnel tests in a job run.
+type runTestResult struct {
+	ProwJobRunID uint
+	Release      string
+	Variants     pq.StringArray `gorm:"type:text[]"`
+	Date         time.Time
+	TestName     string
+	Status       int
+}
+
+type jobRun struct {
+	release  string
+	variants []string
+	date     time.Time
+	statuses map[string]int
+}
+
+type dayKey struct {
+	kind    string
+	release string
+	variant string
+	date    time.Time
+}
+
+// Refresh recomputes the funnel days of the last two weeks up to the report end.
+func Refresh(dbc *db.DB, reportEnd time.Time) error {
+	start := reportEnd.UTC().Truncate(24*time.Hour).AddDate(0, 0, -refreshDays)
+
+	names := make([]string, 0)
+	for _, def := range definitions {
+		names = append(names, def.overall.List()...)
+		for _, s := range def.stages {
+			names = append(names, s.testName)
+			if def.kind == models.FunnelUpgrade {
+				names = append(names, upgradeSuitePrefix+s.testName)
+			}
+		}
+	}
+
+	results := make([]runTestResult, 0)
+	res := dbc.DB.Table("prow_job_run_tests").
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_job_runs.timestamp >= ? AND prow_job_runs.timestamp <= ?", start, reportEnd).
+		Where("tests.name IN ?", names).
+		Select(`prow_job_run_tests.prow_job_run_id, prow_jobs.release, prow_jobs.variants,
+			DATE(prow_job_runs.timestamp AT TIME ZONE 'UTC') AS date, tests.name AS test_name, prow_job_run_tests.status`).
+		Scan(&results)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	days := buildDays(results)
+	err := dbc.DB.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Unscoped().Where("date >= ?", start).Delete(&models.FunnelDay{}); res.Error != nil {
+			return res.Error
+		}
+		if len(days) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(days, 1000).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("refreshed %d install and upgrade funnel days since %s", len(days), start.Format("2006-01-02"))
+	return nil
+}
+
+// buildDays counts each job run's attempts in every variant of its job, and in All.
+func buildDays(results []runTestResult) []models.FunnelDay {
+	excluded := sets.NewString(testidentification.DefaultExcludedVariants...)
+	runs := map[uint]*jobRun{}
+	for _, r := range results {
+		if excluded.HasAny(r.Variants...) {
+			continue
+		}
+		run, ok := runs[r.ProwJobRunID]
+		if !ok {
+			run = &jobRun{release: r.Release, variants: r.Variants, date: r.Date, statuses: map[string]int{}}
+			runs[r.ProwJobRunID] = run
+		}
+		run.statuses[strings.TrimPrefix(r.TestName, upgradeSuitePrefix)] = r.Status
+	}
+
+	days := map[dayKey]*models.FunnelDay{}
+	for _, run := range runs {
+		for _, def := range definitions {
+			attempted, success, reason := def.outcome(run.statuses)
+			if !attempted {
+				continue
+			}
+			for _, variant := range append([]string{allVariants}, run.variants...) {
+				key := dayKey{kind: def.kind, release: run.release, variant: variant, date: run.date}
+				day, ok := days[key]
+				if !ok {
+					day = &models.FunnelDay{
+						Kind:           def.kind,
+						Release:        run.release,
+						Variant:        variant,
+						Date:           run.date,
+						FailureReasons: models.FailureReasons{},
+					}
+					days[key] = day
+				}
+				day.Attempts++
+				if success {
+					day.Successes++
+				} else {
+					day.FailureReasons[reason]++
+				}
+			}
+		}
+	}
+
+	list := make([]models.FunnelDay, 0, len(days))
+	for _, day := range days {
+		list = append(list, *day)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Release != b.Release {
+			return a.Release < b.Release
+		}
+		return a.Variant < b.Variant
+	})
+	return list
+}
+
+// outcome returns whether the job run attempted the funnel, whether it succeeded, and if not, why.
+func (d definition) outcome(statuses map[string]int) (bool, bool, string) {
+	attempted := false
+	for name := range d.overall {
+		status, ok := statuses[name]
+		if !ok {
+			continue
+		}
+		attempted = true
+		if status == int(sippyprocessingv1.TestStatusSuccess) || status == int(sippyprocessingv1.TestStatusFlake) {
+			return true, true, ""
+		}
+	}
+	if !attempted {
+		return false, false, ""
+	}
+
+	for _, s := range d.stages {
+		if statuses[s.testName] == int(sippyprocessingv1.TestStatusFailure) {
+			return true, false, s.reason
+		}
+	}
+	return true, false, unknownReason
+}
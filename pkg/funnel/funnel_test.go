@@ -0,0 +1,51 @@
+package funnel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+func TestBuildDays(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	result := func(run uint, variants []string, test string, status int) runTestResult {
+		return runTestResult{ProwJobRunID: run, Release: "4.16", Variants: variants, Date: day, TestName: test, Status: status}
+	}
+	aws, gcp := []string{"aws"}, []string{"gcp"}
+
+	days := buildDays([]runTestResult{
+		result(1, aws, testidentification.NewInstallTestName, 1),
+		result(2, aws, testidentification.NewInstallTestName, 12),
+		result(2, aws, testidentification.InstallConfigTestName, 1),
+		result(2, aws, testidentification.NewInfrastructureTestName, 12),
+		result(2, aws, testidentification.InstallBootstrapTestName, 12),
+		result(3, gcp, testidentification.NewInstallTestName, 12),
+		result(3, gcp, testidentification.UpgradeTestName, 13),
+		result(4, gcp, testidentification.UpgradeTestName, 12),
+		result(4, gcp, upgradeSuitePrefix+testidentification.OperatorsUpgradedTest, 12),
+		// aggregated jobs repeat the runs they aggregate
+		result(5, []string{"aws", "aggregated"}, testidentification.NewInstallTestName, 12),
+	})
+
+	counts := map[string]models.FunnelDay{}
+	for _, d := range days {
+		counts[d.Kind+" "+d.Variant] = d
+	}
+	require.Len(t, counts, 5)
+
+	assert.Equal(t, 3, counts["install All"].Attempts)
+	assert.Equal(t, 1, counts["install All"].Successes)
+	assert.Equal(t, models.FailureReasons{"infrastructure": 1, "unknown": 1}, counts["install All"].FailureReasons)
+	assert.Equal(t, 2, counts["install aws"].Attempts)
+	assert.Equal(t, models.FailureReasons{"infrastructure": 1}, counts["install aws"].FailureReasons)
+	assert.Equal(t, models.FailureReasons{"unknown": 1}, counts["install gcp"].FailureReasons)
+
+	assert.Equal(t, 2, counts["upgrade gcp"].Attempts)
+	assert.Equal(t, 1, counts["upgrade gcp"].Successes, "flakes succeeded")
+	assert.Equal(t, models.FailureReasons{"operators upgrade": 1}, counts["upgrade gcp"].FailureReasons)
+}
@@ -0,0 +1,47 @@
+package commenter
+
+import (
+	"sync"
+	"time"
+)
+
+// commentBudget caps the number of comments added a day, resetting at midnight UTC. It is kept in memory, so it
+// applies to each commenter process.
+type commentBudget struct {
+	lock  sync.Mutex
+	limit int
+	used  int
+	day   time.Time
+	now   func() time.Time
+}
+
+func newCommentBudget(limit int, now func() time.Time) *commentBudget {
+	b := &commentBudget{limit: limit, now: now}
+	if limit > 0 {
+		commentBudgetRemainingMetric.Set(float64(limit))
+	}
+	return b
+}
+
+// take uses one comment from today's budget, returning false if it was already used up.
+func (b *commentBudget) take() bool {
+	if b.limit <= 0 {
+		return true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	today := b.now().UTC().Truncate(24 * time.Hour)
+	if !today.Equal(b.day) {
+		b.day = today
+		b.used = 0
+	}
+
+	if b.used >= b.limit {
+		return false
+	}
+	b.used++
+	commentBudgetRemainingMetric.Set(float64(b.limit - b.used))
+	return true
+}
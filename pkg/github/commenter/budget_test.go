@@ -0,0 +1,26 @@
+package commenter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentBudget(t *testing.T) {
+	now := time.Date(2023, 11, 1, 23, 0, 0, 0, time.UTC)
+	budget := newCommentBudget(2, func() time.Time { return now })
+
+	assert.True(t, budget.take())
+	assert.True(t, budget.take())
+	assert.False(t, budget.take(), "budget should be exhausted")
+
+	// resets at midnight UTC
+	now = now.Add(time.Hour)
+	assert.True(t, budget.take())
+
+	unlimited := newCommentBudget(0, func() time.Time { return now })
+	for i := 0; i < 10; i++ {
+		assert.True(t, unlimited.take())
+	}
+}
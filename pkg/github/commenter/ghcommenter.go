@@ -6,28 +6,70 @@ import (
 	"strings"
 	"time"
 
+	gh "github.com/google/go-github/v45/github"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/github"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
+var (
+	commentBudgetRemainingMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sippy_repo_comment_budget_remaining",
+		Help: "The number of pr comments we may still add today",
+	})
+
+	commentBudgetExhaustedMetric = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sippy_repo_comment_budget_exhausted",
+		Help: "Tracks the number of pr comments deferred because the daily comment budget was exhausted",
+	})
+)
+
+// ErrCommentBudgetExhausted is returned by AddComment once the daily comment budget has been used up.
+var ErrCommentBudgetExhausted = errors.New("daily comment budget exhausted")
+
 type GitHubCommenter struct {
 	githubClient *github.Client
 	dbc          *db.DB
 	includeRepos map[string]sets.String
 	excludeRepos map[string]sets.String
+	apiLimiter   *util.TokenBucket
+	budget       *commentBudget
+}
+
+// CommentLimits keeps a bad run of the commenter from exhausting our GitHub API quota or spamming PRs.
+type CommentLimits struct {
+	// APIRequestsPerHour and APIBurst limit the GitHub API calls made while commenting, zero disables the limit.
+	APIRequestsPerHour int
+	APIBurst           int
+
+	// DailyBudget is the maximum number of comments added a day, zero disables the budget.
+	DailyBudget int
 }
 
 const TrtCommentIDKey = `trt_comment_id`
 
-func NewGitHubCommenter(githubClient *github.Client, dbc *db.DB, excludedRepos, includedRepos []string) (*GitHubCommenter, error) {
+// CheckRunName is the name of the check runs published in place of comments.
+const CheckRunName = "sippy/risk-analysis"
+
+func NewGitHubCommenter(githubClient *github.Client, dbc *db.DB, excludedRepos, includedRepos []string, limits CommentLimits) (*GitHubCommenter, error) {
 	ghCommenter := &GitHubCommenter{}
 	ghCommenter.githubClient = githubClient
 	ghCommenter.dbc = dbc
+	ghCommenter.budget = newCommentBudget(limits.DailyBudget, time.Now)
+	if limits.APIRequestsPerHour > 0 {
+		burst := limits.APIBurst
+		if burst < 1 {
+			burst = 1
+		}
+		ghCommenter.apiLimiter = util.NewTokenBucket(burst, limits.APIRequestsPerHour)
+	}
 
 	var err error
 	ghCommenter.excludeRepos, err = buildOrgRepos(excludedRepos)
@@ -93,11 +135,25 @@ func (ghc *GitHubCommenter) CreateCommentID(commentType models.CommentType, sha
 	return fmt.Sprintf("TRT_%s", sha)
 }
 
+// waitForAPI blocks until the commenter may make another GitHub API call.
+func (ghc *GitHubCommenter) waitForAPI() {
+	if ghc.apiLimiter != nil {
+		ghc.apiLimiter.Wait()
+	}
+}
+
+// IsWithinRateLimitThreshold returns true when our remaining GitHub API quota is too low to keep commenting.
+func (ghc *GitHubCommenter) IsWithinRateLimitThreshold() bool {
+	return ghc.githubClient.IsWithinRateLimitThreshold()
+}
+
 func (ghc *GitHubCommenter) GetCurrentState(org, repo string, number int) (*github.PREntry, error) {
+	ghc.waitForAPI()
 	return ghc.githubClient.PRFetch(org, repo, number)
 }
 
 func (ghc *GitHubCommenter) FindExistingCommentID(org, repo string, number int, commentKey, commentID string) (*int64, *string, error) {
+	ghc.waitForAPI()
 	return ghc.githubClient.FindCommentID(org, repo, number, commentKey, commentID)
 }
 
@@ -230,6 +286,11 @@ func (ghc *GitHubCommenter) ValidateAndUpdatePendingRecordComment(org, repo stri
 		return false, err
 	}
 
+	// we only comment once per sha
+	if pullRequestComment.CommentedAt != nil {
+		return false, nil
+	}
+
 	// default is wait 10 minutes but then increase the wait by the number of errors we have encountered for this record
 	waitMillis := int64(600000 * (pullRequestComment.FailedCommentAttempts + 1))
 
@@ -272,6 +333,15 @@ func (ghc *GitHubCommenter) UpdatePendingRecordErrorCount(org, repo string, numb
 	return nil
 }
 
+// MarkCommented records that a comment has been posted for the sha, so it is no longer pending but still prevents
+// commenting on the same sha again.
+func (ghc *GitHubCommenter) MarkCommented(org, repo string, number int, sha string, commentType models.CommentType) error {
+	res := ghc.dbc.DB.Model(&models.PullRequestComment{}).
+		Where("org = ? AND repo = ? AND pull_number = ? AND sha = ? AND comment_type = ?", org, repo, number, sha, commentType).
+		Update("commented_at", time.Now())
+	return res.Error
+}
+
 func (ghc *GitHubCommenter) ClearPendingRecord(org, repo string, number int, sha string, commentType models.CommentType, record *models.PullRequestComment) {
 	logger := log.WithField("org", org).
 		WithField("repo", repo).
@@ -333,7 +403,7 @@ func (ghc *GitHubCommenter) QueryPendingComments(commentType models.CommentType)
 	pullRequestComments := make([]models.PullRequestComment, 0)
 
 	res := ghc.dbc.DB.Table("pull_request_comments").
-		Where("comment_type = ?", commentType).
+		Where("comment_type = ? AND commented_at IS NULL", commentType).
 		Order("created_at").
 		Scan(&pullRequestComments)
 
@@ -344,16 +414,39 @@ func (ghc *GitHubCommenter) QueryPendingComments(commentType models.CommentType)
 	return pullRequestComments, nil
 }
 
-func (ghc *GitHubCommenter) AddComment(org, repo string, number int, comment string) error {
+// AddComment adds the comment to the PR, returning the created comment, or nil if the repo is not included.
+func (ghc *GitHubCommenter) AddComment(org, repo string, number int, comment string) (*gh.IssueComment, error) {
 	// could return error or log something but handle silently for now
 	// we shouldn't even get called in this case
 	if !ghc.IsRepoIncluded(org, repo) {
-		return nil
+		return nil, nil
+	}
+
+	if !ghc.budget.take() {
+		commentBudgetExhaustedMetric.Inc()
+		return nil, ErrCommentBudgetExhausted
 	}
 
+	ghc.waitForAPI()
 	return ghc.githubClient.CreatePRComment(org, repo, number, comment)
 }
 
+// AddCheckRun publishes a completed check run on the sha in place of a comment. It counts against the daily comment
+// budget.
+func (ghc *GitHubCommenter) AddCheckRun(org, repo, sha, conclusion, title, summary, detailsURL string) error {
+	if !ghc.IsRepoIncluded(org, repo) {
+		return nil
+	}
+
+	if !ghc.budget.take() {
+		commentBudgetExhaustedMetric.Inc()
+		return ErrCommentBudgetExhausted
+	}
+
+	ghc.waitForAPI()
+	return ghc.githubClient.CreateCheckRun(org, repo, sha, CheckRunName, conclusion, title, summary, detailsURL)
+}
+
 func (ghc *GitHubCommenter) DeleteComment(org, repo string, updateID int64) error {
 	// could return error or log something but handle silently for now
 	// we shouldn't even get called in this case
@@ -361,5 +454,6 @@ func (ghc *GitHubCommenter) DeleteComment(org, repo string, updateID int64) erro
 		return nil
 	}
 
+	ghc.waitForAPI()
 	return ghc.githubClient.DeletePRComment(org, repo, updateID)
 }
@@ -115,7 +115,7 @@ func TestGitHubCommenter_IsRepoIncluded(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			ghCommenter, err := NewGitHubCommenter(nil, nil, tt.exclude, tt.include)
+			ghCommenter, err := NewGitHubCommenter(nil, nil, tt.exclude, tt.include, CommentLimits{})
 
 			// invalid ghCommenter at this point
 			// we expected the error
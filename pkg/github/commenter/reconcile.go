@@ -0,0 +1,123 @@
+package commenter
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// minimizeReason is the GitHub classifier used when hiding stale comments.
+const minimizeReason = "OUTDATED"
+
+// PostComment comments on the PR for the sha, reconciling the comment with those previously posted for the comment
+// type. When the verdict is unchanged since the last comment, that comment is updated in place, so the PR isn't
+// notified again. Otherwise, a new comment is added and the previous comments are minimized as outdated.
+func (ghc *GitHubCommenter) PostComment(org, repo string, number int, sha string, commentType models.CommentType, verdict, comment string) error {
+	logger := log.WithField("org", org).
+		WithField("repo", repo).
+		WithField("number", number).
+		WithField("sha", sha)
+
+	active, err := ghc.queryActivePostedComments(org, repo, number, commentType)
+	if err != nil {
+		return err
+	}
+
+	update, stale := reconcilePostedComments(active, verdict)
+	if update != nil {
+		logger.Infof("Updating comment %d, verdict is still %s", update.CommentID, verdict)
+		ghc.waitForAPI()
+		if err := ghc.githubClient.EditPRComment(org, repo, update.CommentID, comment); err != nil {
+			return err
+		}
+		res := ghc.dbc.DB.Model(update).Update("sha", sha)
+		return res.Error
+	}
+
+	created, err := ghc.AddComment(org, repo, number, comment)
+	if err != nil || created == nil {
+		return err
+	}
+
+	posted := models.PostedComment{
+		CommentID:   created.GetID(),
+		NodeID:      created.GetNodeID(),
+		Org:         org,
+		Repo:        repo,
+		PullNumber:  number,
+		CommentType: int(commentType),
+		SHA:         sha,
+		Verdict:     verdict,
+	}
+	if res := ghc.dbc.DB.Create(&posted); res.Error != nil {
+		// the comment was added, so we only log to avoid adding it again
+		logger.WithError(res.Error).Error("Could not record posted comment")
+	}
+
+	// the comment was added, so stale comments are retried with the next comment rather than failing this one
+	if err := ghc.minimizeComments(stale); err != nil {
+		logger.WithError(err).Error("Could not minimize stale comments")
+	}
+	return nil
+}
+
+// MinimizePostedComments hides every comment posted on the PR for the comment type, i.e. when a later sha has nothing
+// to report.
+func (ghc *GitHubCommenter) MinimizePostedComments(org, repo string, number int, commentType models.CommentType) error {
+	active, err := ghc.queryActivePostedComments(org, repo, number, commentType)
+	if err != nil {
+		return err
+	}
+	return ghc.minimizeComments(active)
+}
+
+// HasActivePostedComments returns true if the PR has comments for the comment type that have not been minimized.
+func (ghc *GitHubCommenter) HasActivePostedComments(org, repo string, number int, commentType models.CommentType) (bool, error) {
+	active, err := ghc.queryActivePostedComments(org, repo, number, commentType)
+	return len(active) > 0, err
+}
+
+func (ghc *GitHubCommenter) minimizeComments(comments []models.PostedComment) error {
+	for i := range comments {
+		if comments[i].NodeID == "" {
+			continue
+		}
+		ghc.waitForAPI()
+		if err := ghc.githubClient.MinimizeComment(comments[i].NodeID, minimizeReason); err != nil {
+			return fmt.Errorf("error minimizing comment %d: %w", comments[i].CommentID, err)
+		}
+		if res := ghc.dbc.DB.Model(&comments[i]).Update("minimized", true); res.Error != nil {
+			return res.Error
+		}
+	}
+	return nil
+}
+
+func (ghc *GitHubCommenter) queryActivePostedComments(org, repo string, number int, commentType models.CommentType) ([]models.PostedComment, error) {
+	postedComments := make([]models.PostedComment, 0)
+	res := ghc.dbc.DB.
+		Where("org = ? AND repo = ? AND pull_number = ? AND comment_type = ? AND NOT minimized", org, repo, number, commentType).
+		Order("created_at").
+		Find(&postedComments)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return postedComments, nil
+}
+
+// reconcilePostedComments decides how a new comment with the verdict relates to the active comments, oldest first.
+// If the most recent comment has the same verdict it is returned to be updated. Otherwise, every active comment is
+// stale once the new comment is added.
+func reconcilePostedComments(active []models.PostedComment, verdict string) (*models.PostedComment, []models.PostedComment) {
+	if len(active) == 0 {
+		return nil, nil
+	}
+
+	latest := active[len(active)-1]
+	if latest.Verdict == verdict {
+		return &latest, nil
+	}
+	return nil, active
+}
@@ -0,0 +1,47 @@
+package commenter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestReconcilePostedComments(t *testing.T) {
+	older := models.PostedComment{CommentID: 1, SHA: "a", Verdict: "High"}
+	latest := models.PostedComment{CommentID: 2, SHA: "b", Verdict: "Medium"}
+
+	tests := []struct {
+		name           string
+		active         []models.PostedComment
+		verdict        string
+		expectedUpdate *models.PostedComment
+		expectedStale  []models.PostedComment
+	}{
+		{
+			name:    "first comment",
+			verdict: "High",
+		},
+		{
+			name:           "unchanged verdict updates the latest comment",
+			active:         []models.PostedComment{older, latest},
+			verdict:        "Medium",
+			expectedUpdate: &latest,
+		},
+		{
+			name:          "changed verdict makes every comment stale",
+			active:        []models.PostedComment{older, latest},
+			verdict:       "High",
+			expectedStale: []models.PostedComment{older, latest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, stale := reconcilePostedComments(tt.active, tt.verdict)
+			assert.Equal(t, tt.expectedUpdate, update)
+			assert.Equal(t, tt.expectedStale, stale)
+		})
+	}
+}
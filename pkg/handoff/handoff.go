@@ -0,0 +1,147 @@
+// Package handoff builds the on-call handoff report: what changed in the last day, formatted as Markdown for pasting
+// into the TRT handoff doc.
+package handoff
+
+import (
+	"sort"
+	"time"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// DefaultWindow is how far back the handoff report looks.
+	DefaultWindow = 24 * time.Hour
+
+	// staleAfter is how long a loader can go without loading anything before the report flags it. The loaders run
+	// hourly, so this allows for a few slow or failed runs.
+	staleAfter = 6 * time.Hour
+)
+
+// Report is what happened between Since and Until.
+type Report struct {
+	Since time.Time
+	Until time.Time
+
+	// NewRegressions were opened during the window, ResolvedRegressions closed.
+	NewRegressions      []models.TestRegression
+	ResolvedRegressions []models.TestRegression
+
+	// Streams are the payload streams with payloads during the window.
+	Streams []StreamStatus
+
+	Loaders []LoaderStatus
+}
+
+// StreamStatus counts a payload stream's payloads during the window, with its latest.
+type StreamStatus struct {
+	Release      string
+	Stream       string
+	Architecture string
+	Accepted     int
+	Rejected     int
+	LatestTag    string
+	LatestPhase  string
+}
+
+// LoaderStatus is when a loader last loaded data.
+type LoaderStatus struct {
+	Name       string
+	LastLoaded time.Time
+	Stale      bool
+}
+
+// Build builds the handoff report for the window ending at until.
+func Build(dbc *db.DB, until time.Time, window time.Duration) (*Report, error) {
+	report := &Report{Since: until.Add(-window), Until: until}
+
+	res := dbc.DB.Where("first_seen >= ? AND first_seen < ?", report.Since, until).
+		Order("release, test_name").
+		Find(&report.NewRegressions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	res = dbc.DB.Where("status = ? AND closed_at >= ? AND closed_at < ?", models.TestRegressionClosed,
+		report.Since, until).
+		Order("release, test_name").
+		Find(&report.ResolvedRegressions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	tags := make([]models.ReleaseTag, 0)
+	res = dbc.DB.Select("release_tag", "release", "stream", "architecture", "phase", "release_time").
+		Where("release_time >= ? AND release_time < ?", report.Since, until).
+		Find(&tags)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	report.Streams = streamStatuses(tags)
+
+	lastRun, err := query.LastProwJobRunCreated(dbc)
+	if err != nil {
+		return nil, err
+	}
+	var lastTag struct {
+		Max time.Time
+	}
+	if res := dbc.DB.Raw("SELECT MAX(created_at) FROM release_tags").Scan(&lastTag); res.Error != nil {
+		return nil, res.Error
+	}
+	report.Loaders = []LoaderStatus{
+		loaderStatus("prow", lastRun, until),
+		loaderStatus("releases", lastTag.Max, until),
+	}
+
+	return report, nil
+}
+
+// streamStatuses groups the payloads by stream, ordered by release, stream and architecture.
+func streamStatuses(tags []models.ReleaseTag) []StreamStatus {
+	type streamKey struct {
+		release, stream, architecture string
+	}
+	latest := map[streamKey]time.Time{}
+	byStream := map[streamKey]*StreamStatus{}
+	for _, tag := range tags {
+		key := streamKey{tag.Release, tag.Stream, tag.Architecture}
+		status, ok := byStream[key]
+		if !ok {
+			status = &StreamStatus{Release: tag.Release, Stream: tag.Stream, Architecture: tag.Architecture}
+			byStream[key] = status
+		}
+		switch tag.Phase {
+		case "Accepted":
+			status.Accepted++
+		case "Rejected":
+			status.Rejected++
+		}
+		if !ok || tag.ReleaseTime.After(latest[key]) {
+			latest[key] = tag.ReleaseTime
+			status.LatestTag = tag.ReleaseTag
+			status.LatestPhase = tag.Phase
+		}
+	}
+
+	streams := make([]StreamStatus, 0, len(byStream))
+	for _, status := range byStream {
+		streams = append(streams, *status)
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		a, b := streams[i], streams[j]
+		if a.Release != b.Release {
+			return a.Release < b.Release
+		}
+		if a.Stream != b.Stream {
+			return a.Stream < b.Stream
+		}
+		return a.Architecture < b.Architecture
+	})
+	return streams
+}
+
+func loaderStatus(name string, lastLoaded, until time.Time) LoaderStatus {
+	return LoaderStatus{Name: name, LastLoaded: lastLoaded, Stale: until.Sub(lastLoaded) > staleAfter}
+}
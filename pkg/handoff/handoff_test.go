@@ -0,0 +1,66 @@
+package handoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestStreamStatuses(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	tag := func(name, release, stream, phase string, hour int) models.ReleaseTag {
+		return models.ReleaseTag{ReleaseTag: name, Release: release, Stream: stream, Architecture: "amd64",
+			Phase: phase, ReleaseTime: day.Add(time.Duration(hour) * time.Hour)}
+	}
+
+	streams := streamStatuses([]models.ReleaseTag{
+		tag("4.16.0-0.nightly-2", "4.16", "nightly", "Rejected", 8),
+		tag("4.16.0-0.nightly-1", "4.16", "nightly", "Accepted", 2),
+		tag("4.16.0-0.nightly-3", "4.16", "nightly", "Ready", 12),
+		tag("4.16.0-0.ci-1", "4.16", "ci", "Accepted", 4),
+		tag("4.15.0-0.nightly-1", "4.15", "nightly", "Rejected", 1),
+	})
+	assert.Equal(t, []StreamStatus{
+		{Release: "4.15", Stream: "nightly", Architecture: "amd64", Rejected: 1,
+			LatestTag: "4.15.0-0.nightly-1", LatestPhase: "Rejected"},
+		{Release: "4.16", Stream: "ci", Architecture: "amd64", Accepted: 1,
+			LatestTag: "4.16.0-0.ci-1", LatestPhase: "Accepted"},
+		{Release: "4.16", Stream: "nightly", Architecture: "amd64", Accepted: 1, Rejected: 1,
+			LatestTag: "4.16.0-0.nightly-3", LatestPhase: "Ready"},
+	}, streams)
+}
+
+func TestRender(t *testing.T) {
+	until := time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC)
+	report := &Report{
+		Since: until.Add(-DefaultWindow),
+		Until: until,
+		NewRegressions: []models.TestRegression{
+			{Release: "4.16", TestName: "[sig-network] `pods` should work", BasisPassPercentage: 99.5,
+				SamplePassPercentage: 80},
+		},
+		Streams: []StreamStatus{
+			{Release: "4.16", Stream: "nightly", Architecture: "amd64", Accepted: 1, Rejected: 2,
+				LatestTag: "4.16.0-0.nightly-3", LatestPhase: "Rejected"},
+		},
+		Loaders: []LoaderStatus{
+			loaderStatus("prow", until.Add(-42*time.Minute), until),
+			loaderStatus("releases", until.Add(-7*time.Hour), until),
+			loaderStatus("other", time.Time{}, until),
+		},
+	}
+
+	out, err := Render(report)
+	require.NoError(t, err)
+	assert.Contains(t, out, "## Sippy handoff: 2024-03-04 09:00 to 2024-03-05 09:00 UTC\n")
+	assert.Contains(t, out, "### New regressions\n\n- 4.16: `[sig-network] 'pods' should work`, 99.5% to 80.0%\n")
+	assert.Contains(t, out, "### Resolved regressions\n\nNone.\n")
+	assert.Contains(t, out, "| 4.16 | nightly | amd64 | 1 | 2 | 4.16.0-0.nightly-3 (Rejected) |\n")
+	assert.Contains(t, out, "- prow: last loaded 42m0s ago\n")
+	assert.Contains(t, out, "- releases: last loaded 7h0m0s ago **(stale)**\n")
+	assert.Contains(t, out, "- other: **never loaded**\n")
+}
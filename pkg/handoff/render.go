@@ -0,0 +1,71 @@
+package handoff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var handoffTemplate = template.Must(template.New("handoff").Funcs(template.FuncMap{
+	"code":    code,
+	"timeAgo": timeAgo,
+}).Parse(`## Sippy handoff: {{ .Since.UTC.Format "2006-01-02 15:04" }} to {{ .Until.UTC.Format "2006-01-02 15:04" }} UTC
+
+### New regressions
+{{ with .NewRegressions }}
+{{- range . }}
+- {{ .Release }}: {{ code .TestName }}, {{ printf "%.1f" .BasisPassPercentage }}% to {{ printf "%.1f" .SamplePassPercentage }}%
+{{- end }}
+{{- else }}
+None.
+{{- end }}
+
+### Resolved regressions
+{{ with .ResolvedRegressions }}
+{{- range . }}
+- {{ .Release }}: {{ code .TestName }}, regressed since {{ .FirstSeen.UTC.Format "2006-01-02" }}
+{{- end }}
+{{- else }}
+None.
+{{- end }}
+
+### Payloads
+{{ with .Streams }}
+| Release | Stream | Architecture | Accepted | Rejected | Latest |
+| --- | --- | --- | --- | --- | --- |
+{{- range . }}
+| {{ .Release }} | {{ .Stream }} | {{ .Architecture }} | {{ .Accepted }} | {{ .Rejected }} | {{ .LatestTag }} ({{ .LatestPhase }}) |
+{{- end }}
+{{- else }}
+No payloads.
+{{- end }}
+
+### Loaders
+{{ range .Loaders }}
+- {{ .Name }}: {{ if .LastLoaded.IsZero }}**never loaded**{{ else }}last loaded {{ timeAgo .LastLoaded $.Until }}{{ if .Stale }} **(stale)**{{ end }}{{ end }}
+{{- end }}
+`))
+
+// Render renders the report as Markdown.
+func Render(report *Report) (string, error) {
+	var out bytes.Buffer
+	if err := handoffTemplate.Execute(&out, report); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// code formats a test name as inline code, so Markdown in test names isn't rendered.
+func code(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "'") + "`"
+}
+
+func timeAgo(t, now time.Time) string {
+	age := now.Sub(t)
+	if age < time.Minute {
+		return "just now"
+	}
+	return fmt.Sprintf("%s ago", age.Round(time.Minute))
+}
@@ -0,0 +1,107 @@
+// Package history snapshots weekly pass rates of tests and jobs into tables that are never pruned, so trends can be
+// graphed over a year or more without keeping every job run.
+package history
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const week = 7 * 24 * time.Hour
+
+// Snapshot records every complete week before the report end that hasn't been recorded yet, starting with the oldest
+// job run on the first snapshot. The most recently recorded week is recorded again, as job runs may load late.
+func Snapshot(dbc *db.DB, reportEnd time.Time) error {
+	current := WeekStart(reportEnd)
+
+	var from time.Time
+	latest := models.TestWeeklyResult{}
+	if res := dbc.DB.Order("week DESC").Limit(1).Find(&latest); res.Error != nil {
+		return res.Error
+	}
+	if latest.ID != 0 {
+		from = WeekStart(latest.Week)
+	} else {
+		oldest := models.ProwJobRun{}
+		if res := dbc.DB.Order("timestamp").Limit(1).Find(&oldest); res.Error != nil {
+			return res.Error
+		}
+		if oldest.ID == 0 {
+			return nil
+		}
+		from = WeekStart(oldest.Timestamp)
+	}
+
+	for w := from; w.Before(current); w = w.Add(week) {
+		if err := snapshotWeek(dbc, w); err != nil {
+			return err
+		}
+		log.Infof("recorded weekly test and job results for the week of %s", w.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// snapshotWeek replaces the week's test and job results.
+func snapshotWeek(dbc *db.DB, start time.Time) error {
+	params := map[string]interface{}{"start": start, "end": start.Add(week)}
+	return dbc.DB.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Unscoped().Where("week = ?", start).Delete(&models.TestWeeklyResult{}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Unscoped().Where("week = ?", start).Delete(&models.JobWeeklyResult{}); res.Error != nil {
+			return res.Error
+		}
+
+		res := tx.Exec(`
+			INSERT INTO test_weekly_results (created_at, updated_at, release, test_id, variant, week, runs, successes, flakes, failures)
+			SELECT
+				NOW(),
+				NOW(),
+				prow_jobs.release,
+				prow_job_run_tests.test_id,
+				variant,
+				@start,
+				COUNT(*),
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1),
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13),
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12)
+			FROM prow_job_run_tests
+				JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+				JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+				CROSS JOIN UNNEST(ARRAY_APPEND(prow_jobs.variants, 'All')) AS variant
+			WHERE prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+				AND prow_job_run_tests.deleted_at IS NULL
+			GROUP BY prow_jobs.release, prow_job_run_tests.test_id, variant`, params)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		return tx.Exec(`
+			INSERT INTO job_weekly_results (created_at, updated_at, prow_job_id, week, runs, successes, failures, infrastructure_failures)
+			SELECT
+				NOW(),
+				NOW(),
+				prow_job_runs.prow_job_id,
+				@start,
+				COUNT(*),
+				COUNT(*) FILTER (WHERE prow_job_runs.succeeded),
+				COUNT(*) FILTER (WHERE prow_job_runs.failed),
+				COUNT(*) FILTER (WHERE prow_job_runs.infrastructure_failure)
+			FROM prow_job_runs
+			WHERE prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+				AND prow_job_runs.deleted_at IS NULL
+			GROUP BY prow_job_runs.prow_job_id`, params).Error
+	})
+}
+
+// WeekStart returns the Monday, at midnight UTC, of the week the time falls in.
+func WeekStart(t time.Time) time.Time {
+	day := t.UTC().Truncate(24 * time.Hour)
+	daysSinceMonday := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -daysSinceMonday)
+}
@@ -0,0 +1,17 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeekStart(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, WeekStart(monday))
+	assert.Equal(t, monday, WeekStart(time.Date(2024, 3, 6, 13, 30, 0, 0, time.UTC)))
+	assert.Equal(t, monday, WeekStart(time.Date(2024, 3, 10, 23, 59, 59, 0, time.UTC)))
+	// times are compared in UTC
+	assert.Equal(t, monday.AddDate(0, 0, 7), WeekStart(time.Date(2024, 3, 10, 22, 0, 0, 0, time.FixedZone("EST", -5*3600))))
+}
@@ -0,0 +1,54 @@
+package jirafiling
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// IssueClient is the subset of the jira API used to file and update issues.
+type IssueClient interface {
+	Create(issue *jira.Issue) (*jira.Issue, error)
+	Get(key string) (*jira.Issue, error)
+	AddComment(key, body string) error
+}
+
+// bearerTransport authenticates requests with a personal access token.
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+type issueClient struct {
+	client *jira.Client
+}
+
+func NewIssueClient(url, token string) (IssueClient, error) {
+	httpClient := &http.Client{Transport: &bearerTransport{token: token}, Timeout: time.Minute}
+	client, err := jira.NewClient(httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+	return &issueClient{client: client}, nil
+}
+
+func (c *issueClient) Create(issue *jira.Issue) (*jira.Issue, error) {
+	created, _, err := c.client.Issue.Create(issue)
+	return created, err
+}
+
+func (c *issueClient) Get(key string) (*jira.Issue, error) {
+	issue, _, err := c.client.Issue.Get(key, nil)
+	return issue, err
+}
+
+func (c *issueClient) AddComment(key, body string) error {
+	_, _, err := c.client.Issue.AddComment(key, &jira.Comment{Body: body})
+	return err
+}
@@ -0,0 +1,285 @@
+package jirafiling
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// DefaultURL is the jira instance issues are filed in when none is configured.
+const DefaultURL = "https://issues.redhat.com"
+
+const (
+	defaultDays                = 3
+	defaultRegressionThreshold = 10
+	defaultMinRuns             = 10
+	defaultIssueType           = "Bug"
+
+	autoFiledLabel = "sippy-auto-filed"
+
+	// updateInterval limits comments on filed issues to one a day, as loads run far more often
+	updateInterval = 24 * time.Hour
+
+	sampleFailedRuns = 5
+	maxSummaryLength = 255
+)
+
+// Filer files jira issues for tests that have stayed regressed for several days, and comments on the issues it filed
+// while the regression lasts. Tests already linked to an open issue are not filed again.
+type Filer struct {
+	dbc    *db.DB
+	config v1.JiraFilingConfig
+	client IssueClient
+}
+
+// NewFiler returns a filer, or nil if filing isn't configured.
+func NewFiler(dbc *db.DB, config v1.JiraFilingConfig) (*Filer, error) {
+	if len(config.Releases) == 0 {
+		return nil, nil
+	}
+	if config.URL == "" {
+		config.URL = DefaultURL
+	}
+	if config.Days <= 0 {
+		config.Days = defaultDays
+	}
+	if config.RegressionThreshold <= 0 {
+		config.RegressionThreshold = defaultRegressionThreshold
+	}
+	if config.MinRuns <= 0 {
+		config.MinRuns = defaultMinRuns
+	}
+	if config.IssueType == "" {
+		config.IssueType = defaultIssueType
+	}
+
+	token := os.Getenv("JIRA_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("jira filing requires the JIRA_TOKEN environment variable")
+	}
+	client, err := NewIssueClient(config.URL, token)
+	if err != nil {
+		return nil, err
+	}
+	return &Filer{dbc: dbc, config: config, client: client}, nil
+}
+
+// Evaluate updates which tests are regressed in each release, and files or updates issues for those regressed long
+// enough.
+func (f *Filer) Evaluate(now time.Time) error {
+	if f == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, release := range f.config.Releases {
+		if err := f.evaluateRelease(release, now); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", release, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error filing regression issues: %v", errs)
+	}
+	return nil
+}
+
+func (f *Filer) evaluateRelease(release string, now time.Time) error {
+	tests, _, err := api.BuildTestsResults(f.dbc, release, "default", true, false, nil)
+	if err != nil {
+		return err
+	}
+	regressed := regressedTests(tests, f.config.RegressionThreshold, f.config.MinRuns)
+
+	var tracked []models.SustainedRegression
+	if res := f.dbc.DB.Where("release = ?", release).Find(&tracked); res.Error != nil {
+		return res.Error
+	}
+
+	var due []models.SustainedRegression
+	seen := map[string]bool{}
+	for _, sr := range tracked {
+		seen[sr.TestName] = true
+		if _, ok := regressed[sr.TestName]; !ok {
+			// recovered, a later regression is tracked afresh
+			if res := f.dbc.DB.Unscoped().Delete(&models.SustainedRegression{}, sr.ID); res.Error != nil {
+				return res.Error
+			}
+			continue
+		}
+		if now.Sub(sr.FirstRegressed) >= time.Duration(f.config.Days)*24*time.Hour {
+			due = append(due, sr)
+		}
+	}
+	for _, name := range sortedNames(regressed) {
+		if seen[name] {
+			continue
+		}
+		sr := models.SustainedRegression{Release: release, TestName: name, FirstRegressed: now}
+		if res := f.dbc.DB.Create(&sr); res.Error != nil {
+			return res.Error
+		}
+	}
+	log.Infof("%d tests regressed in %s, %d for at least %d days", len(regressed), release, len(due), f.config.Days)
+
+	var unfiled []string
+	for _, sr := range due {
+		if sr.IssueKey == "" {
+			unfiled = append(unfiled, sr.TestName)
+		}
+	}
+	openBugs, err := query.OpenBugsForTests(f.dbc, unfiled)
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		sr := &due[i]
+		test := regressed[sr.TestName]
+		if err := f.fileOrUpdate(sr, test, openBugs[sr.TestName], now); err != nil {
+			log.WithError(err).Errorf("error filing issue for %s regression in %s", sr.TestName, release)
+			continue
+		}
+		if res := f.dbc.DB.Save(sr); res.Error != nil {
+			return res.Error
+		}
+	}
+	return nil
+}
+
+func (f *Filer) fileOrUpdate(sr *models.SustainedRegression, test apitype.Test, openBugs []models.Bug, now time.Time) error {
+	switch {
+	case sr.IssueKey == "" && len(openBugs) > 0:
+		// someone is already tracking it
+		sr.IssueKey = openBugs[0].Key
+		return nil
+	case sr.IssueKey == "":
+		project := f.project(test.JiraComponent)
+		if project == "" {
+			return nil
+		}
+		failedRuns, err := query.RecentFailedRunsForTest(f.dbc, sr.Release, sr.TestName, sampleFailedRuns)
+		if err != nil {
+			return err
+		}
+		created, err := f.client.Create(f.newIssue(project, sr, test, failedRuns, now))
+		if err != nil {
+			return err
+		}
+		log.Infof("filed %s for %s regression in %s", created.Key, sr.TestName, sr.Release)
+		sr.IssueKey, sr.AutoFiled, sr.IssueUpdated = created.Key, true, now
+		return nil
+	case sr.AutoFiled && now.Sub(sr.IssueUpdated) >= updateInterval:
+		issue, err := f.client.Get(sr.IssueKey)
+		if err != nil {
+			return err
+		}
+		// leave closed issues alone, rather than arguing with whoever closed them
+		if issue.Fields != nil && issue.Fields.Status != nil && issue.Fields.Status.StatusCategory.Key == "done" {
+			return nil
+		}
+		if err := f.client.AddComment(sr.IssueKey, updateComment(sr, test, now)); err != nil {
+			return err
+		}
+		sr.IssueUpdated = now
+	}
+	return nil
+}
+
+func (f *Filer) project(component string) string {
+	if project, ok := f.config.ComponentProjects[component]; ok {
+		return project
+	}
+	return f.config.DefaultProject
+}
+
+func (f *Filer) newIssue(project string, sr *models.SustainedRegression, test apitype.Test, failedRuns []models.ProwJobRun, now time.Time) *jira.Issue {
+	summary := fmt.Sprintf("%s regressed in %s: %s", componentOrTest(test), sr.Release, sr.TestName)
+	if len(summary) > maxSummaryLength {
+		summary = summary[:maxSummaryLength-3] + "..."
+	}
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "Test {{%s}} has been regressed in %s for %d days.\n\n", sr.TestName, sr.Release, regressedDays(sr, now))
+	fmt.Fprintf(&description, "* Pass rate: %.1f%% over %d runs, from %.1f%% the previous week\n",
+		test.CurrentPassPercentage, test.CurrentRuns, test.PreviousPassPercentage)
+	if f.config.SippyURL != "" {
+		fmt.Fprintf(&description, "* [Test analysis in Sippy|%s]\n", testAnalysisURL(f.config.SippyURL, sr.Release, sr.TestName))
+	}
+	if len(failedRuns) > 0 {
+		description.WriteString("\nRecent failed runs:\n")
+		for _, run := range failedRuns {
+			fmt.Fprintf(&description, "* [%s|%s] at %s\n", run.ProwJob.Name, run.URL, run.Timestamp.UTC().Format(time.RFC3339))
+		}
+	}
+	description.WriteString("\nThis issue was filed automatically by Sippy, and will be updated while the regression lasts.")
+
+	labels := append([]string{autoFiledLabel}, f.config.Labels...)
+	fields := &jira.IssueFields{
+		Project:     jira.Project{Key: project},
+		Type:        jira.IssueType{Name: f.config.IssueType},
+		Summary:     summary,
+		Description: description.String(),
+		Labels:      labels,
+	}
+	if test.JiraComponent != "" {
+		fields.Components = []*jira.Component{{Name: test.JiraComponent}}
+	}
+	return &jira.Issue{Fields: fields}
+}
+
+func updateComment(sr *models.SustainedRegression, test apitype.Test, now time.Time) string {
+	return fmt.Sprintf("Still regressed after %d days: %.1f%% passing over %d runs, from %.1f%% the previous week.",
+		regressedDays(sr, now), test.CurrentPassPercentage, test.CurrentRuns, test.PreviousPassPercentage)
+}
+
+// regressedTests returns the tests whose pass rate dropped by at least the threshold, with enough runs to tell.
+func regressedTests(tests []apitype.Test, threshold float64, minRuns int) map[string]apitype.Test {
+	regressed := map[string]apitype.Test{}
+	for _, test := range tests {
+		if test.CurrentRuns < minRuns || test.PreviousRuns < minRuns {
+			continue
+		}
+		if test.NetImprovement <= -threshold {
+			regressed[test.Name] = test
+		}
+	}
+	return regressed
+}
+
+func regressedDays(sr *models.SustainedRegression, now time.Time) int {
+	return int(now.Sub(sr.FirstRegressed).Hours() / 24)
+}
+
+func componentOrTest(test apitype.Test) string {
+	if test.JiraComponent != "" {
+		return test.JiraComponent + " test"
+	}
+	return "Test"
+}
+
+func testAnalysisURL(sippyURL, release, testName string) string {
+	return fmt.Sprintf("%s/sippy-ng/tests/%s/analysis?test=%s", strings.TrimSuffix(sippyURL, "/"), release, url.QueryEscape(testName))
+}
+
+// sortedNames orders the tests, so new regressions are recorded in a stable order.
+func sortedNames(tests map[string]apitype.Test) []string {
+	names := make([]string, 0, len(tests))
+	for name := range tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
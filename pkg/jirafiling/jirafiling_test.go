@@ -0,0 +1,95 @@
+package jirafiling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+type fakeClient struct {
+	status   string
+	comments map[string][]string
+}
+
+func (c *fakeClient) Create(issue *jira.Issue) (*jira.Issue, error) {
+	return &jira.Issue{Key: "NEW-1"}, nil
+}
+
+func (c *fakeClient) Get(key string) (*jira.Issue, error) {
+	return &jira.Issue{Key: key, Fields: &jira.IssueFields{Status: &jira.Status{StatusCategory: jira.StatusCategory{Key: c.status}}}}, nil
+}
+
+func (c *fakeClient) AddComment(key, body string) error {
+	c.comments[key] = append(c.comments[key], body)
+	return nil
+}
+
+func TestRegressedTests(t *testing.T) {
+	tests := []apitype.Test{
+		{Name: "regressed", CurrentRuns: 20, PreviousRuns: 20, NetImprovement: -15},
+		{Name: "slightly worse", CurrentRuns: 20, PreviousRuns: 20, NetImprovement: -5},
+		{Name: "too few runs", CurrentRuns: 2, PreviousRuns: 20, NetImprovement: -50},
+	}
+
+	regressed := regressedTests(tests, 10, 10)
+	assert.Equal(t, []string{"regressed"}, sortedNames(regressed))
+}
+
+func TestNewIssue(t *testing.T) {
+	now := time.Date(2023, 11, 5, 12, 0, 0, 0, time.UTC)
+	f := &Filer{config: v1.JiraFilingConfig{IssueType: "Bug", Labels: []string{"trt"}, SippyURL: "https://sippy.example.com/"}}
+	sr := &models.SustainedRegression{Release: "4.15", TestName: "test a", FirstRegressed: now.AddDate(0, 0, -4)}
+	test := apitype.Test{Name: "test a", JiraComponent: "Networking", CurrentRuns: 20, CurrentPassPercentage: 70, PreviousPassPercentage: 95}
+	runs := []models.ProwJobRun{{ProwJob: models.ProwJob{Name: "periodic-e2e"}, URL: "https://prow.example.com/1", Timestamp: now}}
+
+	issue := f.newIssue("OCPBUGS", sr, test, runs, now)
+	assert.Equal(t, "OCPBUGS", issue.Fields.Project.Key)
+	assert.Equal(t, "Networking test regressed in 4.15: test a", issue.Fields.Summary)
+	assert.Equal(t, []string{"sippy-auto-filed", "trt"}, issue.Fields.Labels)
+	require.Len(t, issue.Fields.Components, 1)
+	assert.Equal(t, "Networking", issue.Fields.Components[0].Name)
+	assert.Contains(t, issue.Fields.Description, "Test {{test a}} has been regressed in 4.15 for 4 days.")
+	assert.Contains(t, issue.Fields.Description, "* Pass rate: 70.0% over 20 runs, from 95.0% the previous week")
+	assert.Contains(t, issue.Fields.Description, "[Test analysis in Sippy|https://sippy.example.com/sippy-ng/tests/4.15/analysis?test=test+a]")
+	assert.Contains(t, issue.Fields.Description, "* [periodic-e2e|https://prow.example.com/1] at 2023-11-05T12:00:00Z")
+}
+
+func TestFileOrUpdate(t *testing.T) {
+	now := time.Date(2023, 11, 5, 12, 0, 0, 0, time.UTC)
+	test := apitype.Test{Name: "test a", CurrentRuns: 20, CurrentPassPercentage: 70, PreviousPassPercentage: 95}
+
+	client := &fakeClient{status: "indeterminate", comments: map[string][]string{}}
+	f := &Filer{client: client}
+
+	// an open bug is already linked, so nothing is filed
+	sr := &models.SustainedRegression{Release: "4.15", TestName: "test a", FirstRegressed: now.AddDate(0, 0, -4)}
+	require.NoError(t, f.fileOrUpdate(sr, test, []models.Bug{{Key: "OCPBUGS-1"}}, now))
+	assert.Equal(t, "OCPBUGS-1", sr.IssueKey)
+	assert.False(t, sr.AutoFiled)
+	require.NoError(t, f.fileOrUpdate(sr, test, nil, now))
+	assert.Empty(t, client.comments)
+
+	// issues sippy filed are commented on once a day
+	sr = &models.SustainedRegression{Release: "4.15", TestName: "test a", FirstRegressed: now.AddDate(0, 0, -4),
+		IssueKey: "NEW-1", AutoFiled: true, IssueUpdated: now.Add(-time.Hour)}
+	require.NoError(t, f.fileOrUpdate(sr, test, nil, now))
+	assert.Empty(t, client.comments)
+
+	sr.IssueUpdated = now.AddDate(0, 0, -1)
+	require.NoError(t, f.fileOrUpdate(sr, test, nil, now))
+	assert.Equal(t, []string{"Still regressed after 4 days: 70.0% passing over 20 runs, from 95.0% the previous week."}, client.comments["NEW-1"])
+	assert.Equal(t, now, sr.IssueUpdated)
+
+	// closed issues are left alone
+	client.status = "done"
+	sr.IssueUpdated = now.AddDate(0, 0, -1)
+	require.NoError(t, f.fileOrUpdate(sr, test, nil, now))
+	assert.Len(t, client.comments["NEW-1"], 1)
+}
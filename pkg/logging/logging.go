@@ -0,0 +1,96 @@
+// Package logging configures logrus for sippy's commands, and carries request IDs through contexts so that the log
+// lines of a single API call or loader run, including its database statements, can be found together.
+package logging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	// RequestIDField is the log field holding the request ID.
+	RequestIDField = "requestID"
+
+	// timestampFormat adds millisecond precision to log timestamps, useful for debugging performance.
+	timestampFormat = "2006-01-02T15:04:05.999Z07:00"
+)
+
+// Formats are the supported log formats.
+var Formats = []string{FormatText, FormatJSON}
+
+type requestIDKey struct{}
+
+// Configure sets the level and format of the standard logger.
+func Configure(level, format string) error {
+	lvl, err := log.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("cannot parse log-level: %w", err)
+	}
+	log.SetLevel(lvl)
+
+	switch format {
+	case FormatText:
+		log.SetFormatter(&log.TextFormatter{
+			TimestampFormat: timestampFormat,
+			FullTimestamp:   true,
+		})
+	case FormatJSON:
+		log.SetFormatter(&log.JSONFormatter{
+			TimestampFormat: timestampFormat,
+		})
+	default:
+		return fmt.Errorf("unknown log format %q, expected one of %v", format, Formats)
+	}
+
+	log.Debug("debug logging enabled")
+	return nil
+}
+
+// NewRequestID returns a new random request ID.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string if there isn't one.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns a log entry with the request ID carried by ctx, if any.
+func FromContext(ctx context.Context) *log.Entry {
+	if requestID := RequestID(ctx); requestID != "" {
+		return log.WithField(RequestIDField, requestID)
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// RequestIDHook adds a fixed request ID to every log line, for commands where the whole process is one operation.
+type RequestIDHook struct {
+	RequestID string
+}
+
+func (h RequestIDHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h RequestIDHook) Fire(entry *log.Entry) error {
+	if _, ok := entry.Data[RequestIDField]; !ok {
+		entry.Data[RequestIDField] = h.RequestID
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	defer log.SetFormatter(log.StandardLogger().Formatter)
+	defer log.SetLevel(log.GetLevel())
+
+	require.NoError(t, Configure("debug", FormatJSON))
+	assert.Equal(t, log.DebugLevel, log.GetLevel())
+	assert.IsType(t, &log.JSONFormatter{}, log.StandardLogger().Formatter)
+
+	require.NoError(t, Configure("info", FormatText))
+	assert.IsType(t, &log.TextFormatter{}, log.StandardLogger().Formatter)
+
+	assert.Error(t, Configure("loud", FormatText))
+	assert.Error(t, Configure("info", "xml"))
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Empty(t, RequestID(context.Background()))
+
+	ctx := WithRequestID(context.Background(), "abc-123")
+	assert.Equal(t, "abc-123", RequestID(ctx))
+
+	logger := log.New()
+	logger.SetFormatter(&log.JSONFormatter{})
+	buf := &bytes.Buffer{}
+	logger.SetOutput(buf)
+	entry := FromContext(ctx)
+	entry.Logger = logger
+	entry.Info("hello")
+
+	line := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "abc-123", line[RequestIDField])
+}
+
+func TestRequestIDHook(t *testing.T) {
+	logger := log.New()
+	logger.SetFormatter(&log.JSONFormatter{})
+	buf := &bytes.Buffer{}
+	logger.SetOutput(buf)
+	logger.AddHook(RequestIDHook{RequestID: "load-1"})
+
+	logger.Info("loading")
+	logger.WithField(RequestIDField, "api-2").Info("serving")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	for i, want := range []string{"load-1", "api-2"} {
+		line := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(lines[i], &line))
+		assert.Equal(t, want, line[RequestIDField])
+	}
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+const defaultSMTPPort = 587
+
+// emailSink mails events as plain text.
+type emailSink struct {
+	addr     string
+	from     string
+	to       []string
+	auth     smtp.Auth
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func newEmailSink(config v1.EmailSinkConfig, password string) (*emailSink, error) {
+	if config.SMTP.Host == "" || config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("email notifications require an SMTP host, from and to addresses")
+	}
+	port := config.SMTP.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+
+	e := &emailSink{
+		addr:     fmt.Sprintf("%s:%d", config.SMTP.Host, port),
+		from:     config.From,
+		to:       config.To,
+		sendMail: smtp.SendMail,
+	}
+	if config.SMTP.Username != "" {
+		e.auth = smtp.PlainAuth("", config.SMTP.Username, password, config.SMTP.Host)
+	}
+	return e, nil
+}
+
+func (e *emailSink) send(event Event) error {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("From: %s\r\n", e.from))
+	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(e.to, ", ")))
+	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", event.Title))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n")
+	if event.Message != "" {
+		msg.WriteString(event.Message + "\r\n")
+	}
+	if event.URL != "" {
+		msg.WriteString("\r\n" + event.URL + "\r\n")
+	}
+	return e.sendMail(e.addr, e.auth, e.from, e.to, []byte(msg.String()))
+}
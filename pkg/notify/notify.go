@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+type EventType string
+
+const (
+	// EventRegression is sent when component readiness finds a new regression.
+	EventRegression EventType = "regression"
+
+	// EventPayloadRejected is sent when a payload is rejected.
+	EventPayloadRejected EventType = "payload-rejected"
+
+	// EventLoaderFailed is sent when a data loader encounters errors.
+	EventLoaderFailed EventType = "loader-failed"
+
+	// EventJobSilent is sent when a job that ran regularly stops reporting runs.
+	EventJobSilent EventType = "job-silent"
+
+	// EventQuarantineExpired is sent when a test's quarantine expires.
+	EventQuarantineExpired EventType = "quarantine-expired"
+)
+
+var eventTypes = sets.NewString(string(EventRegression), string(EventPayloadRejected), string(EventLoaderFailed),
+	string(EventJobSilent), string(EventQuarantineExpired))
+
+// Event is something that happened that people may want to know about.
+type Event struct {
+	Type EventType
+
+	// Release, Component and Variants are used to route the event, and are empty when they don't apply.
+	Release   string
+	Component string
+	Variants  []string
+
+	Title   string
+	Message string
+	URL     string
+}
+
+// Notifier sends events to the destinations interested in them.
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// NewNotifier returns a notifier routing events to the sinks in the config, or a notifier that does nothing if
+// there are no routes.
+func NewNotifier(config v1.NotificationConfig) (Notifier, error) {
+	if len(config.Slack) == 0 && len(config.Routes) == 0 {
+		return NewNoopNotifier(), nil
+	}
+	return newRouter(config, sinkOptions{
+		slackAPIURL:   slackAPIURL,
+		slackBotToken: os.Getenv("SLACK_BOT_TOKEN"),
+		smtpPassword:  os.Getenv("SIPPY_SMTP_PASSWORD"),
+	})
+}
+
+type noopNotifier struct{}
+
+// NewNoopNotifier returns a notifier that drops every event.
+func NewNoopNotifier() Notifier {
+	return noopNotifier{}
+}
+
+func (noopNotifier) Notify(Event) error {
+	return nil
+}
+
+// filter matches events against a route's configured event types, releases, components and variants.
+type filter struct {
+	events     sets.String
+	releases   sets.String
+	components sets.String
+	variants   sets.String
+}
+
+func newFilter(config v1.NotificationFilter) (filter, error) {
+	for _, event := range config.Events {
+		if !eventTypes.Has(event) {
+			return filter{}, fmt.Errorf("unknown event type %q, must be one of %v", event, eventTypes.List())
+		}
+	}
+	return filter{
+		events:     sets.NewString(config.Events...),
+		releases:   sets.NewString(config.Releases...),
+		components: sets.NewString(config.Components...),
+		variants:   sets.NewString(config.Variants...),
+	}, nil
+}
+
+// matches returns true if the event matches every non-empty filter. Events without a release, component or variants
+// don't match a filter on them.
+func (f filter) matches(event Event) bool {
+	return matchesSet(f.events, string(event.Type)) &&
+		matchesSet(f.releases, event.Release) &&
+		matchesSet(f.components, event.Component) &&
+		(f.variants.Len() == 0 || f.variants.HasAny(event.Variants...))
+}
+
+func matchesSet(set sets.String, value string) bool {
+	return set.Len() == 0 || set.Has(value)
+}
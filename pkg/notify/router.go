@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// sink delivers events to a destination.
+type sink interface {
+	send(event Event) error
+}
+
+// sinkOptions are the credentials and endpoints shared by sinks, overridden in tests.
+type sinkOptions struct {
+	slackAPIURL   string
+	slackBotToken string
+	smtpPassword  string
+}
+
+type route struct {
+	filter filter
+	sinks  []string
+}
+
+// router sends each event to the sinks of the routes it matches.
+type router struct {
+	sinks  map[string]sink
+	routes []route
+}
+
+func newRouter(config v1.NotificationConfig, opts sinkOptions) (*router, error) {
+	r := &router{sinks: map[string]sink{}}
+
+	for _, sinkConfig := range config.Sinks {
+		if sinkConfig.Name == "" {
+			return nil, fmt.Errorf("notification sinks require a name")
+		}
+		if _, ok := r.sinks[sinkConfig.Name]; ok {
+			return nil, fmt.Errorf("duplicate notification sink %q", sinkConfig.Name)
+		}
+		s, err := newSink(sinkConfig, opts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification sink %q: %w", sinkConfig.Name, err)
+		}
+		r.sinks[sinkConfig.Name] = s
+	}
+
+	for _, routeConfig := range config.Routes {
+		if len(routeConfig.Sinks) == 0 {
+			return nil, fmt.Errorf("notification routes require at least one sink")
+		}
+		for _, name := range routeConfig.Sinks {
+			if _, ok := r.sinks[name]; !ok {
+				return nil, fmt.Errorf("notification route references unknown sink %q", name)
+			}
+		}
+		f, err := newFilter(routeConfig.NotificationFilter)
+		if err != nil {
+			return nil, err
+		}
+		r.routes = append(r.routes, route{filter: f, sinks: routeConfig.Sinks})
+	}
+
+	// each shorthand slack channel is a sink with its own route
+	for i, channel := range config.Slack {
+		s, err := newSlackSink(channel.SlackSinkConfig, opts.slackAPIURL, opts.slackBotToken)
+		if err != nil {
+			return nil, err
+		}
+		f, err := newFilter(channel.NotificationFilter)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("slack[%d]", i)
+		r.sinks[name] = s
+		r.routes = append(r.routes, route{filter: f, sinks: []string{name}})
+	}
+
+	return r, nil
+}
+
+func newSink(config v1.NotificationSinkConfig, opts sinkOptions) (sink, error) {
+	configured := 0
+	var s sink
+	var err error
+	if config.Slack != nil {
+		configured++
+		s, err = newSlackSink(*config.Slack, opts.slackAPIURL, opts.slackBotToken)
+	}
+	if config.Teams != nil {
+		configured++
+		s, err = newTeamsSink(*config.Teams)
+	}
+	if config.Email != nil {
+		configured++
+		s, err = newEmailSink(*config.Email, opts.smtpPassword)
+	}
+	if config.Webhook != nil {
+		configured++
+		s, err = newWebhookSink(*config.Webhook)
+	}
+	if configured != 1 {
+		return nil, fmt.Errorf("exactly one of slack, teams, email or webhook must be set")
+	}
+	return s, err
+}
+
+// Notify sends the event to every sink of every matching route once, returning the errors of any that failed.
+func (r *router) Notify(event Event) error {
+	notified := sets.NewString()
+	var errs []string
+	for _, route := range r.routes {
+		if !route.filter.matches(event) {
+			continue
+		}
+		for _, name := range route.sinks {
+			if notified.Has(name) {
+				continue
+			}
+			notified.Insert(name)
+			if err := r.sinks[name].send(event); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", name, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send %s notification to %d sinks: %s", event.Type, len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+func TestRouter(t *testing.T) {
+	var webhooks []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		webhooks = append(webhooks, payload)
+	}))
+	defer server.Close()
+
+	notifier, err := newRouter(v1.NotificationConfig{
+		Sinks: []v1.NotificationSinkConfig{
+			{Name: "networking-email", Email: &v1.EmailSinkConfig{SMTP: v1.SMTPConfig{Host: "smtp.example.com"}, From: "sippy@example.com", To: []string{"net@example.com"}}},
+			{Name: "ci-webhook", Webhook: &v1.WebhookSinkConfig{URL: server.URL}},
+		},
+		Routes: []v1.NotificationRouteConfig{
+			{NotificationFilter: v1.NotificationFilter{Components: []string{"Networking"}, Variants: []string{"aws", "gcp"}}, Sinks: []string{"networking-email", "ci-webhook"}},
+			{NotificationFilter: v1.NotificationFilter{Events: []string{"regression"}}, Sinks: []string{"ci-webhook"}},
+		},
+	}, sinkOptions{})
+	require.NoError(t, err)
+
+	var mails []string
+	notifier.sinks["networking-email"].(*emailSink).sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mails = append(mails, string(msg))
+		return nil
+	}
+
+	testCases := []struct {
+		name             string
+		event            Event
+		expectedMails    int
+		expectedWebhooks []map[string]interface{}
+	}{
+		{
+			name:          "matching both routes notifies each sink once",
+			event:         Event{Type: EventRegression, Release: "4.15", Component: "Networking", Variants: []string{"aws", "amd64"}, Title: "regressed"},
+			expectedMails: 1,
+			expectedWebhooks: []map[string]interface{}{
+				{"type": "regression", "release": "4.15", "component": "Networking", "variants": []interface{}{"aws", "amd64"}, "title": "regressed"},
+			},
+		},
+		{
+			name:  "variant not routed to the team",
+			event: Event{Type: EventRegression, Component: "Networking", Variants: []string{"azure"}, Title: "regressed"},
+			expectedWebhooks: []map[string]interface{}{
+				{"type": "regression", "component": "Networking", "variants": []interface{}{"azure"}, "title": "regressed"},
+			},
+		},
+		{
+			name:  "no matching route",
+			event: Event{Type: EventLoaderFailed, Title: "failed"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mails, webhooks = nil, nil
+			require.NoError(t, notifier.Notify(tc.event))
+			assert.Len(t, mails, tc.expectedMails)
+			assert.Equal(t, tc.expectedWebhooks, webhooks)
+		})
+	}
+
+	mails = nil
+	require.NoError(t, notifier.Notify(Event{Type: EventPayloadRejected, Component: "Networking", Variants: []string{"gcp"},
+		Title: "rejected", Message: "nightly", URL: "https://example.com"}))
+	require.Len(t, mails, 1)
+	assert.Contains(t, mails[0], "To: net@example.com\r\nSubject: rejected\r\n")
+	assert.Contains(t, mails[0], "\r\n\r\nnightly\r\n\r\nhttps://example.com\r\n")
+}
+
+func TestRouterErrors(t *testing.T) {
+	webhook := &v1.WebhookSinkConfig{URL: "https://example.com"}
+
+	_, err := newRouter(v1.NotificationConfig{
+		Routes: []v1.NotificationRouteConfig{{Sinks: []string{"missing"}}},
+	}, sinkOptions{})
+	assert.ErrorContains(t, err, `unknown sink "missing"`)
+
+	_, err = newRouter(v1.NotificationConfig{
+		Sinks: []v1.NotificationSinkConfig{{Name: "a", Webhook: webhook}, {Name: "a", Webhook: webhook}},
+	}, sinkOptions{})
+	assert.ErrorContains(t, err, `duplicate notification sink "a"`)
+
+	_, err = newRouter(v1.NotificationConfig{
+		Sinks: []v1.NotificationSinkConfig{{Name: "a", Webhook: webhook, Slack: &v1.SlackSinkConfig{WebhookURL: "https://example.com"}}},
+	}, sinkOptions{})
+	assert.ErrorContains(t, err, "exactly one of slack, teams, email or webhook")
+}
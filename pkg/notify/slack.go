@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+const slackAPIURL = "https://slack.com/api/chat.postMessage"
+
+// slackSink posts to a channel with the bot token, or to an incoming webhook.
+type slackSink struct {
+	channel    string
+	webhookURL string
+	apiURL     string
+	botToken   string
+	httpClient *http.Client
+}
+
+func newSlackSink(config v1.SlackSinkConfig, apiURL, botToken string) (*slackSink, error) {
+	if config.Channel == "" && config.WebhookURL == "" {
+		return nil, fmt.Errorf("slack notifications require a channel or webhookURL")
+	}
+	if config.WebhookURL == "" && botToken == "" {
+		return nil, fmt.Errorf("slack notifications to channel %s require the SLACK_BOT_TOKEN environment variable", config.Channel)
+	}
+	return &slackSink{
+		channel:    config.Channel,
+		webhookURL: config.WebhookURL,
+		apiURL:     apiURL,
+		botToken:   botToken,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *slackSink) send(event Event) error {
+	text := slackText(event)
+	if s.webhookURL != "" {
+		return s.post(s.webhookURL, "", map[string]string{"text": text})
+	}
+	return s.post(s.apiURL, s.botToken, map[string]string{"channel": s.channel, "text": text})
+}
+
+func (s *slackSink) post(url, token string, payload map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+
+	// webhooks reply with plain text, the web API replies with JSON reporting errors with a 200
+	if token != "" {
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return err
+		}
+		if !result.OK {
+			return fmt.Errorf("slack returned error: %s", result.Error)
+		}
+	}
+
+	return nil
+}
+
+// slackText formats the event with Slack's mrkdwn.
+func slackText(event Event) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*%s*", event.Title))
+	if event.Message != "" {
+		sb.WriteString("\n" + event.Message)
+	}
+	if event.URL != "" {
+		sb.WriteString(fmt.Sprintf("\n<%s|View details>", event.URL))
+	}
+	return sb.String()
+}
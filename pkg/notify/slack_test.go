@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+func TestSlackSink(t *testing.T) {
+	var posts []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payload["path"] = r.URL.Path
+		payload["auth"] = r.Header.Get("Authorization")
+		posts = append(posts, payload)
+		if r.URL.Path == "/api" {
+			_, _ = w.Write([]byte(`{"ok": true}`))
+		}
+	}))
+	defer server.Close()
+
+	notifier, err := newRouter(v1.NotificationConfig{Slack: []v1.SlackChannelConfig{
+		{SlackSinkConfig: v1.SlackSinkConfig{Channel: "#all"}},
+		{
+			SlackSinkConfig:    v1.SlackSinkConfig{Channel: "#networking"},
+			NotificationFilter: v1.NotificationFilter{Events: []string{"regression"}, Releases: []string{"4.15"}, Components: []string{"Networking"}},
+		},
+		{
+			SlackSinkConfig:    v1.SlackSinkConfig{WebhookURL: server.URL + "/webhook"},
+			NotificationFilter: v1.NotificationFilter{Events: []string{"payload-rejected", "loader-failed"}},
+		},
+	}}, sinkOptions{slackAPIURL: server.URL + "/api", slackBotToken: "token"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		event         Event
+		expectedPosts []map[string]string
+	}{
+		{
+			name:  "regression in a filtered component",
+			event: Event{Type: EventRegression, Release: "4.15", Component: "Networking", Title: "regressed"},
+			expectedPosts: []map[string]string{
+				{"path": "/api", "auth": "Bearer token", "channel": "#all", "text": "*regressed*"},
+				{"path": "/api", "auth": "Bearer token", "channel": "#networking", "text": "*regressed*"},
+			},
+		},
+		{
+			name:  "regression in another component",
+			event: Event{Type: EventRegression, Release: "4.15", Component: "Storage", Title: "regressed"},
+			expectedPosts: []map[string]string{
+				{"path": "/api", "auth": "Bearer token", "channel": "#all", "text": "*regressed*"},
+			},
+		},
+		{
+			name:  "rejected payload",
+			event: Event{Type: EventPayloadRejected, Release: "4.15", Title: "rejected", Message: "nightly", URL: "https://example.com"},
+			expectedPosts: []map[string]string{
+				{"path": "/api", "auth": "Bearer token", "channel": "#all", "text": "*rejected*\nnightly\n<https://example.com|View details>"},
+				{"path": "/webhook", "auth": "", "text": "*rejected*\nnightly\n<https://example.com|View details>"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			posts = nil
+			require.NoError(t, notifier.Notify(tc.event))
+			assert.Equal(t, tc.expectedPosts, posts)
+		})
+	}
+}
+
+func TestSlackSinkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+	}))
+	defer server.Close()
+
+	channel := func(name string, events ...string) v1.NotificationConfig {
+		return v1.NotificationConfig{Slack: []v1.SlackChannelConfig{{
+			SlackSinkConfig:    v1.SlackSinkConfig{Channel: name},
+			NotificationFilter: v1.NotificationFilter{Events: events},
+		}}}
+	}
+
+	notifier, err := newRouter(channel("#missing"), sinkOptions{slackAPIURL: server.URL, slackBotToken: "token"})
+	require.NoError(t, err)
+	assert.ErrorContains(t, notifier.Notify(Event{Type: EventLoaderFailed}), "channel_not_found")
+
+	_, err = newRouter(channel("#all"), sinkOptions{slackAPIURL: server.URL})
+	assert.Error(t, err, "channels require a bot token")
+
+	_, err = newRouter(channel("#all", "unknown"), sinkOptions{slackAPIURL: server.URL, slackBotToken: "token"})
+	assert.Error(t, err, "unknown event types are rejected")
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+// teamsSink posts events to a Microsoft Teams webhook as adaptive cards.
+type teamsSink struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+func newTeamsSink(config v1.TeamsSinkConfig) (*teamsSink, error) {
+	if config.WebhookURL == "" {
+		return nil, fmt.Errorf("teams notifications require a webhookURL")
+	}
+	return &teamsSink{webhookURL: config.WebhookURL, httpClient: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string                   `json:"$schema"`
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Body    []map[string]interface{} `json:"body"`
+	Actions []map[string]interface{} `json:"actions,omitempty"`
+}
+
+func (t *teamsSink) send(event Event) error {
+	body, err := json.Marshal(teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     teamsCard(event),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.httpClient.Post(t.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("teams returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// teamsCard lays the event out as a title, facts for its attributes, and a line per line of the message, so the
+// tests of a regression summary are listed rather than run together.
+func teamsCard(event Event) adaptiveCard {
+	card := adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]interface{}{
+			{"type": "TextBlock", "text": event.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+		},
+	}
+
+	var facts []map[string]string
+	for _, fact := range []struct{ title, value string }{
+		{"Release", event.Release},
+		{"Component", event.Component},
+		{"Variants", strings.Join(event.Variants, ", ")},
+	} {
+		if fact.value != "" {
+			facts = append(facts, map[string]string{"title": fact.title, "value": fact.value})
+		}
+	}
+	if len(facts) > 0 {
+		card.Body = append(card.Body, map[string]interface{}{"type": "FactSet", "facts": facts})
+	}
+
+	for i, line := range strings.Split(event.Message, "\n") {
+		if line == "" {
+			continue
+		}
+		block := map[string]interface{}{"type": "TextBlock", "text": line, "wrap": true}
+		if i > 0 {
+			block["spacing"] = "None"
+		}
+		card.Body = append(card.Body, block)
+	}
+
+	if event.URL != "" {
+		card.Actions = []map[string]interface{}{{"type": "Action.OpenUrl", "title": "View details", "url": event.URL}}
+	}
+	return card
+}
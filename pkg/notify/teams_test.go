@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+func TestTeamsSink(t *testing.T) {
+	var posted map[string]interface{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink, err := newTeamsSink(v1.TeamsSinkConfig{WebhookURL: server.URL})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.send(Event{
+		Type:      EventRegression,
+		Release:   "4.15",
+		Component: "Networking",
+		Variants:  []string{"aws", "amd64"},
+		Title:     "2 new Networking regressions in 4.15",
+		Message:   "test a (aws amd64 ovn)\ntest b (aws amd64 ovn)",
+		URL:       "https://sippy.example.com",
+	}))
+
+	expected := `{
+		"type": "message",
+		"attachments": [{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": {
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type": "AdaptiveCard",
+				"version": "1.4",
+				"body": [
+					{"type": "TextBlock", "text": "2 new Networking regressions in 4.15", "weight": "Bolder", "size": "Medium", "wrap": true},
+					{"type": "FactSet", "facts": [
+						{"title": "Release", "value": "4.15"},
+						{"title": "Component", "value": "Networking"},
+						{"title": "Variants", "value": "aws, amd64"}
+					]},
+					{"type": "TextBlock", "text": "test a (aws amd64 ovn)", "wrap": true},
+					{"type": "TextBlock", "text": "test b (aws amd64 ovn)", "wrap": true, "spacing": "None"}
+				],
+				"actions": [{"type": "Action.OpenUrl", "title": "View details", "url": "https://sippy.example.com"}]
+			}
+		}]
+	}`
+	var expectedPost map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(expected), &expectedPost))
+	assert.Equal(t, expectedPost, posted)
+
+	status = http.StatusBadRequest
+	assert.ErrorContains(t, sink.send(Event{Type: EventLoaderFailed, Title: "failed"}), "status 400")
+
+	_, err = newTeamsSink(v1.TeamsSinkConfig{})
+	assert.Error(t, err)
+}
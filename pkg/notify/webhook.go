@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+// webhookSink posts events as JSON, for integrations sippy doesn't support directly.
+type webhookSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+func newWebhookSink(config v1.WebhookSinkConfig) (*webhookSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook notifications require a url")
+	}
+	return &webhookSink{url: config.URL, httpClient: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+type webhookPayload struct {
+	Type      EventType `json:"type"`
+	Release   string    `json:"release,omitempty"`
+	Component string    `json:"component,omitempty"`
+	Variants  []string  `json:"variants,omitempty"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message,omitempty"`
+	URL       string    `json:"url,omitempty"`
+}
+
+func (w *webhookSink) send(event Event) error {
+	body, err := json.Marshal(webhookPayload{
+		Type:      event.Type,
+		Release:   event.Release,
+		Component: event.Component,
+		Variants:  event.Variants,
+		Title:     event.Title,
+		Message:   event.Message,
+		URL:       event.URL,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.httpClient.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Package querytemplates runs a fixed set of parameterized analytical queries for power users who would otherwise need
+// one-off database access. Callers only choose a template and its parameters, never SQL. Queries run in read-only
+// transactions with a statement timeout, and return a bounded number of rows.
+package querytemplates
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+const (
+	DefaultRowLimit = 1000
+	MaxRowLimit     = 10000
+
+	// Timeout bounds how long a query may run, both in the database and waiting on it.
+	Timeout = 30 * time.Second
+)
+
+const (
+	stringParameter  = "string"
+	integerParameter = "integer"
+)
+
+// Template describes a query callers may run.
+type Template struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parameters  []Parameter `json:"parameters"`
+
+	// sql is the query, with its parameters and the report end as @report_end. It must not end in a semicolon, as
+	// it's wrapped to limit its rows.
+	sql string
+}
+
+// Parameter describes one of a template's parameters. Integer parameters are bounded by Minimum and Maximum.
+type Parameter struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Minimum     int         `json:"minimum,omitempty"`
+	Maximum     int         `json:"maximum,omitempty"`
+}
+
+// Result is a template's rows, and whether there were more than the row limit.
+type Result struct {
+	Template  string                   `json:"template"`
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	RowCount  int                      `json:"row_count"`
+	Truncated bool                     `json:"truncated"`
+}
+
+// invalidQuery refuses a query that names an unknown template or has invalid parameters, as opposed to failing to run.
+func invalidQuery(format string, args ...interface{}) error {
+	return api.NewProblem(http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+// Templates returns every template, sorted by name.
+func Templates() []Template {
+	list := make([]Template, len(allTemplates))
+	copy(list, allTemplates)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+func lookup(name string) (Template, bool) {
+	for _, t := range allTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// Run runs the named template with its JSON parameters, returning at most rowLimit rows.
+func Run(ctx context.Context, dbc *db.DB, name string, parameters json.RawMessage, rowLimit int, reportEnd time.Time) (Result, error) {
+	t, ok := lookup(name)
+	if !ok {
+		return Result{}, invalidQuery("unknown template %q", name)
+	}
+	if rowLimit == 0 {
+		rowLimit = DefaultRowLimit
+	}
+	if rowLimit < 1 || rowLimit > MaxRowLimit {
+		return Result{}, invalidQuery("limit must be between 1 and %d", MaxRowLimit)
+	}
+
+	params, err := t.decodeParameters(parameters)
+	if err != nil {
+		return Result{}, err
+	}
+	params["report_end"] = reportEnd
+	// one extra row tells us whether the results were truncated
+	params["row_limit"] = rowLimit + 1
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	tx := dbc.DB.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	if tx.Error != nil {
+		return Result{}, tx.Error
+	}
+	defer tx.Rollback()
+	// SET doesn't take bind parameters
+	if res := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", Timeout.Milliseconds())); res.Error != nil {
+		return Result{}, res.Error
+	}
+
+	rows, err := tx.Raw("SELECT * FROM ("+t.sql+") AS template_query LIMIT @row_limit", params).Rows()
+	if err != nil {
+		return Result{}, err
+	}
+	defer rows.Close()
+
+	result := Result{Template: t.Name, Rows: make([]map[string]interface{}, 0)}
+	if result.Columns, err = rows.Columns(); err != nil {
+		return Result{}, err
+	}
+	for rows.Next() {
+		if len(result.Rows) == rowLimit {
+			result.Truncated = true
+			break
+		}
+		row := map[string]interface{}{}
+		if err := tx.ScanRows(rows, &row); err != nil {
+			return Result{}, err
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return Result{}, err
+	}
+	result.RowCount = len(result.Rows)
+	return result, nil
+}
+
+// decodeParameters checks the parameters against the template's, rejecting unknown ones and defaulting missing ones.
+func (t Template) decodeParameters(parameters json.RawMessage) (map[string]interface{}, error) {
+	raw := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(parameters)) > 0 {
+		if err := json.Unmarshal(parameters, &raw); err != nil {
+			return nil, invalidQuery("invalid parameters for %s: %s", t.Name, err)
+		}
+	}
+
+	params := map[string]interface{}{}
+	for _, p := range t.Parameters {
+		value, ok := raw[p.Name]
+		delete(raw, p.Name)
+		if !ok || bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			if p.Required {
+				return nil, invalidQuery("%s is required", p.Name)
+			}
+			params[p.Name] = p.Default
+			continue
+		}
+
+		switch p.Type {
+		case stringParameter:
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil || s == "" {
+				return nil, invalidQuery("%s must be a non-empty string", p.Name)
+			}
+			params[p.Name] = s
+		case integerParameter:
+			var i int
+			if err := json.Unmarshal(value, &i); err != nil || i < p.Minimum || i > p.Maximum {
+				return nil, invalidQuery("%s must be an integer between %d and %d", p.Name, p.Minimum, p.Maximum)
+			}
+			params[p.Name] = i
+		}
+	}
+	for name := range raw {
+		return nil, invalidQuery("unknown parameter %q for %s", name, t.Name)
+	}
+	return params, nil
+}
@@ -0,0 +1,102 @@
+package querytemplates
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/api"
+)
+
+func TestTemplates(t *testing.T) {
+	names := map[string]bool{}
+	for _, tmpl := range Templates() {
+		assert.False(t, names[tmpl.Name], "duplicate template %s", tmpl.Name)
+		names[tmpl.Name] = true
+		assert.NotEmpty(t, tmpl.Description, tmpl.Name)
+		assert.False(t, strings.HasSuffix(strings.TrimSpace(tmpl.sql), ";"), "%s must not end in a semicolon", tmpl.Name)
+		for _, p := range tmpl.Parameters {
+			assert.Contains(t, tmpl.sql, "@"+p.Name, "%s doesn't use parameter %s", tmpl.Name, p.Name)
+		}
+	}
+
+	// templates must serialize, as they're listed to callers
+	_, err := json.Marshal(Templates())
+	require.NoError(t, err)
+}
+
+func TestDecodeParameters(t *testing.T) {
+	testCases := []struct {
+		name           string
+		parameters     string
+		expectedParams map[string]interface{}
+		expectedError  string
+	}{
+		{
+			name:           "defaults optional parameters",
+			parameters:     `{"release": "4.16"}`,
+			expectedParams: map[string]interface{}{"release": "4.16", "stream": "nightly", "architecture": "amd64", "days": 14},
+		},
+		{
+			name:           "takes parameters",
+			parameters:     `{"release": "4.16", "stream": "ci", "days": 30}`,
+			expectedParams: map[string]interface{}{"release": "4.16", "stream": "ci", "architecture": "amd64", "days": 30},
+		},
+		{
+			name:          "requires parameters",
+			parameters:    `{"stream": "ci"}`,
+			expectedError: "release is required",
+		},
+		{
+			name:          "rejects unknown parameters",
+			parameters:    `{"release": "4.16", "sql": "DROP TABLE tests"}`,
+			expectedError: `unknown parameter "sql"`,
+		},
+		{
+			name:          "bounds integers",
+			parameters:    `{"release": "4.16", "days": 365}`,
+			expectedError: "days must be an integer between 1 and 90",
+		},
+		{
+			name:          "checks types",
+			parameters:    `{"release": 4.16}`,
+			expectedError: "release must be a non-empty string",
+		},
+	}
+
+	tmpl, ok := lookup("payload_phases")
+	require.True(t, ok)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, err := tmpl.decodeParameters(json.RawMessage(tc.parameters))
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assertInvalidQuery(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedParams, params)
+		})
+	}
+}
+
+func TestRunInvalid(t *testing.T) {
+	_, err := Run(context.Background(), nil, "raw_sql", nil, 0, time.Now())
+	assertInvalidQuery(t, err)
+
+	_, err = Run(context.Background(), nil, "payload_phases", json.RawMessage(`{"release": "4.16"}`), MaxRowLimit+1, time.Now())
+	assertInvalidQuery(t, err)
+}
+
+func assertInvalidQuery(t *testing.T, err error) {
+	var problem api.Problem
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+}
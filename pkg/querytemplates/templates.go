@@ -0,0 +1,133 @@
+package querytemplates
+
+var (
+	releaseParameter = Parameter{
+		Name:        "release",
+		Type:        stringParameter,
+		Description: "OpenShift release, i.e. 4.16",
+		Required:    true,
+	}
+	testParameter = Parameter{
+		Name:        "test",
+		Type:        stringParameter,
+		Description: "Full test name",
+		Required:    true,
+	}
+	jobParameter = Parameter{
+		Name:        "job",
+		Type:        stringParameter,
+		Description: "Full prow job name",
+		Required:    true,
+	}
+)
+
+func daysParameter(defaultDays, maxDays int) Parameter {
+	return Parameter{
+		Name:        "days",
+		Type:        integerParameter,
+		Description: "How many days before the report end to include",
+		Default:     defaultDays,
+		Minimum:     1,
+		Maximum:     maxDays,
+	}
+}
+
+// allTemplates are the templates available, new templates must only read, and should filter on indexed columns.
+var allTemplates = []Template{
+	{
+		Name:        "test_daily_results",
+		Description: "A test's results in the release's jobs for each day.",
+		Parameters:  []Parameter{releaseParameter, testParameter, daysParameter(14, 90)},
+		sql: `
+			SELECT
+				DATE(prow_job_runs.timestamp) AS date,
+				COUNT(*) AS runs,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1) AS successes,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13) AS flakes,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12) AS failures
+			FROM prow_job_run_tests
+				JOIN tests ON tests.id = prow_job_run_tests.test_id
+				JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+				JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_jobs.release = @release AND tests.name = @test
+				AND prow_job_runs.timestamp BETWEEN @report_end - make_interval(days => @days) AND @report_end
+				AND prow_job_run_tests.deleted_at IS NULL
+			GROUP BY DATE(prow_job_runs.timestamp)
+			ORDER BY date`,
+	},
+	{
+		Name:        "test_failures_by_job",
+		Description: "The jobs a test ran in, in the release, with its runs and failures, most failures first.",
+		Parameters:  []Parameter{releaseParameter, testParameter, daysParameter(7, 30)},
+		sql: `
+			SELECT
+				prow_jobs.name AS job,
+				COUNT(*) AS runs,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12) AS failures
+			FROM prow_job_run_tests
+				JOIN tests ON tests.id = prow_job_run_tests.test_id
+				JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+				JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_jobs.release = @release AND tests.name = @test
+				AND prow_job_runs.timestamp BETWEEN @report_end - make_interval(days => @days) AND @report_end
+				AND prow_job_run_tests.deleted_at IS NULL
+			GROUP BY prow_jobs.name
+			ORDER BY failures DESC, runs DESC, job`,
+	},
+	{
+		Name:        "job_daily_results",
+		Description: "A job's runs for each day, with how many succeeded, failed and failed due to infrastructure.",
+		Parameters:  []Parameter{jobParameter, daysParameter(14, 90)},
+		sql: `
+			SELECT
+				DATE(prow_job_runs.timestamp) AS date,
+				COUNT(*) AS runs,
+				COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS successes,
+				COUNT(*) FILTER (WHERE prow_job_runs.failed) AS failures,
+				COUNT(*) FILTER (WHERE prow_job_runs.infrastructure_failure) AS infrastructure_failures
+			FROM prow_job_runs
+				JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_jobs.name = @job
+				AND prow_job_runs.timestamp BETWEEN @report_end - make_interval(days => @days) AND @report_end
+				AND prow_job_runs.deleted_at IS NULL
+			GROUP BY DATE(prow_job_runs.timestamp)
+			ORDER BY date`,
+	},
+	{
+		Name:        "job_failing_tests",
+		Description: "The tests that failed in a job's runs, most failures first.",
+		Parameters:  []Parameter{jobParameter, daysParameter(7, 30)},
+		sql: `
+			SELECT
+				tests.name AS test,
+				COUNT(*) AS runs,
+				COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12) AS failures
+			FROM prow_job_run_tests
+				JOIN tests ON tests.id = prow_job_run_tests.test_id
+				JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+				JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_jobs.name = @job
+				AND prow_job_runs.timestamp BETWEEN @report_end - make_interval(days => @days) AND @report_end
+				AND prow_job_run_tests.deleted_at IS NULL
+			GROUP BY tests.name
+			HAVING COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12) > 0
+			ORDER BY failures DESC, test`,
+	},
+	{
+		Name:        "payload_phases",
+		Description: "The payloads of a release's stream and architecture, newest first, with whether they were accepted.",
+		Parameters: []Parameter{
+			releaseParameter,
+			{Name: "stream", Type: stringParameter, Description: "Payload stream, i.e. nightly or ci", Default: "nightly"},
+			{Name: "architecture", Type: stringParameter, Description: "Payload architecture, i.e. amd64", Default: "amd64"},
+			daysParameter(14, 90),
+		},
+		sql: `
+			SELECT release_tag, release_time, phase, forced
+			FROM release_tags
+			WHERE release = @release AND stream = @stream AND architecture = @architecture
+				AND release_time BETWEEN @report_end - make_interval(days => @days) AND @report_end
+				AND deleted_at IS NULL
+			ORDER BY release_time DESC`,
+	},
+}
@@ -0,0 +1,117 @@
+package regressiondetection
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+// LoadBaselines reads and validates the test baselines file.
+func LoadBaselines(path string) ([]v1.TestBaseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading test baselines: %w", err)
+	}
+	config := v1.TestBaselinesConfig{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing test baselines %s: %w", path, err)
+	}
+	if err := ValidateBaselines(config.Baselines); err != nil {
+		return nil, fmt.Errorf("invalid test baselines %s: %w", path, err)
+	}
+	return config.Baselines, nil
+}
+
+// ValidateBaselines checks each baseline names a release and test, has a pass percentage, and isn't declared twice for
+// the same variants.
+func ValidateBaselines(baselines []v1.TestBaseline) error {
+	var errs []string
+	seen := map[string]bool{}
+	for i, b := range baselines {
+		if b.Release == "" || b.TestName == "" {
+			errs = append(errs, fmt.Sprintf("baseline %d must have a release and testName", i))
+			continue
+		}
+		if b.PassPercentage <= 0 || b.PassPercentage > 100 {
+			errs = append(errs, fmt.Sprintf("%s baseline of %q must have a passPercentage above 0 and at most 100",
+				b.Release, b.TestName))
+		}
+		for _, variant := range b.Variants {
+			if strings.TrimSpace(variant) == "" {
+				errs = append(errs, fmt.Sprintf("%s baseline of %q has an empty variant", b.Release, b.TestName))
+				break
+			}
+		}
+		key := b.Release + "/" + regressionKey(b.TestName, b.Variants)
+		if seen[key] {
+			errs = append(errs, fmt.Sprintf("%s baseline of %q for variants %v is declared more than once",
+				b.Release, b.TestName, b.Variants))
+		}
+		seen[key] = true
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// regressionKey identifies a regression of a test, in all jobs or only in those with the variants.
+func regressionKey(testName string, variants []string) string {
+	if len(variants) == 0 {
+		return testName
+	}
+	sorted := append([]string{}, variants...)
+	sort.Strings(sorted)
+	return testName + " [" + strings.Join(sorted, ",") + "]"
+}
+
+// baselineRegression checks whether the test passed significantly less than its baseline this week, with a one-sided
+// binomial test, counting flakes as passes. Tests with fewer than minRuns runs aren't checked.
+func baselineRegression(test apitype.Test, baseline float64, confidence, minRuns int) (detection, bool) {
+	if test.CurrentRuns < minRuns {
+		return detection{}, false
+	}
+	pValue, worse := belowBaseline(test.CurrentSuccesses+test.CurrentFlakes, test.CurrentRuns, baseline, confidence)
+	if !worse {
+		return detection{}, false
+	}
+	test.PreviousPassPercentage = baseline
+	test.PreviousRuns = 0
+	return detection{test: test, pValue: pValue, baseline: true}, true
+}
+
+// belowBaseline returns the probability of at most passes of runs passing if the test passed at the baseline pass
+// percentage, and whether that's unlikely at the percent confidence. Pass rates at or above the baseline are never
+// significant.
+func belowBaseline(passes, runs int, baseline float64, confidence int) (float64, bool) {
+	if runs == 0 || float64(passes)*100 >= baseline*float64(runs) {
+		return 0, false
+	}
+	pValue := binomialCDF(passes, runs, baseline/100)
+	return pValue, pValue < 1-float64(confidence)/100
+}
+
+// binomialCDF is the probability of at most k successes in n trials that each succeed with probability p.
+func binomialCDF(k, n int, p float64) float64 {
+	if p >= 1 {
+		if k >= n {
+			return 1
+		}
+		return 0
+	}
+	cdf := 0.0
+	lnN, _ := math.Lgamma(float64(n + 1))
+	for i := 0; i <= k; i++ {
+		lnI, _ := math.Lgamma(float64(i + 1))
+		lnNI, _ := math.Lgamma(float64(n - i + 1))
+		cdf += math.Exp(lnN - lnI - lnNI + float64(i)*math.Log(p) + float64(n-i)*math.Log1p(-p))
+	}
+	return math.Min(cdf, 1)
+}
@@ -0,0 +1,327 @@
+// Package regressiondetection records tests whose pass rate dropped significantly from the previous week, using
+// Fisher's exact test rather than a fixed pass percentage drop, so tests with few runs aren't regressed by a couple of
+// unlucky failures and tests with many runs are regressed by smaller, real drops.
+package regressiondetection
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+const (
+	defaultConfidence = 95
+	defaultMinRuns    = 10
+
+	// currentDays is how far back the jobs a regressed test failed in are looked for, the current week of the test
+	// report.
+	currentDays = 7
+)
+
+// tierWeights weight a regression's pass percentage drop by the most important tier of the jobs the test failed in.
+// Jobs without a tier weigh 1.
+var tierWeights = map[string]float64{
+	models.JobTierBlocking:     3,
+	models.JobTierInforming:    2,
+	"":                         1,
+	models.JobTierExperimental: 0.5,
+}
+
+// detection is a test that regressed, with the p-value of its comparison to the previous week or its baseline, and the
+// most important tier of the jobs it failed in. Variants are set when it regressed from a baseline declared for them.
+type detection struct {
+	test     apitype.Test
+	pValue   float64
+	tier     string
+	variants []string
+	baseline bool
+}
+
+// Detect opens regressions for the tests of the configured releases that are significantly worse than the previous
+// week, or than their declared baselines, updates those still regressed, and closes those that aren't.
+func Detect(dbc *db.DB, config v1.RegressionDetectionConfig, now time.Time) error {
+	if config.Confidence <= 0 {
+		config.Confidence = defaultConfidence
+	}
+	if config.Confidence >= 100 {
+		return fmt.Errorf("regression detection confidence must be below 100, got %d", config.Confidence)
+	}
+	if config.MinRuns <= 0 {
+		config.MinRuns = defaultMinRuns
+	}
+
+	baselines := map[string][]v1.TestBaseline{}
+	if config.BaselinesFile != "" {
+		all, err := LoadBaselines(config.BaselinesFile)
+		if err != nil {
+			return err
+		}
+		for _, b := range all {
+			baselines[b.Release] = append(baselines[b.Release], b)
+		}
+	}
+
+	var errs []error
+	for _, release := range config.Releases {
+		if err := detectRelease(dbc, release, config, baselines[release], now); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", release, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error detecting test regressions: %v", errs)
+	}
+	return nil
+}
+
+func detectRelease(dbc *db.DB, release string, config v1.RegressionDetectionConfig, baselines []v1.TestBaseline,
+	now time.Time) error {
+	tests, _, err := api.BuildTestsResults(dbc, release, "default", true, false, nil)
+	if err != nil {
+		return err
+	}
+	allJobBaselines := map[string]float64{}
+	for _, b := range baselines {
+		if len(b.Variants) == 0 {
+			allJobBaselines[b.TestName] = b.PassPercentage
+		}
+	}
+	regressed := regressedTests(tests, config.Confidence, config.MinRuns, allJobBaselines)
+	for _, b := range baselines {
+		if len(b.Variants) == 0 {
+			continue
+		}
+		if err := detectVariantBaseline(dbc, release, b, config, regressed); err != nil {
+			return err
+		}
+	}
+	if err := setTestIDs(dbc, regressed); err != nil {
+		return err
+	}
+	if err := setFailedTiers(dbc, release, regressed, now); err != nil {
+		return err
+	}
+
+	var opened, closed int
+	err = dbc.DB.Transaction(func(tx *gorm.DB) error {
+		var open []models.TestRegression
+		if res := tx.Where("release = ? AND status = ?", release, models.TestRegressionOpen).Find(&open); res.Error != nil {
+			return res.Error
+		}
+
+		seen := map[string]bool{}
+		for i := range open {
+			tr := &open[i]
+			key := regressionKey(tr.TestName, tr.Variants)
+			seen[key] = true
+			if d, ok := regressed[key]; ok {
+				updateRegression(tr, d, now)
+			} else {
+				closedAt := now
+				tr.Status, tr.ClosedAt = models.TestRegressionClosed, &closedAt
+				closed++
+			}
+			if res := tx.Save(tr); res.Error != nil {
+				return res.Error
+			}
+		}
+
+		for _, key := range sortedNames(regressed) {
+			if seen[key] {
+				continue
+			}
+			d := regressed[key]
+			tr := &models.TestRegression{
+				Release:   release,
+				TestID:    uint(d.test.ID),
+				TestName:  d.test.Name,
+				Variants:  append(pq.StringArray{}, d.variants...),
+				Status:    models.TestRegressionOpen,
+				FirstSeen: now,
+			}
+			updateRegression(tr, d, now)
+			if res := tx.Create(tr); res.Error != nil {
+				return res.Error
+			}
+			opened++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("%d tests regressed in %s, %d newly and %d recovered", len(regressed), release, opened, closed)
+	return nil
+}
+
+func updateRegression(tr *models.TestRegression, d detection, now time.Time) {
+	tr.LastSeen = now
+	tr.BasisPassPercentage = d.test.PreviousPassPercentage
+	tr.BasisRuns = d.test.PreviousRuns
+	tr.SamplePassPercentage = d.test.CurrentPassPercentage
+	tr.SampleRuns = d.test.CurrentRuns
+	tr.PValue = d.pValue
+	tr.Baseline = d.baseline
+	tr.Tier = d.tier
+	tr.Severity = severity(d)
+}
+
+// severity is the regressed test's pass percentage drop, weighted by the tier of the jobs it failed in.
+func severity(d detection) float64 {
+	weight, ok := tierWeights[d.tier]
+	if !ok {
+		weight = 1
+	}
+	return (d.test.PreviousPassPercentage - d.test.CurrentPassPercentage) * weight
+}
+
+// setTestIDs sets the regressed tests' IDs, the test report only numbers its rows.
+func setTestIDs(dbc *db.DB, regressed map[string]detection) error {
+	if len(regressed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(regressed))
+	for _, d := range regressed {
+		names = append(names, d.test.Name)
+	}
+	tests := make([]models.Test, 0)
+	if res := dbc.DB.Select("id, name").Where("name IN ?", names).Find(&tests); res.Error != nil {
+		return res.Error
+	}
+	ids := map[string]uint{}
+	for _, test := range tests {
+		ids[test.Name] = test.ID
+	}
+	for key, d := range regressed {
+		d.test.ID = int(ids[d.test.Name])
+		regressed[key] = d
+	}
+	return nil
+}
+
+// setFailedTiers sets the most important tier of the jobs each regressed test failed in this week.
+func setFailedTiers(dbc *db.DB, release string, regressed map[string]detection, now time.Time) error {
+	if len(regressed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(regressed))
+	for _, d := range regressed {
+		names = append(names, d.test.Name)
+	}
+
+	type testTier struct {
+		TestName string
+		Tier     string
+	}
+	tiers := make([]testTier, 0)
+	res := dbc.DB.Table("prow_job_run_tests").
+		Select("DISTINCT tests.name AS test_name, prow_jobs.tier").
+		Joins("JOIN tests ON tests.id = prow_job_run_tests.test_id").
+		Joins("JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_jobs.release = ? AND tests.name IN ?", release, names).
+		Where("prow_job_run_tests.status = ?", int(sippyprocessingv1.TestStatusFailure)).
+		Where("prow_job_runs.timestamp >= ?", now.AddDate(0, 0, -currentDays)).
+		Scan(&tiers)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	failedTiers := map[string][]string{}
+	for _, t := range tiers {
+		failedTiers[t.TestName] = append(failedTiers[t.TestName], t.Tier)
+	}
+	for key, d := range regressed {
+		d.tier = mostImportantTier(failedTiers[d.test.Name])
+		regressed[key] = d
+	}
+	return nil
+}
+
+// mostImportantTier returns the tier that weighs most, or no tier when there are none.
+func mostImportantTier(tiers []string) string {
+	if len(tiers) == 0 {
+		return ""
+	}
+	most := tiers[0]
+	for _, tier := range tiers[1:] {
+		if tierWeights[tier] > tierWeights[most] {
+			most = tier
+		}
+	}
+	return most
+}
+
+// regressedTests returns the tests with enough runs in both weeks whose pass rate dropped significantly, keyed by
+// name. Tests with a baseline are compared to it instead of the previous week.
+func regressedTests(tests []apitype.Test, confidence, minRuns int, baselines map[string]float64) map[string]detection {
+	regressed := map[string]detection{}
+	for _, test := range tests {
+		if baseline, ok := baselines[test.Name]; ok {
+			if d, ok := baselineRegression(test, baseline, confidence, minRuns); ok {
+				regressed[test.Name] = d
+			}
+			continue
+		}
+		if test.CurrentRuns < minRuns || test.PreviousRuns < minRuns {
+			continue
+		}
+		if pValue, ok := significantRegression(test, confidence); ok {
+			regressed[test.Name] = detection{test: test, pValue: pValue}
+		}
+	}
+	return regressed
+}
+
+// significantRegression compares the current week's failures to the previous week's, counting flakes as passes like
+// component readiness does.
+func significantRegression(test apitype.Test, confidence int) (float64, bool) {
+	return api.SignificantlyWorse(test.PreviousFailures, test.PreviousSuccesses+test.PreviousFlakes,
+		test.CurrentFailures, test.CurrentSuccesses+test.CurrentFlakes, confidence)
+}
+
+// detectVariantBaseline compares the test's results in the jobs with all of the baseline's variants to the baseline,
+// adding it to the regressed tests if it passed significantly less.
+func detectVariantBaseline(dbc *db.DB, release string, b v1.TestBaseline, config v1.RegressionDetectionConfig,
+	regressed map[string]detection) error {
+	fil := &filter.Filter{
+		Items:        []filter.FilterItem{{Field: "name", Operator: filter.OperatorEquals, Value: b.TestName}},
+		LinkOperator: filter.LinkOperatorAnd,
+	}
+	for _, variant := range b.Variants {
+		fil.Items = append(fil.Items, filter.FilterItem{Field: "variants", Operator: filter.OperatorContains, Value: variant})
+	}
+	tests, _, err := api.BuildTestsResults(dbc, release, "default", true, false, fil)
+	if err != nil {
+		return err
+	}
+	for _, test := range tests {
+		if d, ok := baselineRegression(test, b.PassPercentage, config.Confidence, config.MinRuns); ok {
+			d.variants = b.Variants
+			regressed[regressionKey(test.Name, b.Variants)] = d
+		}
+	}
+	return nil
+}
+
+// sortedNames orders the tests, so new regressions are recorded in a stable order.
+func sortedNames(tests map[string]detection) []string {
+	names := make([]string, 0, len(tests))
+	for name := range tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,236 @@
+package regressiondetection
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestSignificantRegression(t *testing.T) {
+	tests := []struct {
+		name        string
+		test        apitype.Test
+		significant bool
+	}{
+		{
+			name: "large drop with many runs",
+			test: apitype.Test{
+				PreviousSuccesses: 98, PreviousFailures: 2,
+				CurrentSuccesses: 70, CurrentFailures: 30,
+			},
+			significant: true,
+		},
+		{
+			name: "small drop with many runs",
+			test: apitype.Test{
+				PreviousSuccesses: 990, PreviousFailures: 10,
+				CurrentSuccesses: 960, CurrentFailures: 40,
+			},
+			significant: true,
+		},
+		{
+			name: "same drop with few runs",
+			test: apitype.Test{
+				PreviousSuccesses: 10, PreviousFailures: 0,
+				CurrentSuccesses: 8, CurrentFailures: 2,
+			},
+			significant: false,
+		},
+		{
+			name: "improvement",
+			test: apitype.Test{
+				PreviousSuccesses: 70, PreviousFailures: 30,
+				CurrentSuccesses: 98, CurrentFailures: 2,
+			},
+			significant: false,
+		},
+		{
+			name: "flakes count as passes",
+			test: apitype.Test{
+				PreviousSuccesses: 98, PreviousFailures: 2,
+				CurrentSuccesses: 70, CurrentFlakes: 28, CurrentFailures: 2,
+			},
+			significant: false,
+		},
+		{
+			name:        "no runs",
+			test:        apitype.Test{PreviousSuccesses: 10},
+			significant: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pValue, significant := significantRegression(tt.test, 95)
+			assert.Equal(t, tt.significant, significant, "p-value %f", pValue)
+		})
+	}
+}
+
+func TestRegressedTests(t *testing.T) {
+	tests := []apitype.Test{
+		{Name: "regressed", PreviousRuns: 100, PreviousSuccesses: 98, PreviousFailures: 2, CurrentRuns: 100, CurrentSuccesses: 70, CurrentFailures: 30},
+		{Name: "too few runs", PreviousRuns: 100, PreviousSuccesses: 100, CurrentRuns: 5, CurrentFailures: 5},
+		{Name: "steady", PreviousRuns: 100, PreviousSuccesses: 90, PreviousFailures: 10, CurrentRuns: 100, CurrentSuccesses: 89, CurrentFailures: 11},
+	}
+
+	regressed := regressedTests(tests, 95, 10, nil)
+	assert.Equal(t, []string{"regressed"}, sortedNames(regressed))
+	assert.Less(t, regressed["regressed"].pValue, 0.05)
+	assert.False(t, regressed["regressed"].baseline)
+}
+
+func TestRegressedTestsWithBaselines(t *testing.T) {
+	tests := []apitype.Test{
+		// steady week over week, but well below what its owners expect
+		{Name: "below baseline", PreviousRuns: 100, PreviousSuccesses: 80, PreviousFailures: 20, CurrentRuns: 100,
+			CurrentSuccesses: 80, CurrentFailures: 20, CurrentPassPercentage: 80},
+		// dropped from the previous week, but within the pass rate its owners accept
+		{Name: "within baseline", PreviousRuns: 100, PreviousSuccesses: 98, PreviousFailures: 2, CurrentRuns: 100,
+			CurrentSuccesses: 70, CurrentFailures: 30, CurrentPassPercentage: 70},
+		{Name: "too few runs", CurrentRuns: 5, CurrentFailures: 5},
+	}
+
+	regressed := regressedTests(tests, 95, 10, map[string]float64{
+		"below baseline":  98,
+		"within baseline": 65,
+		"too few runs":    99,
+	})
+	assert.Equal(t, []string{"below baseline"}, sortedNames(regressed))
+	d := regressed["below baseline"]
+	assert.True(t, d.baseline)
+	assert.Equal(t, 98.0, d.test.PreviousPassPercentage)
+	assert.Zero(t, d.test.PreviousRuns)
+	assert.InDelta(t, 18, severity(d), 0.001)
+}
+
+func TestBelowBaseline(t *testing.T) {
+	tests := []struct {
+		name        string
+		passes      int
+		runs        int
+		baseline    float64
+		significant bool
+	}{
+		{name: "far below", passes: 80, runs: 100, baseline: 98, significant: true},
+		{name: "slightly below with few runs", passes: 9, runs: 10, baseline: 95, significant: false},
+		{name: "slightly below with many runs", passes: 9300, runs: 10000, baseline: 95, significant: true},
+		{name: "at baseline", passes: 95, runs: 100, baseline: 95, significant: false},
+		{name: "above baseline", passes: 100, runs: 100, baseline: 95, significant: false},
+		{name: "fails a test expected to always pass", passes: 99, runs: 100, baseline: 100, significant: true},
+		{name: "no runs", baseline: 95, significant: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pValue, significant := belowBaseline(tt.passes, tt.runs, tt.baseline, 95)
+			assert.Equal(t, tt.significant, significant, "p-value %f", pValue)
+		})
+	}
+}
+
+func TestBinomialCDF(t *testing.T) {
+	assert.InDelta(t, 0.5, binomialCDF(0, 1, 0.5), 1e-9)
+	assert.InDelta(t, 0.6875, binomialCDF(2, 4, 0.5), 1e-9)
+	assert.InDelta(t, 1, binomialCDF(10, 10, 0.3), 1e-9)
+	assert.InDelta(t, 0, binomialCDF(9, 10, 1), 1e-9)
+}
+
+func TestValidateBaselines(t *testing.T) {
+	assert.NoError(t, ValidateBaselines([]v1.TestBaseline{
+		{Release: "4.16", TestName: "test", PassPercentage: 95},
+		{Release: "4.16", TestName: "test", Variants: []string{"metal-ipi"}, PassPercentage: 80},
+		{Release: "4.15", TestName: "test", PassPercentage: 95},
+	}))
+
+	err := ValidateBaselines([]v1.TestBaseline{
+		{TestName: "test", PassPercentage: 95},
+		{Release: "4.16", TestName: "test", PassPercentage: 120},
+		{Release: "4.16", TestName: "other", Variants: []string{"ovn", "metal-ipi"}, PassPercentage: 80},
+		{Release: "4.16", TestName: "other", Variants: []string{"metal-ipi", "ovn"}, PassPercentage: 85},
+		{Release: "4.16", TestName: "empty", Variants: []string{" "}, PassPercentage: 85},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "baseline 0 must have a release and testName")
+	assert.Contains(t, err.Error(), "passPercentage above 0 and at most 100")
+	assert.Contains(t, err.Error(), "declared more than once")
+	assert.Contains(t, err.Error(), "has an empty variant")
+}
+
+func TestLoadBaselines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baselines.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`baselines:
+- release: "4.16"
+  testName: "[sig-network] pods should be reachable"
+  variants: [metal-ipi]
+  passPercentage: 90
+  owner: Networking
+  reason: flaky metal networking, tracked in OCPBUGS-1234
+`), 0600))
+
+	baselines, err := LoadBaselines(path)
+	require.NoError(t, err)
+	assert.Equal(t, []v1.TestBaseline{{
+		Release:        "4.16",
+		TestName:       "[sig-network] pods should be reachable",
+		Variants:       []string{"metal-ipi"},
+		PassPercentage: 90,
+		Owner:          "Networking",
+		Reason:         "flaky metal networking, tracked in OCPBUGS-1234",
+	}}, baselines)
+
+	_, err = LoadBaselines(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestSeverity(t *testing.T) {
+	test := apitype.Test{PreviousPassPercentage: 98, CurrentPassPercentage: 88}
+	tests := []struct {
+		name             string
+		failedTiers      []string
+		expectedTier     string
+		expectedSeverity float64
+	}{
+		{
+			name:             "blocking outweighs the others",
+			failedTiers:      []string{models.JobTierExperimental, models.JobTierBlocking, "", models.JobTierInforming},
+			expectedTier:     models.JobTierBlocking,
+			expectedSeverity: 30,
+		},
+		{
+			name:             "informing",
+			failedTiers:      []string{"", models.JobTierInforming},
+			expectedTier:     models.JobTierInforming,
+			expectedSeverity: 20,
+		},
+		{
+			name:             "jobs without a tier outweigh experimental jobs",
+			failedTiers:      []string{models.JobTierExperimental, ""},
+			expectedTier:     "",
+			expectedSeverity: 10,
+		},
+		{
+			name:             "only experimental jobs",
+			failedTiers:      []string{models.JobTierExperimental},
+			expectedTier:     models.JobTierExperimental,
+			expectedSeverity: 5,
+		},
+		{
+			name:             "no failed jobs found",
+			expectedTier:     "",
+			expectedSeverity: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier := mostImportantTier(tt.failedTiers)
+			assert.Equal(t, tt.expectedTier, tier)
+			assert.InDelta(t, tt.expectedSeverity, severity(detection{test: test, tier: tier}), 0.001)
+		})
+	}
+}
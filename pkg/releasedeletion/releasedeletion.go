@@ -0,0 +1,149 @@
+// Package releasedeletion removes the data of an end-of-life release: its jobs, their runs and test results, its
+// payloads, the errors loading them, and the regressions, silent jobs, funnels and weekly history computed from them.
+// Runs and payloads are deleted in batches, each in its own statement, so no single transaction cascades to millions of
+// test results.
+package releasedeletion
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// DefaultBatchSize is the default number of job runs, or release tags, deleted at a time. A run has a few thousand
+// test results, which are deleted with it.
+const DefaultBatchSize = 100
+
+// Result counts the rows of each kind deleted, or that would be with a dry run. Rows deleted by foreign key cascades,
+// such as test results, aren't counted.
+type Result struct {
+	Jobs            int64 `json:"jobs"`
+	JobRuns         int64 `json:"job_runs"`
+	ReleaseTags     int64 `json:"release_tags"`
+	Regressions     int64 `json:"regressions"`
+	FunnelDays      int64 `json:"funnel_days"`
+	WeeklyResults   int64 `json:"weekly_results"`
+	DailyResults    int64 `json:"daily_results"`
+	SustainedIssues int64 `json:"sustained_issues"`
+	SilentJobs      int64 `json:"silent_jobs"`
+	LoaderErrors    int64 `json:"loader_errors"`
+}
+
+// Delete removes the release's data. With dryRun, it only counts what would be deleted.
+func Delete(dbc *db.DB, release string, batchSize int, dryRun bool) (*Result, error) {
+	if release == "" {
+		return nil, fmt.Errorf("release is required")
+	}
+	if batchSize < 1 {
+		return nil, fmt.Errorf("batch size must be positive")
+	}
+	if dryRun {
+		return count(dbc, release)
+	}
+
+	result := &Result{}
+	var err error
+
+	// runs first, so deleting the jobs doesn't cascade to all of them at once
+	result.JobRuns, err = deleteInBatches(dbc, "job runs", &models.ProwJobRun{}, `SELECT prow_job_runs.id FROM prow_job_runs
+		JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+		WHERE prow_jobs.release = ? LIMIT ?`, release, batchSize)
+	if err != nil {
+		return result, err
+	}
+
+	result.ReleaseTags, err = deleteInBatches(dbc, "release tags", &models.ReleaseTag{},
+		`SELECT id FROM release_tags WHERE release = ? LIMIT ?`, release, batchSize)
+	if err != nil {
+		return result, err
+	}
+
+	res := dbc.DB.Unscoped().
+		Where("prow_job_id IN (SELECT id FROM prow_jobs WHERE release = ?)", release).
+		Delete(&models.JobWeeklyResult{})
+	if res.Error != nil {
+		return result, res.Error
+	}
+	result.WeeklyResults += res.RowsAffected
+
+	res = dbc.DB.Unscoped().Where("release = ?", release).Delete(&models.ProwJob{})
+	if res.Error != nil {
+		return result, res.Error
+	}
+	result.Jobs = res.RowsAffected
+
+	for _, table := range []struct {
+		model interface{}
+		count *int64
+	}{
+		{&models.TestWeeklyResult{}, &result.WeeklyResults},
+		{&models.TestDailyResult{}, &result.DailyResults},
+		{&models.FunnelDay{}, &result.FunnelDays},
+		{&models.TestRegression{}, &result.Regressions},
+		{&models.SustainedRegression{}, &result.SustainedIssues},
+		{&models.SilentJob{}, &result.SilentJobs},
+		{&models.LoaderError{}, &result.LoaderErrors},
+	} {
+		res := dbc.DB.Unscoped().Where("release = ?", release).Delete(table.model)
+		if res.Error != nil {
+			return result, res.Error
+		}
+		*table.count += res.RowsAffected
+	}
+
+	log.Infof("deleted release %s: %+v", release, *result)
+	return result, nil
+}
+
+// deleteInBatches deletes the rows whose IDs the query selects, batchSize at a time, until there are none left.
+func deleteInBatches(dbc *db.DB, name string, model interface{}, query, release string, batchSize int) (int64, error) {
+	var deleted int64
+	for {
+		ids := make([]uint, 0, batchSize)
+		if res := dbc.DB.Raw(query, release, batchSize).Scan(&ids); res.Error != nil {
+			return deleted, res.Error
+		}
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+
+		res := dbc.DB.Unscoped().Delete(model, ids)
+		if res.Error != nil {
+			return deleted, res.Error
+		}
+		deleted += res.RowsAffected
+		log.Infof("deleted %d %s of release %s", deleted, name, release)
+	}
+}
+
+func count(dbc *db.DB, release string) (*Result, error) {
+	result := &Result{}
+	for _, c := range []struct {
+		query string
+		count *int64
+	}{
+		{"SELECT COUNT(*) FROM prow_jobs WHERE release = ?", &result.Jobs},
+		{`SELECT COUNT(*) FROM prow_job_runs JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+			WHERE prow_jobs.release = ?`, &result.JobRuns},
+		{"SELECT COUNT(*) FROM release_tags WHERE release = ?", &result.ReleaseTags},
+		{"SELECT COUNT(*) FROM test_regressions WHERE release = ?", &result.Regressions},
+		{"SELECT COUNT(*) FROM funnel_days WHERE release = ?", &result.FunnelDays},
+		{"SELECT COUNT(*) FROM test_weekly_results WHERE release = ?", &result.WeeklyResults},
+		{"SELECT COUNT(*) FROM test_daily_results WHERE release = ?", &result.DailyResults},
+		{`SELECT COUNT(*) FROM job_weekly_results JOIN prow_jobs ON prow_jobs.id = job_weekly_results.prow_job_id
+			WHERE prow_jobs.release = ?`, &result.WeeklyResults},
+		{"SELECT COUNT(*) FROM sustained_regressions WHERE release = ?", &result.SustainedIssues},
+		{"SELECT COUNT(*) FROM silent_jobs WHERE release = ?", &result.SilentJobs},
+		{"SELECT COUNT(*) FROM loader_errors WHERE release = ?", &result.LoaderErrors},
+	} {
+		var n int64
+		if res := dbc.DB.Raw(c.query, release).Scan(&n); res.Error != nil {
+			return nil, res.Error
+		}
+		*c.count += n
+	}
+	return result, nil
+}
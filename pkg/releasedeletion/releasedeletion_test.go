@@ -0,0 +1,15 @@
+package releasedeletion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteValidatesArguments(t *testing.T) {
+	_, err := Delete(nil, "", DefaultBatchSize, true)
+	assert.EqualError(t, err, "release is required")
+
+	_, err = Delete(nil, "4.11", 0, false)
+	assert.EqualError(t, err, "batch size must be positive")
+}
@@ -0,0 +1,87 @@
+package seed
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// Load inserts the data set into the database, which must not have any jobs yet, so seeded data is never mixed with
+// real data.
+func Load(dbc *db.DB, ds Dataset) error {
+	var jobs int64
+	if res := dbc.DB.Model(&models.ProwJob{}).Count(&jobs); res.Error != nil {
+		return res.Error
+	}
+	if jobs > 0 {
+		return fmt.Errorf("database already has %d jobs, seed a fresh database", jobs)
+	}
+
+	return dbc.DB.Transaction(func(tx *gorm.DB) error {
+		suite := models.Suite{Name: suiteName}
+		if res := tx.Create(&suite); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.CreateInBatches(ds.Tests, dbc.BatchSize); res.Error != nil {
+			return res.Error
+		}
+
+		var runs, results int
+		for _, job := range ds.Jobs {
+			if res := tx.Omit("JobRuns").Create(&job.Job); res.Error != nil {
+				return res.Error
+			}
+			if len(job.Runs) == 0 {
+				continue
+			}
+
+			jobRuns := make([]models.ProwJobRun, len(job.Runs))
+			jobRunTests := make([][]*models.ProwJobRunTest, len(job.Runs))
+			for i, run := range job.Runs {
+				for _, result := range run.Results {
+					jobRunTests[i] = append(jobRunTests[i], &models.ProwJobRunTest{
+						TestID:   ds.Tests[result.Test].ID,
+						TestName: ds.Tests[result.Test].Name,
+						SuiteID:  &suite.ID,
+						Status:   int(result.Status),
+					})
+				}
+				jobRuns[i] = run.Run
+				jobRuns[i].ProwJobID = job.Job.ID
+				jobRuns[i].SetTestSummary(jobRunTests[i])
+			}
+			if res := tx.Omit("ProwJob").CreateInBatches(jobRuns, dbc.BatchSize); res.Error != nil {
+				return res.Error
+			}
+
+			runTests := make([]models.ProwJobRunTest, 0)
+			for i, tests := range jobRunTests {
+				for _, test := range tests {
+					test.ProwJobRunID = jobRuns[i].ID
+					runTests = append(runTests, *test)
+				}
+			}
+			if len(runTests) > 0 {
+				if res := tx.Omit("ProwJobRun", "Test", "Suite").CreateInBatches(runTests, dbc.BatchSize); res.Error != nil {
+					return res.Error
+				}
+			}
+			runs += len(jobRuns)
+			results += len(runTests)
+		}
+
+		if len(ds.ReleaseTags) > 0 {
+			if res := tx.CreateInBatches(ds.ReleaseTags, dbc.BatchSize); res.Error != nil {
+				return res.Error
+			}
+		}
+
+		log.Infof("seeded %d jobs, %d job runs, %d tests, %d test results and %d payloads",
+			len(ds.Jobs), runs, len(ds.Tests), results, len(ds.ReleaseTags))
+		return nil
+	})
+}
@@ -0,0 +1,341 @@
+// Package seed generates realistic synthetic jobs, job runs, test results and payloads, and loads them into a fresh
+// database, so developers and integration tests can run sippy without GCP credentials. Generation is deterministic
+// for a given random seed.
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/failureclassification"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+const (
+	suiteName = "openshift-tests"
+
+	// probabilities a run fails before any tests run, or while installing
+	infrastructureFailureRate = 0.03
+	installFailureRate        = 0.05
+	// flakeRate is how often a failing test passes on retry
+	flakeRate = 0.3
+	// payloadAcceptRate is how often a payload is accepted
+	payloadAcceptRate = 0.8
+)
+
+var (
+	platforms     = []string{"aws", "gcp", "azure", "metal", "vsphere"}
+	networks      = []string{"ovn", "sdn"}
+	jobKinds      = []string{"e2e", "e2e-upgrade", "e2e-serial"}
+	buildClusters = []string{"build01", "build02", "build03", "build04", "build05"}
+	repositories  = []string{"origin", "installer", "cluster-version-operator", "machine-config-operator", "ovn-kubernetes"}
+	sigs          = []string{"sig-network", "sig-storage", "sig-node", "sig-apps", "sig-api-machinery", "sig-cli", "sig-auth"}
+	featureGates  = []string{"GatewayAPI", "MachineAPIMigration", "VolumeGroupSnapshot"}
+
+	// clusterProfiles and cloudRegions are the cluster profile and regions of each platform's jobs.
+	clusterProfiles = map[string]string{"aws": "aws", "gcp": "gcp", "azure": "azure4", "metal": "equinix-ocp-metal",
+		"vsphere": "vsphere-2"}
+	cloudRegions = map[string][]string{
+		"aws":     {"us-east-1", "us-east-2", "us-west-2"},
+		"gcp":     {"us-central1", "us-east1"},
+		"azure":   {"centralus", "eastus"},
+		"vsphere": {"us-east"},
+	}
+
+	installTests = []string{
+		testidentification.NewInstallTestName,
+		testidentification.InstallConfigTestName,
+		testidentification.NewInfrastructureTestName,
+		testidentification.InstallBootstrapTestName,
+		testidentification.InstallOtherTestName,
+	}
+	upgradeTests = []string{
+		testidentification.UpgradeTestName,
+		testidentification.CVOAcknowledgesUpgradeTest,
+		testidentification.OperatorsUpgradedTest,
+		testidentification.MachineConfigsUpgradedTest,
+	}
+)
+
+// Options control how much data is generated.
+type Options struct {
+	Releases []string
+	// Jobs is the number of periodic jobs per release.
+	Jobs int
+	// Tests is the number of e2e tests, in addition to the install and upgrade tests.
+	Tests int
+	// Days of history to generate before the end time.
+	Days int
+	// RunsPerDay is how many times each job runs a day.
+	RunsPerDay int
+	// PayloadsPerDay is how many nightly payloads each release gets a day.
+	PayloadsPerDay int
+	Seed           int64
+}
+
+// DefaultOptions are a small data set, quick to load, with a couple of weeks of history so reports comparing weeks
+// have data.
+func DefaultOptions() Options {
+	return Options{
+		Releases:       []string{"4.16", "4.15"},
+		Jobs:           12,
+		Tests:          200,
+		Days:           21,
+		RunsPerDay:     3,
+		PayloadsPerDay: 2,
+		Seed:           1,
+	}
+}
+
+func (o Options) Validate() error {
+	if len(o.Releases) == 0 {
+		return fmt.Errorf("at least one release is required")
+	}
+	if o.Jobs < 1 || o.Tests < 1 || o.Days < 1 || o.RunsPerDay < 1 || o.PayloadsPerDay < 0 {
+		return fmt.Errorf("jobs, tests, days and runs per day must be positive, and payloads per day not negative")
+	}
+	return nil
+}
+
+// Dataset is the generated data. Test results refer to tests by their index in Tests, as IDs are only known once
+// loaded.
+type Dataset struct {
+	Tests       []models.Test
+	Jobs        []Job
+	ReleaseTags []models.ReleaseTag
+}
+
+// Job is a job and its runs.
+type Job struct {
+	Job  models.ProwJob
+	Runs []Run
+}
+
+// Run is a job run and its test results.
+type Run struct {
+	Run     models.ProwJobRun
+	Results []Result
+}
+
+// Result is a test's status in a run.
+type Result struct {
+	Test   int
+	Status v1.TestStatus
+}
+
+// testProfile is how often a test fails, before and after the regression point when it regresses.
+type testProfile struct {
+	failureRate   float64
+	regressedRate float64
+}
+
+// Generate generates the data set, with job runs and payloads in the days before end.
+func Generate(opts Options, end time.Time) Dataset {
+	rnd := rand.New(rand.NewSource(opts.Seed)) //nolint:gosec
+	ds := Dataset{}
+
+	testIndex := map[string]int{}
+	addTest := func(name string) int {
+		if i, ok := testIndex[name]; ok {
+			return i
+		}
+		test := models.Test{Name: name}
+		test.SetTags()
+		ds.Tests = append(ds.Tests, test)
+		testIndex[name] = len(ds.Tests) - 1
+		return testIndex[name]
+	}
+	for _, name := range installTests {
+		addTest(name)
+	}
+	for _, name := range upgradeTests {
+		addTest(name)
+	}
+
+	// most tests are reliable, some flaky, and a few regress in the last week so there's something to find
+	e2eTests := make([]int, 0, opts.Tests)
+	profiles := map[int]testProfile{}
+	for i := 0; i < opts.Tests; i++ {
+		sig := sigs[rnd.Intn(len(sigs))]
+		tags := ""
+		if i%10 == 0 {
+			tags = fmt.Sprintf(" [OCPFeatureGate:%s]", featureGates[(i/10)%len(featureGates)])
+		}
+		index := addTest(fmt.Sprintf("[%s] synthetic test %04d should pass%s [Suite:openshift/conformance/parallel]",
+			sig, i, tags))
+		e2eTests = append(e2eTests, index)
+
+		profile := testProfile{failureRate: 0.002}
+		switch r := rnd.Float64(); {
+		case r < 0.03:
+			profile.regressedRate = 0.3
+		case r < 0.13:
+			profile.failureRate = 0.05
+		}
+		if profile.regressedRate == 0 {
+			profile.regressedRate = profile.failureRate
+		}
+		profiles[index] = profile
+	}
+
+	start := end.Add(-time.Duration(opts.Days) * 24 * time.Hour)
+	regressedAt := end.Add(-7 * 24 * time.Hour)
+	runID := int64(1700000000000000000)
+	pullRequests := 0
+	for _, release := range opts.Releases {
+		for j := 0; j < opts.Jobs; j++ {
+			platform, network := platforms[j%len(platforms)], networks[(j/len(platforms))%len(networks)]
+			kind := jobKinds[(j/(len(platforms)*len(networks)))%len(jobKinds)]
+			name := fmt.Sprintf("periodic-ci-openshift-release-master-nightly-%s-%s-%s-%s", release, kind, platform, network)
+			if j >= len(platforms)*len(networks)*len(jobKinds) {
+				name = fmt.Sprintf("%s-%d", name, j)
+			}
+			upgrade := kind == "e2e-upgrade"
+
+			job := Job{Job: models.ProwJob{
+				Kind:     models.ProwPeriodic,
+				Name:     name,
+				Release:  release,
+				Variants: []string{platform, network, "amd64", "ha"},
+				VariantDimensions: models.VariantDimensions{
+					"Platform":     platform,
+					"Network":      network,
+					"Architecture": "amd64",
+					"Topology":     "ha",
+				},
+				Timeout:        4 * time.Hour,
+				ClusterProfile: clusterProfiles[platform],
+			}}
+			if upgrade {
+				job.Job.Variants = append(job.Job.Variants, "upgrade-minor")
+				job.Job.VariantDimensions["Upgrade"] = "upgrade-minor"
+			}
+			if kind == "e2e-serial" {
+				job.Job.Variants = append(job.Job.Variants, "serial")
+				job.Job.VariantDimensions["Suite"] = "serial"
+			}
+
+			interval := 24 * time.Hour / time.Duration(opts.RunsPerDay)
+			for ts := start.Add(time.Duration(rnd.Int63n(int64(interval)))); ts.Before(end); ts = ts.Add(interval) {
+				runID++
+				run := generateRun(rnd, ts, upgrade, ts.After(regressedAt), e2eTests, profiles, testIndex)
+				run.Run.URL = fmt.Sprintf("https://prow.ci.openshift.org/view/gs/test-platform-results/logs/%s/%d", name, runID)
+				run.Run.Cluster = buildClusters[rnd.Intn(len(buildClusters))]
+				if regions := cloudRegions[platform]; len(regions) > 0 {
+					run.Run.CloudRegion = regions[runID%int64(len(regions))]
+				}
+				job.Runs = append(job.Runs, run)
+			}
+			ds.Jobs = append(ds.Jobs, job)
+		}
+
+		ds.ReleaseTags = append(ds.ReleaseTags, generateReleaseTags(rnd, release, start, end, opts.PayloadsPerDay, &pullRequests)...)
+	}
+	return ds
+}
+
+func generateRun(rnd *rand.Rand, ts time.Time, upgrade, regressed bool, e2eTests []int, profiles map[int]testProfile,
+	testIndex map[string]int) Run {
+	run := Run{Run: models.ProwJobRun{
+		Timestamp: ts,
+		Duration:  time.Duration(90+rnd.Intn(60)) * time.Minute,
+	}}
+
+	if rnd.Float64() < infrastructureFailureRate {
+		run.Run.Failed, run.Run.InfrastructureFailure = true, true
+		run.Run.OverallResult = v1.JobInfrastructureFailure
+		run.Run.FailureClassification = string(failureclassification.InfraFailure)
+		return run
+	}
+
+	if rnd.Float64() < installFailureRate {
+		// installs fail at one of the stages, and no tests run
+		stage := installTests[1+rnd.Intn(len(installTests)-1)]
+		run.Results = append(run.Results,
+			Result{Test: testIndex[testidentification.NewInstallTestName], Status: v1.TestStatusFailure},
+			Result{Test: testIndex[stage], Status: v1.TestStatusFailure})
+		run.Run.Failed, run.Run.TestFailures = true, 2
+		run.Run.OverallResult = v1.JobInstallFailure
+		run.Run.FailureClassification = string(failureclassification.InstallFailure)
+		return run
+	}
+	for _, name := range installTests {
+		run.Results = append(run.Results, Result{Test: testIndex[name], Status: v1.TestStatusSuccess})
+	}
+	if upgrade {
+		for _, name := range upgradeTests {
+			run.Results = append(run.Results, Result{Test: testIndex[name], Status: v1.TestStatusSuccess})
+		}
+	}
+
+	for _, test := range e2eTests {
+		profile := profiles[test]
+		rate := profile.failureRate
+		if regressed {
+			rate = profile.regressedRate
+		}
+		status := v1.TestStatusSuccess
+		if rnd.Float64() < rate {
+			status = v1.TestStatusFailure
+			if rnd.Float64() < flakeRate {
+				status = v1.TestStatusFlake
+			}
+		}
+		if status == v1.TestStatusFailure {
+			run.Run.TestFailures++
+		}
+		run.Results = append(run.Results, Result{Test: test, Status: status})
+	}
+
+	if run.Run.TestFailures > 0 {
+		run.Run.Failed = true
+		run.Run.OverallResult = v1.JobTestFailure
+		run.Run.FailureClassification = string(failureclassification.ProductFailure)
+	} else {
+		run.Run.Succeeded = true
+		run.Run.OverallResult = v1.JobSucceeded
+	}
+	return run
+}
+
+// generateReleaseTags generates the release's payloads, numbering their pull requests from the count so far, as pull
+// requests are unique across releases.
+func generateReleaseTags(rnd *rand.Rand, release string, start, end time.Time, perDay int, pr *int) []models.ReleaseTag {
+	tags := make([]models.ReleaseTag, 0)
+	if perDay == 0 {
+		return tags
+	}
+	interval := 24 * time.Hour / time.Duration(perDay)
+	previous := ""
+	for ts := start.Truncate(time.Hour); ts.Before(end); ts = ts.Add(interval) {
+		tag := models.ReleaseTag{
+			ReleaseTag:         fmt.Sprintf("%s.0-0.nightly-%s", release, ts.UTC().Format("2006-01-02-150405")),
+			Release:            release,
+			Stream:             "nightly",
+			Architecture:       "amd64",
+			Phase:              "Accepted",
+			ReleaseTime:        ts,
+			PreviousReleaseTag: previous,
+		}
+		if rnd.Float64() >= payloadAcceptRate {
+			tag.Phase = "Rejected"
+			tag.RejectReasons = []string{"TestFailures"}
+		}
+		for i := 0; i < 1+rnd.Intn(3); i++ {
+			*pr++
+			repo := repositories[rnd.Intn(len(repositories))]
+			tag.PullRequests = append(tag.PullRequests, models.ReleasePullRequest{
+				URL:           fmt.Sprintf("https://github.com/openshift/%s/pull/%d", repo, *pr),
+				PullRequestID: fmt.Sprint(*pr),
+				Name:          repo,
+				Description:   fmt.Sprintf("Synthetic change %d to %s", *pr, repo),
+			})
+		}
+		previous = tag.ReleaseTag
+		tags = append(tags, tag)
+	}
+	return tags
+}
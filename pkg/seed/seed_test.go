@@ -0,0 +1,108 @@
+package seed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+func TestGenerate(t *testing.T) {
+	end := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	opts := Options{Releases: []string{"4.16", "4.15"}, Jobs: 12, Tests: 50, Days: 14, RunsPerDay: 2, PayloadsPerDay: 2, Seed: 1}
+	ds := Generate(opts, end)
+
+	assert.Len(t, ds.Tests, 50+len(installTests)+len(upgradeTests))
+	require.Len(t, ds.Jobs, 24)
+	assert.Len(t, ds.ReleaseTags, 2*14*2)
+
+	jobNames := map[string]bool{}
+	urls := map[string]bool{}
+	var failures, infrastructureFailures, successes int
+	for _, job := range ds.Jobs {
+		assert.False(t, jobNames[job.Job.Name], "duplicate job %s", job.Job.Name)
+		jobNames[job.Job.Name] = true
+		assert.Contains(t, job.Job.Name, job.Job.Release)
+		assert.Len(t, job.Runs, 14*2)
+
+		for _, run := range job.Runs {
+			assert.False(t, urls[run.Run.URL], "duplicate run %s", run.Run.URL)
+			urls[run.Run.URL] = true
+			assert.True(t, run.Run.Timestamp.Before(end))
+			assert.False(t, run.Run.Timestamp.Before(end.AddDate(0, 0, -14)))
+
+			switch run.Run.OverallResult {
+			case v1.JobInfrastructureFailure:
+				infrastructureFailures++
+				assert.Empty(t, run.Results)
+			case v1.JobSucceeded:
+				successes++
+				assert.Zero(t, run.Run.TestFailures)
+			default:
+				failures++
+				assert.True(t, run.Run.Failed)
+			}
+			for _, result := range run.Results {
+				assert.Less(t, result.Test, len(ds.Tests))
+			}
+		}
+	}
+	assert.NotZero(t, successes)
+	assert.NotZero(t, failures)
+	assert.NotZero(t, infrastructureFailures)
+
+	prs := map[string]bool{}
+	for _, tag := range ds.ReleaseTags {
+		for _, pr := range tag.PullRequests {
+			assert.False(t, prs[pr.URL], "duplicate pull request %s", pr.URL)
+			prs[pr.URL] = true
+		}
+	}
+
+	// the same seed generates the same data
+	assert.Equal(t, ds, Generate(opts, end))
+}
+
+func TestGenerateUpgradeJobsRunUpgradeTests(t *testing.T) {
+	ds := Generate(Options{Releases: []string{"4.16"}, Jobs: 30, Tests: 5, Days: 1, RunsPerDay: 4, Seed: 2}, time.Now())
+
+	upgradeTest := -1
+	for i, test := range ds.Tests {
+		if test.Name == testidentification.UpgradeTestName {
+			upgradeTest = i
+		}
+	}
+	require.NotEqual(t, -1, upgradeTest)
+
+	for _, job := range ds.Jobs {
+		upgrade := false
+		for _, v := range job.Job.Variants {
+			upgrade = upgrade || v == "upgrade-minor"
+		}
+		for _, run := range job.Runs {
+			ranUpgrade := false
+			for _, result := range run.Results {
+				ranUpgrade = ranUpgrade || result.Test == upgradeTest
+			}
+			if run.Run.OverallResult == v1.JobSucceeded {
+				assert.Equal(t, upgrade, ranUpgrade, job.Job.Name)
+			}
+		}
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, DefaultOptions().Validate())
+
+	opts := DefaultOptions()
+	opts.Releases = nil
+	assert.Error(t, opts.Validate())
+
+	opts = DefaultOptions()
+	opts.RunsPerDay = 0
+	assert.Error(t, opts.Validate())
+}
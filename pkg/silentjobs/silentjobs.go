@@ -0,0 +1,166 @@
+// Package silentjobs records jobs that stopped reporting: jobs that ran regularly in the two weeks before, but have
+// had no runs for a while, i.e. because they were dropped from the CI config by mistake or can no longer be scheduled.
+// A job with no runs otherwise goes unnoticed, as it has no failures to report.
+package silentjobs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/notify"
+)
+
+const (
+	defaultMinRunsPerDay = 1
+	defaultHours         = 48
+
+	// baselineDays is how long before the silence a job's usual runs are counted.
+	baselineDays = 14
+)
+
+// jobRuns counts a job's runs in the baseline before the silent period and in the silent period.
+type jobRuns struct {
+	ProwJobID    uint
+	JobName      string
+	BaselineRuns int
+	RecentRuns   int
+	LastRun      time.Time
+}
+
+// Detect opens silent jobs for the jobs of the configured releases that stopped reporting runs, notifying about them,
+// updates those still silent, and closes those that ran again.
+func Detect(dbc *db.DB, config v1.SilentJobDetectionConfig, notifier notify.Notifier, now time.Time) error {
+	if config.MinRunsPerDay <= 0 {
+		config.MinRunsPerDay = defaultMinRunsPerDay
+	}
+	if config.Hours <= 0 {
+		config.Hours = defaultHours
+	}
+
+	var errs []error
+	for _, release := range config.Releases {
+		if err := detectRelease(dbc, release, config, notifier, now); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", release, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error detecting silent jobs: %v", errs)
+	}
+	return nil
+}
+
+func detectRelease(dbc *db.DB, release string, config v1.SilentJobDetectionConfig, notifier notify.Notifier,
+	now time.Time) error {
+	silentSince := now.Add(-time.Duration(config.Hours * float64(time.Hour)))
+	baselineStart := silentSince.AddDate(0, 0, -baselineDays)
+
+	runs := make([]jobRuns, 0)
+	res := dbc.DB.Table("prow_jobs").
+		Select(`prow_jobs.id AS prow_job_id, prow_jobs.name AS job_name,
+			COUNT(*) FILTER (WHERE prow_job_runs.timestamp < ?) AS baseline_runs,
+			COUNT(*) FILTER (WHERE prow_job_runs.timestamp >= ?) AS recent_runs,
+			MAX(prow_job_runs.timestamp) AS last_run`, silentSince, silentSince).
+		Joins("JOIN prow_job_runs ON prow_job_runs.prow_job_id = prow_jobs.id").
+		Where("prow_jobs.release = ? AND prow_jobs.deleted_at IS NULL", release).
+		Where("prow_job_runs.timestamp >= ? AND prow_job_runs.timestamp < ?", baselineStart, now).
+		Group("prow_jobs.id, prow_jobs.name").
+		Scan(&runs)
+	if res.Error != nil {
+		return res.Error
+	}
+	silent := silentJobs(runs, config.MinRunsPerDay)
+
+	var opened []models.SilentJob
+	var closed int
+	err := dbc.DB.Transaction(func(tx *gorm.DB) error {
+		var open []models.SilentJob
+		if res := tx.Where("release = ? AND status = ?", release, models.SilentJobOpen).Find(&open); res.Error != nil {
+			return res.Error
+		}
+
+		seen := map[uint]bool{}
+		for i := range open {
+			sj := &open[i]
+			seen[sj.ProwJobID] = true
+			if _, ok := silent[sj.ProwJobID]; ok {
+				sj.LastSeen = now
+			} else {
+				closedAt := now
+				sj.Status, sj.ClosedAt = models.SilentJobClosed, &closedAt
+				closed++
+			}
+			if res := tx.Save(sj); res.Error != nil {
+				return res.Error
+			}
+		}
+
+		for _, job := range sortedJobs(silent) {
+			if seen[job.ProwJobID] {
+				continue
+			}
+			sj := models.SilentJob{
+				Release:            release,
+				ProwJobID:          job.ProwJobID,
+				JobName:            job.JobName,
+				Status:             models.SilentJobOpen,
+				ExpectedRunsPerDay: float64(job.BaselineRuns) / baselineDays,
+				LastRun:            job.LastRun,
+				FirstSeen:          now,
+				LastSeen:           now,
+			}
+			if res := tx.Create(&sj); res.Error != nil {
+				return res.Error
+			}
+			opened = append(opened, sj)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sj := range opened {
+		err := notifier.Notify(notify.Event{
+			Type:    notify.EventJobSilent,
+			Release: release,
+			Title:   fmt.Sprintf("%s stopped reporting", sj.JobName),
+			Message: fmt.Sprintf("%s averaged %.1f runs a day, but hasn't run since %s.", sj.JobName,
+				sj.ExpectedRunsPerDay, sj.LastRun.UTC().Format(time.RFC3339)),
+		})
+		if err != nil {
+			log.WithError(err).Warningf("error notifying about silent job %s", sj.JobName)
+		}
+	}
+
+	log.Infof("%d jobs silent in %s, %d newly and %d running again", len(silent), release, len(opened), closed)
+	return nil
+}
+
+// silentJobs returns the jobs that averaged at least minRunsPerDay runs a day in the baseline, but had none since.
+func silentJobs(runs []jobRuns, minRunsPerDay float64) map[uint]jobRuns {
+	silent := map[uint]jobRuns{}
+	for _, job := range runs {
+		if job.RecentRuns == 0 && float64(job.BaselineRuns)/baselineDays >= minRunsPerDay {
+			silent[job.ProwJobID] = job
+		}
+	}
+	return silent
+}
+
+func sortedJobs(jobs map[uint]jobRuns) []jobRuns {
+	sorted := make([]jobRuns, 0, len(jobs))
+	for _, job := range jobs {
+		sorted = append(sorted, job)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].JobName < sorted[j].JobName
+	})
+	return sorted
+}
@@ -0,0 +1,30 @@
+package silentjobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilentJobs(t *testing.T) {
+	runs := []jobRuns{
+		{ProwJobID: 1, JobName: "daily-stopped", BaselineRuns: 14},
+		{ProwJobID: 2, JobName: "daily-running", BaselineRuns: 14, RecentRuns: 2},
+		{ProwJobID: 3, JobName: "weekly-stopped", BaselineRuns: 2},
+		{ProwJobID: 4, JobName: "frequent-stopped", BaselineRuns: 140},
+	}
+
+	silent := silentJobs(runs, 1)
+	assert.Equal(t, []string{"daily-stopped", "frequent-stopped"}, jobNames(sortedJobs(silent)))
+
+	silent = silentJobs(runs, 5)
+	assert.Equal(t, []string{"frequent-stopped"}, jobNames(sortedJobs(silent)))
+}
+
+func jobNames(jobs []jobRuns) []string {
+	names := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.JobName)
+	}
+	return names
+}
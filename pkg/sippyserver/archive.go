@@ -0,0 +1,49 @@
+package sippyserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/archive"
+)
+
+// SetArchiveStore sets the store job runs were archived to, so archived runs can be read back. Without one, the
+// archived runs API is unavailable.
+func (s *Server) SetArchiveStore(store archive.Store) {
+	s.archiveStore = store
+}
+
+// jsonArchivedJobRun returns the archived job run given by the prow_job_run_id parameter, with its test results.
+func (s *Server) jsonArchivedJobRun(w http.ResponseWriter, req *http.Request) {
+	if s.archiveStore == nil {
+		api.RespondWithError(http.StatusBadRequest, w, "server not configured with an archive location, unable to use this API")
+		return
+	}
+
+	jobRunIDStr := req.URL.Query().Get("prow_job_run_id")
+	if jobRunIDStr == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "prow_job_run_id query parameter not specified")
+		return
+	}
+	jobRunID, err := strconv.ParseUint(jobRunIDStr, 10, 64)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "unable to parse prow_job_run_id: "+err.Error())
+		return
+	}
+
+	run, err := archive.Get(req.Context(), s.db, s.archiveStore, uint(jobRunID))
+	if err != nil {
+		log.WithError(err).WithField("jobRunID", jobRunID).Error("error reading archived job run")
+		api.RespondWithError(http.StatusInternalServerError, w, "error reading archived job run: "+err.Error())
+		return
+	}
+	if run == nil {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("job run %d isn't archived", jobRunID))
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, run)
+}
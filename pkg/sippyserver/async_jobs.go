@@ -0,0 +1,390 @@
+package sippyserver
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	asyncJobsPath = "/api/async_jobs"
+
+	// asyncJobTimeout is how long a job may run, and the longest a job is left pending or running before it's
+	// reported as abandoned, i.e. when the replica running it restarted.
+	asyncJobTimeout = 30 * time.Minute
+	// asyncJobReuse is how long the result of a job is returned to identical requests, rather than running it again.
+	asyncJobReuse = time.Hour
+	// asyncJobRetention is how long jobs and their results are kept.
+	asyncJobRetention = 24 * time.Hour
+	// asyncJobQueueSize is the number of jobs a replica queues before refusing new ones.
+	asyncJobQueueSize = 100
+	// maxAsyncJobErrorBytes caps how much of a failed report's response is kept as the job's error.
+	maxAsyncJobErrorBytes = 4096
+)
+
+var errAbandonedAsyncJob = fmt.Errorf("job was abandoned, its server may have restarted")
+
+// asyncJobRequest is the body POSTed to create a job.
+type asyncJobRequest struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+}
+
+// SetAsyncWorkers sets how many async jobs the server runs at once. With none, jobs can't be created, but the status
+// and results of jobs run by other replicas are still served.
+func (s *Server) SetAsyncWorkers(workers int) {
+	s.asyncWorkers = workers
+}
+
+// startAsyncWorkers starts the workers running queued jobs as internal requests to the handler, until the context is
+// done.
+func (s *Server) startAsyncWorkers(ctx context.Context, handler http.Handler) {
+	if s.db == nil || s.asyncWorkers < 1 {
+		return
+	}
+	s.asyncQueue = make(chan string, asyncJobQueueSize)
+	for i := 0; i < s.asyncWorkers; i++ {
+		go func() {
+			for {
+				select {
+				case id := <-s.asyncQueue:
+					s.runAsyncJob(ctx, handler, id)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	log.Infof("started %d async job workers", s.asyncWorkers)
+}
+
+// jsonAsyncJobs creates a job for a POSTed report path, or returns the status of the job given by the id parameter.
+func (s *Server) jsonAsyncJobs(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		s.createAsyncJob(w, req)
+	case http.MethodGet:
+		job, ok := s.getAsyncJob(w, req)
+		if ok {
+			api.RespondWithJSON(http.StatusOK, w, job)
+		}
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "async jobs must be POSTed, or their status fetched with GET")
+	}
+}
+
+// asyncJobResult returns the response of the succeeded job given by the id parameter.
+func (s *Server) asyncJobResult(w http.ResponseWriter, req *http.Request) {
+	job, ok := s.getAsyncJob(w, req)
+	if !ok {
+		return
+	}
+	if job.Status != models.AsyncJobSucceeded {
+		api.RespondWithError(http.StatusConflict, w, fmt.Sprintf("job %s has no result, it's %s", job.ID, job.Status))
+		return
+	}
+	if res := s.db.DB.WithContext(req.Context()).Select("result").First(job); res.Error != nil {
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying job result: "+res.Error.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", job.ContentType)
+	if job.Format == "csv" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.ID+".csv"))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(job.Result); err != nil {
+		log.WithError(err).Debugf("error writing http response")
+	}
+}
+
+func (s *Server) createAsyncJob(w http.ResponseWriter, req *http.Request) {
+	if s.asyncQueue == nil {
+		api.RespondWithError(http.StatusServiceUnavailable, w, "async jobs aren't run by this server")
+		return
+	}
+
+	jobReq := asyncJobRequest{}
+	if err := json.NewDecoder(req.Body).Decode(&jobReq); err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "couldn't decode job: "+err.Error())
+		return
+	}
+	target, err := parseAsyncJobPath(jobReq.Path)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
+		return
+	}
+	if jobReq.Format == "" {
+		jobReq.Format = "json"
+	}
+	if jobReq.Format != "json" && jobReq.Format != "csv" {
+		api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("unknown format %q, expected json or csv", jobReq.Format))
+		return
+	}
+
+	dbc := s.db.DB.WithContext(req.Context())
+	now := time.Now()
+	if res := dbc.Where("created_at < ?", now.Add(-asyncJobRetention)).Delete(&models.AsyncJob{}); res.Error != nil {
+		log.WithError(res.Error).Warning("error deleting expired async jobs")
+	}
+
+	tenant := api.Tenant(req.Context())
+	job := &models.AsyncJob{}
+	key := strings.Join([]string{tenant, jobReq.Format, cacheKey(&http.Request{URL: target})}, "|")
+	res := dbc.Omit("result").
+		Where("key = ? AND status <> ? AND created_at > ?", key, models.AsyncJobFailed, now.Add(-asyncJobReuse)).
+		Order("created_at DESC").Limit(1).Find(job)
+	if res.Error != nil {
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying async jobs: "+res.Error.Error())
+		return
+	}
+	if res.RowsAffected > 0 && abandonedAsyncJob(job, now) {
+		s.finishAsyncJob(job, nil, errAbandonedAsyncJob)
+	}
+	if res.RowsAffected == 0 || job.Status == models.AsyncJobFailed {
+		job = &models.AsyncJob{
+			ID:     uuid.NewString(),
+			Key:    key,
+			Path:   target.String(),
+			Format: jobReq.Format,
+			Tenant: tenant,
+			Status: models.AsyncJobPending,
+		}
+		if res := dbc.Create(job); res.Error != nil {
+			api.RespondWithError(http.StatusInternalServerError, w, "error creating async job: "+res.Error.Error())
+			return
+		}
+
+		select {
+		case s.asyncQueue <- job.ID:
+		default:
+			s.finishAsyncJob(job, nil, fmt.Errorf("too many queued jobs"))
+			api.RespondWithError(http.StatusServiceUnavailable, w, "too many queued jobs, try again later")
+			return
+		}
+		log.WithField("job", job.ID).Infof("queued async job for %s", job.Path)
+	}
+
+	w.Header().Set("Location", asyncJobsPath+"?id="+job.ID)
+	api.RespondWithJSON(http.StatusAccepted, w, job)
+}
+
+// getAsyncJob returns the job given by the id parameter, without its result, responding with an error if there
+// isn't one.
+func (s *Server) getAsyncJob(w http.ResponseWriter, req *http.Request) (*models.AsyncJob, bool) {
+	id := req.URL.Query().Get("id")
+	if id == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "id is required")
+		return nil, false
+	}
+
+	job := &models.AsyncJob{}
+	res := s.db.DB.WithContext(req.Context()).Omit("result").Where("id = ?", id).Limit(1).Find(job)
+	if res.Error != nil {
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying async job: "+res.Error.Error())
+		return nil, false
+	}
+	if res.RowsAffected == 0 || job.Tenant != api.Tenant(req.Context()) {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("job %s not found", id))
+		return nil, false
+	}
+
+	if abandonedAsyncJob(job, time.Now()) {
+		s.finishAsyncJob(job, nil, errAbandonedAsyncJob)
+	}
+	return job, true
+}
+
+// abandonedAsyncJob reports whether an unfinished job has waited, or run, longer than any job is allowed to.
+func abandonedAsyncJob(job *models.AsyncJob, now time.Time) bool {
+	switch job.Status {
+	case models.AsyncJobPending:
+		return now.Sub(job.CreatedAt) > asyncJobTimeout
+	case models.AsyncJobRunning:
+		return job.StartedAt == nil || now.Sub(*job.StartedAt) > asyncJobTimeout
+	}
+	return false
+}
+
+// runAsyncJob claims a pending job and serves its request with the handler, storing the response, or why it failed.
+func (s *Server) runAsyncJob(ctx context.Context, handler http.Handler, id string) {
+	started := time.Now()
+	res := s.db.DB.Model(&models.AsyncJob{}).
+		Where("id = ? AND status = ?", id, models.AsyncJobPending).
+		Updates(map[string]interface{}{"status": models.AsyncJobRunning, "started_at": started})
+	if res.Error != nil {
+		log.WithError(res.Error).WithField("job", id).Error("error starting async job")
+		return
+	}
+	if res.RowsAffected == 0 {
+		// abandoned while queued
+		return
+	}
+
+	job := &models.AsyncJob{}
+	if res := s.db.DB.Omit("result").First(job, "id = ?", id); res.Error != nil {
+		log.WithError(res.Error).WithField("job", id).Error("error querying async job")
+		return
+	}
+	job.Status = models.AsyncJobRunning
+	job.StartedAt = &started
+	jobLog := log.WithFields(log.Fields{"job": job.ID, "path": job.Path})
+
+	ctx, cancel := context.WithTimeout(ctx, asyncJobTimeout)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, job.Path, nil).WithContext(ctx)
+	if job.Tenant != "" {
+		req.Header.Set(api.TenantHeader, job.Tenant)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		body := rec.Body.String()
+		if len(body) > maxAsyncJobErrorBytes {
+			body = body[:maxAsyncJobErrorBytes]
+		}
+		s.finishAsyncJob(job, nil, fmt.Errorf("report responded %d: %s", rec.Code, body))
+		jobLog.WithField("status", rec.Code).Warning("async job failed")
+		return
+	}
+
+	job.ContentType = rec.Header().Get("Content-Type")
+	result := rec.Body.Bytes()
+	if job.Format == "csv" {
+		var err error
+		if result, err = jsonToCSV(rec.Body); err != nil {
+			s.finishAsyncJob(job, nil, err)
+			jobLog.WithError(err).Warning("async job failed")
+			return
+		}
+		job.ContentType = "text/csv"
+	}
+	s.finishAsyncJob(job, result, nil)
+	jobLog.WithField("elapsed", time.Since(started)).Info("async job succeeded")
+}
+
+// finishAsyncJob records the job's result, or the error that failed it.
+func (s *Server) finishAsyncJob(job *models.AsyncJob, result []byte, err error) {
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Status = models.AsyncJobSucceeded
+	if err != nil {
+		job.Status = models.AsyncJobFailed
+		job.Error = err.Error()
+	}
+	updates := map[string]interface{}{
+		"status":       job.Status,
+		"error":        job.Error,
+		"finished_at":  finished,
+		"content_type": job.ContentType,
+		"result":       result,
+	}
+	if res := s.db.DB.Model(&models.AsyncJob{}).Where("id = ?", job.ID).Updates(updates); res.Error != nil {
+		log.WithError(res.Error).WithField("job", job.ID).Error("error recording async job result")
+	}
+}
+
+// parseAsyncJobPath validates the report path a job requests, which must be another API endpoint of this server.
+func parseAsyncJobPath(path string) (*url.URL, error) {
+	target, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %v", path, err)
+	}
+	if target.Scheme != "" || target.Host != "" || !strings.HasPrefix(target.Path, "/api/") {
+		return nil, fmt.Errorf("path %q must be an API path, starting with /api/", path)
+	}
+	if strings.HasPrefix(target.Path, asyncJobsPath) {
+		return nil, fmt.Errorf("path %q can't be an async job", path)
+	}
+	return &url.URL{Path: target.Path, RawQuery: target.RawQuery}, nil
+}
+
+// jsonToCSV converts a report's JSON, an array of objects or a paginated result with them in "rows", to CSV. The
+// columns are the keys of the objects, sorted, and nested values are written as JSON.
+func jsonToCSV(r io.Reader) ([]byte, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var report interface{}
+	if err := dec.Decode(&report); err != nil {
+		return nil, fmt.Errorf("couldn't decode report: %v", err)
+	}
+
+	if paginated, ok := report.(map[string]interface{}); ok {
+		report = paginated["rows"]
+	}
+	items, ok := report.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("report isn't a list of rows, it can't be converted to csv")
+	}
+
+	rows := make([]map[string]interface{}, 0, len(items))
+	columnSet := map[string]bool{}
+	for _, item := range items {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("report rows aren't objects, they can't be converted to csv")
+		}
+		for column := range row {
+			columnSet[column] = true
+		}
+		rows = append(rows, row)
+	}
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var out strings.Builder
+	writer := csv.NewWriter(&out)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = csvValue(row[column])
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return []byte(out.String()), nil
+}
+
+func csvValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return fmt.Sprintf("%t", value)
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(encoded)
+}
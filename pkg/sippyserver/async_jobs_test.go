@@ -0,0 +1,82 @@
+package sippyserver
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestJSONToCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		report      string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "array of rows",
+			report:   `[{"name": "a", "runs": 10, "flaky": false}, {"name": "b, c", "runs": 1.5, "variants": ["aws", "amd64"]}]`,
+			expected: "flaky,name,runs,variants\nfalse,a,10,\n,\"b, c\",1.5,\"[\"\"aws\"\",\"\"amd64\"\"]\"\n",
+		},
+		{
+			name:     "paginated rows",
+			report:   `{"rows": [{"id": 12345678901234567890}], "page": 0, "total_rows": 1}`,
+			expected: "id\n12345678901234567890\n",
+		},
+		{
+			name:     "no rows",
+			report:   `[]`,
+			expected: "\n",
+		},
+		{
+			name:        "not rows",
+			report:      `{"release": "4.16"}`,
+			expectedErr: true,
+		},
+		{
+			name:        "rows aren't objects",
+			report:      `[1, 2]`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := jsonToCSV(strings.NewReader(tc.report))
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
+
+func TestParseAsyncJobPath(t *testing.T) {
+	target, err := parseAsyncJobPath("/api/tests?release=4.16#top")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/tests?release=4.16", target.String())
+
+	for _, path := range []string{"", "/sippy-ng/", "https://example.com/api/tests", "/api/async_jobs/result?id=1"} {
+		_, err := parseAsyncJobPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestAbandonedAsyncJob(t *testing.T) {
+	now := time.Now()
+	started := now.Add(-time.Minute)
+	longAgo := now.Add(-2 * asyncJobTimeout)
+
+	assert.False(t, abandonedAsyncJob(&models.AsyncJob{Status: models.AsyncJobPending, CreatedAt: started}, now))
+	assert.True(t, abandonedAsyncJob(&models.AsyncJob{Status: models.AsyncJobPending, CreatedAt: longAgo}, now))
+	assert.False(t, abandonedAsyncJob(&models.AsyncJob{Status: models.AsyncJobRunning, StartedAt: &started}, now))
+	assert.True(t, abandonedAsyncJob(&models.AsyncJob{Status: models.AsyncJobRunning, StartedAt: &longAgo}, now))
+	assert.False(t, abandonedAsyncJob(&models.AsyncJob{Status: models.AsyncJobSucceeded, CreatedAt: longAgo}, now))
+}
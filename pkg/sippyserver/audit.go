@@ -0,0 +1,187 @@
+package sippyserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgtype"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// maxAuditedBodyBytes caps the request payloads we keep in the audit log, larger ones aren't recorded.
+	maxAuditedBodyBytes = 1 << 20
+	// maxRequestBodyBytes caps the request payloads of audited endpoints, larger ones are rejected.
+	maxRequestBodyBytes = 16 << 20
+
+	defaultAuditLogLimit = 500
+	maxAuditLogLimit     = 5000
+)
+
+type auditContextKey struct{}
+
+// auditEntry collects the state a handler wants recorded alongside its request.
+type auditEntry struct {
+	before interface{}
+	after  interface{}
+}
+
+// setAuditBefore records the state of a resource before an audited handler changes it.
+func setAuditBefore(req *http.Request, v interface{}) {
+	if entry, ok := req.Context().Value(auditContextKey{}).(*auditEntry); ok {
+		entry.before = v
+	}
+}
+
+// setAuditAfter records the state of a resource after an audited handler has changed it. When not called, the
+// request payload is recorded instead.
+func setAuditAfter(req *http.Request, v interface{}) {
+	if entry, ok := req.Context().Value(auditContextKey{}).(*auditEntry); ok {
+		entry.after = v
+	}
+}
+
+// audited wraps a handler for a write endpoint so every mutating request it serves is recorded in the audit log.
+// Safe methods pass straight through.
+func (s *Server) audited(handler func(w http.ResponseWriter, r *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingMethod(r.Method) || s.db == nil {
+			handler(w, r)
+			return
+		}
+
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+
+		entry := &auditEntry{}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler(rec, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, entry)))
+
+		if len(body) > maxAuditedBodyBytes {
+			body = nil
+		}
+		auditLog := newAuditLog(r, rec.status, w.Header().Get(api.RequestIDHeader), body, entry)
+		if res := s.db.DB.Create(&auditLog); res.Error != nil {
+			log.WithError(res.Error).WithField("endpoint", auditLog.Endpoint).Error("error recording audit log")
+		}
+	}
+}
+
+// readBody reads the whole request body, so it can be recorded, and replaces it with a copy for the handler. Bodies
+// that can't be read, or are too large, are rejected.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Body == nil {
+		return nil, true
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+	if err != nil {
+		if len(body) >= maxRequestBodyBytes {
+			api.RespondWithError(http.StatusRequestEntityTooLarge, w,
+				fmt.Sprintf("request body is larger than %d bytes", maxRequestBodyBytes))
+			return nil, false
+		}
+		api.RespondWithError(http.StatusBadRequest, w, "couldn't read request body: "+err.Error())
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// auditUser identifies the caller from the headers set by the authenticating proxy in front of sippy.
+func auditUser(req *http.Request) string {
+	for _, header := range []string{"X-Forwarded-User", "X-Forwarded-Email"} {
+		if user := req.Header.Get(header); user != "" {
+			return user
+		}
+	}
+	return "anonymous"
+}
+
+func newAuditLog(req *http.Request, status int, requestID string, body []byte, entry *auditEntry) models.AuditLog {
+	auditLog := models.AuditLog{
+		User:       auditUser(req),
+		Method:     req.Method,
+		Endpoint:   req.URL.Path,
+		RequestID:  requestID,
+		StatusCode: status,
+		Before:     pgtype.JSONB{Status: pgtype.Null},
+		After:      pgtype.JSONB{Status: pgtype.Null},
+	}
+
+	if entry.before != nil {
+		if err := auditLog.Before.Set(entry.before); err != nil {
+			log.WithError(err).Warning("couldn't encode audit before state")
+		}
+	}
+
+	switch {
+	case entry.after != nil:
+		if err := auditLog.After.Set(entry.after); err != nil {
+			log.WithError(err).Warning("couldn't encode audit after state")
+		}
+	case json.Valid(body):
+		auditLog.After = pgtype.JSONB{Bytes: body, Status: pgtype.Present}
+	}
+
+	return auditLog
+}
+
+func (s *Server) jsonAuditLog(w http.ResponseWriter, req *http.Request) {
+	end := s.GetReportEnd()
+	start := end.Add(-30 * 24 * time.Hour)
+	if startParam, err := getISO8601Date("start", req); err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "couldn't parse start param: "+err.Error())
+		return
+	} else if startParam != nil {
+		start = *startParam
+	}
+	if endParam, err := getISO8601Date("end", req); err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "couldn't parse end param: "+err.Error())
+		return
+	} else if endParam != nil {
+		end = *endParam
+	}
+
+	limit := defaultAuditLogLimit
+	if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse limit param: "+err.Error())
+			return
+		}
+	}
+	if limit < 1 || limit > maxAuditLogLimit {
+		api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("limit must be between 1 and %d", maxAuditLogLimit))
+		return
+	}
+
+	results, err := query.AuditLogs(s.db.WithContext(req.Context()),
+		req.URL.Query().Get("user"), req.URL.Query().Get("endpoint"), start, end, limit)
+	if err != nil {
+		log.WithError(err).Error("error querying audit log")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying audit log: "+err.Error())
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
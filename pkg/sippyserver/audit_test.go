@@ -0,0 +1,92 @@
+package sippyserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuditLog(t *testing.T) {
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		body           string
+		entry          *auditEntry
+		expectedUser   string
+		expectedBefore string
+		expectedAfter  string
+	}{
+		{
+			name:          "request payload recorded as after",
+			headers:       map[string]string{"X-Forwarded-User": "jdoe"},
+			body:          `{"triage":"infra"}`,
+			entry:         &auditEntry{},
+			expectedUser:  "jdoe",
+			expectedAfter: `{"triage":"infra"}`,
+		},
+		{
+			name:           "handler supplied before and after",
+			headers:        map[string]string{"X-Forwarded-Email": "jdoe@example.com"},
+			body:           `{"triage":"infra"}`,
+			entry:          &auditEntry{before: map[string]string{"triage": "product"}, after: map[string]string{"triage": "infra"}},
+			expectedUser:   "jdoe@example.com",
+			expectedBefore: `{"triage":"product"}`,
+			expectedAfter:  `{"triage":"infra"}`,
+		},
+		{
+			name:         "non-json payload is not recorded",
+			body:         "not json",
+			entry:        &auditEntry{},
+			expectedUser: "anonymous",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/triage", strings.NewReader(tc.body))
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+
+			auditLog := newAuditLog(req, http.StatusOK, "abc-123", []byte(tc.body), tc.entry)
+
+			assert.Equal(t, tc.expectedUser, auditLog.User)
+			assert.Equal(t, "/api/triage", auditLog.Endpoint)
+			assert.Equal(t, http.MethodPost, auditLog.Method)
+			assert.Equal(t, "abc-123", auditLog.RequestID)
+			assertJSONB(t, tc.expectedBefore, auditLog.Before)
+			assertJSONB(t, tc.expectedAfter, auditLog.After)
+		})
+	}
+}
+
+func assertJSONB(t *testing.T, expected string, actual pgtype.JSONB) {
+	if expected == "" {
+		assert.Equal(t, pgtype.Null, actual.Status)
+		return
+	}
+	assert.Equal(t, pgtype.Present, actual.Status)
+	assert.JSONEq(t, expected, string(actual.Bytes))
+}
+
+func TestReadBody(t *testing.T) {
+	payload := `{"name": "` + strings.Repeat("x", maxAuditedBodyBytes) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/saved_views", strings.NewReader(payload))
+	body, ok := readBody(httptest.NewRecorder(), req)
+	assert.True(t, ok)
+	assert.Equal(t, payload, string(body))
+	handlerBody, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, string(handlerBody), "the handler gets the whole body, however large")
+
+	rec := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/saved_views", strings.NewReader(strings.Repeat("x", maxRequestBodyBytes+1)))
+	_, ok = readBody(rec, req)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
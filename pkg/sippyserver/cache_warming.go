@@ -0,0 +1,78 @@
+package sippyserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// DefaultWarmPaths are the reports warmed for each release after a refresh: the release overview, its most failing
+// tests and its job pass rates. {release} is replaced with the release.
+var DefaultWarmPaths = []string{
+	"/api/health?release={release}",
+	"/api/tests?release={release}&sortField=current_failures&sort=desc&limit=25",
+	"/api/jobs?release={release}",
+}
+
+// cacheWarmingKey marks requests made to warm the cache, which always compute the report rather than use the cached
+// one computed from the previous data.
+type cacheWarmingKey struct{}
+
+// SetCacheWarming sets the reports WarmCache computes, for each of the newest releases.
+func (s *Server) SetCacheWarming(releases int, paths []string) {
+	s.warmReleases = releases
+	s.warmPaths = paths
+}
+
+// WarmCache computes the most requested reports of the newest releases and caches them, so the first users after a
+// refresh don't wait for their queries. It's called once the refresh completes, and clears the in-memory cache first,
+// as its entries were computed from the previous data.
+func (s *Server) WarmCache(ctx context.Context) {
+	s.httpServerLock.Lock()
+	handler := s.serveMux
+	s.httpServerLock.Unlock()
+	if handler == nil || s.cache == nil || s.db == nil || s.warmReleases < 1 || len(s.warmPaths) == 0 {
+		return
+	}
+
+	if c, ok := s.cache.(interface{ Clear() }); ok {
+		c.Clear()
+	}
+
+	releases, err := query.ReleasesFromDB(s.db.WithContext(ctx))
+	if err != nil {
+		log.WithError(err).Error("error querying releases to warm the cache for")
+		return
+	}
+	if len(releases) > s.warmReleases {
+		releases = releases[:s.warmReleases]
+	}
+
+	start := time.Now()
+	warmed := 0
+	for _, release := range releases {
+		for _, path := range s.warmPaths {
+			if ctx.Err() != nil {
+				return
+			}
+			target := strings.ReplaceAll(path, "{release}", url.QueryEscape(release.Release))
+			req := httptest.NewRequest(http.MethodGet, target, nil).
+				WithContext(context.WithValue(ctx, cacheWarmingKey{}, true))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				log.WithField("status", rec.Code).Warningf("error warming the cache with %s", target)
+				continue
+			}
+			warmed++
+		}
+	}
+	log.WithField("elapsed", time.Since(start)).Infof("warmed the cache with %d reports", warmed)
+}
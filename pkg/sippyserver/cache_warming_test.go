@@ -0,0 +1,79 @@
+package sippyserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/cache/memory"
+)
+
+func TestCacheKey(t *testing.T) {
+	a := httptest.NewRequest(http.MethodGet, "/api/tests?release=4.16&sort=desc", nil)
+	b := httptest.NewRequest(http.MethodGet, "/api/tests?sort=desc&release=4.16", nil)
+	assert.Equal(t, cacheKey(a), cacheKey(b))
+	assert.Equal(t, "/api/jobs", cacheKey(httptest.NewRequest(http.MethodGet, "/api/jobs", nil)))
+
+	c := httptest.NewRequest(http.MethodGet, "/api/tests?release=4.16&sort=desc", nil)
+	c = c.WithContext(api.WithTenant(c.Context(), "okd"))
+	assert.Equal(t, "okd|/api/tests?release=4.16&sort=desc", cacheKey(c))
+}
+
+func TestCachedWarming(t *testing.T) {
+	s := &Server{cache: memory.NewCache(10, nil)}
+	calls := 0
+	handler := s.cached(time.Hour, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	})
+
+	get := func(ctx context.Context, target string) {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx))
+	}
+	get(context.Background(), "/api/health?release=4.16")
+	get(context.Background(), "/api/health?release=4.16")
+	assert.Equal(t, 1, calls)
+
+	// warming recomputes the report even though it's cached
+	get(context.WithValue(context.Background(), cacheWarmingKey{}, true), "/api/health?release=4.16")
+	assert.Equal(t, 2, calls)
+	get(context.Background(), "/api/health?release=4.16")
+	assert.Equal(t, 2, calls)
+}
+
+func TestWarmCacheNotServing(t *testing.T) {
+	s := &Server{cache: memory.NewCache(10, nil)}
+	s.SetCacheWarming(2, DefaultWarmPaths)
+	// not serving yet, so there's nothing to warm
+	s.WarmCache(context.Background())
+}
+
+func TestCachedStatus(t *testing.T) {
+	s := &Server{cache: memory.NewCache(10, nil)}
+	calls := 0
+	status := http.StatusInternalServerError
+	handler := s.cached(time.Hour, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	})
+
+	get := func() int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/health?release=4.16", nil))
+		return rec.Code
+	}
+	// errors aren't cached
+	assert.Equal(t, http.StatusInternalServerError, get())
+	assert.Equal(t, http.StatusInternalServerError, get())
+	assert.Equal(t, 2, calls)
+
+	status = http.StatusAccepted
+	assert.Equal(t, http.StatusAccepted, get())
+	assert.Equal(t, http.StatusAccepted, get(), "the cached status is replayed")
+	assert.Equal(t, 3, calls)
+}
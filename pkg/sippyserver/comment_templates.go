@@ -0,0 +1,94 @@
+package sippyserver
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+// RiskAnalysisCommentData is passed to risk analysis comment templates.
+type RiskAnalysisCommentData struct {
+	Org  string
+	Repo string
+	SHA  string
+
+	// OverallRisk is the highest risk level of the analyzed jobs.
+	OverallRisk string
+
+	// Jobs are the risky jobs, sorted highest risk first.
+	Jobs RiskAnalysisEntryList
+}
+
+// CommentTemplates renders PR comments from the Go templates defined in the Sippy config, falling back to the
+// built-in comments when no template applies.
+type CommentTemplates struct {
+	riskAnalysis    *template.Template
+	orgRiskAnalysis map[string]*template.Template
+}
+
+// NewCommentTemplates parses the comment templates in the commenter config. Besides the template builtins, templates
+// can use:
+//
+//	testLink <release> <test name>: a markdown link to the test's details in sippy
+//	pluralize <word> <count>:      the word, pluralized unless count is 1
+//	escapeBug <summary>:           a bug summary escaped so the CI bot doesn't detect Jira references
+//	join <list> <sep>:             strings.Join
+func NewCommentTemplates(config v1.CommenterConfig, sippyURL string) (*CommentTemplates, error) {
+	funcs := template.FuncMap{
+		"testLink": func(release, testName string) string {
+			return testLink(sippyURL, release, testName)
+		},
+		"pluralize": pluralize,
+		"escapeBug": escapeBugSummary,
+		"join":      strings.Join,
+	}
+
+	ct := &CommentTemplates{orgRiskAnalysis: map[string]*template.Template{}}
+	if config.RiskAnalysisTemplate != "" {
+		tmpl, err := template.New("riskAnalysis").Funcs(funcs).Parse(config.RiskAnalysisTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid risk analysis comment template: %w", err)
+		}
+		ct.riskAnalysis = tmpl
+	}
+	for org, text := range config.OrgRiskAnalysisTemplates {
+		tmpl, err := template.New("riskAnalysis-" + org).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid risk analysis comment template for org %s: %w", org, err)
+		}
+		ct.orgRiskAnalysis[org] = tmpl
+	}
+
+	return ct, nil
+}
+
+// riskAnalysisComment renders the risk analysis comment with the org's template, or the default template if the org
+// doesn't have one. It returns false if neither is configured.
+func (ct *CommentTemplates) riskAnalysisComment(data RiskAnalysisCommentData) (string, bool, error) {
+	if ct == nil {
+		return "", false, nil
+	}
+
+	tmpl := ct.orgRiskAnalysis[data.Org]
+	if tmpl == nil {
+		tmpl = ct.riskAnalysis
+	}
+	if tmpl == nil {
+		return "", false, nil
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", true, err
+	}
+	return sb.String(), true, nil
+}
+
+// escapeBugSummary prevents the openshift-ci bot from detecting JIRA references in the link by replacing - with html
+// escaped sequence
+func escapeBugSummary(summary string) string {
+	return strings.ReplaceAll(html.EscapeString(summary), "-", "&#45;")
+}
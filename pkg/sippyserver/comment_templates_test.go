@@ -0,0 +1,67 @@
+package sippyserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestCommentTemplates(t *testing.T) {
+	config := v1.CommenterConfig{
+		RiskAnalysisTemplate: `Risk for {{ .SHA }}: {{ .OverallRisk }} ({{ len .Jobs }} {{ pluralize "job" (len .Jobs) }})
+{{ range .Jobs }}{{ $release := .Value.CompareRelease }}- {{ .Key }}{{ range .Value.TestRiskAnalysis }} {{ testLink $release .Name }}{{ range .OpenBugs }} {{ escapeBug .Summary }}{{ end }}{{ end }}
+{{ end }}`,
+		OrgRiskAnalysisTemplates: map[string]string{
+			"example": `{{ .Org }}/{{ .Repo }} is at {{ .OverallRisk }} risk`,
+		},
+	}
+	data := RiskAnalysisCommentData{
+		Org:         "openshift",
+		Repo:        "origin",
+		SHA:         "abc123",
+		OverallRisk: api.FailureRiskLevelHigh.Name,
+		Jobs: RiskAnalysisEntryList{
+			{
+				Key: "e2e-aws",
+				Value: RiskAnalysisSummary{
+					CompareRelease: "4.15",
+					RiskLevel:      api.FailureRiskLevelHigh,
+					TestRiskAnalysis: []api.ProwJobRunTestRiskAnalysis{
+						{Name: "test a", OpenBugs: []models.Bug{{Summary: "OCPBUGS-1: broken"}}},
+					},
+				},
+			},
+		},
+	}
+
+	templates, err := NewCommentTemplates(config, "https://sippy.example.com")
+	require.NoError(t, err)
+
+	comment, templated, err := templates.riskAnalysisComment(data)
+	require.NoError(t, err)
+	assert.True(t, templated)
+	assert.Equal(t, `Risk for abc123: High (1 job)
+- e2e-aws [test a](https://sippy.example.com/sippy-ng/tests/4.15/analysis?test=test+a) OCPBUGS&#45;1: broken
+`, comment)
+
+	data.Org = "example"
+	comment, templated, err = templates.riskAnalysisComment(data)
+	require.NoError(t, err)
+	assert.True(t, templated)
+	assert.Equal(t, "example/origin is at High risk", comment)
+
+	// no templates configured uses the built-in comment
+	templates, err = NewCommentTemplates(v1.CommenterConfig{}, "")
+	require.NoError(t, err)
+	_, templated, err = templates.riskAnalysisComment(data)
+	require.NoError(t, err)
+	assert.False(t, templated)
+
+	_, err = NewCommentTemplates(v1.CommenterConfig{RiskAnalysisTemplate: "{{ .SHA"}, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package sippyserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/federation"
+)
+
+// SetFederation sets the remote instances /api/federated queries along with this one. Without them, the federated
+// API is unavailable.
+func (s *Server) SetFederation(f *federation.Federation) {
+	s.federation = f
+}
+
+// jsonFederated sends a read query for the API path after /api/federated/ to this instance, through the local
+// handler, and to the remote instances, returning their merged results, i.e. /api/federated/jobs?release=4.16 merges
+// the /api/jobs?release=4.16 responses.
+func (s *Server) jsonFederated(local http.Handler) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if s.federation == nil {
+			api.RespondWithError(http.StatusBadRequest, w, "server not configured with federated instances, unable to use this API")
+			return
+		}
+		if req.Method != http.MethodGet {
+			api.RespondWithError(http.StatusMethodNotAllowed, w, "only read queries can be federated")
+			return
+		}
+		path := "/api/" + strings.TrimPrefix(req.URL.Path, "/api/federated/")
+		if path == "/api/" || strings.HasPrefix(path, "/api/federated") {
+			api.RespondWithError(http.StatusBadRequest, w, "federated queries are sent to /api/federated/<api path>")
+			return
+		}
+		api.RespondWithJSON(http.StatusOK, w, s.federation.Query(req, local, path))
+	}
+}
@@ -0,0 +1,69 @@
+package sippyserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+type healthStatus struct {
+	Status      string     `json:"status"`
+	LastUpdated *time.Time `json:"last_updated,omitempty"`
+}
+
+// healthz reports liveness: if we can answer at all, the process is healthy.
+func (s *Server) healthz(w http.ResponseWriter, _ *http.Request) {
+	api.RespondWithJSON(http.StatusOK, w, healthStatus{Status: "ok"})
+}
+
+// readyz reports whether this instance should receive traffic. It fails when the database is unreachable, and when
+// --readiness-max-data-age is set, when the data we would serve has gone stale.
+func (s *Server) readyz(w http.ResponseWriter, req *http.Request) {
+	if s.db == nil {
+		api.RespondWithJSON(http.StatusOK, w, healthStatus{Status: "ok"})
+		return
+	}
+
+	sqlDB, err := s.db.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(req.Context())
+	}
+	if err != nil {
+		log.WithError(err).Warning("readiness check could not reach the database")
+		api.RespondWithError(http.StatusServiceUnavailable, w, "database is unreachable")
+		return
+	}
+
+	lastUpdated, err := query.LastProwJobRunCreated(s.db.WithContext(req.Context()))
+	if err != nil {
+		log.WithError(err).Warning("readiness check could not query last updated")
+		api.RespondWithError(http.StatusServiceUnavailable, w, "error querying last updated from db")
+		return
+	}
+
+	if err := checkDataFreshness(lastUpdated, s.GetReportEnd(), s.readinessMaxDataAge); err != nil {
+		log.WithError(err).Warning("readiness check failed")
+		api.RespondWithError(http.StatusServiceUnavailable, w, err.Error())
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, healthStatus{Status: "ok", LastUpdated: &lastUpdated})
+}
+
+// checkDataFreshness returns an error if lastUpdated is more than maxAge before reportEnd. A zero maxAge disables
+// the check.
+func checkDataFreshness(lastUpdated, reportEnd time.Time, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	if age := reportEnd.Sub(lastUpdated); age > maxAge {
+		return fmt.Errorf("data is stale: newest prow job run was loaded %s ago, exceeding %s",
+			age.Round(time.Minute), maxAge)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package sippyserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDataFreshness(t *testing.T) {
+	reportEnd := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		lastUpdated time.Time
+		maxAge      time.Duration
+		expectStale bool
+	}{
+		{
+			name:        "check disabled",
+			lastUpdated: reportEnd.Add(-30 * 24 * time.Hour),
+			maxAge:      0,
+		},
+		{
+			name:        "fresh data",
+			lastUpdated: reportEnd.Add(-time.Hour),
+			maxAge:      6 * time.Hour,
+		},
+		{
+			name:        "stale data",
+			lastUpdated: reportEnd.Add(-7 * time.Hour),
+			maxAge:      6 * time.Hour,
+			expectStale: true,
+		},
+		{
+			name:        "empty database",
+			lastUpdated: time.Time{},
+			maxAge:      6 * time.Hour,
+			expectStale: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkDataFreshness(tc.lastUpdated, reportEnd, tc.maxAge)
+			if tc.expectStale {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -9,13 +9,14 @@ import (
 func (s *Server) jsonUpgradeReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := req.URL.Query().Get("release")
 
-	api.PrintUpgradeJSONReportFromDB(w, req, s.db, release)
+	api.PrintUpgradeJSONReportFromDB(w, req, s.db.WithContext(req.Context()), release, s.variantManager.InvalidVariants(release))
 }
 
 func (s *Server) jsonInstallReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := req.URL.Query().Get("release")
 
-	api.PrintInstallJSONReportFromDB(w, s.db,
+	api.PrintInstallJSONReportFromDB(w, s.db.WithContext(req.Context()),
 		release,
+		s.variantManager.InvalidVariants(release),
 	)
 }
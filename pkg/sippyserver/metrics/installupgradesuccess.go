@@ -57,7 +57,7 @@ func refreshTestSuccessMetrics(dbc *db.DB, testName string, successMetric, succe
 	for _, release := range releases {
 		for _, reportType := range []v1.ReportType{v1.CurrentReport, v1.TwoDayReport} {
 			_, testToVariantToResults, err := api.VariantTestsReport(dbc, release.Release, reportType,
-				sets.NewString(testName), sets.NewString(), sets.NewString(), excludedVariants)
+				sets.NewString(testName), sets.NewString(), sets.NewString(), excludedVariants, nil)
 			if err != nil {
 				return err
 			}
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strconv"
@@ -17,6 +18,7 @@ import (
 	bqclient "github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
 	"github.com/openshift/sippy/pkg/filter"
+	"github.com/openshift/sippy/pkg/notify"
 	"github.com/openshift/sippy/pkg/testidentification"
 	"github.com/openshift/sippy/pkg/util"
 
@@ -24,6 +26,7 @@ import (
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/db/query"
 )
 
@@ -68,6 +71,10 @@ var (
 		Name: "sippy_hours_since_last_update",
 		Help: "Number of hours since Sippy last successfully fetched new data.",
 	}, []string{})
+	loadLastSuccessMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sippy_data_load_last_success_timestamp_seconds",
+		Help: "Unix timestamp of the last load that completed without errors",
+	}, []string{"loader"})
 	componentReadinessMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "sippy_component_readiness",
 		Help: "Regression score for components",
@@ -92,7 +99,7 @@ var (
 
 // presume in a historical context there won't be scraping of these metrics
 // pinning the time just to be consistent
-func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, gcsBucket string, variantManager testidentification.VariantManager, reportEnd time.Time, cacheOptions cache.RequestOptions) error {
+func RefreshMetricsDB(ctx context.Context, dbc *db.DB, bqc *bqclient.Client, gcsBucket string, variantManager testidentification.VariantManager, reportEnd time.Time, cacheOptions cache.RequestOptions, notifier notify.Notifier) error {
 	start := time.Now()
 	log.Info("beginning refresh metrics")
 	releases, err := query.ReleasesFromDB(dbc)
@@ -112,11 +119,15 @@ func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, gcsBucket string, varian
 	}
 	hoursSinceLastUpdate.WithLabelValues().Set(time.Since(lastUpdated).Hours())
 
+	if err := refreshLoaderMetrics(dbc); err != nil {
+		log.WithError(err).Error("error refreshing loader metrics")
+	}
+
 	for _, pType := range promReportTypes {
 		// start, boundary and end will just be defaults
 		// the api will decide based on the period
 		// and current day / time
-		jobsResult, err := api.JobReportsFromDB(dbc, pType.release, pType.period, nil, time.Time{}, time.Time{}, time.Time{}, reportEnd)
+		jobsResult, err := api.JobReportsFromDB(dbc, pType.release, pType.period, nil, time.Time{}, time.Time{}, time.Time{}, reportEnd, false)
 
 		if err != nil {
 			return errors.Wrapf(err, "error refreshing prom report type %s - %s", pType.period, pType.release)
@@ -147,11 +158,11 @@ func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, gcsBucket string, varian
 	refreshPayloadMetrics(dbc, reportEnd)
 
 	if bqc != nil {
-		if err := refreshComponentReadinessMetrics(bqc, gcsBucket, cacheOptions); err != nil {
+		if err := refreshComponentReadinessMetrics(ctx, bqc, gcsBucket, cacheOptions, notifier); err != nil {
 			log.WithError(err).Error("error refreshing component readiness metrics")
 		}
 
-		if err := refreshDisruptionMetrics(bqc); err != nil {
+		if err := refreshDisruptionMetrics(ctx, bqc); err != nil {
 			log.WithError(err).Error("error refreshing disruption metrics")
 		}
 
@@ -171,7 +182,7 @@ func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, gcsBucket string, varian
 	return nil
 }
 
-func refreshComponentReadinessMetrics(client *bqclient.Client, gcsBucket string, cacheOptions cache.RequestOptions) error {
+func refreshComponentReadinessMetrics(ctx context.Context, client *bqclient.Client, gcsBucket string, cacheOptions cache.RequestOptions, notifier notify.Notifier) error {
 	if client == nil || client.BQ == nil {
 		log.Warningf("not generating component readiness metrics as we don't have a bigquery client")
 		return nil
@@ -261,7 +272,7 @@ func refreshComponentReadinessMetrics(client *bqclient.Client, gcsBucket string,
 	}
 
 	// Get report
-	rows, errs := api.GetComponentReportFromBigQuery(client, gcsBucket, baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOptions)
+	rows, errs := api.GetComponentReportFromBigQuery(ctx, client, gcsBucket, baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOptions)
 	if len(errs) > 0 {
 		var strErrors []string
 		for _, err := range errs {
@@ -275,6 +286,20 @@ func refreshComponentReadinessMetrics(client *bqclient.Client, gcsBucket string,
 			componentReadinessMetric.WithLabelValues(row.Component, col.Network, col.Arch, col.Platform).Set(float64(col.Status))
 		}
 	}
+	notifyNewRegressions(notifier, sampleRelease.Release, rows.Rows)
+
+	return nil
+}
+
+func refreshLoaderMetrics(dbc *db.DB) error {
+	var successes []models.LoaderSuccess
+	if res := dbc.DB.Find(&successes); res.Error != nil {
+		return res.Error
+	}
+
+	for _, success := range successes {
+		loadLastSuccessMetric.WithLabelValues(success.Loader).Set(float64(success.SucceededAt.Unix()))
+	}
 
 	return nil
 }
@@ -376,7 +401,7 @@ func refreshPayloadMetrics(dbc *db.DB, reportEnd time.Time) {
 // refreshDisruptionMetrics queries our BigQuery views for current release vs two weeks ago, and previous release GA.
 // Metrics are published for the delta for each NURP which can then be alerted on if certain thresholds are exceeded.
 // The previous GA view should have its release and GA date updated on each release GA.
-func refreshDisruptionMetrics(client *bqclient.Client) error {
+func refreshDisruptionMetrics(ctx context.Context, client *bqclient.Client) error {
 	if client == nil || client.BQ == nil {
 		log.Warningf("not generating disruption metrics as we don't have a bigquery client")
 		return nil
@@ -387,7 +412,7 @@ func refreshDisruptionMetrics(client *bqclient.Client) error {
 		return nil
 	}
 
-	disruptionReport, err := api.GetDisruptionVsPrevGAReportFromBigQuery(client)
+	disruptionReport, err := api.GetDisruptionVsPrevGAReportFromBigQuery(ctx, client)
 	if err != nil {
 		return fmt.Errorf("errors returned: %v", err)
 	}
@@ -410,7 +435,7 @@ func refreshDisruptionMetrics(client *bqclient.Client) error {
 			row.MasterNodesUpdated, row.Network, row.Topology, row.Architecture).Set(float64(row.Relevance))
 	}
 
-	disruptionReport, err = api.GetDisruptionVsTwoWeeksAgoReportFromBigQuery(client)
+	disruptionReport, err = api.GetDisruptionVsTwoWeeksAgoReportFromBigQuery(ctx, client)
 	if err != nil {
 		return fmt.Errorf("errors returned: %v", err)
 	}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/notify"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// maxRegressionsPerNotification keeps notifications readable when many tests regress at once.
+const maxRegressionsPerNotification = 10
+
+// knownRegressions are the regressed tests found by the previous component readiness refresh, so only new
+// regressions are notified. It is nil until the first refresh, which records the current regressions without
+// notifying, so restarting sippy doesn't notify about every open regression.
+var knownRegressions sets.String
+
+func notifyNewRegressions(notifier notify.Notifier, release string, rows []apitype.ComponentReportRow) {
+	current, regressions := findNewRegressions(knownRegressions, rows)
+	if knownRegressions == nil {
+		knownRegressions = current
+		return
+	}
+	knownRegressions = current
+
+	components := make([]string, 0, len(regressions))
+	for component := range regressions {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	for _, component := range components {
+		tests := regressions[component].tests
+		message := strings.Join(tests, "\n")
+		if len(tests) > maxRegressionsPerNotification {
+			message = strings.Join(tests[:maxRegressionsPerNotification], "\n") +
+				fmt.Sprintf("\n...and %d more", len(tests)-maxRegressionsPerNotification)
+		}
+
+		err := notifier.Notify(notify.Event{
+			Type:      notify.EventRegression,
+			Release:   release,
+			Component: component,
+			Variants:  regressions[component].variants.List(),
+			Title:     fmt.Sprintf("%d new %s regressions in %s", len(tests), component, release),
+			Message:   message,
+		})
+		if err != nil {
+			log.WithError(err).Warningf("error notifying about %s regressions", component)
+		}
+	}
+}
+
+// componentRegressions are a component's new regressions, and the variants they were found in.
+type componentRegressions struct {
+	tests    []string
+	variants sets.String
+}
+
+// findNewRegressions returns every regressed test in the component report, and the ones not in known, grouped by
+// component.
+func findNewRegressions(known sets.String, rows []apitype.ComponentReportRow) (sets.String, map[string]*componentRegressions) {
+	current := sets.NewString()
+	regressions := map[string]*componentRegressions{}
+	for _, row := range rows {
+		for _, col := range row.Columns {
+			for _, test := range col.RegressedTests {
+				if test.Status > apitype.SignificantRegression {
+					continue
+				}
+				key := fmt.Sprintf("%s|%s|%s|%s|%s|%s", test.TestID, test.Network, test.Upgrade, test.Arch, test.Platform, test.Variant)
+				if current.Has(key) {
+					continue
+				}
+				current.Insert(key)
+				if known.Has(key) {
+					continue
+				}
+				if regressions[row.Component] == nil {
+					regressions[row.Component] = &componentRegressions{variants: sets.NewString()}
+				}
+				r := regressions[row.Component]
+				r.tests = append(r.tests, fmt.Sprintf("%s (%s %s %s)", test.TestName, test.Platform, test.Arch, test.Network))
+				for _, variant := range []string{test.Platform, test.Arch, test.Network, test.Upgrade, test.Variant} {
+					if variant != "" {
+						r.variants.Insert(variant)
+					}
+				}
+			}
+		}
+	}
+	return current, regressions
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+func TestFindNewRegressions(t *testing.T) {
+	regressedTest := func(id, name, platform string, status apitype.ComponentReportStatus) apitype.ComponentReportTestSummary {
+		test := apitype.ComponentReportTestSummary{Status: status}
+		test.TestID = id
+		test.TestName = name
+		test.Platform = platform
+		test.Arch = "amd64"
+		test.Network = "ovn"
+		return test
+	}
+
+	rows := []apitype.ComponentReportRow{
+		{
+			ComponentReportRowIdentification: apitype.ComponentReportRowIdentification{Component: "Networking"},
+			Columns: []apitype.ComponentReportColumn{
+				{RegressedTests: []apitype.ComponentReportTestSummary{
+					regressedTest("1", "test a", "aws", apitype.ExtremeRegression),
+					regressedTest("2", "test b", "aws", apitype.SignificantRegression),
+					regressedTest("3", "test c", "aws", apitype.MissingSample),
+				}},
+				{RegressedTests: []apitype.ComponentReportTestSummary{
+					regressedTest("1", "test a", "gcp", apitype.SignificantRegression),
+				}},
+			},
+		},
+	}
+
+	current, regressions := findNewRegressions(sets.NewString("1|ovn||amd64|aws|"), rows)
+	assert.Equal(t, 3, current.Len())
+	require.Contains(t, regressions, "Networking")
+	assert.Equal(t, []string{"test b (aws amd64 ovn)", "test a (gcp amd64 ovn)"}, regressions["Networking"].tests)
+	assert.Equal(t, []string{"amd64", "aws", "gcp", "ovn"}, regressions["Networking"].variants.List())
+
+	_, regressions = findNewRegressions(current, rows)
+	assert.Empty(t, regressions)
+}
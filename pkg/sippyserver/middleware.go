@@ -0,0 +1,119 @@
+package sippyserver
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/logging"
+	"github.com/openshift/sippy/pkg/tracing"
+)
+
+// requestIDHandler assigns every request an ID, honoring one supplied by the caller (i.e. from a proxy). The ID is
+// placed on the response headers before the wrapped handler runs, so that error responses and logs can include it, and
+// on the request context, so that the database statements made for the request log it too.
+func requestIDHandler(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(api.RequestIDHeader)
+		if requestID == "" {
+			requestID = logging.NewRequestID()
+		}
+		w.Header().Set(api.RequestIDHeader, requestID)
+		h.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), requestID)))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// recoverHandler converts a panic in any handler into a problem response rather than a dropped connection.
+func recoverHandler(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.WithFields(log.Fields{
+					"uri":       r.URL.String(),
+					"requestID": w.Header().Get(api.RequestIDHeader),
+				}).Errorf("panic handling request: %v\n%s", rec, debug.Stack())
+				api.RespondWithError(http.StatusInternalServerError, w, fmt.Sprintf("unexpected error handling request: %v", rec))
+			}
+		}()
+		h.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+var apiRequestMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "sippy_api_request_millis",
+	Help:    "Milliseconds to respond to API requests, by route",
+	Buckets: []float64{10, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000, 300000},
+}, []string{"route", "method", "code"})
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// metricsHandler records request latency labelled by the mux pattern that matched, rather than the raw URL,
+// to keep the metric's cardinality bounded.
+func metricsHandler(mux *http.ServeMux, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		apiRequestMetric.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).
+			Observe(float64(time.Since(start).Milliseconds()))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// tracingHandler starts a server span for each request, continuing any trace propagated by the caller. Handlers
+// should pass the request's context down to the DB and cloud clients so their spans are attributed to the request.
+func tracingHandler(mux *http.ServeMux, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.target", r.URL.RequestURI()),
+				attribute.String("sippy.request_id", w.Header().Get(api.RequestIDHeader)),
+			))
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttributes(attribute.Int("http.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	}
+	return http.HandlerFunc(fn)
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"html"
+	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
@@ -72,12 +72,18 @@ type RiskAnalysisEntry struct {
 // commentUpdaterRate: the minimum duration between adding a comment before we begin work on adding the next
 // ghCommenter: the commenting implmentation
 // dryRunOnly: default is true to prevent unintended commenting when running locally or in a test deployment
-func NewWorkProcessor(dbc *db.DB, gcsBucket *storage.BucketHandle, commentAnalysisWorkers int, commentAnalysisRate, commentUpdaterRate time.Duration, ghCommenter *commenter.GitHubCommenter, dryRunOnly bool) *WorkProcessor {
+// sippyURL: the sippy instance comments link to for test details
+// checkRuns: publish results as GitHub check runs rather than comments
+// commentTemplates: templates customizing the comments, nil for the built-in comments
+func NewWorkProcessor(dbc *db.DB, gcsBucket *storage.BucketHandle, commentAnalysisWorkers int, commentAnalysisRate, commentUpdaterRate time.Duration, ghCommenter *commenter.GitHubCommenter, dryRunOnly bool, sippyURL string, checkRuns bool, commentTemplates *CommentTemplates) *WorkProcessor {
 	wp := &WorkProcessor{dbc: dbc, gcsBucket: gcsBucket, ghCommenter: ghCommenter,
 		commentAnalysisRate:    commentAnalysisRate,
 		commentUpdaterRate:     commentUpdaterRate,
 		commentAnalysisWorkers: commentAnalysisWorkers,
 		dryRunOnly:             dryRunOnly,
+		sippyURL:               sippyURL,
+		checkRuns:              checkRuns,
+		commentTemplates:       commentTemplates,
 	}
 	return wp
 }
@@ -90,6 +96,9 @@ type WorkProcessor struct {
 	gcsBucket              *storage.BucketHandle
 	ghCommenter            *commenter.GitHubCommenter
 	dryRunOnly             bool
+	sippyURL               string
+	checkRuns              bool
+	commentTemplates       *CommentTemplates
 }
 
 type PendingComment struct {
@@ -99,6 +108,10 @@ type PendingComment struct {
 	repo        string
 	number      int
 	commentType int
+
+	// riskLevel and detailsURL are used when publishing a check run
+	riskLevel  api.RiskLevel
+	detailsURL string
 }
 
 type CommentWorker struct {
@@ -106,6 +119,7 @@ type CommentWorker struct {
 	pendingComments           chan PendingComment
 	ghCommenter               *commenter.GitHubCommenter
 	dryRunOnly                bool
+	checkRuns                 bool
 }
 
 type AnalysisWorker struct {
@@ -114,11 +128,16 @@ type AnalysisWorker struct {
 	riskAnalysisLocator *regexp.Regexp
 	pendingAnalysis     chan models.PullRequestComment
 	pendingComments     chan PendingComment
+	sippyURL            string
+	checkRuns           bool
+	commentTemplates    *CommentTemplates
 }
 
 type RiskAnalysisSummary struct {
 	Name             string
 	URL              string
+	ProwJobRunID     string
+	CompareRelease   string
 	RiskLevel        api.RiskLevel
 	OverallReasons   []string
 	TestRiskAnalysis []api.ProwJobRunTestRiskAnalysis
@@ -166,6 +185,7 @@ func (wp *WorkProcessor) Run(ctx context.Context) {
 		// so that we don't make comments running locally
 		// or in a test deployment unless configured to do so
 		dryRunOnly: wp.dryRunOnly,
+		checkRuns:  wp.checkRuns,
 	}
 
 	if commentWorker.dryRunOnly {
@@ -182,7 +202,7 @@ func (wp *WorkProcessor) Run(ctx context.Context) {
 	defer close(pendingWork)
 
 	for i := 0; i < wp.commentAnalysisWorkers; i++ {
-		analysisWorker := AnalysisWorker{riskAnalysisLocator: gcs.GetDefaultRiskAnalysisSummaryFile(), dbc: wp.dbc, gcsBucket: wp.gcsBucket, pendingAnalysis: pendingWork, pendingComments: pendingComments}
+		analysisWorker := AnalysisWorker{riskAnalysisLocator: gcs.GetDefaultRiskAnalysisSummaryFile(), dbc: wp.dbc, gcsBucket: wp.gcsBucket, pendingAnalysis: pendingWork, pendingComments: pendingComments, sippyURL: wp.sippyURL, checkRuns: wp.checkRuns, commentTemplates: wp.commentTemplates}
 		go analysisWorker.Run()
 	}
 
@@ -233,6 +253,12 @@ func (wp *WorkProcessor) work(ctx context.Context, pendingWork chan models.PullR
 
 	log.Debug("Checking for work")
 
+	// leave headroom in our GitHub API quota for everything else using it
+	if wp.ghCommenter.IsWithinRateLimitThreshold() {
+		log.Warning("GitHub API quota is low, skipping WorkProcessor work cycle")
+		return nil
+	}
+
 	// get a list of items
 	// process each item one at a time while checking for shutdown
 
@@ -290,13 +316,26 @@ func (cw *CommentWorker) Run() {
 			continue
 		}
 
-		err = cw.writeComment(cw.ghCommenter, pc)
+		commented, err := cw.writeComment(cw.ghCommenter, pc)
+
+		// the record stays pending, and we'll try again once the budget resets
+		if errors.Is(err, commenter.ErrCommentBudgetExhausted) {
+			log.Warningf("Comment budget exhausted, deferring record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
+			continue
+		}
 
 		if err == nil {
 			// if we had an error writing the comment then keep the record
 			// we will attempt to process the record again and overwrite any previous comment for the same sha
-			// otherwise, clear the record
-			cw.ghCommenter.ClearPendingRecord(pc.org, pc.repo, pc.number, pc.sha, models.CommentType(pc.commentType), nil)
+			// otherwise, mark the sha as commented so we don't comment on it again, or clear the record if there was
+			// nothing to comment
+			if commented {
+				if err := cw.ghCommenter.MarkCommented(pc.org, pc.repo, pc.number, pc.sha, models.CommentType(pc.commentType)); err != nil {
+					log.WithError(err).Errorf("Error marking record commented %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
+				}
+			} else {
+				cw.ghCommenter.ClearPendingRecord(pc.org, pc.repo, pc.number, pc.sha, models.CommentType(pc.commentType), nil)
+			}
 			if errCount > 0 {
 				errCount--
 				writeCommentErrorMetric.WithLabelValues(pc.org, pc.repo).Set(errCount)
@@ -319,7 +358,8 @@ func (cw *CommentWorker) Run() {
 	}
 }
 
-func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pendingComment PendingComment) error {
+// writeComment adds the pending comment to its PR, returning true if the PR has a comment for the sha afterwards.
+func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pendingComment PendingComment) (bool, error) {
 
 	start := time.Now()
 	defer func() {
@@ -327,9 +367,15 @@ func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pe
 		writeCommentMetric.WithLabelValues(pendingComment.org, pendingComment.repo).Observe(float64(end.UnixMilli() - start.UnixMilli()))
 	}()
 
-	// if there is no comment then just delete the record
+	// if there is no comment then just delete the record, unless earlier comments on the PR are now stale
 	if pendingComment.comment == "" {
-		return nil
+		if cw.checkRuns {
+			return false, nil
+		}
+		active, err := ghCommenter.HasActivePostedComments(pendingComment.org, pendingComment.repo, pendingComment.number, models.CommentType(pendingComment.commentType))
+		if err != nil || !active {
+			return false, err
+		}
 	}
 
 	// could be that the include / exclude lists were updated
@@ -337,7 +383,7 @@ func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pe
 	// double check before we interact with github
 	// the record should still get deleted
 	if !ghCommenter.IsRepoIncluded(pendingComment.org, pendingComment.repo) {
-		return nil
+		return false, nil
 	}
 
 	logger := log.WithField("org", pendingComment.org).
@@ -349,17 +395,17 @@ func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pe
 
 	if err != nil {
 		logger.WithError(err).Error("Failed to get the current PR state")
-		return err
+		return false, err
 	}
 
 	if prEntry == nil || prEntry.SHA == "" {
 		logger.Error("Invalid sha when validating prEntry")
-		return nil
+		return false, nil
 	}
 
 	if pendingComment.commentType == int(models.CommentTypeRiskAnalysis) && prEntry.MergedAt != nil {
 		logger.Warning("PR has merged, skipping risk analysis comment")
-		return nil
+		return false, nil
 	}
 
 	if prEntry.SHA != pendingComment.sha {
@@ -369,18 +415,18 @@ func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pe
 		// we should have a new record with the current sha
 		// and will analyze latest against that
 		// we do want to delete our pending comment record though
-		// which should happen if we return nil
-		return nil
+		// which should happen if we return false, nil
+		return false, nil
 	}
 
 	if prEntry.State == nil {
 		logger.Error("Invalid state for PR")
-		return nil
+		return false, nil
 	}
 
 	if !strings.EqualFold(*prEntry.State, "open") {
 		logger.Warningf("Skipping commenting for PR state: %s", *prEntry.State)
-		return nil
+		return false, nil
 	}
 
 	// create a constant for the key
@@ -394,37 +440,49 @@ func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pe
 	// it is the default setting and needs to be overridden in production / live commenting instances
 	if cw.dryRunOnly {
 		logger.Infof("Dry run comment for: %s\n%s", commentID, pendingComment.comment)
-		return nil
+		return false, nil
+	}
+
+	// the latest sha has nothing to report, so earlier comments are outdated
+	if pendingComment.comment == "" {
+		logger.Info("Minimizing stale comments")
+		return false, ghCommenter.MinimizePostedComments(pendingComment.org, pendingComment.repo, pendingComment.number, models.CommentType(pendingComment.commentType))
+	}
+
+	if cw.checkRuns {
+		logger.Infof("Adding check run for id: %s", commentID)
+		if err := ghCommenter.AddCheckRun(pendingComment.org, pendingComment.repo, pendingComment.sha,
+			checkRunConclusion(pendingComment.riskLevel), fmt.Sprintf("Job failure risk: %s", pendingComment.riskLevel.Name),
+			pendingComment.comment, pendingComment.detailsURL); err != nil {
+			return false, err
+		}
+		return true, nil
 	}
 
 	ghcomment := fmt.Sprintf("<!-- META={\"%s\": \"%s\"} -->\n\n%s", commenter.TrtCommentIDKey, commentID, pendingComment.comment)
 
-	// is there an existing comment of our type that we should remove
-	existingCommentID, commentBody, err := ghCommenter.FindExistingCommentID(pendingComment.org, pendingComment.repo, pendingComment.number, commenter.TrtCommentIDKey, commentID)
+	// have we already commented on this sha
+	existingCommentID, _, err := ghCommenter.FindExistingCommentID(pendingComment.org, pendingComment.repo, pendingComment.number, commenter.TrtCommentIDKey, commentID)
 
 	// for now, we return any errors when interacting with gitHub so that we backoff our processing rate
 	// to do, select which ones indicate a need to backoff
 	if err != nil {
-		return err
+		return false, err
 	}
 
+	// we only comment once per sha, so leave any existing comment in place rather than notifying everyone on the PR
+	// again, i.e. when our record of the comment was lost
 	if existingCommentID != nil {
-		// compare the current body against the pending body
-		// if they are the same then don't comment again
-		if commentBody != nil && strings.TrimSpace(*commentBody) == strings.TrimSpace(ghcomment) {
-			logger.Infof("Existing comment matches pending comment for id: %s", commentID)
-			return nil
-		}
-		// we delete the existing comment and add a new one so the comment will be at the end of the comment list
-		err = ghCommenter.DeleteComment(pendingComment.org, pendingComment.repo, *existingCommentID)
-		// if we had an error then return it, the record will remain, and we will attempt processing again later
-		if err != nil {
-			return err
-		}
+		logger.Infof("Existing comment found for id: %s", commentID)
+		return true, nil
 	}
 
-	logger.Infof("Adding comment id: %s", commentID)
-	return ghCommenter.AddComment(pendingComment.org, pendingComment.repo, pendingComment.number, ghcomment)
+	logger.Infof("Posting comment id: %s", commentID)
+	if err := ghCommenter.PostComment(pendingComment.org, pendingComment.repo, pendingComment.number, pendingComment.sha,
+		models.CommentType(pendingComment.commentType), pendingComment.riskLevel.Name, ghcomment); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (aw *AnalysisWorker) Run() {
@@ -490,6 +548,8 @@ func (aw *AnalysisWorker) processRiskAnalysisComment(prPendingComment models.Pul
 	// when the comment processor sees an empty comment it will
 	// not create a comment but will delete the comment record
 	comment := ""
+	riskLevel := api.FailureRiskLevelNone
+	detailsURL := ""
 	if len(analysis) > 0 {
 
 		sortedAnalysis := make(RiskAnalysisEntryList, 0)
@@ -498,7 +558,28 @@ func (aw *AnalysisWorker) processRiskAnalysisComment(prPendingComment models.Pul
 		}
 		sort.Sort(sortedAnalysis)
 
-		comment = buildComment(sortedAnalysis, prPendingComment.SHA)
+		var templated bool
+		var err error
+		comment, templated, err = aw.commentTemplates.riskAnalysisComment(RiskAnalysisCommentData{
+			Org:         prPendingComment.Org,
+			Repo:        prPendingComment.Repo,
+			SHA:         prPendingComment.SHA,
+			OverallRisk: sortedAnalysis[0].Value.RiskLevel.Name,
+			Jobs:        sortedAnalysis,
+		})
+		if err != nil {
+			// leave the record pending, so it is retried once the template is fixed
+			logger.WithError(err).Error("Failed to render risk analysis comment template")
+			return
+		}
+		if !templated {
+			comment = buildComment(sortedAnalysis, prPendingComment.SHA, aw.sippyURL)
+		}
+		riskLevel = sortedAnalysis[0].Value.RiskLevel
+		detailsURL = riskAnalysisDetailsURL(aw.sippyURL, sortedAnalysis[0].Value)
+	} else if aw.checkRuns {
+		// a check run is always published, so it can be required by branch protection
+		comment = fmt.Sprintf("No risky job failures for sha: %s", prPendingComment.SHA)
 	}
 
 	pendingComment := PendingComment{
@@ -508,6 +589,8 @@ func (aw *AnalysisWorker) processRiskAnalysisComment(prPendingComment models.Pul
 		repo:        prPendingComment.Repo,
 		number:      prPendingComment.PullNumber,
 		commentType: prPendingComment.CommentType,
+		riskLevel:   riskLevel,
+		detailsURL:  detailsURL,
 	}
 
 	// will block if the buffer is full
@@ -516,9 +599,14 @@ func (aw *AnalysisWorker) processRiskAnalysisComment(prPendingComment models.Pul
 	log.Debugf("Comment added to pendingComments: %s/%s/%s", pendingComment.org, pendingComment.repo, pendingComment.sha)
 }
 
-func buildComment(sortedAnalysis RiskAnalysisEntryList, sha string) string {
+// buildComment renders the risk analysis of a PR's jobs, sorted highest risk first, as a markdown table. Failed tests
+// link to their sippy test details when sippyURL is set.
+func buildComment(sortedAnalysis RiskAnalysisEntryList, sha, sippyURL string) string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Job Failure Risk Analysis for sha: %s\n\n| Job Name | Failure Risk |\n|:---|:---|\n", sha))
+	sb.WriteString(fmt.Sprintf("Job Failure Risk Analysis for sha: %s\n\n", sha))
+	sb.WriteString(fmt.Sprintf("Overall failure risk: **%s** (%d risky %s)\n\n",
+		sortedAnalysis[0].Value.RiskLevel.Name, len(sortedAnalysis), pluralize("job", len(sortedAnalysis))))
+	sb.WriteString("| Job Name | Failure Risk |\n|:---|:---|\n")
 
 	// don't want the comment to be too large so if we have a high number of jobs to analyze
 	// reduce the max tests / reasons we show
@@ -562,7 +650,7 @@ func buildComment(sortedAnalysis RiskAnalysisEntryList, sha string) string {
 				if i > 0 {
 					riskSb.WriteString("<br>---")
 				}
-				riskSb.WriteString(fmt.Sprintf("<br>*%s*", t.Name))
+				riskSb.WriteString(fmt.Sprintf("<br>*%s*", testLink(sippyURL, value.Value.CompareRelease, t.Name)))
 				for j, r := range t.Risk.Reasons {
 					if j > maxSubRows {
 						riskSb.WriteString(fmt.Sprintf("<br>Showing %d of %d test risk reasons", j, len(t.Risk.Reasons)))
@@ -582,8 +670,7 @@ func buildComment(sortedAnalysis RiskAnalysisEntryList, sha string) string {
 
 						riskSb.WriteString("Open Bugs")
 					}
-					// prevent the openshift-ci bot from detecting JIRA references in the link by replacing - with html escaped sequence
-					riskSb.WriteString(fmt.Sprintf("<br>[%s](%s)", strings.ReplaceAll(html.EscapeString(b.Summary), "-", "&#45;"), b.URL))
+					riskSb.WriteString(fmt.Sprintf("<br>[%s](%s)", escapeBugSummary(b.Summary), b.URL))
 				}
 			}
 		}
@@ -594,6 +681,41 @@ func buildComment(sortedAnalysis RiskAnalysisEntryList, sha string) string {
 	return sb.String()
 }
 
+// checkRunConclusion fails the check run for high risk failures, which are unlikely to be flakes, and is neutral
+// otherwise so it never blocks a PR on its own.
+func checkRunConclusion(riskLevel api.RiskLevel) string {
+	if riskLevel.Level >= api.FailureRiskLevelHigh.Level {
+		return "failure"
+	}
+	return "neutral"
+}
+
+// riskAnalysisDetailsURL links to sippy's risk analysis of the job run, falling back to the job run in prow.
+func riskAnalysisDetailsURL(sippyURL string, summary RiskAnalysisSummary) string {
+	if sippyURL == "" || summary.ProwJobRunID == "" {
+		return summary.URL
+	}
+	return fmt.Sprintf("%s/api/jobs/runs/risk_analysis?prow_job_run_id=%s",
+		strings.TrimSuffix(sippyURL, "/"), url.QueryEscape(summary.ProwJobRunID))
+}
+
+// testLink links a test name to its sippy test details for the release, or returns the name when either is unknown.
+func testLink(sippyURL, release, testName string) string {
+	if sippyURL == "" || release == "" {
+		return testName
+	}
+	testURL := fmt.Sprintf("%s/sippy-ng/tests/%s/analysis?test=%s",
+		strings.TrimSuffix(sippyURL, "/"), url.PathEscape(release), url.QueryEscape(testName))
+	return fmt.Sprintf("[%s](%s)", testName, testURL)
+}
+
+func pluralize(word string, count int) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
+
 // buildPRJobRiskAnalysis walks the GCS path for this PR to find the most recent job runs,
 // if any have not finished it returns false
 // otherwise returns a map of the test name and the overall RiskAnalysis for that test
@@ -724,11 +846,14 @@ func (aw *AnalysisWorker) buildPRJobRiskAnalysis(prRoot string, dryrun bool) (bo
 			continue
 		}
 
-		riskSummary, _ := aw.getRiskSummary(latest, latestPath, priorRiskAnalysis)
+		riskSummary, riskAnalysis := aw.getRiskSummary(latest, latestPath, priorRiskAnalysis)
 
 		// don't include none or unknown in our report
 		if riskSummary.OverallRisk.Level != api.FailureRiskLevelNone && riskSummary.OverallRisk.Level != api.FailureRiskLevelUnknown {
-			riskAnalysisSummary := RiskAnalysisSummary{Name: jobName, URL: prowLink, RiskLevel: riskSummary.OverallRisk.Level, OverallReasons: riskSummary.OverallRisk.Reasons, TestRiskAnalysis: riskSummary.Tests}
+			riskAnalysisSummary := RiskAnalysisSummary{Name: jobName, URL: prowLink, ProwJobRunID: latest, RiskLevel: riskSummary.OverallRisk.Level, OverallReasons: riskSummary.OverallRisk.Reasons, TestRiskAnalysis: riskSummary.Tests}
+			if riskAnalysis != nil {
+				riskAnalysisSummary.CompareRelease = riskAnalysis.CompareRelease
+			}
 			analysisByJobs[jobName] = riskAnalysisSummary
 		}
 	}
@@ -87,3 +87,62 @@ func TestMatchPriorRiskAnalysisTest(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildComment(t *testing.T) {
+	analysis := RiskAnalysisEntryList{
+		{
+			Key: "pull-ci-openshift-origin-master-e2e-aws-ovn",
+			Value: RiskAnalysisSummary{
+				Name:           "pull-ci-openshift-origin-master-e2e-aws-ovn",
+				URL:            "https://prow.ci.openshift.org/view/gs/1",
+				CompareRelease: "4.14",
+				RiskLevel:      api.FailureRiskLevelHigh,
+				TestRiskAnalysis: []api.ProwJobRunTestRiskAnalysis{
+					{
+						Name: "[sig-arch] Only known images used by tests",
+						Risk: api.FailureRisk{
+							Level:   api.FailureRiskLevelHigh,
+							Reasons: []string{"This test has passed 99.04% of 3767 runs on release 4.14 [Overall] in the last week."},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		sippyURL         string
+		expectedTestText string
+	}{
+		"LinksTests": {
+			sippyURL:         "https://sippy.example.com/",
+			expectedTestText: "*[[sig-arch] Only known images used by tests](https://sippy.example.com/sippy-ng/tests/4.14/analysis?test=%5Bsig-arch%5D+Only+known+images+used+by+tests)*",
+		},
+		"NoSippyURL": {
+			expectedTestText: "*[sig-arch] Only known images used by tests*",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			comment := buildComment(analysis, "abc123", tc.sippyURL)
+
+			assert.Contains(t, comment, "Job Failure Risk Analysis for sha: abc123")
+			assert.Contains(t, comment, "Overall failure risk: **High** (1 risky job)")
+			assert.Contains(t, comment, tc.expectedTestText)
+		})
+	}
+}
+
+func TestCheckRunConclusion(t *testing.T) {
+	assert.Equal(t, "failure", checkRunConclusion(api.FailureRiskLevelHigh))
+	assert.Equal(t, "neutral", checkRunConclusion(api.FailureRiskLevelMissingData))
+	assert.Equal(t, "neutral", checkRunConclusion(api.FailureRiskLevelNone))
+}
+
+func TestRiskAnalysisDetailsURL(t *testing.T) {
+	summary := RiskAnalysisSummary{URL: "https://prow.ci.openshift.org/view/gs/job/123", ProwJobRunID: "123"}
+	assert.Equal(t, "https://sippy.example.com/api/jobs/runs/risk_analysis?prow_job_run_id=123",
+		riskAnalysisDetailsURL("https://sippy.example.com/", summary))
+	assert.Equal(t, summary.URL, riskAnalysisDetailsURL("", summary))
+}
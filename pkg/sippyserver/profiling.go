@@ -0,0 +1,42 @@
+package sippyserver
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/openshift/sippy/pkg/api"
+)
+
+// SetProfilingToken serves the pprof profiles under /debug/pprof/ to requests with the token as a bearer token. With
+// no token, they aren't served. Profiles expose the process's memory, so they're never served without one.
+func (s *Server) SetProfilingToken(token string) {
+	s.profilingToken = token
+}
+
+func (s *Server) registerProfiling(mux *http.ServeMux) {
+	if s.profilingToken == "" {
+		return
+	}
+	mux.Handle("/debug/pprof/", s.profilingAuth(http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", s.profilingAuth(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", s.profilingAuth(http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", s.profilingAuth(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", s.profilingAuth(http.HandlerFunc(pprof.Trace)))
+}
+
+// profilingAuth rejects requests without the profiling token.
+func (s *Server) profilingAuth(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(s.profilingToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			api.RespondWithError(http.StatusUnauthorized, w, "profiling requires the profiling token")
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,95 @@
+package sippyserver
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RefreshScheduler periodically refreshes data, such as the materialized views and regressions, while the server runs,
+// so it doesn't rely on a loader calling refresh when it finishes.
+type RefreshScheduler struct {
+	interval time.Duration
+	jitter   time.Duration
+	refresh  func(trigger string)
+
+	// inFlight is held while refreshing, a tick or API request that can't take it is skipped
+	inFlight sync.Mutex
+}
+
+// NewRefreshScheduler returns a scheduler that refreshes every interval plus a random delay up to jitter, so several
+// replicas don't all refresh at once. The refresh is passed what triggered it, i.e. schedule or api.
+func NewRefreshScheduler(interval, jitter time.Duration, refresh func(trigger string)) *RefreshScheduler {
+	return &RefreshScheduler{
+		interval: interval,
+		jitter:   jitter,
+		refresh:  refresh,
+	}
+}
+
+// Run refreshes on schedule until the context is cancelled.
+func (r *RefreshScheduler) Run(ctx context.Context) {
+	log.Infof("refreshing data every %s with up to %s jitter", r.interval, r.jitter)
+	for {
+		timer := time.NewTimer(r.nextDelay())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			// refresh in the background, so a refresh taking longer than the interval causes later ticks to be
+			// skipped rather than delayed
+			go r.TryRefresh("schedule")
+		}
+	}
+}
+
+// TryRefresh refreshes unless a refresh is already in flight, returning whether it did.
+func (r *RefreshScheduler) TryRefresh(trigger string) bool {
+	if !r.inFlight.TryLock() {
+		log.Warningf("skipping %s refresh, a refresh is already in flight", trigger)
+		return false
+	}
+	defer r.inFlight.Unlock()
+
+	r.run(trigger)
+	return true
+}
+
+// Start refreshes in the background unless a refresh is already in flight, returning whether it started one.
+func (r *RefreshScheduler) Start(trigger string) bool {
+	if !r.inFlight.TryLock() {
+		log.Warningf("not starting %s refresh, a refresh is already in flight", trigger)
+		return false
+	}
+	go func() {
+		defer r.inFlight.Unlock()
+		r.run(trigger)
+	}()
+	return true
+}
+
+// InFlight returns whether a refresh is in flight.
+func (r *RefreshScheduler) InFlight() bool {
+	if !r.inFlight.TryLock() {
+		return true
+	}
+	r.inFlight.Unlock()
+	return false
+}
+
+func (r *RefreshScheduler) run(trigger string) {
+	start := time.Now()
+	r.refresh(trigger)
+	log.Infof("%s refresh took %s", trigger, time.Since(start))
+}
+
+func (r *RefreshScheduler) nextDelay() time.Duration {
+	if r.jitter <= 0 {
+		return r.interval
+	}
+	return r.interval + time.Duration(rand.Int63n(int64(r.jitter))) //nolint:gosec
+}
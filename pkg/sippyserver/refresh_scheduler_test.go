@@ -0,0 +1,80 @@
+package sippyserver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshSchedulerSkipsInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var refreshes int32
+	scheduler := NewRefreshScheduler(time.Hour, 0, func(string) {
+		atomic.AddInt32(&refreshes, 1)
+		started <- struct{}{}
+		<-release
+	})
+
+	done := make(chan bool)
+	go func() { done <- scheduler.TryRefresh("schedule") }()
+	<-started
+
+	assert.False(t, scheduler.TryRefresh("schedule"), "refresh should be skipped while one is in flight")
+	close(release)
+	assert.True(t, <-done)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+}
+
+func TestRefreshSchedulerStart(t *testing.T) {
+	started, release, done := make(chan string), make(chan struct{}), make(chan struct{})
+	scheduler := NewRefreshScheduler(time.Hour, 0, func(trigger string) {
+		started <- trigger
+		<-release
+		close(done)
+	})
+
+	assert.False(t, scheduler.InFlight())
+	assert.True(t, scheduler.Start("api"))
+	assert.Equal(t, "api", <-started)
+	assert.True(t, scheduler.InFlight())
+	assert.False(t, scheduler.Start("api"), "refresh shouldn't start while one is in flight")
+
+	close(release)
+	<-done
+	assert.Eventually(t, func() bool { return !scheduler.InFlight() }, 5*time.Second, time.Millisecond)
+}
+
+func TestRefreshSchedulerRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	refreshed := make(chan struct{}, 10)
+	scheduler := NewRefreshScheduler(time.Millisecond, time.Millisecond, func(string) {
+		refreshed <- struct{}{}
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler never refreshed")
+	}
+	cancel()
+	<-stopped
+}
+
+func TestRefreshSchedulerDelay(t *testing.T) {
+	scheduler := NewRefreshScheduler(time.Hour, 10*time.Minute, nil)
+	for i := 0; i < 100; i++ {
+		delay := scheduler.nextDelay()
+		assert.GreaterOrEqual(t, delay, time.Hour)
+		assert.Less(t, delay, time.Hour+10*time.Minute)
+	}
+	assert.Equal(t, time.Hour, NewRefreshScheduler(time.Hour, 0, nil).nextDelay())
+}
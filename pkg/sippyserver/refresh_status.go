@@ -0,0 +1,177 @@
+package sippyserver
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// SetRefreshScheduler sets the scheduler refreshing the data, which refreshes requested from the API are started with,
+// so they aren't run alongside a scheduled refresh.
+func (s *Server) SetRefreshScheduler(scheduler *RefreshScheduler) {
+	s.refreshScheduler = scheduler
+}
+
+// jsonRefreshStatus returns the refresh in progress and the last one to finish on GET, and starts a refresh on a POST
+// by an authenticated user.
+func (s *Server) jsonRefreshStatus(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		status, err := api.GetRefreshStatusFromDB(s.db.WithContext(req.Context()))
+		if err != nil {
+			log.WithError(err).Error("error querying refresh status")
+			api.RespondWithError(http.StatusInternalServerError, w, "error querying refresh status")
+			return
+		}
+		status.InFlight = s.refreshScheduler != nil && s.refreshScheduler.InFlight()
+		api.RespondWithJSON(http.StatusOK, w, status)
+	case http.MethodPost:
+		if auditUser(req) == "anonymous" {
+			api.RespondWithError(http.StatusForbidden, w, "refreshes can only be started by an authenticated user")
+			return
+		}
+		if s.refreshScheduler == nil {
+			api.RespondWithError(http.StatusServiceUnavailable, w, "server not configured to refresh data")
+			return
+		}
+		if !s.refreshScheduler.Start("api") {
+			api.RespondWithError(http.StatusConflict, w, "a refresh is already in flight")
+			return
+		}
+		api.RespondWithJSON(http.StatusAccepted, w, map[string]string{"status": "refresh started"})
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "the refresh status is read with GET, or a refresh started with POST")
+	}
+}
+
+// refreshRecorder records a refresh's progress as a RefreshRun, saving it as each step starts and finishes. A nil
+// recorder records nothing, so refreshes without a database, or whose run couldn't be created, still proceed.
+type refreshRecorder struct {
+	dbc  *db.DB
+	lock sync.Mutex
+	run  models.RefreshRun
+}
+
+// startRefreshRecorder creates the run of a refresh triggered by the trigger.
+func startRefreshRecorder(dbc *db.DB, trigger string) *refreshRecorder {
+	if dbc == nil {
+		return nil
+	}
+	r := &refreshRecorder{
+		dbc: dbc,
+		run: models.RefreshRun{
+			Trigger:   trigger,
+			Status:    models.RefreshRunning,
+			StartedAt: time.Now(),
+			Steps:     models.RefreshSteps{},
+		},
+	}
+	if res := dbc.DB.Create(&r.run); res.Error != nil {
+		log.WithError(res.Error).Warning("couldn't record refresh, its progress won't be reported")
+		return nil
+	}
+	return r
+}
+
+// startStep records a step as running, returning its index for finishStep.
+func (r *refreshRecorder) startStep(kind, name string) int {
+	if r == nil {
+		return -1
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	r.run.Steps = append(r.run.Steps, models.RefreshStep{
+		Kind:      kind,
+		Name:      name,
+		Status:    models.RefreshRunning,
+		StartedAt: &now,
+	})
+	r.save()
+	return len(r.run.Steps) - 1
+}
+
+// finishStep records how a started step ended.
+func (r *refreshRecorder) finishStep(step int, skipped bool, err error) {
+	if r == nil || step < 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	recorded := &r.run.Steps[step]
+	recorded.DurationMS = time.Since(*recorded.StartedAt).Milliseconds()
+	switch {
+	case err != nil:
+		recorded.Status = models.RefreshFailed
+		recorded.Error = err.Error()
+		r.run.Errors++
+	case skipped:
+		recorded.Status = models.RefreshSkipped
+	default:
+		recorded.Status = models.RefreshSucceeded
+	}
+	r.save()
+}
+
+// skipStep records a step that didn't run.
+func (r *refreshRecorder) skipStep(kind, name string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.run.Steps = append(r.run.Steps, models.RefreshStep{Kind: kind, Name: name, Status: models.RefreshSkipped})
+	r.save()
+}
+
+// countOpenRegressions records how many test regressions are open, once they've been recomputed.
+func (r *refreshRecorder) countOpenRegressions() {
+	if r == nil {
+		return
+	}
+	var count int64
+	res := r.dbc.DB.Model(&models.TestRegression{}).Where("status = ?", models.TestRegressionOpen).Count(&count)
+	if res.Error != nil {
+		log.WithError(res.Error).Warning("couldn't count open test regressions")
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	open := int(count)
+	r.run.OpenRegressions = &open
+	r.save()
+}
+
+// finish records the refresh as finished, failed if any of its steps did.
+func (r *refreshRecorder) finish() {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	r.run.FinishedAt = &now
+	r.run.Status = models.RefreshSucceeded
+	if r.run.Errors > 0 {
+		r.run.Status = models.RefreshFailed
+	}
+	r.save()
+}
+
+// save must be called with the lock held.
+func (r *refreshRecorder) save() {
+	if res := r.dbc.DB.Save(&r.run); res.Error != nil {
+		log.WithError(res.Error).Warning("couldn't record refresh progress")
+	}
+}
@@ -0,0 +1,49 @@
+package sippyserver
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+func TestRefreshInDependencyOrder(t *testing.T) {
+	views := []db.PostgresMaterializedView{
+		{Name: "runs"},
+		{Name: "tests"},
+		{Name: "runs_by_day", DependsOn: []string{"runs"}},
+		{Name: "tests_by_run", DependsOn: []string{"runs", "tests"}},
+		{Name: "summary", DependsOn: []string{"runs_by_day", "tests_by_run", "not_selected"}},
+	}
+
+	var lock sync.Mutex
+	refreshed := map[string]int{}
+	order := 0
+	refreshInDependencyOrder(views, 3, func(matView string) {
+		lock.Lock()
+		defer lock.Unlock()
+		order++
+		refreshed[matView] = order
+	})
+
+	assert.Len(t, refreshed, len(views))
+	for _, pmv := range views {
+		for _, dependency := range pmv.DependsOn {
+			if dependency == "not_selected" {
+				continue
+			}
+			assert.Less(t, refreshed[dependency], refreshed[pmv.Name], "%s refreshed before %s", pmv.Name, dependency)
+		}
+	}
+}
+
+func TestSelectMatViews(t *testing.T) {
+	views := selectMatViews([]string{"prow_test_durations_14d_matview", "prow_test_report_7d_matview", "missing"})
+	names := make([]string, 0, len(views))
+	for _, pmv := range views {
+		names = append(names, pmv.Name)
+	}
+	assert.Equal(t, []string{"prow_test_report_7d_matview", "prow_test_durations_14d_matview"}, names)
+}
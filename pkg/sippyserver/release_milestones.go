@@ -0,0 +1,46 @@
+package sippyserver
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+// SetReleaseMilestones sets the milestones of each release from the config, it is called again when the config is
+// reloaded.
+func (s *Server) SetReleaseMilestones(releases map[string]v1.ReleaseConfig) {
+	milestones := map[string]v1.ReleaseMilestones{}
+	for release, config := range releases {
+		milestones[release] = config.Milestones
+	}
+	s.milestonesLock.Lock()
+	defer s.milestonesLock.Unlock()
+	s.milestones = milestones
+}
+
+func (s *Server) releaseMilestones(release string) v1.ReleaseMilestones {
+	s.milestonesLock.RLock()
+	defer s.milestonesLock.RUnlock()
+	return s.milestones[release]
+}
+
+// jsonReleaseMilestoneReport returns the release's milestones and phase, with its results since each milestone
+// reached.
+func (s *Server) jsonReleaseMilestoneReport(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	report, err := api.GetReleaseMilestoneReportFromDB(s.db.WithContext(req.Context()), release,
+		s.releaseMilestones(release), s.GetReportEnd())
+	if err != nil {
+		log.WithError(err).Error("error querying release milestones")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying release milestones")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, report)
+}
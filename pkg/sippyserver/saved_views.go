@@ -0,0 +1,120 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// maxSavedViewBytes caps the size of a saved view request.
+const maxSavedViewBytes = 64 * 1024
+
+// jsonSavedViews lists the global views and the user's own on GET, and creates a view from a POSTed
+// SavedViewRequest.
+func (s *Server) jsonSavedViews(w http.ResponseWriter, req *http.Request) {
+	user := auditUser(req)
+	dbc := s.db.WithContext(req.Context())
+	switch req.Method {
+	case http.MethodGet:
+		views, err := api.ListSavedViewsFromDB(dbc, user)
+		if err != nil {
+			log.WithError(err).Error("error querying saved views")
+			api.RespondWithError(http.StatusInternalServerError, w, "error querying saved views")
+			return
+		}
+		api.RespondWithJSON(http.StatusOK, w, views)
+	case http.MethodPost:
+		view, ok := decodeSavedView(w, req, user, "")
+		if !ok {
+			return
+		}
+		if err := api.CreateSavedView(dbc, view); err != nil {
+			api.RespondWithProblemOrError(w, err, "saving saved view")
+			return
+		}
+		setAuditAfter(req, view)
+		api.RespondWithJSON(http.StatusCreated, w, view)
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "saved views are listed with GET, or created with POST")
+	}
+}
+
+// jsonSavedView gets, replaces with a PUT SavedViewRequest, or deletes the saved view with the slug in the path,
+// /api/saved_views/<slug>.
+func (s *Server) jsonSavedView(w http.ResponseWriter, req *http.Request) {
+	slug := strings.TrimPrefix(req.URL.Path, "/api/saved_views/")
+	if slug == "" || strings.Contains(slug, "/") {
+		api.RespondWithError(http.StatusNotFound, w, "saved views are at /api/saved_views/<slug>")
+		return
+	}
+
+	user := auditUser(req)
+	dbc := s.db.WithContext(req.Context())
+	view, err := api.GetSavedViewFromDB(dbc, slug)
+	if err != nil {
+		log.WithError(err).Error("error querying saved view")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying saved view")
+		return
+	}
+	if view == nil {
+		api.RespondWithError(http.StatusNotFound, w, "no saved view "+slug)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		api.RespondWithJSON(http.StatusOK, w, view)
+	case http.MethodPut:
+		updated, ok := decodeSavedView(w, req, user, slug)
+		if !ok {
+			return
+		}
+		before := *view
+		if err := api.UpdateSavedView(dbc, view, updated, user); err != nil {
+			api.RespondWithProblemOrError(w, err, "saving saved view")
+			return
+		}
+		setAuditBefore(req, before)
+		setAuditAfter(req, view)
+		api.RespondWithJSON(http.StatusOK, w, view)
+	case http.MethodDelete:
+		if err := api.DeleteSavedView(dbc, view, user); err != nil {
+			api.RespondWithProblemOrError(w, err, "deleting saved view")
+			return
+		}
+		setAuditBefore(req, view)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "saved views are fetched with GET, replaced with PUT, or deleted with DELETE")
+	}
+}
+
+// decodeSavedView reads the SavedViewRequest in the body for an authenticated user, defaulting its slug.
+func decodeSavedView(w http.ResponseWriter, req *http.Request, user, slug string) (*models.SavedView, bool) {
+	if user == "anonymous" {
+		api.RespondWithError(http.StatusForbidden, w, "saved views can only be changed by an authenticated user")
+		return nil, false
+	}
+
+	viewReq := apitype.SavedViewRequest{}
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxSavedViewBytes)).Decode(&viewReq); err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "couldn't decode saved view: "+err.Error())
+		return nil, false
+	}
+	if viewReq.Slug == "" {
+		viewReq.Slug = slug
+	}
+	view, err := api.NewSavedView(user, viewReq)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
+		return nil, false
+	}
+	return view, true
+}
@@ -1,8 +1,10 @@
 package sippyserver
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"net/http/httptest"
@@ -17,26 +19,42 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/push"
+	"gorm.io/gorm"
 
 	"github.com/openshift/sippy/pkg/bigquery"
 
+	"github.com/openshift/sippy/pkg/api/failureclusters"
 	"github.com/openshift/sippy/pkg/api/jobrunintervals"
 	"github.com/openshift/sippy/pkg/apis/cache"
+	"github.com/openshift/sippy/pkg/archive"
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
 
 	"github.com/openshift/sippy/pkg/db/models"
 
 	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/federation"
 	"github.com/openshift/sippy/pkg/filter"
+	"github.com/openshift/sippy/pkg/funnel"
+	"github.com/openshift/sippy/pkg/handoff"
+	"github.com/openshift/sippy/pkg/history"
+	"github.com/openshift/sippy/pkg/notify"
+	"github.com/openshift/sippy/pkg/silentjobs"
 	"github.com/openshift/sippy/pkg/synthetictests"
+	"github.com/openshift/sippy/pkg/testanalysis"
+	"github.com/openshift/sippy/pkg/testquarantine"
 	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift/sippy/pkg/api"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/querytemplates"
+	"github.com/openshift/sippy/pkg/regressiondetection"
 	"github.com/openshift/sippy/pkg/testidentification"
+	"github.com/openshift/sippy/pkg/tools"
 )
 
 // Mode defines the server mode of operation, OpenShift or upstream Kubernetes.
@@ -61,6 +79,7 @@ func NewServer(
 	pinnedDateTime *time.Time,
 	cacheClient cache.Cache,
 	crTimeRoundingFactor time.Duration,
+	readinessMaxDataAge time.Duration,
 ) *Server {
 
 	server := &Server{
@@ -77,10 +96,11 @@ func NewServer(
 		gcsClient:            gcsClient,
 		cache:                cacheClient,
 		crTimeRoundingFactor: crTimeRoundingFactor,
+		readinessMaxDataAge:  readinessMaxDataAge,
 	}
 
 	if bigQueryClient != nil {
-		go api.GetComponentTestVariantsFromBigQuery(bigQueryClient, gcsBucket)
+		go api.GetComponentTestVariantsFromBigQuery(context.Background(), bigQueryClient, gcsBucket)
 	}
 
 	return server
@@ -98,6 +118,11 @@ var allMatViewsRefreshMetric = promauto.NewHistogram(prometheus.HistogramOpts{
 	Buckets: []float64{5000, 10000, 30000, 60000, 300000, 600000, 1200000, 1800000, 2400000, 3000000, 3600000},
 })
 
+var matViewLastRefreshMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "sippy_matview_last_refresh_timestamp_seconds",
+	Help: "Unix timestamp of the last successful refresh of each postgresql materialized view",
+}, []string{"view"})
+
 type Server struct {
 	mode                 Mode
 	listenAddr           string
@@ -106,6 +131,9 @@ type Server struct {
 	sippyNG              fs.FS
 	static               fs.FS
 	httpServer           *http.Server
+	serveMux             http.Handler
+	httpServerLock       sync.Mutex
+	shutdown             bool
 	db                   *db.DB
 	bigQueryClient       *bigquery.Client
 	pinnedDateTime       *time.Time
@@ -113,54 +141,64 @@ type Server struct {
 	gcsBucket            string
 	cache                cache.Cache
 	crTimeRoundingFactor time.Duration
+	readinessMaxDataAge  time.Duration
+	tenant               string
+	warmReleases         int
+	warmPaths            []string
+	asyncWorkers         int
+	asyncQueue           chan string
+	stopAsyncWorkers     context.CancelFunc
+	profilingToken       string
+	archiveStore         archive.Store
+	federation           *federation.Federation
+	milestones           map[string]v1.ReleaseMilestones
+	milestonesLock       sync.RWMutex
+	refreshScheduler     *RefreshScheduler
+	testRenames          []v1.TestRenameConfig
+	testRenamesLock      sync.RWMutex
 }
 
 func (s *Server) GetReportEnd() time.Time {
 	return util.GetReportEnd(s.pinnedDateTime)
 }
 
+// matViewRefreshWorkers is how many materialized views are refreshed at once.
+const matViewRefreshWorkers = 2
+
 // refreshMaterializedViews updates the postgresql materialized views backing our reports. It is called by the handler
 // for the /refresh API endpoint, which is called by the sidecar script which loads the new data from testgrid into the
 // main postgresql tables.
 //
 // refreshMatviewOnlyIfEmpty is used on startup to indicate that we want to do an initial refresh *only* if
 // the views appear to be empty.
-func refreshMaterializedViews(dbc *db.DB, refreshMatviewOnlyIfEmpty bool) {
+func refreshMaterializedViews(dbc *db.DB, refreshMatviewOnlyIfEmpty bool, views []string, recorder *refreshRecorder) {
 	var promPusher *push.Pusher
 	if pushgateway := os.Getenv("SIPPY_PROMETHEUS_PUSHGATEWAY"); pushgateway != "" {
 		promPusher = push.New(pushgateway, "sippy-matviews")
 		promPusher.Collector(matViewRefreshMetric)
 		promPusher.Collector(allMatViewsRefreshMetric)
+		promPusher.Collector(matViewLastRefreshMetric)
 	}
 
-	log.Info("refreshing materialized views")
+	log.Infof("refreshing %d materialized views", len(views))
 	allStart := time.Now()
 
 	if dbc == nil {
 		log.Info("skipping materialized view refresh as server has no db connection provided")
 		return
 	}
-	// create a channel for work "tasks"
-	ch := make(chan string)
-
-	wg := sync.WaitGroup{}
-
-	// allow concurrent workers for refreshing matviews in parallel
-	for t := 0; t < 2; t++ {
-		wg.Add(1)
-		go refreshMatview(dbc, refreshMatviewOnlyIfEmpty, ch, &wg)
-	}
-
-	for _, pmv := range db.PostgresMatViews {
-		ch <- pmv.Name
-	}
 
-	close(ch)
-	wg.Wait()
+	refreshInDependencyOrder(selectMatViews(views), matViewRefreshWorkers, func(matView string) {
+		step := recorder.startStep(models.RefreshMatView, matView)
+		skipped, err := refreshMatview(dbc, refreshMatviewOnlyIfEmpty, matView)
+		recorder.finishStep(step, skipped, err)
+	})
 
 	allElapsed := time.Since(allStart)
-	log.WithField("elapsed", allElapsed).Info("refreshed all materialized views")
-	allMatViewsRefreshMetric.Observe(float64(allElapsed.Milliseconds()))
+	log.WithField("elapsed", allElapsed).Infof("refreshed %d materialized views", len(views))
+	if len(views) == len(db.PostgresMatViews) {
+		allMatViewsRefreshMetric.Observe(float64(allElapsed.Milliseconds()))
+	}
 
 	if promPusher != nil {
 		log.Info("pushing metrics to prometheus gateway")
@@ -172,54 +210,204 @@ func refreshMaterializedViews(dbc *db.DB, refreshMatviewOnlyIfEmpty bool) {
 	}
 }
 
-func refreshMatview(dbc *db.DB, refreshMatviewOnlyIfEmpty bool, ch chan string, wg *sync.WaitGroup) {
+// selectMatViews returns the named materialized views, in the order of db.PostgresMatViews.
+func selectMatViews(names []string) []db.PostgresMaterializedView {
+	selected := sets.NewString(names...)
+	views := make([]db.PostgresMaterializedView, 0, len(names))
+	for _, pmv := range db.PostgresMatViews {
+		if selected.Has(pmv.Name) {
+			views = append(views, pmv)
+		}
+	}
+	return views
+}
 
-	for matView := range ch {
-		start := time.Now()
-		tmpLog := log.WithField("matview", matView)
-
-		// If requested, we only refresh the materialized view if it has no rows
-		if refreshMatviewOnlyIfEmpty {
-			var count int
-			if res := dbc.DB.Raw(fmt.Sprintf("SELECT COUNT(*) FROM %s", matView)).Scan(&count); res.Error != nil {
-				tmpLog.WithError(res.Error).Warn("proceeding with refresh of matview that appears to be empty")
-			} else if count > 0 {
-				tmpLog.Info("skipping matview refresh as it appears to be populated")
-				continue
+// refreshInDependencyOrder refreshes the views with a pool of workers, starting each once the views it depends on
+// have been refreshed. Views depend only on those listed before them, so there are no cycles.
+func refreshInDependencyOrder(views []db.PostgresMaterializedView, workers int, refresh func(matView string)) {
+	selected := sets.NewString()
+	for _, pmv := range views {
+		selected.Insert(pmv.Name)
+	}
+
+	// the queue holds every view, so queueing a view never blocks waiting for the workers
+	queue := make(chan string, len(views))
+	waiting := map[string]int{}
+	dependents := map[string][]string{}
+	for _, pmv := range views {
+		for _, dependency := range pmv.DependsOn {
+			if selected.Has(dependency) {
+				waiting[pmv.Name]++
+				dependents[dependency] = append(dependents[dependency], pmv.Name)
 			}
 		}
+		if waiting[pmv.Name] == 0 {
+			queue <- pmv.Name
+		}
+	}
 
-		// Try to refresh concurrently, if we get an error that likely means the view has never been
-		// populated (could be a developer env, or a schema migration on the view), fall back to the normal
-		// refresh which locks reads.
-		tmpLog.Info("refreshing materialized view")
-		if res := dbc.DB.Exec(
-			fmt.Sprintf("REFRESH MATERIALIZED VIEW CONCURRENTLY %s", matView)); res.Error != nil {
-			tmpLog.WithError(res.Error).Warn("error refreshing materialized view concurrently, falling back to regular refresh")
+	done := make(chan string)
+	for t := 0; t < workers; t++ {
+		go func() {
+			for matView := range queue {
+				refresh(matView)
+				done <- matView
+			}
+		}()
+	}
 
-			if res := dbc.DB.Exec(
-				fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", matView)); res.Error != nil {
-				tmpLog.WithError(res.Error).Error("error refreshing materialized view")
-			} else {
-				elapsed := time.Since(start)
-				tmpLog.WithField("elapsed", elapsed).Info("refreshed materialized view")
-				matViewRefreshMetric.WithLabelValues(matView).Observe(float64(elapsed.Milliseconds()))
+	for remaining := len(views); remaining > 0; remaining-- {
+		for _, dependent := range dependents[<-done] {
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				queue <- dependent
 			}
+		}
+	}
+	close(queue)
+}
 
-		} else {
-			elapsed := time.Since(start)
-			tmpLog.WithField("elapsed", elapsed).Info("refreshed materialized view concurrently")
-			matViewRefreshMetric.WithLabelValues(matView).Observe(float64(elapsed.Milliseconds()))
+// refreshMatview refreshes the view, returning whether it was skipped for already being populated.
+func refreshMatview(dbc *db.DB, refreshMatviewOnlyIfEmpty bool, matView string) (bool, error) {
+	start := time.Now()
+	tmpLog := log.WithField("matview", matView)
+
+	// If requested, we only refresh the materialized view if it has no rows
+	if refreshMatviewOnlyIfEmpty {
+		var count int
+		if res := dbc.DB.Raw(fmt.Sprintf("SELECT COUNT(*) FROM %s", matView)).Scan(&count); res.Error != nil {
+			tmpLog.WithError(res.Error).Warn("proceeding with refresh of matview that appears to be empty")
+		} else if count > 0 {
+			tmpLog.Info("skipping matview refresh as it appears to be populated")
+			return true, nil
 		}
 	}
-	wg.Done()
+
+	// Try to refresh concurrently, if we get an error that likely means the view has never been
+	// populated (could be a developer env, or a schema migration on the view), fall back to the normal
+	// refresh which locks reads.
+	tmpLog.Info("refreshing materialized view")
+	if res := dbc.DB.Exec(
+		fmt.Sprintf("REFRESH MATERIALIZED VIEW CONCURRENTLY %s", matView)); res.Error != nil {
+		tmpLog.WithError(res.Error).Warn("error refreshing materialized view concurrently, falling back to regular refresh")
+
+		if res := dbc.DB.Exec(
+			fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", matView)); res.Error != nil {
+			tmpLog.WithError(res.Error).Error("error refreshing materialized view")
+			return false, res.Error
+		}
+		elapsed := time.Since(start)
+		tmpLog.WithField("elapsed", elapsed).Info("refreshed materialized view")
+		matViewRefreshMetric.WithLabelValues(matView).Observe(float64(elapsed.Milliseconds()))
+		matViewLastRefreshMetric.WithLabelValues(matView).SetToCurrentTime()
+		return false, nil
+	}
+
+	elapsed := time.Since(start)
+	tmpLog.WithField("elapsed", elapsed).Info("refreshed materialized view concurrently")
+	matViewRefreshMetric.WithLabelValues(matView).Observe(float64(elapsed.Milliseconds()))
+	matViewLastRefreshMetric.WithLabelValues(matView).SetToCurrentTime()
+	return false, nil
 }
 
-func RefreshData(dbc *db.DB, pinnedDateTime *time.Time, refreshMatviewsOnlyIfEmpty bool) {
-	log.Infof("Refreshing data")
+// detectingRegressionsStep is the name of the step detecting test regressions, after which the open regressions are
+// counted.
+const detectingRegressionsStep = "detecting test regressions"
+
+// refreshStep computes data from the materialized views or tables, once they're refreshed.
+type refreshStep struct {
+	name string
+	// inputs are the materialized views and tables the step reads.
+	inputs []string
+	run    func() error
+}
+
+// RefreshData refreshes every materialized view and the data computed from them. The trigger, i.e. the command
+// refreshing, is recorded with the refresh's progress.
+func RefreshData(dbc *db.DB, trigger string, pinnedDateTime *time.Time, refreshMatviewsOnlyIfEmpty bool,
+	regressionDetection v1.RegressionDetectionConfig, silentJobDetection v1.SilentJobDetectionConfig, notifier notify.Notifier) {
+	views := make([]string, 0, len(db.PostgresMatViews))
+	for _, pmv := range db.PostgresMatViews {
+		views = append(views, pmv.Name)
+	}
+	refreshData(dbc, trigger, pinnedDateTime, refreshMatviewsOnlyIfEmpty, regressionDetection, silentJobDetection, notifier,
+		views, nil)
+}
+
+// RefreshDataForTables refreshes only the materialized views computed from the tables, i.e. those a load wrote to,
+// and the data computed from the refreshed views or the tables. For instance, loading release payloads doesn't
+// refresh the test reports.
+func RefreshDataForTables(dbc *db.DB, trigger string, pinnedDateTime *time.Time,
+	regressionDetection v1.RegressionDetectionConfig, silentJobDetection v1.SilentJobDetectionConfig,
+	notifier notify.Notifier, tables []string) {
+	refreshData(dbc, trigger, pinnedDateTime, false, regressionDetection, silentJobDetection, notifier,
+		db.MatViewsForTables(tables), tables)
+}
 
-	refreshMaterializedViews(dbc, refreshMatviewsOnlyIfEmpty)
+// refreshData refreshes the views, then runs the steps reading any of them or the tables. With no tables, every step
+// runs.
+func refreshData(dbc *db.DB, trigger string, pinnedDateTime *time.Time, refreshMatviewsOnlyIfEmpty bool,
+	regressionDetection v1.RegressionDetectionConfig, silentJobDetection v1.SilentJobDetectionConfig,
+	notifier notify.Notifier, views, tables []string) {
+	log.Infof("Refreshing data")
+	recorder := startRefreshRecorder(dbc, trigger)
+
+	refreshMaterializedViews(dbc, refreshMatviewsOnlyIfEmpty, views, recorder)
+
+	reportEnd := util.GetReportEnd(pinnedDateTime)
+	steps := []refreshStep{
+		{
+			name:   "refreshing test analysis summaries",
+			inputs: []string{"prow_test_analysis_by_variant_14d_matview", "prow_test_analysis_by_job_14d_matview", "prow_jobs"},
+			run:    func() error { return testanalysis.Refresh(dbc) },
+		},
+		{
+			// regressions are detected from the test reports, so the views must be refreshed first
+			name:   detectingRegressionsStep,
+			inputs: []string{"prow_test_report_7d_matview", "prow_test_report_2d_matview"},
+			run:    func() error { return regressiondetection.Detect(dbc, regressionDetection, reportEnd) },
+		},
+		{
+			name:   "detecting silent jobs",
+			inputs: []string{"prow_job_runs", "prow_jobs"},
+			run:    func() error { return silentjobs.Detect(dbc, silentJobDetection, notifier, reportEnd) },
+		},
+		{
+			name:   "notifying expired test quarantines",
+			inputs: []string{"prow_job_runs", "test_quarantines"},
+			run:    func() error { return testquarantine.NotifyExpired(dbc, notifier, reportEnd) },
+		},
+		{
+			name:   "refreshing install and upgrade funnels",
+			inputs: []string{"prow_job_run_tests", "prow_job_runs", "prow_jobs", "tests"},
+			run:    func() error { return funnel.Refresh(dbc, reportEnd) },
+		},
+		{
+			name:   "recording weekly test and job results",
+			inputs: []string{"prow_job_run_tests", "prow_job_runs", "prow_jobs"},
+			run:    func() error { return history.Snapshot(dbc, reportEnd) },
+		},
+	}
+
+	changed := sets.NewString(views...).Insert(tables...)
+	for _, step := range steps {
+		if tables != nil && !changed.HasAny(step.inputs...) {
+			log.Infof("skipping %s, none of its inputs changed", step.name)
+			recorder.skipStep(models.RefreshComputation, step.name)
+			continue
+		}
+		recorded := recorder.startStep(models.RefreshComputation, step.name)
+		err := step.run()
+		if err != nil {
+			log.WithError(err).Error("error " + step.name)
+		}
+		recorder.finishStep(recorded, false, err)
+		if step.name == detectingRegressionsStep && err == nil {
+			recorder.countOpenRegressions()
+		}
+	}
 
+	recorder.finish()
 	log.Infof("Refresh complete")
 }
 
@@ -235,243 +423,1072 @@ func (s *Server) jsonCapabilitiesReport(w http.ResponseWriter, _ *http.Request)
 		log.WithError(err).Warningf("could not fetch build cluster data")
 	}
 
-	api.RespondWithJSON(http.StatusOK, w, capabilities)
-}
+	api.RespondWithJSON(http.StatusOK, w, capabilities)
+}
+
+func (s *Server) jsonAutocompleteFromDB(w http.ResponseWriter, req *http.Request) {
+	api.PrintAutocompleteFromDB(w, req, s.db.WithContext(req.Context()))
+}
+
+func (s *Server) jsonReleaseTagsReport(w http.ResponseWriter, req *http.Request) {
+	api.PrintReleasesReport(w, req, s.db.WithContext(req.Context()))
+}
+
+// jsonReleaseTagFromDB serves /api/releases/tags/{tag}/changelog.
+func (s *Server) jsonReleaseTagFromDB(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/releases/tags/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "changelog" {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("no API endpoint found for %s", req.URL.Path))
+		return
+	}
+	releaseTag := parts[0]
+
+	changelog, err := api.GetPayloadChangelogFromDB(s.db.WithContext(req.Context()), releaseTag)
+	if err != nil {
+		log.WithError(err).Error("error querying payload changelog from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying payload changelog from db")
+		return
+	}
+	if changelog == nil {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("payload %s not found", releaseTag))
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, changelog)
+}
+
+func (s *Server) jsonIncidentEvent(w http.ResponseWriter, req *http.Request) {
+	start, err := getISO8601Date("start", req)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "couldn't parse start param: "+err.Error())
+		return
+	}
+
+	end, err := getISO8601Date("end", req)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "couldn't parse end param: "+err.Error())
+		return
+	}
+
+	results, err := api.GetJIRAIncidentsFromDB(s.db.WithContext(req.Context()), start, end)
+	if err != nil {
+		api.RespondWithError(http.StatusInternalServerError, w, "couldn't fetch events"+err.Error())
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonReleaseTagsEvent(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release != "" {
+		filterOpts, err := filter.FilterOptionsFromRequest(req, "release_time", apitype.SortDescending)
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse filter opts: "+err.Error())
+			return
+		}
+
+		start, err := getISO8601Date("start", req)
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse start param: "+err.Error())
+			return
+		}
+
+		end, err := getISO8601Date("end", req)
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse end param: "+err.Error())
+			return
+		}
+
+		results, err := api.GetPayloadEvents(s.db.WithContext(req.Context()), release, filterOpts, start, end)
+		if err != nil {
+			api.RespondWithError(http.StatusInternalServerError, w, "couldn't fetch payload events: "+err.Error())
+			return
+		}
+
+		api.RespondWithJSON(http.StatusOK, w, results)
+	}
+}
+
+func (s *Server) jsonReleasePullRequestsReport(w http.ResponseWriter, req *http.Request) {
+	api.PrintPullRequestsReport(w, req, s.db.WithContext(req.Context()))
+}
+
+func (s *Server) jsonListPayloadJobRuns(w http.ResponseWriter, req *http.Request) {
+	// Release appears optional here, perhaps when listing all job runs for all payloads
+	// in the release, but this may not make sense. Likely this API call should be
+	// moved away from filters and possible support for multiple payloads at once to
+	// URL encoded single payload.
+	release := req.URL.Query().Get("release")
+	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
+	if err != nil {
+		log.WithError(err).Error("error")
+		api.RespondWithError(http.StatusInternalServerError, w, "Error building job run report:"+err.Error())
+		return
+	}
+
+	payloadJobRuns, err := api.ListPayloadJobRuns(s.db.WithContext(req.Context()), filterOpts, release)
+	if err != nil {
+		log.WithError(err).Error("error listing payload job runs")
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, payloadJobRuns)
+}
+
+// TODO: may want to merge with jsonReleaseHealthReport, but this is a fair bit slower, and release health is run
+// on startup many times over when we calculate the metrics.
+// if we could boil the go logic for building this down into a query, it could become another matview and then
+// could be run quickly, assembling into the health api much more easily
+func (s *Server) jsonGetPayloadAnalysis(w http.ResponseWriter, req *http.Request) {
+	release := req.URL.Query().Get("release")
+	if release == "" {
+		api.RespondWithError(http.StatusBadRequest, w, `"release" is required`)
+		return
+	}
+	stream := req.URL.Query().Get("stream")
+	if stream == "" {
+		api.RespondWithError(http.StatusBadRequest, w, `"stream" is required`)
+		return
+	}
+	arch := req.URL.Query().Get("arch")
+	if arch == "" {
+		api.RespondWithError(http.StatusBadRequest, w, `"arch" is required`)
+		return
+	}
+
+	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
+	if err != nil {
+		api.RespondWithError(http.StatusInternalServerError, w, err.Error())
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"release": release,
+		"stream":  stream,
+		"arch":    arch,
+	}).Info("analyzing payload stream")
+
+	result, err := api.GetPayloadStreamTestFailures(s.db.WithContext(req.Context()), release, stream, arch, filterOpts, s.GetReportEnd())
+	if err != nil {
+		log.WithError(err).Error("error")
+		api.RespondWithError(http.StatusInternalServerError, w, "Error analyzing payload: "+err.Error())
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonGetPayloadTestFailures is an api to fetch information about what tests failed across all jobs in a specific
+// payload.
+func (s *Server) jsonGetPayloadTestFailures(w http.ResponseWriter, req *http.Request) {
+	payload := req.URL.Query().Get("payload")
+	if payload == "" {
+		api.RespondWithError(http.StatusBadRequest, w, `"payload" is required`)
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"payload": payload,
+	})
+	logger.Info("checking for test failures in payload")
+
+	result, err := api.GetPayloadTestFailures(s.db.WithContext(req.Context()), payload, logger)
+	if err != nil {
+		log.WithError(err).Error("error")
+		api.RespondWithError(http.StatusInternalServerError, w, "Error looking up test failures for payload: "+err.Error())
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonPayloadBisect finds the payloads of a stream in the last two weeks, or up to 30 days, where a test's pass rate
+// dropped, and the pull requests they included.
+func (s *Server) jsonPayloadBisect(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		api.RespondWithError(http.StatusBadRequest, w, `"test" is required`)
+		return
+	}
+	stream := req.URL.Query().Get("stream")
+	if stream == "" {
+		stream = "nightly"
+	}
+	arch := req.URL.Query().Get("arch")
+	if arch == "" {
+		arch = "amd64"
+	}
+
+	days := 14
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 30 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 30")
+			return
+		}
+	}
+	confidence := 95
+	if param := req.URL.Query().Get("confidence"); param != "" {
+		var err error
+		if confidence, err = strconv.Atoi(param); err != nil || confidence < 1 || confidence > 99 {
+			api.RespondWithError(http.StatusBadRequest, w, "confidence must be a percentage between 1 and 99")
+			return
+		}
+	}
+
+	end := s.GetReportEnd()
+	bisect, err := api.GetPayloadBisect(s.db.WithContext(req.Context()), release, stream, arch, testName,
+		end.AddDate(0, 0, -days), end, confidence)
+	if err != nil {
+		log.WithError(err).Error("error bisecting payloads")
+		api.RespondWithError(http.StatusInternalServerError, w, "error bisecting payloads: "+err.Error())
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, bisect)
+}
+
+// jsonPayloadStreamComparison compares the release's nightly and ci payloads on the arch parameter's architecture,
+// amd64 by default, over the last days, 7 by default.
+func (s *Server) jsonPayloadStreamComparison(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	arch := req.URL.Query().Get("arch")
+	if arch == "" {
+		arch = "amd64"
+	}
+	days := 7
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 30 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 30")
+			return
+		}
+	}
+
+	end := s.GetReportEnd()
+	comparison, err := api.ComparePayloadStreams(s.db.WithContext(req.Context()), release, arch,
+		end.AddDate(0, 0, -days), end)
+	if err != nil {
+		log.WithError(err).Error("error comparing payload streams")
+		api.RespondWithError(http.StatusInternalServerError, w, "error comparing payload streams: "+err.Error())
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, comparison)
+}
+
+// jsonPayloadGate advises whether a payload should be accepted. A payload loaded into sippy is given by the
+// release_tag parameter, one that isn't yet, i.e. while its jobs run, is POSTed with its blocking jobs' results.
+func (s *Server) jsonPayloadGate(w http.ResponseWriter, req *http.Request) {
+	dbc := s.db.WithContext(req.Context())
+	var gate *apitype.PayloadGate
+	var err error
+	switch req.Method {
+	case http.MethodGet:
+		releaseTag := req.URL.Query().Get("release_tag")
+		if releaseTag == "" {
+			api.RespondWithError(http.StatusBadRequest, w, `"release_tag" is required`)
+			return
+		}
+		gate, err = api.GatePayloadFromDB(dbc, releaseTag, s.GetReportEnd())
+		if err == nil && gate == nil {
+			api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("payload %s not found, POST its blocking jobs instead", releaseTag))
+			return
+		}
+	case http.MethodPost:
+		gateReq := apitype.PayloadGateRequest{}
+		if err := json.NewDecoder(req.Body).Decode(&gateReq); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't decode request: "+err.Error())
+			return
+		}
+		if gateReq.ReleaseTag == "" || gateReq.Release == "" {
+			api.RespondWithError(http.StatusBadRequest, w, "release_tag and release are required")
+			return
+		}
+		gate, err = api.GatePayload(dbc, gateReq, s.GetReportEnd())
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "payload gates are fetched with GET, or POSTed for payloads sippy hasn't loaded")
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("error gating payload")
+		api.RespondWithError(http.StatusInternalServerError, w, "error gating payload: "+err.Error())
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, gate)
+}
+
+func (s *Server) jsonReleaseHealthReport(w http.ResponseWriter, req *http.Request) {
+	release := req.URL.Query().Get("release")
+	if release == "" {
+		api.RespondWithError(http.StatusBadRequest, w, `"release" is required`)
+		return
+	}
+
+	results, err := api.ReleaseHealthReports(s.db.WithContext(req.Context()), release, s.GetReportEnd())
+	if err != nil {
+		log.WithError(err).Error("error generating release health report")
+		api.RespondWithError(http.StatusInternalServerError, w, err.Error())
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+// jsonReleaseReadinessReport compares a release's last week, or the sample given, to a basis defaulting to the four
+// weeks before the previous release's GA.
+func (s *Server) jsonReleaseReadinessReport(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	options := apitype.ReleaseReadinessRequestOptions{
+		Release:      release,
+		SampleStart:  s.GetReportEnd().AddDate(0, 0, -7),
+		SampleEnd:    s.GetReportEnd(),
+		BasisRelease: req.URL.Query().Get("baseRelease"),
+		Confidence:   95,
+	}
+	if options.BasisRelease == "" {
+		var err error
+		options.BasisRelease, options.BasisStart, options.BasisEnd, err = api.DefaultReleaseReadinessBasis(release)
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, err.Error())
+			return
+		}
+	}
+
+	for param, t := range map[string]*time.Time{
+		"baseStartTime":   &options.BasisStart,
+		"baseEndTime":     &options.BasisEnd,
+		"sampleStartTime": &options.SampleStart,
+		"sampleEndTime":   &options.SampleEnd,
+	} {
+		parsed, err := getISO8601Date(param, req)
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("couldn't parse %s param: %s", param, err))
+			return
+		}
+		if parsed != nil {
+			*t = *parsed
+		}
+	}
+	if options.BasisStart.IsZero() || options.BasisEnd.IsZero() {
+		api.RespondWithError(http.StatusBadRequest, w, "baseStartTime and baseEndTime are required with baseRelease")
+		return
+	}
+
+	if confidence := req.URL.Query().Get("confidence"); confidence != "" {
+		var err error
+		options.Confidence, err = strconv.Atoi(confidence)
+		if err != nil || options.Confidence < 1 || options.Confidence > 99 {
+			api.RespondWithError(http.StatusBadRequest, w, "confidence must be a percentage between 1 and 99")
+			return
+		}
+	}
+
+	report, errs := api.GetReleaseReadinessReport(s.db.WithContext(req.Context()), s.cache, options)
+	if len(errs) > 0 {
+		log.Errorf("error generating release readiness report: %v", errs)
+		api.RespondWithError(http.StatusInternalServerError, w, fmt.Sprintf("error generating release readiness report: %v", errs))
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, report)
+}
+
+// jsonReleaseReadinessForecast projects the pass rate at GA of each of the release's blocking jobs from its daily pass
+// rates over the last two weeks, or up to 30 days, flagging those heading below the threshold.
+func (s *Server) jsonReleaseReadinessForecast(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	days := 14
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 30 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 30")
+			return
+		}
+	}
+	threshold := 80.0
+	if param := req.URL.Query().Get("threshold"); param != "" {
+		var err error
+		if threshold, err = strconv.ParseFloat(param, 64); err != nil || threshold < 0 || threshold > 100 {
+			api.RespondWithError(http.StatusBadRequest, w, "threshold must be a percentage between 0 and 100")
+			return
+		}
+	}
+
+	end := s.GetReportEnd()
+	ga := s.releaseMilestones(release).GA
+	if ga.IsZero() {
+		ga = releaseloader.GADateMap[release]
+	}
+	if ga.IsZero() {
+		api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("release %s has no GA date to forecast to", release))
+		return
+	}
+	if !ga.After(end) {
+		api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("release %s is already GA", release))
+		return
+	}
+
+	forecast, err := api.GetReleaseReadinessForecastFromDB(s.db.WithContext(req.Context()), release, ga,
+		end.AddDate(0, 0, -days), end, threshold)
+	if err != nil {
+		log.WithError(err).Error("error forecasting release readiness")
+		api.RespondWithError(http.StatusInternalServerError, w, "error forecasting release readiness")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, forecast)
+}
+
+func (s *Server) jsonTestDurationPercentilesFromDB(w http.ResponseWriter, req *http.Request) {
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
+		return
+	}
+
+	percentiles, err := query.TestDurationPercentiles(s.db.WithContext(req.Context()), testName)
+	if err != nil {
+		log.WithError(err).Error("error querying test duration percentiles from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test duration percentiles from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, percentiles)
+}
+
+// jsonNewTestsFromDB lists the tests first seen in the release's jobs in the last week, or up to 30 days, that never
+// ran before, with their pass rates since.
+func (s *Server) jsonNewTestsFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	days := 7
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 30 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 30")
+			return
+		}
+	}
+
+	end := s.GetReportEnd()
+	tests, err := query.NewTests(s.db.WithContext(req.Context()), release, end.AddDate(0, 0, -days), end)
+	if err != nil {
+		log.WithError(err).Error("error querying new tests from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying new tests from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, tests)
+}
+
+// jsonTestDurationRegressionsFromDB lists the tests whose P95 duration grew by at least 25% and 10 seconds from the
+// base release by default.
+func (s *Server) jsonTestDurationRegressionsFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	minRuns := 10
+	minGrowth, minIncrease := 25.0, 10.0
+	if param := req.URL.Query().Get("minRuns"); param != "" {
+		var err error
+		if minRuns, err = strconv.Atoi(param); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse minRuns param: "+err.Error())
+			return
+		}
+	}
+	for param, value := range map[string]*float64{"minGrowth": &minGrowth, "minIncrease": &minIncrease} {
+		if v := req.URL.Query().Get(param); v != "" {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("couldn't parse %s param: %s", param, err))
+				return
+			}
+			*value = parsed
+		}
+	}
+
+	regressions, err := api.GetTestDurationRegressionsFromDB(s.db.WithContext(req.Context()), release,
+		req.URL.Query().Get("baseRelease"), minRuns, minGrowth, minIncrease)
+	if err != nil {
+		log.WithError(err).Error("error querying test duration regressions from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test duration regressions from db: "+err.Error())
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, regressions)
+}
+
+// jsonTestSkipRatesFromDB lists the tests skipped in a variant at least 20 points more often in the last week than the
+// week before by default.
+func (s *Server) jsonTestSkipRatesFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	minRuns := 10
+	minIncrease := 20.0
+	if param := req.URL.Query().Get("minRuns"); param != "" {
+		var err error
+		if minRuns, err = strconv.Atoi(param); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse minRuns param: "+err.Error())
+			return
+		}
+	}
+	if param := req.URL.Query().Get("minIncrease"); param != "" {
+		var err error
+		if minIncrease, err = strconv.ParseFloat(param, 64); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse minIncrease param: "+err.Error())
+			return
+		}
+	}
+
+	rates, err := api.GetTestSkipRatesFromDB(s.db.WithContext(req.Context()), release, minRuns, minIncrease,
+		s.GetReportEnd())
+	if err != nil {
+		log.WithError(err).Error("error querying test skip rates from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test skip rates from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, rates)
+}
+
+// jsonJobTimeoutRisksFromDB lists the jobs whose P95 duration is at least 80% of their timeout by default.
+func (s *Server) jsonJobTimeoutRisksFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	minPercentage := 80.0
+	if param := req.URL.Query().Get("minPercentage"); param != "" {
+		var err error
+		if minPercentage, err = strconv.ParseFloat(param, 64); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse minPercentage param: "+err.Error())
+			return
+		}
+	}
+
+	risks, err := api.GetJobTimeoutRisksFromDB(s.db.WithContext(req.Context()), release, minPercentage, s.GetReportEnd())
+	if err != nil {
+		log.WithError(err).Error("error querying job timeout risks from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying job timeout risks from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, risks)
+}
+
+// jsonFailureClustersFromDB groups the release's test failures of the last day, or up to a week, by output similarity.
+func (s *Server) jsonFailureClustersFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	days := 1
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 7 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 7")
+			return
+		}
+	}
+	threshold := 0.8
+	if param := req.URL.Query().Get("similarity"); param != "" {
+		var err error
+		if threshold, err = strconv.ParseFloat(param, 64); err != nil || threshold <= 0 || threshold > 1 {
+			api.RespondWithError(http.StatusBadRequest, w, "similarity must be above 0 and at most 1")
+			return
+		}
+	}
+
+	end := s.GetReportEnd()
+	clusters, err := failureclusters.FailureClustersFromDB(s.db.WithContext(req.Context()), release, end.AddDate(0, 0, -days), end, threshold)
+	if err != nil {
+		log.WithError(err).Error("error clustering test failures")
+		api.RespondWithError(http.StatusInternalServerError, w, "error clustering test failures")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, clusters)
+}
+
+// jsonFunnelFromDB returns the release's install or upgrade success and failure reasons per variant and day, for the
+// last two weeks or up to 90 days.
+func (s *Server) jsonFunnelFromDB(kind string) func(w http.ResponseWriter, req *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		release := s.getReleaseOrFail(w, req)
+		if release == "" {
+			return
+		}
+
+		days := 14
+		if param := req.URL.Query().Get("days"); param != "" {
+			var err error
+			if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 90 {
+				api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 90")
+				return
+			}
+		}
+
+		start := s.GetReportEnd().UTC().Truncate(24*time.Hour).AddDate(0, 0, -days)
+		funnel, err := api.GetFunnelFromDB(s.db.WithContext(req.Context()), kind, release, req.URL.Query().Get("variant"), start)
+		if err != nil {
+			log.WithError(err).Errorf("error querying %s funnel", kind)
+			api.RespondWithError(http.StatusInternalServerError, w, "error querying "+kind+" funnel from db")
+			return
+		}
+		api.RespondWithJSON(http.StatusOK, w, funnel)
+	}
+}
+
+func (s *Server) jsonTestAnalysis(w http.ResponseWriter, req *http.Request, dbFN func(*db.DB, *filter.Filter, string, string, time.Time) (map[string][]api.CountByDate, error)) {
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
+		return
+	}
+	release := s.getReleaseOrFail(w, req)
+	if release != "" {
+		filters, err := filter.ExtractFilters(req)
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse filter opts: "+err.Error())
+			return
+		}
+		results, err := dbFN(s.db.WithContext(req.Context()), filters, release, testName, s.GetReportEnd())
+		if err != nil {
+			api.RespondWithError(http.StatusInternalServerError, w, err.Error())
+			return
+		}
+		api.RespondWithJSON(200, w, results)
+	}
+}
+
+func (s *Server) jsonTestAnalysisByJobFromDB(w http.ResponseWriter, req *http.Request) {
+	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByJobFromDB)
+}
+
+func (s *Server) jsonTestAnalysisByVariantFromDB(w http.ResponseWriter, req *http.Request) {
+	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByVariantFromDB)
+}
+
+// jsonTestHistoryFromDB returns a test's weekly results in a release, across every job or those of a variant.
+func (s *Server) jsonTestHistoryFromDB(w http.ResponseWriter, req *http.Request) {
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
+		return
+	}
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	variant := req.URL.Query().Get("variant")
+	if variant == "" {
+		variant = "All"
+	}
+
+	results, err := api.GetTestHistoryFromDB(s.db.WithContext(req.Context()), release, testName, variant)
+	if err != nil {
+		log.WithError(err).Error("error querying test history from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test history from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+// SetTestRenames sets the renames the history of tests is followed through across releases, it is called again when
+// the config is reloaded.
+func (s *Server) SetTestRenames(renames []v1.TestRenameConfig) {
+	s.testRenamesLock.Lock()
+	defer s.testRenamesLock.Unlock()
+	s.testRenames = renames
+}
+
+// jsonCrossReleaseTestHistoryFromDB returns the test's weekly results across releases as one series, following it
+// through renames, and to similar names unless fuzzy=false.
+func (s *Server) jsonCrossReleaseTestHistoryFromDB(w http.ResponseWriter, req *http.Request) {
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
+		return
+	}
+	variant := req.URL.Query().Get("variant")
+	if variant == "" {
+		variant = "All"
+	}
+	fuzzy := req.URL.Query().Get("fuzzy") != "false"
+
+	s.testRenamesLock.RLock()
+	renames := s.testRenames
+	s.testRenamesLock.RUnlock()
+
+	history, err := api.GetCrossReleaseTestHistoryFromDB(s.db.WithContext(req.Context()), testName, variant, renames, fuzzy)
+	if err != nil {
+		log.WithError(err).Error("error querying cross-release test history from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying cross-release test history from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, history)
+}
+
+// jsonTestFromDB serves the APIs of the test whose ID is in the path, /api/tests/{id}/timeseries returning its pass
+// rate in the release per day, over the last two weeks at most, or per week since any time.
+func (s *Server) jsonTestFromDB(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/tests/"), "/")
+	if len(parts) != 2 || parts[1] != "timeseries" {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("no API endpoint found for %s", req.URL.Path))
+		return
+	}
+	testID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("invalid test id %q", parts[0]))
+		return
+	}
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	variant := req.URL.Query().Get("variant")
+	if variant == "" {
+		variant = "All"
+	}
+
+	reportEnd := s.GetReportEnd()
+	firstDay := reportEnd.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-api.MaxTimeseriesDays)
+	granularity := req.URL.Query().Get("granularity")
+	var since time.Time
+	switch granularity {
+	case "", api.TimeseriesDay:
+		granularity = api.TimeseriesDay
+		since = firstDay
+	case api.TimeseriesWeek:
+		since = reportEnd.AddDate(0, 0, -7*52)
+	default:
+		api.RespondWithError(http.StatusBadRequest, w, "granularity must be day or week")
+		return
+	}
+	if param := req.URL.Query().Get("since"); param != "" {
+		if since, err = time.Parse("2006-01-02", param); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("invalid since %q, expected a date like 2006-01-02", param))
+			return
+		}
+	}
+	if !since.Before(reportEnd) {
+		api.RespondWithError(http.StatusBadRequest, w, "since must be before the report end")
+		return
+	}
+	if granularity == api.TimeseriesDay && since.Before(firstDay) {
+		api.RespondWithError(http.StatusBadRequest, w,
+			fmt.Sprintf("daily timeseries can start at most %d days ago, use granularity=week", api.MaxTimeseriesDays))
+		return
+	}
+
+	timeseries, err := api.GetTestTimeseriesFromDB(s.db.WithContext(req.Context()), uint(testID), release, variant,
+		granularity, since, reportEnd)
+	if err != nil {
+		log.WithError(err).Error("error querying test timeseries from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test timeseries from db")
+		return
+	}
+	if timeseries == nil {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("test %d not found", testID))
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, timeseries)
+}
+
+// jsonJobHistoryFromDB returns a job's weekly results.
+func (s *Server) jsonJobHistoryFromDB(w http.ResponseWriter, req *http.Request) {
+	jobName := req.URL.Query().Get("job")
+	if jobName == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "'job' is required.")
+		return
+	}
+
+	results, err := api.GetJobHistoryFromDB(s.db.WithContext(req.Context()), jobName)
+	if err != nil {
+		log.WithError(err).Error("error querying job history from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying job history from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+// jsonJobFromDB serves the APIs of the job whose ID is in the path, /api/jobs/{id}/history returning its runs over the
+// last days, given by the days parameter.
+func (s *Server) jsonJobFromDB(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/jobs/"), "/")
+	if len(parts) != 2 || parts[1] != "history" {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("no API endpoint found for %s", req.URL.Path))
+		return
+	}
+	jobID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("invalid job id %q", parts[0]))
+		return
+	}
+	days := 14
+	if param := req.URL.Query().Get("days"); param != "" {
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 90 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 90")
+			return
+		}
+	}
+
+	excludeInfraFailures, _ := strconv.ParseBool(req.URL.Query().Get("excludeInfraFailures"))
 
-func (s *Server) jsonAutocompleteFromDB(w http.ResponseWriter, req *http.Request) {
-	api.PrintAutocompleteFromDB(w, req, s.db)
+	history, err := api.GetJobRunHistoryFromDB(s.db.WithContext(req.Context()), uint(jobID), days, s.GetReportEnd(),
+		excludeInfraFailures)
+	if err != nil {
+		log.WithError(err).Error("error querying job run history from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying job run history from db")
+		return
+	}
+	if history == nil {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("job %d not found", jobID))
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, history)
 }
 
-func (s *Server) jsonReleaseTagsReport(w http.ResponseWriter, req *http.Request) {
-	api.PrintReleasesReport(w, req, s.db)
-}
+// jsonSigFromDB serves /api/sigs/{sig}/health, summarizing the sig's tests in the release.
+func (s *Server) jsonSigFromDB(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/sigs/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "health" {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("no API endpoint found for %s", req.URL.Path))
+		return
+	}
+	sig := parts[0]
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
 
-func (s *Server) jsonIncidentEvent(w http.ResponseWriter, req *http.Request) {
-	start, err := getISO8601Date("start", req)
+	health, err := api.GetSigHealthFromDB(s.db.WithContext(req.Context()), sig, release)
 	if err != nil {
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "couldn't parse start param" + err.Error()})
+		log.WithError(err).Error("error querying sig health from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying sig health from db")
+		return
+	}
+	if health == nil {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("no tests of %s ran in %s", sig, release))
 		return
 	}
+	api.RespondWithJSON(http.StatusOK, w, health)
+}
 
-	end, err := getISO8601Date("end", req)
-	if err != nil {
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "couldn't parse start param" + err.Error()})
+// jsonComponentHierarchyFromDB rolls the release's test results up from capabilities to sub-components to components,
+// for every component or the one given.
+func (s *Server) jsonComponentHierarchyFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
 		return
 	}
 
-	results, err := api.GetJIRAIncidentsFromDB(s.db, start, end)
+	hierarchy, err := api.GetComponentHierarchyFromDB(s.db.WithContext(req.Context()), release,
+		req.URL.Query().Get("component"))
 	if err != nil {
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "couldn't fetch events" + err.Error()})
+		log.WithError(err).Error("error querying component hierarchy from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying component hierarchy from db")
 		return
 	}
-
-	api.RespondWithJSON(http.StatusOK, w, results)
+	api.RespondWithJSON(http.StatusOK, w, hierarchy)
 }
 
-func (s *Server) jsonReleaseTagsEvent(w http.ResponseWriter, req *http.Request) {
+// jsonSilentJobsFromDB lists the release's jobs that stopped reporting runs.
+func (s *Server) jsonSilentJobsFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getReleaseOrFail(w, req)
-	if release != "" {
-		filterOpts, err := filter.FilterOptionsFromRequest(req, "release_time", apitype.SortDescending)
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse filter opts " + err.Error()})
-			return
-		}
+	if release == "" {
+		return
+	}
 
-		start, err := getISO8601Date("start", req)
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse start param" + err.Error()})
-			return
-		}
+	silent, err := api.GetSilentJobsFromDB(s.db.WithContext(req.Context()), release)
+	if err != nil {
+		log.WithError(err).Error("error querying silent jobs from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying silent jobs from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, silent)
+}
 
-		end, err := getISO8601Date("end", req)
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse start param" + err.Error()})
+// jsonLoaderErrorsFromDB summarizes the runs that failed to import by job, or lists the errors importing the runs of
+// the job param when it's set.
+func (s *Server) jsonLoaderErrorsFromDB(w http.ResponseWriter, req *http.Request) {
+	loader := req.URL.Query().Get("loader")
+	if loader == "" {
+		loader = "prow"
+	}
+	days := 7
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 30 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 30")
 			return
 		}
+	}
+	since := time.Now().AddDate(0, 0, -days)
 
-		results, err := api.GetPayloadEvents(s.db, release, filterOpts, start, end)
+	dbc := s.db.WithContext(req.Context())
+	if job := req.URL.Query().Get("job"); job != "" {
+		loaderErrs, err := api.GetJobLoaderErrorsFromDB(dbc, loader, job, since)
 		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse start param" + err.Error()})
+			log.WithError(err).Error("error querying loader errors from db")
+			api.RespondWithError(http.StatusInternalServerError, w, "error querying loader errors from db")
 			return
 		}
-
-		api.RespondWithJSON(http.StatusOK, w, results)
-	}
-}
-
-func (s *Server) jsonReleasePullRequestsReport(w http.ResponseWriter, req *http.Request) {
-	api.PrintPullRequestsReport(w, req, s.db)
-}
-
-func (s *Server) jsonListPayloadJobRuns(w http.ResponseWriter, req *http.Request) {
-	// Release appears optional here, perhaps when listing all job runs for all payloads
-	// in the release, but this may not make sense. Likely this API call should be
-	// moved away from filters and possible support for multiple payloads at once to
-	// URL encoded single payload.
-	release := req.URL.Query().Get("release")
-	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
-	if err != nil {
-		log.WithError(err).Error("error")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "Error building job run report:" + err.Error()})
+		api.RespondWithJSON(http.StatusOK, w, loaderErrs)
 		return
 	}
 
-	payloadJobRuns, err := api.ListPayloadJobRuns(s.db, filterOpts, release)
+	jobs, err := api.GetLoaderErrorJobsFromDB(dbc, loader, req.URL.Query().Get("release"), since)
 	if err != nil {
-		log.WithError(err).Error("error listing payload job runs")
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": err.Error(),
-		})
+		log.WithError(err).Error("error querying loader errors from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying loader errors from db")
+		return
 	}
-	api.RespondWithJSON(http.StatusOK, w, payloadJobRuns)
+	api.RespondWithJSON(http.StatusOK, w, jobs)
 }
 
-// TODO: may want to merge with jsonReleaseHealthReport, but this is a fair bit slower, and release health is run
-// on startup many times over when we calculate the metrics.
-// if we could boil the go logic for building this down into a query, it could become another matview and then
-// could be run quickly, assembling into the health api much more easily
-func (s *Server) jsonGetPayloadAnalysis(w http.ResponseWriter, req *http.Request) {
-	release := req.URL.Query().Get("release")
+// jsonTestRegressionsFromDB lists the release's test regressions, only those with the status param when it's set.
+func (s *Server) jsonTestRegressionsFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
 	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"release" is required`),
-		})
 		return
 	}
-	stream := req.URL.Query().Get("stream")
-	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"stream" is required`),
-		})
+	status := req.URL.Query().Get("status")
+	if status != "" && status != models.TestRegressionOpen && status != models.TestRegressionClosed {
+		api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("status must be %s or %s", models.TestRegressionOpen,
+			models.TestRegressionClosed))
 		return
 	}
-	arch := req.URL.Query().Get("arch")
-	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"arch" is required`),
-		})
+
+	regressions, err := api.GetTestRegressionsFromDB(s.db.WithContext(req.Context()), release, status)
+	if err != nil {
+		log.WithError(err).Error("error querying test regressions from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test regressions from db")
 		return
 	}
+	api.RespondWithJSON(http.StatusOK, w, regressions)
+}
 
-	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
+// markdownHandoffReportFromDB renders the on-call handoff report for the last day as Markdown.
+func (s *Server) markdownHandoffReportFromDB(w http.ResponseWriter, req *http.Request) {
+	report, err := handoff.Build(s.db.WithContext(req.Context()), s.GetReportEnd(), handoff.DefaultWindow)
 	if err != nil {
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": err.Error()})
+		log.WithError(err).Error("error building handoff report from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error building handoff report from db")
 		return
 	}
-
-	log.WithFields(log.Fields{
-		"release": release,
-		"stream":  stream,
-		"arch":    arch,
-	}).Info("analyzing payload stream")
-
-	result, err := api.GetPayloadStreamTestFailures(s.db, release, stream, arch, filterOpts, s.GetReportEnd())
+	out, err := handoff.Render(report)
 	if err != nil {
-		log.WithError(err).Error("error")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "Error analyzing payload: " + err.Error()})
+		log.WithError(err).Error("error rendering handoff report")
+		api.RespondWithError(http.StatusInternalServerError, w, "error rendering handoff report")
 		return
 	}
-
-	api.RespondWithJSON(http.StatusOK, w, result)
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	fmt.Fprint(w, out)
 }
 
-// jsonGetPayloadTestFailures is an api to fetch information about what tests failed across all jobs in a specific
-// payload.
-func (s *Server) jsonGetPayloadTestFailures(w http.ResponseWriter, req *http.Request) {
-	payload := req.URL.Query().Get("payload")
-	if payload == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"payload" is required`),
-		})
+// jsonSearchFromDB fuzzily searches test names, job names and failure messages.
+func (s *Server) jsonSearchFromDB(w http.ResponseWriter, req *http.Request) {
+	q := strings.TrimSpace(req.URL.Query().Get("q"))
+	if len(q) < api.MinSearchLength {
+		api.RespondWithError(http.StatusBadRequest, w,
+			fmt.Sprintf("'q' must be at least %d characters.", api.MinSearchLength))
 		return
 	}
 
-	logger := log.WithFields(log.Fields{
-		"payload": payload,
-	})
-	logger.Info("checking for test failures in payload")
-
-	result, err := api.GetPayloadTestFailures(s.db, payload, logger)
-	if err != nil {
-		log.WithError(err).Error("error")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "Error looking up test failures for payload: " + err.Error()})
-		return
+	kinds := []string{api.SearchTests, api.SearchJobs, api.SearchFailures}
+	if param := req.URL.Query().Get("type"); param != "" {
+		kinds = strings.Split(param, ",")
+		for _, kind := range kinds {
+			if kind != api.SearchTests && kind != api.SearchJobs && kind != api.SearchFailures {
+				api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("unknown type %q", kind))
+				return
+			}
+		}
 	}
 
-	api.RespondWithJSON(http.StatusOK, w, result)
-}
-
-func (s *Server) jsonReleaseHealthReport(w http.ResponseWriter, req *http.Request) {
-	release := req.URL.Query().Get("release")
-	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"release" is required`),
-		})
-		return
+	limit := api.DefaultSearchLimit
+	if param := req.URL.Query().Get("limit"); param != "" {
+		var err error
+		if limit, err = strconv.Atoi(param); err != nil || limit < 1 || limit > api.MaxSearchLimit {
+			api.RespondWithError(http.StatusBadRequest, w,
+				fmt.Sprintf("limit must be a number from 1 to %d", api.MaxSearchLimit))
+			return
+		}
 	}
 
-	results, err := api.ReleaseHealthReports(s.db, release, s.GetReportEnd())
+	results, err := api.SearchFromDB(s.db.WithContext(req.Context()), q, req.URL.Query().Get("release"), kinds, limit,
+		s.GetReportEnd())
 	if err != nil {
-		log.WithError(err).Error("error generating release health report")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": err.Error(),
-		})
+		log.WithError(err).Error("error searching db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error searching db")
 		return
 	}
-
 	api.RespondWithJSON(http.StatusOK, w, results)
 }
 
-func (s *Server) jsonTestAnalysis(w http.ResponseWriter, req *http.Request, dbFN func(*db.DB, *filter.Filter, string, string, time.Time) (map[string][]api.CountByDate, error)) {
+// jsonVariantInteractionsFromDB breaks a test's regression from the previous week down by variant, and finds the pairs
+// of variants driving it.
+func (s *Server) jsonVariantInteractionsFromDB(w http.ResponseWriter, req *http.Request) {
 	testName := req.URL.Query().Get("test")
 	if testName == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": "'test' is required.",
-		})
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
 		return
 	}
 	release := s.getReleaseOrFail(w, req)
-	if release != "" {
-		filters, err := filter.ExtractFilters(req)
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse filter opts " + err.Error()})
-			return
-		}
-		results, err := dbFN(s.db, filters, release, testName, s.GetReportEnd())
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": err.Error()})
+	if release == "" {
+		return
+	}
+	confidence := 95
+	if param := req.URL.Query().Get("confidence"); param != "" {
+		var err error
+		if confidence, err = strconv.Atoi(param); err != nil || confidence < 1 || confidence > 99 {
+			api.RespondWithError(http.StatusBadRequest, w, "confidence must be a percentage between 1 and 99")
 			return
 		}
-		api.RespondWithJSON(200, w, results)
 	}
-}
 
-func (s *Server) jsonTestAnalysisByJobFromDB(w http.ResponseWriter, req *http.Request) {
-	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByJobFromDB)
+	analysis, err := api.GetVariantInteractionsFromDB(s.db.WithContext(req.Context()), release, testName, s.GetReportEnd(), confidence)
+	if err != nil {
+		log.WithError(err).Error("error analyzing variant interactions")
+		api.RespondWithError(http.StatusInternalServerError, w, "error analyzing variant interactions")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, analysis)
 }
 
-func (s *Server) jsonTestAnalysisByVariantFromDB(w http.ResponseWriter, req *http.Request) {
-	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByVariantFromDB)
+// jsonTestOwnershipCoverageFromDB reports how many of the release's tests map to a component, listing the ones that
+// don't.
+func (s *Server) jsonTestOwnershipCoverageFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	coverage, err := api.GetTestOwnershipCoverageFromDB(s.db.WithContext(req.Context()), release)
+	if err != nil {
+		log.WithError(err).Error("error querying test ownership coverage from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test ownership coverage from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, coverage)
 }
 
 func (s *Server) jsonTestAnalysisOverallFromDB(w http.ResponseWriter, req *http.Request) {
@@ -481,20 +1498,14 @@ func (s *Server) jsonTestAnalysisOverallFromDB(w http.ResponseWriter, req *http.
 func (s *Server) jsonTestBugsFromDB(w http.ResponseWriter, req *http.Request) {
 	testName := req.URL.Query().Get("test")
 	if testName == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": "'test' is required.",
-		})
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
 		return
 	}
 
-	bugs, err := query.LoadBugsForTest(s.db, testName, false)
+	bugs, err := query.LoadBugsForTest(s.db.WithContext(req.Context()), testName, false)
 	if err != nil {
 		log.WithError(err).Error("error querying test bugs from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying test bugs from db",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test bugs from db")
 		return
 	}
 	api.RespondWithJSON(http.StatusOK, w, bugs)
@@ -508,29 +1519,20 @@ func (s *Server) jsonTestDurationsFromDB(w http.ResponseWriter, req *http.Reques
 
 	testName := req.URL.Query().Get("test")
 	if testName == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": "'test' is required.",
-		})
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
 		return
 	}
 
 	filters, err := filter.ExtractFilters(req)
 	if err != nil {
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error processing filter options",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error processing filter options")
 		return
 	}
 
-	outputs, err := api.GetTestDurationsFromDB(s.db, release, testName, filters)
+	outputs, err := api.GetTestDurationsFromDB(s.db.WithContext(req.Context()), release, testName, filters)
 	if err != nil {
 		log.WithError(err).Error("error querying test outputs from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying test outputs from db",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test outputs from db")
 		return
 	}
 	api.RespondWithJSON(http.StatusOK, w, outputs)
@@ -544,29 +1546,20 @@ func (s *Server) jsonTestOutputsFromDB(w http.ResponseWriter, req *http.Request)
 
 	testName := req.URL.Query().Get("test")
 	if testName == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": "'test' is required.",
-		})
+		api.RespondWithError(http.StatusBadRequest, w, "'test' is required.")
 		return
 	}
 
 	filters, err := filter.ExtractFilters(req)
 	if err != nil {
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error processing filter options",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error processing filter options")
 		return
 	}
 
-	outputs, err := api.GetTestOutputsFromDB(s.db, release, testName, filters, 10)
+	outputs, err := api.GetTestOutputsFromDB(s.db.WithContext(req.Context()), release, testName, filters, 10)
 	if err != nil {
 		log.WithError(err).Error("error querying test outputs from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying test outputs from db",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test outputs from db")
 		return
 	}
 	api.RespondWithJSON(http.StatusOK, w, outputs)
@@ -574,22 +1567,16 @@ func (s *Server) jsonTestOutputsFromDB(w http.ResponseWriter, req *http.Request)
 
 func (s *Server) jsonComponentTestVariantsFromBigQuery(w http.ResponseWriter, req *http.Request) {
 	if s.bigQueryClient == nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": "component report API is only available when google-service-account-credential-file is configured",
-		})
+		api.RespondWithError(http.StatusBadRequest, w, "component report API is only available when google-service-account-credential-file is configured")
 		return
 	}
-	outputs, errs := api.GetComponentTestVariantsFromBigQuery(s.bigQueryClient, s.gcsBucket)
+	outputs, errs := api.GetComponentTestVariantsFromBigQuery(req.Context(), s.bigQueryClient, s.gcsBucket)
 	if len(errs) > 0 {
 		log.Warningf("%d errors were encountered while querying test variants from big query:", len(errs))
 		for _, err := range errs {
 			log.Error(err.Error())
 		}
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": fmt.Sprintf("error querying test variants from big query: %v", errs),
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, fmt.Sprintf("error querying test variants from big query: %v", errs))
 		return
 	}
 	api.RespondWithJSON(http.StatusOK, w, outputs)
@@ -598,14 +1585,12 @@ func (s *Server) jsonComponentTestVariantsFromBigQuery(w http.ResponseWriter, re
 func (s *Server) jsonComponentReportFromBigQuery(w http.ResponseWriter, req *http.Request) {
 	baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOption, err := s.parseComponentReportRequest(req)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": err.Error(),
-		})
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 
 	outputs, errs := api.GetComponentReportFromBigQuery(
+		req.Context(),
 		s.bigQueryClient,
 		s.gcsBucket,
 		baseRelease,
@@ -621,10 +1606,7 @@ func (s *Server) jsonComponentReportFromBigQuery(w http.ResponseWriter, req *htt
 		for _, err := range errs {
 			log.Error(err.Error())
 		}
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": fmt.Sprintf("error querying component from big query: %v", errs),
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, fmt.Sprintf("error querying component from big query: %v", errs))
 		return
 	}
 	api.RespondWithJSON(http.StatusOK, w, outputs)
@@ -633,13 +1615,11 @@ func (s *Server) jsonComponentReportFromBigQuery(w http.ResponseWriter, req *htt
 func (s *Server) jsonComponentReportTestDetailsFromBigQuery(w http.ResponseWriter, req *http.Request) {
 	baseRelease, sampleRelease, testIDOption, variantOption, excludeOption, advancedOption, cacheOption, err := s.parseComponentReportRequest(req)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": err.Error(),
-		})
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 	outputs, errs := api.GetComponentReportTestDetailsFromBigQuery(
+		req.Context(),
 		s.bigQueryClient,
 		s.gcsBucket,
 		baseRelease,
@@ -654,10 +1634,7 @@ func (s *Server) jsonComponentReportTestDetailsFromBigQuery(w http.ResponseWrite
 		for _, err := range errs {
 			log.Error(err.Error())
 		}
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": fmt.Sprintf("error querying component test details from big query: %v", errs),
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, fmt.Sprintf("error querying component test details from big query: %v", errs))
 		return
 	}
 	api.RespondWithJSON(http.StatusOK, w, outputs)
@@ -811,12 +1788,12 @@ func (s *Server) jsonJobBugsFromDB(w http.ResponseWriter, req *http.Request) {
 
 	fil, err := filter.ExtractFilters(req)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
 		return
 	}
 	jobFilter, _, err := splitJobAndJobRunFilters(fil)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
 		return
 	}
 
@@ -824,23 +1801,17 @@ func (s *Server) jsonJobBugsFromDB(w http.ResponseWriter, req *http.Request) {
 	limit := getLimitParam(req)
 	sortField, sort := getSortParams(req)
 
-	jobIDs, err := query.ListFilteredJobIDs(s.db, release, jobFilter, start, boundary, end, limit, sortField, sort)
+	jobIDs, err := query.ListFilteredJobIDs(s.db.WithContext(req.Context()), release, jobFilter, start, boundary, end, limit, sortField, sort)
 	if err != nil {
 		log.WithError(err).Error("error querying jobs")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying jobs",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying jobs")
 		return
 	}
 
-	bugs, err := query.LoadBugsForJobs(s.db, jobIDs, false)
+	bugs, err := query.LoadBugsForJobs(s.db.WithContext(req.Context()), jobIDs, false)
 	if err != nil {
 		log.WithError(err).Error("error querying job bugs from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying job bugs from db",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying job bugs from db")
 		return
 	}
 	api.RespondWithJSON(http.StatusOK, w, bugs)
@@ -849,7 +1820,14 @@ func (s *Server) jsonJobBugsFromDB(w http.ResponseWriter, req *http.Request) {
 func (s *Server) jsonTestsReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getReleaseOrFail(w, req)
 	if release != "" {
-		api.PrintTestsJSONFromDB(release, w, req, s.db)
+		api.PrintTestsJSONFromDB(release, w, req, s.db.WithContext(req.Context()))
+	}
+}
+
+func (s *Server) jsonTestsDiffReportFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release != "" {
+		api.PrintTestsDiffJSONFromDB(release, w, req, s.db.WithContext(req.Context()), s.GetReportEnd())
 	}
 }
 
@@ -858,64 +1836,64 @@ func (s *Server) jsonTestDetailsReportFromDB(w http.ResponseWriter, req *http.Re
 	testSubstring := req.URL.Query()["test"]
 	release := s.getReleaseOrFail(w, req)
 	if release != "" {
-		api.PrintTestsDetailsJSONFromDB(w, release, testSubstring, s.db)
+		api.PrintTestsDetailsJSONFromDB(w, release, testSubstring, s.db.WithContext(req.Context()))
 	}
 }
 
-func (s *Server) jsonReleasesReportFromDB(w http.ResponseWriter, _ *http.Request) {
+func (s *Server) jsonReleasesReportFromDB(w http.ResponseWriter, req *http.Request) {
 	response := apitype.Releases{
 		GADates: releaseloader.GADateMap,
 	}
-	releases, err := query.ReleasesFromDB(s.db)
+	var releases []query.Release
+	var err error
+	if tenant := api.Tenant(req.Context()); tenant != "" {
+		releases, err = query.TenantReleasesFromDB(s.db, tenant)
+	} else {
+		releases, err = query.ReleasesFromDB(s.db)
+	}
 	if err != nil {
 		log.WithError(err).Error("error querying releases from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying releases from db",
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying releases from db")
 		return
 	}
 
 	for _, release := range releases {
 		response.Releases = append(response.Releases, release.Release)
+		milestones := api.ReleaseMilestones(release.Release, s.releaseMilestones(release.Release), s.GetReportEnd())
+		if len(milestones) > 0 {
+			if response.Milestones == nil {
+				response.Milestones = map[string][]apitype.ReleaseMilestone{}
+			}
+			response.Milestones[release.Release] = milestones
+		}
 	}
 
-	type LastUpdated struct {
-		Max time.Time
-	}
-	var lastUpdated LastUpdated
 	// Assume our last update is the last time we inserted a prow job run.
-	res := s.db.DB.Raw("SELECT MAX(created_at) FROM prow_job_runs").Scan(&lastUpdated)
-	if res.Error != nil {
-		log.WithError(res.Error).Error("error querying last updated from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying last updated from db",
-		})
+	lastUpdated, err := query.LastProwJobRunCreated(s.db)
+	if err != nil {
+		log.WithError(err).Error("error querying last updated from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying last updated from db")
 		return
 	}
 
-	response.LastUpdated = lastUpdated.Max
+	response.LastUpdated = lastUpdated
 	api.RespondWithJSON(http.StatusOK, w, response)
 }
 
 func (s *Server) jsonHealthReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getReleaseOrFail(w, req)
 	if release != "" {
-		api.PrintOverallReleaseHealthFromDB(w, s.db, release, s.GetReportEnd())
+		api.PrintOverallReleaseHealthFromDB(w, s.db.WithContext(req.Context()), release, s.GetReportEnd())
 	}
 }
 
 func (s *Server) jsonBuildClusterHealth(w http.ResponseWriter, req *http.Request) {
 	start, boundary, end := getPeriodDates("default", req, s.GetReportEnd())
 
-	results, err := api.GetBuildClusterHealthReport(s.db, start, boundary, end)
+	results, err := api.GetBuildClusterHealthReport(s.db.WithContext(req.Context()), start, boundary, end)
 	if err != nil {
 		log.WithError(err).Error("error querying build cluster health from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying build cluster health from db " + err.Error(),
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying build cluster health from db "+err.Error())
 		return
 	}
 
@@ -928,13 +1906,10 @@ func (s *Server) jsonBuildClusterHealthAnalysis(w http.ResponseWriter, req *http
 		period = api.PeriodDay
 	}
 
-	results, err := api.GetBuildClusterHealthAnalysis(s.db, period)
+	results, err := api.GetBuildClusterHealthAnalysis(s.db.WithContext(req.Context()), period)
 	if err != nil {
 		log.WithError(err).Error("error querying build cluster health from db")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
-			"code":    http.StatusInternalServerError,
-			"message": "error querying build cluster health from db " + err.Error(),
-		})
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying build cluster health from db "+err.Error())
 		return
 	}
 
@@ -949,10 +1924,7 @@ func (s *Server) getReleaseOrFail(w http.ResponseWriter, req *http.Request) stri
 	release := req.URL.Query().Get("release")
 
 	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    "400",
-			"message": "release is required",
-		})
+		api.RespondWithError(http.StatusBadRequest, w, "release is required")
 		return release
 	}
 
@@ -963,7 +1935,7 @@ func (s *Server) jsonJobsDetailsReportFromDB(w http.ResponseWriter, req *http.Re
 	release := s.getReleaseOrFail(w, req)
 	jobName := req.URL.Query().Get("job")
 	if release != "" && jobName != "" {
-		err := api.PrintJobDetailsReportFromDB(w, req, s.db, release, jobName, s.GetReportEnd())
+		err := api.PrintJobDetailsReportFromDB(w, req, s.db.WithContext(req.Context()), release, jobName, s.GetReportEnd())
 		if err != nil {
 			log.Errorf("Error from PrintJobDetailsReportFromDB: %v", err)
 		}
@@ -981,21 +1953,22 @@ func (s *Server) printReportDate(w http.ResponseWriter, req *http.Request) {
 func (s *Server) printCanaryReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getReleaseOrFail(w, req)
 	if release != "" {
-		api.PrintCanaryTestsFromDB(release, w, s.db)
+		api.PrintCanaryTestsFromDB(release, w, s.db.WithContext(req.Context()))
 	}
 }
 
 func (s *Server) jsonVariantsReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getReleaseOrFail(w, req)
 	if release != "" {
-		api.PrintVariantReportFromDB(w, req, s.db, release, s.GetReportEnd())
+		api.PrintVariantReportFromDB(w, req, s.db.WithContext(req.Context()), release, s.GetReportEnd(),
+			s.variantManager.InvalidVariants(release))
 	}
 }
 
 func (s *Server) jsonJobsReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getReleaseOrFail(w, req)
 	if release != "" {
-		api.PrintJobsReportFromDB(w, req, s.db, release, s.GetReportEnd())
+		api.PrintJobsReportFromDB(w, req, s.db.WithContext(req.Context()), release, s.GetReportEnd())
 	}
 }
 
@@ -1004,16 +1977,14 @@ func (s *Server) jsonRepositoriesReportFromDB(w http.ResponseWriter, req *http.R
 	if release != "" {
 		filterOpts, err := filter.FilterOptionsFromRequest(req, "premerge_job_failures", apitype.SortDescending)
 		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse filter opts " + err.Error()})
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse filter opts: "+err.Error())
 			return
 		}
 
-		results, err := api.GetRepositoriesReportFromDB(s.db, release, filterOpts, s.GetReportEnd())
+		results, err := api.GetRepositoriesReportFromDB(s.db.WithContext(req.Context()), release, filterOpts, s.GetReportEnd())
 		if err != nil {
 			log.WithError(err).Error("error")
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "Error fetching repositories " + err.Error()})
+			api.RespondWithError(http.StatusInternalServerError, w, "Error fetching repositories "+err.Error())
 			return
 		}
 
@@ -1026,16 +1997,14 @@ func (s *Server) jsonPullRequestsReportFromDB(w http.ResponseWriter, req *http.R
 	if release != "" {
 		filterOpts, err := filter.FilterOptionsFromRequest(req, "merged_at", apitype.SortDescending)
 		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse filter opts " + err.Error()})
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't parse filter opts: "+err.Error())
 			return
 		}
 
-		results, err := api.GetPullRequestsReportFromDB(s.db, release, filterOpts)
+		results, err := api.GetPullRequestsReportFromDB(s.db.WithContext(req.Context()), release, filterOpts)
 		if err != nil {
 			log.WithError(err).Error("error")
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "Error fetching pull requests" + err.Error()})
+			api.RespondWithError(http.StatusInternalServerError, w, "Error fetching pull requests"+err.Error())
 			return
 		}
 
@@ -1043,30 +2012,111 @@ func (s *Server) jsonPullRequestsReportFromDB(w http.ResponseWriter, req *http.R
 	}
 }
 
+// jsonPullRequestImpactFromDB lists the pull requests first included in a stream's payloads in the last week, or up to
+// two weeks, most correlated with rejected payloads, regressed jobs and new test regressions.
+func (s *Server) jsonPullRequestImpactFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	stream := req.URL.Query().Get("stream")
+	if stream == "" {
+		stream = "nightly"
+	}
+	arch := req.URL.Query().Get("arch")
+	if arch == "" {
+		arch = "amd64"
+	}
+	days := 7
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > 14 {
+			api.RespondWithError(http.StatusBadRequest, w, "days must be between 1 and 14")
+			return
+		}
+	}
+
+	end := s.GetReportEnd()
+	impacts, err := api.GetPullRequestImpactFromDB(s.db.WithContext(req.Context()), release, stream, arch, end.AddDate(0, 0, -days), end)
+	if err != nil {
+		log.WithError(err).Error("error analyzing pull request impact")
+		api.RespondWithError(http.StatusInternalServerError, w, "error analyzing pull request impact: "+err.Error())
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, impacts)
+}
+
 func (s *Server) jsonJobRunsReportFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getRelease(req)
 
 	filterOpts, err := filter.FilterOptionsFromRequest(req, "timestamp", "desc")
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
 		return
 	}
 
 	pagination, err := getPaginationParams(req)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not parse pagination options: " + err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, "Could not parse pagination options: "+err.Error())
 		return
 	}
 
-	result, err := api.JobsRunsReportFromDB(s.db, filterOpts, release, pagination, s.GetReportEnd())
+	result, err := api.JobsRunsReportFromDB(s.db.WithContext(req.Context()), filterOpts, release, pagination, s.GetReportEnd())
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 
 	api.RespondWithJSON(http.StatusOK, w, result)
 }
 
+// jsonJobRunTestsFromDB lists the tests that failed or flaked in a job run, with the kept failure output and
+// system-out of the test param when it's set.
+func (s *Server) jsonJobRunTestsFromDB(w http.ResponseWriter, req *http.Request) {
+	jobRunIDStr := req.URL.Query().Get("prow_job_run_id")
+	if jobRunIDStr == "" {
+		api.RespondWithError(http.StatusBadRequest, w, "prow_job_run_id query parameter not specified")
+		return
+	}
+	jobRunID, err := strconv.ParseUint(jobRunIDStr, 10, 64)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "unable to parse prow_job_run_id: "+err.Error())
+		return
+	}
+
+	tests, err := api.GetJobRunTestsFromDB(s.db.WithContext(req.Context()), uint(jobRunID), req.URL.Query().Get("test"))
+	if err != nil {
+		log.WithError(err).Error("error querying job run tests from db")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying job run tests from db")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, tests)
+}
+
+// jsonJobRunRetestRecommendation recommends whether the failed run given by prow_job_run_id is likely to pass when
+// retested, or failed for a real reason, for bots deciding whether to retest presubmits.
+func (s *Server) jsonJobRunRetestRecommendation(w http.ResponseWriter, req *http.Request) {
+	jobRunID, err := strconv.ParseInt(req.URL.Query().Get("prow_job_run_id"), 10, 64)
+	if err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "unable to parse prow_job_run_id: "+err.Error())
+		return
+	}
+
+	logger := log.WithField("func", "jsonJobRunRetestRecommendation").WithField("jobRunID", jobRunID)
+	recommendation, err := api.GetRetestRecommendationFromDB(s.db.WithContext(req.Context()), jobRunID,
+		s.GetReportEnd(), logger)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("no job run %d", jobRunID))
+		return
+	}
+	if err != nil {
+		logger.WithError(err).Error("error building retest recommendation")
+		api.RespondWithError(http.StatusInternalServerError, w, "error building retest recommendation")
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, recommendation)
+}
+
 // jsonJobRunRiskAnalysis is an API to make a guess at the severity of failures in a prow job run, based on historical
 // pass rates for each failed test, on-going incidents, and other factors.
 //
@@ -1090,29 +2140,24 @@ func (s *Server) jsonJobRunRiskAnalysis(w http.ResponseWriter, req *http.Request
 
 		jobRunID, err := strconv.ParseInt(jobRunIDStr, 10, 64)
 		if err != nil {
-			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-				"code":    http.StatusBadRequest,
-				"message": "unable to parse prow_job_run_id: " + err.Error()})
+			api.RespondWithError(http.StatusBadRequest, w, "unable to parse prow_job_run_id: "+err.Error())
 			return
 		}
 
 		logger = logger.WithField("jobRunID", jobRunID)
 
 		// lookup prowjob and run count
-		jobRun, jobRunTestCount, err = api.FetchJobRun(s.db, jobRunID, logger)
+		jobRun, jobRunTestCount, err = api.FetchJobRun(s.db.WithContext(req.Context()), jobRunID, logger)
 
 		if err != nil {
-			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-				"code": http.StatusBadRequest, "message": err.Error()})
+			api.RespondWithError(http.StatusBadRequest, w, err.Error())
 			return
 		}
 
 	} else {
 		err := json.NewDecoder(req.Body).Decode(&jobRun)
 		if err != nil {
-			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-				"code":    http.StatusBadRequest,
-				"message": fmt.Sprintf("error decoding prow job run json in request body: %s", err)})
+			api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("error decoding prow job run json in request body: %s", err))
 			return
 		}
 
@@ -1139,11 +2184,9 @@ func (s *Server) jsonJobRunRiskAnalysis(w http.ResponseWriter, req *http.Request
 		// We don't expect the caller to fully populate the ProwJob, just it's name,
 		// override the input by looking up the actual ProwJob so we have access to release and variants.
 		job := &models.ProwJob{}
-		res := s.db.DB.Where("name = ?", jobRun.ProwJob.Name).First(job)
+		res := s.db.WithContext(req.Context()).DB.Where("name = ?", jobRun.ProwJob.Name).First(job)
 		if res.Error != nil {
-			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-				"code":    http.StatusBadRequest,
-				"message": fmt.Sprintf("unable to find ProwJob: %s", jobRun.ProwJob.Name)})
+			api.RespondWithError(http.StatusBadRequest, w, fmt.Sprintf("unable to find ProwJob: %s", jobRun.ProwJob.Name))
 			return
 		}
 		jobRun.ProwJob = *job
@@ -1151,16 +2194,15 @@ func (s *Server) jsonJobRunRiskAnalysis(w http.ResponseWriter, req *http.Request
 		// if the ClusterData is being passed in then use it to override the variants (agnostic case, etc)
 		if jobRun.ClusterData.Release != "" {
 			jobRun.ProwJob.Variants = s.variantManager.IdentifyVariants(jobRun.ProwJob.Name, jobRun.ClusterData.Release, jobRun.ClusterData)
+			jobRun.ProwJob.VariantDimensions = s.variantManager.IdentifyVariantDimensions(jobRun.ProwJob.Name, jobRun.ClusterData.Release, jobRun.ClusterData)
 		}
 		logger = logger.WithField("jobRunID", jobRun.ID)
 	}
 
 	logger.Infof("job run = %+v", *jobRun)
-	result, err := api.JobRunRiskAnalysis(s.db, jobRun, jobRunTestCount, logger.WithField("func", "JobRunRiskAnalysis"))
+	result, err := api.JobRunRiskAnalysis(s.db.WithContext(req.Context()), jobRun, jobRunTestCount, logger.WithField("func", "JobRunRiskAnalysis"))
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 
@@ -1176,24 +2218,19 @@ func (s *Server) jsonJobRunIntervals(w http.ResponseWriter, req *http.Request) {
 	logger := log.WithField("func", "jsonJobRunIntervals")
 
 	if s.gcsClient == nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": "server not configured for GCS, unable to use this API"})
+		api.RespondWithError(http.StatusBadRequest, w, "server not configured for GCS, unable to use this API")
 		return
 	}
 
 	jobRunIDStr := req.URL.Query().Get("prow_job_run_id")
 	if jobRunIDStr == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code": http.StatusBadRequest, "message": "prow_job_run_id query parameter not specified"})
+		api.RespondWithError(http.StatusBadRequest, w, "prow_job_run_id query parameter not specified")
 		return
 	}
 
 	jobRunID, err := strconv.ParseInt(jobRunIDStr, 10, 64)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": "unable to parse prow_job_run_id: " + err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, "unable to parse prow_job_run_id: "+err.Error())
 		return
 	}
 	logger = logger.WithField("jobRunID", jobRunID)
@@ -1221,12 +2258,10 @@ func (s *Server) jsonJobRunIntervals(w http.ResponseWriter, req *http.Request) {
 		// JobName was not passed.
 		gcsPath = ""
 	}
-	result, err := jobrunintervals.JobRunIntervals(s.gcsClient, s.db, jobRunID, s.gcsBucket, gcsPath,
+	result, err := jobrunintervals.JobRunIntervals(s.gcsClient, s.db.WithContext(req.Context()), jobRunID, s.gcsBucket, gcsPath,
 		logger.WithField("func", "JobRunRiskIntervals"))
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, err.Error())
 		return
 	}
 
@@ -1259,12 +2294,12 @@ func (s *Server) jsonJobsAnalysisFromDB(w http.ResponseWriter, req *http.Request
 
 	fil, err := filter.ExtractFilters(req)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
 		return
 	}
 	jobFilter, jobRunsFilter, err := splitJobAndJobRunFilters(fil)
 	if err != nil {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
+		api.RespondWithError(http.StatusBadRequest, w, "Could not marshal query:"+err.Error())
 		return
 	}
 
@@ -1277,17 +2312,93 @@ func (s *Server) jsonJobsAnalysisFromDB(w http.ResponseWriter, req *http.Request
 		period = api.PeriodDay
 	}
 
-	results, err := api.PrintJobAnalysisJSONFromDB(s.db, release, jobFilter, jobRunsFilter,
+	results, err := api.PrintJobAnalysisJSONFromDB(s.db.WithContext(req.Context()), release, jobFilter, jobRunsFilter,
 		start, boundary, end, limit, sortField, sort, period, s.GetReportEnd())
 	if err != nil {
 		log.WithError(err).Error("error in PrintJobAnalysisJSONFromDB")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": err.Error()})
+		api.RespondWithError(http.StatusInternalServerError, w, err.Error())
 		return
 	}
 
 	api.RespondWithJSON(http.StatusOK, w, results)
 }
 
+// jsonToolDefinitions lists the tools LLM agents can call, with JSON schemas for their arguments.
+func (s *Server) jsonToolDefinitions(w http.ResponseWriter, req *http.Request) {
+	api.RespondWithJSON(http.StatusOK, w, tools.Definitions())
+}
+
+// jsonToolCall runs a tool, with a POST body of {"name": ..., "arguments": {...}}.
+func (s *Server) jsonToolCall(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "tool calls must be POSTed")
+		return
+	}
+
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.NewDecoder(io.LimitReader(req.Body, 64*1024)).Decode(&call); err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "could not decode tool call: "+err.Error())
+		return
+	}
+
+	result, err := tools.Call(req.Context(), s.db, call.Name, call.Arguments, s.GetReportEnd())
+	if err != nil {
+		api.RespondWithProblemOrError(w, err, "calling tool "+call.Name)
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, map[string]interface{}{"name": call.Name, "result": result})
+}
+
+// jsonQuery lists the analytical query templates on GET, and runs one with a POST body of
+// {"template": ..., "parameters": {...}, "limit": ...}. Queries are only run for users identified by the
+// authenticating proxy, and are recorded in the audit log.
+func (s *Server) jsonQuery(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		api.RespondWithJSON(http.StatusOK, w, querytemplates.Templates())
+		return
+	case http.MethodPost:
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "queries must be POSTed")
+		return
+	}
+
+	user := auditUser(req)
+	if user == "anonymous" {
+		api.RespondWithError(http.StatusForbidden, w, "queries require an authenticated user")
+		return
+	}
+
+	var q struct {
+		Template   string          `json:"template"`
+		Parameters json.RawMessage `json:"parameters"`
+		Limit      int             `json:"limit"`
+	}
+	if err := json.NewDecoder(io.LimitReader(req.Body, 64*1024)).Decode(&q); err != nil {
+		api.RespondWithError(http.StatusBadRequest, w, "could not decode query: "+err.Error())
+		return
+	}
+
+	result, err := querytemplates.Run(req.Context(), s.db, q.Template, q.Parameters, q.Limit, s.GetReportEnd())
+	if err != nil {
+		api.RespondWithProblemOrError(w, err, "running query template")
+		return
+	}
+
+	setAuditAfter(req, map[string]interface{}{
+		"template":   q.Template,
+		"parameters": q.Parameters,
+		"limit":      q.Limit,
+		"row_count":  result.RowCount,
+		"truncated":  result.Truncated,
+	})
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
 func (s *Server) Serve() {
 	// Use private ServeMux to prevent tests from stomping on http.DefaultServeMux
 	serveMux := http.NewServeMux()
@@ -1317,6 +2428,10 @@ func (s *Server) Serve() {
 
 	// Re-direct "/" to sippy-ng
 	serveMux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/api/") {
+			api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("no API endpoint found for %s", req.URL.Path))
+			return
+		}
 		if req.URL.Path != "/" {
 			http.NotFound(w, req)
 			return
@@ -1325,32 +2440,60 @@ func (s *Server) Serve() {
 	})
 
 	serveMux.HandleFunc("/api/autocomplete/", s.jsonAutocompleteFromDB)
-	serveMux.HandleFunc("/api/jobs", s.jsonJobsReportFromDB)
+	serveMux.HandleFunc("/api/jobs", s.cached(1*time.Hour, s.jsonJobsReportFromDB))
 	serveMux.HandleFunc("/api/jobs/runs", s.jsonJobRunsReportFromDB)
+	serveMux.HandleFunc("/api/jobs/runs/tests", s.jsonJobRunTestsFromDB)
 	serveMux.HandleFunc("/api/jobs/runs/risk_analysis", s.jsonJobRunRiskAnalysis)
+	serveMux.HandleFunc("/api/jobs/runs/retest_recommendation", s.jsonJobRunRetestRecommendation)
 	serveMux.HandleFunc("/api/jobs/runs/intervals", s.cached(4*time.Hour, s.jsonJobRunIntervals))
 	serveMux.HandleFunc("/api/jobs/analysis", s.jsonJobsAnalysisFromDB)
 	serveMux.HandleFunc("/api/jobs/details", s.jsonJobsDetailsReportFromDB)
 	serveMux.HandleFunc("/api/jobs/bugs", s.jsonJobBugsFromDB)
+	serveMux.HandleFunc("/api/jobs/timeouts", s.cached(1*time.Hour, s.jsonJobTimeoutRisksFromDB))
+	serveMux.HandleFunc("/api/jobs/history", s.cached(1*time.Hour, s.jsonJobHistoryFromDB))
+	serveMux.HandleFunc("/api/jobs/silent", s.cached(1*time.Hour, s.jsonSilentJobsFromDB))
+	serveMux.HandleFunc("/api/jobs/import_errors", s.jsonLoaderErrorsFromDB)
+	serveMux.HandleFunc("/api/jobs/", s.cached(1*time.Hour, s.jsonJobFromDB))
+	serveMux.HandleFunc("/api/sigs/", s.cached(1*time.Hour, s.jsonSigFromDB))
+	serveMux.HandleFunc("/api/components/hierarchy", s.cached(1*time.Hour, s.jsonComponentHierarchyFromDB))
+	serveMux.HandleFunc("/api/report/handoff", s.markdownHandoffReportFromDB)
+	serveMux.HandleFunc("/api/search", s.cached(1*time.Hour, s.jsonSearchFromDB))
 	serveMux.HandleFunc("/api/pull_requests", s.cached(1*time.Hour, s.jsonPullRequestsReportFromDB))
+	serveMux.HandleFunc("/api/pull_requests/impact", s.cached(1*time.Hour, s.jsonPullRequestImpactFromDB))
 	serveMux.HandleFunc("/api/repositories", s.jsonRepositoriesReportFromDB)
-	serveMux.HandleFunc("/api/tests", s.jsonTestsReportFromDB)
+	serveMux.HandleFunc("/api/tests", s.cached(1*time.Hour, s.jsonTestsReportFromDB))
+	serveMux.HandleFunc("/api/tests/diff", s.cached(1*time.Hour, s.jsonTestsDiffReportFromDB))
 	serveMux.HandleFunc("/api/tests/details", s.cached(1*time.Hour, s.jsonTestDetailsReportFromDB))
 	serveMux.HandleFunc("/api/tests/analysis/overall", s.cached(1*time.Hour, s.jsonTestAnalysisOverallFromDB))
 	serveMux.HandleFunc("/api/tests/analysis/variants", s.cached(1*time.Hour, s.jsonTestAnalysisByVariantFromDB))
 	serveMux.HandleFunc("/api/tests/analysis/jobs", s.cached(1*time.Hour, s.jsonTestAnalysisByJobFromDB))
+	serveMux.HandleFunc("/api/tests/analysis/variant_interactions", s.cached(1*time.Hour, s.jsonVariantInteractionsFromDB))
+	serveMux.HandleFunc("/api/tests/history", s.cached(1*time.Hour, s.jsonTestHistoryFromDB))
+	serveMux.HandleFunc("/api/tests/history/releases", s.cached(1*time.Hour, s.jsonCrossReleaseTestHistoryFromDB))
+	serveMux.HandleFunc("/api/tests/", s.cached(1*time.Hour, s.jsonTestFromDB))
 	serveMux.HandleFunc("/api/tests/bugs", s.jsonTestBugsFromDB)
 	serveMux.HandleFunc("/api/tests/outputs", s.cached(1*time.Hour, s.jsonTestOutputsFromDB))
+	serveMux.HandleFunc("/api/failure-clusters", s.cached(1*time.Hour, s.jsonFailureClustersFromDB))
 	serveMux.HandleFunc("/api/tests/durations", s.cached(1*time.Hour, s.jsonTestDurationsFromDB))
+	serveMux.HandleFunc("/api/tests/durations/percentiles", s.cached(1*time.Hour, s.jsonTestDurationPercentilesFromDB))
+	serveMux.HandleFunc("/api/tests/durations/regressions", s.cached(1*time.Hour, s.jsonTestDurationRegressionsFromDB))
+	serveMux.HandleFunc("/api/tests/new", s.cached(1*time.Hour, s.jsonNewTestsFromDB))
+	serveMux.HandleFunc("/api/tests/regressions", s.cached(1*time.Hour, s.jsonTestRegressionsFromDB))
+	serveMux.HandleFunc("/api/tests/skips", s.cached(1*time.Hour, s.jsonTestSkipRatesFromDB))
+	serveMux.HandleFunc("/api/tests/ownership", s.cached(1*time.Hour, s.jsonTestOwnershipCoverageFromDB))
 	serveMux.HandleFunc("/api/install", s.cached(1*time.Hour, s.jsonInstallReportFromDB))
 	serveMux.HandleFunc("/api/upgrade", s.cached(1*time.Hour, s.jsonUpgradeReportFromDB))
+	serveMux.HandleFunc("/api/install/funnel", s.cached(1*time.Hour, s.jsonFunnelFromDB(models.FunnelInstall)))
+	serveMux.HandleFunc("/api/upgrade/funnel", s.cached(1*time.Hour, s.jsonFunnelFromDB(models.FunnelUpgrade)))
 	serveMux.HandleFunc("/api/releases", s.jsonReleasesReportFromDB)
 	serveMux.HandleFunc("/api/health/build_cluster/analysis", s.jsonBuildClusterHealthAnalysis)
 	serveMux.HandleFunc("/api/health/build_cluster", s.jsonBuildClusterHealth)
-	serveMux.HandleFunc("/api/health", s.jsonHealthReportFromDB)
+	serveMux.HandleFunc("/api/health", s.cached(1*time.Hour, s.jsonHealthReportFromDB))
 	serveMux.HandleFunc("/api/variants", s.jsonVariantsReportFromDB)
+	serveMux.HandleFunc("/api/variants/metadata", s.jsonVariantsMetadata)
 	serveMux.HandleFunc("/api/canary", s.printCanaryReportFromDB)
 	serveMux.HandleFunc("/api/report_date", s.printReportDate)
+	serveMux.HandleFunc("/api/refresh/status", s.audited(s.jsonRefreshStatus))
 	// Note that component readiness is cached, but at the lower layer of report generation so we can use the cached
 	// data in metrics.
 	serveMux.HandleFunc("/api/component_readiness", s.jsonComponentReportFromBigQuery)
@@ -1360,46 +2503,109 @@ func (s *Server) Serve() {
 	serveMux.HandleFunc("/api/capabilities", s.jsonCapabilitiesReport)
 	if s.db != nil {
 		serveMux.HandleFunc("/api/releases/health", s.jsonReleaseHealthReport)
+		serveMux.HandleFunc("/api/releases/readiness", s.jsonReleaseReadinessReport)
+		serveMux.HandleFunc("/api/releases/readiness/forecast", s.cached(1*time.Hour, s.jsonReleaseReadinessForecast))
+		serveMux.HandleFunc("/api/releases/milestones", s.cached(1*time.Hour, s.jsonReleaseMilestoneReport))
 		serveMux.HandleFunc("/api/releases/tags/events", s.jsonReleaseTagsEvent)
 		serveMux.HandleFunc("/api/releases/tags", s.jsonReleaseTagsReport)
+		serveMux.HandleFunc("/api/releases/tags/", s.cached(1*time.Hour, s.jsonReleaseTagFromDB))
 		serveMux.HandleFunc("/api/releases/pull_requests", s.jsonReleasePullRequestsReport)
 		serveMux.HandleFunc("/api/releases/job_runs", s.jsonListPayloadJobRuns)
 		serveMux.HandleFunc("/api/incidents", s.jsonIncidentEvent)
+		serveMux.HandleFunc("/api/tools", s.jsonToolDefinitions)
+		serveMux.HandleFunc("/api/tools/call", s.jsonToolCall)
+		serveMux.HandleFunc("/api/query", s.audited(s.jsonQuery))
+		serveMux.HandleFunc("/api/audit", s.jsonAuditLog)
+		serveMux.HandleFunc("/api/saved_views", s.audited(s.jsonSavedViews))
+		serveMux.HandleFunc("/api/saved_views/", s.audited(s.jsonSavedView))
+		serveMux.HandleFunc("/api/tests/ownership/overrides", s.audited(s.jsonTestOwnershipOverrides))
+		serveMux.HandleFunc("/api/tests/ownership/overrides/", s.audited(s.jsonTestOwnershipOverride))
+		serveMux.HandleFunc("/api/tests/quarantines", s.audited(s.jsonTestQuarantines))
+		serveMux.HandleFunc("/api/tests/quarantines/", s.audited(s.jsonTestQuarantine))
+		serveMux.HandleFunc("/api/async_jobs", s.jsonAsyncJobs)
+		serveMux.HandleFunc("/api/async_jobs/result", s.asyncJobResult)
+		serveMux.HandleFunc("/api/jobs/runs/archived", s.jsonArchivedJobRun)
 
 		serveMux.HandleFunc("/api/releases/test_failures",
 			s.jsonGetPayloadAnalysis)
 
 		serveMux.HandleFunc("/api/payloads/test_failures",
 			s.jsonGetPayloadTestFailures)
+		serveMux.HandleFunc("/api/payloads/bisect", s.jsonPayloadBisect)
+		serveMux.HandleFunc("/api/payloads/gate", s.jsonPayloadGate)
+		serveMux.HandleFunc("/api/payloads/streams/compare", s.jsonPayloadStreamComparison)
 	}
 
+	serveMux.HandleFunc("/api/federated/", s.jsonFederated(s.tenantHandler(serveMux)))
+
+	serveMux.HandleFunc("/healthz", s.healthz)
+	serveMux.HandleFunc("/readyz", s.readyz)
+	s.registerProfiling(serveMux)
+
 	var handler http.Handler = serveMux
+	handler = s.tenantHandler(handler)
+	handler = recoverHandler(handler)
+	handler = tracingHandler(serveMux, handler)
+	handler = metricsHandler(serveMux, handler)
 	// wrap mux with our logger. this will
 	handler = logRequestHandler(handler)
 	// ... potentially add more middleware handlers
+	handler = requestIDHandler(handler)
 
 	// Store a pointer to the HTTP server for later retrieval.
-	s.httpServer = &http.Server{
+	s.httpServerLock.Lock()
+	if s.shutdown {
+		s.httpServerLock.Unlock()
+		return
+	}
+	httpServer := &http.Server{
 		Addr:              s.listenAddr,
 		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
+	s.httpServer = httpServer
+	s.serveMux = serveMux
+	asyncCtx, stopAsyncWorkers := context.WithCancel(context.Background())
+	s.stopAsyncWorkers = stopAsyncWorkers
+	s.httpServerLock.Unlock()
+
+	s.startAsyncWorkers(asyncCtx, s.tenantHandler(serveMux))
 
 	log.Infof("Serving reports on %s ", s.listenAddr)
 
-	if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
 		log.WithError(err).Error("Server exited")
 	}
 }
 
+// Shutdown stops the server accepting connections, and waits for in-flight requests to finish until the context is
+// done. Serve returns as soon as shutdown starts, so callers must wait for Shutdown to return before exiting.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.httpServerLock.Lock()
+	s.shutdown = true
+	httpServer := s.httpServer
+	stopAsyncWorkers := s.stopAsyncWorkers
+	s.httpServerLock.Unlock()
+
+	if stopAsyncWorkers != nil {
+		defer stopAsyncWorkers()
+	}
+
+	if httpServer == nil {
+		return nil
+	}
+	return httpServer.Shutdown(ctx)
+}
+
 func logRequestHandler(h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		h.ServeHTTP(w, r)
 		log.WithFields(log.Fields{
-			"uri":     r.URL.String(),
-			"method":  r.Method,
-			"elapsed": time.Since(start),
+			"uri":       r.URL.String(),
+			"method":    r.Method,
+			"elapsed":   time.Since(start),
+			"requestID": w.Header().Get(api.RequestIDHeader),
 		}).Info("responded to request")
 	}
 	return http.HandlerFunc(fn)
@@ -1412,7 +2618,11 @@ func (s *Server) cached(duration time.Duration, handler func(w http.ResponseWrit
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		content, err := s.cache.Get(r.RequestURI)
+		if warming, _ := r.Context().Value(cacheWarmingKey{}).(bool); warming {
+			recordResponse(s.cache, duration, w, r, handler)
+			return
+		}
+		content, err := s.cache.Get(cacheKey(r))
 		if err != nil { // cache miss
 			log.WithError(err).Debugf("cache miss: could not fetch data from cache for %q", r.RequestURI)
 		} else if content != nil && respondFromCache(content, w, r) == nil { // cache hit
@@ -1422,6 +2632,19 @@ func (s *Server) cached(duration time.Duration, handler func(w http.ResponseWrit
 	}
 }
 
+// cacheKey identifies a response in the cache by its tenant, path and query, with the query parameters sorted so the
+// same report requested with them in a different order is a hit.
+func cacheKey(r *http.Request) string {
+	key := r.URL.Path
+	if r.URL.RawQuery != "" {
+		key += "?" + r.URL.Query().Encode()
+	}
+	if tenant := api.Tenant(r.Context()); tenant != "" {
+		key = tenant + "|" + key
+	}
+	return key
+}
+
 func respondFromCache(content []byte, w http.ResponseWriter, r *http.Request) error {
 	apiResponse := cache.APIResponse{}
 	if err := json.Unmarshal(content, &apiResponse); err != nil {
@@ -1430,10 +2653,17 @@ func respondFromCache(content []byte, w http.ResponseWriter, r *http.Request) er
 	}
 	log.Debugf("cache hit for %q", r.RequestURI)
 	for k, v := range apiResponse.Headers {
+		// the request ID belongs to the request that populated the cache, not this one
+		if k == api.RequestIDHeader {
+			continue
+		}
 		w.Header()[k] = v
 	}
 	w.Header().Set("X-Sippy-Cached", "true")
-	w.WriteHeader(http.StatusOK)
+	if apiResponse.StatusCode == 0 {
+		apiResponse.StatusCode = http.StatusOK
+	}
+	w.WriteHeader(apiResponse.StatusCode)
 
 	if _, err := w.Write(apiResponse.Response); err != nil {
 		log.WithError(err).Debugf("error writing http response")
@@ -1454,15 +2684,17 @@ func recordResponse(c cache.Cache, duration time.Duration, w http.ResponseWriter
 	w.WriteHeader(recorder.Code)
 	content := recorder.Body.Bytes()
 	apiResponse.Response = content
+	apiResponse.StatusCode = recorder.Code
 
-	log.Debugf("caching new page: %s for %s\n", r.RequestURI, duration)
-	apiResponseBytes, err := json.Marshal(apiResponse)
-	if err != nil {
-		log.WithError(err).Warningf("couldn't marshal api response")
-	}
-
-	if err := c.Set(r.RequestURI, apiResponseBytes, duration); err != nil {
-		log.WithError(err).Warningf("could not cache page")
+	// errors, often transient, are retried by the next request rather than served from the cache
+	if recorder.Code >= 200 && recorder.Code < 300 {
+		log.Debugf("caching new page: %s for %s\n", r.RequestURI, duration)
+		apiResponseBytes, err := json.Marshal(apiResponse)
+		if err != nil {
+			log.WithError(err).Warningf("couldn't marshal api response")
+		} else if err := c.Set(cacheKey(r), apiResponseBytes, duration); err != nil {
+			log.WithError(err).Warningf("could not cache page")
+		}
 	}
 	if _, err := w.Write(content); err != nil {
 		log.WithError(err).Debugf("error writing http response")
@@ -1470,5 +2702,7 @@ func recordResponse(c cache.Cache, duration time.Duration, w http.ResponseWriter
 }
 
 func (s *Server) GetHTTPServer() *http.Server {
+	s.httpServerLock.Lock()
+	defer s.httpServerLock.Unlock()
 	return s.httpServer
 }
@@ -0,0 +1,147 @@
+package sippyserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// releaseParams are the query parameters naming a release a report is for.
+var releaseParams = []string{"release", "baseRelease", "sampleRelease"}
+
+// SetTenant scopes every API request to the tenant, ignoring the one requested, for a deployment serving a single
+// product.
+func (s *Server) SetTenant(tenant string) {
+	s.tenant = tenant
+}
+
+// requestTenant returns the tenant the request is scoped to, from the server, the tenant query parameter or the
+// tenant header, in that order.
+func (s *Server) requestTenant(r *http.Request) string {
+	if s.tenant != "" {
+		return s.tenant
+	}
+	if tenant := r.URL.Query().Get("tenant"); tenant != "" {
+		return tenant
+	}
+	return r.Header.Get(api.TenantHeader)
+}
+
+// tenantResource is a job, job run or payload a request names by ID or path, which must belong to the tenant.
+type tenantResource struct {
+	kind  string
+	name  string
+	owned func(dbc *db.DB, tenant string) (bool, error)
+}
+
+// requestedResources returns the jobs, job runs and payloads the request names by ID or path. IDs that don't parse are
+// left to the handler to reject.
+func requestedResources(r *http.Request) []tenantResource {
+	var resources []tenantResource
+	params := r.URL.Query()
+
+	if param := params.Get("prow_job_run_id"); param != "" {
+		if id, err := strconv.ParseUint(param, 10, 64); err == nil {
+			resources = append(resources, tenantResource{kind: "job run", name: param,
+				owned: func(dbc *db.DB, tenant string) (bool, error) {
+					return query.TenantHasJobRun(dbc, tenant, uint(id))
+				}})
+		}
+	}
+	for _, param := range []string{"job", "job_name"} {
+		if name := params.Get(param); name != "" {
+			resources = append(resources, tenantResource{kind: "job", name: name,
+				owned: func(dbc *db.DB, tenant string) (bool, error) {
+					return query.TenantHasJobName(dbc, tenant, name)
+				}})
+		}
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/jobs/") {
+		jobID := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/jobs/"), "/")[0]
+		if id, err := strconv.ParseUint(jobID, 10, 64); err == nil {
+			resources = append(resources, tenantResource{kind: "job", name: jobID,
+				owned: func(dbc *db.DB, tenant string) (bool, error) {
+					return query.TenantHasJob(dbc, tenant, uint(id))
+				}})
+		}
+	}
+
+	payloads := []string{params.Get("release_tag"), params.Get("payload")}
+	if strings.HasPrefix(r.URL.Path, "/api/releases/tags/") {
+		// the tag of /api/releases/tags/{tag}/changelog, unlike /api/releases/tags/events
+		if parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/releases/tags/"), "/"); len(parts) > 1 {
+			payloads = append(payloads, parts[0])
+		}
+	}
+	for _, payload := range payloads {
+		if payload != "" {
+			payload := payload
+			resources = append(resources, tenantResource{kind: "payload", name: payload,
+				owned: func(dbc *db.DB, tenant string) (bool, error) {
+					return query.TenantHasPayload(dbc, tenant, payload)
+				}})
+		}
+	}
+	return resources
+}
+
+// tenantHandler scopes API requests to their tenant, rejecting those for a release, job, job run or payload of
+// another tenant, so products sharing the instance can't see each other's reports.
+func (s *Server) tenantHandler(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		tenant := s.requestTenant(r)
+		if tenant == "" || s.db == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var requested []string
+		for _, param := range releaseParams {
+			if release := r.URL.Query().Get(param); release != "" {
+				requested = append(requested, release)
+			}
+		}
+		if len(requested) > 0 {
+			releases, err := query.TenantReleasesFromDB(s.db.WithContext(r.Context()), tenant)
+			if err != nil {
+				log.WithError(err).Error("error querying tenant releases")
+				api.RespondWithError(http.StatusInternalServerError, w, "error querying tenant releases")
+				return
+			}
+			tenantReleases := sets.NewString()
+			for _, release := range releases {
+				tenantReleases.Insert(release.Release)
+			}
+			for _, release := range requested {
+				if !tenantReleases.Has(release) {
+					api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("release %s not found", release))
+					return
+				}
+			}
+		}
+
+		for _, resource := range requestedResources(r) {
+			owned, err := resource.owned(s.db.WithContext(r.Context()), tenant)
+			if err != nil {
+				log.WithError(err).Errorf("error querying tenant of %s %s", resource.kind, resource.name)
+				api.RespondWithError(http.StatusInternalServerError, w, "error querying tenant of "+resource.kind)
+				return
+			}
+			if !owned {
+				api.RespondWithError(http.StatusNotFound, w, fmt.Sprintf("%s %s not found", resource.kind, resource.name))
+				return
+			}
+		}
+
+		h.ServeHTTP(w, r.WithContext(api.WithTenant(r.Context(), tenant)))
+	}
+	return http.HandlerFunc(fn)
+}
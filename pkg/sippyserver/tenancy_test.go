@@ -0,0 +1,63 @@
+package sippyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/api"
+)
+
+func TestRequestTenant(t *testing.T) {
+	s := &Server{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs?release=4.16", nil)
+	assert.Empty(t, s.requestTenant(req))
+
+	req.Header.Set(api.TenantHeader, "okd")
+	assert.Equal(t, "okd", s.requestTenant(req))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/jobs?release=4.16&tenant=ocp", nil)
+	req.Header.Set(api.TenantHeader, "okd")
+	assert.Equal(t, "ocp", s.requestTenant(req))
+
+	s.SetTenant("hcp")
+	assert.Equal(t, "hcp", s.requestTenant(req))
+}
+
+func TestTenantHandlerWithoutTenant(t *testing.T) {
+	var tenant string
+	handler := (&Server{}).tenantHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = api.Tenant(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/jobs?release=4.16", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, tenant)
+}
+
+func TestRequestedResources(t *testing.T) {
+	resources := func(target string) []string {
+		var names []string
+		for _, resource := range requestedResources(httptest.NewRequest(http.MethodGet, target, nil)) {
+			names = append(names, resource.kind+" "+resource.name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"job run 1234"}, resources("/api/jobs/runs/retest_recommendation?prow_job_run_id=1234"))
+	assert.Empty(t, resources("/api/jobs/runs/risk_analysis?prow_job_run_id=abc"))
+	assert.Equal(t, []string{"job 42"}, resources("/api/jobs/42/history?days=7"))
+	assert.Empty(t, resources("/api/jobs/runs/tests"))
+	assert.Equal(t, []string{"job periodic-ci-e2e"}, resources("/api/jobs/history?job=periodic-ci-e2e"))
+	assert.Equal(t, []string{"payload 4.16.0-0.nightly-2024-03-04-090000"},
+		resources("/api/releases/tags/4.16.0-0.nightly-2024-03-04-090000/changelog"))
+	assert.Equal(t, []string{"payload 4.16.0-0.nightly-2024-03-04-090000"},
+		resources("/api/payloads/gate?release=4.16&payload=4.16.0-0.nightly-2024-03-04-090000"))
+	assert.Empty(t, resources("/api/releases/tags/events?release=4.16"))
+	assert.Empty(t, resources("/api/tests?release=4.16"))
+}
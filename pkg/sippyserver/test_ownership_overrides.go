@@ -0,0 +1,106 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+// maxTestOwnershipOverrideBytes caps the size of a test ownership override request.
+const maxTestOwnershipOverrideBytes = 64 * 1024
+
+// jsonTestOwnershipOverrides lists the overrides of the test mapping on GET, and creates or replaces the override of a
+// test from a POSTed TestOwnershipOverrideRequest.
+func (s *Server) jsonTestOwnershipOverrides(w http.ResponseWriter, req *http.Request) {
+	dbc := s.db.WithContext(req.Context())
+	switch req.Method {
+	case http.MethodGet:
+		overrides, err := api.ListTestOwnershipOverridesFromDB(dbc)
+		if err != nil {
+			log.WithError(err).Error("error querying test ownership overrides")
+			api.RespondWithError(http.StatusInternalServerError, w, "error querying test ownership overrides")
+			return
+		}
+		api.RespondWithJSON(http.StatusOK, w, overrides)
+	case http.MethodPost:
+		user := auditUser(req)
+		if user == "anonymous" {
+			api.RespondWithError(http.StatusForbidden, w, "test ownership can only be overridden by an authenticated user")
+			return
+		}
+		overrideReq := apitype.TestOwnershipOverrideRequest{}
+		if err := json.NewDecoder(io.LimitReader(req.Body, maxTestOwnershipOverrideBytes)).Decode(&overrideReq); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't decode test ownership override: "+err.Error())
+			return
+		}
+		override, err := api.NewTestOwnershipOverride(user, overrideReq)
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, err.Error())
+			return
+		}
+
+		replaced, err := api.SetTestOwnershipOverride(dbc, override)
+		if err != nil {
+			api.RespondWithProblemOrError(w, err, "saving test ownership override")
+			return
+		}
+		status := http.StatusCreated
+		if replaced != nil {
+			setAuditBefore(req, replaced)
+			status = http.StatusOK
+		}
+		setAuditAfter(req, override)
+		api.RespondWithJSON(status, w, override)
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w,
+			"test ownership overrides are listed with GET, or created with POST")
+	}
+}
+
+// jsonTestOwnershipOverride gets, or deletes, the override with the ID in the path,
+// /api/tests/ownership/overrides/<id>.
+func (s *Server) jsonTestOwnershipOverride(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(req.URL.Path, "/api/tests/ownership/overrides/"), 10, 64)
+	if err != nil {
+		api.RespondWithError(http.StatusNotFound, w, "test ownership overrides are at /api/tests/ownership/overrides/<id>")
+		return
+	}
+
+	dbc := s.db.WithContext(req.Context())
+	override, err := api.GetTestOwnershipOverrideFromDB(dbc, uint(id))
+	if err != nil {
+		log.WithError(err).Error("error querying test ownership override")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test ownership override")
+		return
+	}
+	if override == nil {
+		api.RespondWithError(http.StatusNotFound, w, "no test ownership override "+strconv.FormatUint(id, 10))
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		api.RespondWithJSON(http.StatusOK, w, override)
+	case http.MethodDelete:
+		if auditUser(req) == "anonymous" {
+			api.RespondWithError(http.StatusForbidden, w, "test ownership overrides can only be deleted by an authenticated user")
+			return
+		}
+		if err := api.DeleteTestOwnershipOverride(dbc, override); err != nil {
+			api.RespondWithProblemOrError(w, err, "deleting test ownership override")
+			return
+		}
+		setAuditBefore(req, override)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w,
+			"test ownership overrides are fetched with GET, or deleted with DELETE")
+	}
+}
@@ -0,0 +1,105 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+// maxTestQuarantineBytes caps the size of a test quarantine request.
+const maxTestQuarantineBytes = 64 * 1024
+
+// jsonTestQuarantines lists the active quarantines on GET, or all of them with expired=true, and quarantines a test from
+// a POSTed TestQuarantineRequest, replacing its quarantine if it has one.
+func (s *Server) jsonTestQuarantines(w http.ResponseWriter, req *http.Request) {
+	dbc := s.db.WithContext(req.Context())
+	switch req.Method {
+	case http.MethodGet:
+		includeExpired := req.URL.Query().Get("expired") == "true"
+		quarantines, err := api.ListTestQuarantinesFromDB(dbc, includeExpired, s.GetReportEnd())
+		if err != nil {
+			log.WithError(err).Error("error querying test quarantines")
+			api.RespondWithError(http.StatusInternalServerError, w, "error querying test quarantines")
+			return
+		}
+		api.RespondWithJSON(http.StatusOK, w, quarantines)
+	case http.MethodPost:
+		user := auditUser(req)
+		if user == "anonymous" {
+			api.RespondWithError(http.StatusForbidden, w, "tests can only be quarantined by an authenticated user")
+			return
+		}
+		quarantineReq := apitype.TestQuarantineRequest{}
+		if err := json.NewDecoder(io.LimitReader(req.Body, maxTestQuarantineBytes)).Decode(&quarantineReq); err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, "couldn't decode test quarantine: "+err.Error())
+			return
+		}
+		quarantine, err := api.NewTestQuarantine(user, quarantineReq, s.GetReportEnd())
+		if err != nil {
+			api.RespondWithError(http.StatusBadRequest, w, err.Error())
+			return
+		}
+
+		replaced, err := api.SetTestQuarantine(dbc, quarantine)
+		if err != nil {
+			api.RespondWithProblemOrError(w, err, "saving test quarantine")
+			return
+		}
+		status := http.StatusCreated
+		if replaced != nil {
+			setAuditBefore(req, replaced)
+			status = http.StatusOK
+		}
+		setAuditAfter(req, quarantine)
+		api.RespondWithJSON(status, w, quarantine)
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w, "test quarantines are listed with GET, or created with POST")
+	}
+}
+
+// jsonTestQuarantine gets, or lifts, the quarantine with the ID in the path, /api/tests/quarantines/<id>.
+func (s *Server) jsonTestQuarantine(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(req.URL.Path, "/api/tests/quarantines/"), 10, 64)
+	if err != nil {
+		api.RespondWithError(http.StatusNotFound, w, "test quarantines are at /api/tests/quarantines/<id>")
+		return
+	}
+
+	dbc := s.db.WithContext(req.Context())
+	quarantine, err := api.GetTestQuarantineFromDB(dbc, uint(id))
+	if err != nil {
+		log.WithError(err).Error("error querying test quarantine")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying test quarantine")
+		return
+	}
+	if quarantine == nil {
+		api.RespondWithError(http.StatusNotFound, w, "no test quarantine "+strconv.FormatUint(id, 10))
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		api.RespondWithJSON(http.StatusOK, w, quarantine)
+	case http.MethodDelete:
+		if auditUser(req) == "anonymous" {
+			api.RespondWithError(http.StatusForbidden, w, "test quarantines can only be lifted by an authenticated user")
+			return
+		}
+		if err := api.DeleteTestQuarantine(dbc, quarantine); err != nil {
+			api.RespondWithProblemOrError(w, err, "lifting test quarantine")
+			return
+		}
+		setAuditBefore(req, quarantine)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondWithError(http.StatusMethodNotAllowed, w,
+			"test quarantines are fetched with GET, or lifted with DELETE")
+	}
+}
@@ -0,0 +1,61 @@
+package sippyserver
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/testidentification"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// jsonVariantsMetadata describes every variant known to the variant manager or found in the database. When a release
+// is given, job counts are limited to it and the release's invalid variants are left out.
+func (s *Server) jsonVariantsMetadata(w http.ResponseWriter, req *http.Request) {
+	release := req.URL.Query().Get("release")
+
+	jobCounts, err := query.VariantJobCounts(s.db.WithContext(req.Context()), release)
+	if err != nil {
+		log.WithError(err).Error("error querying variant job counts")
+		api.RespondWithError(http.StatusInternalServerError, w, "error querying variant job counts: "+err.Error())
+		return
+	}
+
+	var invalidVariants []string
+	if release != "" {
+		invalidVariants = s.variantManager.InvalidVariants(release)
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, variantsMetadata(s.variantManager, jobCounts, invalidVariants))
+}
+
+func variantsMetadata(vm testidentification.VariantManager, jobCounts map[string]int, invalidVariants []string) apitype.VariantsMetadata {
+	names := sets.NewString(vm.AllVariants().List()...).
+		Union(sets.StringKeySet(jobCounts)).
+		Delete(invalidVariants...)
+	platforms := vm.AllPlatforms()
+
+	result := apitype.VariantsMetadata{
+		Variants:   make([]apitype.VariantMetadata, 0, names.Len()),
+		Dimensions: map[string][]string{},
+	}
+	for _, name := range names.List() {
+		description := vm.DescribeVariant(name)
+		result.Variants = append(result.Variants, apitype.VariantMetadata{
+			Name:           name,
+			Dimension:      description.Dimension,
+			DimensionValue: description.DimensionValue,
+			Description:    description.Description,
+			Platform:       platforms.Has(name),
+			JobCount:       jobCounts[name],
+		})
+		if description.Dimension != "" {
+			result.Dimensions[description.Dimension] = append(result.Dimensions[description.Dimension], description.DimensionValue)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,32 @@
+package sippyserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+func TestVariantsMetadata(t *testing.T) {
+	vm, err := testidentification.NewConfigVariantManager(&v1.VariantConfig{
+		Variants:     []string{"aws", "gcp", "sdn"},
+		Platforms:    []string{"aws", "gcp"},
+		Dimensions:   map[string]string{"aws": "Platform", "gcp": "Platform", "sdn": "Network"},
+		Descriptions: map[string]string{"aws": "Jobs running on AWS"},
+	})
+	assert.NoError(t, err)
+
+	result := variantsMetadata(vm, map[string]int{"aws": 3, "legacy": 1}, []string{"sdn"})
+
+	assert.Equal(t, apitype.VariantsMetadata{
+		Variants: []apitype.VariantMetadata{
+			{Name: "aws", Dimension: "Platform", DimensionValue: "aws", Description: "Jobs running on AWS", Platform: true, JobCount: 3},
+			{Name: "gcp", Dimension: "Platform", DimensionValue: "gcp", Platform: true},
+			{Name: "legacy", JobCount: 1},
+		},
+		Dimensions: map[string][]string{"Platform": {"aws", "gcp"}},
+	}, result)
+}
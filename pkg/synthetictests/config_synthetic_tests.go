@@ -0,0 +1,152 @@
+package synthetictests
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1config "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/apis/junit"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+// ArtifactMatcher is implemented by SyntheticTestManagers that need to know which artifacts a job run produced. The
+// loader sets RawJobRunResult.Artifacts to the artifact paths matching any of the regexps.
+type ArtifactMatcher interface {
+	ArtifactRegexps() []*regexp.Regexp
+}
+
+type configSyntheticTest struct {
+	name              string
+	jobRegexp         *regexp.Regexp
+	config            v1config.SyntheticTestConfig
+	requiredArtifact  *regexp.Regexp
+	forbiddenArtifact *regexp.Regexp
+}
+
+// configSyntheticManager adds synthetic tests defined in the sippy config to those of another manager.
+type configSyntheticManager struct {
+	SyntheticTestManager
+	tests []configSyntheticTest
+}
+
+// NewConfigSyntheticTestManager validates the configured tests, and returns a manager that adds them to the synthetic
+// tests created by manager.
+func NewConfigSyntheticTestManager(manager SyntheticTestManager, tests []v1config.SyntheticTestConfig) (SyntheticTestManager, error) {
+	if len(tests) == 0 {
+		return manager, nil
+	}
+
+	m := configSyntheticManager{SyntheticTestManager: manager}
+	for _, test := range tests {
+		if test.Name == "" {
+			return nil, fmt.Errorf("synthetic test is missing a name")
+		}
+		if test.MaxDuration == 0 && test.RequiredArtifact == "" && test.ForbiddenArtifact == "" {
+			return nil, fmt.Errorf("synthetic test %q has no conditions", test.Name)
+		}
+
+		compiled := configSyntheticTest{name: test.Name, config: test}
+		var err error
+		if compiled.jobRegexp, err = compileOptional(test.JobRegexp); err != nil {
+			return nil, fmt.Errorf("synthetic test %q has an invalid jobRegexp: %w", test.Name, err)
+		}
+		if compiled.requiredArtifact, err = compileOptional(test.RequiredArtifact); err != nil {
+			return nil, fmt.Errorf("synthetic test %q has an invalid requiredArtifact: %w", test.Name, err)
+		}
+		if compiled.forbiddenArtifact, err = compileOptional(test.ForbiddenArtifact); err != nil {
+			return nil, fmt.Errorf("synthetic test %q has an invalid forbiddenArtifact: %w", test.Name, err)
+		}
+		m.tests = append(m.tests, compiled)
+	}
+
+	return m, nil
+}
+
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+func (m configSyntheticManager) ArtifactRegexps() []*regexp.Regexp {
+	var regexps []*regexp.Regexp
+	if am, ok := m.SyntheticTestManager.(ArtifactMatcher); ok {
+		regexps = append(regexps, am.ArtifactRegexps()...)
+	}
+	for _, test := range m.tests {
+		for _, re := range []*regexp.Regexp{test.requiredArtifact, test.forbiddenArtifact} {
+			if re != nil {
+				regexps = append(regexps, re)
+			}
+		}
+	}
+	return regexps
+}
+
+func (m configSyntheticManager) CreateSyntheticTests(jrr *sippyprocessingv1.RawJobRunResult) *junit.TestSuite {
+	suite := m.SyntheticTestManager.CreateSyntheticTests(jrr)
+
+	for _, test := range m.tests {
+		if test.jobRegexp != nil && !test.jobRegexp.MatchString(jrr.Job) {
+			continue
+		}
+
+		problems := test.evaluate(jrr)
+		if problems == nil {
+			// Still running, or nothing to evaluate
+			continue
+		}
+
+		if len(problems) == 0 {
+			jrr.TestResults = append(jrr.TestResults, sippyprocessingv1.RawJobRunTestResult{
+				Name:   test.name,
+				Status: sippyprocessingv1.TestStatusSuccess,
+			})
+			suite.TestCases = append(suite.TestCases, &junit.TestCase{Name: test.name})
+		} else {
+			jrr.TestFailures++
+			jrr.FailedTestNames = append(jrr.FailedTestNames, test.name)
+			suite.TestCases = append(suite.TestCases, &junit.TestCase{
+				Name: test.name,
+				FailureOutput: &junit.FailureOutput{
+					Output: fmt.Sprintf("Synthetic test %q failed: %s", test.name, strings.Join(problems, "; ")),
+				},
+			})
+			suite.NumFailed++
+		}
+		suite.NumTests++
+	}
+
+	return suite
+}
+
+// evaluate returns why the job run fails the test, an empty slice when it passes, or nil when the job run can't be
+// evaluated because it hasn't finished.
+func (t configSyntheticTest) evaluate(jrr *sippyprocessingv1.RawJobRunResult) []string {
+	if !jrr.Succeeded && !jrr.Failed {
+		return nil
+	}
+
+	problems := []string{}
+	if t.config.MaxDuration > 0 && jrr.Duration > t.config.MaxDuration {
+		problems = append(problems, fmt.Sprintf("job run took %s, exceeding %s", jrr.Duration, t.config.MaxDuration))
+	}
+	if t.requiredArtifact != nil && !anyMatch(t.requiredArtifact, jrr.Artifacts) {
+		problems = append(problems, fmt.Sprintf("no artifact matching %q", t.config.RequiredArtifact))
+	}
+	if t.forbiddenArtifact != nil && anyMatch(t.forbiddenArtifact, jrr.Artifacts) {
+		problems = append(problems, fmt.Sprintf("found artifact matching %q", t.config.ForbiddenArtifact))
+	}
+	return problems
+}
+
+func anyMatch(re *regexp.Regexp, paths []string) bool {
+	for _, path := range paths {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
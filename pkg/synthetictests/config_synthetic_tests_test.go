@@ -0,0 +1,129 @@
+package synthetictests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1config "github.com/openshift/sippy/pkg/apis/config/v1"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func TestConfigSyntheticTests(t *testing.T) {
+	tests := []v1config.SyntheticTestConfig{
+		{
+			Name:        "job run should finish within 2h",
+			JobRegexp:   "-e2e-",
+			MaxDuration: 2 * time.Hour,
+		},
+		{
+			Name:              "job run should gather must-gather without a kernel panic",
+			RequiredArtifact:  `must-gather\.tar$`,
+			ForbiddenArtifact: `kernel-panic`,
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		jrr             v1.RawJobRunResult
+		expectedPassed  []string
+		expectedFailed  []string
+		expectedSkipped bool
+	}{
+		{
+			name: "all conditions met",
+			jrr: v1.RawJobRunResult{
+				Job:       "periodic-e2e-aws",
+				Succeeded: true,
+				Duration:  time.Hour,
+				Artifacts: []string{"artifacts/must-gather.tar"},
+			},
+			expectedPassed: []string{tests[0].Name, tests[1].Name},
+		},
+		{
+			name: "slow run with a forbidden artifact",
+			jrr: v1.RawJobRunResult{
+				Job:       "periodic-e2e-aws",
+				Failed:    true,
+				Duration:  3 * time.Hour,
+				Artifacts: []string{"artifacts/must-gather.tar", "artifacts/nodes/kernel-panic.log"},
+			},
+			expectedFailed: []string{tests[0].Name, tests[1].Name},
+		},
+		{
+			name: "job not matching the regexp only gets unfiltered tests",
+			jrr: v1.RawJobRunResult{
+				Job:       "periodic-upgrade-aws",
+				Succeeded: true,
+				Duration:  3 * time.Hour,
+			},
+			expectedFailed: []string{tests[1].Name},
+		},
+		{
+			name: "running jobs are not evaluated",
+			jrr: v1.RawJobRunResult{
+				Job: "periodic-e2e-aws",
+			},
+			expectedSkipped: true,
+		},
+	}
+
+	manager, err := NewConfigSyntheticTestManager(NewEmptySyntheticTestManager(), tests)
+	require.NoError(t, err)
+	assert.Len(t, manager.(ArtifactMatcher).ArtifactRegexps(), 2)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jrr := tc.jrr
+			suite := manager.CreateSyntheticTests(&jrr)
+
+			if tc.expectedSkipped {
+				assert.Empty(t, suite.TestCases)
+				return
+			}
+
+			var passed, failed []string
+			for _, testCase := range suite.TestCases {
+				if testCase.FailureOutput != nil {
+					failed = append(failed, testCase.Name)
+				} else {
+					passed = append(passed, testCase.Name)
+				}
+			}
+			assert.Equal(t, tc.expectedPassed, passed)
+			assert.Equal(t, tc.expectedFailed, failed)
+			assert.Equal(t, tc.expectedFailed, jrr.FailedTestNames)
+			assert.Equal(t, len(tc.expectedFailed), jrr.TestFailures)
+			assert.Equal(t, uint(len(tc.expectedPassed)+len(tc.expectedFailed)), suite.NumTests)
+		})
+	}
+}
+
+func TestConfigSyntheticTestsValidation(t *testing.T) {
+	testCases := []struct {
+		name string
+		test v1config.SyntheticTestConfig
+	}{
+		{
+			name: "missing name",
+			test: v1config.SyntheticTestConfig{MaxDuration: time.Hour},
+		},
+		{
+			name: "no conditions",
+			test: v1config.SyntheticTestConfig{Name: "test"},
+		},
+		{
+			name: "invalid regexp",
+			test: v1config.SyntheticTestConfig{Name: "test", RequiredArtifact: "("},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewConfigSyntheticTestManager(NewEmptySyntheticTestManager(), []v1config.SyntheticTestConfig{tc.test})
+			assert.Error(t, err)
+		})
+	}
+}
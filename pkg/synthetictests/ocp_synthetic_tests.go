@@ -47,6 +47,9 @@ func (openshiftSyntheticManager) CreateSyntheticTests(jrr *sippyprocessingv1.Raw
 	}
 	installFailed := jrr.Failed && jrr.InstallStatus != testidentification.Success
 	installSucceeded := jrr.Succeeded || jrr.InstallStatus == testidentification.Success
+	// a hosted control plane's operators run in the management cluster, so a failure creating the hosted cluster is
+	// an install failure even without operator results.
+	hostedInstallFailed := jrr.HostedControlPlane && jrr.InstallStatus == testidentification.Failure
 
 	switch {
 	case !hasFinalOperatorResults:
@@ -70,6 +73,9 @@ func (openshiftSyntheticManager) CreateSyntheticTests(jrr *sippyprocessingv1.Raw
 			}
 		}
 
+	case hostedInstallFailed && !hasFinalOperatorResults:
+		syntheticTests[testidentification.InstallTestName].fail = 1
+
 	case !hasFinalOperatorResults:
 		// if we don't have any operator results, then don't count this an install one way or the other.  This was an infra failure
 
@@ -104,7 +110,7 @@ func (openshiftSyntheticManager) CreateSyntheticTests(jrr *sippyprocessingv1.Raw
 
 	// set the infra status
 	switch {
-	case installFailed && !hasFinalOperatorResults:
+	case installFailed && !hasFinalOperatorResults && !hostedInstallFailed:
 		// we only count failures as infra if we have no operator results.  If we got any operator working, then CI infra was working.
 		syntheticTests[testidentification.InfrastructureTestName].fail = 1
 
@@ -120,7 +126,10 @@ func (openshiftSyntheticManager) CreateSyntheticTests(jrr *sippyprocessingv1.Raw
 	// do nothing
 
 	default:
-		if jrr.UpgradeForOperatorsStatus == testidentification.Success && jrr.UpgradeForMachineConfigPoolsStatus == testidentification.Success {
+		// hosted control planes have no machine config pools, their node pools upgrade separately
+		machineConfigPoolsUpgraded := jrr.UpgradeForMachineConfigPoolsStatus == testidentification.Success ||
+			(jrr.HostedControlPlane && jrr.UpgradeForMachineConfigPoolsStatus == "")
+		if jrr.UpgradeForOperatorsStatus == testidentification.Success && machineConfigPoolsUpgraded {
 			syntheticTests[testidentification.UpgradeTestName].pass = 1
 			// if the test succeeded, then the operator install tests should all be passes
 			for _, operatorState := range jrr.FinalOperatorStates {
@@ -213,7 +222,7 @@ func jobRunStatus(result *sippyprocessingv1.RawJobRunResult) sippyprocessingv1.J
 	}
 
 	if result.InstallStatus == failure {
-		if len(result.FinalOperatorStates) == 0 {
+		if len(result.FinalOperatorStates) == 0 && !result.HostedControlPlane {
 			return sippyprocessingv1.JobInfrastructureFailure
 		}
 		return sippyprocessingv1.JobInstallFailure
@@ -76,6 +76,81 @@ func TestSyntheticSippyTestGeneration(t *testing.T) {
 	}
 }
 
+func TestSyntheticTestsHostedControlPlane(t *testing.T) {
+	testCases := []struct {
+		name        string
+		hosted      bool
+		jrr         v1.RawJobRunResult
+		passed      []string
+		failed      []string
+		unreported  []string
+		finalResult v1.JobOverallResult
+	}{
+		{
+			name:        "failed hosted cluster creation is an install failure",
+			hosted:      true,
+			jrr:         v1.RawJobRunResult{Failed: true, InstallStatus: testidentification.Failure},
+			passed:      []string{testidentification.InfrastructureTestName},
+			failed:      []string{testidentification.InstallTestName},
+			finalResult: v1.JobInstallFailure,
+		},
+		{
+			name:        "failed install without operator results is an infrastructure failure",
+			jrr:         v1.RawJobRunResult{Failed: true, InstallStatus: testidentification.Failure},
+			failed:      []string{testidentification.InfrastructureTestName},
+			unreported:  []string{testidentification.InstallTestName},
+			finalResult: v1.JobInfrastructureFailure,
+		},
+		{
+			name:   "hosted upgrade doesn't wait for machine config pools",
+			hosted: true,
+			jrr: v1.RawJobRunResult{Succeeded: true, UpgradeStarted: true,
+				UpgradeForOperatorsStatus: testidentification.Success},
+			passed:      []string{testidentification.UpgradeTestName},
+			finalResult: v1.JobSucceeded,
+		},
+		{
+			name: "upgrade waits for machine config pools",
+			jrr: v1.RawJobRunResult{Failed: true, InstallStatus: testidentification.Success, UpgradeStarted: true,
+				UpgradeForOperatorsStatus: testidentification.Success},
+			failed:      []string{testidentification.UpgradeTestName},
+			finalResult: v1.JobUnknown,
+		},
+		{
+			name:   "hosted upgrade fails with its machine config pools",
+			hosted: true,
+			jrr: v1.RawJobRunResult{Failed: true, InstallStatus: testidentification.Success, UpgradeStarted: true,
+				UpgradeForOperatorsStatus:          testidentification.Success,
+				UpgradeForMachineConfigPoolsStatus: testidentification.Failure},
+			failed:      []string{testidentification.UpgradeTestName},
+			finalResult: v1.JobUpgradeFailure,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jrr := tc.jrr
+			jrr.HostedControlPlane = tc.hosted
+			suite := NewOpenshiftSyntheticTestManager().CreateSyntheticTests(&jrr)
+
+			results := map[string]bool{}
+			for _, test := range suite.TestCases {
+				results[test.Name] = test.FailureOutput == nil
+			}
+			for _, name := range tc.passed {
+				assert.True(t, results[name], "expected %s to pass", name)
+			}
+			for _, name := range tc.failed {
+				passed, ok := results[name]
+				assert.True(t, ok && !passed, "expected %s to fail", name)
+			}
+			for _, name := range tc.unreported {
+				assert.NotContains(t, results, name)
+			}
+			assert.Equal(t, tc.finalResult, jrr.OverallResult)
+		})
+	}
+}
+
 func assertJobRunTestResult(t *testing.T, rjr v1.RawJobResult, expectedTestResults []v1.RawJobRunTestResult) {
 	for _, etr := range expectedTestResults {
 		var found bool
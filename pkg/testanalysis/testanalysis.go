@@ -0,0 +1,60 @@
+// Package testanalysis maintains the summary tables read by the test analysis endpoints. The 14 day test analysis
+// materialized views are keyed by test ID, and the by job view has to be joined with jobs to filter by variant, so
+// reading them for a single test scans most of a view. The summary tables hold the same counts, with the job variants
+// copied in, indexed by release and test name.
+package testanalysis
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const byJobSummary = `
+INSERT INTO test_analysis_by_job_days (release, test_name, date, test_id, job_name, variants, runs, passes, flakes, failures)
+SELECT m.release, m.test_name, m.date, m.test_id, m.job_name, prow_jobs.variants, m.runs, m.passes, m.flakes, m.failures
+FROM prow_test_analysis_by_job_14d_matview m
+JOIN prow_jobs ON prow_jobs.name = m.job_name`
+
+const byVariantSummary = `
+INSERT INTO test_analysis_by_variant_days (release, test_name, date, test_id, variant, runs, passes, flakes, failures)
+SELECT release, test_name, date, test_id, variant, runs, passes, flakes, failures
+FROM prow_test_analysis_by_variant_14d_matview`
+
+// Refresh rebuilds the summary tables from the test analysis materialized views, which must be refreshed first. The
+// tables are replaced in one transaction, so the endpoints read the previous summaries until it commits.
+func Refresh(dbc *db.DB) error {
+	start := time.Now()
+	var byJob, byVariant int64
+	err := dbc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.TestAnalysisByJobDay{}, &models.TestAnalysisByVariantDay{}} {
+			if res := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(model); res.Error != nil {
+				return res.Error
+			}
+		}
+
+		res := tx.Exec(byJobSummary)
+		if res.Error != nil {
+			return res.Error
+		}
+		byJob = res.RowsAffected
+
+		res = tx.Exec(byVariantSummary)
+		if res.Error != nil {
+			return res.Error
+		}
+		byVariant = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.WithField("elapsed", time.Since(start)).
+		Infof("refreshed test analysis summaries with %d job and %d variant rows", byJob, byVariant)
+	return nil
+}
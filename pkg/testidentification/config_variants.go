@@ -0,0 +1,144 @@
+package testidentification
+
+import (
+	"fmt"
+	"regexp"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+type compiledVariantRule struct {
+	regexp   *regexp.Regexp
+	variants []string
+	terminal bool
+}
+
+// configVariants identifies variants using rules loaded from a VariantConfig rather than compiled-in regexes.
+type configVariants struct {
+	allVariants  sets.String
+	allPlatforms sets.String
+	neverStable  sets.String
+	dimensions   map[string]variantDimension
+	descriptions map[string]string
+	rules        []compiledVariantRule
+	releaseRules map[string][]compiledVariantRule
+}
+
+// NewConfigVariantManager validates the config and returns a VariantManager driven by its rules.
+func NewConfigVariantManager(config *v1.VariantConfig) (VariantManager, error) {
+	v := &configVariants{
+		allVariants:  sets.NewString(config.Variants...),
+		allPlatforms: sets.NewString(config.Platforms...),
+		neverStable:  sets.NewString(config.NeverStableJobs...),
+		dimensions:   map[string]variantDimension{},
+		descriptions: config.Descriptions,
+		releaseRules: map[string][]compiledVariantRule{},
+	}
+	if len(config.NeverStableJobs) > 0 {
+		v.allVariants.Insert(NeverStable)
+	}
+
+	if unknown := v.allPlatforms.Difference(v.allVariants); unknown.Len() > 0 {
+		return nil, fmt.Errorf("platforms %v are not listed in variants", unknown.List())
+	}
+	for variant, dimension := range config.Dimensions {
+		if !v.allVariants.Has(variant) {
+			return nil, fmt.Errorf("dimension %s assigned to unknown variant %q", dimension, variant)
+		}
+		v.dimensions[variant] = variantDimension{key: dimension, value: variant}
+	}
+	for variant := range config.Descriptions {
+		if !v.allVariants.Has(variant) {
+			return nil, fmt.Errorf("description given for unknown variant %q", variant)
+		}
+	}
+
+	var err error
+	if v.rules, err = v.compileRules(config.Rules); err != nil {
+		return nil, err
+	}
+	for release, rules := range config.ReleaseOverrides {
+		if v.releaseRules[release], err = v.compileRules(rules); err != nil {
+			return nil, fmt.Errorf("release %s: %w", release, err)
+		}
+	}
+
+	return v, nil
+}
+
+func (v *configVariants) compileRules(rules []v1.VariantRule) ([]compiledVariantRule, error) {
+	compiled := make([]compiledVariantRule, 0, len(rules))
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Regexp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variant rule regexp %q: %w", rule.Regexp, err)
+		}
+		for _, variant := range rule.Variants {
+			if !v.allVariants.Has(variant) {
+				return nil, fmt.Errorf("variant rule %q assigns unknown variant %q", rule.Regexp, variant)
+			}
+		}
+		compiled = append(compiled, compiledVariantRule{
+			regexp:   re,
+			variants: rule.Variants,
+			terminal: rule.Terminal,
+		})
+	}
+	return compiled, nil
+}
+
+func (v *configVariants) AllVariants() sets.String {
+	return v.allVariants
+}
+
+func (v *configVariants) AllPlatforms() sets.String {
+	return v.allPlatforms
+}
+
+func (v *configVariants) IdentifyVariants(jobName, release string, _ models.ClusterData) []string {
+	if v.IsJobNeverStable(jobName) {
+		return []string{NeverStable}
+	}
+
+	rules, ok := v.releaseRules[release]
+	if !ok {
+		rules = v.rules
+	}
+
+	variants := []string{}
+	seen := sets.NewString()
+	for _, rule := range rules {
+		if !rule.regexp.MatchString(jobName) {
+			continue
+		}
+		if rule.terminal {
+			return append([]string{}, rule.variants...)
+		}
+		for _, variant := range rule.variants {
+			if !seen.Has(variant) {
+				seen.Insert(variant)
+				variants = append(variants, variant)
+			}
+		}
+	}
+
+	return variants
+}
+
+func (v *configVariants) IdentifyVariantDimensions(jobName, release string, jobVariants models.ClusterData) models.VariantDimensions {
+	return variantsToDimensions(v.IdentifyVariants(jobName, release, jobVariants), v.dimensions)
+}
+
+func (v *configVariants) IsJobNeverStable(jobName string) bool {
+	return v.neverStable.Has(jobName)
+}
+
+func (v *configVariants) InvalidVariants(release string) []string {
+	return nil
+}
+
+func (v *configVariants) DescribeVariant(variant string) VariantDescription {
+	return v.dimensions[variant].describe(v.descriptions[variant])
+}
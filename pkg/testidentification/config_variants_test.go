@@ -0,0 +1,128 @@
+package testidentification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func Test_configVariants_IdentifyVariants(t *testing.T) {
+	config := &v1.VariantConfig{
+		Variants:        []string{"aggregated", "aws", "gcp", "ovn", "sdn", "upgrade"},
+		Platforms:       []string{"aws", "gcp"},
+		Dimensions:      map[string]string{"aws": "Platform", "gcp": "Platform", "ovn": "Network", "sdn": "Network"},
+		NeverStableJobs: []string{"periodic-ci-openshift-release-master-ci-4.14-e2e-gcp-sdn-serial"},
+		Rules: []v1.VariantRule{
+			{Regexp: `(?i)aggregated-`, Variants: []string{"aggregated"}, Terminal: true},
+			{Regexp: `(?i)-aws`, Variants: []string{"aws"}},
+			{Regexp: `(?i)-gcp`, Variants: []string{"gcp"}},
+			{Regexp: `(?i)-ovn`, Variants: []string{"ovn"}},
+			{Regexp: `(?i)-sdn`, Variants: []string{"sdn"}},
+			{Regexp: `(?i)-upgrade`, Variants: []string{"upgrade"}},
+		},
+		ReleaseOverrides: map[string][]v1.VariantRule{
+			"4.17": {
+				{Regexp: `(?i)-aws`, Variants: []string{"aws"}},
+				{Regexp: `.*`, Variants: []string{"ovn"}},
+			},
+		},
+	}
+	vm, err := NewConfigVariantManager(config)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		release string
+		want    []string
+	}{
+		{
+			name:    "periodic-ci-openshift-release-master-ci-4.14-e2e-aws-ovn-upgrade",
+			release: "4.14",
+			want:    []string{"aws", "ovn", "upgrade"},
+		},
+		{
+			name:    "aggregated-aws-ovn-upgrade-4.14-micro-release-openshift-release-analysis-aggregator",
+			release: "4.14",
+			want:    []string{"aggregated"},
+		},
+		{
+			name:    "periodic-ci-openshift-release-master-ci-4.14-e2e-gcp-sdn-serial",
+			release: "4.14",
+			want:    []string{NeverStable},
+		},
+		{
+			name:    "periodic-ci-openshift-release-master-ci-4.17-e2e-aws-sdn",
+			release: "4.17",
+			want:    []string{"aws", "ovn"},
+		},
+		{
+			name:    "periodic-ci-openshift-release-master-ci-4.14-e2e-metal",
+			release: "4.14",
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, vm.IdentifyVariants(tt.name, tt.release, models.ClusterData{}))
+		})
+	}
+
+	assert.Equal(t, models.VariantDimensions{"Platform": "aws", "Network": "ovn"},
+		vm.IdentifyVariantDimensions("periodic-ci-openshift-release-master-ci-4.14-e2e-aws-ovn-upgrade", "4.14", models.ClusterData{}))
+
+	assert.True(t, vm.AllVariants().Has(NeverStable))
+	assert.Equal(t, []string{"aws", "gcp"}, vm.AllPlatforms().List())
+}
+
+func TestNewConfigVariantManagerValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config v1.VariantConfig
+	}{
+		{
+			name: "unknown variant in rule",
+			config: v1.VariantConfig{
+				Variants: []string{"aws"},
+				Rules:    []v1.VariantRule{{Regexp: `-gcp`, Variants: []string{"gcp"}}},
+			},
+		},
+		{
+			name: "invalid regexp",
+			config: v1.VariantConfig{
+				Variants: []string{"aws"},
+				Rules:    []v1.VariantRule{{Regexp: `-aws(`, Variants: []string{"aws"}}},
+			},
+		},
+		{
+			name: "platform not a variant",
+			config: v1.VariantConfig{
+				Variants:  []string{"aws"},
+				Platforms: []string{"gcp"},
+			},
+		},
+		{
+			name: "dimension for unknown variant",
+			config: v1.VariantConfig{
+				Variants:   []string{"aws"},
+				Dimensions: map[string]string{"gcp": "Platform"},
+			},
+		},
+		{
+			name: "unknown variant in release override",
+			config: v1.VariantConfig{
+				Variants:         []string{"aws"},
+				ReleaseOverrides: map[string][]v1.VariantRule{"4.17": {{Regexp: `-gcp`, Variants: []string{"gcp"}}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConfigVariantManager(&tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package testidentification
+
+import (
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// Experimental is the variant of jobs configured as experimental, which default reports leave out.
+const Experimental = models.JobTierExperimental
+
+// experimentalVariants adds the experimental variant to the jobs configured as experimental.
+type experimentalVariants struct {
+	VariantManager
+	experimentalJobs sets.String
+}
+
+// NewExperimentalVariantManager wraps a VariantManager so the given jobs, typically the releases' experimental jobs,
+// also have the experimental variant, and JobTier=experimental, so they're left out of default reports.
+func NewExperimentalVariantManager(vm VariantManager, experimentalJobs []string) VariantManager {
+	if len(experimentalJobs) == 0 {
+		return vm
+	}
+	return experimentalVariants{
+		VariantManager:   vm,
+		experimentalJobs: sets.NewString(experimentalJobs...),
+	}
+}
+
+func (v experimentalVariants) AllVariants() sets.String {
+	return sets.NewString(v.VariantManager.AllVariants().List()...).Insert(Experimental)
+}
+
+func (v experimentalVariants) IdentifyVariants(jobName, release string, jobVariants models.ClusterData) []string {
+	variants := v.VariantManager.IdentifyVariants(jobName, release, jobVariants)
+	if v.experimentalJobs.Has(jobName) {
+		variants = append(variants, Experimental)
+	}
+	return variants
+}
+
+// IdentifyVariantDimensions sets JobTier=experimental for experimental jobs, unless they're never-stable, which
+// already excludes them from everything else.
+func (v experimentalVariants) IdentifyVariantDimensions(jobName, release string, jobVariants models.ClusterData) models.VariantDimensions {
+	dimensions := v.VariantManager.IdentifyVariantDimensions(jobName, release, jobVariants)
+	if v.experimentalJobs.Has(jobName) && dimensions["JobTier"] == "" {
+		if dimensions == nil {
+			dimensions = models.VariantDimensions{}
+		}
+		dimensions["JobTier"] = Experimental
+	}
+	return dimensions
+}
+
+func (v experimentalVariants) DescribeVariant(variant string) VariantDescription {
+	description := v.VariantManager.DescribeVariant(variant)
+	if variant == Experimental && description.Description == "" {
+		return variantDimension{"JobTier", Experimental}.describe("Jobs still being brought up, left out of default reports")
+	}
+	return description
+}
@@ -0,0 +1,44 @@
+package testidentification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestExperimentalVariantManager(t *testing.T) {
+	vm := NewExperimentalVariantManager(NewOpenshiftVariantManager(), []string{
+		"periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-new-feature",
+	})
+
+	tests := []struct {
+		name             string
+		job              string
+		expectedVariants []string
+		expectedTier     string
+	}{
+		{
+			name:             "experimental job",
+			job:              "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn-new-feature",
+			expectedVariants: []string{"aws", "amd64", "ovn", "ha", Experimental},
+			expectedTier:     Experimental,
+		},
+		{
+			name:             "other job",
+			job:              "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn",
+			expectedVariants: []string{"aws", "amd64", "ovn", "ha"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expectedVariants, vm.IdentifyVariants(tc.job, "4.16", models.ClusterData{}))
+			assert.Equal(t, tc.expectedTier, vm.IdentifyVariantDimensions(tc.job, "4.16", models.ClusterData{})["JobTier"])
+		})
+	}
+
+	assert.True(t, vm.AllVariants().Has(Experimental))
+	assert.Equal(t, "JobTier", vm.DescribeVariant(Experimental).Dimension)
+}
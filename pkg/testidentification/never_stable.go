@@ -0,0 +1,51 @@
+package testidentification
+
+import (
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// neverStableVariants adds jobs flagged as never-stable from their pass rates to a VariantManager's curated list.
+type neverStableVariants struct {
+	VariantManager
+	neverStableJobs sets.String
+}
+
+// NewNeverStableVariantManager wraps a VariantManager so the given jobs, typically those flagged by the never-stable
+// loader, are also treated as never-stable.
+func NewNeverStableVariantManager(vm VariantManager, neverStableJobs []string) VariantManager {
+	return neverStableVariants{
+		VariantManager:  vm,
+		neverStableJobs: sets.NewString(neverStableJobs...),
+	}
+}
+
+func (v neverStableVariants) AllVariants() sets.String {
+	return sets.NewString(v.VariantManager.AllVariants().List()...).Insert(NeverStable)
+}
+
+func (v neverStableVariants) IdentifyVariants(jobName, release string, jobVariants models.ClusterData) []string {
+	if v.neverStableJobs.Has(jobName) {
+		return []string{NeverStable}
+	}
+	return v.VariantManager.IdentifyVariants(jobName, release, jobVariants)
+}
+
+func (v neverStableVariants) IdentifyVariantDimensions(jobName, release string, jobVariants models.ClusterData) models.VariantDimensions {
+	if v.neverStableJobs.Has(jobName) {
+		return models.VariantDimensions{"JobTier": NeverStable}
+	}
+	return v.VariantManager.IdentifyVariantDimensions(jobName, release, jobVariants)
+}
+
+func (v neverStableVariants) DescribeVariant(variant string) VariantDescription {
+	description := v.VariantManager.DescribeVariant(variant)
+	if variant == NeverStable && description.Description == "" {
+		return openshiftVariantDimensions[NeverStable].describe(openshiftVariantDescriptions[NeverStable])
+	}
+	return description
+}
+
+func (v neverStableVariants) IsJobNeverStable(jobName string) bool {
+	return v.neverStableJobs.Has(jobName) || v.VariantManager.IsJobNeverStable(jobName)
+}
@@ -22,6 +22,19 @@ func (noVariants) AllPlatforms() sets.String {
 func (v noVariants) IdentifyVariants(jobName, release string, jobVariants models.ClusterData) []string {
 	return []string{}
 }
+
+func (noVariants) IdentifyVariantDimensions(jobName, release string, jobVariants models.ClusterData) models.VariantDimensions {
+	return models.VariantDimensions{}
+}
+
 func (noVariants) IsJobNeverStable(jobName string) bool {
 	return false
 }
+
+func (noVariants) InvalidVariants(release string) []string {
+	return nil
+}
+
+func (noVariants) DescribeVariant(variant string) VariantDescription {
+	return VariantDescription{}
+}
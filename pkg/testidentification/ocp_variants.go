@@ -78,6 +78,8 @@ var (
 	vsphereRegex    = regexp.MustCompile(`(?i)-vsphere`)
 	vsphereUPIRegex = regexp.MustCompile(`(?i)-vsphere.*-upi`)
 
+	majorMinorRegex = regexp.MustCompile(`^\d+\.\d+`)
+
 	allOpenshiftVariants = sets.NewString(
 		"alibaba",
 		"amd64",
@@ -132,6 +134,91 @@ var (
 		"vsphere-ipi",
 		"vsphere-upi",
 	)
+
+	// openshiftVariantDimensions places each variant within a dimension. When adding a variant to
+	// allOpenshiftVariants, please add it here too. "upgrade" is omitted as it is implied by Upgrade=micro or minor.
+	openshiftVariantDimensions = map[string]variantDimension{
+		"aggregated":     {"Aggregation", "aggregated"},
+		"alibaba":        {"Platform", "alibaba"},
+		"amd64":          {"Architecture", "amd64"},
+		"arm64":          {"Architecture", "arm64"},
+		"assisted":       {"Installer", "assisted"},
+		"aws":            {"Platform", "aws"},
+		"azure":          {"Platform", "azure"},
+		"compact":        {"Topology", "compact"},
+		"etcd-scaling":   {"Suite", "etcd-scaling"},
+		"fips":           {"SecurityMode", "fips"},
+		"gcp":            {"Platform", "gcp"},
+		"ha":             {"Topology", "ha"},
+		"heterogeneous":  {"Architecture", "heterogeneous"},
+		"hypershift":     {"Topology", models.TopologyExternal},
+		"libvirt":        {"Platform", "libvirt"},
+		"metal-assisted": {"Platform", "metal-assisted"},
+		"metal-ipi":      {"Platform", "metal-ipi"},
+		"metal-upi":      {"Platform", "metal-upi"},
+		"microshift":     {"Topology", "microshift"},
+		NeverStable:      {"JobTier", NeverStable},
+		"openstack":      {"Platform", "openstack"},
+		"osd":            {"Product", "osd"},
+		"ovirt":          {"Platform", "ovirt"},
+		"ovn":            {"Network", "ovn"},
+		"ppc64le":        {"Architecture", "ppc64le"},
+		"promote":        {"Procedure", "promote"},
+		"proxy":          {"NetworkAccess", "proxy"},
+		"realtime":       {"Kernel", "realtime"},
+		"s390x":          {"Architecture", "s390x"},
+		"sdn":            {"Network", "sdn"},
+		"serial":         {"Suite", "serial"},
+		"single-node":    {"Topology", "single-node"},
+		"techpreview":    {"FeatureSet", "techpreview"},
+		"upgrade-micro":  {"Upgrade", "micro"},
+		"upgrade-minor":  {"Upgrade", "minor"},
+		"vsphere-ipi":    {"Platform", "vsphere-ipi"},
+		"vsphere-upi":    {"Platform", "vsphere-upi"},
+	}
+
+	// openshiftVariantDescriptions are shown in the UI. When adding a variant to allOpenshiftVariants, please add
+	// it here too.
+	openshiftVariantDescriptions = map[string]string{
+		"aggregated":     "Aggregated jobs, which analyze the results of several runs of another job",
+		"alibaba":        "Jobs running on Alibaba Cloud",
+		"amd64":          "Jobs running on the amd64 architecture",
+		"arm64":          "Jobs running on the arm64 architecture",
+		"assisted":       "Jobs installing with the assisted installer",
+		"aws":            "Jobs running on AWS",
+		"azure":          "Jobs running on Azure",
+		"compact":        "Jobs running three node clusters, where the control plane nodes are also workers",
+		"etcd-scaling":   "Jobs testing scaling of the etcd cluster",
+		"fips":           "Jobs running with FIPS mode enabled",
+		"gcp":            "Jobs running on GCP",
+		"ha":             "Jobs running highly available clusters",
+		"heterogeneous":  "Jobs running clusters with nodes of mixed architectures",
+		"hypershift":     "Jobs running hosted control planes, in a management cluster",
+		"libvirt":        "Jobs running on libvirt",
+		"metal-assisted": "Jobs running on bare metal, installed with the assisted installer",
+		"metal-ipi":      "Jobs running on bare metal, with installer provisioned infrastructure",
+		"metal-upi":      "Jobs running on bare metal, with user provisioned infrastructure",
+		"microshift":     "Jobs running MicroShift",
+		NeverStable:      "Jobs which have never been stable, excluded from all other variants",
+		"openstack":      "Jobs running on OpenStack",
+		"osd":            "Jobs running OpenShift Dedicated",
+		"ovirt":          "Jobs running on oVirt",
+		"ovn":            "Jobs running the OVN-Kubernetes network plugin",
+		"ppc64le":        "Jobs running on the ppc64le architecture",
+		"promote":        "Jobs promoting release payloads",
+		"proxy":          "Jobs running behind a proxy",
+		"realtime":       "Jobs running the realtime kernel",
+		"s390x":          "Jobs running on the s390x architecture",
+		"sdn":            "Jobs running the OpenShift SDN network plugin",
+		"serial":         "Jobs running the serial test suite",
+		"single-node":    "Jobs running single node clusters",
+		"techpreview":    "Jobs running with the TechPreviewNoUpgrade feature set",
+		"upgrade":        "Jobs upgrading the cluster",
+		"upgrade-micro":  "Jobs upgrading between two versions of the same minor release",
+		"upgrade-minor":  "Jobs upgrading from the previous minor release",
+		"vsphere-ipi":    "Jobs running on vSphere, with installer provisioned infrastructure",
+		"vsphere-upi":    "Jobs running on vSphere, with user provisioned infrastructure",
+	}
 )
 
 func init() {
@@ -155,8 +242,21 @@ func (openshiftVariants) AllPlatforms() sets.String {
 	return allPlatforms
 }
 
+// clusterDataVariants maps values reported in cluster-data.json, which come straight from the cluster's API, to the
+// variant they represent.
+var clusterDataVariants = map[string]string{
+	"OVNKubernetes":   "ovn",
+	"OpenShiftSDN":    "sdn",
+	"HighlyAvailable": "ha",
+	"SingleReplica":   "single-node",
+	"External":        "hypershift",
+}
+
 func compareAndSelectVariant(jobNameVariant, clusterVariant, variantKey string) string {
 	val := jobNameVariant
+	if v, ok := clusterDataVariants[clusterVariant]; ok {
+		clusterVariant = v
+	}
 
 	if clusterVariant != "" {
 		if val != "" && clusterVariant != val {
@@ -218,21 +318,17 @@ func (v openshiftVariants) IdentifyVariants(jobName, release string, jobVariants
 	}
 
 	// Upgrade
-	// TODO: consider adding jobType.FromRelease and jobType.Release comparisons for determining minor / micro, if desirable
-	if upgradeRegex.MatchString(jobName) {
-		variants = append(variants, "upgrade")
-		if upgradeMinorRegex.MatchString(jobName) {
-			variants = append(variants, "upgrade-minor")
-		} else {
-			variants = append(variants, "upgrade-micro")
-		}
+	if upgrade := determineUpgrade(jobName, jobVariants); upgrade != "" {
+		variants = append(variants, "upgrade", upgrade)
 	}
 
-	// Topology
-	// external == hypershift hosted
-	if singleNodeRegex.MatchString(jobName) {
+	// Topology, hosted control planes report an External control plane topology
+	switch {
+	case hypershiftRegex.MatchString(jobName):
+		variants = append(variants, compareAndSelectVariant("hypershift", jobVariants.Topology, "Topology"))
+	case singleNodeRegex.MatchString(jobName):
 		variants = append(variants, compareAndSelectVariant("single-node", jobVariants.Topology, "Topology"))
-	} else {
+	default:
 		variants = append(variants, compareAndSelectVariant("ha", jobVariants.Topology, "Topology"))
 	}
 
@@ -240,9 +336,6 @@ func (v openshiftVariants) IdentifyVariants(jobName, release string, jobVariants
 	if microshiftRegex.MatchString(jobName) {
 		variants = append(variants, "microshift")
 	}
-	if hypershiftRegex.MatchString(jobName) {
-		variants = append(variants, "hypershift")
-	}
 	if serialRegex.MatchString(jobName) {
 		variants = append(variants, "serial")
 	}
@@ -258,7 +351,11 @@ func (v openshiftVariants) IdentifyVariants(jobName, release string, jobVariants
 	if osdRegex.MatchString(jobName) {
 		variants = append(variants, "osd")
 	}
-	if fipsRegex.MatchString(jobName) {
+	if jobVariants.FIPS != nil {
+		if *jobVariants.FIPS {
+			variants = append(variants, "fips")
+		}
+	} else if fipsRegex.MatchString(jobName) {
 		variants = append(variants, "fips")
 	}
 	if techpreview.MatchString(jobName) {
@@ -279,6 +376,54 @@ func (v openshiftVariants) IdentifyVariants(jobName, release string, jobVariants
 	return variants
 }
 
+// IdentifyVariantDimensions groups the variants from IdentifyVariants by dimension. Jobs that aren't bucketed into a
+// terminal variant (never-stable, promote or aggregated) also get Upgrade=none when they aren't upgrade jobs.
+func (v openshiftVariants) IdentifyVariantDimensions(jobName, release string, jobVariants models.ClusterData) models.VariantDimensions {
+	dimensions := variantsToDimensions(v.IdentifyVariants(jobName, release, jobVariants), openshiftVariantDimensions)
+	// Every non-terminal job has a topology
+	_, hasTopology := dimensions["Topology"]
+	_, hasUpgrade := dimensions["Upgrade"]
+	if hasTopology && !hasUpgrade {
+		dimensions["Upgrade"] = "none"
+	}
+
+	// Observed only, these are too fine-grained to be flat variants
+	if hasTopology && jobVariants.CloudRegion != "" {
+		dimensions["CloudRegion"] = jobVariants.CloudRegion
+	}
+	if hasTopology && jobVariants.NetworkStack != "" {
+		dimensions["NetworkStack"] = jobVariants.NetworkStack
+	}
+	return dimensions
+}
+
+// determineUpgrade returns upgrade-minor or upgrade-micro for upgrade jobs. The releases the cluster actually
+// upgraded between are preferred over the job name when the job reported them.
+func determineUpgrade(jobName string, jobVariants models.ClusterData) string {
+	if jobVariants.FromRelease != "" {
+		from, to := majorMinor(jobVariants.FromRelease), majorMinor(jobVariants.Release)
+		if from != "" && to != "" {
+			if from != to {
+				return "upgrade-minor"
+			}
+			return "upgrade-micro"
+		}
+	}
+
+	if !upgradeRegex.MatchString(jobName) {
+		return ""
+	}
+	if upgradeMinorRegex.MatchString(jobName) {
+		return "upgrade-minor"
+	}
+	return "upgrade-micro"
+}
+
+// majorMinor returns the X.Y prefix of a release version such as 4.14.0-0.nightly-2023-10-16-123456.
+func majorMinor(release string) string {
+	return majorMinorRegex.FindString(release)
+}
+
 func determinePlatform(jobName, _ string) string {
 	// Platforms
 	if alibabaRegex.MatchString(jobName) {
@@ -357,3 +502,11 @@ func (openshiftVariants) IsJobNeverStable(jobName string) bool {
 
 	return false
 }
+
+func (openshiftVariants) InvalidVariants(release string) []string {
+	return nil
+}
+
+func (openshiftVariants) DescribeVariant(variant string) VariantDescription {
+	return openshiftVariantDimensions[variant].describe(openshiftVariantDescriptions[variant])
+}
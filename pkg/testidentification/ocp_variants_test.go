@@ -8,6 +8,7 @@ import (
 )
 
 func Test_openshiftVariants_IdentifyVariants(t *testing.T) {
+	notFIPS := false
 	tests := []struct {
 		name        string
 		release     string
@@ -17,7 +18,7 @@ func Test_openshiftVariants_IdentifyVariants(t *testing.T) {
 		{
 			name:    "periodic-ci-openshift-hypershift-main-periodics-conformance-aws-ovn-4-12",
 			release: "4.12",
-			want:    []string{"aws", "amd64", "ovn", "ha", "hypershift"},
+			want:    []string{"aws", "amd64", "ovn", "hypershift"},
 		},
 		{
 			name:    "periodic-ci-openshift-release-master-nightly-4.12-e2e-metal-ovn-single-node-live-iso",
@@ -95,6 +96,30 @@ func Test_openshiftVariants_IdentifyVariants(t *testing.T) {
 			clusterData: models.ClusterData{Release: "4.13", Network: "sdn", Platform: "azure", Architecture: "arm64", Topology: "single"},
 			want:        []string{"azure", "arm64", "sdn", "ha"},
 		},
+		{
+			name:        "periodic-ci-openshift-release-master-ci-4.14-e2e-aws-cluster-api-network-type",
+			release:     "4.14",
+			clusterData: models.ClusterData{Release: "4.14", Network: "OpenShiftSDN", Topology: "SingleReplica"},
+			want:        []string{"aws", "amd64", "sdn", "single-node"},
+		},
+		{
+			name:        "periodic-ci-openshift-release-master-ci-4.15-e2e-aws-ovn-conformance",
+			release:     "4.15",
+			clusterData: models.ClusterData{Release: "4.15", Network: "OVNKubernetes", Topology: "External"},
+			want:        []string{"aws", "amd64", "ovn", "hypershift"},
+		},
+		{
+			name:        "periodic-ci-openshift-release-master-ci-4.14-e2e-aws-ovn-upgrade",
+			release:     "4.14",
+			clusterData: models.ClusterData{Release: "4.14.0-0.ci-2023-10-16-123456", FromRelease: "4.13.17"},
+			want:        []string{"aws", "amd64", "ovn", "upgrade", "upgrade-minor", "ha"},
+		},
+		{
+			name:        "periodic-ci-openshift-release-master-ci-4.14-e2e-aws-ovn-fips",
+			release:     "4.14",
+			clusterData: models.ClusterData{Release: "4.14", FIPS: &notFIPS},
+			want:        []string{"aws", "amd64", "ovn", "ha"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -105,3 +130,76 @@ func Test_openshiftVariants_IdentifyVariants(t *testing.T) {
 		})
 	}
 }
+
+func Test_openshiftVariants_IdentifyVariantDimensions(t *testing.T) {
+	tests := []struct {
+		name        string
+		release     string
+		clusterData models.ClusterData
+		want        models.VariantDimensions
+	}{
+		{
+			name:    "periodic-ci-openshift-release-master-ci-4.14-upgrade-from-stable-4.13-e2e-aws-ovn-upgrade",
+			release: "4.14",
+			want: models.VariantDimensions{
+				"Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "Upgrade": "minor",
+			},
+		},
+		{
+			name:    "periodic-ci-openshift-release-master-nightly-4.14-e2e-gcp-sdn-serial",
+			release: "4.14",
+			want: models.VariantDimensions{
+				"Platform": "gcp", "Architecture": "amd64", "Network": "sdn", "Topology": "ha", "Upgrade": "none",
+				"Suite": "serial",
+			},
+		},
+		{
+			name:    "periodic-ci-openshift-hypershift-main-periodics-conformance-aws-ovn-4-12",
+			release: "4.12",
+			want: models.VariantDimensions{
+				"Platform": "aws", "Architecture": "amd64", "Network": "ovn", "Topology": "external", "Upgrade": "none",
+			},
+		},
+		{
+			name:        "periodic-ci-openshift-release-master-ci-4.14-e2e-azure-ovn",
+			release:     "4.14",
+			clusterData: models.ClusterData{Topology: "single-node"},
+			want: models.VariantDimensions{
+				"Platform": "azure", "Architecture": "amd64", "Network": "ovn", "Topology": "single-node", "Upgrade": "none",
+			},
+		},
+		{
+			name:        "periodic-ci-openshift-release-master-ci-4.14-e2e-gcp-ovn",
+			release:     "4.14",
+			clusterData: models.ClusterData{Release: "4.14", CloudRegion: "us-central1", NetworkStack: "IPv4"},
+			want: models.VariantDimensions{
+				"Platform": "gcp", "Architecture": "amd64", "Network": "ovn", "Topology": "ha", "Upgrade": "none",
+				"CloudRegion": "us-central1", "NetworkStack": "IPv4",
+			},
+		},
+		{
+			name:    "aggregated-aws-ovn-upgrade-4.14-micro-release-openshift-release-analysis-aggregator",
+			release: "4.14",
+			want:    models.VariantDimensions{"Aggregation": "aggregated"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := openshiftVariants{}
+			if got := v.IdentifyVariantDimensions(tt.name, tt.release, tt.clusterData); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IdentifyVariantDimensions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_openshiftVariantDimensionsAndDescriptionsComplete(t *testing.T) {
+	for _, variant := range allOpenshiftVariants.List() {
+		if _, ok := openshiftVariantDimensions[variant]; !ok && variant != "upgrade" {
+			t.Errorf("variant %q has no dimension", variant)
+		}
+		if _, ok := openshiftVariantDescriptions[variant]; !ok {
+			t.Errorf("variant %q has no description", variant)
+		}
+	}
+}
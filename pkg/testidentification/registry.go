@@ -0,0 +1,101 @@
+package testidentification
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
+)
+
+// Names of the built-in variant managers.
+const (
+	OpenshiftVariantManagerName = "ocp"
+	EmptyVariantManagerName     = "none"
+	ConfigVariantManagerName    = "config"
+)
+
+// VariantManagerOptions are the settings available to a VariantManagerFactory.
+type VariantManagerOptions struct {
+	// ConfigPath is the variant definitions file given with --variant-config, if any.
+	ConfigPath string
+}
+
+// VariantManagerFactory builds a VariantManager.
+type VariantManagerFactory func(opts VariantManagerOptions) (VariantManager, error)
+
+var (
+	variantManagersLock sync.RWMutex
+	variantManagers     = map[string]VariantManagerFactory{}
+)
+
+func init() {
+	RegisterVariantManager(OpenshiftVariantManagerName, func(VariantManagerOptions) (VariantManager, error) {
+		return NewOpenshiftVariantManager(), nil
+	})
+	RegisterVariantManager(EmptyVariantManagerName, func(VariantManagerOptions) (VariantManager, error) {
+		return NewEmptyVariantManager(), nil
+	})
+	RegisterVariantManager(ConfigVariantManagerName, func(opts VariantManagerOptions) (VariantManager, error) {
+		if opts.ConfigPath == "" {
+			return nil, fmt.Errorf("--variant-config is required with --mode=%s", ConfigVariantManagerName)
+		}
+		return NewConfigVariantManagerFromFile(opts.ConfigPath)
+	})
+}
+
+// RegisterVariantManager makes a VariantManager available as a --mode. Deployments outside OpenShift can call it
+// from an init function to compile in their own implementation. It panics if the name is already registered.
+func RegisterVariantManager(name string, factory VariantManagerFactory) {
+	variantManagersLock.Lock()
+	defer variantManagersLock.Unlock()
+
+	if _, ok := variantManagers[name]; ok {
+		panic(fmt.Sprintf("variant manager %q is already registered", name))
+	}
+	variantManagers[name] = factory
+}
+
+// NewVariantManager builds the VariantManager registered under name.
+func NewVariantManager(name string, opts VariantManagerOptions) (VariantManager, error) {
+	variantManagersLock.RLock()
+	factory, ok := variantManagers[name]
+	variantManagersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown mode %q, only %s is allowed", name, strings.Join(RegisteredVariantManagers(), ", "))
+	}
+
+	return factory(opts)
+}
+
+// RegisteredVariantManagers returns the names of all registered variant managers, sorted.
+func RegisteredVariantManagers() []string {
+	variantManagersLock.RLock()
+	defer variantManagersLock.RUnlock()
+
+	names := make([]string, 0, len(variantManagers))
+	for name := range variantManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewConfigVariantManagerFromFile loads a VariantConfig from path and returns a VariantManager driven by it.
+func NewConfigVariantManagerFromFile(path string) (VariantManager, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not load variant config")
+	}
+	var variantConfig v1.VariantConfig
+	if err := yaml.Unmarshal(data, &variantConfig); err != nil {
+		return nil, errors.WithMessage(err, "couldn't unmarshal variant config")
+	}
+
+	return NewConfigVariantManager(&variantConfig)
+}
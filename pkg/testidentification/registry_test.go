@@ -0,0 +1,31 @@
+package testidentification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantManagerRegistry(t *testing.T) {
+	RegisterVariantManager("test-registry", func(VariantManagerOptions) (VariantManager, error) {
+		return NewEmptyVariantManager(), nil
+	})
+
+	vm, err := NewVariantManager("test-registry", VariantManagerOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, NewEmptyVariantManager(), vm)
+	assert.Contains(t, RegisteredVariantManagers(), "test-registry")
+
+	assert.Panics(t, func() {
+		RegisterVariantManager("test-registry", func(VariantManagerOptions) (VariantManager, error) {
+			return nil, nil
+		})
+	})
+
+	_, err = NewVariantManager("unknown", VariantManagerOptions{})
+	assert.Error(t, err)
+
+	_, err = NewVariantManager(ConfigVariantManagerName, VariantManagerOptions{})
+	assert.Error(t, err, "config mode requires a config path")
+}
@@ -0,0 +1,65 @@
+package testidentification
+
+import (
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// releaseVariants drops variants that are configured as invalid for a release from those a VariantManager identifies.
+type releaseVariants struct {
+	VariantManager
+	invalidVariants map[string]sets.String
+}
+
+// NewReleaseVariantManager wraps a VariantManager so the given variants, keyed by release, are never identified for
+// that release's jobs, i.e. sdn after it was removed in 4.17.
+func NewReleaseVariantManager(vm VariantManager, invalidVariants map[string][]string) VariantManager {
+	if len(invalidVariants) == 0 {
+		return vm
+	}
+
+	v := releaseVariants{
+		VariantManager:  vm,
+		invalidVariants: map[string]sets.String{},
+	}
+	for release, variants := range invalidVariants {
+		v.invalidVariants[release] = sets.NewString(variants...)
+	}
+	return v
+}
+
+func (v releaseVariants) IdentifyVariants(jobName, release string, jobVariants models.ClusterData) []string {
+	variants := v.VariantManager.IdentifyVariants(jobName, release, jobVariants)
+	invalid, ok := v.invalidVariants[release]
+	if !ok {
+		return variants
+	}
+
+	valid := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		if !invalid.Has(variant) {
+			valid = append(valid, variant)
+		}
+	}
+	return valid
+}
+
+// IdentifyVariantDimensions drops any dimension whose value is an invalid variant, i.e. Network=sdn when sdn is invalid.
+func (v releaseVariants) IdentifyVariantDimensions(jobName, release string, jobVariants models.ClusterData) models.VariantDimensions {
+	dimensions := v.VariantManager.IdentifyVariantDimensions(jobName, release, jobVariants)
+	invalid, ok := v.invalidVariants[release]
+	if !ok {
+		return dimensions
+	}
+
+	for key, value := range dimensions {
+		if invalid.Has(value) {
+			delete(dimensions, key)
+		}
+	}
+	return dimensions
+}
+
+func (v releaseVariants) InvalidVariants(release string) []string {
+	return sets.NewString(v.VariantManager.InvalidVariants(release)...).Union(v.invalidVariants[release]).List()
+}
@@ -0,0 +1,54 @@
+package testidentification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestReleaseVariantManager(t *testing.T) {
+	vm := NewReleaseVariantManager(NewOpenshiftVariantManager(), map[string][]string{
+		"4.17": {"sdn"},
+	})
+
+	tests := []struct {
+		name               string
+		job                string
+		release            string
+		expectedVariants   []string
+		expectedDimensions models.VariantDimensions
+	}{
+		{
+			name:             "invalid variant is dropped",
+			job:              "periodic-ci-openshift-release-master-ci-4.17-e2e-aws-sdn",
+			release:          "4.17",
+			expectedVariants: []string{"aws", "amd64", "ha"},
+			expectedDimensions: models.VariantDimensions{
+				"Platform":     "aws",
+				"Architecture": "amd64",
+				"Topology":     "ha",
+				"Upgrade":      "none",
+			},
+		},
+		{
+			name:             "variant is kept for other releases",
+			job:              "periodic-ci-openshift-release-master-ci-4.16-e2e-aws-sdn",
+			release:          "4.16",
+			expectedVariants: []string{"aws", "amd64", "sdn", "ha"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tc.expectedVariants, vm.IdentifyVariants(tc.job, tc.release, models.ClusterData{}))
+			if tc.expectedDimensions != nil {
+				assert.Equal(t, tc.expectedDimensions, vm.IdentifyVariantDimensions(tc.job, tc.release, models.ClusterData{}))
+			}
+		})
+	}
+
+	assert.Equal(t, []string{"sdn"}, vm.InvalidVariants("4.17"))
+	assert.Empty(t, vm.InvalidVariants("4.16"))
+}
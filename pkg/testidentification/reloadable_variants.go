@@ -0,0 +1,60 @@
+package testidentification
+
+import (
+	"sync"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// ReloadableVariantManager delegates to a VariantManager that can be replaced while it's in use, i.e. when the server
+// reloads its config on SIGHUP.
+type ReloadableVariantManager struct {
+	lock sync.RWMutex
+	vm   VariantManager
+}
+
+func NewReloadableVariantManager(vm VariantManager) *ReloadableVariantManager {
+	return &ReloadableVariantManager{vm: vm}
+}
+
+// Set replaces the VariantManager calls are delegated to.
+func (r *ReloadableVariantManager) Set(vm VariantManager) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.vm = vm
+}
+
+func (r *ReloadableVariantManager) current() VariantManager {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.vm
+}
+
+func (r *ReloadableVariantManager) AllVariants() sets.String {
+	return r.current().AllVariants()
+}
+
+func (r *ReloadableVariantManager) AllPlatforms() sets.String {
+	return r.current().AllPlatforms()
+}
+
+func (r *ReloadableVariantManager) IdentifyVariants(jobName, release string, jobVariants models.ClusterData) []string {
+	return r.current().IdentifyVariants(jobName, release, jobVariants)
+}
+
+func (r *ReloadableVariantManager) IdentifyVariantDimensions(jobName, release string, jobVariants models.ClusterData) models.VariantDimensions {
+	return r.current().IdentifyVariantDimensions(jobName, release, jobVariants)
+}
+
+func (r *ReloadableVariantManager) IsJobNeverStable(jobName string) bool {
+	return r.current().IsJobNeverStable(jobName)
+}
+
+func (r *ReloadableVariantManager) InvalidVariants(release string) []string {
+	return r.current().InvalidVariants(release)
+}
+
+func (r *ReloadableVariantManager) DescribeVariant(variant string) VariantDescription {
+	return r.current().DescribeVariant(variant)
+}
@@ -0,0 +1,18 @@
+package testidentification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestReloadableVariantManager(t *testing.T) {
+	vm := NewReloadableVariantManager(NewOpenshiftVariantManager())
+	assert.Empty(t, vm.InvalidVariants("4.17"))
+
+	vm.Set(NewReleaseVariantManager(NewOpenshiftVariantManager(), map[string][]string{"4.17": {"sdn"}}))
+	assert.Equal(t, []string{"sdn"}, vm.InvalidVariants("4.17"))
+	assert.NotContains(t, vm.IdentifyVariants("periodic-ci-openshift-release-master-ci-4.17-e2e-aws-sdn", "4.17", models.ClusterData{}), "sdn")
+}
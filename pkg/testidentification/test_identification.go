@@ -45,7 +45,7 @@ const (
 
 var (
 	// DefaultExcludedVariants is used to exclude particular variants in reporting
-	DefaultExcludedVariants = []string{"aggregated", "never-stable"}
+	DefaultExcludedVariants = []string{"aggregated", "never-stable", Experimental}
 
 	// TODO: add [sig-sippy] here as well so we can more clearly identify and substring search
 	// OperatorInstallPrefix is used when sippy adds synthetic tests to report if each operator installed correct.
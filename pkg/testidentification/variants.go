@@ -16,7 +16,54 @@ type VariantManager interface {
 	// IdentifyVariants takes a job name and returns the list of variants that job belongs to.
 	IdentifyVariants(jobName string, release string, jobVariants models.ClusterData) []string
 
+	// IdentifyVariantDimensions takes a job name and returns its variants keyed by dimension, i.e. Platform=aws,
+	// Network=ovn, Upgrade=minor.
+	IdentifyVariantDimensions(jobName string, release string, jobVariants models.ClusterData) models.VariantDimensions
+
 	// IsJobNeverStable returns true if the job has been curated as never having passed more than 50ish% of the time.
 	// This is used sparingly for jobs that are persistently failing and never taken stable.
 	IsJobNeverStable(jobName string) bool
+
+	// InvalidVariants returns the variants that don't apply to a release, i.e. sdn after it was removed. They are
+	// never identified for the release's jobs, and are left out of its variant reports.
+	InvalidVariants(release string) []string
+
+	// DescribeVariant returns the dimension and a human-readable description of a variant, for use in UIs. Unknown
+	// variants return an empty description.
+	DescribeVariant(variant string) VariantDescription
+}
+
+// VariantDescription describes a variant, see VariantManager.DescribeVariant.
+type VariantDescription struct {
+	// Dimension and DimensionValue place the variant within a dimension, i.e. upgrade-minor is Upgrade=minor.
+	Dimension      string
+	DimensionValue string
+
+	Description string
+}
+
+// variantDimension places a flat variant within a dimension, i.e. "upgrade-minor" is Upgrade=minor.
+type variantDimension struct {
+	key   string
+	value string
+}
+
+func (d variantDimension) describe(description string) VariantDescription {
+	return VariantDescription{
+		Dimension:      d.key,
+		DimensionValue: d.value,
+		Description:    description,
+	}
+}
+
+// variantsToDimensions groups flat variants by dimension. Variants without a dimension are dropped, and when several
+// variants share a dimension the last one wins.
+func variantsToDimensions(variants []string, dimensions map[string]variantDimension) models.VariantDimensions {
+	result := models.VariantDimensions{}
+	for _, variant := range variants {
+		if d, ok := dimensions[variant]; ok {
+			result[d.key] = d.value
+		}
+	}
+	return result
 }
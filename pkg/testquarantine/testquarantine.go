@@ -0,0 +1,54 @@
+// Package testquarantine notifies about test quarantines that expired, so a test isn't forgotten in quarantine: its
+// owners either extend the quarantine, or its failures count against its jobs again.
+package testquarantine
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/notify"
+)
+
+// NotifyExpired sends an event for each quarantine that expired since the last time, marking it notified. A
+// quarantine whose event couldn't be sent is tried again the next time.
+func NotifyExpired(dbc *db.DB, notifier notify.Notifier, now time.Time) error {
+	expired := make([]models.TestQuarantine, 0)
+	res := dbc.DB.Where("expires_at <= ? AND expiry_notified_at IS NULL", now).Order("expires_at").Find(&expired)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	var notified int
+	for i := range expired {
+		q := &expired[i]
+		err := notifier.Notify(expiredEvent(*q))
+		if err != nil {
+			log.WithError(err).Warningf("error notifying about the expired quarantine of %s", q.TestName)
+			continue
+		}
+		notifiedAt := now
+		q.ExpiryNotifiedAt = &notifiedAt
+		if res := dbc.DB.Model(q).Update("expiry_notified_at", notifiedAt); res.Error != nil {
+			return res.Error
+		}
+		notified++
+	}
+
+	log.Infof("%d test quarantines expired, %d notified", len(expired), notified)
+	return nil
+}
+
+func expiredEvent(q models.TestQuarantine) notify.Event {
+	return notify.Event{
+		Type:  notify.EventQuarantineExpired,
+		Title: fmt.Sprintf("Quarantine of %s expired", q.TestName),
+		Message: fmt.Sprintf("%s was quarantined by %s until %s: %s. Its failures count against its jobs again, "+
+			"unless the quarantine is extended.", q.TestName, q.CreatedBy, q.ExpiresAt.UTC().Format(time.RFC3339),
+			q.Reason),
+		URL: q.JiraURL,
+	}
+}
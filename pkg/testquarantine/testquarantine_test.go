@@ -0,0 +1,27 @@
+package testquarantine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/notify"
+)
+
+func TestExpiredEvent(t *testing.T) {
+	event := expiredEvent(models.TestQuarantine{
+		TestName:  "[sig-network] pods should be reachable",
+		JiraURL:   "https://issues.redhat.com/browse/OCPBUGS-1234",
+		Reason:    "flakes on metal",
+		ExpiresAt: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		CreatedBy: "alice@example.com",
+	})
+	assert.Equal(t, notify.EventQuarantineExpired, event.Type)
+	assert.Equal(t, "Quarantine of [sig-network] pods should be reachable expired", event.Title)
+	assert.Equal(t, "[sig-network] pods should be reachable was quarantined by alice@example.com until "+
+		"2024-03-15T00:00:00Z: flakes on metal. Its failures count against its jobs again, unless the quarantine is "+
+		"extended.", event.Message)
+	assert.Equal(t, "https://issues.redhat.com/browse/OCPBUGS-1234", event.URL)
+}
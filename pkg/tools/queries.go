@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+var releaseProperty = stringProperty("OpenShift release, i.e. 4.15")
+
+type topRegressedTestsArgs struct {
+	Release   string `json:"release"`
+	Component string `json:"component"`
+	Limit     int    `json:"limit"`
+}
+
+func (a *topRegressedTestsArgs) validate() error {
+	if a.Release == "" {
+		return fmt.Errorf("release is required")
+	}
+	return validateLimit(&a.Limit, 10, 50)
+}
+
+type regressedTest struct {
+	Name                   string  `json:"name"`
+	Variants               string  `json:"variants"`
+	Component              string  `json:"component"`
+	CurrentPassPercentage  float64 `json:"current_pass_percentage"`
+	PreviousPassPercentage float64 `json:"previous_pass_percentage"`
+	NetImprovement         float64 `json:"net_improvement"`
+	CurrentRuns            int     `json:"current_runs"`
+	PValue                 float64 `json:"p_value"`
+	OpenBugs               int     `json:"open_bugs"`
+}
+
+var topRegressedTestsTool = tool{
+	definition: Definition{
+		Name: "top_regressed_tests",
+		Description: "Lists the open test regressions with the largest pass rate drops over the last week " +
+			"compared to the week before, in a release and optionally a jira component.",
+		InputSchema: objectSchema(map[string]interface{}{
+			"release":   releaseProperty,
+			"component": stringProperty("Jira component owning the tests, i.e. Networking / ovn-kubernetes"),
+			"limit":     limitProperty("Maximum number of tests to return", 10, 50),
+		}, "release"),
+	},
+	newArgs: func() validator { return &topRegressedTestsArgs{} },
+	run: func(dbc *db.DB, a interface{}, reportEnd time.Time, tenant string) (interface{}, error) {
+		args := a.(*topRegressedTestsArgs)
+		if err := checkTenantRelease(dbc, tenant, args.Release); err != nil {
+			return nil, err
+		}
+		tests, _, err := api.BuildTestsResults(dbc, args.Release, "default", true, false, nil)
+		if err != nil {
+			return nil, err
+		}
+		regressions, err := api.GetTestRegressionsFromDB(dbc, args.Release, models.TestRegressionOpen)
+		if err != nil {
+			return nil, err
+		}
+		return topRegressed(regressions, tests, args.Component, args.Limit), nil
+	},
+}
+
+// topRegressed returns the open regressions, as regression detection recorded them, with the largest pass rate drops.
+// The tests' results give their components and open bugs.
+func topRegressed(regressions []models.TestRegression, tests []apitype.Test, component string, limit int) []regressedTest {
+	byName := map[string]apitype.Test{}
+	for _, test := range tests {
+		byName[test.Name] = test
+	}
+	regressed := make([]regressedTest, 0)
+	for _, tr := range regressions {
+		test := byName[tr.TestName]
+		if component != "" && test.JiraComponent != component {
+			continue
+		}
+		regressed = append(regressed, regressedTest{
+			Name:                   tr.TestName,
+			Variants:               strings.Join(tr.Variants, ","),
+			Component:              test.JiraComponent,
+			CurrentPassPercentage:  tr.SamplePassPercentage,
+			PreviousPassPercentage: tr.BasisPassPercentage,
+			NetImprovement:         tr.SamplePassPercentage - tr.BasisPassPercentage,
+			CurrentRuns:            tr.SampleRuns,
+			PValue:                 tr.PValue,
+			OpenBugs:               test.OpenBugs,
+		})
+	}
+	sort.SliceStable(regressed, func(i, j int) bool {
+		return regressed[i].NetImprovement < regressed[j].NetImprovement
+	})
+	if len(regressed) > limit {
+		regressed = regressed[:limit]
+	}
+	return regressed
+}
+
+type jobHistoryArgs struct {
+	Job   string `json:"job"`
+	Limit int    `json:"limit"`
+}
+
+func (a *jobHistoryArgs) validate() error {
+	if a.Job == "" {
+		return fmt.Errorf("job is required")
+	}
+	return validateLimit(&a.Limit, 20, 100)
+}
+
+type jobRun struct {
+	URL                   string    `json:"url"`
+	Timestamp             time.Time `json:"timestamp"`
+	OverallResult         string    `json:"overall_result"`
+	Succeeded             bool      `json:"succeeded"`
+	InfrastructureFailure bool      `json:"infrastructure_failure"`
+	TestFailures          int       `json:"test_failures"`
+}
+
+var jobHistoryTool = tool{
+	definition: Definition{
+		Name: "job_history",
+		Description: "Lists a prow job's most recent runs, newest first, with their results. Overall results are " +
+			"single letters: S succeeded, F failed e2e tests, I failed installing, U failed upgrading, N " +
+			"infrastructure failure, n failed before setup, A aborted and R running.",
+		InputSchema: objectSchema(map[string]interface{}{
+			"job":   stringProperty("Full prow job name, i.e. periodic-ci-openshift-release-master-nightly-4.15-e2e-aws-ovn"),
+			"limit": limitProperty("Maximum number of runs to return", 20, 100),
+		}, "job"),
+	},
+	newArgs: func() validator { return &jobHistoryArgs{} },
+	run: func(dbc *db.DB, a interface{}, reportEnd time.Time, tenant string) (interface{}, error) {
+		args := a.(*jobHistoryArgs)
+		var runs []models.ProwJobRun
+		q := dbc.DB.
+			Joins("INNER JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+			Where("prow_jobs.name = ?", args.Job).
+			Where("prow_job_runs.timestamp <= ?", reportEnd)
+		if tenant != "" {
+			q = q.Where("prow_jobs.tenant = ?", tenant)
+		}
+		res := q.Order("prow_job_runs.timestamp DESC").
+			Limit(args.Limit).
+			Find(&runs)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+
+		history := make([]jobRun, 0, len(runs))
+		for _, run := range runs {
+			history = append(history, jobRun{
+				URL:                   run.URL,
+				Timestamp:             run.Timestamp,
+				OverallResult:         string(run.OverallResult),
+				Succeeded:             run.Succeeded,
+				InfrastructureFailure: run.InfrastructureFailure,
+				TestFailures:          run.TestFailures,
+			})
+		}
+		return history, nil
+	},
+}
+
+type payloadHealthArgs struct {
+	Release string `json:"release"`
+}
+
+func (a *payloadHealthArgs) validate() error {
+	if a.Release == "" {
+		return fmt.Errorf("release is required")
+	}
+	return nil
+}
+
+type payloadStreamHealth struct {
+	Stream             string    `json:"stream"`
+	Architecture       string    `json:"architecture"`
+	LastPayload        string    `json:"last_payload"`
+	LastPayloadTime    time.Time `json:"last_payload_time"`
+	LastPhase          string    `json:"last_phase"`
+	ConsecutiveInPhase int       `json:"consecutive_in_phase"`
+	AcceptedLastWeek   int       `json:"accepted_last_week"`
+	RejectedLastWeek   int       `json:"rejected_last_week"`
+}
+
+var payloadHealthTool = tool{
+	definition: Definition{
+		Name: "payload_health",
+		Description: "Summarizes each payload stream of a release: its latest payload and phase (Accepted or " +
+			"Rejected), how many payloads in a row had that phase, and last week's accepted and rejected counts.",
+		InputSchema: objectSchema(map[string]interface{}{
+			"release": releaseProperty,
+		}, "release"),
+	},
+	newArgs: func() validator { return &payloadHealthArgs{} },
+	run: func(dbc *db.DB, a interface{}, reportEnd time.Time, tenant string) (interface{}, error) {
+		args := a.(*payloadHealthArgs)
+		if err := checkTenantRelease(dbc, tenant, args.Release); err != nil {
+			return nil, err
+		}
+		reports, err := api.ReleaseHealthReports(dbc, args.Release, reportEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		health := make([]payloadStreamHealth, 0, len(reports))
+		for _, report := range reports {
+			health = append(health, payloadStreamHealth{
+				Stream:             report.Stream,
+				Architecture:       report.Architecture,
+				LastPayload:        report.ReleaseTag.ReleaseTag,
+				LastPayloadTime:    report.ReleaseTime,
+				LastPhase:          report.LastPhase,
+				ConsecutiveInPhase: report.Count,
+				AcceptedLastWeek:   report.PhaseCounts.CurrentWeek.Accepted,
+				RejectedLastWeek:   report.PhaseCounts.CurrentWeek.Rejected,
+			})
+		}
+		return health, nil
+	},
+}
@@ -0,0 +1,162 @@
+// Package tools describes a few sippy queries as tools with JSON schemas, for LLM agents and chatbots to call. Tools
+// only read, and run in read-only transactions so a mistake can't write to the database.
+package tools
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// Definition describes a tool in the format tool-calling models expect.
+type Definition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// invalidArguments refuses a call that names an unknown tool or has invalid arguments, as opposed to failing to query.
+func invalidArguments(format string, args ...interface{}) error {
+	return api.NewProblem(http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+type tool struct {
+	definition Definition
+	// newArgs returns a pointer to the tool's arguments struct, which validate checks once decoded.
+	newArgs func() validator
+	// run queries for the tenant's data when it's set.
+	run func(dbc *db.DB, args interface{}, reportEnd time.Time, tenant string) (interface{}, error)
+}
+
+type validator interface {
+	validate() error
+}
+
+// allTools are the tools available, new tools must only read.
+var allTools = []tool{
+	topRegressedTestsTool,
+	jobHistoryTool,
+	payloadHealthTool,
+}
+
+// Definitions returns every tool, sorted by name.
+func Definitions() []Definition {
+	defs := make([]Definition, 0, len(allTools))
+	for _, t := range allTools {
+		defs = append(defs, t.definition)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+func lookup(name string) (tool, bool) {
+	for _, t := range allTools {
+		if t.definition.Name == name {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+// Call runs the named tool with its JSON arguments, scoped to the tenant of ctx if any.
+func Call(ctx context.Context, dbc *db.DB, name string, arguments json.RawMessage, reportEnd time.Time) (interface{}, error) {
+	t, ok := lookup(name)
+	if !ok {
+		return nil, invalidArguments("unknown tool %q", name)
+	}
+
+	args, err := decodeArguments(t, arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := dbc.DB.WithContext(ctx).Begin(&sql.TxOptions{ReadOnly: true})
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer tx.Rollback()
+
+	return t.run(&db.DB{DB: tx, BatchSize: dbc.BatchSize}, args, reportEnd, api.Tenant(ctx))
+}
+
+// checkTenantRelease rejects a release the tenant has no jobs in, as if it didn't exist.
+func checkTenantRelease(dbc *db.DB, tenant, release string) error {
+	if tenant == "" {
+		return nil
+	}
+	releases, err := query.TenantReleasesFromDB(dbc, tenant)
+	if err != nil {
+		return err
+	}
+	for _, r := range releases {
+		if r.Release == release {
+			return nil
+		}
+	}
+	return invalidArguments("release %s not found", release)
+}
+
+// decodeArguments decodes and validates the arguments, rejecting fields the schema doesn't define.
+func decodeArguments(t tool, arguments json.RawMessage) (validator, error) {
+	args := t.newArgs()
+	if len(bytes.TrimSpace(arguments)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(arguments))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(args); err != nil {
+			return nil, invalidArguments("invalid arguments for %s: %s", t.definition.Name, err)
+		}
+	}
+	if err := args.validate(); err != nil {
+		return nil, invalidArguments("invalid arguments for %s: %s", t.definition.Name, err)
+	}
+	return args, nil
+}
+
+// objectSchema returns a JSON schema for an object with the properties, rejecting any others.
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func limitProperty(description string, defaultLimit, maxLimit int) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "integer",
+		"description": description,
+		"minimum":     1,
+		"maximum":     maxLimit,
+		"default":     defaultLimit,
+	}
+}
+
+// validateLimit defaults an unset limit, and rejects one outside 1 to max.
+func validateLimit(limit *int, defaultLimit, maxLimit int) error {
+	if *limit == 0 {
+		*limit = defaultLimit
+	}
+	if *limit < 1 || *limit > maxLimit {
+		return fmt.Errorf("limit must be between 1 and %d", maxLimit)
+	}
+	return nil
+}
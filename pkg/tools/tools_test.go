@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestDefinitions(t *testing.T) {
+	defs := Definitions()
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		names = append(names, def.Name)
+		assert.Equal(t, false, def.InputSchema["additionalProperties"], def.Name)
+	}
+	assert.Equal(t, []string{"job_history", "payload_health", "top_regressed_tests"}, names)
+
+	// definitions must serialize, as they're served to agents
+	_, err := json.Marshal(defs)
+	require.NoError(t, err)
+}
+
+func TestDecodeArguments(t *testing.T) {
+	testCases := []struct {
+		name          string
+		arguments     string
+		expectedArgs  validator
+		expectedError string
+	}{
+		{
+			name:         "defaults the limit",
+			arguments:    `{"release": "4.15"}`,
+			expectedArgs: &topRegressedTestsArgs{Release: "4.15", Limit: 10},
+		},
+		{
+			name:          "requires a release",
+			arguments:     `{"component": "Networking"}`,
+			expectedError: "release is required",
+		},
+		{
+			name:          "rejects unknown arguments",
+			arguments:     `{"release": "4.15", "sql": "DROP TABLE tests"}`,
+			expectedError: `unknown field "sql"`,
+		},
+		{
+			name:          "bounds the limit",
+			arguments:     `{"release": "4.15", "limit": 1000}`,
+			expectedError: "limit must be between 1 and 50",
+		},
+	}
+
+	tool, ok := lookup("top_regressed_tests")
+	require.True(t, ok)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := decodeArguments(tool, json.RawMessage(tc.arguments))
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assertInvalidArguments(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestCallUnknownTool(t *testing.T) {
+	_, err := Call(context.Background(), nil, "delete_everything", nil, time.Now())
+	assertInvalidArguments(t, err)
+}
+
+func TestTopRegressed(t *testing.T) {
+	regressions := []models.TestRegression{
+		{TestName: "slightly worse", BasisPassPercentage: 100, SamplePassPercentage: 95, SampleRuns: 10},
+		{TestName: "much worse", Variants: pq.StringArray{"aws"}, BasisPassPercentage: 100, SamplePassPercentage: 60,
+			SampleRuns: 10},
+		{TestName: "other component", BasisPassPercentage: 100, SamplePassPercentage: 40, SampleRuns: 10},
+	}
+	tests := []apitype.Test{
+		{Name: "slightly worse", JiraComponent: "Networking", OpenBugs: 1},
+		{Name: "much worse", JiraComponent: "Networking"},
+		{Name: "not regressed", JiraComponent: "Networking", NetImprovement: -50},
+		{Name: "other component", JiraComponent: "Storage"},
+	}
+
+	regressed := topRegressed(regressions, tests, "Networking", 10)
+	require.Len(t, regressed, 2)
+	assert.Equal(t, "much worse", regressed[0].Name)
+	assert.Equal(t, "aws", regressed[0].Variants)
+	assert.Equal(t, -40.0, regressed[0].NetImprovement)
+	assert.Equal(t, "slightly worse", regressed[1].Name)
+	assert.Equal(t, 1, regressed[1].OpenBugs)
+
+	regressed = topRegressed(regressions, tests, "", 1)
+	require.Len(t, regressed, 1)
+	assert.Equal(t, "other component", regressed[0].Name)
+}
+
+func assertInvalidArguments(t *testing.T, err error) {
+	var problem api.Problem
+	require.ErrorAs(t, err, &problem)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/openshift/sippy"
+
+// Init installs a global tracer provider exporting spans over OTLP/HTTP. The exporter and resource are configured
+// entirely from the standard OTEL_* environment variables, i.e. OTEL_EXPORTER_OTLP_ENDPOINT and
+// OTEL_RESOURCE_ATTRIBUTES. When no endpoint is configured tracing is left disabled, and every span is a no-op.
+//
+// The returned function flushes any buffered spans and should be called before the process exits.
+func Init(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		log.Debug("no OTLP endpoint configured, tracing is disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't create OTLP trace exporter")
+	}
+
+	// Attributes from the environment take precedence over our default service name.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't create trace resource")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	log.WithField("service", serviceName).Info("OTLP tracing enabled")
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns sippy's tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// EndSpan records err on the span, if any, and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// StartQuerySpan starts a span for a query against the given database system, i.e. "bigquery" or "postgresql". The
+// span should be ended once the results have been read.
+func StartQuerySpan(ctx context.Context, system, statement string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, system+".query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", system),
+			attribute.String("db.statement", statement),
+		))
+}
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket limits the rate of an operation while allowing short bursts. The bucket holds up to capacity tokens,
+// and refills at ratePerHour tokens an hour. Every operation takes a token.
+type TokenBucket struct {
+	lock     sync.Mutex
+	capacity float64
+	tokens   float64
+	interval time.Duration
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewTokenBucket returns a full bucket.
+func NewTokenBucket(capacity, ratePerHour int) *TokenBucket {
+	return newTokenBucket(capacity, ratePerHour, time.Now)
+}
+
+func newTokenBucket(capacity, ratePerHour int, now func() time.Time) *TokenBucket {
+	return &TokenBucket{
+		capacity: float64(capacity),
+		tokens:   float64(capacity),
+		interval: time.Hour / time.Duration(ratePerHour),
+		last:     now(),
+		now:      now,
+	}
+}
+
+// Take takes a token if one is available, otherwise it returns how long until one will be.
+func (tb *TokenBucket) Take() (bool, time.Duration) {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+
+	now := tb.now()
+	tb.tokens += float64(now.Sub(tb.last)) / float64(tb.interval)
+	if tb.tokens > tb.capacity {
+		tb.tokens = tb.capacity
+	}
+	tb.last = now
+
+	if tb.tokens >= 1 {
+		tb.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - tb.tokens) * float64(tb.interval))
+}
+
+// Wait blocks until a token is available and takes it.
+func (tb *TokenBucket) Wait() {
+	for {
+		ok, wait := tb.Take()
+		if ok {
+			return
+		}
+		time.Sleep(wait)
+	}
+}
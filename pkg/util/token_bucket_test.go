@@ -0,0 +1,41 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+	tb := newTokenBucket(2, 60, func() time.Time { return now })
+
+	// a full bucket allows a burst up to its capacity
+	for i := 0; i < 2; i++ {
+		ok, _ := tb.Take()
+		assert.True(t, ok, "take %d", i)
+	}
+	ok, wait := tb.Take()
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, wait)
+
+	// refills at one token a minute
+	now = now.Add(30 * time.Second)
+	ok, wait = tb.Take()
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+
+	now = now.Add(30 * time.Second)
+	ok, _ = tb.Take()
+	assert.True(t, ok)
+
+	// never refills past capacity
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		ok, _ = tb.Take()
+		assert.True(t, ok, "take %d", i)
+	}
+	ok, _ = tb.Take()
+	assert.False(t, ok)
+}
@@ -11,8 +11,10 @@ import (
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 
+	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/notify"
 	"github.com/openshift/sippy/pkg/sippyserver"
 )
 
@@ -152,7 +154,7 @@ func testNameWithoutSuite(dbc *gorm.DB) error {
 	// Refresh materialized views
 	sippyserver.RefreshData(&db.DB{
 		DB: dbc,
-	}, nil, false)
+	}, "updatesuites", nil, false, v1.RegressionDetectionConfig{}, v1.SilentJobDetectionConfig{}, notify.NewNoopNotifier())
 
 	return nil
 }